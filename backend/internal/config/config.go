@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -23,6 +25,185 @@ type Config struct {
 	PrivateKeyPath string
 	PublicKeyPath  string
 	CORSOrigins    string
+	StorageDir     string
+	BatchWorkers   int
+	BatchMaxFiles  int
+	BatchMaxSize   int64
+
+	MaxPDFSize        int64
+	UploadMemoryLimit int64
+	UploadTempDir     string
+	UploadExpiry      time.Duration
+	UploadCleanup     time.Duration
+	// Anonymous (verification) uploads expire sooner and are capped per client IP
+	UploadGuestExpiry time.Duration
+	UploadMaxPerIP    int
+	UploadBytesPerIP  int64
+
+	JobWorkers         int
+	JobPollInterval    time.Duration
+	JobMaxAttempts     int
+	JobLockTimeout     time.Duration
+	AsyncSignThreshold int64
+
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookRetryBase    time.Duration
+	WebhookRetryMax     time.Duration
+	// Allows receivers on loopback and private networks, for local development only
+	WebhookAllowPrivate bool
+
+	TrustedProxies     string
+	RateLimitStore     string
+	RateLimitRedisURL  string
+	RateLimitCacheSize int
+	RateLimitPolicies  string
+
+	LoginMaxFailures     int
+	LoginIPMaxFailures   int
+	LoginFailureWindow   time.Duration
+	LoginLockoutDuration time.Duration
+	LoginDelayBase       time.Duration
+	LoginDelayMax        time.Duration
+	LoginCaptchaAfter    int
+
+	MFAIssuer             string
+	MFAEncryptionKey      string
+	MFAChallengeTTL       time.Duration
+	MFARequiredForSigning bool
+
+	WebAuthnRPID               string
+	WebAuthnRPName             string
+	WebAuthnRPOrigins          string
+	WebAuthnChallengeTTL       time.Duration
+	WebAuthnRequiredForSigning bool
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	SchedulerEnabled         bool
+	SchedulerTick            time.Duration
+	SessionCleanupSchedule   string
+	VerificationLogRetention time.Duration
+	VerificationLogSchedule  string
+	AuditLogRetention        time.Duration
+	AuditLogSchedule         string
+	KeyMaxAge                time.Duration
+	KeyAgeCheckSchedule      string
+
+	OIDCProviders   []OIDCProviderConfig
+	OIDCRedirectURL string
+	OIDCStateTTL    time.Duration
+
+	LDAP LDAPConfig
+
+	APIKeyDefaultTTL  time.Duration
+	APIKeyMaxTTL      time.Duration
+	APIKeyRateLimit   int
+	APIKeyTouchPeriod time.Duration
+
+	RBACCacheTTL time.Duration
+
+	OrgKeyEncryptionKey string
+	OrgKeySize          int
+
+	// RegistrationMode is "open", "invite" (an invitation token is required) or
+	// "closed" (accounts are only created by admins)
+	RegistrationMode string
+	InvitationTTL    time.Duration
+
+	// DelegationMaxDuration caps how long a signing delegation may be granted for
+	DelegationMaxDuration time.Duration
+
+	// DuplicatePolicy is what signing an already signed PDF does when the request
+	// does not choose: "reject", "return_existing" or "new_version"
+	DuplicatePolicy string
+	// DuplicateSimilarity is the percentage of shared text from which another
+	// document is reported as a near duplicate (0 disables the comparison)
+	DuplicateSimilarity int
+	// DuplicateScanLimit is how many recent documents a new one is compared with
+	DuplicateScanLimit int
+
+	// GeoIPDatabase is a country CSV (DB-IP lite format) used to record the
+	// country of verifiers; empty leaves it unrecorded
+	GeoIPDatabase string
+	// TamperAlertThreshold is how many qr_valid_content_changed results of a
+	// document within TamperAlertWindow raise a forgery alert (0 disables alerts)
+	TamperAlertThreshold int
+	TamperAlertWindow    time.Duration
+}
+
+// LDAPConfig configures password login against an LDAP or Active Directory server.
+// Directory logins are disabled while URL is empty.
+type LDAPConfig struct {
+	// URL is ldap://host:389 or ldaps://host:636
+	URL string
+	// StartTLS upgrades an ldap:// connection before any credentials are sent
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// CACertFile is a PEM bundle trusted for the server certificate in addition
+	// to the system roots
+	CACertFile string
+	// BindDN and BindPassword are the service account used to find users; empty
+	// searches anonymously
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter restricts the search to entries that may sign in
+	UserFilter        string
+	UsernameAttribute string
+	// IDAttribute holds a stable identifier for the entry (entryUUID, objectGUID);
+	// empty uses the username
+	IDAttribute    string
+	EmailAttribute string
+	NameAttribute  string
+	// GroupAttribute lists the groups on the user entry (memberOf)
+	GroupAttribute string
+	// GroupBaseDN enables a search for groups whose GroupFilter matches the user;
+	// {dn} and {username} in the filter are replaced
+	GroupBaseDN  string
+	GroupFilter  string
+	RoleMappings []RoleMapping
+	// DefaultRole is given to users in no mapped group; empty (set as "none")
+	// refuses them
+	DefaultRole string
+	// AutoProvision creates an account on first login
+	AutoProvision bool
+	// LinkExisting attaches a first login to the local account with the same
+	// username; otherwise such logins are refused
+	LinkExisting bool
+	Timeout      time.Duration
+}
+
+// OIDCProviderConfig configures an OpenID Connect identity provider. Its settings
+// are read from OIDC_<NAME>_* variables for each name listed in OIDC_PROVIDERS.
+type OIDCProviderConfig struct {
+	// Name identifies the provider in URLs and linked identities
+	Name         string
+	DisplayName  string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// GroupsClaim is the ID token claim listing the user's groups
+	GroupsClaim string
+	// RoleMappings assign a role to members of a group; the first matching entry wins
+	RoleMappings []RoleMapping
+	// DefaultRole is given to users in no mapped group; empty (set as "none")
+	// refuses them
+	DefaultRole string
+	// AutoProvision creates an account on first login
+	AutoProvision bool
+	// LinkByEmail attaches a first login to the existing account with the same
+	// verified email address
+	LinkByEmail bool
+}
+
+// RoleMapping maps a group at an identity provider or directory to a role
+type RoleMapping struct {
+	Group string
+	Role  string
 }
 
 func Load() (*Config, error) {
@@ -45,6 +226,103 @@ func Load() (*Config, error) {
 		PrivateKeyPath: getEnv("PRIVATE_KEY_PATH", "private_key.pem"),
 		PublicKeyPath:  getEnv("PUBLIC_KEY_PATH", "public_key.pem"),
 		CORSOrigins:    getEnv("CORS_ORIGINS", "https://sign.arikachmad.com,https://sign-api.arikachmad.com,http://localhost:3000,http://localhost:8065"),
+		StorageDir:     getEnv("STORAGE_DIR", "storage"),
+		BatchWorkers:   getEnvInt("BATCH_WORKERS", 4),
+		BatchMaxFiles:  getEnvInt("BATCH_MAX_FILES", 500),
+		BatchMaxSize:   getEnvInt64("BATCH_MAX_SIZE", 500<<20),
+
+		MaxPDFSize:        getEnvInt64("MAX_PDF_SIZE", 50<<20),
+		UploadMemoryLimit: getEnvInt64("UPLOAD_MEMORY_LIMIT", 8<<20),
+		UploadTempDir:     getEnv("UPLOAD_TEMP_DIR", ""),
+		UploadExpiry:      getEnvDuration("UPLOAD_EXPIRY", 24*time.Hour),
+		UploadCleanup:     getEnvDuration("UPLOAD_CLEANUP_INTERVAL", time.Hour),
+		UploadGuestExpiry: getEnvDuration("UPLOAD_GUEST_EXPIRY", 30*time.Minute),
+		UploadMaxPerIP:    getEnvInt("UPLOAD_MAX_PER_IP", 5),
+		UploadBytesPerIP:  getEnvInt64("UPLOAD_BYTES_PER_IP", 200<<20),
+
+		JobWorkers:         getEnvInt("JOB_WORKERS", 2),
+		JobPollInterval:    getEnvDuration("JOB_POLL_INTERVAL", 2*time.Second),
+		JobMaxAttempts:     getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		JobLockTimeout:     getEnvDuration("JOB_LOCK_TIMEOUT", 15*time.Minute),
+		AsyncSignThreshold: getEnvInt64("ASYNC_SIGN_THRESHOLD", 0),
+
+		WebhookPollInterval: getEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		WebhookTimeout:      getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBase:    getEnvDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+		WebhookRetryMax:     getEnvDuration("WEBHOOK_RETRY_MAX", 6*time.Hour),
+		WebhookAllowPrivate: getEnvBool("WEBHOOK_ALLOW_PRIVATE", false),
+
+		TrustedProxies:     getEnv("TRUSTED_PROXIES", ""),
+		RateLimitStore:     getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitRedisURL:  getEnv("RATE_LIMIT_REDIS_URL", "redis://localhost:6379/0"),
+		RateLimitCacheSize: getEnvInt("RATE_LIMIT_CACHE_SIZE", 10000),
+		RateLimitPolicies:  getEnv("RATE_LIMIT_POLICIES", ""),
+
+		LoginMaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures:   getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		LoginFailureWindow:   getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginDelayBase:       getEnvDuration("LOGIN_DELAY_BASE", time.Second),
+		LoginDelayMax:        getEnvDuration("LOGIN_DELAY_MAX", 30*time.Second),
+		LoginCaptchaAfter:    getEnvInt("LOGIN_CAPTCHA_AFTER", 3),
+
+		MFAIssuer:             getEnv("MFA_ISSUER", "Digital Signature System"),
+		MFAEncryptionKey:      getEnv("MFA_ENCRYPTION_KEY", ""),
+		MFAChallengeTTL:       getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+		MFARequiredForSigning: getEnvBool("MFA_REQUIRED_FOR_SIGNING", false),
+
+		WebAuthnRPID:               getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:             getEnv("WEBAUTHN_RP_NAME", "Digital Signature System"),
+		WebAuthnRPOrigins:          getEnv("WEBAUTHN_RP_ORIGINS", "http://localhost:3000"),
+		WebAuthnChallengeTTL:       getEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+		WebAuthnRequiredForSigning: getEnvBool("WEBAUTHN_REQUIRED_FOR_SIGNING", false),
+
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		SchedulerEnabled:         getEnvBool("SCHEDULER_ENABLED", true),
+		SchedulerTick:            getEnvDuration("SCHEDULER_TICK", 30*time.Second),
+		SessionCleanupSchedule:   getEnv("SESSION_CLEANUP_SCHEDULE", "@hourly"),
+		VerificationLogRetention: getEnvDuration("VERIFICATION_LOG_RETENTION", 0),
+		VerificationLogSchedule:  getEnv("VERIFICATION_LOG_RETENTION_SCHEDULE", "30 2 * * *"),
+		AuditLogRetention:        getEnvDuration("AUDIT_LOG_RETENTION", 90*24*time.Hour),
+		AuditLogSchedule:         getEnv("AUDIT_LOG_RETENTION_SCHEDULE", "45 2 * * *"),
+		KeyMaxAge:                getEnvDuration("KEY_MAX_AGE", 365*24*time.Hour),
+		KeyAgeCheckSchedule:      getEnv("KEY_AGE_CHECK_SCHEDULE", "0 6 * * *"),
+
+		OIDCProviders:   loadOIDCProviders(getEnv("OIDC_PROVIDERS", "")),
+		OIDCRedirectURL: getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCStateTTL:    getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+
+		LDAP: loadLDAPConfig(),
+
+		APIKeyDefaultTTL:  getEnvDuration("API_KEY_DEFAULT_TTL", 90*24*time.Hour),
+		APIKeyMaxTTL:      getEnvDuration("API_KEY_MAX_TTL", 365*24*time.Hour),
+		APIKeyRateLimit:   getEnvInt("API_KEY_RATE_LIMIT", 60),
+		APIKeyTouchPeriod: getEnvDuration("API_KEY_TOUCH_PERIOD", time.Minute),
+
+		RBACCacheTTL: getEnvDuration("RBAC_CACHE_TTL", 30*time.Second),
+
+		OrgKeyEncryptionKey: getEnv("ORG_KEY_ENCRYPTION_KEY", ""),
+		OrgKeySize:          getEnvInt("ORG_KEY_SIZE", 2048),
+
+		RegistrationMode: strings.ToLower(getEnv("REGISTRATION_MODE", "open")),
+		InvitationTTL:    getEnvDuration("INVITATION_TTL", 7*24*time.Hour),
+
+		DelegationMaxDuration: getEnvDuration("DELEGATION_MAX_DURATION", 90*24*time.Hour),
+
+		DuplicatePolicy:     strings.ToLower(getEnv("DUPLICATE_POLICY", "reject")),
+		DuplicateSimilarity: getEnvInt("DUPLICATE_SIMILARITY", 85),
+		DuplicateScanLimit:  getEnvInt("DUPLICATE_SCAN_LIMIT", 500),
+
+		GeoIPDatabase:        getEnv("GEOIP_DATABASE", ""),
+		TamperAlertThreshold: getEnvInt("TAMPER_ALERT_THRESHOLD", 5),
+		TamperAlertWindow:    getEnvDuration("TAMPER_ALERT_WINDOW", 24*time.Hour),
+	}
+
+	if config.OIDCRedirectURL == "" {
+		config.OIDCRedirectURL = strings.TrimRight(config.BaseURL, "/") + "/oidc/callback"
 	}
 
 	return config, nil
@@ -57,6 +335,130 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// loadOIDCProviders reads the settings of each provider named in the comma-separated list
+func loadOIDCProviders(names string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		defaultRole := getEnv(prefix+"DEFAULT_ROLE", "user")
+		if defaultRole == "none" {
+			defaultRole = ""
+		}
+
+		providers = append(providers, OIDCProviderConfig{
+			Name:          name,
+			DisplayName:   getEnv(prefix+"DISPLAY_NAME", name),
+			IssuerURL:     getEnv(prefix+"ISSUER", ""),
+			ClientID:      getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:  getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:        strings.Fields(strings.ReplaceAll(getEnv(prefix+"SCOPES", "openid profile email"), ",", " ")),
+			GroupsClaim:   getEnv(prefix+"GROUPS_CLAIM", "groups"),
+			RoleMappings:  parseRoleMappings(getEnv(prefix+"ROLE_MAPPING", "")),
+			DefaultRole:   defaultRole,
+			AutoProvision: getEnvBool(prefix+"AUTO_PROVISION", true),
+			LinkByEmail:   getEnvBool(prefix+"LINK_BY_EMAIL", false),
+		})
+	}
+	return providers
+}
+
+// loadLDAPConfig reads the LDAP_* variables
+func loadLDAPConfig() LDAPConfig {
+	defaultRole := getEnv("LDAP_DEFAULT_ROLE", "user")
+	if defaultRole == "none" {
+		defaultRole = ""
+	}
+
+	return LDAPConfig{
+		URL:                getEnv("LDAP_URL", ""),
+		StartTLS:           getEnvBool("LDAP_START_TLS", false),
+		InsecureSkipVerify: getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		CACertFile:         getEnv("LDAP_CA_CERT_FILE", ""),
+		BindDN:             getEnv("LDAP_BIND_DN", ""),
+		BindPassword:       getEnv("LDAP_BIND_PASSWORD", ""),
+		BaseDN:             getEnv("LDAP_BASE_DN", ""),
+		UserFilter:         getEnv("LDAP_USER_FILTER", "(objectClass=person)"),
+		UsernameAttribute:  getEnv("LDAP_USERNAME_ATTRIBUTE", "uid"),
+		IDAttribute:        getEnv("LDAP_ID_ATTRIBUTE", ""),
+		EmailAttribute:     getEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+		NameAttribute:      getEnv("LDAP_NAME_ATTRIBUTE", "cn"),
+		GroupAttribute:     getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+		GroupBaseDN:        getEnv("LDAP_GROUP_BASE_DN", ""),
+		GroupFilter:        getEnv("LDAP_GROUP_FILTER", "(member={dn})"),
+		RoleMappings:       parseRoleMappings(getEnv("LDAP_ROLE_MAPPING", "")),
+		DefaultRole:        defaultRole,
+		AutoProvision:      getEnvBool("LDAP_AUTO_PROVISION", true),
+		LinkExisting:       getEnvBool("LDAP_LINK_EXISTING", false),
+		Timeout:            getEnvDuration("LDAP_TIMEOUT", 10*time.Second),
+	}
+}
+
+// parseRoleMappings parses "group=role" pairs separated by commas
+func parseRoleMappings(value string) []RoleMapping {
+	var mappings []RoleMapping
+	for _, pair := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			continue
+		}
+		mappings = append(mappings, RoleMapping{Group: group, Role: role})
+	}
+	return mappings
+}
+
+// GetTrustedProxies returns the proxies whose X-Forwarded-For is believed, or nil when unset
+func (c *Config) GetTrustedProxies() []string {
+	if strings.TrimSpace(c.TrustedProxies) == "" {
+		return nil
+	}
+	proxies := strings.Split(c.TrustedProxies, ",")
+	for i, proxy := range proxies {
+		proxies[i] = strings.TrimSpace(proxy)
+	}
+	return proxies
+}
+
 // GetCORSOrigins returns CORS origins as a slice
 func (c *Config) GetCORSOrigins() []string {
 	if c.CORSOrigins == "" {
@@ -68,3 +470,15 @@ func (c *Config) GetCORSOrigins() []string {
 	}
 	return origins
 }
+
+// GetWebAuthnOrigins returns the origins WebAuthn ceremonies may come from
+func (c *Config) GetWebAuthnOrigins() []string {
+	if strings.TrimSpace(c.WebAuthnRPOrigins) == "" {
+		return nil
+	}
+	origins := strings.Split(c.WebAuthnRPOrigins, ",")
+	for i, origin := range origins {
+		origins[i] = strings.TrimSpace(origin)
+	}
+	return origins
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Delegation lets the delegate sign documents on the delegator's behalf while
+// the grant is valid and not revoked
+type Delegation struct {
+	ID          string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	DelegatorID string `json:"delegator_id" gorm:"type:uuid;not null;index:idx_delegations_delegator_id"`
+	DelegateID  string `json:"delegate_id" gorm:"type:uuid;not null;index:idx_delegations_delegate_id"`
+	// OrganizationID is the grant's scope: documents signed for that organization,
+	// or documents signed outside any organization when nil
+	OrganizationID *string   `json:"organization_id,omitempty" gorm:"type:uuid"`
+	ValidFrom      time.Time `json:"valid_from" gorm:"not null"`
+	ValidUntil     time.Time `json:"valid_until" gorm:"not null"`
+	Reason         string    `json:"reason,omitempty"`
+	// RevokedAt and RevokedBy are set once the grant has been withdrawn
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy *string    `json:"revoked_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at"`
+	Delegator *User      `json:"delegator,omitempty" gorm:"foreignKey:DelegatorID"`
+	Delegate  *User      `json:"delegate,omitempty" gorm:"foreignKey:DelegateID"`
+}
+
+// ActiveAt reports whether the grant can be used at t
+func (d *Delegation) ActiveAt(t time.Time) bool {
+	return d.RevokedAt == nil && !t.Before(d.ValidFrom) && t.Before(d.ValidUntil)
+}
+
+func (d *Delegation) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -7,13 +7,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// How much of a document its public verification page shows
+const (
+	// DocumentVisibilityPublic shows the document's details, less the fields
+	// its organization redacts
+	DocumentVisibilityPublic = "public"
+	// DocumentVisibilityMinimal only shows whether the document is valid
+	DocumentVisibilityMinimal = "minimal"
+	// DocumentVisibilityAccessCode shows the details to verifiers holding the
+	// access code printed next to the QR code
+	DocumentVisibilityAccessCode = "access_code"
+)
+
 type Document struct {
 	ID            string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID        string    `json:"user_id" gorm:"not null;index:idx_documents_user_id"`
 	Filename      string    `json:"filename" gorm:"not null"`
 	Issuer        string    `json:"issuer" gorm:"not null"`
 	Title         *string   `json:"title,omitempty" gorm:"index:idx_documents_title"`
-	LetterNumber  *string   `json:"letter_number,omitempty" gorm:"index:idx_documents_letter_number"`
+	LetterNumber  *string   `json:"letter_number,omitempty" gorm:"index:idx_documents_letter_number;uniqueIndex:idx_documents_scheme_letter_number,priority:2"`
 	DocumentHash  string    `json:"document_hash" gorm:"not null;index:idx_documents_hash"`
 	SignatureData string    `json:"signature_data" gorm:"not null"`
 	QRCodeData    string    `json:"qr_code_data" gorm:"not null"`
@@ -22,6 +34,41 @@ type Document struct {
 	FileSize      int64     `json:"file_size"`
 	Status        string    `json:"status" gorm:"default:active"`
 	User          User      `json:"user" gorm:"foreignKey:UserID"`
+	// OrganizationID is the tenant the document was signed for; nil outside any organization
+	OrganizationID *string `json:"organization_id,omitempty" gorm:"type:uuid;index:idx_documents_organization_id"`
+	// KeyID names the organization key that signed the document; empty for the server key
+	KeyID string `json:"key_id,omitempty"`
+	// SignedByID is the delegate who signed on behalf of UserID under DelegationID;
+	// both are nil when the owner signed
+	SignedByID   *string `json:"signed_by_id,omitempty" gorm:"type:uuid;index:idx_documents_signed_by_id"`
+	DelegationID *string `json:"delegation_id,omitempty" gorm:"type:uuid"`
+	// Version counts the times this PDF was signed in the same scope;
+	// PreviousVersionID is the document a forced re-signing superseded
+	Version           int     `json:"version" gorm:"not null;default:1"`
+	PreviousVersionID *string `json:"previous_version_id,omitempty" gorm:"type:uuid;index:idx_documents_previous_version_id"`
+	// LetterNumberSchemeID is the scheme that allocated LetterNumber; nil when
+	// the number was typed in. Allocated numbers are unique per scheme and are
+	// covered by the signature.
+	LetterNumberSchemeID *string `json:"letter_number_scheme_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_documents_scheme_letter_number,priority:1"`
+	// StampedHash is the hash of the signed PDF with the QR code stamped in, so
+	// either file can be verified; empty when no stamped PDF was produced
+	StampedHash string `json:"stamped_hash,omitempty" gorm:"index:idx_documents_stamped_hash"`
+	// Private documents are only verified through their ID and are never
+	// found by uploading the file
+	Private bool `json:"private" gorm:"not null;default:false"`
+	// Visibility is how much the public verification page shows
+	Visibility string `json:"visibility" gorm:"not null;default:public"`
+	// AccessCodeHash is the bcrypt hash of the access code of an access_code document
+	AccessCodeHash string `json:"-"`
+	// ContentText is the text extracted from the PDF; it is searched with the
+	// title, issuer, filename and letter number
+	ContentText string `json:"-" gorm:"type:text"`
+	// Metadata is what was read from the PDF when it was signed; nil for
+	// documents signed before extraction or whose PDF could not be read
+	Metadata *DocumentMetadata `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	// PageText is the extracted text of each page; it is only filled for the
+	// document detail view
+	PageText []string `json:"page_text,omitempty" gorm:"-"`
 }
 
 type VerificationLog struct {
@@ -30,6 +77,7 @@ type VerificationLog struct {
 	VerificationResult string    `json:"verification_result"`
 	VerifiedAt         time.Time `json:"verified_at"`
 	VerifierIP         string    `json:"verifier_ip"`
+	Country            string    `json:"country,omitempty" gorm:"size:2;index"`
 	Details            string    `json:"details" gorm:"type:jsonb"`
 	Document           Document  `json:"document" gorm:"foreignKey:DocumentID"`
 }
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// PageTextSeparator separates the text of consecutive pages in Document.ContentText
+const PageTextSeparator = "\f"
+
+// DocumentMetadata is what was read from the PDF when it was signed
+type DocumentMetadata struct {
+	PDFVersion   string     `json:"pdf_version"`
+	PageCount    int        `json:"page_count"`
+	Encrypted    bool       `json:"encrypted"`
+	Title        string     `json:"title,omitempty"`
+	Author       string     `json:"author,omitempty"`
+	Subject      string     `json:"subject,omitempty"`
+	Keywords     string     `json:"keywords,omitempty"`
+	Creator      string     `json:"creator,omitempty"`
+	Producer     string     `json:"producer,omitempty"`
+	CreationDate *time.Time `json:"creation_date,omitempty"`
+	ModifiedDate *time.Time `json:"modified_date,omitempty"`
+	// XMP is the raw XMP metadata packet of the document catalog
+	XMP           string                 `json:"xmp,omitempty"`
+	Pages         []PageMetadata         `json:"pages,omitempty"`
+	EmbeddedFiles []EmbeddedFileMetadata `json:"embedded_files,omitempty"`
+	// TextTruncated is set when only the start of the document's text was kept
+	TextTruncated bool `json:"text_truncated,omitempty"`
+}
+
+// PageMetadata is the size of a page in points
+type PageMetadata struct {
+	Number   int     `json:"number"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation int64   `json:"rotation,omitempty"`
+}
+
+// EmbeddedFileMetadata describes a file attached to the PDF
+type EmbeddedFileMetadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+	Size        int    `json:"size"`
+}
+
+// PageTexts splits the document's extracted text into its pages
+func (d *Document) PageTexts() []string {
+	if d.ContentText == "" {
+		return nil
+	}
+	return strings.Split(d.ContentText, PageTextSeparator)
+}
@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invitation lets the holder of its token register while signup is invite-only
+type Invitation struct {
+	ID    string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Email string `json:"email" gorm:"not null;index:idx_invitations_email"`
+	// Role is given to the account created with the invitation
+	Role string `json:"role" gorm:"not null;default:user"`
+	// TokenHash is the SHA-256 of the token; the token itself is only shown once
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex:idx_invitations_token_hash"`
+	InvitedBy string    `json:"invited_by" gorm:"type:uuid;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	// AcceptedAt and AcceptedUserID are set once the invitation has been used
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *string    `json:"accepted_user_id,omitempty" gorm:"type:uuid"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
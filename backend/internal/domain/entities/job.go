@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Job status values
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+type Job struct {
+	ID              string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Type            string     `json:"type" gorm:"not null;index:idx_jobs_type"`
+	UserID          string     `json:"user_id" gorm:"index:idx_jobs_user_id"`
+	Status          string     `json:"status" gorm:"not null;default:queued;index:idx_jobs_status_run_at,priority:1"`
+	Payload         string     `json:"-" gorm:"type:jsonb"`
+	Result          string     `json:"result,omitempty" gorm:"type:jsonb"`
+	ResultPath      string     `json:"-"`
+	Error           string     `json:"error,omitempty"`
+	Attempts        int        `json:"attempts"`
+	MaxAttempts     int        `json:"max_attempts"`
+	RunAt           time.Time  `json:"run_at" gorm:"index:idx_jobs_status_run_at,priority:2"`
+	LockedAt        *time.Time `json:"locked_at,omitempty"`
+	LockedBy        string     `json:"-"`
+	CancelRequested bool       `json:"cancel_requested" gorm:"default:false"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsFinished reports whether the job has reached a terminal state
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
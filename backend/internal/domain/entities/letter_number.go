@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LetterNumberScheme numbers an organization's letters from a pattern such as
+// "{seq}/{dept}/{roman_month}/{year}". Its sequence restarts every year.
+type LetterNumberScheme struct {
+	ID             string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_letter_number_schemes_org_code"`
+	// Code names the scheme when signing, such as "PHY-OUT"
+	Code    string `json:"code" gorm:"not null;uniqueIndex:idx_letter_number_schemes_org_code"`
+	Name    string `json:"name" gorm:"not null"`
+	Pattern string `json:"pattern" gorm:"not null"`
+	// Department fills the {dept} placeholder
+	Department string `json:"department"`
+	// Padding is the minimum number of digits of {seq}
+	Padding int `json:"padding" gorm:"not null;default:3"`
+	// IsDefault numbers the organization's documents signed without a letter number
+	IsDefault bool      `json:"is_default" gorm:"not null;default:false"`
+	IsActive  bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LetterNumberCounter is the last sequence number a scheme allocated in a year
+type LetterNumberCounter struct {
+	SchemeID  string    `json:"scheme_id" gorm:"primaryKey;type:uuid"`
+	Year      int       `json:"year" gorm:"primaryKey;autoIncrement:false"`
+	Value     int64     `json:"value" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *LetterNumberScheme) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
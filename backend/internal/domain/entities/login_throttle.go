@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Login throttles are tracked separately per account name and per client address
+const (
+	LoginThrottleScopeUsername = "username"
+	LoginThrottleScopeIP       = "ip"
+)
+
+// LoginThrottle counts recent failed logins for one username or IP address
+type LoginThrottle struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Scope         string     `json:"scope" gorm:"uniqueIndex:idx_login_throttles_scope_key;not null"`
+	Key           string     `json:"key" gorm:"uniqueIndex:idx_login_throttles_scope_key;not null"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at" gorm:"index"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (t *LoginThrottle) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsLocked reports whether the lockout is still in effect at now
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
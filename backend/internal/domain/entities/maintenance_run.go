@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Maintenance runs are started either by their schedule or by an administrator
+const (
+	MaintenanceTriggerSchedule = "schedule"
+	MaintenanceTriggerManual   = "manual"
+)
+
+const (
+	MaintenanceRunRunning   = "running"
+	MaintenanceRunSucceeded = "succeeded"
+	MaintenanceRunFailed    = "failed"
+)
+
+// MaintenanceRun records one execution of a scheduled maintenance task
+type MaintenanceRun struct {
+	ID      string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Task    string `json:"task" gorm:"not null;index:idx_maintenance_runs_task_trigger"`
+	Trigger string `json:"trigger" gorm:"not null;index:idx_maintenance_runs_task_trigger"`
+	// ScheduledFor is the schedule slot the run covers; manual runs use their start time
+	ScheduledFor time.Time  `json:"scheduled_for" gorm:"not null;index:idx_maintenance_runs_task_trigger"`
+	Status       string     `json:"status" gorm:"not null"`
+	Result       string     `json:"result,omitempty" gorm:"type:text"`
+	Error        string     `json:"error,omitempty" gorm:"type:text"`
+	Node         string     `json:"node"`
+	StartedAt    time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (r *MaintenanceRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMFA holds a user's TOTP enrollment. The row exists from the start of enrollment
+// and only protects logins once the first code has been confirmed.
+type UserMFA struct {
+	UserID string `json:"user_id" gorm:"primaryKey;type:uuid"`
+	// Secret is the AES-GCM encrypted base32 TOTP seed
+	Secret  string `json:"-" gorm:"not null"`
+	Enabled bool   `json:"enabled" gorm:"not null;default:false"`
+	// LastUsedStep is the time step of the last accepted code, so a code cannot be replayed
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator is unavailable. Only a hash of the code is stored.
+type MFARecoveryCode struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (c *MFARecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
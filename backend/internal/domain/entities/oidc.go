@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to their account at an OpenID Connect provider or
+// LDAP directory
+type UserIdentity struct {
+	ID       string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID   string `json:"user_id" gorm:"type:uuid;not null;index"`
+	Provider string `json:"provider" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	// Subject is the provider's stable identifier for the account (the sub claim)
+	Subject     string     `json:"subject" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// OIDCAuthRequest holds the state of an authorization request between the redirect
+// to the provider and the callback. Each request can be completed once.
+type OIDCAuthRequest struct {
+	ID       string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Provider string `json:"provider" gorm:"not null"`
+	// StateHash is the SHA-256 (hex) of the state parameter sent to the provider
+	StateHash    string    `json:"-" gorm:"not null;uniqueIndex"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OIDCAuthRequest) TableName() string {
+	return "oidc_auth_requests"
+}
+
+func (r *OIDCAuthRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organization roles held through a membership
+const (
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization key states
+const (
+	OrganizationKeyActive  = "active"
+	OrganizationKeyRetired = "retired"
+)
+
+// Fields of the public verification page an organization can redact
+const (
+	VerificationFieldFilename     = "filename"
+	VerificationFieldIssuer       = "issuer"
+	VerificationFieldOrganization = "organization"
+	VerificationFieldTitle        = "title"
+	VerificationFieldLetterNumber = "letter_number"
+	VerificationFieldFileSize     = "file_size"
+	VerificationFieldDocumentHash = "document_hash"
+	VerificationFieldQRCodeData   = "qr_code_data"
+	VerificationFieldSignedBy     = "signed_by"
+)
+
+// Organization is a tenant, such as a faculty, with its own issuer identity,
+// signing key, stamp branding and verification domain
+type Organization struct {
+	ID   string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Slug string `json:"slug" gorm:"not null;uniqueIndex:idx_organizations_slug"`
+	Name string `json:"name" gorm:"not null"`
+	// IssuerName replaces the issuer given when signing, so every document
+	// carries the organization's identity
+	IssuerName string `json:"issuer_name"`
+	// VerificationBaseURL is used in verification links instead of BASE_URL
+	VerificationBaseURL string `json:"verification_base_url"`
+	// StampLabel is printed in the centre of the QR code; the issuer is used when empty
+	StampLabel string `json:"stamp_label"`
+	// Stamp position and width in points on the last page; zero uses the default
+	StampX     float64 `json:"stamp_x"`
+	StampY     float64 `json:"stamp_y"`
+	StampWidth float64 `json:"stamp_width"`
+	// VerificationRedactions are the verification fields hidden on the public
+	// page of the organization's documents
+	VerificationRedactions []string  `json:"verification_redactions" gorm:"type:jsonb;serializer:json"`
+	IsActive               bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// OrganizationMember gives a user access to an organization's documents
+type OrganizationMember struct {
+	ID             string        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string        `json:"organization_id" gorm:"type:uuid;not null;uniqueIndex:idx_organization_members_org_user"`
+	UserID         string        `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_organization_members_org_user;index:idx_organization_members_user_id"`
+	Role           string        `json:"role" gorm:"not null;default:member"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	User           *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// OrganizationKey is an organization's RSA signing key. Retired keys no longer
+// sign but still verify the documents they signed.
+type OrganizationKey struct {
+	ID             string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	OrganizationID string `json:"organization_id" gorm:"type:uuid;not null;index:idx_organization_keys_org_id"`
+	KeyID          string `json:"key_id" gorm:"not null;uniqueIndex:idx_organization_keys_key_id"`
+	Algorithm      string `json:"algorithm" gorm:"not null"`
+	PublicKey      string `json:"public_key" gorm:"not null"`
+	// PrivateKey is the PEM encoded private key, encrypted at rest
+	PrivateKey string     `json:"-" gorm:"not null"`
+	Status     string     `json:"status" gorm:"not null;default:active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (k *OrganizationKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Permissions a role can grant
+const (
+	PermissionDocumentSign    = "document:sign"
+	PermissionDocumentRevoke  = "document:revoke"
+	PermissionDocumentReadAny = "document:read:any"
+	PermissionKeyRotate       = "key:rotate"
+	PermissionUserManage      = "user:manage"
+	PermissionAuditRead       = "audit:read"
+	PermissionMaintenanceRun  = "maintenance:run"
+)
+
+// Permissions lists every permission a role can be granted
+var Permissions = []string{
+	PermissionDocumentSign,
+	PermissionDocumentRevoke,
+	PermissionDocumentReadAny,
+	PermissionKeyRotate,
+	PermissionUserManage,
+	PermissionAuditRead,
+	PermissionMaintenanceRun,
+}
+
+// Built-in roles, created at startup
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Role is a named bundle of permissions; users are assigned a role through User.Role
+type Role struct {
+	ID          string   `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string   `json:"name" gorm:"not null;uniqueIndex:idx_roles_name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" gorm:"type:jsonb;serializer:json"`
+	// BuiltIn roles cannot be deleted
+	BuiltIn   bool      `json:"built_in" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// HasPermission reports whether the role grants permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, granted := range r.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -7,11 +7,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// Session is a login on one device. SessionToken is the latest access token issued
+// for it; RefreshToken is the per-session secret refresh tokens are derived from,
+// and RefreshGeneration counts rotations so a replayed refresh token is recognized.
 type Session struct {
-	ID           string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID       string    `json:"user_id" gorm:"not null;index:idx_sessions_user_id"`
-	SessionToken string    `json:"session_token" gorm:"uniqueIndex:idx_sessions_token;not null"`
-	RefreshToken string    `json:"refresh_token" gorm:"uniqueIndex:idx_sessions_refresh_token;not null"`
+	ID                string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID            string    `json:"user_id" gorm:"not null;index:idx_sessions_user_id"`
+	SessionToken      string    `json:"-" gorm:"uniqueIndex:idx_sessions_token;not null"`
+	RefreshToken      string    `json:"-" gorm:"uniqueIndex:idx_sessions_refresh_token;not null"`
+	RefreshGeneration int       `json:"-" gorm:"not null;default:0"`
+	// MFA is set when the session was opened with a second factor
+	MFA          bool      `json:"mfa" gorm:"not null;default:false"`
+	IPAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	LastAccessed time.Time `json:"last_accessed"`
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upload purposes; a finished upload can only feed the flow it was created for
+const (
+	UploadPurposeSign   = "sign"
+	UploadPurposeVerify = "verify"
+)
+
+// Upload status values
+const (
+	UploadStatusUploading = "uploading"
+	UploadStatusCompleted = "completed"
+)
+
+// UploadSession tracks a resumable (tus) upload; the bytes live under the storage directory
+type UploadSession struct {
+	ID         string            `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Purpose    string            `json:"purpose" gorm:"not null"`
+	UserID     string            `json:"user_id,omitempty" gorm:"index:idx_upload_sessions_user_id"`
+	DocumentID string            `json:"document_id,omitempty"`
+	ClientIP   string            `json:"-" gorm:"index:idx_upload_sessions_client_ip"`
+	Length     int64             `json:"length" gorm:"not null"`
+	Offset     int64             `json:"offset" gorm:"not null;default:0"`
+	Metadata   map[string]string `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	Status     string            `json:"status" gorm:"not null;default:uploading"`
+	ExpiresAt  time.Time         `json:"expires_at" gorm:"index:idx_upload_sessions_expires_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+func (u *UploadSession) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsComplete reports whether every byte of the upload has been received
+func (u *UploadSession) IsComplete() bool {
+	return u.Status == UploadStatusCompleted
+}
@@ -17,6 +17,9 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	IsActive     bool      `json:"is_active" gorm:"default:true"`
+	// ServiceAccount marks an account used by another system through API keys;
+	// it has no password and cannot log in
+	ServiceAccount bool `json:"service_account" gorm:"default:false"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
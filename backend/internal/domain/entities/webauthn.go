@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebAuthn challenge purposes. A confirmation challenge authorizes changes to the
+// user's registered credentials.
+const (
+	WebAuthnPurposeRegistration = "registration"
+	WebAuthnPurposeSigning      = "signing"
+	WebAuthnPurposeBatchSigning = "batch_signing"
+	WebAuthnPurposeConfirmation = "confirmation"
+)
+
+// WebAuthnCredential is a security key or passkey registered by a user to confirm signing
+type WebAuthnCredential struct {
+	ID     string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID string `json:"user_id" gorm:"type:uuid;not null;index"`
+	// CredentialID is the authenticator's credential id, base64url encoded
+	CredentialID    string `json:"credential_id" gorm:"not null;uniqueIndex"`
+	PublicKey       []byte `json:"-" gorm:"not null"`
+	AttestationType string `json:"attestation_type"`
+	// Transports is a comma separated list of the transports the authenticator reported
+	Transports     string     `json:"transports"`
+	AAGUID         string     `json:"aaguid" gorm:"column:aaguid"`
+	SignCount      uint32     `json:"-" gorm:"not null;default:0"`
+	BackupEligible bool       `json:"backup_eligible" gorm:"not null;default:false"`
+	BackupState    bool       `json:"backup_state" gorm:"not null;default:false"`
+	Name           string     `json:"name" gorm:"not null"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+func (c *WebAuthnCredential) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// WebAuthnChallenge holds the server side state of a ceremony between its begin and
+// finish requests. Each challenge can be consumed once.
+type WebAuthnChallenge struct {
+	ID      string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID  string `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose string `json:"purpose" gorm:"not null"`
+	// SessionData is the library's JSON encoded ceremony state
+	SessionData string `json:"-" gorm:"type:text;not null"`
+	// Nonce and DocumentHash (hex) are what a signing challenge was derived from
+	Nonce        string    `json:"-"`
+	DocumentHash string    `json:"document_hash,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (WebAuthnChallenge) TableName() string {
+	return "webauthn_challenges"
+}
+
+func (c *WebAuthnChallenge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook event types
+const (
+	WebhookEventDocumentSigned   = "document.signed"
+	WebhookEventDocumentRevoked  = "document.revoked"
+	WebhookEventDocumentVerified = "document.verified"
+	// WebhookEventDocumentForgerySuspected is raised when altered copies of a
+	// document are verified repeatedly
+	WebhookEventDocumentForgerySuspected = "document.forgery_suspected"
+	WebhookEventAll                      = "*"
+)
+
+// Webhook delivery status values
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      string    `json:"user_id" gorm:"not null;index:idx_webhook_subscriptions_user_id"`
+	URL         string    `json:"url" gorm:"not null"`
+	Secret      string    `json:"-" gorm:"not null"`
+	Events      []string  `json:"events" gorm:"type:jsonb;serializer:json"`
+	Description string    `json:"description,omitempty"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// OrganizationID makes this an organization's subscription, managed by its admins and sent
+	// events for its documents; UserID then records who created it. Nil for a user's own
+	OrganizationID *string `json:"organization_id,omitempty" gorm:"type:uuid;index:idx_webhook_subscriptions_organization_id"`
+}
+
+type WebhookDelivery struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	SubscriptionID string     `json:"subscription_id" gorm:"not null;index:idx_webhook_deliveries_subscription_id"`
+	UserID         string     `json:"user_id" gorm:"not null;index:idx_webhook_deliveries_user_id"`
+	EventID        string     `json:"event_id" gorm:"not null"`
+	EventType      string     `json:"event_type" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:jsonb"`
+	Status         string     `json:"status" gorm:"not null;default:pending;index:idx_webhook_deliveries_status_next,priority:1"`
+	Attempts       int        `json:"attempts"`
+	MaxAttempts    int        `json:"max_attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_status_next,priority:2"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `json:"response_body,omitempty"`
+	DurationMs     int64      `json:"duration_ms,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	// OrganizationID is copied from the subscription
+	OrganizationID *string `json:"organization_id,omitempty" gorm:"type:uuid;index:idx_webhook_deliveries_organization_id"`
+}
+
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes reports whether the subscription wants the given event type
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == WebhookEventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
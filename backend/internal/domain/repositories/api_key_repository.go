@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id string) (*entities.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]*entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+	// TouchLastUsed records a use of the key without rewriting the rest of the row
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time, ipAddress string) error
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type BatchJobRepository interface {
+	Create(ctx context.Context, job *entities.BatchJob) error
+	GetByID(ctx context.Context, id string) (*entities.BatchJob, error)
+	Update(ctx context.Context, job *entities.BatchJob) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *entities.Delegation) error
+	// GetByID returns the grant with its delegator and delegate
+	GetByID(ctx context.Context, id string) (*entities.Delegation, error)
+	// ListByUser returns the grants the user gave or received, newest first
+	ListByUser(ctx context.Context, userID string) ([]*entities.Delegation, error)
+	// FindActive returns an unrevoked grant from delegator to delegate that covers
+	// the organization ("" for none) at the given time
+	FindActive(ctx context.Context, delegatorID, delegateID, organizationID string, at time.Time) (*entities.Delegation, error)
+	// Revoke marks the grant revoked if it is not already and reports whether it was
+	Revoke(ctx context.Context, id, revokedBy string, revokedAt time.Time) (bool, error)
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"digital-signature-system/internal/domain/entities"
 )
@@ -12,11 +13,85 @@ type DocumentFilter struct {
 	Status   string
 }
 
+// Sort orders for DocumentSearch
+const (
+	DocumentSortCreatedAt = "created_at"
+	DocumentSortTitle     = "title"
+	// DocumentSortRelevance ranks full-text matches; it needs a query
+	DocumentSortRelevance = "relevance"
+)
+
+// DocumentSearch filters, sorts and pages a document search
+type DocumentSearch struct {
+	// UserID limits the search to documents the user owns or signed; empty
+	// searches every document in the context's organization
+	UserID string
+	// Query is matched against the title, issuer, filename, letter number and
+	// extracted text
+	Query  string
+	Issuer string
+	Status string
+	// CreatedFrom and CreatedTo bound the signing time; CreatedTo is exclusive
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Ascending   bool
+	Limit       int
+	// After continues the search from the last document of the previous page
+	After *DocumentCursor
+}
+
+// DocumentCursor is the sort key and ID of the last document on a page
+type DocumentCursor struct {
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+// FacetCount is the number of matching documents with a value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// DocumentFacets counts the matching documents by issuer, status and signing
+// month (YYYY-MM). Each facet ignores its own filter so other values stay visible.
+type DocumentFacets struct {
+	Issuers  []FacetCount `json:"issuers"`
+	Statuses []FacetCount `json:"statuses"`
+	Months   []FacetCount `json:"months"`
+}
+
+// DocumentSearchResult is one page of a document search
+type DocumentSearchResult struct {
+	Documents []*entities.Document
+	Total     int64
+	Facets    DocumentFacets
+	// Next continues after the last document; nil on the last page
+	Next *DocumentCursor
+}
+
 type DocumentRepository interface {
 	Create(ctx context.Context, doc *entities.Document) error
+	// CreateNumbered creates the document with the next letter number of the
+	// scheme's sequence for year. number receives the allocated sequence value
+	// inside the transaction and finishes the document; when it or the insert
+	// fails the sequence is left unchanged, so allocated numbers have no gaps.
+	CreateNumbered(ctx context.Context, doc *entities.Document, schemeID string, year int, number func(seq int64) error) error
 	GetByID(ctx context.Context, id string) (*entities.Document, error)
+	// GetByUserID returns the documents the user owns or signed on behalf of their owner
 	GetByUserID(ctx context.Context, userID string, filter DocumentFilter) ([]*entities.Document, int64, error)
-	GetByHash(ctx context.Context, hash string) (*entities.Document, error)
+	// GetByHash returns the most recently signed document with the hash that
+	// the user owns or signed; an empty userID searches every user in the
+	// context's organization
+	GetByHash(ctx context.Context, hash, userID string) (*entities.Document, error)
+	// GetVerifiableByHash returns up to limit active documents that are not
+	// private whose original or stamped PDF has the hash, newest first
+	GetVerifiableByHash(ctx context.Context, hash string, limit int) ([]*entities.Document, error)
+	// GetRecentWithText returns up to limit active documents with extracted text,
+	// newest first, that the user owns or signed; an empty userID returns those
+	// of every user in the context's organization
+	GetRecentWithText(ctx context.Context, userID string, limit int) ([]*entities.Document, error)
+	Search(ctx context.Context, search DocumentSearch) (*DocumentSearchResult, error)
 	Update(ctx context.Context, doc *entities.Document) error
 	Delete(ctx context.Context, id string) error
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *entities.Invitation) error
+	GetByID(ctx context.Context, id string) (*entities.Invitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Invitation, error)
+	// ListPending returns invitations that have not been accepted, newest first
+	ListPending(ctx context.Context) ([]*entities.Invitation, error)
+	// Claim marks the invitation accepted by userID if it is still unused and
+	// reports whether it was
+	Claim(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error)
+	// Release makes a claimed invitation usable again when the account could not be created
+	Release(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
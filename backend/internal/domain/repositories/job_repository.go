@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+// ErrJobLockLost is returned when a worker touches a job it no longer holds,
+// because the lock went stale and the job was requeued or claimed elsewhere
+var ErrJobLockLost = errors.New("job is no longer locked by this worker")
+
+type JobRepository interface {
+	Create(ctx context.Context, job *entities.Job) error
+	GetByID(ctx context.Context, id string) (*entities.Job, error)
+	GetByUserID(ctx context.Context, userID string, limit int) ([]*entities.Job, error)
+	Update(ctx context.Context, job *entities.Job) error
+	// ClaimNext locks the oldest runnable job of the given types for the worker
+	ClaimNext(ctx context.Context, workerID string, types []string) (*entities.Job, error)
+	// Heartbeat refreshes the job lock and reports whether cancellation was
+	// requested; it fails with ErrJobLockLost once the worker lost the job
+	Heartbeat(ctx context.Context, id, workerID string) (bool, error)
+	// RequestCancel flags a queued or running job for cancellation, cancelling
+	// a queued one outright, and reports whether the job was still unfinished
+	RequestCancel(ctx context.Context, id string) (bool, error)
+	// Finish stores the outcome of the worker's run and releases the lock
+	// without touching cancel_requested. A job headed back to the queue is
+	// cancelled instead when cancellation was requested during the run. It
+	// fails with ErrJobLockLost once the worker lost the job.
+	Finish(ctx context.Context, job *entities.Job, workerID string) error
+	// RequeueStale returns running jobs whose lock is older than the cutoff to the queue
+	RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+}
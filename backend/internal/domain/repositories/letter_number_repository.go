@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type LetterNumberRepository interface {
+	// CreateScheme stores a new scheme; a default scheme replaces the
+	// organization's previous default
+	CreateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error
+	GetScheme(ctx context.Context, organizationID, id string) (*entities.LetterNumberScheme, error)
+	GetSchemeByCode(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error)
+	// GetDefaultScheme returns the organization's active default scheme, if any
+	GetDefaultScheme(ctx context.Context, organizationID string) (*entities.LetterNumberScheme, error)
+	ListSchemes(ctx context.Context, organizationID string) ([]*entities.LetterNumberScheme, error)
+	// UpdateScheme saves the scheme; a default scheme replaces the
+	// organization's previous default
+	UpdateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error
+	// GetCounter returns the last sequence number the scheme allocated in the
+	// year, or 0 when it allocated none
+	GetCounter(ctx context.Context, schemeID string, year int) (int64, error)
+}
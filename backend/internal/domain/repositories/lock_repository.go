@@ -0,0 +1,10 @@
+package repositories
+
+import "context"
+
+// LockRepository hands out named locks shared by every instance of the application
+type LockRepository interface {
+	// TryLock takes the named lock without waiting. It returns a release function
+	// when the lock was taken and nil when another holder has it.
+	TryLock(ctx context.Context, name string) (func(), error)
+}
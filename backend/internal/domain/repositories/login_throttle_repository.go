@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, scope, key string) (*entities.LoginThrottle, error)
+	GetByID(ctx context.Context, id string) (*entities.LoginThrottle, error)
+	// RecordFailure atomically adds one failure, starting the count over when the last
+	// failure is older than staleBefore or a previous lockout has expired
+	RecordFailure(ctx context.Context, scope, key string, now, staleBefore time.Time) (*entities.LoginThrottle, error)
+	// Lock sets the lockout unless one is already in effect and reports whether it did
+	Lock(ctx context.Context, id string, now, until time.Time) (bool, error)
+	ListLocked(ctx context.Context, now time.Time) ([]*entities.LoginThrottle, error)
+	Clear(ctx context.Context, scope, key string) error
+	Delete(ctx context.Context, id string) error
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
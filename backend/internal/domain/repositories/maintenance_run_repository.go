@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MaintenanceRunRepository interface {
+	Create(ctx context.Context, run *entities.MaintenanceRun) error
+	Update(ctx context.Context, run *entities.MaintenanceRun) error
+	// GetLatest returns the task's run with the latest slot for the trigger, or nil
+	GetLatest(ctx context.Context, task, trigger string) (*entities.MaintenanceRun, error)
+	// List returns runs newest first, optionally for one task, with the total count
+	List(ctx context.Context, task string, limit, offset int) ([]*entities.MaintenanceRun, int64, error)
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MFARepository interface {
+	Get(ctx context.Context, userID string) (*entities.UserMFA, error)
+	// Save creates or replaces the user's enrollment
+	Save(ctx context.Context, mfa *entities.UserMFA) error
+	// Delete removes the enrollment together with its recovery codes
+	Delete(ctx context.Context, userID string) error
+	// UseStep records step as used unless it is not newer than the last one, reporting whether it was
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UseRecoveryCode marks an unused code as used and reports whether one matched
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int64, error)
+}
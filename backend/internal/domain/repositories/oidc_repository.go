@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type OIDCRepository interface {
+	CreateAuthRequest(ctx context.Context, request *entities.OIDCAuthRequest) error
+	// ConsumeAuthRequest deletes and returns the unexpired request with the state hash,
+	// or nil when there is none; of two concurrent calls only one gets the request
+	ConsumeAuthRequest(ctx context.Context, stateHash string, now time.Time) (*entities.OIDCAuthRequest, error)
+	DeleteExpiredAuthRequests(ctx context.Context, now time.Time) (int64, error)
+	GetIdentity(ctx context.Context, provider, subject string) (*entities.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *entities.UserIdentity) error
+	UpdateIdentity(ctx context.Context, identity *entities.UserIdentity) error
+	DeleteIdentity(ctx context.Context, id string) error
+}
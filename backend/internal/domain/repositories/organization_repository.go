@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *entities.Organization) error
+	GetByID(ctx context.Context, id string) (*entities.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*entities.Organization, error)
+	List(ctx context.Context) ([]*entities.Organization, error)
+	Update(ctx context.Context, org *entities.Organization) error
+
+	GetMember(ctx context.Context, organizationID, userID string) (*entities.OrganizationMember, error)
+	ListMembers(ctx context.Context, organizationID string) ([]*entities.OrganizationMember, error)
+	// ListMemberships returns the user's memberships in active organizations
+	ListMemberships(ctx context.Context, userID string) ([]*entities.OrganizationMember, error)
+	SaveMember(ctx context.Context, member *entities.OrganizationMember) error
+	DeleteMember(ctx context.Context, id string) error
+
+	CreateKey(ctx context.Context, key *entities.OrganizationKey) error
+	GetActiveKey(ctx context.Context, organizationID string) (*entities.OrganizationKey, error)
+	GetKey(ctx context.Context, organizationID, keyID string) (*entities.OrganizationKey, error)
+	ListKeys(ctx context.Context, organizationID string) ([]*entities.OrganizationKey, error)
+	// RotateKey retires the organization's active keys and stores key as the
+	// active one in a single transaction
+	RotateKey(ctx context.Context, key *entities.OrganizationKey, retiredAt time.Time) error
+}
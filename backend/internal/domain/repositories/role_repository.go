@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *entities.Role) error
+	GetByName(ctx context.Context, name string) (*entities.Role, error)
+	List(ctx context.Context) ([]*entities.Role, error)
+	Update(ctx context.Context, role *entities.Role) error
+	Delete(ctx context.Context, id string) error
+}
@@ -2,16 +2,26 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"digital-signature-system/internal/domain/entities"
 )
 
 type SessionRepository interface {
 	Create(ctx context.Context, session *entities.Session) error
+	GetByID(ctx context.Context, id string) (*entities.Session, error)
 	GetByToken(ctx context.Context, token string) (*entities.Session, error)
 	GetByUserID(ctx context.Context, userID string) ([]*entities.Session, error)
 	Update(ctx context.Context, session *entities.Session) error
+	// Touch records activity on a session without rewriting its refresh state
+	Touch(ctx context.Context, id string, accessedAt time.Time) error
+	// Rotate stores the session's new tokens if its refresh generation is still
+	// fromGeneration, and reports whether it was
+	Rotate(ctx context.Context, session *entities.Session, fromGeneration int) (bool, error)
 	Delete(ctx context.Context, token string) error
-	DeleteByUserID(ctx context.Context, userID string) error
+	// DeleteByID deletes one of a user's sessions and reports whether it existed
+	DeleteByID(ctx context.Context, userID, id string) (bool, error)
+	// DeleteByUserID deletes a user's sessions except those listed in keepIDs
+	DeleteByUserID(ctx context.Context, userID string, keepIDs ...string) (int64, error)
 	DeleteExpired(ctx context.Context) error
-}
\ No newline at end of file
+}
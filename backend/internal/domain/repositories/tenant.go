@@ -0,0 +1,31 @@
+package repositories
+
+import "context"
+
+type tenantContextKey struct{}
+
+type tenant struct {
+	organizationID string
+	all            bool
+}
+
+// WithOrganization scopes tenant data read or written with the returned context
+// to one organization. An empty organizationID scopes it to documents signed
+// outside any organization.
+func WithOrganization(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant{organizationID: organizationID})
+}
+
+// WithAllOrganizations lifts tenant scoping, for public verification and
+// maintenance that must see every organization's data
+func WithAllOrganizations(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant{all: true})
+}
+
+// OrganizationFromContext returns the organization ctx is scoped to and whether
+// scoping was lifted. A context without a tenant is scoped to documents signed
+// outside any organization.
+func OrganizationFromContext(ctx context.Context) (organizationID string, all bool) {
+	t, _ := ctx.Value(tenantContextKey{}).(tenant)
+	return t.organizationID, t.all
+}
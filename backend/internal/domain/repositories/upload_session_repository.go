@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *entities.UploadSession) error
+	GetByID(ctx context.Context, id string) (*entities.UploadSession, error)
+	Update(ctx context.Context, session *entities.UploadSession) error
+	Delete(ctx context.Context, id string) error
+	// CountUnexpired returns how many unexpired anonymous uploads the client IP
+	// holds and their declared length in bytes
+	CountUnexpired(ctx context.Context, clientIP string, now time.Time) (int64, int64, error)
+	// GetExpired returns sessions whose expiry is before the cutoff, oldest first
+	GetExpired(ctx context.Context, before time.Time, limit int) ([]*entities.UploadSession, error)
+}
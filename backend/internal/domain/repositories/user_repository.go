@@ -6,6 +6,15 @@ import (
 	"digital-signature-system/internal/domain/entities"
 )
 
+// UserFilter narrows a user search. Query matches username, full name or email.
+type UserFilter struct {
+	Query    string
+	Role     string
+	IsActive *bool
+	Page     int
+	PageSize int
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
 	GetByID(ctx context.Context, id string) (*entities.User, error)
@@ -13,4 +22,7 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id string) error
+	ListServiceAccounts(ctx context.Context) ([]*entities.User, error)
+	ListByRole(ctx context.Context, role string) ([]*entities.User, error)
+	Search(ctx context.Context, filter UserFilter) ([]*entities.User, int64, error)
 }
\ No newline at end of file
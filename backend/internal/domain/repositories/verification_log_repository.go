@@ -2,12 +2,60 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"digital-signature-system/internal/domain/entities"
 )
 
+// Groupings of verification statistics
+const (
+	VerificationGroupDocument = "document"
+	// VerificationGroupDay groups by the UTC day of the verification (YYYY-MM-DD)
+	VerificationGroupDay     = "day"
+	VerificationGroupIssuer  = "issuer"
+	VerificationGroupCountry = "country"
+)
+
+// VerificationStatsFilter selects the verifications counted
+type VerificationStatsFilter struct {
+	// UserID limits the counts to documents the user owns or signed; empty
+	// counts those of every user in the context's organization
+	UserID     string
+	DocumentID string
+	Issuer     string
+	// From and To bound the verification time; To is exclusive
+	From *time.Time
+	To   *time.Time
+	// MinContentChanged leaves out groups with fewer qr_valid_content_changed results
+	MinContentChanged int64
+	// Limit bounds the number of groups; 0 returns all of them
+	Limit int
+}
+
+// VerificationStats counts the verifications of a group by outcome
+type VerificationStats struct {
+	// Key is the document ID, day, issuer or country code of the group; it
+	// is empty for the totals and for verifications without a country
+	Key string `json:"key"`
+	// Label is the title, or else the filename, of a document group
+	Label          string `json:"label,omitempty"`
+	Total          int64  `json:"total"`
+	Valid          int64  `json:"valid"`
+	ContentChanged int64  `json:"content_changed"`
+	Invalid        int64  `json:"invalid"`
+	Error          int64  `json:"error"`
+	// UniqueVerifiers counts distinct verifier IP addresses
+	UniqueVerifiers int64 `json:"unique_verifiers"`
+}
+
 type VerificationLogRepository interface {
 	Create(ctx context.Context, log *entities.VerificationLog) error
 	GetByDocumentID(ctx context.Context, docID string) ([]*entities.VerificationLog, error)
 	GetByID(ctx context.Context, id string) (*entities.VerificationLog, error)
-}
\ No newline at end of file
+	// DeleteBefore removes logs of verifications made before the cutoff
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+	// CountVerifications counts the verifications of documents in the context's
+	// organization, grouped by one of the VerificationGroup values and ordered
+	// by day or by most verifications. An empty groupBy returns a single total.
+	CountVerifications(ctx context.Context, filter VerificationStatsFilter, groupBy string) ([]VerificationStats, error)
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type WebAuthnRepository interface {
+	CreateCredential(ctx context.Context, credential *entities.WebAuthnCredential) error
+	ListCredentials(ctx context.Context, userID string) ([]*entities.WebAuthnCredential, error)
+	CountCredentials(ctx context.Context, userID string) (int64, error)
+	// DeleteCredential removes one of the user's credentials and reports whether it existed
+	DeleteCredential(ctx context.Context, userID, id string) (bool, error)
+	// UpdateCredentialUse stores the sign count and backup state from a successful assertion
+	UpdateCredentialUse(ctx context.Context, credential *entities.WebAuthnCredential, usedAt time.Time) error
+	CreateChallenge(ctx context.Context, challenge *entities.WebAuthnChallenge) error
+	// ConsumeChallenge deletes and returns an unexpired challenge of the user's, or nil
+	// when there is none; of two concurrent calls only one gets the challenge
+	ConsumeChallenge(ctx context.Context, userID, id, purpose string, now time.Time) (*entities.WebAuthnChallenge, error)
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, sub *entities.WebhookSubscription) error
+	GetByID(ctx context.Context, id string) (*entities.WebhookSubscription, error)
+	// GetByUserID returns the user's own subscriptions, not those of their organizations
+	GetByUserID(ctx context.Context, userID string) ([]*entities.WebhookSubscription, error)
+	GetByOrganizationID(ctx context.Context, organizationID string) ([]*entities.WebhookSubscription, error)
+	Update(ctx context.Context, sub *entities.WebhookSubscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryFilter narrows the delivery log; OrganizationID selects an organization's
+// deliveries, otherwise UserID selects the user's own
+type WebhookDeliveryFilter struct {
+	UserID         string
+	OrganizationID string
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *entities.WebhookDelivery) error
+	GetByID(ctx context.Context, id string) (*entities.WebhookDelivery, error)
+	List(ctx context.Context, filter WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *entities.WebhookDelivery) error
+	// ClaimDue leases up to limit pending deliveries until leaseUntil so other dispatchers skip them
+	ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]*entities.WebhookDelivery, error)
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrInvalidAPIKey          = errors.New("invalid API key")
+	ErrAPIKeyExpired          = errors.New("API key has expired")
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+	ErrAPIKeyScopeDenied      = errors.New("API key does not permit this operation")
+	ErrInvalidAPIKeyRequest   = errors.New("invalid API key request")
+	ErrServiceAccountNotFound = errors.New("service account not found")
+)
+
+const (
+	// apiKeyPrefix starts every key so leaked keys are easy to recognise and scan for
+	apiKeyPrefix = "dss_"
+	// apiKeyIDLength is the number of hex characters after apiKeyPrefix that identify a key
+	apiKeyIDLength = 12
+	// serviceAccountEmailDomain is used for service accounts created without an email
+	serviceAccountEmailDomain = "service-accounts.invalid"
+)
+
+// CreateServiceAccountRequest describes a new service account
+type CreateServiceAccountRequest struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// CreateAPIKeyRequest describes a new API key for a service account
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt defaults to the configured key lifetime
+	ExpiresAt *time.Time `json:"expires_at"`
+	// RateLimit is in requests per minute; nil uses the configured default and 0
+	// leaves only the API-wide policies in force
+	RateLimit *int `json:"rate_limit"`
+}
+
+// APIKeyService manages service accounts and authenticates the API keys they call the API with
+type APIKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	config     *config.Config
+	now        func() time.Time
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository, cfg *config.Config) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		config:     cfg,
+		now:        time.Now,
+	}
+}
+
+// IsAPIKey reports whether a credential has the form of an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyPrefix)
+}
+
+// CreateServiceAccount creates an account without a password for keys to act as
+func (s *APIKeyService) CreateServiceAccount(ctx context.Context, req *CreateServiceAccountRequest) (*entities.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidAPIKeyRequest)
+	}
+	fullName := strings.TrimSpace(req.FullName)
+	if fullName == "" {
+		fullName = username
+	}
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		email = strings.ToLower(username) + "@" + serviceAccountEmailDomain
+	}
+
+	if existing, err := s.userRepo.GetByUsername(ctx, username); err != nil {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	} else if existing != nil {
+		return nil, ErrUserAlreadyExists
+	}
+	if existing, err := s.userRepo.GetByEmail(ctx, email); err != nil {
+		return nil, fmt.Errorf("failed to check existing email: %w", err)
+	} else if existing != nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	now := s.now()
+	user := &entities.User{
+		Username:       username,
+		FullName:       fullName,
+		Email:          email,
+		Role:           "user",
+		IsActive:       true,
+		ServiceAccount: true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+	return user, nil
+}
+
+// ListServiceAccounts returns every service account
+func (s *APIKeyService) ListServiceAccounts(ctx context.Context) ([]*entities.User, error) {
+	users, err := s.userRepo.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return users, nil
+}
+
+// CreateKey issues a key for the service account. The key itself is returned only
+// here; afterwards just its prefix and hash are known.
+func (s *APIKeyService) CreateKey(ctx context.Context, serviceAccountID, createdBy string, req *CreateAPIKeyRequest) (*entities.APIKey, string, error) {
+	if _, err := s.getServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, "", err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	expiresAt, err := s.keyExpiry(req.ExpiresAt, now)
+	if err != nil {
+		return nil, "", err
+	}
+	rateLimit := s.config.APIKeyRateLimit
+	if req.RateLimit != nil {
+		if *req.RateLimit < 0 {
+			return nil, "", fmt.Errorf("%w: rate_limit must not be negative", ErrInvalidAPIKeyRequest)
+		}
+		rateLimit = *req.RateLimit
+	}
+
+	prefix, rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &entities.APIKey{
+		UserID:    serviceAccountID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(rawKey),
+		Scopes:    scopes,
+		RateLimit: rateLimit,
+		ExpiresAt: expiresAt,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, rawKey, nil
+}
+
+// ListKeys returns the keys of a service account, including revoked and expired ones
+func (s *APIKeyService) ListKeys(ctx context.Context, serviceAccountID string) ([]*entities.APIKey, error) {
+	if _, err := s.getServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+	keys, err := s.apiKeyRepo.ListByUser(ctx, serviceAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops a key from authenticating; revoking a revoked key is a no-op
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID string) (*entities.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := s.now()
+	key.RevokedAt = &now
+	key.UpdatedAt = now
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return key, nil
+}
+
+// Authenticate resolves a raw key to its service account and records the use
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey, ipAddress string) (*entities.User, *entities.APIKey, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashAPIKey(rawKey)), []byte(key.KeyHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if key.RevokedAt != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if !key.IsUsable(now) {
+		return nil, nil, ErrAPIKeyExpired
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	if user == nil || !user.ServiceAccount {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+
+	// Busy keys would otherwise write to the database on every request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= s.config.APIKeyTouchPeriod {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now, ipAddress); err != nil {
+			fmt.Printf("Warning: failed to record use of API key %s: %v\n", key.Prefix, err)
+		} else {
+			key.LastUsedAt = &now
+			key.LastUsedIP = ipAddress
+		}
+	}
+
+	return user, key, nil
+}
+
+func (s *APIKeyService) getServiceAccount(ctx context.Context, id string) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	if user == nil || !user.ServiceAccount {
+		return nil, ErrServiceAccountNotFound
+	}
+	return user, nil
+}
+
+// keyExpiry applies the default lifetime and caps the requested one
+func (s *APIKeyService) keyExpiry(requested *time.Time, now time.Time) (*time.Time, error) {
+	if requested == nil {
+		if s.config.APIKeyDefaultTTL <= 0 {
+			return nil, nil
+		}
+		expiresAt := now.Add(s.config.APIKeyDefaultTTL)
+		return &expiresAt, nil
+	}
+
+	if !requested.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+	}
+	if s.config.APIKeyMaxTTL > 0 && requested.Sub(now) > s.config.APIKeyMaxTTL {
+		return nil, fmt.Errorf("%w: expires_at must be within %s", ErrInvalidAPIKeyRequest, s.config.APIKeyMaxTTL)
+	}
+	expiresAt := *requested
+	return &expiresAt, nil
+}
+
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		known := false
+		for _, supported := range entities.APIKeyScopes {
+			if scope == supported {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unsupported scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// generateAPIKey returns a key of the form dss_<id>_<secret> and its dss_<id> prefix
+func generateAPIKey() (string, string, error) {
+	id := make([]byte, apiKeyIDLength/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	length := len(apiKeyPrefix) + apiKeyIDLength
+	if !IsAPIKey(rawKey) || len(rawKey) <= length+1 || rawKey[length] != '_' {
+		return "", false
+	}
+	return rawKey[:length], true
+}
+
+func hashAPIKey(rawKey string) string {
+	digest := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(digest[:])
+}
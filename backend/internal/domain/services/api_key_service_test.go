@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id string) (*entities.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListByUser(ctx context.Context, userID string) ([]*entities.APIKey, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time, ipAddress string) error {
+	args := m.Called(ctx, id, usedAt, ipAddress)
+	return args.Error(0)
+}
+
+var apiKeyTestNow = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func newTestAPIKeyService() (*APIKeyService, *MockAPIKeyRepository, *MockUserRepository) {
+	apiKeyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	service := NewAPIKeyService(apiKeyRepo, userRepo, &config.Config{
+		APIKeyDefaultTTL:  90 * 24 * time.Hour,
+		APIKeyMaxTTL:      365 * 24 * time.Hour,
+		APIKeyRateLimit:   60,
+		APIKeyTouchPeriod: time.Minute,
+	})
+	service.now = func() time.Time { return apiKeyTestNow }
+	return service, apiKeyRepo, userRepo
+}
+
+func testServiceAccount() *entities.User {
+	return &entities.User{ID: "svc-1", Username: "sis-batch", IsActive: true, ServiceAccount: true}
+}
+
+func TestAPIKeyService_CreateServiceAccount(t *testing.T) {
+	service, _, userRepo := newTestAPIKeyService()
+	ctx := context.Background()
+
+	userRepo.On("GetByUsername", ctx, "sis-batch").Return(nil, nil)
+	userRepo.On("GetByEmail", ctx, "sis-batch@service-accounts.invalid").Return(nil, nil)
+	userRepo.On("Create", ctx, mock.MatchedBy(func(user *entities.User) bool {
+		return user.ServiceAccount && user.PasswordHash == "" && user.Role == "user" && user.FullName == "sis-batch"
+	})).Return(nil)
+
+	user, err := service.CreateServiceAccount(ctx, &CreateServiceAccountRequest{Username: " sis-batch "})
+	require.NoError(t, err)
+	assert.Equal(t, "sis-batch", user.Username)
+	assert.Equal(t, "sis-batch@service-accounts.invalid", user.Email)
+	userRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_CreateServiceAccount_Duplicate(t *testing.T) {
+	service, _, userRepo := newTestAPIKeyService()
+	ctx := context.Background()
+
+	userRepo.On("GetByUsername", ctx, "alice").Return(&entities.User{ID: "u1"}, nil)
+
+	_, err := service.CreateServiceAccount(ctx, &CreateServiceAccountRequest{Username: "alice"})
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	service, apiKeyRepo, userRepo := newTestAPIKeyService()
+	ctx := context.Background()
+
+	userRepo.On("GetByID", ctx, "svc-1").Return(testServiceAccount(), nil)
+	apiKeyRepo.On("Create", ctx, mock.AnythingOfType("*entities.APIKey")).Return(nil)
+
+	key, rawKey, err := service.CreateKey(ctx, "svc-1", "admin-1", &CreateAPIKeyRequest{
+		Name:   "SIS nightly",
+		Scopes: []string{"documents:sign", " Documents:Read ", "documents:sign"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix+"_"))
+	assert.Len(t, key.Prefix, len("dss_")+12)
+	assert.Equal(t, hashAPIKey(rawKey), key.KeyHash)
+	assert.NotContains(t, key.KeyHash, rawKey)
+	assert.Equal(t, []string{"documents:sign", "documents:read"}, key.Scopes)
+	assert.Equal(t, 60, key.RateLimit)
+	assert.Equal(t, "admin-1", key.CreatedBy)
+	require.NotNil(t, key.ExpiresAt)
+	assert.Equal(t, apiKeyTestNow.Add(90*24*time.Hour), *key.ExpiresAt)
+}
+
+func TestAPIKeyService_CreateKey_Validation(t *testing.T) {
+	past := apiKeyTestNow.Add(-time.Hour)
+	tooFar := apiKeyTestNow.Add(2 * 365 * 24 * time.Hour)
+	negative := -1
+
+	tests := []struct {
+		name string
+		req  CreateAPIKeyRequest
+	}{
+		{"missing name", CreateAPIKeyRequest{Scopes: []string{"documents:sign"}}},
+		{"no scopes", CreateAPIKeyRequest{Name: "k"}},
+		{"unknown scope", CreateAPIKeyRequest{Name: "k", Scopes: []string{"admin"}}},
+		{"expiry in the past", CreateAPIKeyRequest{Name: "k", Scopes: []string{"documents:sign"}, ExpiresAt: &past}},
+		{"expiry beyond the maximum", CreateAPIKeyRequest{Name: "k", Scopes: []string{"documents:sign"}, ExpiresAt: &tooFar}},
+		{"negative rate limit", CreateAPIKeyRequest{Name: "k", Scopes: []string{"documents:sign"}, RateLimit: &negative}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, apiKeyRepo, userRepo := newTestAPIKeyService()
+			userRepo.On("GetByID", mock.Anything, "svc-1").Return(testServiceAccount(), nil)
+
+			_, _, err := service.CreateKey(context.Background(), "svc-1", "admin-1", &tt.req)
+			assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+			apiKeyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAPIKeyService_CreateKey_RequiresServiceAccount(t *testing.T) {
+	service, _, userRepo := newTestAPIKeyService()
+	ctx := context.Background()
+
+	userRepo.On("GetByID", ctx, "u1").Return(&entities.User{ID: "u1", IsActive: true}, nil)
+
+	_, _, err := service.CreateKey(ctx, "u1", "admin-1", &CreateAPIKeyRequest{Name: "k", Scopes: []string{"documents:sign"}})
+	assert.ErrorIs(t, err, ErrServiceAccountNotFound)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	service, apiKeyRepo, userRepo := newTestAPIKeyService()
+	ctx := context.Background()
+
+	prefix, rawKey, err := generateAPIKey()
+	require.NoError(t, err)
+	stored := &entities.APIKey{ID: "key-1", UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey)}
+
+	apiKeyRepo.On("GetByPrefix", ctx, prefix).Return(stored, nil)
+	userRepo.On("GetByID", ctx, "svc-1").Return(testServiceAccount(), nil)
+	apiKeyRepo.On("TouchLastUsed", ctx, "key-1", apiKeyTestNow, "10.0.0.5").Return(nil).Once()
+
+	user, key, err := service.Authenticate(ctx, rawKey, "10.0.0.5")
+	require.NoError(t, err)
+	assert.Equal(t, "svc-1", user.ID)
+	assert.Equal(t, "key-1", key.ID)
+
+	// A second request within the touch period does not write again
+	_, _, err = service.Authenticate(ctx, rawKey, "10.0.0.5")
+	require.NoError(t, err)
+	apiKeyRepo.AssertNumberOfCalls(t, "TouchLastUsed", 1)
+}
+
+func TestAPIKeyService_Authenticate_Rejections(t *testing.T) {
+	prefix, rawKey, err := generateAPIKey()
+	require.NoError(t, err)
+	expired := apiKeyTestNow.Add(-time.Minute)
+	revoked := apiKeyTestNow.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		rawKey  string
+		key     *entities.APIKey
+		user    *entities.User
+		wantErr error
+	}{
+		{"not an API key", "eyJhbGciOiJIUzI1NiJ9.e30.sig", nil, nil, ErrInvalidAPIKey},
+		{"unknown prefix", rawKey, nil, nil, ErrInvalidAPIKey},
+		{"wrong secret", prefix + "_wrong", &entities.APIKey{UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey)}, nil, ErrInvalidAPIKey},
+		{"revoked", rawKey, &entities.APIKey{UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey), RevokedAt: &revoked}, nil, ErrInvalidAPIKey},
+		{"expired", rawKey, &entities.APIKey{UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey), ExpiresAt: &expired}, nil, ErrAPIKeyExpired},
+		{"inactive account", rawKey, &entities.APIKey{UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey)}, &entities.User{ID: "svc-1", ServiceAccount: true}, ErrUserInactive},
+		{"not a service account", rawKey, &entities.APIKey{UserID: "svc-1", Prefix: prefix, KeyHash: hashAPIKey(rawKey)}, &entities.User{ID: "svc-1", IsActive: true}, ErrInvalidAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, apiKeyRepo, userRepo := newTestAPIKeyService()
+			apiKeyRepo.On("GetByPrefix", mock.Anything, prefix).Return(tt.key, nil)
+			userRepo.On("GetByID", mock.Anything, "svc-1").Return(tt.user, nil)
+
+			_, _, err := service.Authenticate(context.Background(), tt.rawKey, "10.0.0.5")
+			assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+			apiKeyRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeKey(t *testing.T) {
+	service, apiKeyRepo, _ := newTestAPIKeyService()
+	ctx := context.Background()
+
+	key := &entities.APIKey{ID: "key-1", UserID: "svc-1"}
+	apiKeyRepo.On("GetByID", ctx, "key-1").Return(key, nil)
+	apiKeyRepo.On("Update", ctx, key).Return(nil).Once()
+	apiKeyRepo.On("GetByID", ctx, "missing").Return(nil, nil)
+
+	revoked, err := service.RevokeKey(ctx, "key-1")
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	assert.Equal(t, apiKeyTestNow, *revoked.RevokedAt)
+
+	// Revoking again keeps the original time
+	_, err = service.RevokeKey(ctx, "key-1")
+	require.NoError(t, err)
+	apiKeyRepo.AssertNumberOfCalls(t, "Update", 1)
+
+	_, err = service.RevokeKey(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
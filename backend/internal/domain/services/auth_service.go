@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"digital-signature-system/internal/domain/entities"
@@ -28,11 +30,50 @@ type AuthService struct {
 	userRepo    repositories.UserRepository
 	sessionRepo repositories.SessionRepository
 	jwtSecret   string
+	loginGuard  *LoginGuard
+	mfaService  *MFAService
+	// verifiers check passwords in order; local accounts come first
+	verifiers []CredentialVerifier
+
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+
+	webAuthn         *webauthn.WebAuthn
+	webAuthnRepo     repositories.WebAuthnRepository
+	webAuthnTTL      time.Duration
+	webAuthnRequired bool
+
+	registrationMode string
+	invitations      repositories.InvitationRepository
 }
 
+// mfaChallengePurpose marks the short-lived token issued between the password and
+// second factor steps; it is not accepted as a session token
+const (
+	mfaChallengePurpose    = "mfa_challenge"
+	defaultMFAChallengeTTL = 5 * time.Minute
+
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// IPAddress is the client address, used to throttle repeated failures
+	IPAddress string `json:"-"`
+	// UserAgent is recorded on the session to describe the device
+	UserAgent string `json:"-"`
+}
+
+// MFALoginRequest completes a login that was answered with an MFA challenge
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	// IPAddress is the client address, used to throttle repeated failures
+	IPAddress string `json:"-"`
+	// UserAgent is recorded on the session to describe the device
+	UserAgent string `json:"-"`
 }
 
 type RegisterRequest struct {
@@ -40,18 +81,37 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	// InvitationToken is required while registration is invite-only
+	InvitationToken string `json:"invitation_token,omitempty"`
 }
 
+// LoginResponse carries either a short-lived access token and the refresh token that
+// renews it, or an MFA challenge token when the user must still present a second factor
 type LoginResponse struct {
-	Token     string         `json:"token"`
+	Token     string         `json:"token,omitempty"`
 	ExpiresAt time.Time      `json:"expires_at"`
-	User      *entities.User `json:"user"`
+	User      *entities.User `json:"user,omitempty"`
+
+	RefreshToken     string     `json:"refresh_token,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAEnrollmentRequired tells a user without two-factor that policy requires it to sign
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
 }
 
 type JWTClaims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// MFA is set when the session was opened with a second factor
+	MFA bool `json:"mfa,omitempty"`
+	// Purpose is set on tokens that are not session tokens
+	Purpose string `json:"purpose,omitempty"`
+	// SessionID names the session the token was issued for; revoking the session
+	// revokes the token
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -61,6 +121,13 @@ type AuthenticatedUser struct {
 	FullName string `json:"full_name"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
+	// MFAVerified is set when the session was opened with a second factor
+	MFAVerified bool `json:"mfa_verified"`
+	// SessionID is the session the request was authenticated with
+	SessionID string `json:"-"`
+	// APIKeyID and APIKeyPrefix identify the key a service account authenticated with
+	APIKeyID     string `json:"api_key_id,omitempty"`
+	APIKeyPrefix string `json:"api_key_prefix,omitempty"`
 }
 
 func NewAuthService(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, jwtSecret string) *AuthService {
@@ -68,15 +135,79 @@ func NewAuthService(userRepo repositories.UserRepository, sessionRepo repositori
 		userRepo:    userRepo,
 		sessionRepo: sessionRepo,
 		jwtSecret:   jwtSecret,
+		verifiers:   []CredentialVerifier{NewLocalCredentialVerifier(userRepo)},
+
+		accessTokenTTL:  defaultAccessTokenTTL,
+		refreshTokenTTL: defaultRefreshTokenTTL,
+
+		registrationMode: RegistrationOpen,
+	}
+}
+
+// SetRegistration sets who may register: anyone ("open", also used when mode is
+// empty), holders of an invitation ("invite") or nobody ("closed")
+func (s *AuthService) SetRegistration(mode string, invitations repositories.InvitationRepository) error {
+	if mode == "" {
+		mode = RegistrationOpen
+	}
+	if !ValidRegistrationMode(mode) {
+		return fmt.Errorf("%w: %q", ErrInvalidRegistration, mode)
+	}
+	if mode == RegistrationInvite && invitations == nil {
+		return fmt.Errorf("%w: invite-only registration needs invitations", ErrInvalidRegistration)
+	}
+	s.registrationMode = mode
+	s.invitations = invitations
+	return nil
+}
+
+// RegistrationMode returns how new accounts may register
+func (s *AuthService) RegistrationMode() string {
+	return s.registrationMode
+}
+
+// SetTokenLifetimes sets how long access tokens last and how long a session may go
+// without being refreshed; zero keeps the default
+func (s *AuthService) SetTokenLifetimes(accessTokenTTL, refreshTokenTTL time.Duration) {
+	if accessTokenTTL > 0 {
+		s.accessTokenTTL = accessTokenTTL
 	}
+	if refreshTokenTTL > 0 {
+		s.refreshTokenTTL = refreshTokenTTL
+	}
+}
+
+// SetLoginGuard enables failed login tracking and lockout
+func (s *AuthService) SetLoginGuard(guard *LoginGuard) {
+	s.loginGuard = guard
+}
+
+// SetMFAService enables two-step login for users with two-factor authentication
+func (s *AuthService) SetMFAService(mfaService *MFAService) {
+	s.mfaService = mfaService
+}
+
+// AddCredentialVerifier adds a credential store, such as a directory, that is asked
+// when the stores before it do not accept the password
+func (s *AuthService) AddCredentialVerifier(verifier CredentialVerifier) {
+	s.verifiers = append(s.verifiers, verifier)
 }
 
 // Login authenticates a user and returns a JWT token
 func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginResponse, error) {
-	// Get user by username
-	user, err := s.userRepo.GetByUsername(ctx, req.Username)
-	if err != nil || user == nil {
-		return nil, ErrInvalidCredentials
+	if s.loginGuard != nil {
+		if err := s.loginGuard.Check(ctx, req.Username, req.IPAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify password
+	user, err := s.verifyCredentials(ctx, req.Username, req.Password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		return nil, s.loginFailed(ctx, req)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if user is active
@@ -84,39 +215,184 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 		return nil, ErrUserInactive
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	if s.mfaService != nil {
+		enabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check two-factor authentication: %w", err)
+		}
+		if enabled {
+			// Failures are kept until the second factor passes, so a known password
+			// cannot be used to reset the count between code guesses
+			return s.mfaChallenge(user)
+		}
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := s.generateJWT(user)
+	if s.loginGuard != nil {
+		s.loginGuard.RecordSuccess(ctx, req.Username)
+	}
+
+	response, err := s.createSession(ctx, user, false, req.IPAddress, req.UserAgent)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
+	}
+	response.MFAEnrollmentRequired = s.mfaService != nil && s.mfaService.RequiredForSigning()
+	return response, nil
+}
+
+// verifyCredentials asks each credential store in turn. The first to accept the
+// password decides; any other error, such as an unreachable directory, ends the login.
+func (s *AuthService) verifyCredentials(ctx context.Context, username, password string) (*entities.User, error) {
+	for _, verifier := range s.verifiers {
+		user, err := verifier.Verify(ctx, username, password)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, fmt.Errorf("%s login failed: %w", verifier.Name(), err)
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// CompleteMFALogin finishes a two-step login with a TOTP or recovery code
+func (s *AuthService) CompleteMFALogin(ctx context.Context, req MFALoginRequest) (*LoginResponse, error) {
+	if s.mfaService == nil {
+		return nil, ErrMFANotEnabled
+	}
+
+	claims, err := s.parseToken(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != mfaChallengePurpose {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil {
+		return nil, ErrInvalidToken
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	if s.loginGuard != nil {
+		if err := s.loginGuard.Check(ctx, user.Username, req.IPAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.mfaService.Verify(ctx, user.ID, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			return nil, s.mfaFailed(ctx, user.Username, req.IPAddress)
+		}
+		return nil, err
+	}
+
+	if s.loginGuard != nil {
+		s.loginGuard.RecordSuccess(ctx, user.Username)
+	}
+
+	return s.createSession(ctx, user, true, req.IPAddress, req.UserAgent)
+}
+
+// mfaFailed counts a wrong second factor against the account like a wrong password
+func (s *AuthService) mfaFailed(ctx context.Context, username, ipAddress string) error {
+	if s.loginGuard == nil {
+		return ErrInvalidMFACode
+	}
+	err := s.loginGuard.RecordFailure(ctx, username, ipAddress)
+	var attemptErr *LoginAttemptError
+	if errors.As(err, &attemptErr) && attemptErr.Err == ErrInvalidCredentials {
+		attemptErr.Err = ErrInvalidMFACode
 	}
+	return err
+}
 
-	// Create session record
+// mfaChallenge answers a correct password with a short-lived token for the second step
+func (s *AuthService) mfaChallenge(user *entities.User) (*LoginResponse, error) {
+	ttl := defaultMFAChallengeTTL
+	if s.mfaService.config.MFAChallengeTTL > 0 {
+		ttl = s.mfaService.config.MFAChallengeTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	token, err := s.signToken(&JWTClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Purpose:  mfaChallengePurpose,
+	}, user.ID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA challenge: %w", err)
+	}
+
+	return &LoginResponse{
+		ExpiresAt:   expiresAt,
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+// createSession opens a session for an authenticated user and issues its first
+// access and refresh tokens
+func (s *AuthService) createSession(ctx context.Context, user *entities.User, mfa bool, ipAddress, userAgent string) (*LoginResponse, error) {
+	now := time.Now()
 	session := &entities.Session{
+		ID:           uuid.New().String(),
 		UserID:       user.ID,
-		SessionToken: token,
 		RefreshToken: s.generateRefreshToken(),
-		ExpiresAt:    expiresAt,
-		LastAccessed: time.Now(),
+		MFA:          mfa,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		ExpiresAt:    now.Add(s.refreshTokenTTL),
+		LastAccessed: now,
 	}
 
+	// Generate JWT token
+	token, expiresAt, err := s.generateJWT(user, session.ID, mfa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	session.SessionToken = token
+
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	return s.sessionResponse(user, session, expiresAt), nil
+}
+
+// sessionResponse returns a session's current tokens
+func (s *AuthService) sessionResponse(user *entities.User, session *entities.Session, expiresAt time.Time) *LoginResponse {
+	refreshExpiresAt := session.ExpiresAt
 	return &LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
-	}, nil
+		Token:            session.SessionToken,
+		ExpiresAt:        expiresAt,
+		User:             user,
+		RefreshToken:     s.encodeRefreshToken(session, session.RefreshGeneration),
+		RefreshExpiresAt: &refreshExpiresAt,
+	}
+}
+
+// loginFailed counts a failed login when tracking is enabled. Unknown usernames are
+// counted the same way so lockouts do not reveal which accounts exist.
+func (s *AuthService) loginFailed(ctx context.Context, req LoginRequest) error {
+	if s.loginGuard == nil {
+		return ErrInvalidCredentials
+	}
+	return s.loginGuard.RecordFailure(ctx, req.Username, req.IPAddress)
 }
 
-// Register creates a new user account
+// Register creates a new user account. While registration is invite-only the
+// account gets the role of the invitation it redeems.
 func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*entities.User, error) {
+	if s.registrationMode == RegistrationClosed {
+		return nil, ErrRegistrationClosed
+	}
+	if s.registrationMode == RegistrationInvite && req.InvitationToken == "" {
+		return nil, ErrInvitationRequired
+	}
+
 	// Check if username already exists
 	if existingUser, err := s.userRepo.GetByUsername(ctx, req.Username); err == nil && existingUser != nil {
 		return nil, ErrUserAlreadyExists
@@ -135,6 +411,7 @@ func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*entit
 
 	// Create user
 	user := &entities.User{
+		ID:           uuid.New().String(),
 		Username:     req.Username,
 		PasswordHash: hashedPassword,
 		FullName:     req.FullName,
@@ -143,7 +420,20 @@ func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*entit
 		IsActive:     true,
 	}
 
+	// The invitation is claimed first so it cannot be used twice
+	var invitation *entities.Invitation
+	if s.registrationMode == RegistrationInvite {
+		invitation, err = redeemInvitation(ctx, s.invitations, req.InvitationToken, req.Email, user.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		user.Role = invitation.Role
+	}
+
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		if invitation != nil {
+			s.invitations.Release(ctx, invitation.ID)
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -152,12 +442,61 @@ func (s *AuthService) Register(ctx context.Context, req RegisterRequest) (*entit
 
 // Logout invalidates a user's session
 func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if claims, err := s.parseToken(token); err == nil && claims.SessionID != "" {
+		_, err := s.sessionRepo.DeleteByID(ctx, claims.UserID, claims.SessionID)
+		return err
+	}
 	return s.sessionRepo.Delete(ctx, token)
 }
 
 // ValidateToken validates a JWT token and returns the user
 func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*entities.User, error) {
-	// Parse and validate JWT token
+	user, _, err := s.ValidateTokenClaims(ctx, tokenString)
+	return user, err
+}
+
+// ValidateTokenClaims validates a session token and returns the user with the token's claims
+func (s *AuthService) ValidateTokenClaims(ctx context.Context, tokenString string) (*entities.User, *JWTClaims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Challenge tokens only prove the password step
+	if claims.Purpose != "" {
+		return nil, nil, ErrInvalidToken
+	}
+
+	// Get user from database
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	// Check if user is still active
+	if !user.IsActive {
+		return nil, nil, ErrUserInactive
+	}
+
+	// The token is only good while its session is
+	session, err := s.tokenSession(ctx, claims, tokenString)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if session == nil || session.UserID != user.ID || session.ExpiresAt.Before(now) {
+		return nil, nil, ErrSessionExpired
+	}
+	claims.SessionID = session.ID
+
+	// Update session last accessed time
+	s.sessionRepo.Touch(ctx, session.ID, now)
+
+	return user, claims, nil
+}
+
+// parseToken checks a token's signature and expiry and returns its claims
+func (s *AuthService) parseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -179,31 +518,13 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*e
 		return nil, ErrSessionExpired
 	}
 
-	// Get user from database
-	user, err := s.userRepo.GetByID(ctx, claims.UserID)
-	if err != nil {
-		return nil, ErrUserNotFound
-	}
-
-	// Check if user is still active
-	if !user.IsActive {
-		return nil, ErrUserInactive
-	}
-
-	// Update session last accessed time
-	session, err := s.sessionRepo.GetByToken(ctx, tokenString)
-	if err == nil && session != nil {
-		session.LastAccessed = time.Now()
-		s.sessionRepo.Update(ctx, session)
-	}
-
-	return user, nil
+	return claims, nil
 }
 
 // ValidateSession validates a session token
 func (s *AuthService) ValidateSession(ctx context.Context, sessionToken string) (*entities.User, error) {
 	session, err := s.sessionRepo.GetByToken(ctx, sessionToken)
-	if err != nil {
+	if err != nil || session == nil {
 		return nil, ErrInvalidToken
 	}
 
@@ -226,13 +547,14 @@ func (s *AuthService) ValidateSession(ctx context.Context, sessionToken string)
 
 	// Update last accessed time
 	session.LastAccessed = time.Now()
-	s.sessionRepo.Update(ctx, session)
+	s.sessionRepo.Touch(ctx, session.ID, session.LastAccessed)
 
 	return user, nil
 }
 
-// ChangePassword changes a user's password
-func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error {
+// ChangePassword changes a user's password and ends the user's sessions, except
+// those listed in keepSessionIDs
+func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string, keepSessionIDs ...string) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
@@ -256,7 +578,8 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, n
 	}
 
 	// Invalidate all existing sessions for this user
-	return s.sessionRepo.DeleteByUserID(ctx, userID)
+	_, err = s.sessionRepo.DeleteByUserID(ctx, userID, keepSessionIDs...)
+	return err
 }
 
 // CleanupExpiredSessions removes expired sessions from the database
@@ -264,25 +587,19 @@ func (s *AuthService) CleanupExpiredSessions(ctx context.Context) error {
 	return s.sessionRepo.DeleteExpired(ctx)
 }
 
-// generateJWT creates a new JWT token for the user
-func (s *AuthService) generateJWT(user *entities.User) (string, time.Time, error) {
-	expiresAt := time.Now().Add(24 * time.Hour) // 24 hours
+// generateJWT creates a new access token for the user's session
+func (s *AuthService) generateJWT(user *entities.User, sessionID string, mfa bool) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.accessTokenTTL)
 
 	claims := &JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "digital-signature-system",
-			Subject:   user.ID,
-		},
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		MFA:       mfa,
+		SessionID: sessionID,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := s.signToken(claims, user.ID, expiresAt)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -290,7 +607,21 @@ func (s *AuthService) generateJWT(user *entities.User) (string, time.Time, error
 	return tokenString, expiresAt, nil
 }
 
-// generateRefreshToken creates a random refresh token
+// signToken fills in the registered claims and signs the token
+func (s *AuthService) signToken(claims *JWTClaims, subject string, expiresAt time.Time) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		Issuer:    "digital-signature-system",
+		Subject:   subject,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.jwtSecret))
+}
+
+// generateRefreshToken creates a random per-session refresh secret
 func (s *AuthService) generateRefreshToken() string {
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
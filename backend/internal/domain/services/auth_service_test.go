@@ -12,6 +12,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
 )
 
 // Mock repositories
@@ -58,6 +59,30 @@ func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) ListServiceAccounts(ctx context.Context) ([]*entities.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListByRole(ctx context.Context, role string) ([]*entities.User, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, filter repositories.UserFilter) ([]*entities.User, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.User), args.Get(1).(int64), args.Error(2)
+}
+
 type MockSessionRepository struct {
 	mock.Mock
 }
@@ -67,6 +92,14 @@ func (m *MockSessionRepository) Create(ctx context.Context, session *entities.Se
 	return args.Error(0)
 }
 
+func (m *MockSessionRepository) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Session), args.Error(1)
+}
+
 func (m *MockSessionRepository) GetByToken(ctx context.Context, token string) (*entities.Session, error) {
 	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
@@ -88,14 +121,29 @@ func (m *MockSessionRepository) Update(ctx context.Context, session *entities.Se
 	return args.Error(0)
 }
 
+func (m *MockSessionRepository) Touch(ctx context.Context, id string, accessedAt time.Time) error {
+	args := m.Called(ctx, id, accessedAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Rotate(ctx context.Context, session *entities.Session, fromGeneration int) (bool, error) {
+	args := m.Called(ctx, session, fromGeneration)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockSessionRepository) Delete(ctx context.Context, token string) error {
 	args := m.Called(ctx, token)
 	return args.Error(0)
 }
 
-func (m *MockSessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
-	args := m.Called(ctx, userID)
-	return args.Error(0)
+func (m *MockSessionRepository) DeleteByID(ctx context.Context, userID, id string) (bool, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) DeleteByUserID(ctx context.Context, userID string, keepIDs ...string) (int64, error) {
+	args := m.Called(ctx, userID, keepIDs)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 func (m *MockSessionRepository) DeleteExpired(ctx context.Context) error {
@@ -305,7 +353,9 @@ func TestAuthService_ValidateToken(t *testing.T) {
 		IsActive: true,
 	}
 
-	token, _, err := authService.generateJWT(user)
+	token, _, err := authService.generateJWT(user, "session-1", false)
+	assert.NoError(t, err)
+	legacyToken, _, err := authService.generateJWT(user, "", false)
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -325,11 +375,36 @@ func TestAuthService_ValidateToken(t *testing.T) {
 					SessionToken: token,
 					ExpiresAt:    time.Now().Add(time.Hour),
 				}
-				sessionRepo.On("GetByToken", mock.Anything, token).Return(session, nil)
-				sessionRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+				sessionRepo.On("GetByID", mock.Anything, "session-1").Return(session, nil)
+				sessionRepo.On("Touch", mock.Anything, "session-1", mock.Anything).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "token without session id",
+			token: legacyToken,
+			setupMocks: func(userRepo *MockUserRepository, sessionRepo *MockSessionRepository) {
+				userRepo.On("GetByID", mock.Anything, "user-1").Return(user, nil)
+				session := &entities.Session{
+					ID:           "session-0",
+					UserID:       "user-1",
+					SessionToken: legacyToken,
+					ExpiresAt:    time.Now().Add(time.Hour),
+				}
+				sessionRepo.On("GetByToken", mock.Anything, legacyToken).Return(session, nil)
+				sessionRepo.On("Touch", mock.Anything, "session-0", mock.Anything).Return(nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name:  "revoked session",
+			token: token,
+			setupMocks: func(userRepo *MockUserRepository, sessionRepo *MockSessionRepository) {
+				userRepo.On("GetByID", mock.Anything, "user-1").Return(user, nil)
+				sessionRepo.On("GetByID", mock.Anything, "session-1").Return(nil, nil)
+			},
+			expectedError: ErrSessionExpired,
+		},
 		{
 			name:  "invalid token format",
 			token: "invalid-token",
@@ -426,7 +501,7 @@ func TestAuthService_ChangePassword(t *testing.T) {
 				}
 				userRepo.On("GetByID", mock.Anything, "user-1").Return(user, nil)
 				userRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.User")).Return(nil)
-				sessionRepo.On("DeleteByUserID", mock.Anything, "user-1").Return(nil)
+				sessionRepo.On("DeleteByUserID", mock.Anything, "user-1", []string(nil)).Return(int64(2), nil)
 			},
 			expectedError: nil,
 		},
@@ -528,11 +603,12 @@ func TestAuthService_generateJWT(t *testing.T) {
 		Role:     "user",
 	}
 
-	tokenString, expiresAt, err := authService.generateJWT(user)
+	tokenString, expiresAt, err := authService.generateJWT(user, "session-1", false)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokenString)
 	assert.True(t, expiresAt.After(time.Now()))
+	assert.True(t, expiresAt.Before(time.Now().Add(defaultAccessTokenTTL+time.Second)))
 
 	// Parse the token to verify its contents
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -547,6 +623,7 @@ func TestAuthService_generateJWT(t *testing.T) {
 	assert.Equal(t, user.ID, claims.UserID)
 	assert.Equal(t, user.Username, claims.Username)
 	assert.Equal(t, user.Role, claims.Role)
+	assert.Equal(t, "session-1", claims.SessionID)
 }
 
 func TestAuthService_CleanupExpiredSessions(t *testing.T) {
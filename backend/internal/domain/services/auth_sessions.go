@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// RefreshRequest exchanges a refresh token for new tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	// IPAddress and UserAgent update the session's device details
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// SessionInfo describes one of a user's sessions for the session list
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	Device       string    `json:"device"`
+	IPAddress    string    `json:"ip_address"`
+	UserAgent    string    `json:"user_agent"`
+	MFA          bool      `json:"mfa"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastAccessed time.Time `json:"last_accessed"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+}
+
+// Refresh rotates a session's refresh token and issues a new access token. Each
+// refresh token works once; presenting an already rotated one means a copy is in
+// someone else's hands, so the whole session is revoked.
+func (s *AuthService) Refresh(ctx context.Context, req RefreshRequest) (*LoginResponse, error) {
+	sessionID, generation, mac, ok := parseRefreshToken(req.RefreshToken)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || !hmac.Equal(mac, s.refreshTokenMAC(session, generation)) {
+		return nil, ErrInvalidToken
+	}
+	if generation < session.RefreshGeneration {
+		return nil, s.refreshTokenReused(ctx, session)
+	}
+	if generation > session.RefreshGeneration {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if session.ExpiresAt.Before(now) {
+		s.sessionRepo.DeleteByID(ctx, session.UserID, session.ID)
+		return nil, ErrSessionExpired
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	token, expiresAt, err := s.generateJWT(user, session.ID, session.MFA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	session.SessionToken = token
+	session.RefreshGeneration = generation + 1
+	session.ExpiresAt = now.Add(s.refreshTokenTTL)
+	session.LastAccessed = now
+	if req.IPAddress != "" {
+		session.IPAddress = req.IPAddress
+	}
+	if req.UserAgent != "" {
+		session.UserAgent = req.UserAgent
+	}
+
+	// A concurrent refresh with the same token won the race; one of the two is a copy
+	rotated, err := s.sessionRepo.Rotate(ctx, session, generation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if !rotated {
+		return nil, s.refreshTokenReused(ctx, session)
+	}
+
+	return s.sessionResponse(user, session, expiresAt), nil
+}
+
+// refreshTokenReused revokes a session whose refresh token was replayed
+func (s *AuthService) refreshTokenReused(ctx context.Context, session *entities.Session) error {
+	fmt.Printf("Warning: refresh token reuse detected for session %s of user %s; revoking session\n", session.ID, session.UserID)
+	if _, err := s.sessionRepo.DeleteByID(ctx, session.UserID, session.ID); err != nil {
+		fmt.Printf("Warning: failed to revoke session %s: %v\n", session.ID, err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionInfo, error) {
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	infos := make([]*SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ExpiresAt.Before(now) {
+			continue
+		}
+		infos = append(infos, &SessionInfo{
+			ID:           session.ID,
+			Device:       describeDevice(session.UserAgent),
+			IPAddress:    session.IPAddress,
+			UserAgent:    session.UserAgent,
+			MFA:          session.MFA,
+			CreatedAt:    session.CreatedAt,
+			LastAccessed: session.LastAccessed,
+			ExpiresAt:    session.ExpiresAt,
+			Current:      session.ID == currentSessionID,
+		})
+	}
+	return infos, nil
+}
+
+// RevokeSession ends one of the user's sessions; its tokens stop working immediately
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	deleted, err := s.sessionRepo.DeleteByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except the current one and
+// returns how many were ended
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int64, error) {
+	if currentSessionID == "" {
+		return 0, ErrSessionNotFound
+	}
+	return s.sessionRepo.DeleteByUserID(ctx, userID, currentSessionID)
+}
+
+// tokenSession finds the session an access token belongs to. Tokens issued before
+// sessions were named in the claims are matched by value.
+func (s *AuthService) tokenSession(ctx context.Context, claims *JWTClaims, tokenString string) (*entities.Session, error) {
+	var session *entities.Session
+	var err error
+	if claims.SessionID != "" {
+		session, err = s.sessionRepo.GetByID(ctx, claims.SessionID)
+	} else {
+		session, err = s.sessionRepo.GetByToken(ctx, tokenString)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// encodeRefreshToken returns the refresh token for a generation of the session:
+// the session ID, the generation and a MAC keyed by the session's secret
+func (s *AuthService) encodeRefreshToken(session *entities.Session, generation int) string {
+	mac := base64.RawURLEncoding.EncodeToString(s.refreshTokenMAC(session, generation))
+	return session.ID + "." + strconv.Itoa(generation) + "." + mac
+}
+
+func (s *AuthService) refreshTokenMAC(session *entities.Session, generation int) []byte {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	mac.Write([]byte("refresh|" + session.ID + "|" + strconv.Itoa(generation) + "|" + session.RefreshToken))
+	return mac.Sum(nil)
+}
+
+func parseRefreshToken(token string) (sessionID string, generation int, mac []byte, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", 0, nil, false
+	}
+	generation, err := strconv.Atoi(parts[1])
+	if err != nil || generation < 0 {
+		return "", 0, nil, false
+	}
+	mac, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", 0, nil, false
+	}
+	return parts[0], generation, mac, true
+}
+
+// describeDevice turns a User-Agent into a short label such as "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"), strings.Contains(userAgent, "Opera"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"), strings.Contains(userAgent, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	case strings.HasPrefix(userAgent, "curl/"):
+		browser = "curl"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		platform = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(userAgent, "Mac OS X"), strings.Contains(userAgent, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+// loginForRefresh logs a user in and returns the session the login created
+func loginForRefresh(t *testing.T) (*AuthService, *MockUserRepository, *MockSessionRepository, *LoginResponse, *entities.Session) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	authService := NewAuthService(userRepo, sessionRepo, "test-secret")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &entities.User{ID: "user-1", Username: "alice", PasswordHash: string(hashedPassword), IsActive: true}
+	userRepo.On("GetByUsername", mock.Anything, "alice").Return(user, nil)
+	userRepo.On("GetByID", mock.Anything, "user-1").Return(user, nil)
+
+	var session *entities.Session
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Session")).Run(func(args mock.Arguments) {
+		session = args.Get(1).(*entities.Session)
+	}).Return(nil)
+
+	response, err := authService.Login(context.Background(), LoginRequest{
+		Username:  "alice",
+		Password:  "password123",
+		IPAddress: "192.0.2.1",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	return authService, userRepo, sessionRepo, response, session
+}
+
+func TestAuthService_Login_IssuesRefreshToken(t *testing.T) {
+	_, _, _, response, session := loginForRefresh(t)
+
+	require.NotEmpty(t, response.RefreshToken)
+	require.NotNil(t, response.RefreshExpiresAt)
+	assert.Equal(t, session.ExpiresAt, *response.RefreshExpiresAt)
+	assert.Equal(t, response.Token, session.SessionToken)
+	assert.Equal(t, "192.0.2.1", session.IPAddress)
+	assert.NotContains(t, response.RefreshToken, session.RefreshToken, "the session secret is never handed out")
+	assert.True(t, response.ExpiresAt.Before(session.ExpiresAt), "access tokens expire before the session")
+}
+
+func TestAuthService_Refresh_Rotates(t *testing.T) {
+	authService, _, sessionRepo, login, session := loginForRefresh(t)
+	ctx := context.Background()
+
+	sessionRepo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sessionRepo.On("Rotate", mock.Anything, session, 0).Return(true, nil).Once()
+
+	refreshed, err := authService.Refresh(ctx, RefreshRequest{RefreshToken: login.RefreshToken, IPAddress: "198.51.100.7"})
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, 1, session.RefreshGeneration)
+	assert.Equal(t, "198.51.100.7", session.IPAddress)
+	assert.Equal(t, refreshed.Token, session.SessionToken)
+
+	claims, err := authService.parseToken(refreshed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, claims.SessionID)
+
+	// The rotated token is now a replay: the session is revoked
+	sessionRepo.On("DeleteByID", mock.Anything, "user-1", session.ID).Return(true, nil).Once()
+	_, err = authService.Refresh(ctx, RefreshRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	sessionRepo.AssertCalled(t, "DeleteByID", mock.Anything, "user-1", session.ID)
+}
+
+func TestAuthService_Refresh_LostRace(t *testing.T) {
+	authService, _, sessionRepo, login, session := loginForRefresh(t)
+
+	sessionRepo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sessionRepo.On("Rotate", mock.Anything, session, 0).Return(false, nil)
+	sessionRepo.On("DeleteByID", mock.Anything, "user-1", session.ID).Return(true, nil)
+
+	_, err := authService.Refresh(context.Background(), RefreshRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
+
+func TestAuthService_Refresh_Rejects(t *testing.T) {
+	authService, _, sessionRepo, login, session := loginForRefresh(t)
+	ctx := context.Background()
+
+	sessionRepo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sessionRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+
+	for name, token := range map[string]string{
+		"malformed":         "not-a-refresh-token",
+		"unknown session":   "missing.0.AAAA",
+		"forged mac":        session.ID + ".0.AAAA",
+		"future generation": authService.encodeRefreshToken(session, 5),
+		"access token":      login.Token,
+		"negative counter":  session.ID + ".-1.AAAA",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := authService.Refresh(ctx, RefreshRequest{RefreshToken: token})
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+	sessionRepo.AssertNotCalled(t, "DeleteByID", mock.Anything, mock.Anything, mock.Anything)
+
+	// An expired session cannot be renewed
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+	sessionRepo.On("DeleteByID", mock.Anything, "user-1", session.ID).Return(true, nil)
+	_, err := authService.Refresh(ctx, RefreshRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
+
+func TestAuthService_ListAndRevokeSessions(t *testing.T) {
+	sessionRepo := new(MockSessionRepository)
+	authService := NewAuthService(nil, sessionRepo, "test-secret")
+	ctx := context.Background()
+
+	sessionRepo.On("GetByUserID", ctx, "user-1").Return([]*entities.Session{
+		{ID: "session-1", UserID: "user-1", UserAgent: "curl/8.5.0", ExpiresAt: time.Now().Add(time.Hour)},
+		{ID: "session-2", UserID: "user-1", ExpiresAt: time.Now().Add(-time.Hour)},
+		{ID: "session-3", UserID: "user-1", ExpiresAt: time.Now().Add(time.Hour)},
+	}, nil)
+
+	sessions, err := authService.ListSessions(ctx, "user-1", "session-3")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2, "expired sessions are not listed")
+	assert.Equal(t, "curl", sessions[0].Device)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+
+	sessionRepo.On("DeleteByID", ctx, "user-1", "session-1").Return(true, nil)
+	sessionRepo.On("DeleteByID", ctx, "user-1", "session-9").Return(false, nil)
+	assert.NoError(t, authService.RevokeSession(ctx, "user-1", "session-1"))
+	assert.ErrorIs(t, authService.RevokeSession(ctx, "user-1", "session-9"), ErrSessionNotFound)
+
+	sessionRepo.On("DeleteByUserID", ctx, "user-1", []string{"session-3"}).Return(int64(1), nil)
+	revoked, err := authService.RevokeOtherSessions(ctx, "user-1", "session-3")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	_, err = authService.RevokeOtherSessions(ctx, "user-1", "")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestDescribeDevice(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36":                "Chrome on Windows",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36 Edg/126.0":      "Edge on Windows",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15":         "Safari on macOS",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Safari/604.1": "Safari on iOS",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Mobile Safari/537.36":          "Chrome on Android",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0":                                                     "Firefox on Linux",
+		"curl/8.5.0": "curl",
+		"":           "Unknown device",
+	}
+	for userAgent, want := range tests {
+		assert.Equal(t, want, describeDevice(userAgent), userAgent)
+	}
+}
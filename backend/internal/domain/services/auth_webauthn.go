@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrWebAuthnNotConfigured        = errors.New("security keys are not configured")
+	ErrWebAuthnRequired             = errors.New("a security key confirmation is required")
+	ErrWebAuthnNoCredentials        = errors.New("no security key is registered")
+	ErrWebAuthnChallengeNotFound    = errors.New("security key challenge not found or expired")
+	ErrWebAuthnVerificationFailed   = errors.New("security key verification failed")
+	ErrWebAuthnCredentialNotFound   = errors.New("security key not found")
+	ErrWebAuthnDocumentMismatch     = errors.New("security key confirmation was given for a different document")
+	ErrWebAuthnInvalidHash          = errors.New("document hash must be a hex encoded SHA-256 digest")
+	ErrWebAuthnCredentialRegistered = errors.New("security key is already registered")
+)
+
+const (
+	defaultWebAuthnChallengeTTL = 5 * time.Minute
+	webAuthnNonceSize           = 32
+	maxWebAuthnCredentialName   = 100
+)
+
+// WebAuthnCeremony is returned when a ceremony starts. Options are passed to
+// navigator.credentials.create or .get, and ChallengeID is sent back with the result.
+type WebAuthnCeremony struct {
+	ChallengeID string      `json:"challenge_id"`
+	Options     interface{} `json:"options"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+}
+
+// WebAuthnAssertion is a completed assertion ceremony: the challenge it answers and
+// the authenticator's JSON encoded PublicKeyCredential
+type WebAuthnAssertion struct {
+	ChallengeID string          `json:"challenge_id"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// VerifiedAssertion identifies the authenticator that confirmed an action
+type VerifiedAssertion struct {
+	Credential   *entities.WebAuthnCredential
+	UserVerified bool
+	// Digest is the document hash or batch digest a signing confirmation covers
+	Digest []byte
+}
+
+// webAuthnUser adapts a user and their credentials to the library's User interface
+type webAuthnUser struct {
+	user        *entities.User
+	credentials []*entities.WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	if u.user.FullName != "" {
+		return u.user.FullName
+	}
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, stored := range u.credentials {
+		credential, err := toLibraryCredential(stored)
+		if err != nil {
+			fmt.Printf("Warning: skipping unreadable WebAuthn credential %s: %v\n", stored.ID, err)
+			continue
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials
+}
+
+// SetWebAuthn enables security key registration and signing step-up. Step-up is
+// required for users with a registered key, and for everyone when policy says so.
+func (s *AuthService) SetWebAuthn(repo repositories.WebAuthnRepository, cfg *config.Config) error {
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthnRPID,
+		RPDisplayName: cfg.WebAuthnRPName,
+		RPOrigins:     cfg.GetWebAuthnOrigins(),
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: protocol.VerificationPreferred,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize WebAuthn: %w", err)
+	}
+
+	s.webAuthn = w
+	s.webAuthnRepo = repo
+	s.webAuthnTTL = cfg.WebAuthnChallengeTTL
+	if s.webAuthnTTL <= 0 {
+		s.webAuthnTTL = defaultWebAuthnChallengeTTL
+	}
+	s.webAuthnRequired = cfg.WebAuthnRequiredForSigning
+	return nil
+}
+
+// SigningAssertionRequired reports whether the user must confirm each signing
+// request with a security key
+func (s *AuthService) SigningAssertionRequired(ctx context.Context, userID string) (bool, error) {
+	if s.webAuthn == nil {
+		return false, nil
+	}
+	if s.webAuthnRequired {
+		return true, nil
+	}
+	count, err := s.webAuthnRepo.CountCredentials(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListWebAuthnCredentials returns the user's registered security keys
+func (s *AuthService) ListWebAuthnCredentials(ctx context.Context, userID string) ([]*entities.WebAuthnCredential, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnNotConfigured
+	}
+	return s.webAuthnRepo.ListCredentials(ctx, userID)
+}
+
+// BeginWebAuthnConfirmation starts an assertion that authorizes adding or removing a
+// security key
+func (s *AuthService) BeginWebAuthnConfirmation(ctx context.Context, userID string) (*WebAuthnCeremony, error) {
+	return s.beginAssertion(ctx, userID, entities.WebAuthnPurposeConfirmation, nil)
+}
+
+// BeginSigningAssertion starts the step-up assertion for signing the document whose
+// SHA-256 is documentHash. The challenge is derived from the hash, so the
+// authenticator's signature covers the document being signed.
+func (s *AuthService) BeginSigningAssertion(ctx context.Context, userID string, documentHash []byte) (*WebAuthnCeremony, error) {
+	if len(documentHash) != sha256.Size {
+		return nil, ErrWebAuthnInvalidHash
+	}
+	return s.beginAssertion(ctx, userID, entities.WebAuthnPurposeSigning, documentHash)
+}
+
+// BeginBatchSigningAssertion starts the step-up assertion for one batch signing
+// request. Like a single document's, the challenge is derived from the batch's
+// BatchDigest, so the confirmation only covers those files and manifest.
+func (s *AuthService) BeginBatchSigningAssertion(ctx context.Context, userID string, batchDigest []byte) (*WebAuthnCeremony, error) {
+	if len(batchDigest) != sha256.Size {
+		return nil, ErrWebAuthnInvalidHash
+	}
+	return s.beginAssertion(ctx, userID, entities.WebAuthnPurposeBatchSigning, batchDigest)
+}
+
+// VerifySigningAssertion checks a step-up assertion against the hash of the document
+// actually uploaded. The challenge is consumed whether or not it verifies.
+func (s *AuthService) VerifySigningAssertion(ctx context.Context, userID string, assertion WebAuthnAssertion, documentHash []byte) (*VerifiedAssertion, error) {
+	return s.verifyBoundAssertion(ctx, userID, assertion, entities.WebAuthnPurposeSigning, documentHash)
+}
+
+// VerifyBatchSigningAssertion checks the step-up assertion for a batch signing
+// request against the BatchDigest of the files and manifest actually uploaded
+func (s *AuthService) VerifyBatchSigningAssertion(ctx context.Context, userID string, assertion WebAuthnAssertion, batchDigest []byte) (*VerifiedAssertion, error) {
+	return s.verifyBoundAssertion(ctx, userID, assertion, entities.WebAuthnPurposeBatchSigning, batchDigest)
+}
+
+// verifyBoundAssertion consumes the challenge and checks that it was started
+// for the digest before validating the authenticator's response
+func (s *AuthService) verifyBoundAssertion(ctx context.Context, userID string, assertion WebAuthnAssertion, purpose string, digest []byte) (*VerifiedAssertion, error) {
+	challenge, err := s.consumeChallenge(ctx, userID, assertion.ChallengeID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := hex.DecodeString(challenge.DocumentHash)
+	if err != nil || subtle.ConstantTimeCompare(expected, digest) != 1 {
+		return nil, ErrWebAuthnDocumentMismatch
+	}
+
+	verified, err := s.validateAssertion(ctx, userID, challenge, assertion.Response)
+	if err != nil {
+		return nil, err
+	}
+	verified.Digest = expected
+	return verified, nil
+}
+
+// BeginWebAuthnRegistration starts registering a security key. A user who already has
+// one must confirm with it first, so a stolen session cannot add its own key.
+func (s *AuthService) BeginWebAuthnRegistration(ctx context.Context, userID string, confirmation *WebAuthnAssertion) (*WebAuthnCeremony, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnNotConfigured
+	}
+
+	user, err := s.webAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkConfirmation(ctx, user, confirmation); err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithConveyancePreference(protocol.PreferNoAttestation),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin WebAuthn registration: %w", err)
+	}
+
+	return s.storeCeremony(ctx, userID, entities.WebAuthnPurposeRegistration, session, creation, "", "")
+}
+
+// FinishWebAuthnRegistration verifies the authenticator's attestation and stores the
+// new credential under name
+func (s *AuthService) FinishWebAuthnRegistration(ctx context.Context, userID, challengeID, name string, response []byte) (*entities.WebAuthnCredential, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnNotConfigured
+	}
+
+	challenge, err := s.consumeChallenge(ctx, userID, challengeID, entities.WebAuthnPurposeRegistration)
+	if err != nil {
+		return nil, err
+	}
+	session, err := decodeSessionData(challenge)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.webAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Security key"
+	}
+	if len(name) > maxWebAuthnCredentialName {
+		name = name[:maxWebAuthnCredentialName]
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	stored := &entities.WebAuthnCredential{
+		UserID:          userID,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(credential.ID),
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          formatAAGUID(credential.Authenticator.AAGUID),
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            name,
+	}
+	for _, existing := range user.credentials {
+		if existing.CredentialID == stored.CredentialID {
+			return nil, ErrWebAuthnCredentialRegistered
+		}
+	}
+	if err := s.webAuthnRepo.CreateCredential(ctx, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// DeleteWebAuthnCredential removes one of the user's security keys after a
+// confirmation from any of them
+func (s *AuthService) DeleteWebAuthnCredential(ctx context.Context, userID, credentialID string, confirmation *WebAuthnAssertion) error {
+	if s.webAuthn == nil {
+		return ErrWebAuthnNotConfigured
+	}
+
+	user, err := s.webAuthnUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, credential := range user.credentials {
+		if credential.ID == credentialID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrWebAuthnCredentialNotFound
+	}
+	if err := s.checkConfirmation(ctx, user, confirmation); err != nil {
+		return err
+	}
+
+	deleted, err := s.webAuthnRepo.DeleteCredential(ctx, userID, credentialID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// checkConfirmation verifies a confirmation assertion when the user has any credential
+func (s *AuthService) checkConfirmation(ctx context.Context, user *webAuthnUser, confirmation *WebAuthnAssertion) error {
+	if len(user.credentials) == 0 {
+		return nil
+	}
+	if confirmation == nil || confirmation.ChallengeID == "" {
+		return ErrWebAuthnRequired
+	}
+
+	challenge, err := s.consumeChallenge(ctx, user.user.ID, confirmation.ChallengeID, entities.WebAuthnPurposeConfirmation)
+	if err != nil {
+		return err
+	}
+	_, err = s.validateAssertion(ctx, user.user.ID, challenge, confirmation.Response)
+	return err
+}
+
+func (s *AuthService) beginAssertion(ctx context.Context, userID, purpose string, documentHash []byte) (*WebAuthnCeremony, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnNotConfigured
+	}
+
+	user, err := s.webAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.credentials) == 0 {
+		return nil, ErrWebAuthnNoCredentials
+	}
+
+	var opts []webauthn.LoginOption
+	var nonceHex, hashHex string
+	if documentHash != nil {
+		nonce := make([]byte, webAuthnNonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("failed to generate WebAuthn nonce: %w", err)
+		}
+		opts = append(opts, webauthn.WithChallenge(signingChallenge(nonce, documentHash)))
+		nonceHex = hex.EncodeToString(nonce)
+		hashHex = hex.EncodeToString(documentHash)
+	}
+
+	assertion, session, err := s.webAuthn.BeginLogin(user, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin WebAuthn assertion: %w", err)
+	}
+
+	return s.storeCeremony(ctx, userID, purpose, session, assertion, nonceHex, hashHex)
+}
+
+func (s *AuthService) storeCeremony(ctx context.Context, userID, purpose string, session *webauthn.SessionData, options interface{}, nonce, documentHash string) (*WebAuthnCeremony, error) {
+	// Abandoned ceremonies are cleared as new ones start
+	if _, err := s.webAuthnRepo.DeleteExpiredChallenges(ctx, time.Now()); err != nil {
+		fmt.Printf("Warning: failed to delete expired WebAuthn challenges: %v\n", err)
+	}
+
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode WebAuthn session: %w", err)
+	}
+
+	challenge := &entities.WebAuthnChallenge{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		Purpose:      purpose,
+		SessionData:  string(sessionData),
+		Nonce:        nonce,
+		DocumentHash: documentHash,
+		ExpiresAt:    time.Now().Add(s.webAuthnTTL),
+	}
+	if err := s.webAuthnRepo.CreateChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnCeremony{ChallengeID: challenge.ID, Options: options, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+func (s *AuthService) consumeChallenge(ctx context.Context, userID, challengeID, purpose string) (*entities.WebAuthnChallenge, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnNotConfigured
+	}
+	if challengeID == "" {
+		return nil, ErrWebAuthnRequired
+	}
+
+	challenge, err := s.webAuthnRepo.ConsumeChallenge(ctx, userID, challengeID, purpose, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrWebAuthnChallengeNotFound
+	}
+	return challenge, nil
+}
+
+// validateAssertion checks the authenticator's signature and sign counter, then
+// records the use on the stored credential
+func (s *AuthService) validateAssertion(ctx context.Context, userID string, challenge *entities.WebAuthnChallenge, response []byte) (*VerifiedAssertion, error) {
+	session, err := decodeSessionData(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	// A signing challenge must still be the one derived from its document hash
+	if challenge.DocumentHash != "" {
+		nonce, nonceErr := hex.DecodeString(challenge.Nonce)
+		documentHash, hashErr := hex.DecodeString(challenge.DocumentHash)
+		if nonceErr != nil || hashErr != nil ||
+			session.Challenge != base64.RawURLEncoding.EncodeToString(signingChallenge(nonce, documentHash)) {
+			return nil, ErrWebAuthnDocumentMismatch
+		}
+	}
+
+	user, err := s.webAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+	credential, err := s.webAuthn.ValidateLogin(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	var stored *entities.WebAuthnCredential
+	for _, candidate := range user.credentials {
+		if candidate.CredentialID == credentialID {
+			stored = candidate
+			break
+		}
+	}
+	if stored == nil {
+		return nil, ErrWebAuthnCredentialNotFound
+	}
+
+	// A counter that did not advance means a copy of the key may be in use
+	if credential.Authenticator.CloneWarning {
+		fmt.Printf("Warning: WebAuthn credential %s of user %s reported a stale sign count\n", stored.ID, userID)
+		return nil, fmt.Errorf("%w: sign count did not increase", ErrWebAuthnVerificationFailed)
+	}
+
+	stored.SignCount = credential.Authenticator.SignCount
+	stored.BackupState = credential.Flags.BackupState
+	if err := s.webAuthnRepo.UpdateCredentialUse(ctx, stored, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &VerifiedAssertion{Credential: stored, UserVerified: credential.Flags.UserVerified}, nil
+}
+
+func (s *AuthService) webAuthnUser(ctx context.Context, userID string) (*webAuthnUser, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	credentials, err := s.webAuthnRepo.ListCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func decodeSessionData(challenge *entities.WebAuthnChallenge) (*webauthn.SessionData, error) {
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(challenge.SessionData), &session); err != nil {
+		return nil, fmt.Errorf("failed to decode WebAuthn session: %w", err)
+	}
+	return &session, nil
+}
+
+// signingChallenge binds a signing assertion to a document: SHA-256(nonce || hash)
+func signingChallenge(nonce, documentHash []byte) []byte {
+	h := sha256.New()
+	h.Write(nonce)
+	h.Write(documentHash)
+	return h.Sum(nil)
+}
+
+func toLibraryCredential(stored *entities.WebAuthnCredential) (webauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(stored.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+
+	var transports []protocol.AuthenticatorTransport
+	for _, transport := range strings.Split(stored.Transports, ",") {
+		if transport != "" {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+
+	var aaguid []byte
+	if parsed, err := uuid.Parse(stored.AAGUID); err == nil {
+		aaguid = parsed[:]
+	}
+
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       stored.PublicKey,
+		AttestationType: stored.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			BackupEligible: stored.BackupEligible,
+			BackupState:    stored.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    aaguid,
+			SignCount: stored.SignCount,
+		},
+	}, nil
+}
+
+func formatAAGUID(aaguid []byte) string {
+	parsed, err := uuid.FromBytes(aaguid)
+	if err != nil {
+		return ""
+	}
+	return parsed.String()
+}
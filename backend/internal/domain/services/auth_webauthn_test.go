@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+// MockWebAuthnRepository is a mock implementation of WebAuthnRepository
+type MockWebAuthnRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebAuthnRepository) CreateCredential(ctx context.Context, credential *entities.WebAuthnCredential) error {
+	args := m.Called(ctx, credential)
+	return args.Error(0)
+}
+
+func (m *MockWebAuthnRepository) ListCredentials(ctx context.Context, userID string) ([]*entities.WebAuthnCredential, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebAuthnCredential), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) CountCredentials(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) DeleteCredential(ctx context.Context, userID, id string) (bool, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) UpdateCredentialUse(ctx context.Context, credential *entities.WebAuthnCredential, usedAt time.Time) error {
+	args := m.Called(ctx, credential, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockWebAuthnRepository) CreateChallenge(ctx context.Context, challenge *entities.WebAuthnChallenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *MockWebAuthnRepository) ConsumeChallenge(ctx context.Context, userID, id, purpose string, now time.Time) (*entities.WebAuthnChallenge, error) {
+	args := m.Called(ctx, userID, id, purpose, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WebAuthnChallenge), args.Error(1)
+}
+
+func (m *MockWebAuthnRepository) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newWebAuthnTestService(t *testing.T, required bool) (*AuthService, *MockUserRepository, *MockWebAuthnRepository) {
+	userRepo := new(MockUserRepository)
+	webAuthnRepo := new(MockWebAuthnRepository)
+	service := NewAuthService(userRepo, new(MockSessionRepository), "test-secret")
+	require.NoError(t, service.SetWebAuthn(webAuthnRepo, &config.Config{
+		WebAuthnRPID:               "localhost",
+		WebAuthnRPName:             "Digital Signature System",
+		WebAuthnRPOrigins:          "http://localhost:3000",
+		WebAuthnChallengeTTL:       time.Minute,
+		WebAuthnRequiredForSigning: required,
+	}))
+	return service, userRepo, webAuthnRepo
+}
+
+func TestAuthService_SigningAssertionRequired(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, webAuthnRepo := newWebAuthnTestService(t, false)
+	webAuthnRepo.On("CountCredentials", ctx, "user-1").Return(int64(0), nil)
+	webAuthnRepo.On("CountCredentials", ctx, "user-2").Return(int64(1), nil)
+
+	required, err := service.SigningAssertionRequired(ctx, "user-1")
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	required, err = service.SigningAssertionRequired(ctx, "user-2")
+	require.NoError(t, err)
+	assert.True(t, required, "a registered key always guards signing")
+
+	service, _, _ = newWebAuthnTestService(t, true)
+	required, err = service.SigningAssertionRequired(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, required, "policy requires a key for everyone")
+
+	// Without WebAuthn configured nothing is required
+	required, err = NewAuthService(nil, nil, "test-secret").SigningAssertionRequired(ctx, "user-1")
+	require.NoError(t, err)
+	assert.False(t, required)
+}
+
+func TestAuthService_BeginSigningAssertion(t *testing.T) {
+	ctx := context.Background()
+	service, userRepo, webAuthnRepo := newWebAuthnTestService(t, false)
+
+	_, err := service.BeginSigningAssertion(ctx, "user-1", []byte("short"))
+	assert.ErrorIs(t, err, ErrWebAuthnInvalidHash)
+
+	documentHash := sha256.Sum256([]byte("document"))
+	userRepo.On("GetByID", ctx, "user-1").Return(&entities.User{ID: "user-1", Username: "signer"}, nil)
+	webAuthnRepo.On("ListCredentials", ctx, "user-1").Return([]*entities.WebAuthnCredential{}, nil).Once()
+
+	_, err = service.BeginSigningAssertion(ctx, "user-1", documentHash[:])
+	assert.ErrorIs(t, err, ErrWebAuthnNoCredentials)
+
+	webAuthnRepo.On("ListCredentials", ctx, "user-1").Return([]*entities.WebAuthnCredential{
+		{ID: "cred-1", UserID: "user-1", CredentialID: "AQID", PublicKey: []byte{1}, Name: "Key"},
+	}, nil)
+	webAuthnRepo.On("DeleteExpiredChallenges", ctx, mock.Anything).Return(int64(0), nil)
+	var stored *entities.WebAuthnChallenge
+	webAuthnRepo.On("CreateChallenge", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*entities.WebAuthnChallenge)
+	}).Return(nil)
+
+	ceremony, err := service.BeginSigningAssertion(ctx, "user-1", documentHash[:])
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, stored.ID, ceremony.ChallengeID)
+	assert.Equal(t, entities.WebAuthnPurposeSigning, stored.Purpose)
+	assert.Equal(t, hex.EncodeToString(documentHash[:]), stored.DocumentHash)
+
+	// The challenge the authenticator signs is derived from the document hash
+	nonce, err := hex.DecodeString(stored.Nonce)
+	require.NoError(t, err)
+	session, err := decodeSessionData(stored)
+	require.NoError(t, err)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(signingChallenge(nonce, documentHash[:])), session.Challenge)
+}
+
+func TestAuthService_BatchSigningAssertion_Digest(t *testing.T) {
+	ctx := context.Background()
+	service, _, webAuthnRepo := newWebAuthnTestService(t, false)
+
+	_, err := service.BeginBatchSigningAssertion(ctx, "user-1", nil)
+	assert.ErrorIs(t, err, ErrWebAuthnInvalidHash)
+
+	confirmed := BatchDigest([]BatchItem{{BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf"}, File: hashedBatchFile("pdf")}})
+	uploaded := BatchDigest([]BatchItem{{BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf"}, File: hashedBatchFile("other pdf")}})
+	webAuthnRepo.On("ConsumeChallenge", ctx, "user-1", "challenge-1", entities.WebAuthnPurposeBatchSigning, mock.Anything).
+		Return(&entities.WebAuthnChallenge{ID: "challenge-1", UserID: "user-1", DocumentHash: hex.EncodeToString(confirmed)}, nil)
+
+	_, err = service.VerifyBatchSigningAssertion(ctx, "user-1", WebAuthnAssertion{ChallengeID: "challenge-1"}, uploaded)
+	assert.ErrorIs(t, err, ErrWebAuthnDocumentMismatch)
+
+	_, err = service.VerifyBatchSigningAssertion(ctx, "user-1", WebAuthnAssertion{}, confirmed)
+	assert.ErrorIs(t, err, ErrWebAuthnRequired)
+}
+
+func TestAuthService_VerifySigningAssertion_DocumentMismatch(t *testing.T) {
+	ctx := context.Background()
+	service, _, webAuthnRepo := newWebAuthnTestService(t, false)
+
+	signedHash := sha256.Sum256([]byte("document"))
+	uploadedHash := sha256.Sum256([]byte("another document"))
+	webAuthnRepo.On("ConsumeChallenge", ctx, "user-1", "challenge-1", entities.WebAuthnPurposeSigning, mock.Anything).
+		Return(&entities.WebAuthnChallenge{ID: "challenge-1", UserID: "user-1", DocumentHash: hex.EncodeToString(signedHash[:])}, nil)
+	webAuthnRepo.On("ConsumeChallenge", ctx, "user-1", "missing", entities.WebAuthnPurposeSigning, mock.Anything).
+		Return(nil, nil)
+
+	_, err := service.VerifySigningAssertion(ctx, "user-1", WebAuthnAssertion{ChallengeID: "challenge-1"}, uploadedHash[:])
+	assert.ErrorIs(t, err, ErrWebAuthnDocumentMismatch)
+
+	_, err = service.VerifySigningAssertion(ctx, "user-1", WebAuthnAssertion{ChallengeID: "missing"}, signedHash[:])
+	assert.ErrorIs(t, err, ErrWebAuthnChallengeNotFound)
+
+	_, err = service.VerifySigningAssertion(ctx, "user-1", WebAuthnAssertion{}, signedHash[:])
+	assert.ErrorIs(t, err, ErrWebAuthnRequired)
+}
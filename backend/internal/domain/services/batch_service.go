@@ -0,0 +1,562 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrBatchEmpty       = errors.New("batch contains no documents")
+	ErrBatchTooLarge    = errors.New("batch exceeds maximum number of documents")
+	ErrInvalidManifest  = errors.New("invalid batch manifest")
+	ErrBatchNotFound    = errors.New("batch job not found")
+	ErrBatchAccess      = errors.New("access denied: batch job belongs to different user")
+	ErrBatchNotFinished = errors.New("batch job has not finished")
+	// ErrBatchFilesTooLarge is returned when the uploaded files together exceed the batch size
+	ErrBatchFilesTooLarge = errors.New("batch files exceed maximum batch size")
+)
+
+// Batch item result status values
+const (
+	BatchItemSigned = "signed"
+	BatchItemFailed = "failed"
+	// BatchItemExisting marks a PDF that was already signed; the existing
+	// document is reported and nothing is added to the archive
+	BatchItemExisting = "existing"
+)
+
+// DocumentSignerInterface defines the document operations needed by batch signing
+type DocumentSignerInterface interface {
+	SignDocument(ctx context.Context, req *SignDocumentRequest) (*SignDocumentResponse, error)
+}
+
+// BatchService signs many documents at once using a bounded worker pool
+type BatchService struct {
+	batchRepo       repositories.BatchJobRepository
+	documentService DocumentSignerInterface
+	spooler         PDFSpooler
+	config          *config.Config
+}
+
+// BatchManifestEntry holds the per-file metadata supplied with a batch upload
+type BatchManifestEntry struct {
+	Filename     string `json:"filename"`
+	Issuer       string `json:"issuer"`
+	Title        string `json:"title"`
+	LetterNumber string `json:"letter_number"`
+	// LetterNumberScheme is the code of the organization's scheme that
+	// allocates the letter number when LetterNumber is left empty
+	LetterNumberScheme string `json:"letter_number_scheme,omitempty"`
+}
+
+// BatchItem is a single PDF queued for signing in a batch
+type BatchItem struct {
+	BatchManifestEntry
+	File BatchFile
+}
+
+// BatchSignRequest represents a request to sign a batch of documents
+type BatchSignRequest struct {
+	UserID string
+	// OrganizationID signs every item for the organization
+	OrganizationID string
+	// OnBehalfOf signs every item under the user's delegation from this delegator
+	OnBehalfOf string
+	// OnDuplicate is what happens to items that were already signed
+	OnDuplicate string
+	Items       []BatchItem
+}
+
+// BatchItemResult records the outcome of signing one batch item
+type BatchItemResult struct {
+	Filename     string `json:"filename"`
+	LetterNumber string `json:"letter_number"`
+	Status       string `json:"status"`
+	DocumentID   string `json:"document_id,omitempty"`
+	SignedFile   string `json:"signed_file,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// BatchReport summarises a finished batch and is stored with the batch job
+type BatchReport struct {
+	BatchID   string            `json:"batch_id"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+// batchOutcome carries a worker result back to the collector
+type batchOutcome struct {
+	index  int
+	result BatchItemResult
+	// signedPath is the signed PDF, staged until it is added to the archive
+	signedPath string
+}
+
+// NewBatchService creates a new batch signing service
+func NewBatchService(
+	batchRepo repositories.BatchJobRepository,
+	documentService DocumentSignerInterface,
+	spooler PDFSpooler,
+	config *config.Config,
+) *BatchService {
+	return &BatchService{
+		batchRepo:       batchRepo,
+		documentService: documentService,
+		spooler:         spooler,
+		config:          config,
+	}
+}
+
+// SignBatch signs every item concurrently, tracking progress on the batch job record.
+// The stamped PDFs and a report.json are written to a ZIP archive under the storage directory.
+func (s *BatchService) SignBatch(ctx context.Context, req *BatchSignRequest) (*entities.BatchJob, *BatchReport, error) {
+	if len(req.Items) == 0 {
+		return nil, nil, ErrBatchEmpty
+	}
+	if s.config.BatchMaxFiles > 0 && len(req.Items) > s.config.BatchMaxFiles {
+		return nil, nil, fmt.Errorf("%w (%d)", ErrBatchTooLarge, s.config.BatchMaxFiles)
+	}
+
+	job := &entities.BatchJob{
+		UserID:     req.UserID,
+		Status:     entities.BatchStatusRunning,
+		TotalItems: len(req.Items),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := s.batchRepo.Create(ctx, job); err != nil {
+		return nil, nil, fmt.Errorf("failed to create batch job: %w", err)
+	}
+
+	report, err := s.processBatch(ctx, job, req)
+	if err != nil {
+		job.Status = entities.BatchStatusFailed
+		job.Error = err.Error()
+		s.finishJob(ctx, job)
+		return job, nil, err
+	}
+
+	reportJSON, _ := json.Marshal(report)
+	job.Report = string(reportJSON)
+	job.Status = entities.BatchStatusCompleted
+	s.finishJob(ctx, job)
+
+	return job, report, nil
+}
+
+// processBatch runs the worker pool and streams results into the output archive
+func (s *BatchService) processBatch(ctx context.Context, job *entities.BatchJob, req *BatchSignRequest) (*BatchReport, error) {
+	outputDir := filepath.Join(s.config.StorageDir, "batches")
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create batch output directory: %w", err)
+	}
+
+	resultPath := filepath.Join(outputDir, job.ID+".zip")
+	out, err := os.Create(resultPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch archive: %w", err)
+	}
+	defer out.Close()
+	job.ResultPath = resultPath
+
+	archive := zip.NewWriter(out)
+	report := &BatchReport{
+		BatchID: job.ID,
+		Total:   len(req.Items),
+		Items:   make([]BatchItemResult, len(req.Items)),
+	}
+
+	// Workers sign documents; this goroutine is the only writer to the archive and job record
+	for outcome := range s.runWorkers(ctx, req, outputDir) {
+		if outcome.signedPath != "" {
+			if err := writeZipFile(archive, outcome.result.SignedFile, outcome.signedPath); err != nil {
+				outcome.result.Status = BatchItemFailed
+				outcome.result.Error = err.Error()
+			}
+			os.Remove(outcome.signedPath)
+		}
+
+		report.Items[outcome.index] = outcome.result
+		job.Processed++
+		if outcome.result.Status == BatchItemSigned || outcome.result.Status == BatchItemExisting {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		job.UpdatedAt = time.Now()
+		if err := s.batchRepo.Update(ctx, job); err != nil {
+			fmt.Printf("Warning: Failed to update batch job progress: %v\n", err)
+		}
+	}
+
+	report.Succeeded = job.Succeeded
+	report.Failed = job.Failed
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch report: %w", err)
+	}
+	if err := writeZipEntry(archive, "report.json", reportJSON); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize batch archive: %w", err)
+	}
+
+	return report, nil
+}
+
+// runWorkers starts a bounded pool of signing workers and returns their results channel
+func (s *BatchService) runWorkers(ctx context.Context, req *BatchSignRequest, outputDir string) <-chan batchOutcome {
+	workers := s.config.BatchWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(req.Items) {
+		workers = len(req.Items)
+	}
+
+	jobs := make(chan int)
+	results := make(chan batchOutcome)
+	done := make(chan struct{})
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for i := range jobs {
+				results <- s.signItem(ctx, req, i, req.Items[i], outputDir)
+			}
+		}()
+	}
+
+	go func() {
+		for i := range req.Items {
+			jobs <- i
+		}
+		close(jobs)
+		for w := 0; w < workers; w++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	return results
+}
+
+// signItem signs a single batch item and converts any failure into an item result.
+// The PDF is streamed from its spool file and the signed PDF staged in outputDir.
+func (s *BatchService) signItem(ctx context.Context, req *BatchSignRequest, index int, item BatchItem, outputDir string) batchOutcome {
+	outcome := batchOutcome{
+		index: index,
+		result: BatchItemResult{
+			Filename:     item.Filename,
+			LetterNumber: item.LetterNumber,
+			Status:       BatchItemFailed,
+		},
+	}
+
+	if err := ctx.Err(); err != nil {
+		outcome.result.Error = err.Error()
+		return outcome
+	}
+
+	input, err := os.Open(item.File.Path)
+	if err != nil {
+		outcome.result.Error = fmt.Sprintf("failed to read %q: %v", item.Filename, err)
+		return outcome
+	}
+	source, err := s.spooler.SpoolPDF(input)
+	input.Close()
+	if err != nil {
+		outcome.result.Error = err.Error()
+		return outcome
+	}
+	defer source.Close()
+
+	output, err := os.CreateTemp(outputDir, "signing-*.pdf")
+	if err != nil {
+		outcome.result.Error = fmt.Sprintf("failed to stage signed PDF: %v", err)
+		return outcome
+	}
+	defer output.Close()
+
+	response, err := s.documentService.SignDocument(ctx, &SignDocumentRequest{
+		Filename:           item.Filename,
+		Issuer:             item.Issuer,
+		Title:              item.Title,
+		LetterNumber:       item.LetterNumber,
+		LetterNumberScheme: item.LetterNumberScheme,
+		Source:             source,
+		Output:             output,
+		UserID:             req.UserID,
+		OrganizationID:     req.OrganizationID,
+		OnBehalfOf:         req.OnBehalfOf,
+		OnDuplicate:        req.OnDuplicate,
+	})
+	if err == nil && !response.Existing {
+		err = output.Close()
+	}
+	if err != nil || response.Existing {
+		os.Remove(output.Name())
+	}
+	if err != nil {
+		outcome.result.Error = err.Error()
+		return outcome
+	}
+	// A letter number allocated by a scheme is only known now
+	if response.Document.LetterNumber != nil {
+		outcome.result.LetterNumber = *response.Document.LetterNumber
+	}
+	if response.Existing {
+		outcome.result.Status = BatchItemExisting
+		outcome.result.DocumentID = response.Document.ID
+		return outcome
+	}
+
+	outcome.result.Status = BatchItemSigned
+	outcome.result.DocumentID = response.Document.ID
+	outcome.result.SignedFile = "signed_" + item.Filename
+	outcome.signedPath = output.Name()
+	return outcome
+}
+
+// finishJob stamps the completion time and persists the final job state
+func (s *BatchService) finishJob(ctx context.Context, job *entities.BatchJob) {
+	now := time.Now()
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+	if err := s.batchRepo.Update(ctx, job); err != nil {
+		fmt.Printf("Warning: Failed to update batch job: %v\n", err)
+	}
+}
+
+// GetBatchJob retrieves a batch job owned by the user
+func (s *BatchService) GetBatchJob(ctx context.Context, userID, batchID string) (*entities.BatchJob, error) {
+	job, err := s.batchRepo.GetByID(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrBatchNotFound
+	}
+	if job.UserID != userID {
+		return nil, ErrBatchAccess
+	}
+	return job, nil
+}
+
+// GetBatchResultPath returns the location of the result archive for a finished batch
+func (s *BatchService) GetBatchResultPath(ctx context.Context, userID, batchID string) (string, error) {
+	job, err := s.GetBatchJob(ctx, userID, batchID)
+	if err != nil {
+		return "", err
+	}
+	if job.Status != entities.BatchStatusCompleted || job.ResultPath == "" {
+		return "", ErrBatchNotFinished
+	}
+	return job.ResultPath, nil
+}
+
+// ParseBatchManifest parses a CSV or JSON manifest describing each file in a batch.
+// CSV manifests need a header row with filename, issuer, title and letter_number
+// columns; letter_number may be left out for rows that name a letter_number_scheme.
+func ParseBatchManifest(data []byte, name string) ([]BatchManifestEntry, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: manifest is empty", ErrInvalidManifest)
+	}
+
+	var entries []BatchManifestEntry
+	var err error
+	if strings.HasSuffix(strings.ToLower(name), ".json") || trimmed[0] == '[' {
+		entries, err = parseJSONManifest(trimmed)
+	} else {
+		entries, err = parseCSVManifest(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.Filename == "" || entry.Issuer == "" || entry.Title == "" || (entry.LetterNumber == "" && entry.LetterNumberScheme == "") {
+			return nil, fmt.Errorf("%w: entry %d is missing required fields", ErrInvalidManifest, i+1)
+		}
+		if seen[entry.Filename] {
+			return nil, fmt.Errorf("%w: duplicate filename %q", ErrInvalidManifest, entry.Filename)
+		}
+		seen[entry.Filename] = true
+	}
+
+	return entries, nil
+}
+
+func parseJSONManifest(data []byte) ([]BatchManifestEntry, error) {
+	var entries []BatchManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	for i := range entries {
+		entries[i] = trimManifestEntry(entries[i])
+	}
+	return entries, nil
+}
+
+func parseCSVManifest(data []byte) ([]BatchManifestEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%w: manifest has no entries", ErrInvalidManifest)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	required := []string{"filename", "issuer", "title", "letter_number"}
+	if _, ok := columns["letter_number_scheme"]; ok {
+		required = required[:3]
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %q column", ErrInvalidManifest, name)
+		}
+	}
+
+	// Optional columns read as empty when they are missing
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	entries := make([]BatchManifestEntry, 0, len(records)-1)
+	for _, record := range records[1:] {
+		entries = append(entries, trimManifestEntry(BatchManifestEntry{
+			Filename:           field(record, "filename"),
+			Issuer:             field(record, "issuer"),
+			Title:              field(record, "title"),
+			LetterNumber:       field(record, "letter_number"),
+			LetterNumberScheme: field(record, "letter_number_scheme"),
+		}))
+	}
+	return entries, nil
+}
+
+func trimManifestEntry(entry BatchManifestEntry) BatchManifestEntry {
+	return BatchManifestEntry{
+		Filename:           strings.TrimSpace(entry.Filename),
+		Issuer:             strings.TrimSpace(entry.Issuer),
+		Title:              strings.TrimSpace(entry.Title),
+		LetterNumber:       strings.TrimSpace(entry.LetterNumber),
+		LetterNumberScheme: strings.TrimSpace(entry.LetterNumberScheme),
+	}
+}
+
+// BatchDigest binds a batch signing confirmation to the batch: the SHA-256 of
+// the items sorted by the SHA-256 of their PDF, each contributing that hash and
+// its manifest entry's filename, issuer, title, letter number and letter number
+// scheme, each followed by a zero byte. Clients compute the same digest before uploading.
+func BatchDigest(items []BatchItem) []byte {
+	type entry struct {
+		hash  [sha256.Size]byte
+		entry BatchManifestEntry
+	}
+	entries := make([]entry, len(items))
+	for i, item := range items {
+		entries[i] = entry{entry: item.BatchManifestEntry}
+		copy(entries[i].hash[:], item.File.Hash)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].hash[:], entries[j].hash[:]) < 0
+	})
+
+	digest := sha256.New()
+	for _, e := range entries {
+		digest.Write(e.hash[:])
+		for _, field := range []string{e.entry.Filename, e.entry.Issuer, e.entry.Title, e.entry.LetterNumber, e.entry.LetterNumberScheme} {
+			digest.Write([]byte(field))
+			digest.Write([]byte{0})
+		}
+	}
+	return digest.Sum(nil)
+}
+
+// BuildBatchItems pairs manifest entries with uploaded files; every file must be
+// described exactly once
+func BuildBatchItems(manifest []BatchManifestEntry, files map[string]BatchFile) ([]BatchItem, error) {
+	items := make([]BatchItem, 0, len(manifest))
+	used := make(map[string]bool, len(manifest))
+
+	for _, entry := range manifest {
+		if used[entry.Filename] {
+			return nil, fmt.Errorf("%w: duplicate filename %q", ErrInvalidManifest, entry.Filename)
+		}
+		file, ok := files[entry.Filename]
+		if !ok {
+			return nil, fmt.Errorf("%w: no uploaded file named %q", ErrInvalidManifest, entry.Filename)
+		}
+		used[entry.Filename] = true
+		items = append(items, BatchItem{BatchManifestEntry: entry, File: file})
+	}
+
+	for name := range files {
+		if !used[name] {
+			return nil, fmt.Errorf("%w: file %q is not listed in the manifest", ErrInvalidManifest, name)
+		}
+	}
+
+	return items, nil
+}
+
+// writeZipFile streams a file on disk into the archive
+func writeZipFile(archive *zip.Writer, name, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to add %q to archive: %w", name, err)
+	}
+	defer file.Close()
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %q to archive: %w", name, err)
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to write %q to archive: %w", name, err)
+	}
+	return nil
+}
+
+func writeZipEntry(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %q to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %q to archive: %w", name, err)
+	}
+	return nil
+}
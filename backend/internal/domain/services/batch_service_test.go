@@ -0,0 +1,438 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/pdf"
+)
+
+type MockBatchJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockBatchJobRepository) Create(ctx context.Context, job *entities.BatchJob) error {
+	args := m.Called(ctx, job)
+	if job.ID == "" {
+		job.ID = "test-batch-id"
+	}
+	return args.Error(0)
+}
+
+func (m *MockBatchJobRepository) GetByID(ctx context.Context, id string) (*entities.BatchJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BatchJob), args.Error(1)
+}
+
+func (m *MockBatchJobRepository) Update(ctx context.Context, job *entities.BatchJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+type MockDocumentSigner struct {
+	mock.Mock
+}
+
+func (m *MockDocumentSigner) SignDocument(ctx context.Context, req *SignDocumentRequest) (*SignDocumentResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*SignDocumentResponse), args.Error(1)
+}
+
+func TestParseBatchManifest(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		filename      string
+		expectedCount int
+		expectedError string
+	}{
+		{
+			name:          "valid CSV manifest",
+			data:          "filename,issuer,title,letter_number\na.pdf,Registrar,Certificate A,001/REG/2026\nb.pdf,Registrar,Certificate B,002/REG/2026\n",
+			filename:      "manifest.csv",
+			expectedCount: 2,
+		},
+		{
+			name:          "CSV columns in any order",
+			data:          "Letter_Number, Title, Filename, Issuer\n001,Cert,a.pdf,Registrar\n",
+			filename:      "manifest.csv",
+			expectedCount: 1,
+		},
+		{
+			name:          "valid JSON manifest",
+			data:          `[{"filename":"a.pdf","issuer":"Registrar","title":"Cert","letter_number":"001"}]`,
+			filename:      "manifest.json",
+			expectedCount: 1,
+		},
+		{
+			name:          "inline JSON without filename",
+			data:          ` [{"filename":"a.pdf","issuer":"Registrar","title":"Cert","letter_number":"001"}]`,
+			expectedCount: 1,
+		},
+		{
+			name:          "missing CSV column",
+			data:          "filename,issuer,title\na.pdf,Registrar,Cert\n",
+			filename:      "manifest.csv",
+			expectedError: "missing \"letter_number\" column",
+		},
+		{
+			name:          "CSV rows numbered by a scheme",
+			data:          "filename,issuer,title,letter_number_scheme\na.pdf,Registrar,Cert,REG\n",
+			filename:      "manifest.csv",
+			expectedCount: 1,
+		},
+		{
+			name:          "JSON row numbered by a scheme",
+			data:          `[{"filename":"a.pdf","issuer":"Registrar","title":"Cert","letter_number_scheme":"REG"}]`,
+			expectedCount: 1,
+		},
+		{
+			name:          "neither letter number nor scheme",
+			data:          "filename,issuer,title,letter_number,letter_number_scheme\na.pdf,Registrar,Cert,,\n",
+			filename:      "manifest.csv",
+			expectedError: "missing required fields",
+		},
+		{
+			name:          "missing required field",
+			data:          `[{"filename":"a.pdf","issuer":"","title":"Cert","letter_number":"001"}]`,
+			expectedError: "missing required fields",
+		},
+		{
+			name:          "duplicate filename",
+			data:          "filename,issuer,title,letter_number\na.pdf,R,T,1\na.pdf,R,T,2\n",
+			filename:      "manifest.csv",
+			expectedError: "duplicate filename",
+		},
+		{
+			name:          "empty manifest",
+			data:          "  ",
+			expectedError: "manifest is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseBatchManifest([]byte(tt.data), tt.filename)
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidManifest)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, entries, tt.expectedCount)
+			assert.Equal(t, "a.pdf", entries[0].Filename)
+			assert.Equal(t, "Registrar", entries[0].Issuer)
+		})
+	}
+}
+
+func TestBatchSpool_AddZip(t *testing.T) {
+	archive := buildZip(t, map[string][]byte{
+		"certs/a.pdf":       []byte("%PDF-1.4 a"),
+		"b.PDF":             []byte("%PDF-1.4 b"),
+		"notes.txt":         []byte("ignored"),
+		"__MACOSX/._a.pdf":  []byte("ignored"),
+		"certs/.hidden.pdf": []byte("ignored"),
+	})
+
+	spool, err := NewBatchSpool(t.TempDir(), 1<<20, 1<<20)
+	require.NoError(t, err)
+	defer spool.Close()
+	require.NoError(t, spool.AddZip(bytes.NewReader(archive), int64(len(archive))))
+	assert.Len(t, spool.Files, 2)
+	data, err := os.ReadFile(spool.Files["a.pdf"].Path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 a"), data)
+	hash := sha256.Sum256([]byte("%PDF-1.4 b"))
+	assert.Equal(t, hash[:], spool.Files["b.PDF"].Hash)
+	assert.Equal(t, int64(10), spool.Files["b.PDF"].Size)
+
+	// The same names again are duplicates
+	assert.Error(t, spool.AddZip(bytes.NewReader(archive), int64(len(archive))))
+
+	spool, err = NewBatchSpool(t.TempDir(), 1<<20, 1<<20)
+	require.NoError(t, err)
+	defer spool.Close()
+	assert.Error(t, spool.AddZip(bytes.NewReader([]byte("not a zip")), 9))
+}
+
+func TestBatchSpool_Limits(t *testing.T) {
+	// Decompressed sizes are checked while the entries are written
+	bomb := buildZip(t, map[string][]byte{"a.pdf": bytes.Repeat([]byte("x"), 1<<20)})
+
+	spool, err := NewBatchSpool(t.TempDir(), 1<<10, 1<<20)
+	require.NoError(t, err)
+	defer spool.Close()
+	err = spool.AddZip(bytes.NewReader(bomb), int64(len(bomb)))
+	assert.ErrorContains(t, err, "exceeds maximum size")
+	assert.NotErrorIs(t, err, ErrBatchFilesTooLarge)
+
+	spool, err = NewBatchSpool(t.TempDir(), 1<<20, 1<<10)
+	require.NoError(t, err)
+	defer spool.Close()
+	assert.ErrorIs(t, spool.AddZip(bytes.NewReader(bomb), int64(len(bomb))), ErrBatchFilesTooLarge)
+
+	// The total counts every file; a full batch reads nothing more
+	spool, err = NewBatchSpool(t.TempDir(), 1<<20, 10)
+	require.NoError(t, err)
+	defer spool.Close()
+	require.NoError(t, spool.Add("a.pdf", strings.NewReader("0123456789")))
+	assert.ErrorIs(t, spool.Add("b.pdf", strings.NewReader("0")), ErrBatchFilesTooLarge)
+	assert.Error(t, spool.Add("a.pdf", strings.NewReader("")))
+}
+
+func TestBuildBatchItems(t *testing.T) {
+	manifest := []BatchManifestEntry{
+		{Filename: "a.pdf", Issuer: "R", Title: "T", LetterNumber: "1"},
+	}
+
+	items, err := BuildBatchItems(manifest, map[string]BatchFile{"a.pdf": {Path: "/spool/a.pdf"}})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "/spool/a.pdf", items[0].File.Path)
+
+	_, err = BuildBatchItems(manifest, map[string]BatchFile{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	_, err = BuildBatchItems(manifest, map[string]BatchFile{"a.pdf": {}, "extra.pdf": {}})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	// A file described twice would be signed twice
+	_, err = BuildBatchItems(append(manifest, BatchManifestEntry{Filename: "a.pdf", Issuer: "R", Title: "T", LetterNumber: "2"}),
+		map[string]BatchFile{"a.pdf": {}})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, "duplicate filename")
+}
+
+func TestBatchDigest(t *testing.T) {
+	a := BatchItem{BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf", Issuer: "R", Title: "T", LetterNumber: "1"}, File: hashedBatchFile("pdf a")}
+	b := BatchItem{BatchManifestEntry: BatchManifestEntry{Filename: "b.pdf", Issuer: "R", Title: "T", LetterNumber: "2"}, File: hashedBatchFile("pdf b")}
+
+	digest := BatchDigest([]BatchItem{a, b})
+	assert.Len(t, digest, 32)
+	// The upload order does not matter
+	assert.Equal(t, digest, BatchDigest([]BatchItem{b, a}))
+
+	// Neither may a file nor its manifest entry change
+	changed := b
+	changed.File = hashedBatchFile("pdf c")
+	assert.NotEqual(t, digest, BatchDigest([]BatchItem{a, changed}))
+	changed = b
+	changed.LetterNumber = "3"
+	assert.NotEqual(t, digest, BatchDigest([]BatchItem{a, changed}))
+	changed = b
+	changed.LetterNumberScheme = "REG"
+	assert.NotEqual(t, digest, BatchDigest([]BatchItem{a, changed}))
+	// Fields are separated, so text cannot move between them
+	changed = b
+	changed.Issuer, changed.Title = "RT", ""
+	assert.NotEqual(t, digest, BatchDigest([]BatchItem{a, changed}))
+	assert.NotEqual(t, digest, BatchDigest([]BatchItem{a}))
+}
+
+func TestBatchService_SignBatch(t *testing.T) {
+	storageDir := t.TempDir()
+	batchRepo := new(MockBatchJobRepository)
+	signer := new(MockDocumentSigner)
+
+	batchRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+	batchRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+
+	signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+		return req.Filename == "bad.pdf"
+	})).Return(nil, assert.AnError)
+	for _, name := range []string{"a.pdf", "c.pdf"} {
+		filename := name
+		signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+			return req.Filename == filename && req.UserID == "user-123" && req.Source != nil && req.Output != nil
+		})).Run(func(args mock.Arguments) {
+			_, _ = args.Get(1).(*SignDocumentRequest).Output.Write([]byte("signed " + filename))
+		}).Return(&SignDocumentResponse{
+			Document: &entities.Document{ID: "doc-" + filename},
+		}, nil)
+	}
+
+	service := NewBatchService(batchRepo, signer, pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()), &config.Config{
+		StorageDir:    storageDir,
+		BatchWorkers:  2,
+		BatchMaxFiles: 10,
+	})
+
+	items := []BatchItem{
+		{BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf", Issuer: "R", Title: "A", LetterNumber: "1"}, File: spooledBatchFile(t, testSpoolPDF)},
+		{BatchManifestEntry: BatchManifestEntry{Filename: "bad.pdf", Issuer: "R", Title: "B", LetterNumber: "2"}, File: spooledBatchFile(t, testSpoolPDF)},
+		{BatchManifestEntry: BatchManifestEntry{Filename: "c.pdf", Issuer: "R", Title: "C", LetterNumber: "3"}, File: spooledBatchFile(t, testSpoolPDF)},
+	}
+
+	job, report, err := service.SignBatch(context.Background(), &BatchSignRequest{UserID: "user-123", Items: items})
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.BatchStatusCompleted, job.Status)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	assert.NotNil(t, job.CompletedAt)
+	assert.NotEmpty(t, job.Report)
+
+	// Report keeps the manifest order regardless of completion order
+	assert.Equal(t, "a.pdf", report.Items[0].Filename)
+	assert.Equal(t, BatchItemSigned, report.Items[0].Status)
+	assert.Equal(t, "doc-a.pdf", report.Items[0].DocumentID)
+	assert.Equal(t, BatchItemFailed, report.Items[1].Status)
+	assert.NotEmpty(t, report.Items[1].Error)
+
+	reader, err := zip.OpenReader(job.ResultPath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	contents := make(map[string][]byte)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = data
+	}
+	assert.Equal(t, []byte("signed a.pdf"), contents["signed_a.pdf"])
+	assert.Equal(t, []byte("signed c.pdf"), contents["signed_c.pdf"])
+	assert.NotContains(t, contents, "signed_bad.pdf")
+
+	var archivedReport BatchReport
+	require.NoError(t, json.Unmarshal(contents["report.json"], &archivedReport))
+	assert.Equal(t, 2, archivedReport.Succeeded)
+	assert.Equal(t, 1, archivedReport.Failed)
+}
+
+func TestBatchService_SignBatch_Existing(t *testing.T) {
+	batchRepo := new(MockBatchJobRepository)
+	signer := new(MockDocumentSigner)
+
+	batchRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+	batchRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+	signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+		return req.OnDuplicate == DuplicateReturnExisting
+	})).Return(&SignDocumentResponse{Document: &entities.Document{ID: "doc-old"}, Existing: true}, nil)
+
+	service := NewBatchService(batchRepo, signer, pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()), &config.Config{StorageDir: t.TempDir(), BatchWorkers: 1})
+	job, report, err := service.SignBatch(context.Background(), &BatchSignRequest{
+		UserID:      "user-123",
+		OnDuplicate: DuplicateReturnExisting,
+		Items:       []BatchItem{{BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf"}, File: spooledBatchFile(t, testSpoolPDF)}},
+	})
+	require.NoError(t, err)
+
+	// An already signed item counts as done but adds no file to the archive
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, BatchItemExisting, report.Items[0].Status)
+	assert.Equal(t, "doc-old", report.Items[0].DocumentID)
+	assert.Empty(t, report.Items[0].SignedFile)
+}
+
+func TestBatchService_SignBatch_LetterNumberScheme(t *testing.T) {
+	batchRepo := new(MockBatchJobRepository)
+	signer := new(MockDocumentSigner)
+
+	batchRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+	batchRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.BatchJob")).Return(nil)
+	allocated := "007/REG/2026"
+	signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+		return req.LetterNumber == "" && req.LetterNumberScheme == "REG" && req.OrganizationID == "org-1"
+	})).Return(&SignDocumentResponse{Document: &entities.Document{ID: "doc-1", LetterNumber: &allocated}}, nil)
+
+	service := NewBatchService(batchRepo, signer, pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()), &config.Config{StorageDir: t.TempDir(), BatchWorkers: 1})
+	_, report, err := service.SignBatch(context.Background(), &BatchSignRequest{
+		UserID:         "user-123",
+		OrganizationID: "org-1",
+		Items: []BatchItem{{
+			BatchManifestEntry: BatchManifestEntry{Filename: "a.pdf", Issuer: "R", Title: "A", LetterNumberScheme: "REG"},
+			File:               spooledBatchFile(t, testSpoolPDF),
+		}},
+	})
+	require.NoError(t, err)
+
+	// The report names the number the scheme allocated
+	assert.Equal(t, BatchItemSigned, report.Items[0].Status)
+	assert.Equal(t, allocated, report.Items[0].LetterNumber)
+}
+
+func TestBatchService_SignBatch_Limits(t *testing.T) {
+	service := NewBatchService(new(MockBatchJobRepository), new(MockDocumentSigner), nil, &config.Config{BatchMaxFiles: 1})
+
+	_, _, err := service.SignBatch(context.Background(), &BatchSignRequest{UserID: "user-123"})
+	assert.ErrorIs(t, err, ErrBatchEmpty)
+
+	items := make([]BatchItem, 2)
+	_, _, err = service.SignBatch(context.Background(), &BatchSignRequest{UserID: "user-123", Items: items})
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+func TestBatchService_GetBatchJob(t *testing.T) {
+	batchRepo := new(MockBatchJobRepository)
+	batchRepo.On("GetByID", mock.Anything, "batch-1").Return(&entities.BatchJob{ID: "batch-1", UserID: "user-123", Status: entities.BatchStatusRunning}, nil)
+	batchRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+
+	service := NewBatchService(batchRepo, new(MockDocumentSigner), nil, &config.Config{})
+
+	job, err := service.GetBatchJob(context.Background(), "user-123", "batch-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "batch-1", job.ID)
+
+	_, err = service.GetBatchJob(context.Background(), "other-user", "batch-1")
+	assert.ErrorIs(t, err, ErrBatchAccess)
+
+	_, err = service.GetBatchJob(context.Background(), "user-123", "missing")
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+
+	_, err = service.GetBatchResultPath(context.Background(), "user-123", "batch-1")
+	assert.ErrorIs(t, err, ErrBatchNotFinished)
+}
+
+// hashedBatchFile describes a spooled PDF by its hash alone
+func hashedBatchFile(data string) BatchFile {
+	hash := sha256.Sum256([]byte(data))
+	return BatchFile{Size: int64(len(data)), Hash: hash[:]}
+}
+
+// spooledBatchFile writes a PDF to a spool file
+func spooledBatchFile(t *testing.T, data string) BatchFile {
+	t.Helper()
+	file := hashedBatchFile(data)
+	file.Path = filepath.Join(t.TempDir(), "file.pdf")
+	require.NoError(t, os.WriteFile(file.Path, []byte(data), 0640))
+	return file
+}
+
+func buildZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BatchFile is an uploaded batch PDF spooled to disk
+type BatchFile struct {
+	Path string
+	Size int64
+	// Hash is the SHA-256 of the PDF, computed while it was spooled
+	Hash []byte
+}
+
+// BatchSpool holds a batch's PDFs in a temporary directory while the batch is
+// checked and signed, so no PDF of the batch is held in memory. Each PDF and
+// the batch as a whole are capped as they are written, which also guards
+// against decompression bombs in archives.
+type BatchSpool struct {
+	dir          string
+	maxFileSize  int64
+	maxTotalSize int64
+	total        int64
+	// Files are the spooled PDFs keyed by base filename
+	Files map[string]BatchFile
+}
+
+// NewBatchSpool creates a spool in a new directory under tempDir, or the
+// system's temporary directory when tempDir is empty. The caller must Close it.
+func NewBatchSpool(tempDir string, maxFileSize, maxTotalSize int64) (*BatchSpool, error) {
+	dir, err := os.MkdirTemp(tempDir, "batch-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch spool: %w", err)
+	}
+	return &BatchSpool{
+		dir:          dir,
+		maxFileSize:  maxFileSize,
+		maxTotalSize: maxTotalSize,
+		Files:        make(map[string]BatchFile),
+	}, nil
+}
+
+// Add streams one PDF into the spool
+func (s *BatchSpool) Add(name string, reader io.Reader) error {
+	if _, exists := s.Files[name]; exists {
+		return fmt.Errorf("duplicate file %q", name)
+	}
+	// A batch that is already full must not read any further
+	if s.total >= s.maxTotalSize {
+		return fmt.Errorf("%w of %d bytes", ErrBatchFilesTooLarge, s.maxTotalSize)
+	}
+
+	file, err := os.CreateTemp(s.dir, "file-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to spool %q: %w", name, err)
+	}
+	defer file.Close()
+
+	// Read one byte past whichever limit comes first to tell that it was exceeded
+	limit := s.maxFileSize
+	if remaining := s.maxTotalSize - s.total; remaining < limit {
+		limit = remaining
+	}
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hasher), io.LimitReader(reader, limit+1))
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", name, err)
+	}
+	if written > s.maxFileSize {
+		return fmt.Errorf("file %q exceeds maximum size of %d bytes", name, s.maxFileSize)
+	}
+	if written > limit {
+		return fmt.Errorf("%w of %d bytes", ErrBatchFilesTooLarge, s.maxTotalSize)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to spool %q: %w", name, err)
+	}
+
+	s.total += written
+	s.Files[name] = BatchFile{Path: file.Name(), Size: written, Hash: hasher.Sum(nil)}
+	return nil
+}
+
+// AddZip spools every PDF in a ZIP archive, keyed by base filename; other
+// files, directories and hidden or macOS metadata entries are skipped
+func (s *BatchSpool) AddZip(reader io.ReaderAt, size int64) error {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return fmt.Errorf("invalid ZIP archive: %w", err)
+	}
+
+	for _, f := range archive.File {
+		name := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !strings.HasSuffix(strings.ToLower(name), ".pdf") {
+			continue
+		}
+		if _, exists := s.Files[name]; exists {
+			return fmt.Errorf("duplicate file %q in archive", name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %q in archive: %w", name, err)
+		}
+		err = s.Add(name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the spooled files
+func (s *BatchSpool) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// copyBatchFile streams a spooled PDF to dst
+func copyBatchFile(dst string, file BatchFile) error {
+	in, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(filepath.Clean(dst), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
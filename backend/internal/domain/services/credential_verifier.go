@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+// CredentialVerifier checks a username and password against a credential store
+type CredentialVerifier interface {
+	// Name identifies the store in logs
+	Name() string
+	// Verify returns the account the credentials belong to. It returns
+	// ErrInvalidCredentials when the store does not know the username or the
+	// password is wrong, so that the next verifier can be asked.
+	Verify(ctx context.Context, username, password string) (*entities.User, error)
+}
+
+// localCredentialVerifier checks passwords against the bcrypt hashes of local accounts
+type localCredentialVerifier struct {
+	userRepo repositories.UserRepository
+}
+
+// NewLocalCredentialVerifier creates the verifier for local accounts
+func NewLocalCredentialVerifier(userRepo repositories.UserRepository) CredentialVerifier {
+	return &localCredentialVerifier{userRepo: userRepo}
+}
+
+func (v *localCredentialVerifier) Name() string {
+	return "local"
+}
+
+func (v *localCredentialVerifier) Verify(ctx context.Context, username, password string) (*entities.User, error) {
+	user, err := v.userRepo.GetByUsername(ctx, username)
+	if err != nil || user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Accounts from an identity provider or directory have no local password
+	if user.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
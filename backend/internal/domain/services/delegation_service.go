@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrDelegationNotFound  = errors.New("delegation not found")
+	ErrInvalidDelegation   = errors.New("invalid delegation")
+	ErrDelegationAccess    = errors.New("only the delegator, the delegate or an administrator may revoke a delegation")
+	ErrNoActiveDelegation  = errors.New("no active delegation from this user")
+	ErrDelegationsDisabled = errors.New("signing on behalf of another user is not available")
+)
+
+const defaultDelegationMaxDuration = 90 * 24 * time.Hour
+
+// MembershipResolver looks up a user's membership in an organization
+type MembershipResolver interface {
+	ResolveMembership(ctx context.Context, userID, organizationID string) (*entities.OrganizationMember, error)
+}
+
+// DelegationRequest describes a grant from the caller to a delegate. ValidFrom
+// defaults to now.
+type DelegationRequest struct {
+	DelegateID     string     `json:"delegate_id" binding:"required"`
+	OrganizationID string     `json:"organization_id"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     time.Time  `json:"valid_until" binding:"required"`
+	Reason         string     `json:"reason"`
+}
+
+// DelegationService manages grants that let one user sign on another's behalf
+type DelegationService struct {
+	delegationRepo repositories.DelegationRepository
+	userRepo       repositories.UserRepository
+	permissions    PermissionChecker
+	organizations  MembershipResolver
+	maxDuration    time.Duration
+	now            func() time.Time
+}
+
+// NewDelegationService creates the service
+func NewDelegationService(delegationRepo repositories.DelegationRepository, userRepo repositories.UserRepository, cfg *config.Config) *DelegationService {
+	maxDuration := cfg.DelegationMaxDuration
+	if maxDuration <= 0 {
+		maxDuration = defaultDelegationMaxDuration
+	}
+	return &DelegationService{
+		delegationRepo: delegationRepo,
+		userRepo:       userRepo,
+		maxDuration:    maxDuration,
+		now:            time.Now,
+	}
+}
+
+// SetPermissionChecker requires both parties of a grant to hold document:sign
+// and lets user admins revoke any grant
+func (s *DelegationService) SetPermissionChecker(permissions PermissionChecker) {
+	s.permissions = permissions
+}
+
+// SetOrganizations lets grants be scoped to an organization both users belong to
+func (s *DelegationService) SetOrganizations(organizations MembershipResolver) {
+	s.organizations = organizations
+}
+
+// CreateDelegation lets the delegate sign on the delegator's behalf within the
+// request's scope and validity window
+func (s *DelegationService) CreateDelegation(ctx context.Context, delegatorID string, req *DelegationRequest) (*entities.Delegation, error) {
+	now := s.now()
+	validFrom := now
+	if req.ValidFrom != nil && req.ValidFrom.After(now) {
+		validFrom = *req.ValidFrom
+	}
+	switch {
+	case req.DelegateID == delegatorID:
+		return nil, fmt.Errorf("%w: you cannot delegate to yourself", ErrInvalidDelegation)
+	case !req.ValidUntil.After(validFrom):
+		return nil, fmt.Errorf("%w: valid_until must be after valid_from and in the future", ErrInvalidDelegation)
+	case req.ValidUntil.Sub(validFrom) > s.maxDuration:
+		return nil, fmt.Errorf("%w: a delegation may last at most %s", ErrInvalidDelegation, s.maxDuration)
+	}
+
+	if err := s.requireSigner(ctx, delegatorID); err != nil {
+		return nil, err
+	}
+	if err := s.requireSigner(ctx, req.DelegateID); err != nil {
+		return nil, err
+	}
+
+	delegation := &entities.Delegation{
+		DelegatorID: delegatorID,
+		DelegateID:  req.DelegateID,
+		ValidFrom:   validFrom,
+		ValidUntil:  req.ValidUntil,
+		Reason:      strings.TrimSpace(req.Reason),
+		CreatedAt:   now,
+	}
+	if req.OrganizationID != "" {
+		if s.organizations == nil {
+			return nil, fmt.Errorf("%w: organizations are not configured", ErrInvalidDelegation)
+		}
+		for _, userID := range []string{delegatorID, req.DelegateID} {
+			if _, err := s.organizations.ResolveMembership(ctx, userID, req.OrganizationID); err != nil {
+				return nil, err
+			}
+		}
+		delegation.OrganizationID = &req.OrganizationID
+	}
+
+	if err := s.delegationRepo.Create(ctx, delegation); err != nil {
+		return nil, fmt.Errorf("failed to create delegation: %w", err)
+	}
+	return s.GetDelegation(ctx, delegation.ID)
+}
+
+// ListDelegations returns the grants the user gave or received
+func (s *DelegationService) ListDelegations(ctx context.Context, userID string) ([]*entities.Delegation, error) {
+	delegations, err := s.delegationRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// GetDelegation returns the grant with its delegator and delegate
+func (s *DelegationService) GetDelegation(ctx context.Context, id string) (*entities.Delegation, error) {
+	delegation, err := s.delegationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation: %w", err)
+	}
+	if delegation == nil {
+		return nil, ErrDelegationNotFound
+	}
+	return delegation, nil
+}
+
+// RevokeDelegation ends a grant. Either party may revoke it, as may users who
+// manage accounts. Documents already signed under it stay valid.
+func (s *DelegationService) RevokeDelegation(ctx context.Context, actorID, id string) (*entities.Delegation, error) {
+	delegation, err := s.GetDelegation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != delegation.DelegatorID && actorID != delegation.DelegateID {
+		manager, err := hasPermission(ctx, s.permissions, actorID, entities.PermissionUserManage)
+		if err != nil {
+			return nil, err
+		}
+		if !manager {
+			return nil, ErrDelegationAccess
+		}
+	}
+
+	now := s.now()
+	revoked, err := s.delegationRepo.Revoke(ctx, delegation.ID, actorID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke delegation: %w", err)
+	}
+	if !revoked {
+		return nil, fmt.Errorf("%w: the delegation is already revoked", ErrInvalidDelegation)
+	}
+	delegation.RevokedAt = &now
+	delegation.RevokedBy = &actorID
+	return delegation, nil
+}
+
+// AuthorizeSigning returns the grant that lets the delegate sign on the
+// delegator's behalf for the organization ("" for none) right now
+func (s *DelegationService) AuthorizeSigning(ctx context.Context, delegatorID, delegateID, organizationID string) (*entities.Delegation, error) {
+	delegation, err := s.delegationRepo.FindActive(ctx, delegatorID, delegateID, organizationID, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find delegation: %w", err)
+	}
+	if delegation == nil {
+		return nil, ErrNoActiveDelegation
+	}
+
+	// The grant cannot outlive the delegator's own right to sign
+	if err := s.requireSigner(ctx, delegatorID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoActiveDelegation, err)
+	}
+	return delegation, nil
+}
+
+// requireSigner checks that the user is an active person allowed to sign documents
+func (s *DelegationService) requireSigner(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !user.IsActive || user.ServiceAccount {
+		return fmt.Errorf("%w: %s cannot sign documents", ErrInvalidDelegation, user.Username)
+	}
+	if s.permissions != nil {
+		// Without roles configured every active user may sign
+		canSign, err := hasPermission(ctx, s.permissions, userID, entities.PermissionDocumentSign)
+		if err != nil {
+			return err
+		}
+		if !canSign {
+			return fmt.Errorf("%w: %s cannot sign documents", ErrInvalidDelegation, user.Username)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockDelegationRepository struct {
+	mock.Mock
+}
+
+func (m *MockDelegationRepository) Create(ctx context.Context, delegation *entities.Delegation) error {
+	args := m.Called(ctx, delegation)
+	return args.Error(0)
+}
+
+func (m *MockDelegationRepository) GetByID(ctx context.Context, id string) (*entities.Delegation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Delegation), args.Error(1)
+}
+
+func (m *MockDelegationRepository) ListByUser(ctx context.Context, userID string) ([]*entities.Delegation, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Delegation), args.Error(1)
+}
+
+func (m *MockDelegationRepository) FindActive(ctx context.Context, delegatorID, delegateID, organizationID string, at time.Time) (*entities.Delegation, error) {
+	args := m.Called(ctx, delegatorID, delegateID, organizationID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Delegation), args.Error(1)
+}
+
+func (m *MockDelegationRepository) Revoke(ctx context.Context, id, revokedBy string, revokedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, revokedBy, revokedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+// userPermissionChecker grants each user the listed permissions
+type userPermissionChecker map[string][]string
+
+func (p userPermissionChecker) UserHasPermission(ctx context.Context, userID, permission string) (bool, error) {
+	for _, granted := range p[userID] {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stubMembershipResolver knows which users belong to which organizations
+type stubMembershipResolver map[string][]string
+
+func (r stubMembershipResolver) ResolveMembership(ctx context.Context, userID, organizationID string) (*entities.OrganizationMember, error) {
+	for _, member := range r[organizationID] {
+		if member == userID {
+			return &entities.OrganizationMember{OrganizationID: organizationID, UserID: userID}, nil
+		}
+	}
+	return nil, ErrNotOrganizationMember
+}
+
+func newTestDelegationService(now time.Time) (*DelegationService, *MockDelegationRepository, *MockUserRepository) {
+	delegationRepo := new(MockDelegationRepository)
+	userRepo := new(MockUserRepository)
+	service := NewDelegationService(delegationRepo, userRepo, &config.Config{DelegationMaxDuration: 30 * 24 * time.Hour})
+	service.now = func() time.Time { return now }
+	service.SetPermissionChecker(userPermissionChecker{
+		"head":      {entities.PermissionDocumentSign},
+		"secretary": {entities.PermissionDocumentSign},
+		"admin":     {entities.PermissionUserManage},
+	})
+	return service, delegationRepo, userRepo
+}
+
+func TestDelegationService_CreateDelegation(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	t.Run("rejects invalid windows", func(t *testing.T) {
+		service, _, _ := newTestDelegationService(now)
+		for _, req := range []*DelegationRequest{
+			{DelegateID: "head", ValidUntil: now.Add(time.Hour)},
+			{DelegateID: "secretary", ValidUntil: now.Add(-time.Hour)},
+			{DelegateID: "secretary", ValidUntil: now.Add(31 * 24 * time.Hour)},
+		} {
+			_, err := service.CreateDelegation(ctx, "head", req)
+			assert.ErrorIs(t, err, ErrInvalidDelegation)
+		}
+	})
+
+	t.Run("requires a delegate who may sign", func(t *testing.T) {
+		service, _, userRepo := newTestDelegationService(now)
+		userRepo.On("GetByID", ctx, "head").Return(&entities.User{ID: "head", Username: "head", IsActive: true}, nil)
+		userRepo.On("GetByID", ctx, "clerk").Return(&entities.User{ID: "clerk", Username: "clerk", IsActive: true}, nil)
+
+		_, err := service.CreateDelegation(ctx, "head", &DelegationRequest{DelegateID: "clerk", ValidUntil: now.Add(time.Hour)})
+		assert.ErrorIs(t, err, ErrInvalidDelegation)
+	})
+
+	t.Run("scopes the grant to an organization both users belong to", func(t *testing.T) {
+		service, delegationRepo, userRepo := newTestDelegationService(now)
+		service.SetOrganizations(stubMembershipResolver{"org-1": {"head", "secretary"}, "org-2": {"head"}})
+		userRepo.On("GetByID", ctx, "head").Return(&entities.User{ID: "head", Username: "head", IsActive: true}, nil)
+		userRepo.On("GetByID", ctx, "secretary").Return(&entities.User{ID: "secretary", Username: "secretary", IsActive: true}, nil)
+
+		_, err := service.CreateDelegation(ctx, "head", &DelegationRequest{DelegateID: "secretary", OrganizationID: "org-2", ValidUntil: now.Add(time.Hour)})
+		assert.ErrorIs(t, err, ErrNotOrganizationMember)
+
+		delegationRepo.On("Create", ctx, mock.MatchedBy(func(d *entities.Delegation) bool {
+			return d.DelegatorID == "head" && d.DelegateID == "secretary" && d.OrganizationID != nil && *d.OrganizationID == "org-1" &&
+				d.ValidFrom.Equal(now) && d.Reason == "Annual leave"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*entities.Delegation).ID = "delegation-1"
+		}).Return(nil)
+		delegationRepo.On("GetByID", ctx, "delegation-1").Return(&entities.Delegation{ID: "delegation-1"}, nil)
+
+		delegation, err := service.CreateDelegation(ctx, "head", &DelegationRequest{
+			DelegateID:     "secretary",
+			OrganizationID: "org-1",
+			ValidUntil:     now.Add(14 * 24 * time.Hour),
+			Reason:         " Annual leave ",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "delegation-1", delegation.ID)
+		delegationRepo.AssertExpectations(t)
+	})
+}
+
+func TestDelegationService_RevokeDelegation(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	service, delegationRepo, _ := newTestDelegationService(now)
+	delegationRepo.On("GetByID", ctx, "delegation-1").Return(&entities.Delegation{ID: "delegation-1", DelegatorID: "head", DelegateID: "secretary"}, nil)
+
+	_, err := service.RevokeDelegation(ctx, "stranger", "delegation-1")
+	assert.ErrorIs(t, err, ErrDelegationAccess)
+
+	delegationRepo.On("Revoke", ctx, "delegation-1", "admin", now).Return(true, nil).Once()
+	delegation, err := service.RevokeDelegation(ctx, "admin", "delegation-1")
+	require.NoError(t, err)
+	require.NotNil(t, delegation.RevokedAt)
+	assert.Equal(t, "admin", *delegation.RevokedBy)
+
+	delegationRepo.On("Revoke", ctx, "delegation-1", "head", now).Return(false, nil).Once()
+	_, err = service.RevokeDelegation(ctx, "head", "delegation-1")
+	assert.ErrorIs(t, err, ErrInvalidDelegation)
+}
+
+func TestDelegationService_AuthorizeSigning(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	service, delegationRepo, userRepo := newTestDelegationService(now)
+
+	delegationRepo.On("FindActive", ctx, "head", "clerk", "", now).Return(nil, nil)
+	_, err := service.AuthorizeSigning(ctx, "head", "clerk", "")
+	assert.ErrorIs(t, err, ErrNoActiveDelegation)
+
+	grant := &entities.Delegation{ID: "delegation-1", DelegatorID: "head", DelegateID: "secretary"}
+	delegationRepo.On("FindActive", ctx, "head", "secretary", "", now).Return(grant, nil)
+	userRepo.On("GetByID", ctx, "head").Return(&entities.User{ID: "head", Username: "head", IsActive: true}, nil).Once()
+	found, err := service.AuthorizeSigning(ctx, "head", "secretary", "")
+	require.NoError(t, err)
+	assert.Equal(t, "delegation-1", found.ID)
+
+	// The grant lapses with the delegator's account
+	userRepo.On("GetByID", ctx, "head").Return(&entities.User{ID: "head", Username: "head", IsActive: false}, nil).Once()
+	_, err = service.AuthorizeSigning(ctx, "head", "secretary", "")
+	assert.ErrorIs(t, err, ErrNoActiveDelegation)
+}
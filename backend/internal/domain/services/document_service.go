@@ -2,10 +2,15 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +21,14 @@ import (
 	"digital-signature-system/internal/infrastructure/pdf"
 )
 
+// ErrInvalidSearch is returned for search parameters or cursors that cannot be used
+var ErrInvalidSearch = errors.New("invalid search")
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
 // SignatureServiceInterface defines the interface for signature operations
 type SignatureServiceInterface interface {
 	SignDocument(documentHash []byte) (*crypto.SignatureData, error)
@@ -30,6 +43,10 @@ type PDFServiceInterface interface {
 	GenerateQRCodeWithCenterLabel(url string, label string, size int) ([]byte, error)
 	InjectQRCode(pdfData []byte, qrCodeData pdf.QRCodeData, position *pdf.QRPosition) ([]byte, error)
 	ReadPDFFromReader(reader io.Reader) ([]byte, error)
+	SpoolPDF(reader io.Reader) (*pdf.SpooledPDF, error)
+	InjectQRCodeFromSpool(src *pdf.SpooledPDF, qrCodeData pdf.QRCodeData, position *pdf.QRPosition, w io.Writer) error
+	ExtractContent(pdfData []byte) (*pdf.PDFContent, error)
+	ExtractContentFromSpool(src *pdf.SpooledPDF) (*pdf.PDFContent, error)
 }
 
 // DocumentService handles all document-related business logic
@@ -38,6 +55,48 @@ type DocumentService struct {
 	signatureService SignatureServiceInterface
 	pdfService       PDFServiceInterface
 	config           *config.Config
+	events           EventPublisher
+	permissions      PermissionChecker
+	organizations    OrganizationResolver
+	delegations      DelegationAuthorizer
+	letterNumbers    LetterNumberSchemes
+}
+
+// PermissionChecker reports whether a user's role grants a permission
+type PermissionChecker interface {
+	UserHasPermission(ctx context.Context, userID, permission string) (bool, error)
+}
+
+// hasPermission reports whether the user's role grants permission; without a
+// checker no role grants anything
+func hasPermission(ctx context.Context, permissions PermissionChecker, userID, permission string) (bool, error) {
+	if permissions == nil {
+		return false, nil
+	}
+	allowed, err := permissions.UserHasPermission(ctx, userID, permission)
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", permission, err)
+	}
+	return allowed, nil
+}
+
+// OrganizationResolver supplies an organization's settings and signing keys
+type OrganizationResolver interface {
+	GetOrganization(ctx context.Context, id string) (*entities.Organization, error)
+	SignerFor(ctx context.Context, organizationID string) (SignatureServiceInterface, string, error)
+	VerifierFor(ctx context.Context, organizationID, keyID string) (SignatureServiceInterface, error)
+}
+
+// DelegationAuthorizer finds the grant that lets a delegate sign on a delegator's behalf
+type DelegationAuthorizer interface {
+	AuthorizeSigning(ctx context.Context, delegatorID, delegateID, organizationID string) (*entities.Delegation, error)
+}
+
+// LetterNumberSchemes finds the scheme that numbers an organization's documents
+type LetterNumberSchemes interface {
+	SchemeFor(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error)
+	// SchemeMatching finds a scheme whose format the letter number follows
+	SchemeMatching(ctx context.Context, organizationID, letterNumber string) (*entities.LetterNumberScheme, error)
 }
 
 // SignDocumentRequest represents the request to sign a document
@@ -48,6 +107,31 @@ type SignDocumentRequest struct {
 	LetterNumber string `json:"letter_number" binding:"required"`
 	PDFData      []byte `json:"-"` // PDF file data
 	UserID       string `json:"-"` // Set from authentication context
+	// OrganizationID signs the document for an organization with its key and branding
+	OrganizationID string `json:"-"`
+	// OnBehalfOf is the delegator when UserID signs under a delegation; the
+	// delegator owns the signed document
+	OnBehalfOf string `json:"-"`
+	// OnDuplicate is what happens when the PDF was already signed: reject,
+	// return_existing or new_version; empty uses the configured policy
+	OnDuplicate string `json:"-"`
+	// LetterNumberScheme is the code of the organization's scheme that allocates
+	// the letter number; without it and a LetterNumber the default scheme is used
+	LetterNumberScheme string `json:"-"`
+	// Private keeps the document from being found by verifying its file
+	Private bool `json:"-"`
+	// Visibility is how much the public verification page shows: public,
+	// minimal or access_code; empty is public
+	Visibility string `json:"-"`
+	// AccessCode is the access code an access_code document gets when it was
+	// already handed out, as for a queued request; empty generates one
+	AccessCode string `json:"-"`
+
+	// Source is an already validated and hashed upload; when set, PDFData is ignored
+	Source *pdf.SpooledPDF `json:"-"`
+	// Output receives a copy of the signed PDF for a Source upload, which is also
+	// kept in storage for download
+	Output io.Writer `json:"-"`
 }
 
 // SignDocumentResponse represents the response after signing a document
@@ -55,6 +139,13 @@ type SignDocumentResponse struct {
 	Document       *entities.Document `json:"document"`
 	SignedPDFData  []byte             `json:"signed_pdf_data,omitempty"`
 	QRCodeImageURL string             `json:"qr_code_image_url,omitempty"`
+	// Existing is set when the PDF was already signed and that document was returned
+	Existing bool `json:"existing,omitempty"`
+	// SimilarDocuments are signed documents with nearly the same text
+	SimilarDocuments []SimilarDocument `json:"similar_documents,omitempty"`
+	// AccessCode unlocks the verification page of an access_code document. It
+	// is only stored hashed, so this is the one time it is returned.
+	AccessCode string `json:"access_code,omitempty"`
 }
 
 // GetDocumentsRequest represents the request to get documents
@@ -74,6 +165,39 @@ type GetDocumentsResponse struct {
 	TotalPages int                  `json:"total_pages"`
 }
 
+// SearchDocumentsRequest searches the caller's documents. Sort defaults to
+// relevance with a query and to newest first without one.
+type SearchDocumentsRequest struct {
+	Query       string
+	Issuer      string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        string
+	Ascending   bool
+	Limit       int
+	// Cursor is the next_cursor of the previous page
+	Cursor string
+	// All searches every document in the organization; it needs document:read:any
+	All    bool
+	UserID string
+}
+
+// SearchDocumentsResponse is one page of search results with facet counts
+type SearchDocumentsResponse struct {
+	Documents  []*entities.Document        `json:"documents"`
+	Total      int64                       `json:"total"`
+	Facets     repositories.DocumentFacets `json:"facets"`
+	NextCursor string                      `json:"next_cursor,omitempty"`
+}
+
+// searchCursor ties a page cursor to the sort it was made for
+type searchCursor struct {
+	Sort      string `json:"s"`
+	Ascending bool   `json:"a,omitempty"`
+	repositories.DocumentCursor
+}
+
 // NewDocumentService creates a new document service
 func NewDocumentService(
 	documentRepo repositories.DocumentRepository,
@@ -89,100 +213,482 @@ func NewDocumentService(
 	}
 }
 
+// SetEventPublisher registers a publisher for document lifecycle events
+func (s *DocumentService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetPermissionChecker lets users whose role grants document:read:any read
+// documents they do not own
+func (s *DocumentService) SetPermissionChecker(permissions PermissionChecker) {
+	s.permissions = permissions
+}
+
+// SetOrganizations lets documents be signed for organizations with their own
+// keys, issuer identity and branding
+func (s *DocumentService) SetOrganizations(organizations OrganizationResolver) {
+	s.organizations = organizations
+}
+
+// SetDelegations lets users sign documents on behalf of others who granted them a delegation
+func (s *DocumentService) SetDelegations(delegations DelegationAuthorizer) {
+	s.delegations = delegations
+}
+
+// SetLetterNumbers lets organizations' documents be numbered by their letter number schemes
+func (s *DocumentService) SetLetterNumbers(schemes LetterNumberSchemes) {
+	s.letterNumbers = schemes
+}
+
 // SignDocument signs a PDF document and generates QR code
 func (s *DocumentService) SignDocument(ctx context.Context, req *SignDocumentRequest) (*SignDocumentResponse, error) {
-	// Validate PDF data
-	if err := s.pdfService.ValidatePDF(req.PDFData); err != nil {
-		return nil, fmt.Errorf("invalid PDF: %w", err)
+	policy, err := s.duplicatePolicy(req.OnDuplicate)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := documentVisibility(req.Visibility)
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate document hash
-	documentHash, err := s.pdfService.CalculateHash(req.PDFData)
+	// A delegate signs with the delegator as the document's owner
+	delegation, err := s.authorizeDelegation(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate document hash: %w", err)
+		return nil, err
 	}
 
-	// Create digital signature
-	signatureData, err := s.signatureService.SignDocument(documentHash)
+	documentHash, fileSize, err := s.hashRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign document: %w", err)
+		return nil, err
+	}
+
+	// Documents of an organization are signed with its key under its issuer name
+	signer, keyID, org, err := s.signerFor(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	issuer := req.Issuer
+	if org != nil {
+		ctx = repositories.WithOrganization(ctx, org.ID)
+		if org.IssuerName != "" {
+			issuer = org.IssuerName
+		}
+	}
+
+	// The same file is signed once unless the request asks for a new version
+	content := s.extractContent(req)
+	duplicates, err := s.findDuplicates(ctx, ownerID(req, delegation), org, base64.StdEncoding.EncodeToString(documentHash), content)
+	if err != nil {
+		return nil, err
+	}
+	if existing := duplicates.Existing; existing != nil {
+		switch policy {
+		case DuplicateReturnExisting:
+			allowed, err := s.canAccess(ctx, existing, req.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				return nil, duplicateError(existing)
+			}
+			return &SignDocumentResponse{Document: existing, Existing: true}, nil
+		case DuplicateReject:
+			return nil, duplicateError(existing)
+		}
+	}
+
+	// The letter number is typed in or allocated from the organization's scheme
+	scheme, err := s.letterNumberScheme(ctx, req, org)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create document entity
 	document := &entities.Document{
-		UserID:        req.UserID,
-		Filename:      req.Filename,
-		Issuer:        req.Issuer,
-		Title:         &req.Title,        // Convert string to *string
-		LetterNumber:  &req.LetterNumber, // Convert string to *string
-		DocumentHash:  base64.StdEncoding.EncodeToString(documentHash),
-		SignatureData: s.encodeSignatureData(signatureData),
-		FileSize:      int64(len(req.PDFData)),
-		Status:        "active",
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-	}
-
-	// Generate QR code data
-	qrCodeData := pdf.QRCodeData{
-		DocID:     document.ID, // Will be set by GORM BeforeCreate
-		Hash:      document.DocumentHash,
-		Signature: document.SignatureData,
-		Timestamp: document.CreatedAt.Unix(),
-	}
-
-	// Store QR code data as JSON
-	qrCodeJSON, err := json.Marshal(qrCodeData)
+		UserID:       req.UserID,
+		KeyID:        keyID,
+		Filename:     req.Filename,
+		Issuer:       issuer,
+		Title:        &req.Title,        // Convert string to *string
+		LetterNumber: &req.LetterNumber, // Convert string to *string
+		DocumentHash: base64.StdEncoding.EncodeToString(documentHash),
+		FileSize:     fileSize,
+		Status:       "active",
+		Version:      1,
+		Private:      req.Private,
+		Visibility:   visibility,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if org != nil {
+		document.OrganizationID = &org.ID
+	}
+	if delegation != nil {
+		document.UserID = delegation.DelegatorID
+		document.SignedByID = &delegation.DelegateID
+		document.DelegationID = &delegation.ID
+	}
+	if existing := duplicates.Existing; existing != nil {
+		document.Version = existing.Version + 1
+		document.PreviousVersionID = &existing.ID
+	}
+	applyContent(document, content)
+
+	// The access code is printed next to the QR code and only its hash is kept
+	var accessCode string
+	if visibility == entities.DocumentVisibilityAccessCode {
+		if req.AccessCode != "" {
+			accessCode = req.AccessCode
+			document.AccessCodeHash, err = hashAccessCode(accessCode)
+		} else {
+			accessCode, document.AccessCodeHash, err = newAccessCode()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// seal creates the digital signature and the QR code data; an allocated
+	// letter number is only known inside the transaction that stores the document
+	var qrCodeData pdf.QRCodeData
+	seal := func() error {
+		signatureData, err := signer.SignDocument(signedDigest(documentHash, document))
+		if err != nil {
+			return fmt.Errorf("failed to sign document: %w", err)
+		}
+		document.SignatureData = s.encodeSignatureData(signatureData)
+
+		qrCodeData = pdf.QRCodeData{
+			DocID:     document.ID, // Will be set by GORM BeforeCreate
+			Hash:      document.DocumentHash,
+			Signature: document.SignatureData,
+			Timestamp: document.CreatedAt.Unix(),
+		}
+		if document.LetterNumberSchemeID != nil {
+			qrCodeData.LetterNumber = *document.LetterNumber
+		}
+
+		// Store QR code data as JSON
+		qrCodeJSON, err := json.Marshal(qrCodeData)
+		if err != nil {
+			return fmt.Errorf("failed to marshal QR code data: %w", err)
+		}
+		document.QRCodeData = string(qrCodeJSON)
+		return nil
+	}
+
+	// The signed PDF is kept for download; it is staged before the document is
+	// saved so a storage failure does not leave a document without its file
+	stored, err := s.createSignedPDF()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal QR code data: %w", err)
+		return nil, err
 	}
-	document.QRCodeData = string(qrCodeJSON)
+	defer func() {
+		stored.Close()
+		os.Remove(stored.Name())
+	}()
 
 	// Save document to database
-	if err := s.documentRepo.Create(ctx, document); err != nil {
-		return nil, fmt.Errorf("failed to save document: %w", err)
+	if scheme != nil {
+		document.LetterNumberSchemeID = &scheme.ID
+		err := s.documentRepo.CreateNumbered(ctx, document, scheme.ID, document.CreatedAt.Year(), func(seq int64) error {
+			letterNumber := FormatLetterNumber(scheme, seq, document.CreatedAt)
+			document.LetterNumber = &letterNumber
+			return seal()
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := seal(); err != nil {
+			return nil, err
+		}
+		if err := s.documentRepo.Create(ctx, document); err != nil {
+			return nil, fmt.Errorf("failed to save document: %w", err)
+		}
 	}
 
-	// Generate verification URL using config BaseURL
-	verifyURL := fmt.Sprintf("%s/verify/%s", s.config.BaseURL, document.ID)
+	// Generate verification URL on the organization's domain or config BaseURL
+	verifyURL := s.verifyURL(org, document.ID)
 
-	// Generate QR code with center label (issuer name)
-	_, err = s.pdfService.GenerateQRCodeWithCenterLabel(verifyURL, req.Issuer, 256)
+	// Generate QR code with center label (stamp label or issuer name)
+	_, err = s.pdfService.GenerateQRCodeWithCenterLabel(verifyURL, stampLabel(org, issuer), 256)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate QR code with center label: %w", err)
 	}
 
 	// Update QR code data with the actual document ID and verification URL
 	qrCodeData.DocID = document.ID
-	qrCodeJSON, err = json.Marshal(qrCodeData)
+	qrCodeJSON, err := json.Marshal(qrCodeData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal QR code data: %w", err)
 	}
 	document.QRCodeData = string(qrCodeJSON)
 
+	// Try to inject QR code into PDF (may fail in development without license).
+	// The stamped file's hash is kept so it can be verified by file as well.
+	var signedPDFData []byte
+	if req.Source != nil {
+		stamped := sha256.New()
+		sinks := []io.Writer{stored, stamped}
+		if req.Output != nil {
+			sinks = append(sinks, req.Output)
+		}
+		if s.writeSignedPDF(req.Source, qrCodeData, stampPosition(org, accessCodeCaption(accessCode)), io.MultiWriter(sinks...)) {
+			document.StampedHash = base64.StdEncoding.EncodeToString(stamped.Sum(nil))
+		}
+	} else {
+		modifiedPDF, err := s.pdfService.InjectQRCode(req.PDFData, qrCodeData, stampPosition(org, accessCodeCaption(accessCode)))
+		if err != nil {
+			// Log the error but don't fail the entire operation
+			// In development, this will fail due to UniPDF license requirements
+			fmt.Printf("Warning: Failed to inject QR code into PDF: %v\n", err)
+			signedPDFData = req.PDFData // Return original PDF
+		} else {
+			signedPDFData = modifiedPDF
+			stampedHash := sha256.Sum256(modifiedPDF)
+			document.StampedHash = base64.StdEncoding.EncodeToString(stampedHash[:])
+		}
+		if _, err := stored.Write(signedPDFData); err != nil {
+			return nil, fmt.Errorf("failed to store signed PDF: %w", err)
+		}
+	}
+	if err := s.keepSignedPDF(stored, document.ID); err != nil {
+		return nil, err
+	}
+
 	// Update document with correct QR code data
 	if err := s.documentRepo.Update(ctx, document); err != nil {
 		return nil, fmt.Errorf("failed to update document with QR code: %w", err)
 	}
 
-	// Try to inject QR code into PDF (may fail in development without license)
-	var signedPDFData []byte
-	modifiedPDF, err := s.pdfService.InjectQRCode(req.PDFData, qrCodeData, nil)
-	if err != nil {
-		// Log the error but don't fail the entire operation
-		// In development, this will fail due to UniPDF license requirements
-		fmt.Printf("Warning: Failed to inject QR code into PDF: %v\n", err)
-		signedPDFData = req.PDFData // Return original PDF
-	} else {
-		signedPDFData = modifiedPDF
+	if s.events != nil {
+		s.events.Publish(ctx, entities.WebhookEventDocumentSigned, document.UserID, document.OrganizationID, documentEventData(document))
 	}
 
 	return &SignDocumentResponse{
-		Document:      document,
-		SignedPDFData: signedPDFData,
+		Document:         document,
+		SignedPDFData:    signedPDFData,
+		SimilarDocuments: duplicates.Similar,
+		AccessCode:       accessCode,
 	}, nil
 }
 
+// letterNumberScheme returns the scheme that allocates the document's letter
+// number, or nil when the request brings its own or none applies. A typed
+// number may not follow one of the organization's schemes, so it cannot
+// collide with a number the scheme allocates.
+func (s *DocumentService) letterNumberScheme(ctx context.Context, req *SignDocumentRequest, org *entities.Organization) (*entities.LetterNumberScheme, error) {
+	if req.LetterNumber != "" {
+		if req.LetterNumberScheme != "" {
+			return nil, ErrLetterNumberConflict
+		}
+		if org == nil || s.letterNumbers == nil {
+			return nil, nil
+		}
+		scheme, err := s.letterNumbers.SchemeMatching(ctx, org.ID, req.LetterNumber)
+		if err != nil {
+			return nil, err
+		}
+		if scheme != nil {
+			return nil, fmt.Errorf("%w: %s is numbered by scheme %s", ErrLetterNumberReserved, req.LetterNumber, scheme.Code)
+		}
+		return nil, nil
+	}
+	if org == nil || s.letterNumbers == nil {
+		if req.LetterNumberScheme != "" {
+			return nil, ErrLetterNumberSchemeNotFound
+		}
+		return nil, nil
+	}
+	scheme, err := s.letterNumbers.SchemeFor(ctx, org.ID, req.LetterNumberScheme)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == nil {
+		return nil, ErrLetterNumberRequired
+	}
+	return scheme, nil
+}
+
+// authorizeDelegation returns the grant a delegate signs under, or nil when
+// users sign for themselves
+func (s *DocumentService) authorizeDelegation(ctx context.Context, req *SignDocumentRequest) (*entities.Delegation, error) {
+	if req.OnBehalfOf == "" || req.OnBehalfOf == req.UserID {
+		return nil, nil
+	}
+	if s.delegations == nil {
+		return nil, ErrDelegationsDisabled
+	}
+	return s.delegations.AuthorizeSigning(ctx, req.OnBehalfOf, req.UserID, req.OrganizationID)
+}
+
+// hashRequest returns the SHA-256 hash and size of the request's PDF
+func (s *DocumentService) hashRequest(req *SignDocumentRequest) ([]byte, int64, error) {
+	if req.Source != nil {
+		// Spooled uploads were validated and hashed while streaming to disk
+		return req.Source.Hash(), req.Source.Size(), nil
+	}
+
+	// Validate PDF data
+	if err := s.pdfService.ValidatePDF(req.PDFData); err != nil {
+		return nil, 0, fmt.Errorf("invalid PDF: %w", err)
+	}
+
+	// Calculate document hash
+	documentHash, err := s.pdfService.CalculateHash(req.PDFData)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to calculate document hash: %w", err)
+	}
+	return documentHash, int64(len(req.PDFData)), nil
+}
+
+// signerFor returns the key that signs for the organization, or the server key
+// outside any organization
+func (s *DocumentService) signerFor(ctx context.Context, organizationID string) (SignatureServiceInterface, string, *entities.Organization, error) {
+	if organizationID == "" {
+		return s.signatureService, "", nil, nil
+	}
+	if s.organizations == nil {
+		return nil, "", nil, fmt.Errorf("organizations are not configured")
+	}
+
+	org, err := s.organizations.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	signer, keyID, err := s.organizations.SignerFor(ctx, org.ID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return signer, keyID, org, nil
+}
+
+// documentOrganization returns the organization a document was signed for, or nil
+func (s *DocumentService) documentOrganization(ctx context.Context, document *entities.Document) *entities.Organization {
+	if document.OrganizationID == nil || s.organizations == nil {
+		return nil
+	}
+	org, err := s.organizations.GetOrganization(ctx, *document.OrganizationID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get organization %s: %v\n", *document.OrganizationID, err)
+		return nil
+	}
+	return org
+}
+
+// verifyURL links to the verification page on the organization's domain when it has one
+func (s *DocumentService) verifyURL(org *entities.Organization, documentID string) string {
+	baseURL := s.config.BaseURL
+	if org != nil && org.VerificationBaseURL != "" {
+		baseURL = org.VerificationBaseURL
+	}
+	return fmt.Sprintf("%s/verify/%s", baseURL, documentID)
+}
+
+// stampLabel is the text in the centre of the QR code
+func stampLabel(org *entities.Organization, issuer string) string {
+	if org != nil && org.StampLabel != "" {
+		return org.StampLabel
+	}
+	return issuer
+}
+
+// stampPosition places the QR code where the organization's branding asks for,
+// with the caption under it; nil uses the default position
+func stampPosition(org *entities.Organization, caption string) *pdf.QRPosition {
+	if caption == "" && (org == nil || (org.StampX == 0 && org.StampY == 0 && org.StampWidth == 0)) {
+		return nil
+	}
+	position := pdf.DefaultQRPosition()
+	position.Caption = caption
+	if org == nil {
+		return &position
+	}
+	if org.StampX > 0 {
+		position.X = org.StampX
+	}
+	if org.StampY > 0 {
+		position.Y = org.StampY
+	}
+	if org.StampWidth > 0 {
+		position.Width = org.StampWidth
+		position.Height = org.StampWidth
+	}
+	return &position
+}
+
+// accessCodeCaption is printed under the QR code of an access_code document
+func accessCodeCaption(accessCode string) string {
+	if accessCode == "" {
+		return ""
+	}
+	return "Access code: " + accessCode
+}
+
+// extractContent reads the PDF's metadata and text so they can be stored and
+// searched. A PDF that cannot be read is still signed, just without them.
+func (s *DocumentService) extractContent(req *SignDocumentRequest) *pdf.PDFContent {
+	var content *pdf.PDFContent
+	var err error
+	if req.Source != nil {
+		content, err = s.pdfService.ExtractContentFromSpool(req.Source)
+	} else {
+		content, err = s.pdfService.ExtractContent(req.PDFData)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to extract PDF content: %v\n", err)
+		return nil
+	}
+	return content
+}
+
+// applyContent stores the extracted metadata and text with the document
+func applyContent(document *entities.Document, content *pdf.PDFContent) {
+	if content == nil {
+		return
+	}
+
+	metadata := &entities.DocumentMetadata{
+		PDFVersion:    content.Version,
+		PageCount:     content.Info.NumPages,
+		Encrypted:     content.Encrypted,
+		Title:         content.Info.Title,
+		Author:        content.Info.Author,
+		Subject:       content.Info.Subject,
+		Keywords:      content.Info.Keywords,
+		Creator:       content.Info.Creator,
+		Producer:      content.Info.Producer,
+		CreationDate:  content.Info.CreationDate,
+		ModifiedDate:  content.Info.ModifiedDate,
+		XMP:           content.XMP,
+		TextTruncated: content.TextTruncated,
+	}
+	texts := make([]string, len(content.Pages))
+	for i, page := range content.Pages {
+		metadata.Pages = append(metadata.Pages, entities.PageMetadata{
+			Number:   page.Number,
+			Width:    page.Width,
+			Height:   page.Height,
+			Rotation: page.Rotation,
+		})
+		texts[i] = page.Text
+	}
+	for _, file := range content.EmbeddedFiles {
+		metadata.EmbeddedFiles = append(metadata.EmbeddedFiles, entities.EmbeddedFileMetadata{
+			Name:        file.Name,
+			Description: file.Description,
+			MimeType:    file.MimeType,
+			Size:        file.Size,
+		})
+	}
+
+	document.Metadata = metadata
+	if strings.TrimSpace(strings.Join(texts, "")) != "" {
+		document.ContentText = strings.Join(texts, entities.PageTextSeparator)
+	}
+}
+
 // GetDocuments retrieves documents for a user with pagination
 func (s *DocumentService) GetDocuments(ctx context.Context, req *GetDocumentsRequest) (*GetDocumentsResponse, error) {
 	filter := repositories.DocumentFilter{
@@ -207,6 +713,109 @@ func (s *DocumentService) GetDocuments(ctx context.Context, req *GetDocumentsReq
 	}, nil
 }
 
+// SearchDocuments runs a full-text search with filters and facet counts, paging
+// with a cursor rather than an offset so deep pages stay fast
+func (s *DocumentService) SearchDocuments(ctx context.Context, req *SearchDocumentsRequest) (*SearchDocumentsResponse, error) {
+	search := repositories.DocumentSearch{
+		UserID:      req.UserID,
+		Query:       strings.TrimSpace(req.Query),
+		Issuer:      req.Issuer,
+		Status:      req.Status,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Sort:        req.Sort,
+		Ascending:   req.Ascending,
+		Limit:       req.Limit,
+	}
+	if req.All {
+		readAny, err := hasPermission(ctx, s.permissions, req.UserID, entities.PermissionDocumentReadAny)
+		if err != nil {
+			return nil, err
+		}
+		if !readAny {
+			return nil, fmt.Errorf("%w: searching all documents requires %s", ErrPermissionDenied, entities.PermissionDocumentReadAny)
+		}
+		search.UserID = ""
+	}
+
+	switch search.Sort {
+	case "":
+		search.Sort = repositories.DocumentSortCreatedAt
+		if search.Query != "" {
+			search.Sort = repositories.DocumentSortRelevance
+		}
+	case repositories.DocumentSortCreatedAt, repositories.DocumentSortTitle:
+	case repositories.DocumentSortRelevance:
+		if search.Query == "" {
+			return nil, fmt.Errorf("%w: sorting by relevance needs a query", ErrInvalidSearch)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidSearch, search.Sort)
+	}
+	if search.CreatedFrom != nil && search.CreatedTo != nil && !search.CreatedTo.After(*search.CreatedFrom) {
+		return nil, fmt.Errorf("%w: the end date must be after the start date", ErrInvalidSearch)
+	}
+	if search.Limit < 1 {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Limit > maxSearchLimit {
+		search.Limit = maxSearchLimit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeSearchCursor(req.Cursor)
+		if err != nil || cursor.Sort != search.Sort || cursor.Ascending != search.Ascending {
+			return nil, fmt.Errorf("%w: the cursor does not belong to this search", ErrInvalidSearch)
+		}
+		search.After = &cursor.DocumentCursor
+	}
+
+	result, err := s.documentRepo.Search(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	response := &SearchDocumentsResponse{
+		Documents: result.Documents,
+		Total:     result.Total,
+		Facets:    result.Facets,
+	}
+	if result.Next != nil {
+		response.NextCursor = encodeSearchCursor(searchCursor{Sort: search.Sort, Ascending: search.Ascending, DocumentCursor: *result.Next})
+	}
+	return response, nil
+}
+
+func encodeSearchCursor(cursor searchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor reads a cursor and checks its key fits the sort it was made for
+func decodeSearchCursor(encoded string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == "" {
+		return nil, fmt.Errorf("cursor has no document ID")
+	}
+	switch cursor.Sort {
+	case repositories.DocumentSortCreatedAt:
+		_, err = time.Parse(time.RFC3339Nano, cursor.Key)
+	case repositories.DocumentSortRelevance:
+		_, err = strconv.ParseFloat(cursor.Key, 64)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
 // GetDocumentByID retrieves a specific document by ID
 func (s *DocumentService) GetDocumentByID(ctx context.Context, userID, documentID string) (*entities.Document, error) {
 	document, err := s.documentRepo.GetByID(ctx, documentID)
@@ -218,14 +827,31 @@ func (s *DocumentService) GetDocumentByID(ctx context.Context, userID, documentI
 		return nil, fmt.Errorf("document not found")
 	}
 
-	// Verify user owns the document
-	if document.UserID != userID {
+	// Verify user owns or signed the document, or may read any document
+	allowed, err := s.canAccess(ctx, document, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
 		return nil, fmt.Errorf("access denied: document belongs to different user")
 	}
 
 	return document, nil
 }
 
+// canAccess reports whether the user owns or signed the document, or may read any document
+func (s *DocumentService) canAccess(ctx context.Context, document *entities.Document, userID string) (bool, error) {
+	if document.UserID == userID || signedBy(document, userID) {
+		return true, nil
+	}
+	return hasPermission(ctx, s.permissions, userID, entities.PermissionDocumentReadAny)
+}
+
+// signedBy reports whether the user signed the document on its owner's behalf
+func signedBy(document *entities.Document, userID string) bool {
+	return document.SignedByID != nil && *document.SignedByID == userID
+}
+
 // DeleteDocument deletes a document
 func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID string) error {
 	// First verify the document exists and belongs to the user
@@ -233,6 +859,10 @@ func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID
 	if err != nil {
 		return err
 	}
+	// Reading any document does not extend to revoking it
+	if document.UserID != userID {
+		return fmt.Errorf("access denied: document belongs to different user")
+	}
 
 	// Update status to deleted instead of hard delete for audit purposes
 	document.Status = "deleted"
@@ -242,6 +872,11 @@ func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
+	// A deleted document no longer verifies, so subscribers see it as revoked
+	if s.events != nil {
+		s.events.Publish(ctx, entities.WebhookEventDocumentRevoked, document.UserID, document.OrganizationID, documentEventData(document))
+	}
+
 	return nil
 }
 
@@ -286,6 +921,58 @@ func (s *DocumentService) ReadPDFFromStream(reader io.Reader) ([]byte, error) {
 	return s.pdfService.ReadPDFFromReader(reader)
 }
 
+// SpoolPDF streams an upload to disk, hashing and parsing it once; the caller must Close it
+func (s *DocumentService) SpoolPDF(reader io.Reader) (*pdf.SpooledPDF, error) {
+	spooled, err := s.pdfService.SpoolPDF(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDF: %w", err)
+	}
+	return spooled, nil
+}
+
+// writeSignedPDF streams the QR-stamped PDF to w, falling back to the original
+// bytes; it reports whether the PDF was stamped
+func (s *DocumentService) writeSignedPDF(src *pdf.SpooledPDF, qrCodeData pdf.QRCodeData, position *pdf.QRPosition, w io.Writer) bool {
+	if err := s.pdfService.InjectQRCodeFromSpool(src, qrCodeData, position, w); err != nil {
+		// In development, this will fail due to UniPDF license requirements
+		fmt.Printf("Warning: Failed to inject QR code into PDF: %v\n", err)
+		if _, err := src.WriteTo(w); err != nil {
+			fmt.Printf("Warning: Failed to write original PDF: %v\n", err)
+		}
+		return false
+	}
+	return true
+}
+
+// signedPDFPath is where the signed PDF of a document is kept
+func (s *DocumentService) signedPDFPath(documentID string) string {
+	return filepath.Join(s.config.StorageDir, "signed", filepath.Base(documentID)+".pdf")
+}
+
+// createSignedPDF opens a staging file for a signed PDF in storage
+func (s *DocumentService) createSignedPDF() (*os.File, error) {
+	dir := filepath.Join(s.config.StorageDir, "signed")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create signed PDF directory: %w", err)
+	}
+	file, err := os.CreateTemp(dir, "signing-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed PDF file: %w", err)
+	}
+	return file, nil
+}
+
+// keepSignedPDF moves a completely written staging file to the document's signed PDF
+func (s *DocumentService) keepSignedPDF(stored *os.File, documentID string) error {
+	if err := stored.Close(); err != nil {
+		return fmt.Errorf("failed to store signed PDF: %w", err)
+	}
+	if err := os.Rename(stored.Name(), s.signedPDFPath(documentID)); err != nil {
+		return fmt.Errorf("failed to store signed PDF: %w", err)
+	}
+	return nil
+}
+
 // GetQRCodeImage generates and returns QR code image for a document
 func (s *DocumentService) GetQRCodeImage(ctx context.Context, userID, documentID string) ([]byte, string, error) {
 	// Get document and verify ownership
@@ -294,11 +981,12 @@ func (s *DocumentService) GetQRCodeImage(ctx context.Context, userID, documentID
 		return nil, "", err
 	}
 
-	// Generate verification URL using config BaseURL
-	verifyURL := fmt.Sprintf("%s/verify/%s", s.config.BaseURL, document.ID)
+	// Generate verification URL on the organization's domain or config BaseURL
+	org := s.documentOrganization(ctx, document)
+	verifyURL := s.verifyURL(org, document.ID)
 
-	// Generate QR code with issuer label in center
-	qrCodeImage, err := s.pdfService.GenerateQRCodeWithCenterLabel(verifyURL, document.Issuer, 256)
+	// Generate QR code with the stamp label or issuer in center
+	qrCodeImage, err := s.pdfService.GenerateQRCodeWithCenterLabel(verifyURL, stampLabel(org, document.Issuer), 256)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate QR code image with center label: %w", err)
 	}
@@ -321,9 +1009,18 @@ func (s *DocumentService) GetSignedPDF(ctx context.Context, userID, documentID s
 		return nil, "", err
 	}
 
-	// For now, we'll return a placeholder since we don't store the actual PDF data
-	// In a production system, you would store the signed PDF file and retrieve it here
-	// This is a simplified implementation that generates a basic response
+	// Generate filename for signed PDF
+	filename := fmt.Sprintf("signed_%s", document.Filename)
+
+	signedPDF, err := os.ReadFile(s.signedPDFPath(document.ID))
+	if err == nil {
+		return signedPDF, filename, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("failed to read signed PDF: %w", err)
+	}
+
+	// Documents signed before signed PDFs were kept get a summary page instead
 
 	// Parse QR code data
 	var qrCodeData pdf.QRCodeData
@@ -400,8 +1097,5 @@ startxref
 		document.ID,
 		document.DocumentHash[:20]+"...")
 
-	// Generate filename for signed PDF
-	filename := fmt.Sprintf("signed_%s", document.Filename)
-
 	return []byte(pdfContent), filename, nil
 }
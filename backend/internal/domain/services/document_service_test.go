@@ -1,13 +1,19 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"digital-signature-system/internal/config"
 	"digital-signature-system/internal/domain/entities"
@@ -35,6 +41,18 @@ func (m *MockDocumentRepository) Create(ctx context.Context, doc *entities.Docum
 	return args.Error(0)
 }
 
+// CreateNumbered allocates the sequence value given as the first Return argument
+func (m *MockDocumentRepository) CreateNumbered(ctx context.Context, doc *entities.Document, schemeID string, year int, number func(seq int64) error) error {
+	args := m.Called(ctx, doc, schemeID, year)
+	if err := number(args.Get(0).(int64)); err != nil {
+		return err
+	}
+	if doc.ID == "" {
+		doc.ID = "test-doc-id"
+	}
+	return args.Error(1)
+}
+
 func (m *MockDocumentRepository) GetByID(ctx context.Context, id string) (*entities.Document, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*entities.Document), args.Error(1)
@@ -45,11 +63,35 @@ func (m *MockDocumentRepository) GetByUserID(ctx context.Context, userID string,
 	return args.Get(0).([]*entities.Document), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockDocumentRepository) GetByHash(ctx context.Context, hash string) (*entities.Document, error) {
-	args := m.Called(ctx, hash)
+func (m *MockDocumentRepository) GetByHash(ctx context.Context, hash, userID string) (*entities.Document, error) {
+	args := m.Called(ctx, hash, userID)
 	return args.Get(0).(*entities.Document), args.Error(1)
 }
 
+func (m *MockDocumentRepository) GetRecentWithText(ctx context.Context, userID string, limit int) ([]*entities.Document, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetVerifiableByHash(ctx context.Context, hash string, limit int) ([]*entities.Document, error) {
+	args := m.Called(ctx, hash, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) Search(ctx context.Context, search repositories.DocumentSearch) (*repositories.DocumentSearchResult, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repositories.DocumentSearchResult), args.Error(1)
+}
+
 func (m *MockDocumentRepository) Update(ctx context.Context, doc *entities.Document) error {
 	args := m.Called(ctx, doc)
 	return args.Error(0)
@@ -108,6 +150,32 @@ func (m *MockPDFService) ReadPDFFromReader(reader io.Reader) ([]byte, error) {
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *MockPDFService) SpoolPDF(reader io.Reader) (*pdf.SpooledPDF, error) {
+	args := m.Called(reader)
+	return args.Get(0).(*pdf.SpooledPDF), args.Error(1)
+}
+
+func (m *MockPDFService) InjectQRCodeFromSpool(src *pdf.SpooledPDF, qrCodeData pdf.QRCodeData, position *pdf.QRPosition, w io.Writer) error {
+	args := m.Called(src, qrCodeData, position, w)
+	return args.Error(0)
+}
+
+func (m *MockPDFService) ExtractContent(pdfData []byte) (*pdf.PDFContent, error) {
+	args := m.Called(pdfData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pdf.PDFContent), args.Error(1)
+}
+
+func (m *MockPDFService) ExtractContentFromSpool(src *pdf.SpooledPDF) (*pdf.PDFContent, error) {
+	args := m.Called(src)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pdf.PDFContent), args.Error(1)
+}
+
 func TestDocumentService_SignDocument(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -137,6 +205,10 @@ func TestDocumentService_SignDocument(t *testing.T) {
 					Algorithm: "RSA-PSS-SHA256",
 				}, nil)
 
+				// Content extraction and duplicate lookup
+				pdfService.On("ExtractContent", mock.AnythingOfType("[]uint8")).Return(&pdf.PDFContent{Version: "1.4"}, nil)
+				docRepo.On("GetByHash", mock.Anything, base64.StdEncoding.EncodeToString([]byte("test-hash")), mock.Anything).Return((*entities.Document)(nil), nil)
+
 				// QR code generation with center label
 				pdfService.On("GenerateQRCodeWithCenterLabel", mock.AnythingOfType("string"), mock.AnythingOfType("string"), 256).Return([]byte("qr-code-image"), nil)
 
@@ -177,6 +249,8 @@ func TestDocumentService_SignDocument(t *testing.T) {
 			setupMocks: func(docRepo *MockDocumentRepository, sigService *MockSignatureService, pdfService *MockPDFService) {
 				pdfService.On("ValidatePDF", mock.AnythingOfType("[]uint8")).Return(nil)
 				pdfService.On("CalculateHash", mock.AnythingOfType("[]uint8")).Return([]byte("test-hash"), nil)
+				pdfService.On("ExtractContent", mock.AnythingOfType("[]uint8")).Return(nil, assert.AnError)
+				docRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
 				sigService.On("SignDocument", []byte("test-hash")).Return((*crypto.SignatureData)(nil), assert.AnError)
 			},
 			expectedError: "failed to sign document",
@@ -199,7 +273,8 @@ func TestDocumentService_SignDocument(t *testing.T) {
 				signatureService: mockSigService,
 				pdfService:       mockPDFService,
 				config: &config.Config{
-					BaseURL: "http://localhost:3000",
+					BaseURL:    "http://localhost:3000",
+					StorageDir: t.TempDir(),
 				},
 			}
 
@@ -219,6 +294,10 @@ func TestDocumentService_SignDocument(t *testing.T) {
 				assert.Equal(t, tt.request.Issuer, response.Document.Issuer)
 				assert.Equal(t, tt.request.UserID, response.Document.UserID)
 				assert.Equal(t, "active", response.Document.Status)
+
+				// The stamped PDF can be verified by file too
+				stamped := sha256.Sum256(response.SignedPDFData)
+				assert.Equal(t, base64.StdEncoding.EncodeToString(stamped[:]), response.Document.StampedHash)
 			}
 
 			// Verify mocks
@@ -229,6 +308,215 @@ func TestDocumentService_SignDocument(t *testing.T) {
 	}
 }
 
+func TestDocumentService_SignDocument_SpooledSource(t *testing.T) {
+	source, err := pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()).SpoolPDF(strings.NewReader(testSpoolPDF))
+	require.NoError(t, err)
+	defer source.Close()
+
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	// The spooled hash is signed directly; ValidatePDF and CalculateHash must not run again
+	mockSigService.On("SignDocument", source.Hash()).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      source.Hash(),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockPDFService.On("ExtractContentFromSpool", source).Return(&pdf.PDFContent{Version: "1.4"}, nil)
+	mockDocRepo.On("GetByHash", mock.Anything, base64.StdEncoding.EncodeToString(source.Hash()), mock.Anything).Return((*entities.Document)(nil), nil)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.AnythingOfType("string"), "John Doe", 256).Return([]byte("qr-code-image"), nil)
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	var output bytes.Buffer
+	// The output is written through the hash of the stamped PDF
+	mockPDFService.On("InjectQRCodeFromSpool", source, mock.AnythingOfType("pdf.QRCodeData"), (*pdf.QRPosition)(nil), mock.Anything).Return(assert.AnError)
+
+	service := &DocumentService{
+		documentRepo:     mockDocRepo,
+		signatureService: mockSigService,
+		pdfService:       mockPDFService,
+		config: &config.Config{
+			BaseURL:    "http://localhost:3000",
+			StorageDir: t.TempDir(),
+		},
+	}
+
+	response, err := service.SignDocument(context.Background(), &SignDocumentRequest{
+		Filename:     "test.pdf",
+		Issuer:       "John Doe",
+		Title:        "Spooled",
+		LetterNumber: "LN-010",
+		Source:       source,
+		Output:       &output,
+		UserID:       "user-123",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString(source.Hash()), response.Document.DocumentHash)
+	assert.Equal(t, source.Size(), response.Document.FileSize)
+	assert.Nil(t, response.SignedPDFData)
+
+	// A failed injection falls back to streaming the original document
+	assert.Equal(t, testSpoolPDF, output.String())
+	assert.Empty(t, response.Document.StampedHash, "an unstamped PDF has no stamped hash")
+
+	mockDocRepo.AssertExpectations(t)
+	mockSigService.AssertExpectations(t)
+	mockPDFService.AssertExpectations(t)
+	mockPDFService.AssertNotCalled(t, "ValidatePDF", mock.Anything)
+	mockPDFService.AssertNotCalled(t, "CalculateHash", mock.Anything)
+}
+
+func TestDocumentService_SignDocument_StoresContent(t *testing.T) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(&pdf.PDFContent{
+		Version: "1.7",
+		Info:    pdf.PDFInfo{NumPages: 2, Title: "Quarterly Report", Author: "Finance", CreationDate: &created},
+		XMP:     "<x:xmpmeta/>",
+		Pages: []pdf.PageContent{
+			{Number: 1, Width: 595, Height: 842, Text: "Quarterly report\nRevenue grew"},
+			{Number: 2, Width: 842, Height: 595, Rotation: 90, Text: "Appendix"},
+		},
+		EmbeddedFiles: []pdf.EmbeddedFile{{Name: "figures.csv", MimeType: "text/csv", Size: 120}},
+	}, nil)
+	mockSigService.On("SignDocument", []byte("test-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("test-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("modified-pdf"), nil)
+	mockDocRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSigService, mockPDFService, &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()})
+	response, err := service.SignDocument(context.Background(), &SignDocumentRequest{
+		Filename: "report.pdf",
+		Issuer:   "Finance Office",
+		PDFData:  []byte("%PDF-1.7 test content"),
+		UserID:   "user-123",
+	})
+	require.NoError(t, err)
+
+	document := response.Document
+	assert.Equal(t, "Quarterly report\nRevenue grew\fAppendix", document.ContentText)
+	assert.Equal(t, []string{"Quarterly report\nRevenue grew", "Appendix"}, document.PageTexts())
+	require.NotNil(t, document.Metadata)
+	assert.Equal(t, "1.7", document.Metadata.PDFVersion)
+	assert.Equal(t, 2, document.Metadata.PageCount)
+	assert.Equal(t, "Quarterly Report", document.Metadata.Title)
+	assert.Equal(t, &created, document.Metadata.CreationDate)
+	assert.Equal(t, "<x:xmpmeta/>", document.Metadata.XMP)
+	assert.Equal(t, []entities.PageMetadata{
+		{Number: 1, Width: 595, Height: 842},
+		{Number: 2, Width: 842, Height: 595, Rotation: 90},
+	}, document.Metadata.Pages)
+	assert.Equal(t, []entities.EmbeddedFileMetadata{{Name: "figures.csv", MimeType: "text/csv", Size: 120}}, document.Metadata.EmbeddedFiles)
+}
+
+func TestDocumentService_SignDocument_AccessCode(t *testing.T) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(nil, assert.AnError)
+	mockSigService.On("SignDocument", []byte("test-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("test-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	var position *pdf.QRPosition
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		position = args.Get(2).(*pdf.QRPosition)
+	}).Return([]byte("modified-pdf"), nil)
+	mockDocRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSigService, mockPDFService, &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()})
+	request := &SignDocumentRequest{
+		Filename:   "transcript.pdf",
+		Issuer:     "Registrar",
+		PDFData:    []byte("%PDF-1.4 test content"),
+		UserID:     "user-123",
+		Visibility: "secret",
+	}
+	_, err := service.SignDocument(context.Background(), request)
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+
+	request.Visibility = entities.DocumentVisibilityAccessCode
+	response, err := service.SignDocument(context.Background(), request)
+	require.NoError(t, err)
+
+	// The code is returned once, stored hashed and printed under the QR code
+	require.NotEmpty(t, response.AccessCode)
+	assert.Equal(t, entities.DocumentVisibilityAccessCode, response.Document.Visibility)
+	assert.True(t, accessCodeMatches(response.Document.AccessCodeHash, response.AccessCode))
+	require.NotNil(t, position)
+	assert.Equal(t, "Access code: "+response.AccessCode, position.Caption)
+	assert.Equal(t, pdf.DefaultQRPosition().X, position.X)
+
+	// A queued request brings the code it was given when it was queued
+	request.AccessCode = "K7QM-3XPA"
+	response, err = service.SignDocument(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "K7QM-3XPA", response.AccessCode)
+	assert.True(t, accessCodeMatches(response.Document.AccessCodeHash, "k7qm 3xpa"))
+	assert.Equal(t, "Access code: K7QM-3XPA", position.Caption)
+}
+
+// testSpoolPDF is a minimal single-page PDF that parses cleanly
+const testSpoolPDF = `%PDF-1.4
+1 0 obj
+<<
+/Type /Catalog
+/Pages 2 0 R
+>>
+endobj
+
+2 0 obj
+<<
+/Type /Pages
+/Kids [3 0 R]
+/Count 1
+>>
+endobj
+
+3 0 obj
+<<
+/Type /Page
+/Parent 2 0 R
+/MediaBox [0 0 612 792]
+>>
+endobj
+
+xref
+0 4
+0000000000 65535 f 
+0000000010 00000 n 
+0000000053 00000 n 
+0000000100 00000 n 
+trailer
+<<
+/Size 4
+/Root 1 0 R
+>>
+startxref
+157
+%%EOF`
+
 func TestDocumentService_GetDocuments(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -473,6 +761,253 @@ func TestDocumentService_DeleteDocument(t *testing.T) {
 	}
 }
 
+// stubPermissionChecker grants the listed permissions to every user
+type stubPermissionChecker map[string]bool
+
+func (p stubPermissionChecker) UserHasPermission(ctx context.Context, userID, permission string) (bool, error) {
+	return p[permission], nil
+}
+
+// failingPermissionChecker cannot look up any role
+type failingPermissionChecker struct{}
+
+func (failingPermissionChecker) UserHasPermission(ctx context.Context, userID, permission string) (bool, error) {
+	return false, assert.AnError
+}
+
+func TestDocumentService_ReadAnyDocument(t *testing.T) {
+	document := &entities.Document{ID: "doc-123", UserID: "user-456", Filename: "test.pdf", Status: "active"}
+	mockDocRepo := new(MockDocumentRepository)
+	mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+
+	service := &DocumentService{documentRepo: mockDocRepo}
+	service.SetPermissionChecker(stubPermissionChecker{entities.PermissionDocumentReadAny: true})
+
+	found, err := service.GetDocumentByID(context.Background(), "auditor-1", "doc-123")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-456", found.UserID)
+
+	// Reading another user's document does not allow revoking it
+	err = service.DeleteDocument(context.Background(), "auditor-1", "doc-123")
+	assert.EqualError(t, err, "access denied: document belongs to different user")
+	mockDocRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	// A failed permission check is reported rather than taken as a denial
+	service.SetPermissionChecker(failingPermissionChecker{})
+	_, err = service.GetDocumentByID(context.Background(), "auditor-1", "doc-123")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, ErrPermissionDenied)
+
+	// Owners do not need a permission check
+	found, err = service.GetDocumentByID(context.Background(), "user-456", "doc-123")
+	assert.NoError(t, err)
+	assert.Equal(t, "doc-123", found.ID)
+}
+
+// stubOrganizationResolver serves one organization and its signing key
+type stubOrganizationResolver struct {
+	org    *entities.Organization
+	signer SignatureServiceInterface
+	keyID  string
+}
+
+func (r *stubOrganizationResolver) GetOrganization(ctx context.Context, id string) (*entities.Organization, error) {
+	if id != r.org.ID {
+		return nil, ErrOrganizationNotFound
+	}
+	return r.org, nil
+}
+
+func (r *stubOrganizationResolver) SignerFor(ctx context.Context, organizationID string) (SignatureServiceInterface, string, error) {
+	return r.signer, r.keyID, nil
+}
+
+func (r *stubOrganizationResolver) VerifierFor(ctx context.Context, organizationID, keyID string) (SignatureServiceInterface, error) {
+	if keyID != r.keyID {
+		return nil, ErrOrganizationKeyNotFound
+	}
+	return r.signer, nil
+}
+
+func TestDocumentService_SignDocument_Organization(t *testing.T) {
+	org := &entities.Organization{
+		ID:                  "org-1",
+		IssuerName:          "Faculty of Engineering",
+		VerificationBaseURL: "https://verify.eng.example.edu",
+		StampLabel:          "ENG",
+		StampX:              40,
+	}
+	orgSigner := new(MockSignatureService)
+	defaultSigner := new(MockSignatureService)
+	mockDocRepo := new(MockDocumentRepository)
+	mockPDFService := new(MockPDFService)
+
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	orgSigner.On("SignDocument", []byte("test-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("org-signature"),
+		Hash:      []byte("test-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(nil, assert.AnError)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.MatchedBy(func(url string) bool {
+		return strings.HasPrefix(url, "https://verify.eng.example.edu/verify/")
+	}), "ENG", 256).Return([]byte("qr-code-image"), nil)
+
+	// Writes are scoped to the organization
+	inOrganization := mock.MatchedBy(func(ctx context.Context) bool {
+		organizationID, all := repositories.OrganizationFromContext(ctx)
+		return organizationID == "org-1" && !all
+	})
+	mockDocRepo.On("GetByHash", inOrganization, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+	mockDocRepo.On("Create", inOrganization, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", inOrganization, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.MatchedBy(func(position *pdf.QRPosition) bool {
+		return position != nil && position.X == 40
+	})).Return([]byte("modified-pdf"), nil)
+
+	service := &DocumentService{
+		documentRepo:     mockDocRepo,
+		signatureService: defaultSigner,
+		pdfService:       mockPDFService,
+		config:           &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()},
+	}
+	service.SetOrganizations(&stubOrganizationResolver{org: org, signer: orgSigner, keyID: "org-key-1"})
+
+	response, err := service.SignDocument(context.Background(), &SignDocumentRequest{
+		Filename:       "transcript.pdf",
+		Issuer:         "Someone Else",
+		PDFData:        []byte("%PDF-1.4 test content"),
+		UserID:         "user-123",
+		OrganizationID: "org-1",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, response.Document.OrganizationID)
+	assert.Equal(t, "org-1", *response.Document.OrganizationID)
+	assert.Equal(t, "org-key-1", response.Document.KeyID)
+	assert.Equal(t, "Faculty of Engineering", response.Document.Issuer)
+
+	defaultSigner.AssertNotCalled(t, "SignDocument", mock.Anything)
+	orgSigner.AssertExpectations(t)
+	mockDocRepo.AssertExpectations(t)
+	mockPDFService.AssertExpectations(t)
+}
+
+// stubDelegationAuthorizer holds the grants from delegator to delegate
+type stubDelegationAuthorizer map[string]*entities.Delegation
+
+func (a stubDelegationAuthorizer) AuthorizeSigning(ctx context.Context, delegatorID, delegateID, organizationID string) (*entities.Delegation, error) {
+	if delegation, ok := a[delegatorID+"/"+delegateID]; ok {
+		return delegation, nil
+	}
+	return nil, ErrNoActiveDelegation
+}
+
+func TestDocumentService_SignDocument_OnBehalfOf(t *testing.T) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSignatureService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	mockSignatureService.On("SignDocument", []byte("test-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("test-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(nil, assert.AnError)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("modified-pdf"), nil)
+	mockDocRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+	mockDocRepo.On("Create", mock.Anything, mock.MatchedBy(func(doc *entities.Document) bool {
+		return doc.UserID == "head" && doc.SignedByID != nil && *doc.SignedByID == "secretary" &&
+			doc.DelegationID != nil && *doc.DelegationID == "delegation-1"
+	})).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSignatureService, mockPDFService, &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()})
+	req := &SignDocumentRequest{
+		Filename:     "memo.pdf",
+		Issuer:       "Department of Physics",
+		Title:        "Memo",
+		LetterNumber: "LN-1",
+		PDFData:      []byte("%PDF-1.4 test content"),
+		UserID:       "secretary",
+		OnBehalfOf:   "head",
+	}
+
+	_, err := service.SignDocument(context.Background(), req)
+	assert.ErrorIs(t, err, ErrDelegationsDisabled)
+
+	service.SetDelegations(stubDelegationAuthorizer{
+		"head/secretary": {ID: "delegation-1", DelegatorID: "head", DelegateID: "secretary"},
+	})
+
+	// Without a grant from this user nothing is signed
+	_, err = service.SignDocument(context.Background(), &SignDocumentRequest{PDFData: req.PDFData, UserID: "secretary", OnBehalfOf: "dean"})
+	assert.ErrorIs(t, err, ErrNoActiveDelegation)
+	mockSignatureService.AssertNotCalled(t, "SignDocument", mock.Anything)
+
+	response, err := service.SignDocument(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "head", response.Document.UserID)
+
+	// The delegate can read the document but not revoke it
+	mockDocRepo.On("GetByID", mock.Anything, response.Document.ID).Return(response.Document, nil)
+	_, err = service.GetDocumentByID(context.Background(), "secretary", response.Document.ID)
+	assert.NoError(t, err)
+	err = service.DeleteDocument(context.Background(), "secretary", response.Document.ID)
+	assert.EqualError(t, err, "access denied: document belongs to different user")
+	mockDocRepo.AssertExpectations(t)
+}
+
+func TestDocumentService_SearchDocuments(t *testing.T) {
+	ctx := context.Background()
+	mockDocRepo := new(MockDocumentRepository)
+	service := &DocumentService{documentRepo: mockDocRepo}
+
+	// A query ranks by relevance, and the next page cursor carries the sort
+	mockDocRepo.On("Search", ctx, repositories.DocumentSearch{UserID: "user-123", Query: "decree", Sort: repositories.DocumentSortRelevance, Limit: 20}).
+		Return(&repositories.DocumentSearchResult{
+			Documents: []*entities.Document{{ID: "doc-1"}},
+			Total:     3,
+			Next:      &repositories.DocumentCursor{Key: "0.6", ID: "doc-1"},
+		}, nil).Once()
+	response, err := service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Query: " decree "})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), response.Total)
+	require.NotEmpty(t, response.NextCursor)
+
+	mockDocRepo.On("Search", ctx, repositories.DocumentSearch{
+		UserID: "user-123", Query: "decree", Sort: repositories.DocumentSortRelevance, Limit: 20,
+		After: &repositories.DocumentCursor{Key: "0.6", ID: "doc-1"},
+	}).Return(&repositories.DocumentSearchResult{}, nil).Once()
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Query: "decree", Cursor: response.NextCursor})
+	require.NoError(t, err)
+
+	// The cursor cannot be reused with another sort
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Query: "decree", Sort: "title", Cursor: response.NextCursor})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Sort: repositories.DocumentSortRelevance})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Sort: "size"})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidSearch)
+
+	// Searching everyone's documents needs document:read:any
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", All: true})
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	service.SetPermissionChecker(stubPermissionChecker{entities.PermissionDocumentReadAny: true})
+	mockDocRepo.On("Search", ctx, repositories.DocumentSearch{Sort: repositories.DocumentSortCreatedAt, Limit: 100}).
+		Return(&repositories.DocumentSearchResult{}, nil).Once()
+	_, err = service.SearchDocuments(ctx, &SearchDocumentsRequest{UserID: "user-123", All: true, Limit: 500})
+	require.NoError(t, err)
+	mockDocRepo.AssertExpectations(t)
+}
+
 func TestDocumentService_EncodeDecodeSignatureData(t *testing.T) {
 	service := &DocumentService{}
 
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/pdf"
+)
+
+var (
+	ErrDuplicateDocument      = errors.New("this PDF has already been signed")
+	ErrInvalidDuplicatePolicy = errors.New("on_duplicate must be reject, return_existing or new_version")
+)
+
+// What signing an already signed PDF does
+const (
+	DuplicateReject         = "reject"
+	DuplicateReturnExisting = "return_existing"
+	DuplicateNewVersion     = "new_version"
+)
+
+const (
+	// shingleSize is the number of consecutive words compared as one unit
+	shingleSize = 3
+	// maxShingleWords caps the words of a document that are compared
+	maxShingleWords = 5000
+	// maxSimilarDocuments caps the near duplicates reported for a document
+	maxSimilarDocuments = 5
+
+	defaultDuplicateScanLimit = 500
+)
+
+// SimilarDocument is a signed document whose text nearly matches a new one
+type SimilarDocument struct {
+	DocumentID   string    `json:"document_id"`
+	Title        string    `json:"title,omitempty"`
+	LetterNumber string    `json:"letter_number,omitempty"`
+	Issuer       string    `json:"issuer"`
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Similarity is the share of text the documents have in common, from 0 to 1
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateReport lists the signed documents a PDF duplicates
+type DuplicateReport struct {
+	// Existing is the latest document signed from the same file, if any
+	Existing *entities.Document `json:"existing,omitempty"`
+	Similar  []SimilarDocument  `json:"similar_documents"`
+}
+
+// CheckDuplicates reports whether the request's PDF was signed before and which
+// documents have nearly the same text, without signing anything. Clerks use it
+// before reissuing a letter.
+func (s *DocumentService) CheckDuplicates(ctx context.Context, req *SignDocumentRequest) (*DuplicateReport, error) {
+	delegation, err := s.authorizeDelegation(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	documentHash, _, err := s.hashRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _, org, err := s.signerFor(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.findDuplicates(ctx, ownerID(req, delegation), org, base64.StdEncoding.EncodeToString(documentHash), s.extractContent(req))
+	if err != nil {
+		return nil, err
+	}
+	// Only documents the caller may open are shown in full
+	if report.Existing != nil {
+		allowed, err := s.canAccess(ctx, report.Existing, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			report.Existing = &entities.Document{ID: report.Existing.ID, CreatedAt: report.Existing.CreatedAt, Version: report.Existing.Version}
+		}
+	}
+	return report, nil
+}
+
+// duplicatePolicy validates the requested policy, defaulting to the configured one
+func (s *DocumentService) duplicatePolicy(requested string) (string, error) {
+	policy := requested
+	if policy == "" {
+		policy = s.config.DuplicatePolicy
+	}
+	switch policy {
+	case "":
+		return DuplicateReject, nil
+	case DuplicateReject, DuplicateReturnExisting, DuplicateNewVersion:
+		return policy, nil
+	default:
+		return "", ErrInvalidDuplicatePolicy
+	}
+}
+
+// findDuplicates looks for documents signed from the same file and documents
+// with nearly the same text. Within an organization every member's documents
+// are compared; otherwise only the owner's own. ctx must be scoped to org.
+func (s *DocumentService) findDuplicates(ctx context.Context, ownerID string, org *entities.Organization, hash string, content *pdf.PDFContent) (*DuplicateReport, error) {
+	report := &DuplicateReport{}
+
+	scope := ownerID
+	if org != nil {
+		scope = ""
+	}
+
+	existing, err := s.documentRepo.GetByHash(ctx, hash, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up document hash: %w", err)
+	}
+	report.Existing = existing
+
+	if content == nil || s.config.DuplicateSimilarity <= 0 {
+		return report, nil
+	}
+	shingles := textShingles(joinedText(content))
+	if len(shingles) == 0 {
+		return report, nil
+	}
+
+	limit := s.config.DuplicateScanLimit
+	if limit <= 0 {
+		limit = defaultDuplicateScanLimit
+	}
+	candidates, err := s.documentRepo.GetRecentWithText(ctx, scope, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := float64(s.config.DuplicateSimilarity) / 100
+	for _, candidate := range candidates {
+		if candidate.DocumentHash == hash || (report.Existing != nil && candidate.ID == report.Existing.ID) {
+			continue
+		}
+		similarity := jaccard(shingles, textShingles(candidate.ContentText))
+		if similarity < threshold {
+			continue
+		}
+		similar := SimilarDocument{
+			DocumentID: candidate.ID,
+			Issuer:     candidate.Issuer,
+			Version:    candidate.Version,
+			CreatedAt:  candidate.CreatedAt,
+			Similarity: similarity,
+		}
+		if candidate.Title != nil {
+			similar.Title = *candidate.Title
+		}
+		if candidate.LetterNumber != nil {
+			similar.LetterNumber = *candidate.LetterNumber
+		}
+		report.Similar = append(report.Similar, similar)
+	}
+
+	sort.SliceStable(report.Similar, func(i, j int) bool {
+		return report.Similar[i].Similarity > report.Similar[j].Similarity
+	})
+	if len(report.Similar) > maxSimilarDocuments {
+		report.Similar = report.Similar[:maxSimilarDocuments]
+	}
+	return report, nil
+}
+
+// ownerID is the user who will own a document the request signs
+func ownerID(req *SignDocumentRequest, delegation *entities.Delegation) string {
+	if delegation != nil {
+		return delegation.DelegatorID
+	}
+	return req.UserID
+}
+
+func duplicateError(existing *entities.Document) error {
+	return fmt.Errorf("%w as document %s on %s", ErrDuplicateDocument, existing.ID, existing.CreatedAt.Format("2006-01-02"))
+}
+
+// joinedText joins the text of every page
+func joinedText(content *pdf.PDFContent) string {
+	texts := make([]string, len(content.Pages))
+	for i, page := range content.Pages {
+		texts[i] = page.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// textShingles returns the set of runs of shingleSize consecutive words in the
+// text, ignoring case and punctuation. Shorter texts yield their words.
+func textShingles(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxShingleWords {
+		words = words[:maxShingleWords]
+	}
+
+	shingles := make(map[string]struct{})
+	if len(words) < shingleSize {
+		for _, word := range words {
+			shingles[word] = struct{}{}
+		}
+		return shingles
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+shingleSize], " ")] = struct{}{}
+	}
+	return shingles
+}
+
+// jaccard is the share of the two sets' union that they have in common
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
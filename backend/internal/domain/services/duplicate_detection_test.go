@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/crypto"
+	"digital-signature-system/internal/infrastructure/pdf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newDuplicateTestService mocks signing a PDF whose hash was already signed as existing
+func newDuplicateTestService(t *testing.T, existing *entities.Document) (*DocumentService, *MockDocumentRepository, *MockSignatureService) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(nil, assert.AnError)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("modified-pdf"), nil)
+	mockSigService.On("SignDocument", []byte("test-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("test-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockDocRepo.On("GetByHash", mock.Anything, base64.StdEncoding.EncodeToString([]byte("test-hash")), mock.Anything).Return(existing, nil)
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSigService, mockPDFService, &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()})
+	return service, mockDocRepo, mockSigService
+}
+
+func TestDocumentService_SignDocument_Duplicates(t *testing.T) {
+	existing := &entities.Document{
+		ID:           "doc-1",
+		UserID:       "user-123",
+		DocumentHash: base64.StdEncoding.EncodeToString([]byte("test-hash")),
+		Version:      1,
+		Status:       "active",
+		CreatedAt:    time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC),
+	}
+	request := func(policy string) *SignDocumentRequest {
+		return &SignDocumentRequest{
+			Filename:    "letter.pdf",
+			Issuer:      "Registrar",
+			PDFData:     []byte("%PDF-1.4 test content"),
+			UserID:      "user-123",
+			OnDuplicate: policy,
+		}
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		service, mockDocRepo, mockSigService := newDuplicateTestService(t, existing)
+
+		_, err := service.SignDocument(context.Background(), request(""))
+		assert.ErrorIs(t, err, ErrDuplicateDocument)
+		assert.Contains(t, err.Error(), "doc-1 on 2026-05-04")
+		mockSigService.AssertNotCalled(t, "SignDocument", mock.Anything)
+		mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("configured policy applies", func(t *testing.T) {
+		service, _, _ := newDuplicateTestService(t, existing)
+		service.config.DuplicatePolicy = DuplicateReturnExisting
+
+		response, err := service.SignDocument(context.Background(), request(""))
+		require.NoError(t, err)
+		assert.True(t, response.Existing)
+	})
+
+	t.Run("return existing", func(t *testing.T) {
+		service, mockDocRepo, mockSigService := newDuplicateTestService(t, existing)
+
+		response, err := service.SignDocument(context.Background(), request(DuplicateReturnExisting))
+		require.NoError(t, err)
+		assert.True(t, response.Existing)
+		assert.Same(t, existing, response.Document)
+		assert.Nil(t, response.SignedPDFData)
+		mockSigService.AssertNotCalled(t, "SignDocument", mock.Anything)
+		mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("new version", func(t *testing.T) {
+		service, _, _ := newDuplicateTestService(t, existing)
+
+		response, err := service.SignDocument(context.Background(), request(DuplicateNewVersion))
+		require.NoError(t, err)
+		assert.False(t, response.Existing)
+		assert.Equal(t, 2, response.Document.Version)
+		require.NotNil(t, response.Document.PreviousVersionID)
+		assert.Equal(t, "doc-1", *response.Document.PreviousVersionID)
+	})
+
+	t.Run("another user's document is not a duplicate", func(t *testing.T) {
+		// The lookup is limited to the owner's documents, so another user's
+		// copy is not found
+		service, mockDocRepo, _ := newDuplicateTestService(t, nil)
+
+		response, err := service.SignDocument(context.Background(), request(""))
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Document.Version)
+		assert.Nil(t, response.Document.PreviousVersionID)
+		mockDocRepo.AssertCalled(t, "GetByHash", mock.Anything, mock.Anything, "user-123")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		service, _, _ := newDuplicateTestService(t, nil)
+
+		_, err := service.SignDocument(context.Background(), request("overwrite"))
+		assert.ErrorIs(t, err, ErrInvalidDuplicatePolicy)
+	})
+}
+
+func TestDocumentService_SignDocument_SimilarDocuments(t *testing.T) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	letter := "Letter of appointment for Jane Smith as lecturer in the department of physics starting in September"
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("new-hash"), nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(&pdf.PDFContent{
+		Pages: []pdf.PageContent{{Number: 1, Text: letter}},
+	}, nil)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("modified-pdf"), nil)
+	mockSigService.On("SignDocument", []byte("new-hash")).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("new-hash"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockDocRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+
+	letterNumber := "001/PHY/V/2026"
+	mockDocRepo.On("GetRecentWithText", mock.Anything, "user-123", 50).Return([]*entities.Document{
+		{ID: "unrelated", Issuer: "Registrar", ContentText: "Minutes of the faculty board meeting held in March"},
+		{ID: "reissued", Issuer: "Registrar", LetterNumber: &letterNumber, Version: 1,
+			ContentText: "Letter of appointment for Jane Smith as lecturer in the department of physics starting in October"},
+	}, nil)
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSigService, mockPDFService, &config.Config{
+		BaseURL:             "http://localhost:3000",
+		StorageDir:          t.TempDir(),
+		DuplicateSimilarity: 80,
+		DuplicateScanLimit:  50,
+	})
+	response, err := service.SignDocument(context.Background(), &SignDocumentRequest{
+		Filename: "appointment.pdf",
+		Issuer:   "Registrar",
+		PDFData:  []byte("%PDF-1.4 test content"),
+		UserID:   "user-123",
+	})
+	require.NoError(t, err)
+
+	// Near duplicates are reported but do not stop the signing
+	require.Len(t, response.SimilarDocuments, 1)
+	assert.Equal(t, "reissued", response.SimilarDocuments[0].DocumentID)
+	assert.Equal(t, letterNumber, response.SimilarDocuments[0].LetterNumber)
+	assert.InDelta(t, 0.87, response.SimilarDocuments[0].Similarity, 0.01)
+	mockDocRepo.AssertExpectations(t)
+}
+
+func TestDocumentService_CheckDuplicates(t *testing.T) {
+	existing := &entities.Document{ID: "doc-1", UserID: "user-123", Filename: "letter.pdf", Version: 3}
+	service, mockDocRepo, mockSigService := newDuplicateTestService(t, existing)
+	service.SetPermissionChecker(stubPermissionChecker{})
+	req := &SignDocumentRequest{PDFData: []byte("%PDF-1.4 test content"), UserID: "user-123"}
+
+	report, err := service.CheckDuplicates(context.Background(), req)
+	require.NoError(t, err)
+	assert.Same(t, existing, report.Existing)
+	mockSigService.AssertNotCalled(t, "SignDocument", mock.Anything)
+	mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTextShingles(t *testing.T) {
+	assert.Equal(t, map[string]struct{}{
+		"the quick brown": {},
+		"quick brown fox": {},
+	}, textShingles("The quick, brown\nFOX!"))
+	assert.Equal(t, map[string]struct{}{"short": {}, "text": {}}, textShingles("Short text"))
+	assert.Empty(t, textShingles(" \f "))
+}
+
+func TestJaccard(t *testing.T) {
+	a := textShingles("one two three four")
+	assert.Equal(t, 1.0, jaccard(a, a))
+	assert.InDelta(t, 1.0/3, jaccard(a, textShingles("one two three five")), 0.001)
+	assert.Zero(t, jaccard(a, textShingles("")))
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+const (
+	defaultJobPollInterval = 2 * time.Second
+	defaultJobLockTimeout  = 15 * time.Minute
+	jobBaseBackoff         = 5 * time.Second
+	jobMaxBackoff          = 10 * time.Minute
+)
+
+// JobRunner polls the job queue and executes jobs with a fixed number of workers
+type JobRunner struct {
+	jobRepo      repositories.JobRepository
+	handlers     map[string]JobHandlerFunc
+	workers      int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	workerID     string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobRunner creates a job runner configured from the application config
+func NewJobRunner(jobRepo repositories.JobRepository, cfg *config.Config) *JobRunner {
+	pollInterval := cfg.JobPollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultJobPollInterval
+	}
+	lockTimeout := cfg.JobLockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = defaultJobLockTimeout
+	}
+
+	hostname, _ := os.Hostname()
+	return &JobRunner{
+		jobRepo:      jobRepo,
+		handlers:     make(map[string]JobHandlerFunc),
+		workers:      cfg.JobWorkers,
+		pollInterval: pollInterval,
+		lockTimeout:  lockTimeout,
+		workerID:     fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+	}
+}
+
+// Register associates a handler with a job type; register all handlers before Start
+func (r *JobRunner) Register(jobType string, handler JobHandlerFunc) {
+	r.handlers[jobType] = handler
+}
+
+// Start launches the worker goroutines; it is a no-op when no workers are configured
+func (r *JobRunner) Start(ctx context.Context) {
+	if r.workers <= 0 {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.recoverStaleJobs(ctx)
+	}()
+
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.workLoop(ctx)
+		}()
+	}
+}
+
+// Stop signals the workers to exit and waits for in-flight jobs to finish
+func (r *JobRunner) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+func (r *JobRunner) workLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting for the next tick
+		for {
+			processed, err := r.RunOnce(ctx)
+			if err != nil {
+				fmt.Printf("Warning: Job worker error: %v\n", err)
+			}
+			if !processed || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *JobRunner) recoverStaleJobs(ctx context.Context) {
+	ticker := time.NewTicker(r.lockTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		if count, err := r.jobRepo.RequeueStale(ctx, time.Now().Add(-r.lockTimeout)); err != nil {
+			fmt.Printf("Warning: Failed to requeue stale jobs: %v\n", err)
+		} else if count > 0 {
+			fmt.Printf("Requeued %d stale jobs\n", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and executes a single job, reporting whether one was processed
+func (r *JobRunner) RunOnce(ctx context.Context) (bool, error) {
+	types := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	if len(types) == 0 {
+		return false, nil
+	}
+
+	job, err := r.jobRepo.ClaimNext(ctx, r.workerID, types)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	r.execute(ctx, job)
+	return true, nil
+}
+
+func (r *JobRunner) execute(ctx context.Context, job *entities.Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Keep the lock fresh and watch for cancellation while the handler runs
+	heartbeatDone := make(chan struct{})
+	var cancelled bool
+	var mu sync.Mutex
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(r.heartbeatInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				requested, err := r.jobRepo.Heartbeat(ctx, job.ID, r.workerID)
+				// Stop the handler as well when the job was handed to another worker
+				if (err == nil && requested) || errors.Is(err, repositories.ErrJobLockLost) {
+					mu.Lock()
+					cancelled = true
+					mu.Unlock()
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	result, err := r.handlers[job.Type](jobCtx, job)
+	cancel()
+	<-heartbeatDone
+
+	mu.Lock()
+	wasCancelled := cancelled
+	mu.Unlock()
+
+	now := time.Now()
+	job.LockedAt = nil
+	job.LockedBy = ""
+	job.UpdatedAt = now
+
+	switch {
+	case wasCancelled:
+		job.Status = entities.JobStatusCancelled
+		job.Error = ErrJobCancelled.Error()
+		job.CompletedAt = &now
+	case err == nil:
+		job.Status = entities.JobStatusSucceeded
+		job.Error = ""
+		job.CompletedAt = &now
+		if result != nil {
+			resultJSON, _ := json.Marshal(result.Data)
+			job.Result = string(resultJSON)
+			job.ResultPath = result.FilePath
+		}
+	case ctx.Err() != nil:
+		// Runner is shutting down; hand the job back without spending an attempt
+		job.Status = entities.JobStatusQueued
+		job.Attempts--
+		job.RunAt = now
+	case errors.Is(err, ErrJobPermanent) || job.Attempts >= job.MaxAttempts:
+		job.Status = entities.JobStatusFailed
+		job.Error = err.Error()
+		job.CompletedAt = &now
+	default:
+		job.Status = entities.JobStatusQueued
+		job.Error = err.Error()
+		job.RunAt = now.Add(JobBackoff(job.Attempts))
+	}
+
+	// Use a fresh context so the final state is saved even during shutdown
+	saveCtx, saveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer saveCancel()
+	if err := r.jobRepo.Finish(saveCtx, job, r.workerID); err != nil {
+		fmt.Printf("Warning: Failed to update job %s: %v\n", job.ID, err)
+	}
+}
+
+func (r *JobRunner) heartbeatInterval() time.Duration {
+	interval := r.lockTimeout / 3
+	if interval > 30*time.Second {
+		interval = 30 * time.Second
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return interval
+}
+
+// JobBackoff returns the exponential delay before retrying after the given attempt
+func JobBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := jobBaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= jobMaxBackoff {
+			return jobMaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func newTestJobRunner(jobRepo *MockJobRepository) *JobRunner {
+	return NewJobRunner(jobRepo, &config.Config{JobWorkers: 1, JobLockTimeout: time.Minute})
+}
+
+func TestJobRunner_RunOnce_Success(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	job := &entities.Job{ID: "job-1", Type: "test", Status: entities.JobStatusRunning, Attempts: 1, MaxAttempts: 3}
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, []string{"test"}).Return(job, nil).Once()
+	jobRepo.On("Finish", mock.Anything, job, mock.Anything).Return(nil)
+
+	runner := newTestJobRunner(jobRepo)
+	runner.Register("test", func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		return &JobResult{Data: map[string]string{"document_id": "doc-1"}, FilePath: "/tmp/out.pdf"}, nil
+	})
+
+	processed, err := runner.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	assert.Equal(t, entities.JobStatusSucceeded, job.Status)
+	assert.JSONEq(t, `{"document_id":"doc-1"}`, job.Result)
+	assert.Equal(t, "/tmp/out.pdf", job.ResultPath)
+	assert.NotNil(t, job.CompletedAt)
+	assert.Nil(t, job.LockedAt)
+	jobRepo.AssertExpectations(t)
+}
+
+func TestJobRunner_RunOnce_EmptyQueue(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	runner := newTestJobRunner(jobRepo)
+	runner.Register("test", func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		t.Fatal("handler should not run")
+		return nil, nil
+	})
+
+	processed, err := runner.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestJobRunner_RunOnce_Failures(t *testing.T) {
+	tests := []struct {
+		name           string
+		attempts       int
+		handlerErr     error
+		expectedStatus string
+		expectRetry    bool
+	}{
+		{
+			name:           "transient failure is retried with backoff",
+			attempts:       1,
+			handlerErr:     errors.New("database unavailable"),
+			expectedStatus: entities.JobStatusQueued,
+			expectRetry:    true,
+		},
+		{
+			name:           "last attempt fails the job",
+			attempts:       3,
+			handlerErr:     errors.New("database unavailable"),
+			expectedStatus: entities.JobStatusFailed,
+		},
+		{
+			name:           "permanent failure skips retries",
+			attempts:       1,
+			handlerErr:     fmt.Errorf("%w: invalid payload", ErrJobPermanent),
+			expectedStatus: entities.JobStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobRepo := new(MockJobRepository)
+			job := &entities.Job{ID: "job-1", Type: "test", Status: entities.JobStatusRunning, Attempts: tt.attempts, MaxAttempts: 3}
+			jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(job, nil).Once()
+			jobRepo.On("Finish", mock.Anything, job, mock.Anything).Return(nil)
+
+			runner := newTestJobRunner(jobRepo)
+			runner.Register("test", func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+				return nil, tt.handlerErr
+			})
+
+			before := time.Now()
+			_, err := runner.RunOnce(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, job.Status)
+			assert.Equal(t, tt.handlerErr.Error(), job.Error)
+			if tt.expectRetry {
+				assert.Nil(t, job.CompletedAt)
+				assert.True(t, job.RunAt.After(before.Add(JobBackoff(tt.attempts)-time.Second)))
+			} else {
+				assert.NotNil(t, job.CompletedAt)
+			}
+		})
+	}
+}
+
+func TestJobRunner_CancelRunningJob(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	job := &entities.Job{ID: "job-1", Type: "test", Status: entities.JobStatusRunning, Attempts: 1, MaxAttempts: 3}
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(job, nil).Once()
+	jobRepo.On("Heartbeat", mock.Anything, "job-1", mock.Anything).Return(true, nil)
+	jobRepo.On("Finish", mock.Anything, job, mock.Anything).Return(nil)
+
+	// A short lock timeout makes the heartbeat fire quickly
+	runner := NewJobRunner(jobRepo, &config.Config{JobWorkers: 1, JobLockTimeout: 30 * time.Millisecond})
+	runner.Register("test", func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("handler was not cancelled")
+		}
+	})
+
+	_, err := runner.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.JobStatusCancelled, job.Status)
+	assert.NotNil(t, job.CompletedAt)
+}
+
+func TestJobRunner_LockLost(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	job := &entities.Job{ID: "job-1", Type: "test", Status: entities.JobStatusRunning, Attempts: 1, MaxAttempts: 3}
+	jobRepo.On("ClaimNext", mock.Anything, mock.Anything, mock.Anything).Return(job, nil).Once()
+	jobRepo.On("Heartbeat", mock.Anything, "job-1", mock.Anything).Return(false, repositories.ErrJobLockLost)
+	jobRepo.On("Finish", mock.Anything, job, mock.Anything).Return(repositories.ErrJobLockLost)
+
+	runner := NewJobRunner(jobRepo, &config.Config{JobWorkers: 1, JobLockTimeout: 30 * time.Millisecond})
+	runner.Register("test", func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("handler was not stopped")
+		}
+	})
+
+	_, err := runner.RunOnce(context.Background())
+	require.NoError(t, err)
+	jobRepo.AssertExpectations(t)
+}
+
+func TestJobBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, JobBackoff(1))
+	assert.Equal(t, 10*time.Second, JobBackoff(2))
+	assert.Equal(t, 20*time.Second, JobBackoff(3))
+	assert.Equal(t, 10*time.Minute, JobBackoff(20))
+}
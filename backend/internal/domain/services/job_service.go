@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/infrastructure/pdf"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobAccess      = errors.New("access denied: job belongs to different user")
+	ErrJobFinished    = errors.New("job has already finished")
+	ErrJobNoResult    = errors.New("job has no downloadable result")
+	ErrJobPermanent   = errors.New("permanent job failure")
+	ErrUnknownJobType = errors.New("unknown job type")
+	ErrJobCancelled   = errors.New("job cancelled")
+)
+
+// Job types handled by the background runner
+const (
+	JobTypeSignDocument = "sign_document"
+	JobTypeBatchSign    = "batch_sign"
+)
+
+// JobResult is returned by job handlers; Data is stored as JSON and FilePath is offered for download
+type JobResult struct {
+	Data     interface{}
+	FilePath string
+}
+
+// JobHandlerFunc processes a claimed job
+type JobHandlerFunc func(ctx context.Context, job *entities.Job) (*JobResult, error)
+
+// JobService enqueues background jobs and exposes their status to owners
+type JobService struct {
+	jobRepo repositories.JobRepository
+	config  *config.Config
+}
+
+// SignJobPayload describes a queued single-document signing job
+type SignJobPayload struct {
+	Filename     string `json:"filename"`
+	Issuer       string `json:"issuer"`
+	Title        string `json:"title"`
+	LetterNumber string `json:"letter_number"`
+	InputPath    string `json:"input_path"`
+	// OrganizationID is the organization the document is signed for, if any
+	OrganizationID string `json:"organization_id,omitempty"`
+	// OnBehalfOf is the delegator the job's user signs for, if any
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+	// OnDuplicate is what happens when the PDF was already signed
+	OnDuplicate string `json:"on_duplicate,omitempty"`
+	// LetterNumberScheme allocates the letter number when none was given
+	LetterNumberScheme string `json:"letter_number_scheme,omitempty"`
+	// Private keeps the document from being found by verifying its file
+	Private bool `json:"private,omitempty"`
+	// Visibility is how much the public verification page shows
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// BatchJobPayload describes a queued batch signing job
+type BatchJobPayload struct {
+	Manifest       []BatchManifestEntry `json:"manifest"`
+	InputDir       string               `json:"input_dir"`
+	OrganizationID string               `json:"organization_id,omitempty"`
+	OnBehalfOf     string               `json:"on_behalf_of,omitempty"`
+	OnDuplicate    string               `json:"on_duplicate,omitempty"`
+}
+
+// NewJobService creates a new job service
+func NewJobService(jobRepo repositories.JobRepository, config *config.Config) *JobService {
+	return &JobService{
+		jobRepo: jobRepo,
+		config:  config,
+	}
+}
+
+// Enqueue stores a new job so a worker can pick it up
+func (s *JobService) Enqueue(ctx context.Context, jobType, userID string, payload interface{}) (*entities.Job, error) {
+	return s.enqueue(ctx, uuid.New().String(), jobType, userID, payload)
+}
+
+func (s *JobService) enqueue(ctx context.Context, jobID, jobType, userID string, payload interface{}) (*entities.Job, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	maxAttempts := s.config.JobMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	now := time.Now()
+	job := &entities.Job{
+		ID:          jobID,
+		Type:        jobType,
+		UserID:      userID,
+		Status:      entities.JobStatusQueued,
+		Payload:     string(payloadJSON),
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// EnqueueSignDocument spools the PDF to job storage and queues it for signing.
+// An access_code document's access code is returned here, the one time it is
+// shown; the job keeps it only until the worker has printed it on the document.
+func (s *JobService) EnqueueSignDocument(ctx context.Context, req *SignDocumentRequest) (*entities.Job, string, error) {
+	jobID := uuid.New().String()
+	dir, err := s.prepareJobDir(jobID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	inputPath := filepath.Join(dir, "input.pdf")
+	if err := writeJobInput(inputPath, req); err != nil {
+		os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("failed to store job input: %w", err)
+	}
+
+	var accessCode string
+	if req.Visibility == entities.DocumentVisibilityAccessCode {
+		if accessCode, err = generateAccessCode(); err != nil {
+			os.RemoveAll(dir)
+			return nil, "", err
+		}
+		if err := os.WriteFile(jobAccessCodePath(inputPath), []byte(accessCode), 0600); err != nil {
+			os.RemoveAll(dir)
+			return nil, "", fmt.Errorf("failed to store job input: %w", err)
+		}
+	}
+
+	job, err := s.enqueue(ctx, jobID, JobTypeSignDocument, req.UserID, SignJobPayload{
+		Filename:           req.Filename,
+		Issuer:             req.Issuer,
+		Title:              req.Title,
+		LetterNumber:       req.LetterNumber,
+		InputPath:          inputPath,
+		OrganizationID:     req.OrganizationID,
+		OnBehalfOf:         req.OnBehalfOf,
+		OnDuplicate:        req.OnDuplicate,
+		LetterNumberScheme: req.LetterNumberScheme,
+		Private:            req.Private,
+		Visibility:         req.Visibility,
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", err
+	}
+	return job, accessCode, nil
+}
+
+// EnqueueBatch spools every batch item to job storage and queues the batch
+func (s *JobService) EnqueueBatch(ctx context.Context, req *BatchSignRequest) (*entities.Job, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrBatchEmpty
+	}
+	if s.config.BatchMaxFiles > 0 && len(req.Items) > s.config.BatchMaxFiles {
+		return nil, fmt.Errorf("%w (%d)", ErrBatchTooLarge, s.config.BatchMaxFiles)
+	}
+
+	jobID := uuid.New().String()
+	dir, err := s.prepareJobDir(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := make([]BatchManifestEntry, 0, len(req.Items))
+	for _, item := range req.Items {
+		if err := copyBatchFile(filepath.Join(dir, filepath.Base(item.Filename)), item.File); err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to store job input: %w", err)
+		}
+		manifest = append(manifest, item.BatchManifestEntry)
+	}
+
+	job, err := s.enqueue(ctx, jobID, JobTypeBatchSign, req.UserID, BatchJobPayload{
+		Manifest:       manifest,
+		InputDir:       dir,
+		OrganizationID: req.OrganizationID,
+		OnBehalfOf:     req.OnBehalfOf,
+		OnDuplicate:    req.OnDuplicate,
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job owned by the user
+func (s *JobService) GetJob(ctx context.Context, userID, jobID string) (*entities.Job, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	if job.UserID != userID {
+		return nil, ErrJobAccess
+	}
+	return job, nil
+}
+
+// ListJobs returns the user's most recent jobs
+func (s *JobService) ListJobs(ctx context.Context, userID string, limit int) ([]*entities.Job, error) {
+	jobs, err := s.jobRepo.GetByUserID(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// CancelJob cancels a queued job immediately or asks the worker to stop a running one
+func (s *JobService) CancelJob(ctx context.Context, userID, jobID string) (*entities.Job, error) {
+	job, err := s.GetJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return nil, ErrJobFinished
+	}
+
+	cancelled, err := s.jobRepo.RequestCancel(ctx, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if !cancelled {
+		// The job finished between reading and cancelling it
+		return nil, ErrJobFinished
+	}
+
+	job, err = s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+
+	// A queued signing job never runs, so its input and access code go now
+	var payload SignJobPayload
+	if job.Type == JobTypeSignDocument && job.Status == entities.JobStatusCancelled &&
+		json.Unmarshal([]byte(job.Payload), &payload) == nil && payload.InputPath != "" {
+		removeJobInput(payload.InputPath)
+	}
+	return job, nil
+}
+
+// GetJobResultPath returns the downloadable output of a succeeded job
+func (s *JobService) GetJobResultPath(ctx context.Context, userID, jobID string) (string, error) {
+	job, err := s.GetJob(ctx, userID, jobID)
+	if err != nil {
+		return "", err
+	}
+	if job.Status != entities.JobStatusSucceeded || job.ResultPath == "" {
+		return "", ErrJobNoResult
+	}
+	return job.ResultPath, nil
+}
+
+// ShouldRunAsync reports whether a signing request is large enough to be queued
+func (s *JobService) ShouldRunAsync(size int64) bool {
+	return s.config.AsyncSignThreshold > 0 && size >= s.config.AsyncSignThreshold
+}
+
+// writeJobInput stores the document to sign, streaming it from the spool file when there is one
+func writeJobInput(path string, req *SignDocumentRequest) error {
+	if req.Source == nil {
+		return os.WriteFile(path, req.PDFData, 0640)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := req.Source.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// jobAccessCodePath is where a queued access_code document's access code waits for the worker
+func jobAccessCodePath(inputPath string) string {
+	return filepath.Join(filepath.Dir(inputPath), "access_code")
+}
+
+// removeJobInput deletes a signing job's input once it cannot be needed again
+func removeJobInput(inputPath string) {
+	os.Remove(inputPath)
+	os.Remove(jobAccessCodePath(inputPath))
+}
+
+func (s *JobService) prepareJobDir(jobID string) (string, error) {
+	dir := filepath.Join(s.config.StorageDir, "jobs", jobID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return dir, nil
+}
+
+// PDFSpooler streams a PDF to disk, hashing and parsing it once
+type PDFSpooler interface {
+	SpoolPDF(reader io.Reader) (*pdf.SpooledPDF, error)
+}
+
+// NewSignDocumentJobHandler signs a spooled PDF and stores the stamped file next to it
+func NewSignDocumentJobHandler(signer DocumentSignerInterface, spooler PDFSpooler) JobHandlerFunc {
+	return func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		var payload SignJobPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", ErrJobPermanent, err)
+		}
+
+		input, err := os.Open(payload.InputPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read job input: %v", ErrJobPermanent, err)
+		}
+		source, err := spooler.SpoolPDF(input)
+		input.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read job input: %v", ErrJobPermanent, err)
+		}
+		defer source.Close()
+
+		var accessCode string
+		if payload.Visibility == entities.DocumentVisibilityAccessCode {
+			code, err := os.ReadFile(jobAccessCodePath(payload.InputPath))
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to read job input: %v", ErrJobPermanent, err)
+			}
+			accessCode = string(code)
+		}
+
+		outputPath := filepath.Join(filepath.Dir(payload.InputPath), "signed.pdf")
+		output, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store signed PDF: %w", err)
+		}
+		defer output.Close()
+
+		response, err := signer.SignDocument(ctx, &SignDocumentRequest{
+			Filename:           payload.Filename,
+			Issuer:             payload.Issuer,
+			Title:              payload.Title,
+			LetterNumber:       payload.LetterNumber,
+			Source:             source,
+			Output:             output,
+			UserID:             job.UserID,
+			OrganizationID:     payload.OrganizationID,
+			OnBehalfOf:         payload.OnBehalfOf,
+			OnDuplicate:        payload.OnDuplicate,
+			LetterNumberScheme: payload.LetterNumberScheme,
+			Private:            payload.Private,
+			Visibility:         payload.Visibility,
+			AccessCode:         accessCode,
+		})
+		if err != nil {
+			os.Remove(outputPath)
+			// Retrying cannot bring back a revoked or expired delegation, make an
+			// already signed PDF new or find a missing numbering scheme
+			if errors.Is(err, ErrNoActiveDelegation) || errors.Is(err, ErrDuplicateDocument) || errors.Is(err, ErrInvalidDuplicatePolicy) ||
+				errors.Is(err, ErrLetterNumberSchemeNotFound) || errors.Is(err, ErrLetterNumberConflict) || errors.Is(err, ErrLetterNumberRequired) ||
+				errors.Is(err, ErrInvalidVisibility) {
+				os.Remove(jobAccessCodePath(payload.InputPath))
+				return nil, fmt.Errorf("%w: %v", ErrJobPermanent, err)
+			}
+			return nil, err
+		}
+		removeJobInput(payload.InputPath)
+		if response.Existing {
+			// Nothing was signed; the existing document is downloaded from its own endpoint
+			os.Remove(outputPath)
+			return &JobResult{
+				Data: map[string]interface{}{"document": response.Document, "existing": true},
+			}, nil
+		}
+
+		if err := output.Close(); err != nil {
+			return nil, fmt.Errorf("failed to store signed PDF: %w", err)
+		}
+		// The access code was handed out when the job was queued and is not kept in its result
+		return &JobResult{
+			Data:     map[string]interface{}{"document": response.Document},
+			FilePath: outputPath,
+		}, nil
+	}
+}
+
+// NewBatchJobHandler runs a spooled batch through the batch service
+func NewBatchJobHandler(batchService *BatchService) JobHandlerFunc {
+	return func(ctx context.Context, job *entities.Job) (*JobResult, error) {
+		var payload BatchJobPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", ErrJobPermanent, err)
+		}
+
+		items := make([]BatchItem, 0, len(payload.Manifest))
+		for _, entry := range payload.Manifest {
+			inputPath := filepath.Join(payload.InputDir, filepath.Base(entry.Filename))
+			if _, err := os.Stat(inputPath); err != nil {
+				return nil, fmt.Errorf("%w: failed to read job input: %v", ErrJobPermanent, err)
+			}
+			items = append(items, BatchItem{BatchManifestEntry: entry, File: BatchFile{Path: inputPath}})
+		}
+
+		batchJob, report, err := batchService.SignBatch(ctx, &BatchSignRequest{
+			UserID:         job.UserID,
+			OrganizationID: payload.OrganizationID,
+			OnBehalfOf:     payload.OnBehalfOf,
+			OnDuplicate:    payload.OnDuplicate,
+			Items:          items,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range payload.Manifest {
+			os.Remove(filepath.Join(payload.InputDir, filepath.Base(entry.Filename)))
+		}
+
+		return &JobResult{
+			Data:     map[string]interface{}{"batch_id": batchJob.ID, "report": report},
+			FilePath: batchJob.ResultPath,
+		}, nil
+	}
+}
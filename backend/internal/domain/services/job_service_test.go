@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/pdf"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(ctx context.Context, job *entities.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) GetByID(ctx context.Context, id string) (*entities.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) GetByUserID(ctx context.Context, userID string, limit int) ([]*entities.Job, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Update(ctx context.Context, job *entities.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ClaimNext(ctx context.Context, workerID string, types []string) (*entities.Job, error) {
+	args := m.Called(ctx, workerID, types)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Heartbeat(ctx context.Context, id, workerID string) (bool, error) {
+	args := m.Called(ctx, id, workerID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) RequestCancel(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) Finish(ctx context.Context, job *entities.Job, workerID string) error {
+	args := m.Called(ctx, job, workerID)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, lockedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestJobService_EnqueueSignDocument(t *testing.T) {
+	storageDir := t.TempDir()
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Job")).Return(nil)
+
+	service := NewJobService(jobRepo, &config.Config{StorageDir: storageDir, JobMaxAttempts: 3})
+
+	job, accessCode, err := service.EnqueueSignDocument(context.Background(), &SignDocumentRequest{
+		Filename:     "large.pdf",
+		Issuer:       "Registrar",
+		Title:        "Certificate",
+		LetterNumber: "001/REG/2026",
+		PDFData:      []byte("%PDF-1.4 large"),
+		UserID:       "user-123",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, JobTypeSignDocument, job.Type)
+	assert.Equal(t, entities.JobStatusQueued, job.Status)
+	assert.Equal(t, "user-123", job.UserID)
+	assert.Equal(t, 3, job.MaxAttempts)
+
+	var payload SignJobPayload
+	require.NoError(t, json.Unmarshal([]byte(job.Payload), &payload))
+	assert.Equal(t, "large.pdf", payload.Filename)
+	assert.Equal(t, filepath.Join(storageDir, "jobs", job.ID, "input.pdf"), payload.InputPath)
+
+	data, err := os.ReadFile(payload.InputPath)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-1.4 large"), data)
+	assert.Empty(t, accessCode)
+}
+
+func TestJobService_EnqueueSignDocument_AccessCode(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Job")).Return(nil)
+
+	service := NewJobService(jobRepo, &config.Config{StorageDir: t.TempDir()})
+
+	job, accessCode, err := service.EnqueueSignDocument(context.Background(), &SignDocumentRequest{
+		PDFData:    []byte("%PDF-1.4 large"),
+		UserID:     "user-123",
+		Visibility: entities.DocumentVisibilityAccessCode,
+	})
+	require.NoError(t, err)
+	assert.Len(t, accessCode, accessCodeLength+1)
+
+	// The code waits for the worker next to the input and is not in the stored payload
+	assert.NotContains(t, job.Payload, accessCode)
+	var payload SignJobPayload
+	require.NoError(t, json.Unmarshal([]byte(job.Payload), &payload))
+	stored, err := os.ReadFile(jobAccessCodePath(payload.InputPath))
+	require.NoError(t, err)
+	assert.Equal(t, accessCode, string(stored))
+}
+
+func TestJobService_EnqueueSignDocument_CleansUpOnFailure(t *testing.T) {
+	storageDir := t.TempDir()
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Job")).Return(errors.New("db down"))
+
+	service := NewJobService(jobRepo, &config.Config{StorageDir: storageDir})
+
+	_, _, err := service.EnqueueSignDocument(context.Background(), &SignDocumentRequest{PDFData: []byte("pdf"), UserID: "user-123"})
+	assert.Error(t, err)
+
+	entries, _ := os.ReadDir(filepath.Join(storageDir, "jobs"))
+	assert.Empty(t, entries)
+}
+
+func TestJobService_CancelJob(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("GetByID", mock.Anything, "queued").Return(&entities.Job{ID: "queued", UserID: "user-123", Status: entities.JobStatusQueued}, nil).Once()
+	jobRepo.On("GetByID", mock.Anything, "queued").Return(&entities.Job{ID: "queued", UserID: "user-123", Status: entities.JobStatusCancelled, CancelRequested: true}, nil).Once()
+	jobRepo.On("GetByID", mock.Anything, "running").Return(&entities.Job{ID: "running", UserID: "user-123", Status: entities.JobStatusRunning}, nil).Once()
+	jobRepo.On("GetByID", mock.Anything, "running").Return(&entities.Job{ID: "running", UserID: "user-123", Status: entities.JobStatusRunning, CancelRequested: true}, nil).Once()
+	jobRepo.On("GetByID", mock.Anything, "racing").Return(&entities.Job{ID: "racing", UserID: "user-123", Status: entities.JobStatusRunning}, nil)
+	jobRepo.On("GetByID", mock.Anything, "done").Return(&entities.Job{ID: "done", UserID: "user-123", Status: entities.JobStatusSucceeded}, nil)
+	jobRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+	jobRepo.On("RequestCancel", mock.Anything, "queued").Return(true, nil)
+	jobRepo.On("RequestCancel", mock.Anything, "running").Return(true, nil)
+	// The job finished after it was read
+	jobRepo.On("RequestCancel", mock.Anything, "racing").Return(false, nil)
+
+	service := NewJobService(jobRepo, &config.Config{})
+	ctx := context.Background()
+
+	job, err := service.CancelJob(ctx, "user-123", "queued")
+	require.NoError(t, err)
+	assert.Equal(t, entities.JobStatusCancelled, job.Status)
+
+	// Running jobs are flagged and stopped by the worker on its next heartbeat
+	job, err = service.CancelJob(ctx, "user-123", "running")
+	require.NoError(t, err)
+	assert.Equal(t, entities.JobStatusRunning, job.Status)
+	assert.True(t, job.CancelRequested)
+
+	_, err = service.CancelJob(ctx, "user-123", "racing")
+	assert.ErrorIs(t, err, ErrJobFinished)
+
+	_, err = service.CancelJob(ctx, "user-123", "done")
+	assert.ErrorIs(t, err, ErrJobFinished)
+
+	_, err = service.CancelJob(ctx, "other-user", "done")
+	assert.ErrorIs(t, err, ErrJobAccess)
+
+	_, err = service.CancelJob(ctx, "user-123", "missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	jobRepo.AssertNotCalled(t, "RequestCancel", mock.Anything, "done")
+	jobRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestJobService_GetJobResultPath(t *testing.T) {
+	jobRepo := new(MockJobRepository)
+	jobRepo.On("GetByID", mock.Anything, "done").Return(&entities.Job{ID: "done", UserID: "user-123", Status: entities.JobStatusSucceeded, ResultPath: "/tmp/out.pdf"}, nil)
+	jobRepo.On("GetByID", mock.Anything, "running").Return(&entities.Job{ID: "running", UserID: "user-123", Status: entities.JobStatusRunning}, nil)
+
+	service := NewJobService(jobRepo, &config.Config{})
+
+	path, err := service.GetJobResultPath(context.Background(), "user-123", "done")
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/out.pdf", path)
+
+	_, err = service.GetJobResultPath(context.Background(), "user-123", "running")
+	assert.ErrorIs(t, err, ErrJobNoResult)
+}
+
+func TestJobService_ShouldRunAsync(t *testing.T) {
+	assert.False(t, NewJobService(nil, &config.Config{}).ShouldRunAsync(100<<20))
+
+	service := NewJobService(nil, &config.Config{AsyncSignThreshold: 10 << 20})
+	assert.False(t, service.ShouldRunAsync(1<<20))
+	assert.True(t, service.ShouldRunAsync(10<<20))
+}
+
+func TestSignDocumentJobHandler(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.pdf")
+	require.NoError(t, os.WriteFile(inputPath, []byte(testSpoolPDF), 0640))
+
+	signer := new(MockDocumentSigner)
+	signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+		return req.Filename == "a.pdf" && req.UserID == "user-123" && req.PDFData == nil &&
+			req.Source != nil && req.Source.Size() == int64(len(testSpoolPDF)) && req.Output != nil
+	})).Run(func(args mock.Arguments) {
+		_, _ = args.Get(1).(*SignDocumentRequest).Output.Write([]byte("signed"))
+	}).Return(&SignDocumentResponse{
+		Document: &entities.Document{ID: "doc-1"},
+	}, nil)
+
+	payload, _ := json.Marshal(SignJobPayload{Filename: "a.pdf", Issuer: "R", Title: "T", LetterNumber: "1", InputPath: inputPath})
+	handler := NewSignDocumentJobHandler(signer, pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()))
+
+	result, err := handler(context.Background(), &entities.Job{UserID: "user-123", Payload: string(payload)})
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "signed.pdf"), result.FilePath)
+	signed, err := os.ReadFile(result.FilePath)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("signed"), signed)
+	assert.NoFileExists(t, inputPath)
+
+	// A missing input can never succeed, so it must not be retried
+	_, err = handler(context.Background(), &entities.Job{UserID: "user-123", Payload: string(payload)})
+	assert.ErrorIs(t, err, ErrJobPermanent)
+}
+
+func TestSignDocumentJobHandler_AccessCode(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.pdf")
+	require.NoError(t, os.WriteFile(inputPath, []byte(testSpoolPDF), 0640))
+	require.NoError(t, os.WriteFile(jobAccessCodePath(inputPath), []byte("K7QM-3XPA"), 0600))
+
+	signer := new(MockDocumentSigner)
+	signer.On("SignDocument", mock.Anything, mock.MatchedBy(func(req *SignDocumentRequest) bool {
+		return req.AccessCode == "K7QM-3XPA"
+	})).Return(&SignDocumentResponse{
+		Document:   &entities.Document{ID: "doc-1"},
+		AccessCode: "K7QM-3XPA",
+	}, nil)
+
+	payload, _ := json.Marshal(SignJobPayload{Filename: "a.pdf", InputPath: inputPath, Visibility: entities.DocumentVisibilityAccessCode})
+	handler := NewSignDocumentJobHandler(signer, pdf.NewPDFServiceWithLimits(pdf.MaxPDFSize, t.TempDir()))
+
+	result, err := handler(context.Background(), &entities.Job{UserID: "user-123", Payload: string(payload)})
+	require.NoError(t, err)
+
+	// The code was handed out when the job was queued and is not kept
+	assert.NotContains(t, result.Data, "access_code")
+	assert.NoFileExists(t, jobAccessCodePath(inputPath))
+}
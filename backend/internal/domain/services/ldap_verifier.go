@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+// LDAPIdentityProvider is the provider name of directory accounts in user identities
+const LDAPIdentityProvider = "ldap"
+
+const defaultLDAPTimeout = 10 * time.Second
+
+var (
+	ErrLDAPUnavailable     = errors.New("directory server is unavailable")
+	ErrLDAPAccessDenied    = errors.New("directory account is not permitted to sign in")
+	ErrLDAPAccountConflict = errors.New("a local account with this username or email already exists")
+)
+
+// LDAPVerifier checks passwords against an LDAP or Active Directory server. It finds
+// the user's entry with the service account and then binds as that entry with the
+// password. Accounts are provisioned on first login and linked through a user identity.
+type LDAPVerifier struct {
+	cfg          config.LDAPConfig
+	tlsConfig    *tls.Config
+	userRepo     repositories.UserRepository
+	identityRepo repositories.OIDCRepository
+}
+
+// ldapAccount is the part of a directory entry the verifier uses
+type ldapAccount struct {
+	DN       string
+	ID       string
+	Username string
+	Email    string
+	FullName string
+	Groups   []string
+}
+
+// NewLDAPVerifier creates a verifier for the configured directory
+func NewLDAPVerifier(cfg config.LDAPConfig, userRepo repositories.UserRepository, identityRepo repositories.OIDCRepository) (*LDAPVerifier, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, fmt.Errorf("LDAP_URL and LDAP_BASE_DN are required")
+	}
+	if cfg.UsernameAttribute == "" {
+		return nil, fmt.Errorf("LDAP_USERNAME_ATTRIBUTE is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultLDAPTimeout
+	}
+
+	serverURL, err := url.Parse(cfg.URL)
+	if err != nil || serverURL.Hostname() == "" {
+		return nil, fmt.Errorf("invalid LDAP_URL %q", cfg.URL)
+	}
+	switch serverURL.Scheme {
+	case "ldaps":
+	case "ldap":
+		if !cfg.StartTLS {
+			fmt.Printf("Warning: LDAP passwords are sent unencrypted; use ldaps:// or set LDAP_START_TLS\n")
+		}
+	default:
+		return nil, fmt.Errorf("LDAP_URL must use ldap:// or ldaps://")
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         serverURL.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LDAP CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &LDAPVerifier{
+		cfg:          cfg,
+		tlsConfig:    tlsConfig,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+	}, nil
+}
+
+func (v *LDAPVerifier) Name() string {
+	return LDAPIdentityProvider
+}
+
+// Verify authenticates against the directory and returns the linked account
+func (v *LDAPVerifier) Verify(ctx context.Context, username, password string) (*entities.User, error) {
+	// A bind with an empty password is an unauthenticated bind, which servers accept
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	account, err := v.authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	role, allowed := mapRole(v.cfg.RoleMappings, v.cfg.DefaultRole, account.Groups)
+	if !allowed {
+		return nil, ErrLDAPAccessDenied
+	}
+
+	return v.resolveUser(ctx, account, role)
+}
+
+// authenticate finds the user's entry, binds as it and collects its groups
+func (v *LDAPVerifier) authenticate(username, password string) (*ldapAccount, error) {
+	conn, err := v.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := v.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+
+	account, err := v.findUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := conn.Bind(account.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: %v", ErrLDAPUnavailable, err)
+	}
+
+	if v.cfg.GroupBaseDN != "" {
+		// Group entries are searched with the service account, not the user
+		if err := v.bindServiceAccount(conn); err != nil {
+			return nil, err
+		}
+		groups, err := v.searchGroups(conn, account)
+		if err != nil {
+			return nil, err
+		}
+		account.Groups = append(account.Groups, groups...)
+	}
+	return account, nil
+}
+
+func (v *LDAPVerifier) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(v.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: v.cfg.Timeout}),
+		ldap.DialWithTLSConfig(v.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLDAPUnavailable, err)
+	}
+	conn.SetTimeout(v.cfg.Timeout)
+
+	if v.cfg.StartTLS {
+		if err := conn.StartTLS(v.tlsConfig.Clone()); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: StartTLS failed: %v", ErrLDAPUnavailable, err)
+		}
+	}
+	return conn, nil
+}
+
+// bindServiceAccount binds as the search account; without one the search is anonymous
+func (v *LDAPVerifier) bindServiceAccount(conn *ldap.Conn) error {
+	if v.cfg.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(v.cfg.BindDN, v.cfg.BindPassword); err != nil {
+		fmt.Printf("Warning: LDAP service account bind failed: %v\n", err)
+		return fmt.Errorf("%w: service account bind failed", ErrLDAPUnavailable)
+	}
+	return nil
+}
+
+// findUser returns the single entry for the username, or nil when there is none.
+// A username matching several entries is treated as unknown.
+func (v *LDAPVerifier) findUser(conn *ldap.Conn, username string) (*ldapAccount, error) {
+	filter := fmt.Sprintf("(&%s(%s=%s))", v.cfg.UserFilter, v.cfg.UsernameAttribute, ldap.EscapeFilter(username))
+
+	var attributes []string
+	for _, attribute := range []string{v.cfg.UsernameAttribute, v.cfg.IDAttribute, v.cfg.EmailAttribute, v.cfg.NameAttribute, v.cfg.GroupAttribute} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		v.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(v.cfg.Timeout.Seconds()), false,
+		filter, attributes, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("%w: user search failed: %v", ErrLDAPUnavailable, err)
+	}
+	if err != nil || len(result.Entries) > 1 {
+		fmt.Printf("Warning: LDAP username %q matches more than one entry\n", username)
+		return nil, nil
+	}
+	if len(result.Entries) == 0 {
+		return nil, nil
+	}
+
+	entry := result.Entries[0]
+	account := &ldapAccount{
+		DN:       entry.DN,
+		Username: entry.GetAttributeValue(v.cfg.UsernameAttribute),
+		FullName: entry.GetAttributeValue(v.cfg.NameAttribute),
+	}
+	if account.Username == "" {
+		account.Username = username
+	}
+	if v.cfg.EmailAttribute != "" {
+		account.Email = strings.ToLower(entry.GetAttributeValue(v.cfg.EmailAttribute))
+	}
+	if v.cfg.GroupAttribute != "" {
+		account.Groups = ldapGroupNames(entry.GetAttributeValues(v.cfg.GroupAttribute))
+	}
+
+	account.ID = strings.ToLower(account.Username)
+	if v.cfg.IDAttribute != "" {
+		account.ID = ldapIdentifier(entry.GetRawAttributeValue(v.cfg.IDAttribute))
+		if account.ID == "" {
+			fmt.Printf("Warning: LDAP entry %s has no %s\n", entry.DN, v.cfg.IDAttribute)
+			return nil, ErrLDAPAccessDenied
+		}
+	}
+	return account, nil
+}
+
+// searchGroups returns the groups under GroupBaseDN that list the user
+func (v *LDAPVerifier) searchGroups(conn *ldap.Conn, account *ldapAccount) ([]string, error) {
+	filter := strings.NewReplacer(
+		"{dn}", ldap.EscapeFilter(account.DN),
+		"{username}", ldap.EscapeFilter(account.Username),
+	).Replace(v.cfg.GroupFilter)
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		v.cfg.GroupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(v.cfg.Timeout.Seconds()), false,
+		filter, []string{"cn"}, nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%w: group search failed: %v", ErrLDAPUnavailable, err)
+	}
+
+	dns := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		dns = append(dns, entry.DN)
+	}
+	return ldapGroupNames(dns), nil
+}
+
+// resolveUser finds the account linked to the directory entry, links the local
+// account with the same username when allowed, or provisions a new one
+func (v *LDAPVerifier) resolveUser(ctx context.Context, account *ldapAccount, role string) (*entities.User, error) {
+	now := time.Now()
+
+	identity, err := v.identityRepo.GetIdentity(ctx, LDAPIdentityProvider, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := v.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			identity.Email = account.Email
+			identity.LastLoginAt = &now
+			if err := v.identityRepo.UpdateIdentity(ctx, identity); err != nil {
+				return nil, err
+			}
+			return syncRole(ctx, v.userRepo, v.cfg.RoleMappings, user, role)
+		}
+		// The account was deleted; the entry may be linked or provisioned again
+		if err := v.identityRepo.DeleteIdentity(ctx, identity.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := v.userRepo.GetByUsername(ctx, account.Username)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	// Service accounts are never taken over by a directory login
+	case user != nil && v.cfg.LinkExisting && !user.ServiceAccount:
+		user, err = syncRole(ctx, v.userRepo, v.cfg.RoleMappings, user, role)
+		if err != nil {
+			return nil, err
+		}
+	case user != nil:
+		return nil, ErrLDAPAccountConflict
+	case v.cfg.AutoProvision:
+		user, err = v.provisionUser(ctx, account, role)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrLDAPAccessDenied
+	}
+
+	if err := v.identityRepo.CreateIdentity(ctx, &entities.UserIdentity{
+		UserID:      user.ID,
+		Provider:    LDAPIdentityProvider,
+		Subject:     account.ID,
+		Email:       account.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionUser creates an account for a first directory login. The account has
+// no local password, so it can only sign in through the directory.
+func (v *LDAPVerifier) provisionUser(ctx context.Context, account *ldapAccount, role string) (*entities.User, error) {
+	if account.Email == "" {
+		fmt.Printf("Warning: LDAP entry %s has no email address and cannot be provisioned\n", account.DN)
+		return nil, ErrLDAPAccessDenied
+	}
+	existing, err := v.userRepo.GetByEmail(ctx, account.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrLDAPAccountConflict
+	}
+
+	fullName := account.FullName
+	if fullName == "" {
+		fullName = account.Username
+	}
+	user := &entities.User{
+		Username: account.Username,
+		FullName: fullName,
+		Email:    account.Email,
+		Role:     role,
+		IsActive: true,
+	}
+	if err := v.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// ldapGroupNames returns each group DN followed by its common name, so role mappings
+// can name a group either way
+func ldapGroupNames(dns []string) []string {
+	names := make([]string, 0, 2*len(dns))
+	for _, dn := range dns {
+		names = append(names, dn)
+		parsed, err := ldap.ParseDN(dn)
+		if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+			continue
+		}
+		names = append(names, parsed.RDNs[0].Attributes[0].Value)
+	}
+	return names
+}
+
+// ldapIdentifier returns a textual identifier as is and a binary one, like
+// objectGUID, hex encoded
+func ldapIdentifier(raw []byte) string {
+	if !utf8.Valid(raw) {
+		return hex.EncodeToString(raw)
+	}
+	for _, b := range raw {
+		if b < 0x20 || b == 0x7f {
+			return hex.EncodeToString(raw)
+		}
+	}
+	return string(raw)
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+type ldapTestEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// ldapTestServer is an in-process LDAP server answering simple binds, subtree
+// searches with and/or/not/equality/present filters, and StartTLS
+type ldapTestServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	caFile    string
+	entries   []*ldapTestEntry
+
+	mu sync.Mutex
+	// binds records each bind DN and whether the connection was encrypted
+	binds []ldapTestBind
+}
+
+type ldapTestBind struct {
+	dn  string
+	tls bool
+}
+
+func newLDAPTestServer(t *testing.T, entries ...*ldapTestEntry) *ldapTestServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	tlsConfig, caFile := newLDAPTestCertificate(t)
+	s := &ldapTestServer{listener: listener, tlsConfig: tlsConfig, caFile: caFile, entries: entries}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *ldapTestServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapTestServer) bindLog() []ldapTestBind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ldapTestBind(nil), s.binds...)
+}
+
+func (s *ldapTestServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *ldapTestServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	encrypted := false
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			s.mu.Lock()
+			s.binds = append(s.binds, ldapTestBind{dn: dn, tls: encrypted})
+			s.mu.Unlock()
+
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if entry := s.entry(dn); entry != nil && password != "" && entry.password == password {
+				code = ldap.LDAPResultSuccess
+			}
+			conn.Write(ldapTestResult(messageID, ldap.ApplicationBindResponse, code).Bytes())
+
+		case ldap.ApplicationSearchRequest:
+			s.search(conn, messageID, op)
+
+		case ldap.ApplicationExtendedRequest:
+			if op.Children[0].Data.String() != ldapStartTLSOID {
+				conn.Write(ldapTestResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError).Bytes())
+				continue
+			}
+			conn.Write(ldapTestResult(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess).Bytes())
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			encrypted = true
+
+		default:
+			// Unbind and anything unsupported end the connection
+			return
+		}
+	}
+}
+
+func (s *ldapTestServer) search(conn net.Conn, messageID int64, op *ber.Packet) {
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var requested []string
+	for _, attribute := range op.Children[7].Children {
+		requested = append(requested, attribute.Data.String())
+	}
+
+	sent := 0
+	for _, entry := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(entry.dn), baseDN) || !entry.matches(filter) {
+			continue
+		}
+		if sizeLimit > 0 && int64(sent) == sizeLimit {
+			conn.Write(ldapTestResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded).Bytes())
+			return
+		}
+		conn.Write(entry.encode(messageID, requested).Bytes())
+		sent++
+	}
+	conn.Write(ldapTestResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+}
+
+func (s *ldapTestServer) entry(dn string) *ldapTestEntry {
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.dn, dn) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func (e *ldapTestEntry) values(name string) []string {
+	for attribute, values := range e.attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func (e *ldapTestEntry) matches(filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !e.matches(child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if e.matches(child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !e.matches(filter.Children[0])
+	case ldap.FilterEqualityMatch:
+		want := filter.Children[1].Data.String()
+		for _, value := range e.values(filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(e.values(filter.Data.String())) > 0
+	default:
+		return false
+	}
+}
+
+func (e *ldapTestEntry) encode(messageID int64, requested []string) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "Object Name"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for _, name := range requested {
+		values := e.values(name)
+		if len(values) == 0 {
+			continue
+		}
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	result.AppendChild(attributes)
+	return ldapTestMessage(messageID, result)
+}
+
+func ldapTestResult(messageID int64, tag ber.Tag, code uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return ldapTestMessage(messageID, result)
+}
+
+func ldapTestMessage(messageID int64, op *ber.Packet) *ber.Packet {
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	message.AppendChild(op)
+	return message
+}
+
+// newLDAPTestCertificate creates a self-signed certificate for 127.0.0.1 and writes
+// it to a file for LDAP_CA_CERT_FILE
+func newLDAPTestCertificate(t *testing.T) (*tls.Config, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldap.test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ldap-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, caFile
+}
+
+var (
+	ldapServiceAccount = &ldapTestEntry{
+		dn:       "cn=signing-app,ou=services,dc=example,dc=edu",
+		password: "service-secret",
+	}
+	ldapAda = &ldapTestEntry{
+		dn:       "uid=ada,ou=people,dc=example,dc=edu",
+		password: "analytical-engine",
+		attributes: map[string][]string{
+			"objectClass": {"person", "inetOrgPerson"},
+			"uid":         {"ada"},
+			"cn":          {"Ada Lovelace"},
+			"mail":        {"Ada@Example.edu"},
+			"memberOf":    {"cn=Signature Admins,ou=groups,dc=example,dc=edu", "cn=staff,ou=groups,dc=example,dc=edu"},
+		},
+	}
+	ldapAlan = &ldapTestEntry{
+		dn:       "uid=alan,ou=people,dc=example,dc=edu",
+		password: "enigma",
+		attributes: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"alan"},
+			"cn":          {"Alan Turing"},
+			"mail":        {"alan@example.edu"},
+		},
+	}
+	ldapStaffGroup = &ldapTestEntry{
+		dn: "cn=registrar,ou=groups,dc=example,dc=edu",
+		attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"registrar"},
+			"member":      {"uid=alan,ou=people,dc=example,dc=edu"},
+		},
+	}
+	// A second entry with the same uid in another branch
+	ldapAdaDuplicate = &ldapTestEntry{
+		dn: "uid=ada,ou=alumni,dc=example,dc=edu",
+		attributes: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"ada"},
+		},
+	}
+)
+
+func newTestLDAPConfig(server *ldapTestServer) config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:               server.url(),
+		StartTLS:          true,
+		CACertFile:        server.caFile,
+		BindDN:            ldapServiceAccount.dn,
+		BindPassword:      ldapServiceAccount.password,
+		BaseDN:            "dc=example,dc=edu",
+		UserFilter:        "(objectClass=person)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NameAttribute:     "cn",
+		GroupAttribute:    "memberOf",
+		GroupFilter:       "(member={dn})",
+		RoleMappings:      []config.RoleMapping{{Group: "signature admins", Role: "admin"}},
+		DefaultRole:       "user",
+		AutoProvision:     true,
+		Timeout:           5 * time.Second,
+	}
+}
+
+func newTestLDAPVerifier(t *testing.T, cfg config.LDAPConfig) (*LDAPVerifier, *MockUserRepository, *MockOIDCRepository) {
+	userRepo := new(MockUserRepository)
+	identityRepo := new(MockOIDCRepository)
+	verifier, err := NewLDAPVerifier(cfg, userRepo, identityRepo)
+	require.NoError(t, err)
+	return verifier, userRepo, identityRepo
+}
+
+func TestLDAPVerifier_ProvisionsUser(t *testing.T) {
+	server := newLDAPTestServer(t, ldapServiceAccount, ldapAda, ldapAlan)
+	verifier, userRepo, identityRepo := newTestLDAPVerifier(t, newTestLDAPConfig(server))
+	ctx := context.Background()
+
+	identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "ada").Return(nil, nil)
+	userRepo.On("GetByUsername", ctx, "ada").Return(nil, nil)
+	userRepo.On("GetByEmail", ctx, "ada@example.edu").Return(nil, nil)
+	var created *entities.User
+	userRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*entities.User)
+		created.ID = "user-1"
+	}).Return(nil)
+	identityRepo.On("CreateIdentity", ctx, mock.MatchedBy(func(identity *entities.UserIdentity) bool {
+		return identity.UserID == "user-1" && identity.Provider == LDAPIdentityProvider && identity.Subject == "ada"
+	})).Return(nil)
+
+	user, err := verifier.Verify(ctx, "ada", "analytical-engine")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.ID)
+	require.NotNil(t, created)
+	assert.Equal(t, "Ada Lovelace", created.FullName)
+	assert.Equal(t, "ada@example.edu", created.Email)
+	assert.Equal(t, "admin", created.Role, "group names match case-insensitively by common name")
+	assert.Empty(t, created.PasswordHash)
+	identityRepo.AssertExpectations(t)
+
+	// The service account searched and the user bound, both after StartTLS
+	binds := server.bindLog()
+	require.Len(t, binds, 2)
+	assert.Equal(t, ldapServiceAccount.dn, binds[0].dn)
+	assert.Equal(t, ldapAda.dn, binds[1].dn)
+	for _, bind := range binds {
+		assert.True(t, bind.tls, "credentials must not be sent before StartTLS")
+	}
+}
+
+func TestLDAPVerifier_InvalidCredentials(t *testing.T) {
+	server := newLDAPTestServer(t, ldapServiceAccount, ldapAda, ldapAlan, ldapAdaDuplicate)
+	cfg := newTestLDAPConfig(server)
+	verifier, _, _ := newTestLDAPVerifier(t, cfg)
+	ctx := context.Background()
+
+	_, err := verifier.Verify(ctx, "alan", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = verifier.Verify(ctx, "grace", "anything")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Filter syntax in the username is escaped rather than interpreted
+	_, err = verifier.Verify(ctx, "*", "enigma")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = verifier.Verify(ctx, "alan)(uid=*", "enigma")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// A username that matches two entries is not guessed at
+	_, err = verifier.Verify(ctx, "ada", "analytical-engine")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	before := len(server.bindLog())
+	_, err = verifier.Verify(ctx, "alan", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Len(t, server.bindLog(), before, "an empty password never reaches the server")
+}
+
+func TestLDAPVerifier_GroupSearchAndRoleSync(t *testing.T) {
+	server := newLDAPTestServer(t, ldapServiceAccount, ldapAlan, ldapStaffGroup)
+	cfg := newTestLDAPConfig(server)
+	cfg.GroupBaseDN = "ou=groups,dc=example,dc=edu"
+	cfg.RoleMappings = []config.RoleMapping{{Group: "cn=registrar,ou=groups,dc=example,dc=edu", Role: "admin"}}
+	verifier, userRepo, identityRepo := newTestLDAPVerifier(t, cfg)
+	ctx := context.Background()
+
+	user := &entities.User{ID: "user-2", Username: "alan", Role: "user", IsActive: true}
+	identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "alan").
+		Return(&entities.UserIdentity{ID: "identity-2", UserID: "user-2", Provider: LDAPIdentityProvider, Subject: "alan"}, nil)
+	identityRepo.On("UpdateIdentity", ctx, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+	userRepo.On("GetByID", ctx, "user-2").Return(user, nil)
+	userRepo.On("Update", ctx, user).Return(nil)
+
+	result, err := verifier.Verify(ctx, "alan", "enigma")
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", result.ID)
+	assert.Equal(t, "admin", user.Role)
+
+	// Groups were searched as the service account after the user's bind
+	binds := server.bindLog()
+	require.Len(t, binds, 3)
+	assert.Equal(t, ldapServiceAccount.dn, binds[2].dn)
+}
+
+func TestLDAPVerifier_Refusals(t *testing.T) {
+	server := newLDAPTestServer(t, ldapServiceAccount, ldapAlan)
+	ctx := context.Background()
+
+	t.Run("no mapped group", func(t *testing.T) {
+		cfg := newTestLDAPConfig(server)
+		cfg.DefaultRole = ""
+		verifier, _, _ := newTestLDAPVerifier(t, cfg)
+		_, err := verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPAccessDenied)
+	})
+
+	t.Run("local account with the same username", func(t *testing.T) {
+		verifier, userRepo, identityRepo := newTestLDAPVerifier(t, newTestLDAPConfig(server))
+		identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "alan").Return(nil, nil)
+		userRepo.On("GetByUsername", ctx, "alan").Return(&entities.User{ID: "local-alan", Username: "alan"}, nil)
+		_, err := verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPAccountConflict)
+		identityRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("local account linked when allowed", func(t *testing.T) {
+		cfg := newTestLDAPConfig(server)
+		cfg.LinkExisting = true
+		verifier, userRepo, identityRepo := newTestLDAPVerifier(t, cfg)
+		identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "alan").Return(nil, nil)
+		userRepo.On("GetByUsername", ctx, "alan").Return(&entities.User{ID: "local-alan", Username: "alan", Role: "user"}, nil)
+		identityRepo.On("CreateIdentity", ctx, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+		user, err := verifier.Verify(ctx, "alan", "enigma")
+		require.NoError(t, err)
+		assert.Equal(t, "local-alan", user.ID)
+	})
+
+	t.Run("unknown user without provisioning", func(t *testing.T) {
+		cfg := newTestLDAPConfig(server)
+		cfg.AutoProvision = false
+		verifier, userRepo, identityRepo := newTestLDAPVerifier(t, cfg)
+		identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "alan").Return(nil, nil)
+		userRepo.On("GetByUsername", ctx, "alan").Return(nil, nil)
+		_, err := verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPAccessDenied)
+	})
+
+	t.Run("wrong service account password", func(t *testing.T) {
+		cfg := newTestLDAPConfig(server)
+		cfg.BindPassword = "stale"
+		verifier, _, _ := newTestLDAPVerifier(t, cfg)
+		_, err := verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPUnavailable)
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		cfg := newTestLDAPConfig(server)
+		cfg.CACertFile = ""
+		verifier, _, _ := newTestLDAPVerifier(t, cfg)
+		_, err := verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPUnavailable)
+		for _, bind := range server.bindLog() {
+			assert.True(t, bind.tls)
+		}
+	})
+
+	t.Run("server down", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		cfg := newTestLDAPConfig(server)
+		cfg.URL = "ldap://" + address
+		verifier, _, _ := newTestLDAPVerifier(t, cfg)
+		_, err = verifier.Verify(ctx, "alan", "enigma")
+		assert.ErrorIs(t, err, ErrLDAPUnavailable)
+	})
+}
+
+func TestAuthService_LoginWithDirectory(t *testing.T) {
+	server := newLDAPTestServer(t, ldapServiceAccount, ldapAlan)
+	verifier, userRepo, identityRepo := newTestLDAPVerifier(t, newTestLDAPConfig(server))
+	sessionRepo := new(MockSessionRepository)
+	authService := NewAuthService(userRepo, sessionRepo, "test-secret")
+	authService.AddCredentialVerifier(verifier)
+	ctx := context.Background()
+
+	// The provisioned account has no local password, so the local check passes it on
+	user := &entities.User{ID: "user-3", Username: "alan", Role: "user", IsActive: true}
+	userRepo.On("GetByUsername", ctx, "alan").Return(user, nil)
+	identityRepo.On("GetIdentity", ctx, LDAPIdentityProvider, "alan").
+		Return(&entities.UserIdentity{ID: "identity-3", UserID: "user-3"}, nil)
+	identityRepo.On("UpdateIdentity", ctx, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+	userRepo.On("GetByID", ctx, "user-3").Return(user, nil)
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Session")).Return(nil)
+
+	response, err := authService.Login(ctx, LoginRequest{Username: "alan", Password: "enigma"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-3", response.User.ID)
+	assert.NotEmpty(t, response.Token)
+
+	_, err = authService.Login(ctx, LoginRequest{Username: "alan", Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestNewLDAPVerifier_Validation(t *testing.T) {
+	for _, cfg := range []config.LDAPConfig{
+		{BaseDN: "dc=example,dc=edu", UsernameAttribute: "uid"},
+		{URL: "ldap://ldap.example.edu", UsernameAttribute: "uid"},
+		{URL: "http://ldap.example.edu", BaseDN: "dc=example,dc=edu", UsernameAttribute: "uid"},
+		{URL: "ldaps://ldap.example.edu", BaseDN: "dc=example,dc=edu", UsernameAttribute: "uid", CACertFile: "/nonexistent/ca.pem"},
+	} {
+		_, err := NewLDAPVerifier(cfg, nil, nil)
+		assert.Error(t, err, "%+v", cfg)
+	}
+}
+
+func TestLDAPHelpers(t *testing.T) {
+	assert.Equal(t, []string{
+		"cn=Signature Admins,ou=groups,dc=example,dc=edu", "Signature Admins",
+		"not a dn",
+	}, ldapGroupNames([]string{"cn=Signature Admins,ou=groups,dc=example,dc=edu", "not a dn"}))
+
+	assert.Equal(t, "6f1c2b8e-entry-uuid", ldapIdentifier([]byte("6f1c2b8e-entry-uuid")))
+	assert.Equal(t, "00ff10", ldapIdentifier([]byte{0x00, 0xff, 0x10}))
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrLetterNumberSchemeNotFound = errors.New("letter number scheme not found")
+	ErrLetterNumberSchemeExists   = errors.New("letter number scheme already exists")
+	ErrInvalidLetterNumberScheme  = errors.New("invalid letter number scheme")
+	ErrLetterNumberConflict       = errors.New("give either a letter number or a letter number scheme, not both")
+	ErrLetterNumberRequired       = errors.New("letter number is required when the organization has no default letter number scheme")
+	// Typed numbers in a scheme's format could take a number it allocates later
+	ErrLetterNumberReserved = errors.New("letter number follows a letter number scheme and can only be allocated by it")
+)
+
+const (
+	defaultLetterNumberPadding = 3
+	maxLetterNumberPadding     = 10
+	// maxLetterNumberLength matches the longest letter number accepted when signing
+	maxLetterNumberLength = 50
+)
+
+var (
+	letterNumberPlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+	letterNumberSchemeCode  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+	romanMonths             = [...]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+)
+
+// LetterNumberSchemeRequest describes a new scheme or the settings of an existing one
+type LetterNumberSchemeRequest struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Pattern    string `json:"pattern"`
+	Department string `json:"department"`
+	// Padding is the minimum number of digits of {seq}; nil keeps it or uses 3
+	Padding   *int `json:"padding,omitempty"`
+	IsDefault bool `json:"is_default"`
+	// IsActive is only read on update; inactive schemes allocate no numbers
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// LetterNumberService manages organizations' letter numbering schemes
+type LetterNumberService struct {
+	repo repositories.LetterNumberRepository
+	orgs *OrganizationService
+	now  func() time.Time
+}
+
+// NewLetterNumberService creates a new letter number service; organization
+// administrators manage the schemes and members may read them
+func NewLetterNumberService(repo repositories.LetterNumberRepository, orgs *OrganizationService) *LetterNumberService {
+	return &LetterNumberService{
+		repo: repo,
+		orgs: orgs,
+		now:  time.Now,
+	}
+}
+
+// ListSchemes returns the organization's schemes to its members
+func (s *LetterNumberService) ListSchemes(ctx context.Context, actorID, organizationID string) ([]*entities.LetterNumberScheme, error) {
+	org, err := s.orgs.GetOrganizationForUser(ctx, actorID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	schemes, err := s.repo.ListSchemes(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list letter number schemes: %w", err)
+	}
+	return schemes, nil
+}
+
+// CreateScheme adds a numbering scheme to the organization
+func (s *LetterNumberService) CreateScheme(ctx context.Context, actorID, organizationID string, req *LetterNumberSchemeRequest) (*entities.LetterNumberScheme, error) {
+	org, err := s.orgs.requireAdmin(ctx, actorID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if !letterNumberSchemeCode.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must be 1-32 letters, digits, '-' or '_'", ErrInvalidLetterNumberScheme)
+	}
+	existing, err := s.repo.GetSchemeByCode(ctx, org.ID, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get letter number scheme: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrLetterNumberSchemeExists
+	}
+
+	now := s.now()
+	scheme := &entities.LetterNumberScheme{
+		OrganizationID: org.ID,
+		Code:           code,
+		Padding:        defaultLetterNumberPadding,
+		IsActive:       true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := applyLetterNumberScheme(scheme, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateScheme(ctx, scheme); err != nil {
+		return nil, fmt.Errorf("failed to create letter number scheme: %w", err)
+	}
+	return scheme, nil
+}
+
+// UpdateScheme replaces a scheme's settings; the code cannot change. Numbers
+// already allocated keep their format and the sequence carries on.
+func (s *LetterNumberService) UpdateScheme(ctx context.Context, actorID, organizationID, schemeID string, req *LetterNumberSchemeRequest) (*entities.LetterNumberScheme, error) {
+	org, err := s.orgs.requireAdmin(ctx, actorID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := s.getScheme(ctx, org.ID, schemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyLetterNumberScheme(scheme, req); err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil {
+		scheme.IsActive = *req.IsActive
+	}
+	// An inactive scheme cannot number documents by default
+	if !scheme.IsActive {
+		scheme.IsDefault = false
+	}
+	scheme.UpdatedAt = s.now()
+
+	if err := s.repo.UpdateScheme(ctx, scheme); err != nil {
+		return nil, fmt.Errorf("failed to update letter number scheme: %w", err)
+	}
+	return scheme, nil
+}
+
+// NextLetterNumber shows members the number the scheme will allocate next. It
+// reserves nothing; a concurrent signing may take it first.
+func (s *LetterNumberService) NextLetterNumber(ctx context.Context, actorID, organizationID, schemeID string) (string, error) {
+	org, err := s.orgs.GetOrganizationForUser(ctx, actorID, organizationID)
+	if err != nil {
+		return "", err
+	}
+	scheme, err := s.getScheme(ctx, org.ID, schemeID)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	last, err := s.repo.GetCounter(ctx, scheme.ID, now.Year())
+	if err != nil {
+		return "", fmt.Errorf("failed to get letter number counter: %w", err)
+	}
+	return FormatLetterNumber(scheme, last+1, now), nil
+}
+
+// SchemeFor returns the active scheme with the code that numbers the
+// organization's documents. Without a code it returns the organization's
+// default scheme, or nil when it has none.
+func (s *LetterNumberService) SchemeFor(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error) {
+	if code == "" {
+		scheme, err := s.repo.GetDefaultScheme(ctx, organizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get letter number scheme: %w", err)
+		}
+		return scheme, nil
+	}
+
+	scheme, err := s.repo.GetSchemeByCode(ctx, organizationID, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get letter number scheme: %w", err)
+	}
+	if scheme == nil || !scheme.IsActive {
+		return nil, ErrLetterNumberSchemeNotFound
+	}
+	return scheme, nil
+}
+
+// SchemeMatching returns a scheme of the organization, active or not, whose
+// format the letter number follows, or nil when it follows none
+func (s *LetterNumberService) SchemeMatching(ctx context.Context, organizationID, letterNumber string) (*entities.LetterNumberScheme, error) {
+	schemes, err := s.repo.ListSchemes(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list letter number schemes: %w", err)
+	}
+	for _, scheme := range schemes {
+		if letterNumberMatches(scheme, letterNumber) {
+			return scheme, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *LetterNumberService) getScheme(ctx context.Context, organizationID, schemeID string) (*entities.LetterNumberScheme, error) {
+	scheme, err := s.repo.GetScheme(ctx, organizationID, schemeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get letter number scheme: %w", err)
+	}
+	if scheme == nil {
+		return nil, ErrLetterNumberSchemeNotFound
+	}
+	return scheme, nil
+}
+
+// applyLetterNumberScheme validates the request and copies it onto the scheme
+func applyLetterNumberScheme(scheme *entities.LetterNumberScheme, req *LetterNumberSchemeRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidLetterNumberScheme)
+	}
+	padding := scheme.Padding
+	if req.Padding != nil {
+		padding = *req.Padding
+	}
+	if padding < 1 || padding > maxLetterNumberPadding {
+		return fmt.Errorf("%w: padding must be between 1 and %d", ErrInvalidLetterNumberScheme, maxLetterNumberPadding)
+	}
+
+	candidate := *scheme
+	candidate.Name = name
+	candidate.Pattern = strings.TrimSpace(req.Pattern)
+	candidate.Department = strings.TrimSpace(req.Department)
+	candidate.Padding = padding
+	candidate.IsDefault = req.IsDefault
+	if err := validateLetterNumberPattern(&candidate); err != nil {
+		return err
+	}
+
+	*scheme = candidate
+	return nil
+}
+
+// validateLetterNumberPattern checks that the pattern only uses known
+// placeholders, restarts with each year and stays within the letter number length
+func validateLetterNumberPattern(scheme *entities.LetterNumberScheme) error {
+	pattern := scheme.Pattern
+	if pattern == "" {
+		return fmt.Errorf("%w: pattern is required", ErrInvalidLetterNumberScheme)
+	}
+
+	placeholders := make(map[string]bool)
+	for _, match := range letterNumberPlaceholder.FindAllStringSubmatch(pattern, -1) {
+		switch match[1] {
+		case "seq", "dept", "month", "roman_month", "year", "yy":
+			placeholders[match[1]] = true
+		default:
+			return fmt.Errorf("%w: unknown placeholder {%s}", ErrInvalidLetterNumberScheme, match[1])
+		}
+	}
+	if strings.ContainsAny(letterNumberPlaceholder.ReplaceAllString(pattern, ""), "{}") {
+		return fmt.Errorf("%w: pattern has an unterminated placeholder", ErrInvalidLetterNumberScheme)
+	}
+	if !placeholders["seq"] {
+		return fmt.Errorf("%w: pattern must contain {seq}", ErrInvalidLetterNumberScheme)
+	}
+	// The sequence restarts every year, so the year keeps numbers unique
+	if !placeholders["year"] && !placeholders["yy"] {
+		return fmt.Errorf("%w: pattern must contain {year} or {yy}", ErrInvalidLetterNumberScheme)
+	}
+	if placeholders["dept"] && scheme.Department == "" {
+		return fmt.Errorf("%w: department is required for {dept}", ErrInvalidLetterNumberScheme)
+	}
+
+	// The longest number a year can produce: a million letters in August
+	longest := FormatLetterNumber(scheme, 999999, time.Date(2099, time.August, 1, 0, 0, 0, 0, time.UTC))
+	if len(longest) > maxLetterNumberLength {
+		return fmt.Errorf("%w: numbers would be longer than %d characters", ErrInvalidLetterNumberScheme, maxLetterNumberLength)
+	}
+	return nil
+}
+
+// FormatLetterNumber renders the scheme's pattern for the sequence value and date
+func FormatLetterNumber(scheme *entities.LetterNumberScheme, seq int64, at time.Time) string {
+	return letterNumberPlaceholder.ReplaceAllStringFunc(scheme.Pattern, func(placeholder string) string {
+		switch placeholder[1 : len(placeholder)-1] {
+		case "seq":
+			return fmt.Sprintf("%0*d", scheme.Padding, seq)
+		case "dept":
+			return scheme.Department
+		case "month":
+			return fmt.Sprintf("%02d", int(at.Month()))
+		case "roman_month":
+			return romanMonths[at.Month()-1]
+		case "year":
+			return strconv.Itoa(at.Year())
+		case "yy":
+			return fmt.Sprintf("%02d", at.Year()%100)
+		}
+		return placeholder
+	})
+}
+
+// letterNumberMatches reports whether FormatLetterNumber could have produced
+// the letter number from the scheme for some sequence value and date
+func letterNumberMatches(scheme *entities.LetterNumberScheme, letterNumber string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range letterNumberPlaceholder.FindAllStringSubmatchIndex(scheme.Pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(scheme.Pattern[last:loc[0]]))
+		switch scheme.Pattern[loc[2]:loc[3]] {
+		case "seq":
+			fmt.Fprintf(&expr, `\d{%d,}`, scheme.Padding)
+		case "dept":
+			expr.WriteString(regexp.QuoteMeta(scheme.Department))
+		case "month":
+			expr.WriteString(`(0[1-9]|1[0-2])`)
+		case "roman_month":
+			expr.WriteString("(" + strings.Join(romanMonths[:], "|") + ")")
+		case "year":
+			expr.WriteString(`\d{4}`)
+		case "yy":
+			expr.WriteString(`\d{2}`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(scheme.Pattern[loc[0]:loc[1]]))
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(scheme.Pattern[last:]))
+	expr.WriteString("$")
+
+	matcher, err := regexp.Compile(expr.String())
+	return err == nil && matcher.MatchString(letterNumber)
+}
+
+// signedDigest is what a document's signature covers: the PDF hash, bound to
+// the letter number when a scheme allocated it
+func signedDigest(documentHash []byte, document *entities.Document) []byte {
+	if document.LetterNumberSchemeID == nil || document.LetterNumber == nil {
+		return documentHash
+	}
+	digest := sha256.New()
+	digest.Write(documentHash)
+	digest.Write([]byte{0})
+	digest.Write([]byte(*document.LetterNumber))
+	return digest.Sum(nil)
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockLetterNumberRepository struct {
+	mock.Mock
+}
+
+func (m *MockLetterNumberRepository) CreateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error {
+	args := m.Called(ctx, scheme)
+	return args.Error(0)
+}
+
+func (m *MockLetterNumberRepository) UpdateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error {
+	args := m.Called(ctx, scheme)
+	return args.Error(0)
+}
+
+func (m *MockLetterNumberRepository) GetScheme(ctx context.Context, organizationID, id string) (*entities.LetterNumberScheme, error) {
+	args := m.Called(ctx, organizationID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LetterNumberScheme), args.Error(1)
+}
+
+func (m *MockLetterNumberRepository) GetSchemeByCode(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error) {
+	args := m.Called(ctx, organizationID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LetterNumberScheme), args.Error(1)
+}
+
+func (m *MockLetterNumberRepository) GetDefaultScheme(ctx context.Context, organizationID string) (*entities.LetterNumberScheme, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LetterNumberScheme), args.Error(1)
+}
+
+func (m *MockLetterNumberRepository) ListSchemes(ctx context.Context, organizationID string) ([]*entities.LetterNumberScheme, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]*entities.LetterNumberScheme), args.Error(1)
+}
+
+func (m *MockLetterNumberRepository) GetCounter(ctx context.Context, schemeID string, year int) (int64, error) {
+	args := m.Called(ctx, schemeID, year)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// stubLetterNumberSchemes serves the organization's schemes by code; "" is the default
+type stubLetterNumberSchemes map[string]*entities.LetterNumberScheme
+
+func (s stubLetterNumberSchemes) SchemeFor(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error) {
+	scheme, ok := s[code]
+	if !ok && code != "" {
+		return nil, ErrLetterNumberSchemeNotFound
+	}
+	return scheme, nil
+}
+
+func (s stubLetterNumberSchemes) SchemeMatching(ctx context.Context, organizationID, letterNumber string) (*entities.LetterNumberScheme, error) {
+	for _, scheme := range s {
+		if letterNumberMatches(scheme, letterNumber) {
+			return scheme, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestFormatLetterNumber(t *testing.T) {
+	scheme := &entities.LetterNumberScheme{Pattern: "{seq}/{dept}/{roman_month}/{year}", Department: "PHY", Padding: 3}
+	at := time.Date(2026, time.May, 4, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "007/PHY/V/2026", FormatLetterNumber(scheme, 7, at))
+	assert.Equal(t, "1234/PHY/V/2026", FormatLetterNumber(scheme, 1234, at))
+
+	scheme = &entities.LetterNumberScheme{Pattern: "OUT-{yy}{month}-{seq}", Padding: 5}
+	assert.Equal(t, "OUT-2612-00042", FormatLetterNumber(scheme, 42, time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestLetterNumberMatches(t *testing.T) {
+	scheme := &entities.LetterNumberScheme{Pattern: "{seq}/{dept}/{roman_month}/{year}", Department: "PHY", Padding: 3}
+	assert.True(t, letterNumberMatches(scheme, "007/PHY/V/2026"))
+	assert.True(t, letterNumberMatches(scheme, "1234/PHY/XII/2026"))
+	assert.False(t, letterNumberMatches(scheme, "07/PHY/V/2026"))
+	assert.False(t, letterNumberMatches(scheme, "007/CHE/V/2026"))
+	assert.False(t, letterNumberMatches(scheme, "007/PHY/XIII/2026"))
+	assert.False(t, letterNumberMatches(scheme, "007/PHY/V/2026/draft"))
+
+	// Pattern text is matched literally
+	scheme = &entities.LetterNumberScheme{Pattern: "OUT.{yy}{month}-{seq}", Padding: 5}
+	assert.True(t, letterNumberMatches(scheme, "OUT.2612-00042"))
+	assert.False(t, letterNumberMatches(scheme, "OUTX2612-00042"))
+	assert.False(t, letterNumberMatches(scheme, "OUT.2613-00042"))
+}
+
+func TestValidateLetterNumberPattern(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		department string
+		valid      bool
+	}{
+		{name: "valid", pattern: "{seq}/{dept}/{roman_month}/{year}", department: "PHY", valid: true},
+		{name: "two digit year", pattern: "{seq}-{yy}", valid: true},
+		{name: "empty", pattern: ""},
+		{name: "unknown placeholder", pattern: "{seq}/{day}/{year}"},
+		{name: "unterminated placeholder", pattern: "{seq}/{year"},
+		{name: "no sequence", pattern: "{dept}/{year}", department: "PHY"},
+		{name: "no year", pattern: "{seq}/{month}"},
+		{name: "department missing", pattern: "{seq}/{dept}/{year}"},
+		{name: "too long", pattern: "{seq}/FACULTY-OF-MATHEMATICS-AND-NATURAL-SCIENCES/{year}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLetterNumberPattern(&entities.LetterNumberScheme{Pattern: tt.pattern, Department: tt.department, Padding: 3})
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidLetterNumberScheme)
+			}
+		})
+	}
+}
+
+func newTestLetterNumberService(t *testing.T) (*LetterNumberService, *MockLetterNumberRepository) {
+	orgs, orgRepo, _ := newTestOrganizationService(t)
+	orgRepo.On("GetByID", mock.Anything, "org-1").Return(&entities.Organization{ID: "org-1", IsActive: true}, nil)
+	orgRepo.On("GetMember", mock.Anything, "org-1", "admin").Return(&entities.OrganizationMember{UserID: "admin", Role: entities.OrganizationRoleAdmin}, nil)
+	orgRepo.On("GetMember", mock.Anything, "org-1", "member").Return(&entities.OrganizationMember{UserID: "member", Role: entities.OrganizationRoleMember}, nil)
+	orgRepo.On("GetMember", mock.Anything, "org-1", "outsider").Return(nil, nil)
+
+	repo := new(MockLetterNumberRepository)
+	service := NewLetterNumberService(repo, orgs)
+	service.now = func() time.Time { return time.Date(2026, time.May, 4, 9, 0, 0, 0, time.UTC) }
+	return service, repo
+}
+
+func TestLetterNumberService_CreateScheme(t *testing.T) {
+	service, repo := newTestLetterNumberService(t)
+	ctx := context.Background()
+	req := &LetterNumberSchemeRequest{
+		Code:       "PHY-OUT",
+		Name:       "Physics outgoing",
+		Pattern:    "{seq}/{dept}/{roman_month}/{year}",
+		Department: "PHY",
+		IsDefault:  true,
+	}
+
+	repo.On("GetSchemeByCode", ctx, "org-1", "PHY-OUT").Return(nil, nil).Once()
+	repo.On("CreateScheme", ctx, mock.AnythingOfType("*entities.LetterNumberScheme")).Return(nil)
+	scheme, err := service.CreateScheme(ctx, "admin", "org-1", req)
+	require.NoError(t, err)
+	assert.Equal(t, "org-1", scheme.OrganizationID)
+	assert.Equal(t, 3, scheme.Padding)
+	assert.True(t, scheme.IsDefault)
+	assert.True(t, scheme.IsActive)
+
+	// Only administrators manage schemes
+	_, err = service.CreateScheme(ctx, "member", "org-1", req)
+	assert.ErrorIs(t, err, ErrOrganizationAccess)
+
+	repo.On("GetSchemeByCode", ctx, "org-1", "PHY-OUT").Return(scheme, nil)
+	_, err = service.CreateScheme(ctx, "admin", "org-1", req)
+	assert.ErrorIs(t, err, ErrLetterNumberSchemeExists)
+
+	_, err = service.CreateScheme(ctx, "admin", "org-1", &LetterNumberSchemeRequest{Code: "PHY OUT", Name: "Physics", Pattern: "{seq}/{year}"})
+	assert.ErrorIs(t, err, ErrInvalidLetterNumberScheme)
+}
+
+func TestLetterNumberService_UpdateScheme(t *testing.T) {
+	service, repo := newTestLetterNumberService(t)
+	ctx := context.Background()
+	scheme := &entities.LetterNumberScheme{ID: "scheme-1", OrganizationID: "org-1", Code: "OUT", Name: "Outgoing", Pattern: "{seq}/{year}", Padding: 3, IsDefault: true, IsActive: true}
+	repo.On("GetScheme", ctx, "org-1", "scheme-1").Return(scheme, nil)
+	repo.On("UpdateScheme", ctx, scheme).Return(nil)
+
+	inactive := false
+	updated, err := service.UpdateScheme(ctx, "admin", "org-1", "scheme-1", &LetterNumberSchemeRequest{
+		Name: "Outgoing", Pattern: "{seq}/{yy}", IsDefault: true, IsActive: &inactive,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "OUT", updated.Code)
+	assert.Equal(t, "{seq}/{yy}", updated.Pattern)
+	assert.False(t, updated.IsActive)
+	assert.False(t, updated.IsDefault, "an inactive scheme is not the default")
+
+	repo.On("GetScheme", ctx, "org-1", "missing").Return(nil, nil)
+	_, err = service.UpdateScheme(ctx, "admin", "org-1", "missing", &LetterNumberSchemeRequest{Name: "Outgoing", Pattern: "{seq}/{year}"})
+	assert.ErrorIs(t, err, ErrLetterNumberSchemeNotFound)
+}
+
+func TestLetterNumberService_NextLetterNumber(t *testing.T) {
+	service, repo := newTestLetterNumberService(t)
+	ctx := context.Background()
+	repo.On("GetScheme", ctx, "org-1", "scheme-1").Return(&entities.LetterNumberScheme{
+		ID: "scheme-1", Pattern: "{seq}/{dept}/{roman_month}/{year}", Department: "PHY", Padding: 3,
+	}, nil)
+	repo.On("GetCounter", ctx, "scheme-1", 2026).Return(int64(6), nil)
+
+	letterNumber, err := service.NextLetterNumber(ctx, "member", "org-1", "scheme-1")
+	require.NoError(t, err)
+	assert.Equal(t, "007/PHY/V/2026", letterNumber)
+
+	_, err = service.NextLetterNumber(ctx, "outsider", "org-1", "scheme-1")
+	assert.Error(t, err)
+}
+
+func TestLetterNumberService_SchemeMatching(t *testing.T) {
+	service, repo := newTestLetterNumberService(t)
+	ctx := context.Background()
+	retired := &entities.LetterNumberScheme{ID: "scheme-2", Code: "OLD", Pattern: "OLD-{seq}/{year}", Padding: 3}
+	repo.On("ListSchemes", ctx, "org-1").Return([]*entities.LetterNumberScheme{
+		{ID: "scheme-1", Code: "OUT", Pattern: "{seq}/{dept}/{year}", Department: "PHY", Padding: 3, IsActive: true},
+		retired,
+	}, nil)
+
+	// Numbers of inactive schemes were allocated too
+	scheme, err := service.SchemeMatching(ctx, "org-1", "OLD-118/2025")
+	require.NoError(t, err)
+	assert.Same(t, retired, scheme)
+
+	scheme, err = service.SchemeMatching(ctx, "org-1", "12/ABC/2026")
+	require.NoError(t, err)
+	assert.Nil(t, scheme)
+}
+
+func TestLetterNumberService_SchemeFor(t *testing.T) {
+	service, repo := newTestLetterNumberService(t)
+	ctx := context.Background()
+	active := &entities.LetterNumberScheme{ID: "scheme-1", Code: "OUT", IsActive: true}
+	repo.On("GetDefaultScheme", ctx, "org-1").Return(active, nil)
+	repo.On("GetSchemeByCode", ctx, "org-1", "OUT").Return(active, nil)
+	repo.On("GetSchemeByCode", ctx, "org-1", "OLD").Return(&entities.LetterNumberScheme{ID: "scheme-2", Code: "OLD"}, nil)
+	repo.On("GetSchemeByCode", ctx, "org-1", "NONE").Return(nil, nil)
+
+	scheme, err := service.SchemeFor(ctx, "org-1", "")
+	require.NoError(t, err)
+	assert.Same(t, active, scheme)
+
+	scheme, err = service.SchemeFor(ctx, "org-1", "OUT")
+	require.NoError(t, err)
+	assert.Same(t, active, scheme)
+
+	_, err = service.SchemeFor(ctx, "org-1", "OLD")
+	assert.ErrorIs(t, err, ErrLetterNumberSchemeNotFound)
+	_, err = service.SchemeFor(ctx, "org-1", "NONE")
+	assert.ErrorIs(t, err, ErrLetterNumberSchemeNotFound)
+}
+
+// newNumberedTestService signs for org-1, whose default scheme numbers its documents
+func newNumberedTestService(t *testing.T) (*DocumentService, *MockDocumentRepository, *MockSignatureService) {
+	mockDocRepo := new(MockDocumentRepository)
+	mockSigService := new(MockSignatureService)
+	mockPDFService := new(MockPDFService)
+
+	mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+	mockPDFService.On("CalculateHash", mock.Anything).Return([]byte("test-hash"), nil)
+	mockPDFService.On("ExtractContent", mock.Anything).Return(nil, assert.AnError)
+	mockPDFService.On("GenerateQRCodeWithCenterLabel", mock.Anything, mock.Anything, 256).Return([]byte("qr-code-image"), nil)
+	mockPDFService.On("InjectQRCode", mock.Anything, mock.Anything, mock.Anything).Return([]byte("modified-pdf"), nil)
+	mockSigService.On("SignDocument", mock.Anything).Return(&crypto.SignatureData{
+		Signature: []byte("test-signature"),
+		Hash:      []byte("signed-digest"),
+		Algorithm: "RSA-PSS-SHA256",
+	}, nil)
+	mockDocRepo.On("GetByHash", mock.Anything, mock.Anything, mock.Anything).Return((*entities.Document)(nil), nil)
+	mockDocRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+	service := NewDocumentService(mockDocRepo, mockSigService, mockPDFService, &config.Config{BaseURL: "http://localhost:3000", StorageDir: t.TempDir()})
+	service.SetOrganizations(&stubOrganizationResolver{org: &entities.Organization{ID: "org-1"}, signer: mockSigService, keyID: "org-key-1"})
+	service.SetLetterNumbers(stubLetterNumberSchemes{
+		"":    {ID: "scheme-1", Pattern: "{seq}/{dept}/{year}", Department: "PHY", Padding: 3},
+		"OUT": {ID: "scheme-2", Pattern: "OUT-{seq}/{year}", Padding: 4},
+	})
+	return service, mockDocRepo, mockSigService
+}
+
+func TestDocumentService_SignDocument_LetterNumberScheme(t *testing.T) {
+	request := func(letterNumber, scheme string) *SignDocumentRequest {
+		return &SignDocumentRequest{
+			Filename:           "letter.pdf",
+			Issuer:             "Registrar",
+			LetterNumber:       letterNumber,
+			LetterNumberScheme: scheme,
+			PDFData:            []byte("%PDF-1.4 test content"),
+			UserID:             "user-123",
+			OrganizationID:     "org-1",
+		}
+	}
+
+	t.Run("default scheme allocates the number", func(t *testing.T) {
+		service, mockDocRepo, mockSigService := newNumberedTestService(t)
+		year := time.Now().Year()
+		mockDocRepo.On("CreateNumbered", mock.Anything, mock.AnythingOfType("*entities.Document"), "scheme-1", year).Return(int64(7), nil)
+
+		response, err := service.SignDocument(context.Background(), request("", ""))
+		require.NoError(t, err)
+		document := response.Document
+		require.NotNil(t, document.LetterNumber)
+		assert.Equal(t, "007/PHY/"+time.Now().Format("2006"), *document.LetterNumber)
+		require.NotNil(t, document.LetterNumberSchemeID)
+		assert.Equal(t, "scheme-1", *document.LetterNumberSchemeID)
+
+		// The signature covers the allocated number
+		mockSigService.AssertCalled(t, "SignDocument", signedDigest([]byte("test-hash"), document))
+		mockSigService.AssertNotCalled(t, "SignDocument", []byte("test-hash"))
+
+		var qrCodeData map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(document.QRCodeData), &qrCodeData))
+		assert.Equal(t, *document.LetterNumber, qrCodeData["letter_number"])
+		mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("named scheme", func(t *testing.T) {
+		service, mockDocRepo, _ := newNumberedTestService(t)
+		mockDocRepo.On("CreateNumbered", mock.Anything, mock.AnythingOfType("*entities.Document"), "scheme-2", mock.Anything).Return(int64(12), nil)
+
+		response, err := service.SignDocument(context.Background(), request("", "OUT"))
+		require.NoError(t, err)
+		assert.Equal(t, "OUT-0012/"+time.Now().Format("2006"), *response.Document.LetterNumber)
+	})
+
+	t.Run("typed number is signed as before", func(t *testing.T) {
+		service, mockDocRepo, mockSigService := newNumberedTestService(t)
+		mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+
+		response, err := service.SignDocument(context.Background(), request("12/ABC/2026", ""))
+		require.NoError(t, err)
+		assert.Equal(t, "12/ABC/2026", *response.Document.LetterNumber)
+		assert.Nil(t, response.Document.LetterNumberSchemeID)
+		mockSigService.AssertCalled(t, "SignDocument", []byte("test-hash"))
+		mockDocRepo.AssertNotCalled(t, "CreateNumbered", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("typed number in a scheme's format", func(t *testing.T) {
+		service, mockDocRepo, _ := newNumberedTestService(t)
+		_, err := service.SignDocument(context.Background(), request("0042/PHY/2026", ""))
+		assert.ErrorIs(t, err, ErrLetterNumberReserved)
+		mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("number and scheme conflict", func(t *testing.T) {
+		service, _, _ := newNumberedTestService(t)
+		_, err := service.SignDocument(context.Background(), request("12/ABC/2026", "OUT"))
+		assert.ErrorIs(t, err, ErrLetterNumberConflict)
+	})
+
+	t.Run("unknown scheme", func(t *testing.T) {
+		service, _, _ := newNumberedTestService(t)
+		_, err := service.SignDocument(context.Background(), request("", "MISSING"))
+		assert.ErrorIs(t, err, ErrLetterNumberSchemeNotFound)
+	})
+
+	t.Run("no default scheme", func(t *testing.T) {
+		service, _, _ := newNumberedTestService(t)
+		service.SetLetterNumbers(stubLetterNumberSchemes{})
+		_, err := service.SignDocument(context.Background(), request("", ""))
+		assert.ErrorIs(t, err, ErrLetterNumberRequired)
+	})
+}
+
+func TestCoversLetterNumber(t *testing.T) {
+	schemeID := "scheme-1"
+	letterNumber := "007/PHY/V/2026"
+	document := &entities.Document{
+		DocumentHash:         base64.StdEncoding.EncodeToString([]byte("test-hash")),
+		LetterNumber:         &letterNumber,
+		LetterNumberSchemeID: &schemeID,
+	}
+	signatureData := &crypto.SignatureData{Hash: signedDigest([]byte("test-hash"), document)}
+	assert.True(t, coversLetterNumber(document, signatureData))
+
+	// A changed letter number no longer matches the signature
+	tampered := "008/PHY/V/2026"
+	document.LetterNumber = &tampered
+	assert.False(t, coversLetterNumber(document, signatureData))
+
+	// Typed letter numbers were never signed
+	assert.True(t, coversLetterNumber(&entities.Document{LetterNumber: &tampered}, &crypto.SignatureData{Hash: []byte("test-hash")}))
+}
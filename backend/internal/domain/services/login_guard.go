@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrAccountLocked         = errors.New("account temporarily locked after too many failed logins")
+	ErrLoginThrottled        = errors.New("too many failed login attempts, try again later")
+	ErrLoginThrottleNotFound = errors.New("login lockout not found")
+)
+
+const loginThrottleCleanupInterval = time.Hour
+
+// LoginAttemptError carries the throttle state back with a rejected login so the
+// client can be told when to retry and whether to show a CAPTCHA
+type LoginAttemptError struct {
+	Err             error
+	RetryAt         time.Time
+	CaptchaRequired bool
+	// Locked lists the throttles this attempt pushed into lockout
+	Locked []*entities.LoginThrottle
+}
+
+func (e *LoginAttemptError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginAttemptError) Unwrap() error {
+	return e.Err
+}
+
+// LoginGuard tracks failed logins per username and per IP address, slowing down
+// repeated failures and locking the account or address once a threshold is reached
+type LoginGuard struct {
+	throttleRepo repositories.LoginThrottleRepository
+	userRepo     repositories.UserRepository
+	config       *config.Config
+	now          func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLoginGuard(throttleRepo repositories.LoginThrottleRepository, userRepo repositories.UserRepository, cfg *config.Config) *LoginGuard {
+	return &LoginGuard{
+		throttleRepo: throttleRepo,
+		userRepo:     userRepo,
+		config:       cfg,
+		now:          time.Now,
+	}
+}
+
+// Check rejects a login from a locked account or address, or one made before the
+// delay earned by earlier failures has passed
+func (g *LoginGuard) Check(ctx context.Context, username, ipAddress string) error {
+	now := g.now()
+	account, address, err := g.load(ctx, username, ipAddress)
+	if err != nil {
+		// Login stays available if the throttle store is unreachable
+		fmt.Printf("Warning: Failed to check login throttle: %v\n", err)
+		return nil
+	}
+
+	captcha := g.captchaRequired(now, account, address)
+	if account != nil && account.IsLocked(now) {
+		return &LoginAttemptError{Err: ErrAccountLocked, RetryAt: *account.LockedUntil, CaptchaRequired: captcha}
+	}
+	if address != nil && address.IsLocked(now) {
+		return &LoginAttemptError{Err: ErrLoginThrottled, RetryAt: *address.LockedUntil, CaptchaRequired: captcha}
+	}
+	if account != nil {
+		if retryAt := g.nextAttemptAt(now, account); now.Before(retryAt) {
+			return &LoginAttemptError{Err: ErrLoginThrottled, RetryAt: retryAt, CaptchaRequired: captcha}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login and returns the error to report for it
+func (g *LoginGuard) RecordFailure(ctx context.Context, username, ipAddress string) error {
+	now := g.now()
+	staleBefore := now.Add(-g.config.LoginFailureWindow)
+
+	account, err := g.throttleRepo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, normalizeUsername(username), now, staleBefore)
+	if err != nil {
+		fmt.Printf("Warning: Failed to record login failure: %v\n", err)
+		return ErrInvalidCredentials
+	}
+	var address *entities.LoginThrottle
+	if ipAddress != "" {
+		if address, err = g.throttleRepo.RecordFailure(ctx, entities.LoginThrottleScopeIP, ipAddress, now, staleBefore); err != nil {
+			fmt.Printf("Warning: Failed to record login failure: %v\n", err)
+		}
+	}
+
+	attemptErr := &LoginAttemptError{
+		Err:             ErrInvalidCredentials,
+		CaptchaRequired: g.captchaRequired(now, account, address),
+	}
+
+	if g.lock(ctx, now, account, g.config.LoginMaxFailures) {
+		attemptErr.Err = ErrAccountLocked
+		attemptErr.RetryAt = *account.LockedUntil
+		attemptErr.Locked = append(attemptErr.Locked, account)
+	} else if delay := g.delay(account.Failures); delay > 0 {
+		attemptErr.RetryAt = account.LastFailureAt.Add(delay)
+	}
+
+	if g.lock(ctx, now, address, g.config.LoginIPMaxFailures) {
+		attemptErr.Locked = append(attemptErr.Locked, address)
+		if attemptErr.RetryAt.Before(*address.LockedUntil) {
+			attemptErr.RetryAt = *address.LockedUntil
+		}
+	}
+
+	return attemptErr
+}
+
+// RecordSuccess forgets the failures counted against username. Failures from the
+// address are kept so one valid account cannot be used to reset guessing against others.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, username string) {
+	if err := g.throttleRepo.Clear(ctx, entities.LoginThrottleScopeUsername, normalizeUsername(username)); err != nil {
+		fmt.Printf("Warning: Failed to reset login throttle: %v\n", err)
+	}
+}
+
+// ListLocked returns the accounts and addresses currently locked out
+func (g *LoginGuard) ListLocked(ctx context.Context) ([]*entities.LoginThrottle, error) {
+	return g.throttleRepo.ListLocked(ctx, g.now())
+}
+
+// UnlockUser lifts the lockout on a user's account and clears its failure count
+func (g *LoginGuard) UnlockUser(ctx context.Context, userID string) (*entities.User, error) {
+	user, err := g.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := g.throttleRepo.Clear(ctx, entities.LoginThrottleScopeUsername, normalizeUsername(user.Username)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Unlock removes one lockout, for an account or an address, by its ID
+func (g *LoginGuard) Unlock(ctx context.Context, id string) (*entities.LoginThrottle, error) {
+	throttle, err := g.throttleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if throttle == nil {
+		return nil, ErrLoginThrottleNotFound
+	}
+
+	if err := g.throttleRepo.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return throttle, nil
+}
+
+// Start launches the background removal of stale failure counts
+func (g *LoginGuard) Start(ctx context.Context) {
+	ctx, g.cancel = context.WithCancel(ctx)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(loginThrottleCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := g.CleanupStale(ctx); err != nil {
+				fmt.Printf("Warning: Login throttle cleanup error: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the cleanup loop
+func (g *LoginGuard) Stop() {
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.wg.Wait()
+}
+
+// CleanupStale deletes failure counts that have aged out and are not locked
+func (g *LoginGuard) CleanupStale(ctx context.Context) (int64, error) {
+	return g.throttleRepo.DeleteStale(ctx, g.now().Add(-g.config.LoginFailureWindow))
+}
+
+func (g *LoginGuard) load(ctx context.Context, username, ipAddress string) (*entities.LoginThrottle, *entities.LoginThrottle, error) {
+	account, err := g.throttleRepo.Get(ctx, entities.LoginThrottleScopeUsername, normalizeUsername(username))
+	if err != nil {
+		return nil, nil, err
+	}
+	if ipAddress == "" {
+		return account, nil, nil
+	}
+	address, err := g.throttleRepo.Get(ctx, entities.LoginThrottleScopeIP, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, address, nil
+}
+
+// lock starts a lockout once throttle reaches maxFailures, reporting whether this call did
+func (g *LoginGuard) lock(ctx context.Context, now time.Time, throttle *entities.LoginThrottle, maxFailures int) bool {
+	if throttle == nil || maxFailures <= 0 || throttle.Failures < maxFailures {
+		return false
+	}
+
+	until := now.Add(g.config.LoginLockoutDuration)
+	locked, err := g.throttleRepo.Lock(ctx, throttle.ID, now, until)
+	if err != nil {
+		fmt.Printf("Warning: Failed to lock login throttle: %v\n", err)
+		return false
+	}
+	if locked {
+		throttle.LockedUntil = &until
+	}
+	return locked
+}
+
+// delay doubles with each failure, starting at the configured base
+func (g *LoginGuard) delay(failures int) time.Duration {
+	base := g.config.LoginDelayBase
+	if base <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := 1; i < failures && delay < g.config.LoginDelayMax; i++ {
+		delay *= 2
+	}
+	if g.config.LoginDelayMax > 0 && delay > g.config.LoginDelayMax {
+		delay = g.config.LoginDelayMax
+	}
+	return delay
+}
+
+func (g *LoginGuard) nextAttemptAt(now time.Time, throttle *entities.LoginThrottle) time.Time {
+	if !g.recent(now, throttle) {
+		return time.Time{}
+	}
+	return throttle.LastFailureAt.Add(g.delay(throttle.Failures))
+}
+
+func (g *LoginGuard) captchaRequired(now time.Time, throttles ...*entities.LoginThrottle) bool {
+	threshold := g.config.LoginCaptchaAfter
+	if threshold <= 0 {
+		return false
+	}
+	for _, throttle := range throttles {
+		if throttle != nil && g.recent(now, throttle) && throttle.Failures >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// recent reports whether the throttle's failures still count
+func (g *LoginGuard) recent(now time.Time, throttle *entities.LoginThrottle) bool {
+	if throttle.LockedUntil != nil && !now.Before(*throttle.LockedUntil) {
+		return false
+	}
+	return throttle.LastFailureAt.After(now.Add(-g.config.LoginFailureWindow))
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
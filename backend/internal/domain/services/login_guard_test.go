@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockLoginThrottleRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottleRepository) Get(ctx context.Context, scope, key string) (*entities.LoginThrottle, error) {
+	args := m.Called(ctx, scope, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) GetByID(ctx context.Context, id string) (*entities.LoginThrottle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) RecordFailure(ctx context.Context, scope, key string, now, staleBefore time.Time) (*entities.LoginThrottle, error) {
+	args := m.Called(ctx, scope, key, now, staleBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) Lock(ctx context.Context, id string, now, until time.Time) (bool, error) {
+	args := m.Called(ctx, id, now, until)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) ListLocked(ctx context.Context, now time.Time) ([]*entities.LoginThrottle, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]*entities.LoginThrottle), args.Error(1)
+}
+
+func (m *MockLoginThrottleRepository) Clear(ctx context.Context, scope, key string) error {
+	args := m.Called(ctx, scope, key)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottleRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottleRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var loginGuardNow = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestLoginGuard(throttleRepo *MockLoginThrottleRepository, userRepo *MockUserRepository) *LoginGuard {
+	guard := NewLoginGuard(throttleRepo, userRepo, &config.Config{
+		LoginMaxFailures:     5,
+		LoginIPMaxFailures:   20,
+		LoginFailureWindow:   15 * time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+		LoginDelayBase:       time.Second,
+		LoginDelayMax:        30 * time.Second,
+		LoginCaptchaAfter:    3,
+	})
+	guard.now = func() time.Time { return loginGuardNow }
+	return guard
+}
+
+func throttleWith(scope, key string, failures int) *entities.LoginThrottle {
+	return &entities.LoginThrottle{
+		ID:            scope + "-" + key,
+		Scope:         scope,
+		Key:           key,
+		Failures:      failures,
+		LastFailureAt: loginGuardNow,
+	}
+}
+
+func TestLoginGuard_RecordFailure(t *testing.T) {
+	tests := []struct {
+		name            string
+		accountFailures int
+		expectedErr     error
+		expectedRetryIn time.Duration
+		expectedCaptcha bool
+		expectLock      bool
+	}{
+		{name: "first failure", accountFailures: 1, expectedErr: ErrInvalidCredentials, expectedRetryIn: time.Second},
+		{name: "delay doubles", accountFailures: 3, expectedErr: ErrInvalidCredentials, expectedRetryIn: 4 * time.Second, expectedCaptcha: true},
+		{name: "threshold locks the account", accountFailures: 5, expectedErr: ErrAccountLocked, expectedRetryIn: 15 * time.Minute, expectedCaptcha: true, expectLock: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttleRepo := new(MockLoginThrottleRepository)
+			guard := newTestLoginGuard(throttleRepo, new(MockUserRepository))
+
+			account := throttleWith(entities.LoginThrottleScopeUsername, "alice", tt.accountFailures)
+			throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeUsername, "alice", loginGuardNow, loginGuardNow.Add(-15*time.Minute)).Return(account, nil)
+			throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeIP, "10.0.0.1", loginGuardNow, mock.Anything).
+				Return(throttleWith(entities.LoginThrottleScopeIP, "10.0.0.1", 1), nil)
+			if tt.expectLock {
+				throttleRepo.On("Lock", mock.Anything, account.ID, loginGuardNow, loginGuardNow.Add(15*time.Minute)).Return(true, nil)
+			}
+
+			err := guard.RecordFailure(context.Background(), " Alice ", "10.0.0.1")
+
+			var attemptErr *LoginAttemptError
+			require.True(t, errors.As(err, &attemptErr))
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, loginGuardNow.Add(tt.expectedRetryIn), attemptErr.RetryAt)
+			assert.Equal(t, tt.expectedCaptcha, attemptErr.CaptchaRequired)
+			if tt.expectLock {
+				assert.Equal(t, []*entities.LoginThrottle{account}, attemptErr.Locked)
+			} else {
+				assert.Empty(t, attemptErr.Locked)
+			}
+			throttleRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginGuard_RecordFailure_LocksAddress(t *testing.T) {
+	throttleRepo := new(MockLoginThrottleRepository)
+	guard := newTestLoginGuard(throttleRepo, new(MockUserRepository))
+
+	address := throttleWith(entities.LoginThrottleScopeIP, "10.0.0.1", 20)
+	throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeUsername, "bob", loginGuardNow, mock.Anything).
+		Return(throttleWith(entities.LoginThrottleScopeUsername, "bob", 1), nil)
+	throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeIP, "10.0.0.1", loginGuardNow, mock.Anything).Return(address, nil)
+	throttleRepo.On("Lock", mock.Anything, address.ID, loginGuardNow, mock.Anything).Return(true, nil)
+
+	err := guard.RecordFailure(context.Background(), "bob", "10.0.0.1")
+
+	var attemptErr *LoginAttemptError
+	require.True(t, errors.As(err, &attemptErr))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, []*entities.LoginThrottle{address}, attemptErr.Locked)
+	assert.Equal(t, loginGuardNow.Add(15*time.Minute), attemptErr.RetryAt)
+	assert.True(t, attemptErr.CaptchaRequired)
+}
+
+func TestLoginGuard_Check(t *testing.T) {
+	lockedUntil := loginGuardNow.Add(10 * time.Minute)
+	expiredLock := loginGuardNow.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		account     *entities.LoginThrottle
+		address     *entities.LoginThrottle
+		expectedErr error
+	}{
+		{name: "no failures"},
+		{
+			name:        "locked account",
+			account:     &entities.LoginThrottle{Failures: 5, LastFailureAt: loginGuardNow, LockedUntil: &lockedUntil},
+			expectedErr: ErrAccountLocked,
+		},
+		{
+			name:        "locked address",
+			address:     &entities.LoginThrottle{Failures: 20, LastFailureAt: loginGuardNow, LockedUntil: &lockedUntil},
+			expectedErr: ErrLoginThrottled,
+		},
+		{
+			name:        "retry before the delay has passed",
+			account:     &entities.LoginThrottle{Failures: 2, LastFailureAt: loginGuardNow.Add(-time.Second)},
+			expectedErr: ErrLoginThrottled,
+		},
+		{
+			name:    "retry after the delay",
+			account: &entities.LoginThrottle{Failures: 2, LastFailureAt: loginGuardNow.Add(-3 * time.Second)},
+		},
+		{
+			name:    "expired lockout",
+			account: &entities.LoginThrottle{Failures: 5, LastFailureAt: loginGuardNow.Add(-time.Minute), LockedUntil: &expiredLock},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttleRepo := new(MockLoginThrottleRepository)
+			guard := newTestLoginGuard(throttleRepo, new(MockUserRepository))
+			throttleRepo.On("Get", mock.Anything, entities.LoginThrottleScopeUsername, "alice").Return(tt.account, nil)
+			throttleRepo.On("Get", mock.Anything, entities.LoginThrottleScopeIP, "10.0.0.1").Return(tt.address, nil)
+
+			err := guard.Check(context.Background(), "alice", "10.0.0.1")
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestLoginGuard_Check_StoreUnavailable(t *testing.T) {
+	throttleRepo := new(MockLoginThrottleRepository)
+	guard := newTestLoginGuard(throttleRepo, new(MockUserRepository))
+	throttleRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	assert.NoError(t, guard.Check(context.Background(), "alice", "10.0.0.1"))
+}
+
+func TestLoginGuard_Delay(t *testing.T) {
+	guard := newTestLoginGuard(new(MockLoginThrottleRepository), new(MockUserRepository))
+
+	assert.Equal(t, time.Duration(0), guard.delay(0))
+	assert.Equal(t, time.Second, guard.delay(1))
+	assert.Equal(t, 16*time.Second, guard.delay(5))
+	assert.Equal(t, 30*time.Second, guard.delay(6))
+	assert.Equal(t, 30*time.Second, guard.delay(1000))
+}
+
+func TestLoginGuard_UnlockUser(t *testing.T) {
+	throttleRepo := new(MockLoginThrottleRepository)
+	userRepo := new(MockUserRepository)
+	guard := newTestLoginGuard(throttleRepo, userRepo)
+
+	userRepo.On("GetByID", mock.Anything, "user-123").Return(&entities.User{ID: "user-123", Username: "Alice"}, nil)
+	userRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+	throttleRepo.On("Clear", mock.Anything, entities.LoginThrottleScopeUsername, "alice").Return(nil)
+
+	user, err := guard.UnlockUser(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Username)
+
+	_, err = guard.UnlockUser(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	throttleRepo.AssertExpectations(t)
+}
+
+func TestAuthService_Login_LockedAccount(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	throttleRepo := new(MockLoginThrottleRepository)
+	authService := NewAuthService(userRepo, new(MockSessionRepository), "test-secret")
+	authService.SetLoginGuard(newTestLoginGuard(throttleRepo, userRepo))
+
+	lockedUntil := loginGuardNow.Add(10 * time.Minute)
+	throttleRepo.On("Get", mock.Anything, entities.LoginThrottleScopeUsername, "alice").
+		Return(&entities.LoginThrottle{Failures: 5, LastFailureAt: loginGuardNow, LockedUntil: &lockedUntil}, nil)
+	throttleRepo.On("Get", mock.Anything, entities.LoginThrottleScopeIP, "10.0.0.1").Return(nil, nil)
+
+	// A locked account is rejected before the password is checked, even if it is right
+	_, err := authService.Login(context.Background(), LoginRequest{Username: "alice", Password: "correct", IPAddress: "10.0.0.1"})
+
+	var attemptErr *LoginAttemptError
+	require.True(t, errors.As(err, &attemptErr))
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.Equal(t, lockedUntil, attemptErr.RetryAt)
+	assert.True(t, attemptErr.CaptchaRequired)
+	userRepo.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Login_UnknownUserCounted(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	throttleRepo := new(MockLoginThrottleRepository)
+	authService := NewAuthService(userRepo, new(MockSessionRepository), "test-secret")
+	authService.SetLoginGuard(newTestLoginGuard(throttleRepo, userRepo))
+
+	throttleRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	userRepo.On("GetByUsername", mock.Anything, "ghost").Return(nil, nil)
+	throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeUsername, "ghost", mock.Anything, mock.Anything).
+		Return(throttleWith(entities.LoginThrottleScopeUsername, "ghost", 1), nil)
+	throttleRepo.On("RecordFailure", mock.Anything, entities.LoginThrottleScopeIP, "10.0.0.1", mock.Anything, mock.Anything).
+		Return(throttleWith(entities.LoginThrottleScopeIP, "10.0.0.1", 1), nil)
+
+	_, err := authService.Login(context.Background(), LoginRequest{Username: "ghost", Password: "guess", IPAddress: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	throttleRepo.AssertExpectations(t)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/infrastructure/crypto"
+	"digital-signature-system/internal/infrastructure/logging"
+)
+
+// Names of the built-in maintenance tasks
+const (
+	MaintenanceTaskSessionCleanup   = "session-cleanup"
+	MaintenanceTaskVerificationLogs = "verification-log-retention"
+	MaintenanceTaskAuditLogs        = "audit-log-retention"
+	MaintenanceTaskKeyAgeCheck      = "key-age-check"
+)
+
+// NewSessionCleanupTask removes sessions whose refresh token has expired
+func NewSessionCleanupTask(authService *AuthService) MaintenanceTaskFunc {
+	return func(ctx context.Context) (string, error) {
+		if err := authService.CleanupExpiredSessions(ctx); err != nil {
+			return "", fmt.Errorf("failed to clean up expired sessions: %w", err)
+		}
+		return "expired sessions removed", nil
+	}
+}
+
+// NewVerificationLogRetentionTask removes verification logs older than the retention period
+func NewVerificationLogRetentionTask(verificationLogRepo repositories.VerificationLogRepository, retention time.Duration) MaintenanceTaskFunc {
+	return func(ctx context.Context) (string, error) {
+		removed, err := verificationLogRepo.DeleteBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d verification logs", removed), nil
+	}
+}
+
+// NewAuditLogRetentionTask removes rotated audit log files older than the retention period
+func NewAuditLogRetentionTask(logDir string, retention time.Duration) MaintenanceTaskFunc {
+	return func(ctx context.Context) (string, error) {
+		removed, err := logging.PruneAuditLogs(logDir, time.Now().Add(-retention))
+		if err != nil {
+			return "", fmt.Errorf("failed to prune audit logs: %w", err)
+		}
+		return fmt.Sprintf("removed %d audit log files", removed), nil
+	}
+}
+
+// NewKeyAgeCheckTask fails once the signing key is older than maxAge, so the
+// overdue rotation shows up as a failed run until the key is replaced
+func NewKeyAgeCheckTask(keyManager *crypto.KeyManager, maxAge time.Duration) MaintenanceTaskFunc {
+	return func(ctx context.Context) (string, error) {
+		age := keyManager.GetKeyAge().Truncate(time.Hour)
+		if keyManager.ShouldRotateKey(maxAge) {
+			return "", fmt.Errorf("signing key %s is %s old and past its rotation age of %s", keyManager.GetKeyID(), age, maxAge)
+		}
+		return fmt.Sprintf("signing key %s is %s old", keyManager.GetKeyID(), age), nil
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/infrastructure/crypto"
+)
+
+var (
+	ErrMFANotEnabled           = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment has not been started")
+	ErrInvalidMFACode          = errors.New("invalid two-factor authentication code")
+	ErrMFARequired             = errors.New("two-factor authentication is required")
+)
+
+const (
+	// mfaCodeSkew accepts codes one step either side of now to allow for clock drift
+	mfaCodeSkew       = 1
+	mfaQRCodeSize     = 256
+	recoveryCodeCount = 10
+)
+
+// MFAEnrollment is returned when enrollment starts; the secret is shown once for
+// manual entry and the QR code encodes the provisioning URI
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          []byte `json:"qr_code"`
+}
+
+// MFAStatus describes a user's two-factor setup
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+	RequiredForSigning     bool       `json:"required_for_signing"`
+}
+
+// MFAService manages TOTP enrollment and checks second factor codes
+type MFAService struct {
+	mfaRepo    repositories.MFARepository
+	userRepo   repositories.UserRepository
+	pdfService PDFServiceInterface
+	cipher     *crypto.SecretCipher
+	config     *config.Config
+	now        func() time.Time
+}
+
+// NewMFAService creates the service. TOTP secrets are encrypted with MFAEncryptionKey,
+// or with the JWT secret when no dedicated key is configured.
+func NewMFAService(mfaRepo repositories.MFARepository, userRepo repositories.UserRepository, pdfService PDFServiceInterface, cfg *config.Config) (*MFAService, error) {
+	key := cfg.MFAEncryptionKey
+	if key == "" {
+		key = cfg.JWTSecret
+	}
+	cipher, err := crypto.NewSecretCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize MFA secret encryption: %w", err)
+	}
+
+	return &MFAService{
+		mfaRepo:    mfaRepo,
+		userRepo:   userRepo,
+		pdfService: pdfService,
+		cipher:     cipher,
+		config:     cfg,
+		now:        time.Now,
+	}, nil
+}
+
+// RequiredForSigning reports whether policy requires a two-factor login to sign
+func (s *MFAService) RequiredForSigning() bool {
+	return s.config.MFARequiredForSigning
+}
+
+// IsEnabled reports whether the user has a confirmed enrollment
+func (s *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.Enabled, nil
+}
+
+// Status returns the user's two-factor setup
+func (s *MFAService) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	status := &MFAStatus{RequiredForSigning: s.RequiredForSigning()}
+
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return status, nil
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.EnabledAt = mfa.EnabledAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// BeginEnrollment generates a new secret for the user. It only takes effect once a
+// code from it is confirmed, and starting again replaces an unconfirmed secret.
+func (s *MFAService) BeginEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, &entities.UserMFA{UserID: userID, Secret: encrypted}); err != nil {
+		return nil, err
+	}
+
+	uri := crypto.TOTPProvisioningURI(s.config.MFAIssuer, user.Username, secret)
+	qrCode, err := s.pdfService.GenerateQRCodeWithCenterLabel(uri, "", mfaQRCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate enrollment QR code: %w", err)
+	}
+
+	return &MFAEnrollment{Secret: secret, ProvisioningURI: uri, QRCode: qrCode}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication once the user proves their
+// authenticator produces valid codes, and returns the recovery codes to show once
+func (s *MFAService) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok, err := s.checkTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	now := s.now()
+	mfa.Enabled = true
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+// Verify checks a TOTP or recovery code for a user with two-factor enabled. Each
+// TOTP step and each recovery code is accepted only once.
+func (s *MFAService) Verify(ctx context.Context, userID, code string) error {
+	mfa, err := s.mfaRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return ErrMFANotEnabled
+	}
+
+	code = normalizeMFACode(code)
+	if len(code) != crypto.TOTPDigits {
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), s.now())
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	step, ok, err := s.checkTOTP(mfa, code)
+	if err != nil {
+		return err
+	}
+	if !ok || step <= mfa.LastUsedStep {
+		return ErrInvalidMFACode
+	}
+	used, err := s.mfaRepo.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// Disable removes the user's enrollment after checking a current code. It is refused
+// while policy requires two-factor authentication for signing.
+func (s *MFAService) Disable(ctx context.Context, userID, code string) error {
+	if s.RequiredForSigning() {
+		return ErrMFARequired
+	}
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *MFAService) checkTOTP(mfa *entities.UserMFA, code string) (int64, bool, error) {
+	secret, err := s.cipher.Decrypt(mfa.Secret)
+	if err != nil {
+		return 0, false, err
+	}
+	return crypto.ValidateTOTP(secret, normalizeMFACode(code), s.now(), mfaCodeSkew)
+}
+
+func (s *MFAService) issueRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(normalizeMFACode(code))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as two groups of five characters
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// Recovery codes carry enough entropy that an unsalted hash is sufficient
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeMFACode drops the separators users type or paste along with a code
+func normalizeMFACode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
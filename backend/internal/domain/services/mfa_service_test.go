@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/crypto"
+)
+
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) Get(ctx context.Context, userID string) (*entities.UserMFA, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserMFA), args.Error(1)
+}
+
+func (m *MockMFARepository) Save(ctx context.Context, mfa *entities.UserMFA) error {
+	args := m.Called(ctx, mfa)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) Delete(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	args := m.Called(ctx, userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error) {
+	args := m.Called(ctx, userID, codeHash, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var mfaTestNow = time.Unix(1700000000, 0)
+
+const mfaTestSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func newTestMFAService(t *testing.T, mfaRepo *MockMFARepository, userRepo *MockUserRepository, requiredForSigning bool) *MFAService {
+	service, err := NewMFAService(mfaRepo, userRepo, new(MockPDFService), &config.Config{
+		JWTSecret:             "test-secret",
+		MFAIssuer:             "Digital Signature System",
+		MFAChallengeTTL:       5 * time.Minute,
+		MFARequiredForSigning: requiredForSigning,
+	})
+	require.NoError(t, err)
+	service.now = func() time.Time { return mfaTestNow }
+	return service
+}
+
+// enrolledMFA returns an enabled enrollment for the test secret
+func enrolledMFA(t *testing.T, service *MFAService, lastUsedStep int64) *entities.UserMFA {
+	encrypted, err := service.cipher.Encrypt(mfaTestSecret)
+	require.NoError(t, err)
+	return &entities.UserMFA{UserID: "user-1", Secret: encrypted, Enabled: true, LastUsedStep: lastUsedStep}
+}
+
+func currentMFACode(t *testing.T) string {
+	code, err := crypto.TOTPCode(mfaTestSecret, crypto.TOTPStep(mfaTestNow))
+	require.NoError(t, err)
+	return code
+}
+
+func TestMFAService_Enrollment(t *testing.T) {
+	mfaRepo := new(MockMFARepository)
+	userRepo := new(MockUserRepository)
+	service := newTestMFAService(t, mfaRepo, userRepo, false)
+	pdfService := service.pdfService.(*MockPDFService)
+
+	userRepo.On("GetByID", mock.Anything, "user-1").Return(&entities.User{ID: "user-1", Username: "alice"}, nil)
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(nil, nil).Once()
+	var pending *entities.UserMFA
+	mfaRepo.On("Save", mock.Anything, mock.AnythingOfType("*entities.UserMFA")).
+		Run(func(args mock.Arguments) { pending = args.Get(1).(*entities.UserMFA) }).
+		Return(nil)
+	pdfService.On("GenerateQRCodeWithCenterLabel", mock.MatchedBy(func(uri string) bool {
+		return len(uri) > 0 && uri[:15] == "otpauth://totp/"
+	}), "", 256).Return([]byte("qr"), nil)
+
+	enrollment, err := service.BeginEnrollment(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("qr"), enrollment.QRCode)
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+	require.NotNil(t, pending)
+	assert.False(t, pending.Enabled)
+	assert.NotEqual(t, enrollment.Secret, pending.Secret, "the secret is stored encrypted")
+
+	code, err := crypto.TOTPCode(enrollment.Secret, crypto.TOTPStep(mfaTestNow))
+	require.NoError(t, err)
+
+	// A wrong code leaves the enrollment pending
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(pending, nil)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	_, err = service.ConfirmEnrollment(context.Background(), "user-1", wrong)
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	assert.False(t, pending.Enabled)
+
+	mfaRepo.On("ReplaceRecoveryCodes", mock.Anything, "user-1", mock.AnythingOfType("[]string")).Return(nil)
+
+	recoveryCodes, err := service.ConfirmEnrollment(context.Background(), "user-1", code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	assert.True(t, pending.Enabled)
+	assert.Equal(t, crypto.TOTPStep(mfaTestNow), pending.LastUsedStep, "the confirming code cannot be reused to log in")
+}
+
+func TestMFAService_Verify(t *testing.T) {
+	mfaRepo := new(MockMFARepository)
+	service := newTestMFAService(t, mfaRepo, new(MockUserRepository), false)
+	step := crypto.TOTPStep(mfaTestNow)
+
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(enrolledMFA(t, service, step-5), nil)
+	mfaRepo.On("UseStep", mock.Anything, "user-1", step).Return(true, nil).Once()
+	assert.NoError(t, service.Verify(context.Background(), "user-1", currentMFACode(t)))
+
+	// A concurrent request already used this step
+	mfaRepo.On("UseStep", mock.Anything, "user-1", step).Return(false, nil).Once()
+	assert.ErrorIs(t, service.Verify(context.Background(), "user-1", currentMFACode(t)), ErrInvalidMFACode)
+
+	assert.ErrorIs(t, service.Verify(context.Background(), "user-1", "123456"), ErrInvalidMFACode)
+}
+
+func TestMFAService_Verify_RejectsReplayedCode(t *testing.T) {
+	mfaRepo := new(MockMFARepository)
+	service := newTestMFAService(t, mfaRepo, new(MockUserRepository), false)
+
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(enrolledMFA(t, service, crypto.TOTPStep(mfaTestNow)), nil)
+
+	assert.ErrorIs(t, service.Verify(context.Background(), "user-1", currentMFACode(t)), ErrInvalidMFACode)
+	mfaRepo.AssertNotCalled(t, "UseStep", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMFAService_Verify_RecoveryCode(t *testing.T) {
+	mfaRepo := new(MockMFARepository)
+	service := newTestMFAService(t, mfaRepo, new(MockUserRepository), false)
+
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(enrolledMFA(t, service, 0), nil)
+	mfaRepo.On("UseRecoveryCode", mock.Anything, "user-1", hashRecoveryCode("abcdefghij"), mfaTestNow).Return(true, nil).Once()
+	mfaRepo.On("UseRecoveryCode", mock.Anything, "user-1", mock.Anything, mfaTestNow).Return(false, nil)
+
+	// Codes are accepted however the user types the separator and case
+	assert.NoError(t, service.Verify(context.Background(), "user-1", " ABCDE-fghij "))
+	assert.ErrorIs(t, service.Verify(context.Background(), "user-1", "abcde-fghij"), ErrInvalidMFACode)
+}
+
+func TestMFAService_Disable_RequiredByPolicy(t *testing.T) {
+	mfaRepo := new(MockMFARepository)
+	service := newTestMFAService(t, mfaRepo, new(MockUserRepository), true)
+
+	err := service.Disable(context.Background(), "user-1", currentMFACode(t))
+	assert.ErrorIs(t, err, ErrMFARequired)
+	mfaRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Login_MFAChallenge(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	mfaRepo := new(MockMFARepository)
+	mfaService := newTestMFAService(t, mfaRepo, userRepo, true)
+	authService := NewAuthService(userRepo, sessionRepo, "test-secret")
+	authService.SetMFAService(mfaService)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &entities.User{ID: "user-1", Username: "alice", PasswordHash: string(hashedPassword), IsActive: true}
+	userRepo.On("GetByUsername", mock.Anything, "alice").Return(user, nil)
+	userRepo.On("GetByID", mock.Anything, "user-1").Return(user, nil)
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(enrolledMFA(t, mfaService, 0), nil)
+
+	challenge, err := authService.Login(context.Background(), LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.True(t, challenge.MFARequired)
+	assert.Empty(t, challenge.Token)
+	assert.Nil(t, challenge.User)
+	require.NotEmpty(t, challenge.MFAToken)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// The challenge token only proves the password step
+	_, err = authService.ValidateToken(context.Background(), challenge.MFAToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = authService.CompleteMFALogin(context.Background(), MFALoginRequest{MFAToken: "not-a-token", Code: currentMFACode(t)})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	mfaRepo.On("UseStep", mock.Anything, "user-1", crypto.TOTPStep(mfaTestNow)).Return(true, nil)
+	var session *entities.Session
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Session")).Run(func(args mock.Arguments) {
+		session = args.Get(1).(*entities.Session)
+	}).Return(nil)
+
+	response, err := authService.CompleteMFALogin(context.Background(), MFALoginRequest{MFAToken: challenge.MFAToken, Code: currentMFACode(t)})
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Token)
+	assert.Equal(t, user, response.User)
+	require.NotNil(t, session)
+	assert.True(t, session.MFA)
+
+	sessionRepo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sessionRepo.On("Touch", mock.Anything, session.ID, mock.Anything).Return(nil)
+	_, claims, err := authService.ValidateTokenClaims(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.MFA)
+}
+
+func TestAuthService_Login_MFAEnrollmentRequired(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	mfaRepo := new(MockMFARepository)
+	authService := NewAuthService(userRepo, sessionRepo, "test-secret")
+	authService.SetMFAService(newTestMFAService(t, mfaRepo, userRepo, true))
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &entities.User{ID: "user-1", Username: "alice", PasswordHash: string(hashedPassword), IsActive: true}
+	userRepo.On("GetByUsername", mock.Anything, "alice").Return(user, nil)
+	mfaRepo.On("Get", mock.Anything, "user-1").Return(nil, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+
+	response, err := authService.Login(context.Background(), LoginRequest{Username: "alice", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.False(t, response.MFARequired)
+	assert.True(t, response.MFAEnrollmentRequired)
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("identity provider not found")
+	ErrOIDCProviderFailed   = errors.New("identity provider is unavailable")
+	ErrOIDCStateInvalid     = errors.New("sign-in request not found or expired")
+	ErrOIDCLoginFailed      = errors.New("sign-in with the identity provider failed")
+	ErrOIDCAccessDenied     = errors.New("your account is not permitted to sign in")
+	ErrOIDCAccountNotLinked = errors.New("no account is linked to this identity")
+	ErrOIDCAccountConflict  = errors.New("an account with this email address already exists")
+	ErrOIDCEmailRequired    = errors.New("identity provider did not supply an email address")
+)
+
+const (
+	defaultOIDCStateTTL   = 10 * time.Minute
+	oidcHTTPTimeout       = 10 * time.Second
+	oidcStateSize         = 32
+	maxOIDCUsernameLength = 50
+)
+
+// OIDCProviderInfo describes a provider on the login page
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// OIDCAuthorization starts a sign-in: the browser is sent to AuthorizationURL and
+// must come back with the same State, which it keeps to reject forged callbacks
+type OIDCAuthorization struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// OIDCCallbackRequest completes a sign-in with the provider's authorization response
+type OIDCCallbackRequest struct {
+	State string `json:"state" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+	// IPAddress and UserAgent are recorded on the session to describe the device
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// oidcClaims are the ID token claims used to find or provision the user
+type oidcClaims struct {
+	Subject           string      `json:"sub"`
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"`
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	Nonce             string      `json:"nonce"`
+}
+
+// oidcProvider is a configured provider; discovery happens on first use
+type oidcProvider struct {
+	config   config.OIDCProviderConfig
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// OIDCService signs users in with OpenID Connect providers using the authorization
+// code flow with PKCE, linking or provisioning local accounts
+type OIDCService struct {
+	oidcRepo    repositories.OIDCRepository
+	userRepo    repositories.UserRepository
+	authService *AuthService
+	redirectURL string
+	stateTTL    time.Duration
+	httpClient  *http.Client
+
+	mu        sync.Mutex
+	configs   map[string]config.OIDCProviderConfig
+	order     []string
+	providers map[string]*oidcProvider
+}
+
+// NewOIDCService creates the service for the providers in the config
+func NewOIDCService(oidcRepo repositories.OIDCRepository, userRepo repositories.UserRepository, authService *AuthService, cfg *config.Config) (*OIDCService, error) {
+	stateTTL := cfg.OIDCStateTTL
+	if stateTTL <= 0 {
+		stateTTL = defaultOIDCStateTTL
+	}
+
+	s := &OIDCService{
+		oidcRepo:    oidcRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		redirectURL: cfg.OIDCRedirectURL,
+		stateTTL:    stateTTL,
+		httpClient:  &http.Client{Timeout: oidcHTTPTimeout},
+		configs:     make(map[string]config.OIDCProviderConfig),
+		providers:   make(map[string]*oidcProvider),
+	}
+	for _, provider := range cfg.OIDCProviders {
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("identity provider %s needs an issuer and a client ID", provider.Name)
+		}
+		if _, exists := s.configs[provider.Name]; exists {
+			return nil, fmt.Errorf("identity provider %s is configured twice", provider.Name)
+		}
+		s.configs[provider.Name] = provider
+		s.order = append(s.order, provider.Name)
+	}
+	return s, nil
+}
+
+// Providers lists the configured providers in configuration order
+func (s *OIDCService) Providers() []OIDCProviderInfo {
+	providers := make([]OIDCProviderInfo, 0, len(s.order))
+	for _, name := range s.order {
+		providers = append(providers, OIDCProviderInfo{Name: name, DisplayName: s.configs[name].DisplayName})
+	}
+	return providers
+}
+
+// BeginLogin records a new authorization request and returns the provider URL to
+// send the browser to
+func (s *OIDCService) BeginLogin(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, err := s.provider(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	// Abandoned sign-ins are cleared as new ones start
+	if _, err := s.oidcRepo.DeleteExpiredAuthRequests(ctx, time.Now()); err != nil {
+		fmt.Printf("Warning: failed to delete expired OIDC authorization requests: %v\n", err)
+	}
+
+	request := &entities.OIDCAuthRequest{
+		Provider:     providerName,
+		StateHash:    hashOIDCState(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}
+	if err := s.oidcRepo.CreateAuthRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthorization{
+		AuthorizationURL: provider.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		State:            state,
+		ExpiresAt:        request.ExpiresAt,
+	}, nil
+}
+
+// CompleteLogin exchanges the authorization code, validates the ID token and signs
+// the linked user in. Users with two-factor authentication get an MFA challenge as
+// after a password.
+func (s *OIDCService) CompleteLogin(ctx context.Context, req OIDCCallbackRequest) (*LoginResponse, error) {
+	request, err := s.oidcRepo.ConsumeAuthRequest(ctx, hashOIDCState(req.State), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	provider, err := s.provider(ctx, request.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	httpCtx := oidc.ClientContext(ctx, s.httpClient)
+	token, err := provider.oauth2.Exchange(httpCtx, req.Code, oauth2.VerifierOption(request.CodeVerifier))
+	if err != nil {
+		fmt.Printf("Warning: OIDC code exchange with %s failed: %v\n", request.Provider, err)
+		return nil, ErrOIDCLoginFailed
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrOIDCLoginFailed
+	}
+
+	idToken, err := provider.verifier.Verify(httpCtx, rawIDToken)
+	if err != nil {
+		fmt.Printf("Warning: OIDC ID token from %s rejected: %v\n", request.Provider, err)
+		return nil, ErrOIDCLoginFailed
+	}
+
+	var claims oidcClaims
+	var allClaims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, ErrOIDCLoginFailed
+	}
+	if err := idToken.Claims(&allClaims); err != nil {
+		return nil, ErrOIDCLoginFailed
+	}
+	if claims.Nonce != request.Nonce {
+		return nil, ErrOIDCLoginFailed
+	}
+
+	role, allowed := mapRole(provider.config.RoleMappings, provider.config.DefaultRole, oidcGroups(allClaims[provider.config.GroupsClaim]))
+	if !allowed {
+		return nil, ErrOIDCAccessDenied
+	}
+
+	user, err := s.resolveUser(ctx, provider.config, &claims, role)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	return s.authService.externalLogin(ctx, user, req.IPAddress, req.UserAgent)
+}
+
+// resolveUser finds the account linked to the identity, links an existing account
+// by verified email when allowed, or provisions a new one
+func (s *OIDCService) resolveUser(ctx context.Context, provider config.OIDCProviderConfig, claims *oidcClaims, role string) (*entities.User, error) {
+	now := time.Now()
+
+	identity, err := s.oidcRepo.GetIdentity(ctx, provider.Name, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			identity.Email = claims.Email
+			identity.LastLoginAt = &now
+			if err := s.oidcRepo.UpdateIdentity(ctx, identity); err != nil {
+				return nil, err
+			}
+			return syncRole(ctx, s.userRepo, provider.RoleMappings, user, role)
+		}
+		// The account was deleted; the identity may be linked or provisioned again
+		if err := s.oidcRepo.DeleteIdentity(ctx, identity.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	// Service accounts are never taken over by a provider login
+	case user != nil && provider.LinkByEmail && emailVerified(claims.EmailVerified) && !user.ServiceAccount:
+		user, err = syncRole(ctx, s.userRepo, provider.RoleMappings, user, role)
+		if err != nil {
+			return nil, err
+		}
+	case user != nil:
+		return nil, ErrOIDCAccountConflict
+	case provider.AutoProvision:
+		user, err = s.provisionUser(ctx, provider, claims, role)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrOIDCAccountNotLinked
+	}
+
+	if err := s.oidcRepo.CreateIdentity(ctx, &entities.UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider.Name,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// syncRole applies the role from a group mapping; without a mapping the provider
+// does not manage roles and the local role is kept
+func syncRole(ctx context.Context, userRepo repositories.UserRepository, mappings []config.RoleMapping, user *entities.User, role string) (*entities.User, error) {
+	if len(mappings) == 0 || user.Role == role {
+		return user, nil
+	}
+	user.Role = role
+	if err := userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+	return user, nil
+}
+
+// provisionUser creates an account for a first sign-in. The account has no
+// password, so it can only sign in through the provider.
+func (s *OIDCService) provisionUser(ctx context.Context, provider config.OIDCProviderConfig, claims *oidcClaims, role string) (*entities.User, error) {
+	base := oidcUsername(claims)
+	if base == "" {
+		base = provider.Name + "-" + hashOIDCState(claims.Subject)[:8]
+	}
+
+	username := base
+	for attempt := 0; ; attempt++ {
+		existing, err := s.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			break
+		}
+		if attempt == 5 {
+			return nil, ErrUserAlreadyExists
+		}
+		suffix, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		username = truncate(base, maxOIDCUsernameLength-5) + "-" + strings.ToLower(suffix[:4])
+	}
+
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := &entities.User{
+		Username: username,
+		FullName: fullName,
+		Email:    claims.Email,
+		Role:     role,
+		IsActive: true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// provider returns a provider, running discovery the first time it is used
+func (s *OIDCService) provider(ctx context.Context, name string) (*oidcProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if provider, ok := s.providers[name]; ok {
+		return provider, nil
+	}
+	cfg, ok := s.configs[name]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	discovered, err := oidc.NewProvider(oidc.ClientContext(ctx, s.httpClient), cfg.IssuerURL)
+	if err != nil {
+		fmt.Printf("Warning: OIDC discovery for %s failed: %v\n", name, err)
+		return nil, ErrOIDCProviderFailed
+	}
+
+	provider := &oidcProvider{
+		config: cfg,
+		oauth2: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     discovered.Endpoint(),
+			RedirectURL:  s.redirectURL,
+			Scopes:       cfg.Scopes,
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+	}
+	s.providers[name] = provider
+	return provider, nil
+}
+
+// externalLogin opens a session for a user authenticated by an identity provider,
+// asking for the second factor first when the user has one
+func (s *AuthService) externalLogin(ctx context.Context, user *entities.User, ipAddress, userAgent string) (*LoginResponse, error) {
+	if s.mfaService != nil {
+		enabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check two-factor authentication: %w", err)
+		}
+		if enabled {
+			return s.mfaChallenge(user)
+		}
+	}
+
+	response, err := s.createSession(ctx, user, false, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	response.MFAEnrollmentRequired = s.mfaService != nil && s.mfaService.RequiredForSigning()
+	return response, nil
+}
+
+// mapRole returns the role for the user's groups and whether they may sign in.
+// Group names are compared case-insensitively, as directory names are.
+func mapRole(mappings []config.RoleMapping, defaultRole string, groups []string) (string, bool) {
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[strings.ToLower(group)] = true
+	}
+	for _, mapping := range mappings {
+		if member[strings.ToLower(mapping.Group)] {
+			return mapping.Role, true
+		}
+	}
+	return defaultRole, defaultRole != ""
+}
+
+// oidcGroups reads a groups claim given either as a list or a single string
+func oidcGroups(claim interface{}) []string {
+	switch value := claim.(type) {
+	case []interface{}:
+		groups := make([]string, 0, len(value))
+		for _, item := range value {
+			if group, ok := item.(string); ok {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+	case string:
+		return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	default:
+		return nil
+	}
+}
+
+// emailVerified reads email_verified, which some providers send as a string
+func emailVerified(claim interface{}) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		verified, _ := strconv.ParseBool(value)
+		return verified
+	default:
+		return false
+	}
+}
+
+// oidcUsername derives a username from the preferred username or email address,
+// keeping only characters usernames may contain
+func oidcUsername(claims *oidcClaims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range candidate {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			b.WriteRune(r)
+		}
+	}
+	return truncate(b.String(), maxOIDCUsernameLength)
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, oidcStateSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashOIDCState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
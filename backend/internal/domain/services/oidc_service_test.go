@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockOIDCRepository struct {
+	mock.Mock
+}
+
+func (m *MockOIDCRepository) CreateAuthRequest(ctx context.Context, request *entities.OIDCAuthRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) ConsumeAuthRequest(ctx context.Context, stateHash string, now time.Time) (*entities.OIDCAuthRequest, error) {
+	args := m.Called(ctx, stateHash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OIDCAuthRequest), args.Error(1)
+}
+
+func (m *MockOIDCRepository) DeleteExpiredAuthRequests(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOIDCRepository) GetIdentity(ctx context.Context, provider, subject string) (*entities.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserIdentity), args.Error(1)
+}
+
+func (m *MockOIDCRepository) CreateIdentity(ctx context.Context, identity *entities.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) UpdateIdentity(ctx context.Context, identity *entities.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) DeleteIdentity(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// mockOIDCProvider is a minimal OpenID Connect provider: discovery, JWKS and a
+// token endpoint that checks the PKCE verifier and returns a signed ID token
+type mockOIDCProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, clientID: "signing-app", codes: make(map[string]mockAuthorization)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		p.mu.Lock()
+		authorization, ok := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+		p.mu.Unlock()
+
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, authorization.claims)
+		token.Header["kid"] = "test-key"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize plays the user signing in at the provider: it checks the authorization
+// URL and returns the code the browser would bring back
+func (p *mockOIDCProvider) authorize(t *testing.T, authorizationURL string, claims jwt.MapClaims) string {
+	parsed, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	require.Equal(t, p.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	require.Equal(t, p.clientID, query.Get("client_id"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Equal(t, "code", query.Get("response_type"))
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   p.clientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	code := "code-" + query.Get("state")[:8]
+	p.mu.Lock()
+	p.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+	return code
+}
+
+type oidcTestEnv struct {
+	provider    *mockOIDCProvider
+	service     *OIDCService
+	oidcRepo    *MockOIDCRepository
+	userRepo    *MockUserRepository
+	sessionRepo *MockSessionRepository
+	request     *entities.OIDCAuthRequest
+}
+
+func newOIDCTestEnv(t *testing.T, configure func(*config.OIDCProviderConfig)) *oidcTestEnv {
+	provider := newMockOIDCProvider(t)
+	providerConfig := config.OIDCProviderConfig{
+		Name:          "university",
+		DisplayName:   "University Login",
+		IssuerURL:     provider.server.URL,
+		ClientID:      provider.clientID,
+		ClientSecret:  "secret",
+		Scopes:        []string{"openid", "profile", "email"},
+		GroupsClaim:   "groups",
+		RoleMappings:  []config.RoleMapping{{Group: "sig-admins", Role: "admin"}, {Group: "staff", Role: "user"}},
+		DefaultRole:   "user",
+		AutoProvision: true,
+	}
+	if configure != nil {
+		configure(&providerConfig)
+	}
+
+	env := &oidcTestEnv{
+		provider:    provider,
+		oidcRepo:    new(MockOIDCRepository),
+		userRepo:    new(MockUserRepository),
+		sessionRepo: new(MockSessionRepository),
+	}
+	authService := NewAuthService(env.userRepo, env.sessionRepo, "test-secret")
+	service, err := NewOIDCService(env.oidcRepo, env.userRepo, authService, &config.Config{
+		OIDCProviders:   []config.OIDCProviderConfig{providerConfig},
+		OIDCRedirectURL: "https://sign.example.edu/oidc/callback",
+	})
+	require.NoError(t, err)
+	env.service = service
+
+	env.oidcRepo.On("DeleteExpiredAuthRequests", mock.Anything, mock.Anything).Return(int64(0), nil)
+	env.oidcRepo.On("CreateAuthRequest", mock.Anything, mock.AnythingOfType("*entities.OIDCAuthRequest")).Run(func(args mock.Arguments) {
+		env.request = args.Get(1).(*entities.OIDCAuthRequest)
+	}).Return(nil)
+	env.sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Session")).Return(nil)
+	return env
+}
+
+// signIn runs the flow up to the callback with the given ID token claims
+func (env *oidcTestEnv) signIn(t *testing.T, claims jwt.MapClaims) (*LoginResponse, error) {
+	ctx := context.Background()
+	authorization, err := env.service.BeginLogin(ctx, "university")
+	require.NoError(t, err)
+	require.NotNil(t, env.request)
+	assert.Equal(t, hashOIDCState(authorization.State), env.request.StateHash)
+
+	code := env.provider.authorize(t, authorization.AuthorizationURL, claims)
+	env.oidcRepo.On("ConsumeAuthRequest", mock.Anything, env.request.StateHash, mock.Anything).Return(env.request, nil).Once()
+
+	return env.service.CompleteLogin(ctx, OIDCCallbackRequest{State: authorization.State, Code: code, IPAddress: "192.0.2.1"})
+}
+
+func TestOIDCService_ProvisionsUser(t *testing.T) {
+	env := newOIDCTestEnv(t, nil)
+
+	env.oidcRepo.On("GetIdentity", mock.Anything, "university", "u-1001").Return(nil, nil)
+	env.userRepo.On("GetByEmail", mock.Anything, "ada@example.edu").Return(nil, nil)
+	env.userRepo.On("GetByUsername", mock.Anything, "ada").Return(&entities.User{ID: "someone-else"}, nil)
+	env.userRepo.On("GetByUsername", mock.Anything, mock.Anything).Return(nil, nil)
+	var created *entities.User
+	env.userRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.User")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*entities.User)
+		created.ID = "user-1"
+	}).Return(nil)
+	var identity *entities.UserIdentity
+	env.oidcRepo.On("CreateIdentity", mock.Anything, mock.AnythingOfType("*entities.UserIdentity")).Run(func(args mock.Arguments) {
+		identity = args.Get(1).(*entities.UserIdentity)
+	}).Return(nil)
+
+	response, err := env.signIn(t, jwt.MapClaims{
+		"sub":                "u-1001",
+		"email":              "ada@example.edu",
+		"name":               "Ada Lovelace",
+		"preferred_username": "ada",
+		"groups":             []string{"staff", "sig-admins"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Token)
+	assert.NotEmpty(t, response.RefreshToken)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "admin", created.Role, "the first matching mapping wins")
+	assert.Equal(t, "Ada Lovelace", created.FullName)
+	assert.Empty(t, created.PasswordHash, "provisioned accounts cannot sign in with a password")
+	assert.NotEqual(t, "ada", created.Username, "a taken username gets a suffix")
+	assert.Contains(t, created.Username, "ada-")
+
+	require.NotNil(t, identity)
+	assert.Equal(t, "user-1", identity.UserID)
+	assert.Equal(t, "u-1001", identity.Subject)
+}
+
+func TestOIDCService_LinkedIdentitySyncsRole(t *testing.T) {
+	env := newOIDCTestEnv(t, nil)
+
+	user := &entities.User{ID: "user-2", Username: "grace", Role: "admin", IsActive: true}
+	env.oidcRepo.On("GetIdentity", mock.Anything, "university", "u-2002").
+		Return(&entities.UserIdentity{ID: "identity-2", UserID: "user-2", Provider: "university", Subject: "u-2002"}, nil)
+	env.oidcRepo.On("UpdateIdentity", mock.Anything, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+	env.userRepo.On("GetByID", mock.Anything, "user-2").Return(user, nil)
+	env.userRepo.On("Update", mock.Anything, user).Return(nil)
+
+	response, err := env.signIn(t, jwt.MapClaims{"sub": "u-2002", "email": "grace@example.edu", "groups": "staff"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", response.User.ID)
+	assert.Equal(t, "user", user.Role, "leaving the admin group removes the admin role")
+	env.userRepo.AssertCalled(t, "Update", mock.Anything, user)
+}
+
+func TestOIDCService_LinkByEmail(t *testing.T) {
+	existing := &entities.User{ID: "user-3", Username: "alan", Email: "alan@example.edu", Role: "user", IsActive: true}
+
+	t.Run("verified email is linked", func(t *testing.T) {
+		env := newOIDCTestEnv(t, func(p *config.OIDCProviderConfig) {
+			p.LinkByEmail = true
+			p.RoleMappings = nil
+		})
+		env.oidcRepo.On("GetIdentity", mock.Anything, "university", "u-3003").Return(nil, nil)
+		env.userRepo.On("GetByEmail", mock.Anything, "alan@example.edu").Return(existing, nil)
+		env.oidcRepo.On("CreateIdentity", mock.Anything, mock.AnythingOfType("*entities.UserIdentity")).Return(nil)
+
+		response, err := env.signIn(t, jwt.MapClaims{"sub": "u-3003", "email": "alan@example.edu", "email_verified": "true"})
+		require.NoError(t, err)
+		assert.Equal(t, "user-3", response.User.ID)
+		env.userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unverified email conflicts", func(t *testing.T) {
+		env := newOIDCTestEnv(t, func(p *config.OIDCProviderConfig) { p.LinkByEmail = true })
+		env.oidcRepo.On("GetIdentity", mock.Anything, "university", "u-3003").Return(nil, nil)
+		env.userRepo.On("GetByEmail", mock.Anything, "alan@example.edu").Return(existing, nil)
+
+		_, err := env.signIn(t, jwt.MapClaims{"sub": "u-3003", "email": "alan@example.edu", "email_verified": false})
+		assert.ErrorIs(t, err, ErrOIDCAccountConflict)
+		env.oidcRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOIDCService_Rejects(t *testing.T) {
+	t.Run("user outside mapped groups", func(t *testing.T) {
+		env := newOIDCTestEnv(t, func(p *config.OIDCProviderConfig) { p.DefaultRole = "" })
+		_, err := env.signIn(t, jwt.MapClaims{"sub": "u-4004", "email": "eve@example.edu", "groups": []string{"students"}})
+		assert.ErrorIs(t, err, ErrOIDCAccessDenied)
+	})
+
+	t.Run("unknown user without provisioning", func(t *testing.T) {
+		env := newOIDCTestEnv(t, func(p *config.OIDCProviderConfig) { p.AutoProvision = false })
+		env.oidcRepo.On("GetIdentity", mock.Anything, "university", "u-5005").Return(nil, nil)
+		env.userRepo.On("GetByEmail", mock.Anything, "new@example.edu").Return(nil, nil)
+		_, err := env.signIn(t, jwt.MapClaims{"sub": "u-5005", "email": "new@example.edu"})
+		assert.ErrorIs(t, err, ErrOIDCAccountNotLinked)
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		env := newOIDCTestEnv(t, nil)
+		_, err := env.signIn(t, jwt.MapClaims{"sub": "u-6006", "nonce": "replayed"})
+		assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+	})
+
+	t.Run("token for another client", func(t *testing.T) {
+		env := newOIDCTestEnv(t, nil)
+		_, err := env.signIn(t, jwt.MapClaims{"sub": "u-6006", "aud": "other-app"})
+		assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		env := newOIDCTestEnv(t, nil)
+		env.oidcRepo.On("ConsumeAuthRequest", mock.Anything, hashOIDCState("forged"), mock.Anything).Return(nil, nil)
+		_, err := env.service.CompleteLogin(context.Background(), OIDCCallbackRequest{State: "forged", Code: "code"})
+		assert.ErrorIs(t, err, ErrOIDCStateInvalid)
+	})
+
+	t.Run("code without the verifier", func(t *testing.T) {
+		env := newOIDCTestEnv(t, nil)
+		ctx := context.Background()
+		authorization, err := env.service.BeginLogin(ctx, "university")
+		require.NoError(t, err)
+		code := env.provider.authorize(t, authorization.AuthorizationURL, jwt.MapClaims{"sub": "u-7007"})
+
+		// An intercepted code is useless without the verifier kept on the server
+		stolen := *env.request
+		stolen.CodeVerifier = "attacker-chosen-verifier-attacker-chosen-verifier"
+		env.oidcRepo.On("ConsumeAuthRequest", mock.Anything, env.request.StateHash, mock.Anything).Return(&stolen, nil)
+		_, err = env.service.CompleteLogin(ctx, OIDCCallbackRequest{State: authorization.State, Code: code})
+		assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		env := newOIDCTestEnv(t, nil)
+		_, err := env.service.BeginLogin(context.Background(), "elsewhere")
+		assert.ErrorIs(t, err, ErrOIDCProviderNotFound)
+	})
+}
+
+func TestNewOIDCService_Validation(t *testing.T) {
+	_, err := NewOIDCService(nil, nil, nil, &config.Config{OIDCProviders: []config.OIDCProviderConfig{{Name: "university"}}})
+	assert.Error(t, err)
+
+	service, err := NewOIDCService(nil, nil, nil, &config.Config{OIDCProviders: []config.OIDCProviderConfig{
+		{Name: "university", DisplayName: "University", IssuerURL: "https://idp.example.edu", ClientID: "app"},
+		{Name: "partner", DisplayName: "Partner", IssuerURL: "https://idp.example.org", ClientID: "app"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []OIDCProviderInfo{
+		{Name: "university", DisplayName: "University"},
+		{Name: "partner", DisplayName: "Partner"},
+	}, service.Providers())
+}
+
+func TestOIDCUsername(t *testing.T) {
+	assert.Equal(t, "ada.lovelace", oidcUsername(&oidcClaims{PreferredUsername: "ada.lovelace"}))
+	assert.Equal(t, "alan", oidcUsername(&oidcClaims{Email: "alan@example.edu"}))
+	assert.Equal(t, "bobtables", oidcUsername(&oidcClaims{PreferredUsername: "bob'; tables"}))
+	assert.Equal(t, "", oidcUsername(&oidcClaims{}))
+}
@@ -0,0 +1,555 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/infrastructure/crypto"
+)
+
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrOrganizationExists      = errors.New("organization already exists")
+	ErrOrganizationInactive    = errors.New("organization is inactive")
+	ErrInvalidOrganization     = errors.New("invalid organization")
+	ErrNotOrganizationMember   = errors.New("not a member of the organization")
+	ErrOrganizationAccess      = errors.New("organization administrator access required")
+	ErrOrganizationKeyNotFound = errors.New("organization key not found")
+)
+
+// organizationSlugPattern keeps slugs usable in URLs and headers
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// OrganizationRequest describes a new organization or the settings of an existing one
+type OrganizationRequest struct {
+	Slug                string  `json:"slug"`
+	Name                string  `json:"name"`
+	IssuerName          string  `json:"issuer_name"`
+	VerificationBaseURL string  `json:"verification_base_url"`
+	StampLabel          string  `json:"stamp_label"`
+	StampX              float64 `json:"stamp_x"`
+	StampY              float64 `json:"stamp_y"`
+	StampWidth          float64 `json:"stamp_width"`
+	// VerificationRedactions are the fields hidden on the public verification page
+	VerificationRedactions []string `json:"verification_redactions"`
+	// IsActive is only read on update; inactive organizations cannot sign
+	IsActive *bool `json:"is_active,omitempty"`
+	// AdminUserID is only read on create and makes that user the first administrator
+	AdminUserID string `json:"admin_user_id,omitempty"`
+}
+
+// OrganizationService manages organizations, their members and signing keys
+type OrganizationService struct {
+	orgRepo     repositories.OrganizationRepository
+	userRepo    repositories.UserRepository
+	permissions PermissionChecker
+	cipher      *crypto.SecretCipher
+	keySize     int
+	now         func() time.Time
+
+	// Decrypted signers are kept by key ID so each key is parsed once
+	mu      sync.Mutex
+	signers map[string]*crypto.SignatureService
+}
+
+// NewOrganizationService creates the service. Private keys are encrypted with
+// OrgKeyEncryptionKey, or with the JWT secret when no dedicated key is configured.
+func NewOrganizationService(orgRepo repositories.OrganizationRepository, userRepo repositories.UserRepository, cfg *config.Config) (*OrganizationService, error) {
+	key := cfg.OrgKeyEncryptionKey
+	if key == "" {
+		key = cfg.JWTSecret
+	}
+	cipher, err := crypto.NewSecretCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize organization key encryption: %w", err)
+	}
+
+	keySize := cfg.OrgKeySize
+	if keySize == 0 {
+		keySize = 2048
+	}
+
+	return &OrganizationService{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		cipher:   cipher,
+		keySize:  keySize,
+		now:      time.Now,
+		signers:  make(map[string]*crypto.SignatureService),
+	}, nil
+}
+
+// SetPermissionChecker lets users whose role grants user:manage administer
+// every organization
+func (s *OrganizationService) SetPermissionChecker(permissions PermissionChecker) {
+	s.permissions = permissions
+}
+
+// CreateOrganization adds an organization with a fresh signing key
+func (s *OrganizationService) CreateOrganization(ctx context.Context, req *OrganizationRequest) (*entities.Organization, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !organizationSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: slug must be 2-63 lowercase letters, digits or '-'", ErrInvalidOrganization)
+	}
+
+	existing, err := s.orgRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing organization: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrOrganizationExists
+	}
+
+	var admin *entities.User
+	if req.AdminUserID != "" {
+		admin, err = s.userRepo.GetByID(ctx, req.AdminUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if admin == nil {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	now := s.now()
+	org := &entities.Organization{
+		Slug:      slug,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyOrganizationSettings(org, req); err != nil {
+		return nil, err
+	}
+
+	key, err := s.generateKey(org)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.orgRepo.Create(ctx, org); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	key.OrganizationID = org.ID
+	if err := s.orgRepo.CreateKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store organization key: %w", err)
+	}
+
+	if admin != nil {
+		member := &entities.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         admin.ID,
+			Role:           entities.OrganizationRoleAdmin,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.orgRepo.SaveMember(ctx, member); err != nil {
+			return nil, fmt.Errorf("failed to add organization administrator: %w", err)
+		}
+	}
+
+	return org, nil
+}
+
+// ListOrganizations returns every organization
+func (s *OrganizationService) ListOrganizations(ctx context.Context) ([]*entities.Organization, error) {
+	orgs, err := s.orgRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// ListUserOrganizations returns the user's memberships in active organizations
+func (s *OrganizationService) ListUserOrganizations(ctx context.Context, userID string) ([]*entities.OrganizationMember, error) {
+	memberships, err := s.orgRepo.ListMemberships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return memberships, nil
+}
+
+// GetOrganization returns the organization with the ID
+func (s *OrganizationService) GetOrganization(ctx context.Context, id string) (*entities.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// GetOrganizationForUser returns the organization when the user is a member or
+// may administer every organization
+func (s *OrganizationService) GetOrganizationForUser(ctx context.Context, userID, id string) (*entities.Organization, error) {
+	org, err := s.GetOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.orgRepo.GetMember(ctx, org.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if member == nil {
+		platformAdmin, err := s.isPlatformAdmin(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !platformAdmin {
+			return nil, ErrNotOrganizationMember
+		}
+	}
+	return org, nil
+}
+
+// UpdateOrganization replaces an organization's settings; the slug cannot change
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, actorID, id string, req *OrganizationRequest) (*entities.Organization, error) {
+	org, err := s.requireAdmin(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyOrganizationSettings(org, req); err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil {
+		org.IsActive = *req.IsActive
+	}
+	org.UpdatedAt = s.now()
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	return org, nil
+}
+
+// ResolveMembership returns the user's membership in the organization. Without
+// an organization ID it returns the user's only membership, or nil when the user
+// belongs to none or several organizations.
+func (s *OrganizationService) ResolveMembership(ctx context.Context, userID, organizationID string) (*entities.OrganizationMember, error) {
+	if organizationID == "" {
+		memberships, err := s.orgRepo.ListMemberships(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organizations: %w", err)
+		}
+		if len(memberships) != 1 {
+			return nil, nil
+		}
+		return memberships[0], nil
+	}
+
+	org, err := s.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsActive {
+		return nil, ErrOrganizationInactive
+	}
+	member, err := s.orgRepo.GetMember(ctx, org.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if member == nil {
+		return nil, ErrNotOrganizationMember
+	}
+	return member, nil
+}
+
+// ListMembers returns the organization's members
+func (s *OrganizationService) ListMembers(ctx context.Context, actorID, id string) ([]*entities.OrganizationMember, error) {
+	org, err := s.requireAdmin(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.orgRepo.ListMembers(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	return members, nil
+}
+
+// SetMember adds the user to the organization or changes their role
+func (s *OrganizationService) SetMember(ctx context.Context, actorID, id, userID, role string) (*entities.OrganizationMember, error) {
+	org, err := s.requireAdmin(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role != entities.OrganizationRoleAdmin && role != entities.OrganizationRoleMember {
+		return nil, fmt.Errorf("%w: role must be %q or %q", ErrInvalidOrganization, entities.OrganizationRoleAdmin, entities.OrganizationRoleMember)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	member, err := s.orgRepo.GetMember(ctx, org.ID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	now := s.now()
+	if member == nil {
+		member = &entities.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, CreatedAt: now}
+	} else if member.Role == entities.OrganizationRoleAdmin && role != entities.OrganizationRoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, org.ID, member.ID); err != nil {
+			return nil, err
+		}
+	}
+	member.Role = role
+	member.UpdatedAt = now
+
+	if err := s.orgRepo.SaveMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to save organization member: %w", err)
+	}
+	return member, nil
+}
+
+// RemoveMember takes the user out of the organization; their documents stay with it
+func (s *OrganizationService) RemoveMember(ctx context.Context, actorID, id, userID string) error {
+	org, err := s.requireAdmin(ctx, actorID, id)
+	if err != nil {
+		return err
+	}
+	member, err := s.orgRepo.GetMember(ctx, org.ID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if member == nil {
+		return ErrNotOrganizationMember
+	}
+	if member.Role == entities.OrganizationRoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, org.ID, member.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.DeleteMember(ctx, member.ID); err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	return nil
+}
+
+// ListKeys returns the organization's signing keys, newest first
+func (s *OrganizationService) ListKeys(ctx context.Context, actorID, id string) ([]*entities.OrganizationKey, error) {
+	org, err := s.GetOrganizationForUser(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.orgRepo.ListKeys(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RotateKey replaces the organization's signing key. The retired key keeps
+// verifying the documents it signed.
+func (s *OrganizationService) RotateKey(ctx context.Context, actorID, id string) (*entities.OrganizationKey, error) {
+	org, err := s.requireAdmin(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.generateKey(org)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.RotateKey(ctx, key, key.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to rotate organization key: %w", err)
+	}
+	return key, nil
+}
+
+// SignerFor returns the active signing key of an active organization and its key ID
+func (s *OrganizationService) SignerFor(ctx context.Context, organizationID string) (SignatureServiceInterface, string, error) {
+	org, err := s.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !org.IsActive {
+		return nil, "", ErrOrganizationInactive
+	}
+
+	key, err := s.orgRepo.GetActiveKey(ctx, org.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get organization key: %w", err)
+	}
+	if key == nil {
+		return nil, "", ErrOrganizationKeyNotFound
+	}
+
+	signer, err := s.signerForKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return signer, key.KeyID, nil
+}
+
+// VerifierFor returns the organization key, active or retired, that signed a document
+func (s *OrganizationService) VerifierFor(ctx context.Context, organizationID, keyID string) (SignatureServiceInterface, error) {
+	key, err := s.orgRepo.GetKey(ctx, organizationID, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization key: %w", err)
+	}
+	if key == nil {
+		return nil, ErrOrganizationKeyNotFound
+	}
+	return s.signerForKey(key)
+}
+
+func (s *OrganizationService) signerForKey(key *entities.OrganizationKey) (*crypto.SignatureService, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if signer, ok := s.signers[key.KeyID]; ok {
+		return signer, nil
+	}
+
+	privateKey, err := s.cipher.Decrypt(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt organization key: %w", err)
+	}
+	km, err := crypto.NewKeyManagerFromEnv(privateKey, key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization key: %w", err)
+	}
+	signer, err := crypto.NewSignatureServiceFromKeyManager(km)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load organization key: %w", err)
+	}
+
+	s.signers[key.KeyID] = signer
+	return signer, nil
+}
+
+func (s *OrganizationService) generateKey(org *entities.Organization) (*entities.OrganizationKey, error) {
+	pair, err := (&crypto.KeyManager{}).GenerateNewKeyPair(s.keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate organization key: %w", err)
+	}
+	encrypted, err := s.cipher.Encrypt(pair.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt organization key: %w", err)
+	}
+
+	return &entities.OrganizationKey{
+		OrganizationID: org.ID,
+		KeyID:          pair.KeyID,
+		Algorithm:      fmt.Sprintf("RSA-%d", s.keySize),
+		PublicKey:      pair.PublicKey,
+		PrivateKey:     encrypted,
+		Status:         entities.OrganizationKeyActive,
+		CreatedAt:      s.now(),
+	}, nil
+}
+
+// requireAdmin returns the organization when the actor administers it or may
+// administer every organization
+func (s *OrganizationService) requireAdmin(ctx context.Context, actorID, id string) (*entities.Organization, error) {
+	org, err := s.GetOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.orgRepo.GetMember(ctx, org.ID, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if member != nil && member.Role == entities.OrganizationRoleAdmin {
+		return org, nil
+	}
+
+	platformAdmin, err := s.isPlatformAdmin(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !platformAdmin {
+		return nil, ErrOrganizationAccess
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) isPlatformAdmin(ctx context.Context, userID string) (bool, error) {
+	return hasPermission(ctx, s.permissions, userID, entities.PermissionUserManage)
+}
+
+// ensureAnotherAdmin stops the last administrator of an organization from being
+// demoted or removed
+func (s *OrganizationService) ensureAnotherAdmin(ctx context.Context, organizationID, memberID string) error {
+	members, err := s.orgRepo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to list organization members: %w", err)
+	}
+	for _, other := range members {
+		if other.ID != memberID && other.Role == entities.OrganizationRoleAdmin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot remove the last organization administrator", ErrInvalidOrganization)
+}
+
+func applyOrganizationSettings(org *entities.Organization, req *OrganizationRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 200 {
+		return fmt.Errorf("%w: name must be 1-200 characters", ErrInvalidOrganization)
+	}
+
+	baseURL := strings.TrimRight(strings.TrimSpace(req.VerificationBaseURL), "/")
+	if baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("%w: verification_base_url must be an absolute http(s) URL", ErrInvalidOrganization)
+		}
+	}
+
+	if req.StampX < 0 || req.StampY < 0 || req.StampWidth < 0 {
+		return fmt.Errorf("%w: stamp position and width cannot be negative", ErrInvalidOrganization)
+	}
+
+	redactions, err := verificationRedactions(req.VerificationRedactions)
+	if err != nil {
+		return err
+	}
+
+	org.Name = name
+	org.IssuerName = strings.TrimSpace(req.IssuerName)
+	org.VerificationBaseURL = baseURL
+	org.StampLabel = strings.TrimSpace(req.StampLabel)
+	org.StampX = req.StampX
+	org.StampY = req.StampY
+	org.StampWidth = req.StampWidth
+	org.VerificationRedactions = redactions
+	return nil
+}
+
+// verificationRedactions checks and de-duplicates the redacted verification fields
+func verificationRedactions(fields []string) ([]string, error) {
+	redactions := make([]string, 0, len(fields))
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(verificationFields, field) {
+			return nil, fmt.Errorf("%w: %q is not a redactable verification field", ErrInvalidOrganization, field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			redactions = append(redactions, field)
+		}
+	}
+	return redactions, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) Create(ctx context.Context, org *entities.Organization) error {
+	args := m.Called(ctx, org)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetByID(ctx context.Context, id string) (*entities.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetBySlug(ctx context.Context, slug string) (*entities.Organization, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) List(ctx context.Context) ([]*entities.Organization, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) Update(ctx context.Context, org *entities.Organization) error {
+	args := m.Called(ctx, org)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetMember(ctx context.Context, organizationID, userID string) (*entities.OrganizationMember, error) {
+	args := m.Called(ctx, organizationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]*entities.OrganizationMember, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListMemberships(ctx context.Context, userID string) ([]*entities.OrganizationMember, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) SaveMember(ctx context.Context, member *entities.OrganizationMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) DeleteMember(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) CreateKey(ctx context.Context, key *entities.OrganizationKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetActiveKey(ctx context.Context, organizationID string) (*entities.OrganizationKey, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OrganizationKey), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetKey(ctx context.Context, organizationID, keyID string) (*entities.OrganizationKey, error) {
+	args := m.Called(ctx, organizationID, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.OrganizationKey), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListKeys(ctx context.Context, organizationID string) ([]*entities.OrganizationKey, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.OrganizationKey), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) RotateKey(ctx context.Context, key *entities.OrganizationKey, retiredAt time.Time) error {
+	args := m.Called(ctx, key, retiredAt)
+	return args.Error(0)
+}
+
+func newTestOrganizationService(t *testing.T) (*OrganizationService, *MockOrganizationRepository, *MockUserRepository) {
+	orgRepo := new(MockOrganizationRepository)
+	userRepo := new(MockUserRepository)
+	service, err := NewOrganizationService(orgRepo, userRepo, &config.Config{
+		OrgKeyEncryptionKey: "organization-key-encryption-secret",
+		OrgKeySize:          2048,
+	})
+	require.NoError(t, err)
+	return service, orgRepo, userRepo
+}
+
+func TestOrganizationService_CreateOrganization(t *testing.T) {
+	service, orgRepo, userRepo := newTestOrganizationService(t)
+	ctx := context.Background()
+
+	admin := &entities.User{ID: "u1", Username: "dean"}
+	orgRepo.On("GetBySlug", ctx, "engineering").Return(nil, nil)
+	userRepo.On("GetByID", ctx, "u1").Return(admin, nil)
+	orgRepo.On("Create", ctx, mock.AnythingOfType("*entities.Organization")).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.Organization).ID = "org-1"
+	}).Return(nil)
+
+	var stored *entities.OrganizationKey
+	orgRepo.On("CreateKey", ctx, mock.AnythingOfType("*entities.OrganizationKey")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*entities.OrganizationKey)
+	}).Return(nil)
+	orgRepo.On("SaveMember", ctx, mock.MatchedBy(func(member *entities.OrganizationMember) bool {
+		return member.OrganizationID == "org-1" && member.UserID == "u1" && member.Role == entities.OrganizationRoleAdmin
+	})).Return(nil)
+
+	org, err := service.CreateOrganization(ctx, &OrganizationRequest{
+		Slug:                " Engineering ",
+		Name:                "Faculty of Engineering",
+		IssuerName:          "Dean of Engineering",
+		VerificationBaseURL: "https://verify.eng.example.edu/",
+		AdminUserID:         "u1",
+		// Repeated fields are kept once
+		VerificationRedactions: []string{"filename", " filename", "document_hash"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "engineering", org.Slug)
+	assert.Equal(t, "https://verify.eng.example.edu", org.VerificationBaseURL)
+	assert.Equal(t, []string{"filename", "document_hash"}, org.VerificationRedactions)
+	assert.True(t, org.IsActive)
+
+	// The private key is stored encrypted and still signs once loaded
+	require.NotNil(t, stored)
+	assert.Equal(t, "org-1", stored.OrganizationID)
+	assert.NotContains(t, stored.PrivateKey, "PRIVATE KEY")
+
+	orgRepo.On("GetByID", ctx, "org-1").Return(org, nil)
+	orgRepo.On("GetActiveKey", ctx, "org-1").Return(stored, nil)
+	signer, keyID, err := service.SignerFor(ctx, "org-1")
+	require.NoError(t, err)
+	assert.Equal(t, stored.KeyID, keyID)
+
+	hash := sha256.Sum256([]byte("document"))
+	signature, err := signer.SignDocument(hash[:])
+	require.NoError(t, err)
+
+	orgRepo.On("GetKey", ctx, "org-1", stored.KeyID).Return(stored, nil)
+	verifier, err := service.VerifierFor(ctx, "org-1", stored.KeyID)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.VerifySignature(hash[:], signature))
+	orgRepo.AssertExpectations(t)
+}
+
+func TestOrganizationService_CreateOrganization_Validation(t *testing.T) {
+	service, orgRepo, _ := newTestOrganizationService(t)
+	ctx := context.Background()
+
+	_, err := service.CreateOrganization(ctx, &OrganizationRequest{Slug: "Bad Slug!", Name: "Bad"})
+	assert.ErrorIs(t, err, ErrInvalidOrganization)
+
+	orgRepo.On("GetBySlug", ctx, "law").Return(&entities.Organization{ID: "org-2", Slug: "law"}, nil)
+	_, err = service.CreateOrganization(ctx, &OrganizationRequest{Slug: "law", Name: "Law"})
+	assert.ErrorIs(t, err, ErrOrganizationExists)
+
+	orgRepo.On("GetBySlug", ctx, "arts").Return(nil, nil)
+	_, err = service.CreateOrganization(ctx, &OrganizationRequest{Slug: "arts", Name: "Arts", VerificationBaseURL: "ftp://arts"})
+	assert.ErrorIs(t, err, ErrInvalidOrganization)
+
+	_, err = service.CreateOrganization(ctx, &OrganizationRequest{Slug: "arts", Name: "Arts", VerificationRedactions: []string{"status"}})
+	assert.ErrorIs(t, err, ErrInvalidOrganization)
+}
+
+func TestOrganizationService_ResolveMembership(t *testing.T) {
+	service, orgRepo, _ := newTestOrganizationService(t)
+	ctx := context.Background()
+
+	member := &entities.OrganizationMember{ID: "m1", OrganizationID: "org-1", UserID: "u1", Role: entities.OrganizationRoleMember}
+	orgRepo.On("ListMemberships", ctx, "u1").Return([]*entities.OrganizationMember{member}, nil)
+	orgRepo.On("ListMemberships", ctx, "u2").Return([]*entities.OrganizationMember{
+		{OrganizationID: "org-1", UserID: "u2"}, {OrganizationID: "org-2", UserID: "u2"},
+	}, nil)
+	orgRepo.On("GetByID", ctx, "org-1").Return(&entities.Organization{ID: "org-1", IsActive: true}, nil)
+	orgRepo.On("GetByID", ctx, "org-2").Return(&entities.Organization{ID: "org-2", IsActive: false}, nil)
+	orgRepo.On("GetMember", ctx, "org-1", "u1").Return(member, nil)
+	orgRepo.On("GetMember", ctx, "org-1", "u3").Return(nil, nil)
+
+	// A single membership is used without naming the organization
+	resolved, err := service.ResolveMembership(ctx, "u1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "org-1", resolved.OrganizationID)
+
+	// Several memberships need an explicit choice
+	resolved, err = service.ResolveMembership(ctx, "u2", "")
+	require.NoError(t, err)
+	assert.Nil(t, resolved)
+
+	_, err = service.ResolveMembership(ctx, "u3", "org-1")
+	assert.ErrorIs(t, err, ErrNotOrganizationMember)
+
+	_, err = service.ResolveMembership(ctx, "u2", "org-2")
+	assert.ErrorIs(t, err, ErrOrganizationInactive)
+}
+
+func TestOrganizationService_MemberAdministration(t *testing.T) {
+	service, orgRepo, userRepo := newTestOrganizationService(t)
+	ctx := context.Background()
+
+	org := &entities.Organization{ID: "org-1", IsActive: true}
+	admin := &entities.OrganizationMember{ID: "m1", OrganizationID: "org-1", UserID: "u1", Role: entities.OrganizationRoleAdmin}
+	orgRepo.On("GetByID", ctx, "org-1").Return(org, nil)
+	orgRepo.On("GetMember", ctx, "org-1", "u1").Return(admin, nil)
+	orgRepo.On("GetMember", ctx, "org-1", "u2").Return(nil, nil)
+	orgRepo.On("ListMembers", ctx, "org-1").Return([]*entities.OrganizationMember{admin}, nil)
+	userRepo.On("GetByID", ctx, "u2").Return(&entities.User{ID: "u2"}, nil)
+	orgRepo.On("SaveMember", ctx, mock.AnythingOfType("*entities.OrganizationMember")).Return(nil)
+
+	member, err := service.SetMember(ctx, "u1", "org-1", "u2", "Member")
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrganizationRoleMember, member.Role)
+
+	// Plain members and outsiders cannot administer the organization
+	_, err = service.SetMember(ctx, "u2", "org-1", "u1", entities.OrganizationRoleMember)
+	assert.ErrorIs(t, err, ErrOrganizationAccess)
+
+	// The last administrator stays
+	err = service.RemoveMember(ctx, "u1", "org-1", "u1")
+	assert.ErrorIs(t, err, ErrInvalidOrganization)
+
+	// Platform administrators manage every organization
+	service.SetPermissionChecker(stubPermissionChecker{entities.PermissionUserManage: true})
+	orgRepo.On("GetMember", ctx, "org-1", "root").Return(nil, nil)
+	members, err := service.ListMembers(ctx, "root", "org-1")
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrRoleNotFound     = errors.New("role not found")
+	ErrRoleExists       = errors.New("role already exists")
+	ErrRoleInUse        = errors.New("role is assigned to users")
+	ErrBuiltInRole      = errors.New("built-in role cannot be changed this way")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// roleNamePattern keeps role names usable in URLs and configuration
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// builtInRoles are created at startup when missing. The admin role always holds
+// every permission so that nobody can lock administrators out.
+var builtInRoles = []entities.Role{
+	{
+		Name:        entities.RoleAdmin,
+		Description: "Full access to every operation",
+		Permissions: entities.Permissions,
+	},
+	{
+		Name:        entities.RoleUser,
+		Description: "Signs and revokes their own documents and reads how they were verified",
+		Permissions: []string{entities.PermissionDocumentSign, entities.PermissionDocumentRevoke, entities.PermissionAuditRead},
+	},
+}
+
+// RoleRequest describes a new or updated role
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RBACService manages roles and answers permission checks
+type RBACService struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	// Roles are read on every authorized request, so they are cached briefly;
+	// changes made through this instance take effect immediately
+	mu       sync.Mutex
+	cache    map[string]*entities.Role
+	cachedAt time.Time
+}
+
+// NewRBACService creates a new RBAC service
+func NewRBACService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, cfg *config.Config) *RBACService {
+	return &RBACService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		cacheTTL: cfg.RBACCacheTTL,
+		now:      time.Now,
+	}
+}
+
+// EnsureBuiltInRoles creates the built-in roles that do not exist yet and gives
+// the admin role any permission added since it was created
+func (s *RBACService) EnsureBuiltInRoles(ctx context.Context) error {
+	for _, builtIn := range builtInRoles {
+		role, err := s.roleRepo.GetByName(ctx, builtIn.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get role %s: %w", builtIn.Name, err)
+		}
+
+		now := s.now()
+		if role == nil {
+			role = &entities.Role{
+				Name:        builtIn.Name,
+				Description: builtIn.Description,
+				Permissions: append([]string(nil), builtIn.Permissions...),
+				BuiltIn:     true,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			if err := s.roleRepo.Create(ctx, role); err != nil {
+				return fmt.Errorf("failed to create role %s: %w", builtIn.Name, err)
+			}
+			continue
+		}
+
+		if builtIn.Name == entities.RoleAdmin && len(missingPermissions(role, entities.Permissions)) > 0 {
+			role.Permissions = append(role.Permissions, missingPermissions(role, entities.Permissions)...)
+			role.UpdatedAt = now
+			if err := s.roleRepo.Update(ctx, role); err != nil {
+				return fmt.Errorf("failed to update role %s: %w", builtIn.Name, err)
+			}
+		}
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// HasPermission reports whether the role grants permission. Unknown roles grant nothing.
+func (s *RBACService) HasPermission(ctx context.Context, roleName, permission string) (bool, error) {
+	role, err := s.cachedRole(ctx, roleName)
+	if err != nil {
+		return false, err
+	}
+	return role != nil && role.HasPermission(permission), nil
+}
+
+// UserHasPermission reports whether the user's current role grants permission
+func (s *RBACService) UserHasPermission(ctx context.Context, userID, permission string) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.IsActive {
+		return false, nil
+	}
+	return s.HasPermission(ctx, user.Role, permission)
+}
+
+// ListRoles returns every role
+func (s *RBACService) ListRoles(ctx context.Context) ([]*entities.Role, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRole returns the role with the name
+func (s *RBACService) GetRole(ctx context.Context, name string) (*entities.Role, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole adds a custom role
+func (s *RBACService) CreateRole(ctx context.Context, req *RoleRequest) (*entities.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 2-50 lowercase letters, digits, '-' or '_' and start with a letter", ErrInvalidRole)
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing role: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrRoleExists
+	}
+
+	now := s.now()
+	role := &entities.Role{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Permissions: permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+// UpdateRole replaces a role's description and permissions. The admin role's
+// permissions cannot be changed.
+func (s *RBACService) UpdateRole(ctx context.Context, name string, req *RoleRequest) (*entities.Role, error) {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == entities.RoleAdmin && len(missingPermissions(&entities.Role{Permissions: permissions}, entities.Permissions)) > 0 {
+		return nil, fmt.Errorf("%w: the admin role keeps every permission", ErrBuiltInRole)
+	}
+
+	role.Description = strings.TrimSpace(req.Description)
+	role.Permissions = permissions
+	role.UpdatedAt = s.now()
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+// DeleteRole removes a custom role that no user holds
+func (s *RBACService) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return ErrBuiltInRole
+	}
+
+	users, err := s.userRepo.ListByRole(ctx, role.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list role users: %w", err)
+	}
+	if len(users) > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// ListRoleUsers returns the users assigned the role
+func (s *RBACService) ListRoleUsers(ctx context.Context, name string) ([]*entities.User, error) {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.ListByRole(ctx, role.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role users: %w", err)
+	}
+	return users, nil
+}
+
+// AssignRole gives the user the role and returns the user with the role they had before
+func (s *RBACService) AssignRole(ctx context.Context, userID, roleName string) (*entities.User, string, error) {
+	role, err := s.GetRole(ctx, strings.ToLower(strings.TrimSpace(roleName)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	previous := user.Role
+	if previous == role.Name {
+		return user, previous, nil
+	}
+
+	// Removing the last administrator would leave nobody able to assign roles
+	if previous == entities.RoleAdmin {
+		admins, err := s.userRepo.ListByRole(ctx, entities.RoleAdmin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list administrators: %w", err)
+		}
+		if countActive(admins) <= 1 && user.IsActive {
+			return nil, "", fmt.Errorf("%w: cannot remove the last active administrator", ErrInvalidRole)
+		}
+	}
+
+	user.Role = role.Name
+	user.UpdatedAt = s.now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, "", fmt.Errorf("failed to assign role: %w", err)
+	}
+	return user, previous, nil
+}
+
+func (s *RBACService) cachedRole(ctx context.Context, name string) (*entities.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache == nil || s.now().Sub(s.cachedAt) >= s.cacheTTL {
+		roles, err := s.roleRepo.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load roles: %w", err)
+		}
+		s.cache = make(map[string]*entities.Role, len(roles))
+		for _, role := range roles {
+			s.cache[role.Name] = role
+		}
+		s.cachedAt = s.now()
+	}
+	return s.cache[name], nil
+}
+
+func (s *RBACService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		known := false
+		for _, supported := range entities.Permissions {
+			if permission == supported {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown permission %q", ErrInvalidRole, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			normalized = append(normalized, permission)
+		}
+	}
+	return normalized, nil
+}
+
+func missingPermissions(role *entities.Role, permissions []string) []string {
+	var missing []string
+	for _, permission := range permissions {
+		if !role.HasPermission(permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+func countActive(users []*entities.User) int {
+	count := 0
+	for _, user := range users {
+		if user.IsActive {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) Create(ctx context.Context, role *entities.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) GetByName(ctx context.Context, name string) (*entities.Role, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) List(ctx context.Context) ([]*entities.Role, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) Update(ctx context.Context, role *entities.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTestRBACService() (*RBACService, *MockRoleRepository, *MockUserRepository) {
+	roleRepo := new(MockRoleRepository)
+	userRepo := new(MockUserRepository)
+	return NewRBACService(roleRepo, userRepo, &config.Config{RBACCacheTTL: time.Minute}), roleRepo, userRepo
+}
+
+func TestRBACService_EnsureBuiltInRoles(t *testing.T) {
+	service, roleRepo, _ := newTestRBACService()
+	ctx := context.Background()
+
+	// The admin role predates a permission; the user role is missing
+	admin := &entities.Role{ID: "r1", Name: "admin", BuiltIn: true, Permissions: []string{entities.PermissionDocumentSign}}
+	roleRepo.On("GetByName", ctx, "admin").Return(admin, nil)
+	roleRepo.On("GetByName", ctx, "user").Return(nil, nil)
+	roleRepo.On("Update", ctx, admin).Return(nil)
+	roleRepo.On("Create", ctx, mock.MatchedBy(func(role *entities.Role) bool {
+		return role.Name == "user" && role.BuiltIn && role.HasPermission(entities.PermissionDocumentSign)
+	})).Return(nil)
+
+	require.NoError(t, service.EnsureBuiltInRoles(ctx))
+	assert.ElementsMatch(t, entities.Permissions, admin.Permissions)
+	roleRepo.AssertExpectations(t)
+}
+
+func TestRBACService_HasPermission(t *testing.T) {
+	service, roleRepo, userRepo := newTestRBACService()
+	ctx := context.Background()
+
+	roleRepo.On("List", ctx).Return([]*entities.Role{
+		{Name: "user", Permissions: []string{entities.PermissionDocumentSign}},
+		{Name: "auditor", Permissions: []string{entities.PermissionAuditRead, entities.PermissionDocumentReadAny}},
+	}, nil).Once()
+
+	allowed, err := service.HasPermission(ctx, "user", entities.PermissionDocumentSign)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.HasPermission(ctx, "user", entities.PermissionUserManage)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Unknown roles grant nothing; roles are served from the cache
+	allowed, err = service.HasPermission(ctx, "ghost", entities.PermissionDocumentSign)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	roleRepo.AssertNumberOfCalls(t, "List", 1)
+
+	userRepo.On("GetByID", ctx, "u1").Return(&entities.User{ID: "u1", Role: "auditor", IsActive: true}, nil)
+	userRepo.On("GetByID", ctx, "u2").Return(&entities.User{ID: "u2", Role: "auditor"}, nil)
+
+	allowed, err = service.UserHasPermission(ctx, "u1", entities.PermissionDocumentReadAny)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.UserHasPermission(ctx, "u2", entities.PermissionDocumentReadAny)
+	require.NoError(t, err)
+	assert.False(t, allowed, "inactive users hold no permissions")
+}
+
+func TestRBACService_CreateRole(t *testing.T) {
+	service, roleRepo, _ := newTestRBACService()
+	ctx := context.Background()
+
+	roleRepo.On("GetByName", ctx, "registrar").Return(nil, nil)
+	roleRepo.On("Create", ctx, mock.AnythingOfType("*entities.Role")).Return(nil)
+
+	role, err := service.CreateRole(ctx, &RoleRequest{
+		Name:        " Registrar ",
+		Permissions: []string{"document:sign", "DOCUMENT:READ:ANY", "document:sign"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "registrar", role.Name)
+	assert.Equal(t, []string{entities.PermissionDocumentSign, entities.PermissionDocumentReadAny}, role.Permissions)
+	assert.False(t, role.BuiltIn)
+
+	_, err = service.CreateRole(ctx, &RoleRequest{Name: "registrar!", Permissions: []string{"document:sign"}})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = service.CreateRole(ctx, &RoleRequest{Name: "clerk", Permissions: []string{"document:destroy"}})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+}
+
+func TestRBACService_UpdateAdminKeepsEveryPermission(t *testing.T) {
+	service, roleRepo, _ := newTestRBACService()
+	ctx := context.Background()
+
+	admin := &entities.Role{ID: "r1", Name: "admin", BuiltIn: true, Permissions: append([]string(nil), entities.Permissions...)}
+	roleRepo.On("GetByName", ctx, "admin").Return(admin, nil)
+
+	_, err := service.UpdateRole(ctx, "admin", &RoleRequest{Permissions: []string{entities.PermissionDocumentSign}})
+	assert.ErrorIs(t, err, ErrBuiltInRole)
+	roleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRBACService_DeleteRole(t *testing.T) {
+	service, roleRepo, userRepo := newTestRBACService()
+	ctx := context.Background()
+
+	roleRepo.On("GetByName", ctx, "user").Return(&entities.Role{ID: "r1", Name: "user", BuiltIn: true}, nil)
+	roleRepo.On("GetByName", ctx, "registrar").Return(&entities.Role{ID: "r2", Name: "registrar"}, nil)
+	roleRepo.On("GetByName", ctx, "clerk").Return(&entities.Role{ID: "r3", Name: "clerk"}, nil)
+	userRepo.On("ListByRole", ctx, "registrar").Return([]*entities.User{{ID: "u1"}}, nil)
+	userRepo.On("ListByRole", ctx, "clerk").Return([]*entities.User{}, nil)
+	roleRepo.On("Delete", ctx, "r3").Return(nil)
+
+	assert.ErrorIs(t, service.DeleteRole(ctx, "user"), ErrBuiltInRole)
+	assert.ErrorIs(t, service.DeleteRole(ctx, "registrar"), ErrRoleInUse)
+	assert.NoError(t, service.DeleteRole(ctx, "clerk"))
+	roleRepo.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestRBACService_AssignRole(t *testing.T) {
+	service, roleRepo, userRepo := newTestRBACService()
+	ctx := context.Background()
+
+	roleRepo.On("GetByName", ctx, "registrar").Return(&entities.Role{ID: "r2", Name: "registrar"}, nil)
+	roleRepo.On("GetByName", ctx, "ghost").Return(nil, nil)
+
+	user := &entities.User{ID: "u1", Role: "user", IsActive: true}
+	userRepo.On("GetByID", ctx, "u1").Return(user, nil)
+	userRepo.On("Update", ctx, user).Return(nil)
+
+	updated, previous, err := service.AssignRole(ctx, "u1", "registrar")
+	require.NoError(t, err)
+	assert.Equal(t, "user", previous)
+	assert.Equal(t, "registrar", updated.Role)
+
+	_, _, err = service.AssignRole(ctx, "u1", "ghost")
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+}
+
+func TestRBACService_AssignRole_LastAdmin(t *testing.T) {
+	service, roleRepo, userRepo := newTestRBACService()
+	ctx := context.Background()
+
+	admin := &entities.User{ID: "a1", Role: "admin", IsActive: true}
+	roleRepo.On("GetByName", ctx, "user").Return(&entities.Role{ID: "r1", Name: "user"}, nil)
+	userRepo.On("GetByID", ctx, "a1").Return(admin, nil)
+	userRepo.On("ListByRole", ctx, "admin").Return([]*entities.User{admin, {ID: "a2", Role: "admin"}}, nil)
+
+	_, _, err := service.AssignRole(ctx, "a1", "user")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	assert.Equal(t, "admin", admin.Role)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
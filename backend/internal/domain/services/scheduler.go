@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/infrastructure/scheduler"
+)
+
+const defaultSchedulerTick = 30 * time.Second
+
+var (
+	ErrMaintenanceTaskNotFound = errors.New("maintenance task not found")
+	ErrMaintenanceTaskRunning  = errors.New("maintenance task is already running")
+)
+
+// MaintenanceTaskFunc performs a maintenance task and summarises what it did
+type MaintenanceTaskFunc func(ctx context.Context) (string, error)
+
+// MaintenanceTaskInfo describes a registered maintenance task
+type MaintenanceTaskInfo struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	// NextRunAt is empty for tasks that only run on demand
+	NextRunAt *time.Time               `json:"next_run_at,omitempty"`
+	LastRun   *entities.MaintenanceRun `json:"last_run,omitempty"`
+}
+
+type maintenanceTask struct {
+	name     string
+	spec     string
+	schedule scheduler.Schedule
+	run      MaintenanceTaskFunc
+	next     time.Time
+}
+
+// Scheduler runs maintenance tasks on cron-like schedules. Every replica runs a
+// scheduler; a database lock per task and the recorded slot of the last run make
+// sure each slot is executed by only one of them.
+type Scheduler struct {
+	runRepo  repositories.MaintenanceRunRepository
+	lockRepo repositories.LockRepository
+	enabled  bool
+	tick     time.Duration
+	node     string
+
+	mu    sync.Mutex
+	tasks map[string]*maintenanceTask
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler configured from the application config
+func NewScheduler(runRepo repositories.MaintenanceRunRepository, lockRepo repositories.LockRepository, cfg *config.Config) *Scheduler {
+	tick := cfg.SchedulerTick
+	if tick <= 0 {
+		tick = defaultSchedulerTick
+	}
+
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		runRepo:  runRepo,
+		lockRepo: lockRepo,
+		enabled:  cfg.SchedulerEnabled,
+		tick:     tick,
+		node:     fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+		tasks:    make(map[string]*maintenanceTask),
+	}
+}
+
+// Register adds a task with its schedule spec; a task with an empty spec only runs
+// when started through RunNow. Register all tasks before Start.
+func (s *Scheduler) Register(name, spec string, run MaintenanceTaskFunc) error {
+	task := &maintenanceTask{name: name, spec: spec, run: run}
+	if spec != "" {
+		schedule, err := scheduler.Parse(spec)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for %s: %w", name, err)
+		}
+		task.schedule = schedule
+		task.next = schedule.Next(time.Now())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[name] = task
+	return nil
+}
+
+// Start launches the scheduling loop; it is a no-op when the scheduler is disabled
+func (s *Scheduler) Start(ctx context.Context) {
+	if !s.enabled {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunDue(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// Stop signals the loop to exit and waits for a running task to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// RunDue runs every task whose next slot is at or before now. A task that fell
+// behind runs once for its latest missed slot rather than once per slot.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) {
+	for _, task := range s.sortedTasks() {
+		s.mu.Lock()
+		slot := task.next
+		due := !slot.IsZero() && !slot.After(now)
+		if due {
+			task.next = task.schedule.Next(now)
+		}
+		s.mu.Unlock()
+
+		if !due {
+			continue
+		}
+		if err := s.runSlot(ctx, task, slot); err != nil {
+			fmt.Printf("Warning: Maintenance task %s failed to run: %v\n", task.name, err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runSlot runs a scheduled slot unless another replica holds the task or already ran it
+func (s *Scheduler) runSlot(ctx context.Context, task *maintenanceTask, slot time.Time) error {
+	release, err := s.lockRepo.TryLock(ctx, maintenanceLockName(task.name))
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		return nil
+	}
+	defer release()
+
+	slot = slot.UTC()
+	latest, err := s.runRepo.GetLatest(ctx, task.name, entities.MaintenanceTriggerSchedule)
+	if err != nil {
+		return err
+	}
+	if latest != nil && !latest.ScheduledFor.Before(slot) {
+		return nil
+	}
+
+	_, err = s.execute(ctx, task, entities.MaintenanceTriggerSchedule, slot)
+	return err
+}
+
+// RunNow runs a task immediately, outside its schedule, and returns the finished run
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*entities.MaintenanceRun, error) {
+	s.mu.Lock()
+	task, ok := s.tasks[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrMaintenanceTaskNotFound
+	}
+
+	release, err := s.lockRepo.TryLock(ctx, maintenanceLockName(name))
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, ErrMaintenanceTaskRunning
+	}
+	defer release()
+
+	return s.execute(ctx, task, entities.MaintenanceTriggerManual, time.Now().UTC())
+}
+
+func (s *Scheduler) execute(ctx context.Context, task *maintenanceTask, trigger string, slot time.Time) (*entities.MaintenanceRun, error) {
+	started := time.Now()
+	run := &entities.MaintenanceRun{
+		Task:         task.name,
+		Trigger:      trigger,
+		ScheduledFor: slot,
+		Status:       entities.MaintenanceRunRunning,
+		Node:         s.node,
+		StartedAt:    started,
+	}
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		return nil, err
+	}
+
+	result, err := task.run(ctx)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(started).Milliseconds()
+	run.Result = result
+	if err != nil {
+		run.Status = entities.MaintenanceRunFailed
+		run.Error = err.Error()
+		fmt.Printf("Warning: Maintenance task %s failed: %v\n", task.name, err)
+	} else {
+		run.Status = entities.MaintenanceRunSucceeded
+	}
+
+	// Record the outcome even when the run was cut short by shutdown
+	if err := s.runRepo.Update(context.Background(), run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Tasks describes the registered tasks with their next slot and latest run
+func (s *Scheduler) Tasks(ctx context.Context) ([]*MaintenanceTaskInfo, error) {
+	tasks := s.sortedTasks()
+	infos := make([]*MaintenanceTaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		runs, _, err := s.runRepo.List(ctx, task.name, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		info := &MaintenanceTaskInfo{Name: task.name, Schedule: task.spec}
+		s.mu.Lock()
+		if !task.next.IsZero() {
+			next := task.next
+			info.NextRunAt = &next
+		}
+		s.mu.Unlock()
+		if len(runs) > 0 {
+			info.LastRun = runs[0]
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// ListRuns returns the run history newest first, optionally for one task
+func (s *Scheduler) ListRuns(ctx context.Context, task string, limit, offset int) ([]*entities.MaintenanceRun, int64, error) {
+	if task != "" {
+		s.mu.Lock()
+		_, ok := s.tasks[task]
+		s.mu.Unlock()
+		if !ok {
+			return nil, 0, ErrMaintenanceTaskNotFound
+		}
+	}
+	return s.runRepo.List(ctx, task, limit, offset)
+}
+
+func (s *Scheduler) sortedTasks() []*maintenanceTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]*maintenanceTask, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].name < tasks[j].name })
+	return tasks
+}
+
+func maintenanceLockName(task string) string {
+	return "maintenance:" + task
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockMaintenanceRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceRunRepository) Create(ctx context.Context, run *entities.MaintenanceRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRunRepository) Update(ctx context.Context, run *entities.MaintenanceRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRunRepository) GetLatest(ctx context.Context, task, trigger string) (*entities.MaintenanceRun, error) {
+	args := m.Called(ctx, task, trigger)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.MaintenanceRun), args.Error(1)
+}
+
+func (m *MockMaintenanceRunRepository) List(ctx context.Context, task string, limit, offset int) ([]*entities.MaintenanceRun, int64, error) {
+	args := m.Called(ctx, task, limit, offset)
+	return args.Get(0).([]*entities.MaintenanceRun), args.Get(1).(int64), args.Error(2)
+}
+
+type MockLockRepository struct {
+	mock.Mock
+}
+
+func (m *MockLockRepository) TryLock(ctx context.Context, name string) (func(), error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(func()), args.Error(1)
+}
+
+func newTestScheduler(t *testing.T) (*Scheduler, *MockMaintenanceRunRepository, *MockLockRepository, *int) {
+	runRepo := new(MockMaintenanceRunRepository)
+	lockRepo := new(MockLockRepository)
+	s := NewScheduler(runRepo, lockRepo, &config.Config{SchedulerEnabled: true})
+
+	calls := 0
+	require.NoError(t, s.Register("cleanup", "@hourly", func(ctx context.Context) (string, error) {
+		calls++
+		return "cleaned", nil
+	}))
+	return s, runRepo, lockRepo, &calls
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	s, runRepo, lockRepo, calls := newTestScheduler(t)
+	ctx := context.Background()
+	slot := s.tasks["cleanup"].next
+
+	// Not due yet
+	s.RunDue(ctx, slot.Add(-time.Second))
+	assert.Equal(t, 0, *calls)
+
+	released := false
+	lockRepo.On("TryLock", ctx, "maintenance:cleanup").Return(func() { released = true }, nil)
+	runRepo.On("GetLatest", ctx, "cleanup", entities.MaintenanceTriggerSchedule).Return(nil, nil)
+	runRepo.On("Create", ctx, mock.AnythingOfType("*entities.MaintenanceRun")).Return(nil)
+	var finished *entities.MaintenanceRun
+	runRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.MaintenanceRun")).Run(func(args mock.Arguments) {
+		finished = args.Get(1).(*entities.MaintenanceRun)
+	}).Return(nil)
+
+	s.RunDue(ctx, slot.Add(time.Second))
+	assert.Equal(t, 1, *calls)
+	assert.True(t, released)
+	require.NotNil(t, finished)
+	assert.Equal(t, entities.MaintenanceRunSucceeded, finished.Status)
+	assert.Equal(t, "cleaned", finished.Result)
+	assert.Equal(t, entities.MaintenanceTriggerSchedule, finished.Trigger)
+	assert.True(t, finished.ScheduledFor.Equal(slot))
+	assert.Equal(t, slot.Add(time.Hour), s.tasks["cleanup"].next)
+
+	// The slot has been handled; the next tick does nothing
+	s.RunDue(ctx, slot.Add(time.Minute))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestScheduler_RunDue_SlotAlreadyRun(t *testing.T) {
+	s, runRepo, lockRepo, calls := newTestScheduler(t)
+	ctx := context.Background()
+	slot := s.tasks["cleanup"].next
+
+	// Another replica ran the slot before this one took the lock
+	lockRepo.On("TryLock", ctx, "maintenance:cleanup").Return(func() {}, nil)
+	runRepo.On("GetLatest", ctx, "cleanup", entities.MaintenanceTriggerSchedule).
+		Return(&entities.MaintenanceRun{ScheduledFor: slot.UTC()}, nil)
+
+	s.RunDue(ctx, slot)
+	assert.Equal(t, 0, *calls)
+	runRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestScheduler_RunDue_LockHeld(t *testing.T) {
+	s, runRepo, lockRepo, calls := newTestScheduler(t)
+	ctx := context.Background()
+	slot := s.tasks["cleanup"].next
+
+	lockRepo.On("TryLock", ctx, "maintenance:cleanup").Return(nil, nil)
+
+	s.RunDue(ctx, slot)
+	assert.Equal(t, 0, *calls)
+	runRepo.AssertNotCalled(t, "GetLatest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScheduler_RunNow(t *testing.T) {
+	s, runRepo, lockRepo, _ := newTestScheduler(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Register("key-check", "", func(ctx context.Context) (string, error) {
+		return "", errors.New("key is too old")
+	}))
+
+	lockRepo.On("TryLock", ctx, "maintenance:key-check").Return(func() {}, nil).Once()
+	runRepo.On("Create", ctx, mock.AnythingOfType("*entities.MaintenanceRun")).Return(nil)
+	runRepo.On("Update", mock.Anything, mock.AnythingOfType("*entities.MaintenanceRun")).Return(nil)
+
+	run, err := s.RunNow(ctx, "key-check")
+	require.NoError(t, err)
+	assert.Equal(t, entities.MaintenanceTriggerManual, run.Trigger)
+	assert.Equal(t, entities.MaintenanceRunFailed, run.Status)
+	assert.Equal(t, "key is too old", run.Error)
+	assert.NotNil(t, run.FinishedAt)
+
+	lockRepo.On("TryLock", ctx, "maintenance:key-check").Return(nil, nil).Once()
+	_, err = s.RunNow(ctx, "key-check")
+	assert.ErrorIs(t, err, ErrMaintenanceTaskRunning)
+
+	_, err = s.RunNow(ctx, "missing")
+	assert.ErrorIs(t, err, ErrMaintenanceTaskNotFound)
+}
+
+func TestScheduler_Tasks(t *testing.T) {
+	s, runRepo, _, _ := newTestScheduler(t)
+	ctx := context.Background()
+	require.NoError(t, s.Register("manual", "", func(ctx context.Context) (string, error) { return "", nil }))
+	assert.Error(t, s.Register("broken", "every hour", func(ctx context.Context) (string, error) { return "", nil }))
+
+	lastRun := &entities.MaintenanceRun{Task: "cleanup", Status: entities.MaintenanceRunSucceeded}
+	runRepo.On("List", ctx, "cleanup", 1, 0).Return([]*entities.MaintenanceRun{lastRun}, int64(1), nil)
+	runRepo.On("List", ctx, "manual", 1, 0).Return([]*entities.MaintenanceRun{}, int64(0), nil)
+
+	tasks, err := s.Tasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "cleanup", tasks[0].Name)
+	assert.NotNil(t, tasks[0].NextRunAt)
+	assert.Equal(t, lastRun, tasks[0].LastRun)
+	assert.Equal(t, "manual", tasks[1].Name)
+	assert.Nil(t, tasks[1].NextRunAt)
+	assert.Nil(t, tasks[1].LastRun)
+
+	_, _, err = s.ListRuns(ctx, "broken", 10, 0)
+	assert.ErrorIs(t, err, ErrMaintenanceTaskNotFound)
+}
+
+func TestVerificationLogRetentionTask(t *testing.T) {
+	logRepo := new(MockVerificationLogRepository)
+	logRepo.On("DeleteBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) > 29*24*time.Hour && time.Since(before) < 31*24*time.Hour
+	})).Return(int64(12), nil)
+
+	result, err := NewVerificationLogRetentionTask(logRepo, 30*24*time.Hour)(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "removed 12 verification logs", result)
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrUploadNotFound         = errors.New("upload not found")
+	ErrUploadAccess           = errors.New("access denied: upload belongs to different user")
+	ErrUploadExpired          = errors.New("upload has expired")
+	ErrUploadOffsetMismatch   = errors.New("upload offset does not match")
+	ErrUploadTooLarge         = errors.New("upload exceeds declared or maximum length")
+	ErrUploadChecksumMismatch = errors.New("upload checksum mismatch")
+	ErrUploadIncomplete       = errors.New("upload is not complete")
+	ErrInvalidUpload          = errors.New("invalid upload request")
+	ErrUploadQuotaExceeded    = errors.New("too many pending uploads from this client")
+)
+
+// UploadChecksumAlgorithms lists the digests accepted in the Upload-Checksum header
+var UploadChecksumAlgorithms = []string{"sha1", "sha256", "md5"}
+
+const uploadCleanupBatch = 100
+
+// uploadLock is a per-upload mutex that is dropped once nobody holds or waits for it
+type uploadLock struct {
+	sync.Mutex
+	refs int
+}
+
+// UploadScope identifies the flow and caller an upload belongs to
+type UploadScope struct {
+	Purpose    string
+	UserID     string
+	DocumentID string
+	// ClientIP caps the uploads anonymous callers may hold at once
+	ClientIP string
+}
+
+// UploadService stores resumable uploads chunk by chunk and removes abandoned ones
+type UploadService struct {
+	uploadRepo repositories.UploadSessionRepository
+	config     *config.Config
+
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService(uploadRepo repositories.UploadSessionRepository, config *config.Config) *UploadService {
+	return &UploadService{
+		uploadRepo: uploadRepo,
+		config:     config,
+		locks:      make(map[string]*uploadLock),
+	}
+}
+
+// MaxLength returns the largest upload that may be declared
+func (s *UploadService) MaxLength() int64 {
+	return s.config.MaxPDFSize
+}
+
+// CreateUpload registers an upload of the given length and reserves its storage file
+func (s *UploadService) CreateUpload(ctx context.Context, scope UploadScope, length int64, metadata map[string]string) (*entities.UploadSession, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: upload length must be positive", ErrInvalidUpload)
+	}
+	if s.config.MaxPDFSize > 0 && length > s.config.MaxPDFSize {
+		return nil, fmt.Errorf("%w: maximum is %d bytes", ErrUploadTooLarge, s.config.MaxPDFSize)
+	}
+
+	now := time.Now()
+	if scope.UserID == "" {
+		if err := s.checkClientQuota(ctx, scope.ClientIP, length, now); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &entities.UploadSession{
+		Purpose:    scope.Purpose,
+		UserID:     scope.UserID,
+		DocumentID: scope.DocumentID,
+		ClientIP:   scope.ClientIP,
+		Length:     length,
+		Metadata:   metadata,
+		Status:     entities.UploadStatusUploading,
+		ExpiresAt:  now.Add(s.expiry(scope.UserID)),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.uploadRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	if err := os.MkdirAll(s.uploadDir(), 0750); err != nil {
+		s.uploadRepo.Delete(ctx, session.ID)
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	file, err := os.OpenFile(s.uploadPath(session.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		s.uploadRepo.Delete(ctx, session.ID)
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+
+	return session, nil
+}
+
+// GetUpload retrieves an unexpired upload within the caller's scope
+func (s *UploadService) GetUpload(ctx context.Context, scope UploadScope, uploadID string) (*entities.UploadSession, error) {
+	session, err := s.uploadRepo.GetByID(ctx, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	if session == nil || session.Purpose != scope.Purpose || session.DocumentID != scope.DocumentID {
+		return nil, ErrUploadNotFound
+	}
+	if session.UserID != scope.UserID {
+		return nil, ErrUploadAccess
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return session, nil
+}
+
+// AppendChunk writes a chunk at the given offset. When checksum ("<algorithm> <base64 digest>")
+// is set the chunk is discarded unless it matches; without one, a partially received chunk is
+// kept so the client can resume from wherever the connection dropped.
+func (s *UploadService) AppendChunk(ctx context.Context, scope UploadScope, uploadID string, offset int64, chunk io.Reader, checksum string) (*entities.UploadSession, error) {
+	digest, expected, err := parseUploadChecksum(checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := s.lock(uploadID)
+	defer unlock()
+
+	session, err := s.GetUpload(ctx, scope, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if offset != session.Offset {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrUploadOffsetMismatch, session.Offset, offset)
+	}
+
+	file, err := os.OpenFile(s.uploadPath(session.ID), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	// Drop any bytes an interrupted request wrote past the committed offset
+	if err := file.Truncate(session.Offset); err != nil {
+		return nil, fmt.Errorf("failed to prepare upload file: %w", err)
+	}
+	if _, err := file.Seek(session.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to prepare upload file: %w", err)
+	}
+
+	var dst io.Writer = file
+	if digest != nil {
+		dst = io.MultiWriter(file, digest)
+	}
+
+	remaining := session.Length - session.Offset
+	written, copyErr := io.Copy(dst, io.LimitReader(chunk, remaining+1))
+	if written > remaining {
+		file.Truncate(session.Offset)
+		return nil, ErrUploadTooLarge
+	}
+	if digest != nil && (copyErr != nil || !bytes.Equal(digest.Sum(nil), expected)) {
+		file.Truncate(session.Offset)
+		if copyErr != nil {
+			return nil, fmt.Errorf("failed to read upload chunk: %w", copyErr)
+		}
+		return nil, ErrUploadChecksumMismatch
+	}
+
+	session.Offset += written
+	if session.Offset == session.Length {
+		session.Status = entities.UploadStatusCompleted
+	}
+	// Expiry is measured from the last activity so slow but steady uploads survive
+	session.UpdatedAt = time.Now()
+	session.ExpiresAt = session.UpdatedAt.Add(s.expiry(session.UserID))
+
+	// Persist progress even when the client disconnected part way through the chunk
+	saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.uploadRepo.Update(saveCtx, session); err != nil {
+		file.Truncate(offset)
+		return nil, fmt.Errorf("failed to update upload: %w", err)
+	}
+
+	if copyErr != nil {
+		return session, fmt.Errorf("failed to read upload chunk: %w", copyErr)
+	}
+	return session, nil
+}
+
+// OpenCompletedUpload opens a finished upload for reading; the caller closes the file
+func (s *UploadService) OpenCompletedUpload(ctx context.Context, scope UploadScope, uploadID string) (*os.File, *entities.UploadSession, error) {
+	session, err := s.GetUpload(ctx, scope, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.IsComplete() {
+		return nil, nil, ErrUploadIncomplete
+	}
+
+	file, err := os.Open(s.uploadPath(session.ID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	return file, session, nil
+}
+
+// TerminateUpload discards an upload within the caller's scope
+func (s *UploadService) TerminateUpload(ctx context.Context, scope UploadScope, uploadID string) error {
+	unlock := s.lock(uploadID)
+	defer unlock()
+
+	session, err := s.GetUpload(ctx, scope, uploadID)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, session.ID)
+}
+
+// ReleaseUpload removes an upload once the flow it fed has finished with it
+func (s *UploadService) ReleaseUpload(ctx context.Context, uploadID string) {
+	unlock := s.lock(uploadID)
+	defer unlock()
+
+	if err := s.remove(ctx, uploadID); err != nil {
+		fmt.Printf("Warning: Failed to remove upload %s: %v\n", uploadID, err)
+	}
+}
+
+// Start launches the background cleanup of expired uploads
+func (s *UploadService) Start(ctx context.Context) {
+	interval := s.config.UploadCleanup
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.CleanupExpired(ctx); err != nil {
+				fmt.Printf("Warning: Upload cleanup error: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the cleanup loop
+func (s *UploadService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// CleanupExpired deletes expired uploads and their files, returning how many were removed
+func (s *UploadService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		sessions, err := s.uploadRepo.GetExpired(ctx, time.Now(), uploadCleanupBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, session := range sessions {
+			if err := s.remove(ctx, session.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(sessions) < uploadCleanupBatch || ctx.Err() != nil {
+			return removed, nil
+		}
+	}
+}
+
+func (s *UploadService) remove(ctx context.Context, uploadID string) error {
+	if err := os.Remove(s.uploadPath(uploadID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove upload file: %w", err)
+	}
+	if err := s.uploadRepo.Delete(ctx, uploadID); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// lock serialises writes to one upload; a second PATCH waits and then fails the offset check
+func (s *UploadService) lock(uploadID string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[uploadID]
+	if !ok {
+		l = &uploadLock{}
+		s.locks[uploadID] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, uploadID)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// checkClientQuota keeps one client IP from holding more anonymous uploads,
+// or more declared bytes, than configured
+func (s *UploadService) checkClientQuota(ctx context.Context, clientIP string, length int64, now time.Time) error {
+	if s.config.UploadMaxPerIP <= 0 && s.config.UploadBytesPerIP <= 0 {
+		return nil
+	}
+	uploads, bytes, err := s.uploadRepo.CountUnexpired(ctx, clientIP, now)
+	if err != nil {
+		return fmt.Errorf("failed to count uploads: %w", err)
+	}
+	if s.config.UploadMaxPerIP > 0 && uploads >= int64(s.config.UploadMaxPerIP) {
+		return fmt.Errorf("%w: at most %d uploads at a time", ErrUploadQuotaExceeded, s.config.UploadMaxPerIP)
+	}
+	if s.config.UploadBytesPerIP > 0 && bytes+length > s.config.UploadBytesPerIP {
+		return fmt.Errorf("%w: at most %d bytes at a time", ErrUploadQuotaExceeded, s.config.UploadBytesPerIP)
+	}
+	return nil
+}
+
+// expiry is how long an upload survives without activity; anonymous uploads
+// get the shorter guest expiry
+func (s *UploadService) expiry(userID string) time.Duration {
+	if userID == "" && s.config.UploadGuestExpiry > 0 {
+		return s.config.UploadGuestExpiry
+	}
+	if s.config.UploadExpiry > 0 {
+		return s.config.UploadExpiry
+	}
+	return 24 * time.Hour
+}
+
+func (s *UploadService) uploadDir() string {
+	return filepath.Join(s.config.StorageDir, "uploads")
+}
+
+func (s *UploadService) uploadPath(uploadID string) string {
+	return filepath.Join(s.uploadDir(), filepath.Base(uploadID))
+}
+
+// parseUploadChecksum reads an Upload-Checksum value; an empty value disables verification
+func parseUploadChecksum(value string) (hash.Hash, []byte, error) {
+	if value == "" {
+		return nil, nil, nil
+	}
+
+	algorithm, encoded, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found {
+		return nil, nil, fmt.Errorf("%w: malformed checksum", ErrInvalidUpload)
+	}
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: checksum is not valid base64", ErrInvalidUpload)
+	}
+
+	switch strings.ToLower(algorithm) {
+	case "sha1":
+		return sha1.New(), expected, nil
+	case "sha256":
+		return sha256.New(), expected, nil
+	case "md5":
+		return md5.New(), expected, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported checksum algorithm %q", ErrInvalidUpload, algorithm)
+	}
+}
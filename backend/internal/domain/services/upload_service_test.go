@@ -0,0 +1,251 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+)
+
+type MockUploadSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockUploadSessionRepository) Create(ctx context.Context, session *entities.UploadSession) error {
+	args := m.Called(ctx, session)
+	if session.ID == "" {
+		session.ID = "11111111-1111-1111-1111-111111111111"
+	}
+	return args.Error(0)
+}
+
+func (m *MockUploadSessionRepository) GetByID(ctx context.Context, id string) (*entities.UploadSession, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UploadSession), args.Error(1)
+}
+
+func (m *MockUploadSessionRepository) Update(ctx context.Context, session *entities.UploadSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockUploadSessionRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUploadSessionRepository) CountUnexpired(ctx context.Context, clientIP string, now time.Time) (int64, int64, error) {
+	args := m.Called(ctx, clientIP, now)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUploadSessionRepository) GetExpired(ctx context.Context, before time.Time, limit int) ([]*entities.UploadSession, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]*entities.UploadSession), args.Error(1)
+}
+
+func sha256Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func newTestUploadService(t *testing.T, repo *MockUploadSessionRepository) (*UploadService, string) {
+	storageDir := t.TempDir()
+	return NewUploadService(repo, &config.Config{
+		StorageDir:   storageDir,
+		MaxPDFSize:   1 << 20,
+		UploadExpiry: time.Hour,
+	}), storageDir
+}
+
+func TestUploadService_CreateUpload(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.UploadSession")).Return(nil)
+	service, storageDir := newTestUploadService(t, repo)
+
+	scope := UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123"}
+	session, err := service.CreateUpload(context.Background(), scope, 10, map[string]string{"filename": "scan.pdf"})
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.UploadStatusUploading, session.Status)
+	assert.Equal(t, "user-123", session.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Minute)
+	assert.FileExists(t, filepath.Join(storageDir, "uploads", session.ID))
+
+	_, err = service.CreateUpload(context.Background(), scope, 2<<20, nil)
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, err = service.CreateUpload(context.Background(), scope, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+}
+
+func TestUploadService_CreateUpload_Anonymous(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.UploadSession")).Return(nil)
+	repo.On("CountUnexpired", mock.Anything, "203.0.113.7", mock.Anything).Return(int64(1), int64(300), nil)
+	repo.On("CountUnexpired", mock.Anything, "203.0.113.8", mock.Anything).Return(int64(3), int64(30), nil)
+	storageDir := t.TempDir()
+	service := NewUploadService(repo, &config.Config{
+		StorageDir:        storageDir,
+		MaxPDFSize:        1 << 20,
+		UploadExpiry:      time.Hour,
+		UploadGuestExpiry: 10 * time.Minute,
+		UploadMaxPerIP:    3,
+		UploadBytesPerIP:  1000,
+	})
+	ctx := context.Background()
+	scope := UploadScope{Purpose: entities.UploadPurposeVerify, DocumentID: "doc-123", ClientIP: "203.0.113.7"}
+
+	session, err := service.CreateUpload(ctx, scope, 700, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", session.ClientIP)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), session.ExpiresAt, time.Minute)
+
+	// The declared bytes count against the client's total
+	_, err = service.CreateUpload(ctx, scope, 701, nil)
+	assert.ErrorIs(t, err, ErrUploadQuotaExceeded)
+
+	scope.ClientIP = "203.0.113.8"
+	_, err = service.CreateUpload(ctx, scope, 10, nil)
+	assert.ErrorIs(t, err, ErrUploadQuotaExceeded)
+
+	// Signed-in users are not capped per IP; the mock reuses the upload ID
+	require.NoError(t, os.Remove(filepath.Join(storageDir, "uploads", session.ID)))
+	_, err = service.CreateUpload(ctx, UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123", ClientIP: "203.0.113.8"}, 10, nil)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "CountUnexpired", 3)
+}
+
+func TestUploadService_AppendChunk(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.UploadSession")).Return(nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*entities.UploadSession")).Return(nil)
+	service, _ := newTestUploadService(t, repo)
+	ctx := context.Background()
+
+	content := []byte("%PDF-1.4 resumable content")
+	scope := UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123"}
+	session, err := service.CreateUpload(ctx, scope, int64(len(content)), nil)
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+	first, rest := content[:10], content[10:]
+	session, err = service.AppendChunk(ctx, scope, session.ID, 0, bytes.NewReader(first), sha256Checksum(first))
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), session.Offset)
+	assert.False(t, session.IsComplete())
+
+	// A resent chunk must be rejected rather than appended twice
+	_, err = service.AppendChunk(ctx, scope, session.ID, 0, bytes.NewReader(first), "")
+	assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+
+	// A corrupted chunk is discarded and the offset stays put
+	_, err = service.AppendChunk(ctx, scope, session.ID, 10, bytes.NewReader([]byte("corrupted")), sha256Checksum(rest))
+	assert.ErrorIs(t, err, ErrUploadChecksumMismatch)
+	assert.Equal(t, int64(10), session.Offset)
+
+	_, _, err = service.OpenCompletedUpload(ctx, scope, session.ID)
+	assert.ErrorIs(t, err, ErrUploadIncomplete)
+
+	session, err = service.AppendChunk(ctx, scope, session.ID, 10, bytes.NewReader(rest), sha256Checksum(rest))
+	require.NoError(t, err)
+	assert.True(t, session.IsComplete())
+
+	file, _, err := service.OpenCompletedUpload(ctx, scope, session.ID)
+	require.NoError(t, err)
+	defer file.Close()
+	stored, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+}
+
+func TestUploadService_AppendChunk_Limits(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.UploadSession")).Return(nil)
+	service, _ := newTestUploadService(t, repo)
+	ctx := context.Background()
+
+	scope := UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123"}
+	session, err := service.CreateUpload(ctx, scope, 4, nil)
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+	_, err = service.AppendChunk(ctx, scope, session.ID, 0, bytes.NewReader([]byte("too long")), "")
+	assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+	_, err = service.AppendChunk(ctx, scope, session.ID, 0, bytes.NewReader([]byte("data")), "crc32 AAAA")
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUploadService_GetUpload_Scope(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	service, _ := newTestUploadService(t, repo)
+	ctx := context.Background()
+
+	repo.On("GetByID", mock.Anything, "sign-upload").Return(&entities.UploadSession{
+		ID:        "sign-upload",
+		Purpose:   entities.UploadPurposeSign,
+		UserID:    "user-123",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	repo.On("GetByID", mock.Anything, "expired-upload").Return(&entities.UploadSession{
+		ID:         "expired-upload",
+		Purpose:    entities.UploadPurposeVerify,
+		DocumentID: "doc-1",
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	}, nil)
+	repo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+
+	_, err := service.GetUpload(ctx, UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123"}, "sign-upload")
+	assert.NoError(t, err)
+
+	_, err = service.GetUpload(ctx, UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-456"}, "sign-upload")
+	assert.ErrorIs(t, err, ErrUploadAccess)
+
+	// A signing upload cannot be used to verify, and vice versa
+	_, err = service.GetUpload(ctx, UploadScope{Purpose: entities.UploadPurposeVerify, DocumentID: "doc-1"}, "sign-upload")
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+
+	_, err = service.GetUpload(ctx, UploadScope{Purpose: entities.UploadPurposeVerify, DocumentID: "doc-1"}, "expired-upload")
+	assert.ErrorIs(t, err, ErrUploadExpired)
+
+	_, err = service.GetUpload(ctx, UploadScope{Purpose: entities.UploadPurposeSign, UserID: "user-123"}, "missing")
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+}
+
+func TestUploadService_CleanupExpired(t *testing.T) {
+	repo := new(MockUploadSessionRepository)
+	service, storageDir := newTestUploadService(t, repo)
+
+	uploadDir := filepath.Join(storageDir, "uploads")
+	require.NoError(t, os.MkdirAll(uploadDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(uploadDir, "stale"), []byte("partial"), 0640))
+
+	repo.On("GetExpired", mock.Anything, mock.AnythingOfType("time.Time"), uploadCleanupBatch).
+		Return([]*entities.UploadSession{{ID: "stale"}, {ID: "already-gone"}}, nil)
+	repo.On("Delete", mock.Anything, "stale").Return(nil)
+	repo.On("Delete", mock.Anything, "already-gone").Return(nil)
+
+	removed, err := service.CleanupExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.NoFileExists(t, filepath.Join(uploadDir, "stale"))
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrInvalidUserRequest  = errors.New("invalid user request")
+	ErrInvitationNotFound  = errors.New("invitation not found")
+	ErrInvalidInvitation   = errors.New("invalid or expired invitation")
+	ErrInvitationRequired  = errors.New("an invitation is required to register")
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrInvalidRegistration = errors.New("invalid registration mode")
+)
+
+// Registration modes for /api/auth/register
+const (
+	RegistrationOpen   = "open"
+	RegistrationInvite = "invite"
+	RegistrationClosed = "closed"
+)
+
+const (
+	defaultUserPageSize  = 20
+	maxUserPageSize      = 100
+	defaultInvitationTTL = 7 * 24 * time.Hour
+)
+
+// CreateUserRequest describes an account created by an admin. A temporary
+// password is generated when Password is empty.
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	FullName string `json:"full_name" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// UserPage is one page of a user search
+type UserPage struct {
+	Users    []*entities.User `json:"users"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// UserAdminService lets admins manage accounts and invitations
+type UserAdminService struct {
+	userRepo       repositories.UserRepository
+	sessionRepo    repositories.SessionRepository
+	invitationRepo repositories.InvitationRepository
+	rbac           *RBACService
+	invitationTTL  time.Duration
+	now            func() time.Time
+}
+
+// NewUserAdminService creates the service
+func NewUserAdminService(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, invitationRepo repositories.InvitationRepository, rbac *RBACService, cfg *config.Config) *UserAdminService {
+	ttl := cfg.InvitationTTL
+	if ttl <= 0 {
+		ttl = defaultInvitationTTL
+	}
+	return &UserAdminService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		invitationRepo: invitationRepo,
+		rbac:           rbac,
+		invitationTTL:  ttl,
+		now:            time.Now,
+	}
+}
+
+// ListUsers searches users by name or email, role and status
+func (s *UserAdminService) ListUsers(ctx context.Context, filter repositories.UserFilter) (*UserPage, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultUserPageSize
+	}
+	if filter.PageSize > maxUserPageSize {
+		filter.PageSize = maxUserPageSize
+	}
+
+	users, total, err := s.userRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return &UserPage{Users: users, Total: total, Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+// GetUser returns the user with the ID
+func (s *UserAdminService) GetUser(ctx context.Context, userID string) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// CreateUser adds an account and returns the generated temporary password, if any
+func (s *UserAdminService) CreateUser(ctx context.Context, req *CreateUserRequest) (*entities.User, string, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, "", err
+	}
+	role, err := s.role(ctx, req.Role)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.ensureAvailable(ctx, req.Username, email); err != nil {
+		return nil, "", err
+	}
+
+	password, temporary, err := choosePassword(req.Password)
+	if err != nil {
+		return nil, "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := s.now()
+	user := &entities.User{
+		Username:     req.Username,
+		PasswordHash: string(hashedPassword),
+		FullName:     req.FullName,
+		Email:        email,
+		Role:         role,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, "", fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, temporary, nil
+}
+
+// SetActive deactivates or reactivates an account. Deactivation ends the user's
+// sessions; admins cannot deactivate themselves or the last active admin.
+func (s *UserAdminService) SetActive(ctx context.Context, actorID, userID string, active bool) (*entities.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive == active {
+		return user, nil
+	}
+
+	if !active {
+		if user.ID == actorID {
+			return nil, fmt.Errorf("%w: you cannot deactivate your own account", ErrInvalidUserRequest)
+		}
+		if user.Role == entities.RoleAdmin {
+			admins, err := s.userRepo.ListByRole(ctx, entities.RoleAdmin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list administrators: %w", err)
+			}
+			if countActive(admins) <= 1 {
+				return nil, fmt.Errorf("%w: cannot deactivate the last active administrator", ErrInvalidUserRequest)
+			}
+		}
+	}
+
+	user.IsActive = active
+	user.UpdatedAt = s.now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if !active {
+		if _, err := s.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to end sessions: %w", err)
+		}
+	}
+	return user, nil
+}
+
+// ResetPassword sets a new password, generating a temporary one when password is
+// empty, and ends the user's sessions
+func (s *UserAdminService) ResetPassword(ctx context.Context, userID, password string) (*entities.User, string, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.ServiceAccount {
+		return nil, "", fmt.Errorf("%w: service accounts have no password", ErrInvalidUserRequest)
+	}
+
+	password, temporary, err := choosePassword(password)
+	if err != nil {
+		return nil, "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = s.now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, "", fmt.Errorf("failed to update password: %w", err)
+	}
+	if _, err := s.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return nil, "", fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return user, temporary, nil
+}
+
+// ForceLogout ends every session of the user and returns how many were ended
+func (s *UserAdminService) ForceLogout(ctx context.Context, userID string) (*entities.User, int64, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	ended, err := s.sessionRepo.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return user, ended, nil
+}
+
+// CreateInvitation invites an email address to register with a role and returns
+// the invitation token, which is only available now
+func (s *UserAdminService) CreateInvitation(ctx context.Context, actorID, email, role string) (*entities.Invitation, string, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, "", err
+	}
+	role, err = s.role(ctx, role)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing, err := s.userRepo.GetByEmail(ctx, email); err != nil {
+		return nil, "", fmt.Errorf("failed to check existing user: %w", err)
+	} else if existing != nil {
+		return nil, "", ErrUserAlreadyExists
+	}
+
+	token, err := generateSecret(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	now := s.now()
+	invitation := &entities.Invitation{
+		Email:     email,
+		Role:      role,
+		TokenHash: hashInvitationToken(token),
+		InvitedBy: actorID,
+		ExpiresAt: now.Add(s.invitationTTL),
+		CreatedAt: now,
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, "", fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return invitation, token, nil
+}
+
+// ListInvitations returns the invitations that have not been used
+func (s *UserAdminService) ListInvitations(ctx context.Context) ([]*entities.Invitation, error) {
+	invitations, err := s.invitationRepo.ListPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation deletes an invitation so its token can no longer be used
+func (s *UserAdminService) RevokeInvitation(ctx context.Context, id string) (*entities.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if err := s.invitationRepo.Delete(ctx, invitation.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// role returns the named role, or the default user role when name is empty
+func (s *UserAdminService) role(ctx context.Context, name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return entities.RoleUser, nil
+	}
+	role, err := s.rbac.GetRole(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return role.Name, nil
+}
+
+func (s *UserAdminService) ensureAvailable(ctx context.Context, username, email string) error {
+	if existing, err := s.userRepo.GetByUsername(ctx, username); err != nil {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	} else if existing != nil {
+		return ErrUserAlreadyExists
+	}
+	if existing, err := s.userRepo.GetByEmail(ctx, email); err != nil {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	} else if existing != nil {
+		return ErrUserAlreadyExists
+	}
+	return nil
+}
+
+// redeemInvitation claims the invitation for a new account with userID and
+// returns it; the email must match the invited address
+func redeemInvitation(ctx context.Context, invitations repositories.InvitationRepository, token, email, userID string, now time.Time) (*entities.Invitation, error) {
+	if token == "" {
+		return nil, ErrInvitationRequired
+	}
+	invitation, err := invitations.GetByTokenHash(ctx, hashInvitationToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation == nil || invitation.AcceptedAt != nil || !invitation.ExpiresAt.After(now) ||
+		!strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return nil, ErrInvalidInvitation
+	}
+
+	claimed, err := invitations.Claim(ctx, invitation.ID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidInvitation
+	}
+	return invitation, nil
+}
+
+func hashInvitationToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+// choosePassword returns password, or a generated temporary password that is
+// also returned as the second value
+func choosePassword(password string) (string, string, error) {
+	if password != "" {
+		if len(password) < 8 {
+			return "", "", fmt.Errorf("%w: password must be at least 8 characters long", ErrInvalidUserRequest)
+		}
+		return password, "", nil
+	}
+	temporary, err := generateSecret(18)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return temporary, temporary, nil
+}
+
+func generateSecret(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if _, err := mail.ParseAddress(email); err != nil || strings.ContainsAny(email, "<> ") {
+		return "", fmt.Errorf("%w: invalid email address", ErrInvalidUserRequest)
+	}
+	return email, nil
+}
+
+// ValidRegistrationMode reports whether mode is a known registration mode
+func ValidRegistrationMode(mode string) bool {
+	switch mode {
+	case RegistrationOpen, RegistrationInvite, RegistrationClosed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *entities.Invitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetByID(ctx context.Context, id string) (*entities.Invitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Invitation, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) ListPending(ctx context.Context) ([]*entities.Invitation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Claim(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, userID, acceptedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Release(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTestUserAdminService() (*UserAdminService, *MockUserRepository, *MockSessionRepository, *MockInvitationRepository, *MockRoleRepository) {
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	invitationRepo := new(MockInvitationRepository)
+	roleRepo := new(MockRoleRepository)
+	cfg := &config.Config{RBACCacheTTL: time.Minute, InvitationTTL: 48 * time.Hour}
+	rbac := NewRBACService(roleRepo, userRepo, cfg)
+	return NewUserAdminService(userRepo, sessionRepo, invitationRepo, rbac, cfg), userRepo, sessionRepo, invitationRepo, roleRepo
+}
+
+func TestUserAdminService_ListUsers(t *testing.T) {
+	service, userRepo, _, _, _ := newTestUserAdminService()
+	ctx := context.Background()
+
+	userRepo.On("Search", ctx, repositories.UserFilter{Query: "smith", Page: 1, PageSize: 100}).
+		Return([]*entities.User{{ID: "u1"}}, int64(1), nil)
+
+	page, err := service.ListUsers(ctx, repositories.UserFilter{Query: " smith ", PageSize: 500})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 100, page.PageSize)
+}
+
+func TestUserAdminService_CreateUser(t *testing.T) {
+	service, userRepo, _, _, roleRepo := newTestUserAdminService()
+	ctx := context.Background()
+
+	roleRepo.On("GetByName", ctx, "registrar").Return(&entities.Role{Name: "registrar"}, nil)
+	roleRepo.On("GetByName", ctx, "no-such-role").Return(nil, nil)
+	userRepo.On("GetByUsername", ctx, "clerk").Return(nil, nil)
+	userRepo.On("GetByEmail", ctx, "clerk@example.com").Return(nil, nil)
+	userRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+
+	user, temporary, err := service.CreateUser(ctx, &CreateUserRequest{
+		Username: "clerk",
+		FullName: "Clerk",
+		Email:    "clerk@example.com",
+		Role:     "Registrar",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "registrar", user.Role)
+	assert.True(t, user.IsActive)
+	require.NotEmpty(t, temporary)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(temporary)))
+
+	_, _, err = service.CreateUser(ctx, &CreateUserRequest{Username: "clerk", FullName: "Clerk", Email: "clerk@example.com", Role: "no-such-role"})
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+
+	userRepo.On("GetByUsername", ctx, "taken").Return(&entities.User{ID: "u9"}, nil)
+	_, _, err = service.CreateUser(ctx, &CreateUserRequest{Username: "taken", FullName: "Taken", Email: "clerk@example.com"})
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+}
+
+func TestUserAdminService_SetActive(t *testing.T) {
+	service, userRepo, sessionRepo, _, _ := newTestUserAdminService()
+	ctx := context.Background()
+
+	admin := &entities.User{ID: "a1", Role: entities.RoleAdmin, IsActive: true}
+	user := &entities.User{ID: "u1", Role: entities.RoleUser, IsActive: true}
+	userRepo.On("GetByID", ctx, "a1").Return(admin, nil)
+	userRepo.On("GetByID", ctx, "u1").Return(user, nil)
+	userRepo.On("ListByRole", ctx, entities.RoleAdmin).Return([]*entities.User{admin}, nil)
+	userRepo.On("Update", ctx, user).Return(nil)
+	sessionRepo.On("DeleteByUserID", ctx, "u1", []string(nil)).Return(int64(2), nil)
+
+	// Deactivation ends the user's sessions
+	updated, err := service.SetActive(ctx, "a1", "u1", false)
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive)
+	sessionRepo.AssertCalled(t, "DeleteByUserID", ctx, "u1", []string(nil))
+
+	updated, err = service.SetActive(ctx, "a1", "u1", true)
+	require.NoError(t, err)
+	assert.True(t, updated.IsActive)
+
+	_, err = service.SetActive(ctx, "a1", "a1", false)
+	assert.ErrorIs(t, err, ErrInvalidUserRequest)
+
+	// The last active admin cannot be deactivated by another admin either
+	_, err = service.SetActive(ctx, "a2", "a1", false)
+	assert.ErrorIs(t, err, ErrInvalidUserRequest)
+}
+
+func TestUserAdminService_ResetPasswordAndForceLogout(t *testing.T) {
+	service, userRepo, sessionRepo, _, _ := newTestUserAdminService()
+	ctx := context.Background()
+
+	user := &entities.User{ID: "u1", PasswordHash: "old", IsActive: true}
+	userRepo.On("GetByID", ctx, "u1").Return(user, nil)
+	userRepo.On("GetByID", ctx, "svc").Return(&entities.User{ID: "svc", ServiceAccount: true}, nil)
+	userRepo.On("Update", ctx, user).Return(nil)
+	sessionRepo.On("DeleteByUserID", ctx, "u1", []string(nil)).Return(int64(3), nil)
+
+	_, temporary, err := service.ResetPassword(ctx, "u1", "new-password-1")
+	require.NoError(t, err)
+	assert.Empty(t, temporary)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("new-password-1")))
+
+	_, _, err = service.ResetPassword(ctx, "u1", "short")
+	assert.ErrorIs(t, err, ErrInvalidUserRequest)
+
+	_, _, err = service.ResetPassword(ctx, "svc", "")
+	assert.ErrorIs(t, err, ErrInvalidUserRequest)
+
+	_, ended, err := service.ForceLogout(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), ended)
+}
+
+func TestUserAdminService_InvitationRegistration(t *testing.T) {
+	service, userRepo, sessionRepo, invitationRepo, roleRepo := newTestUserAdminService()
+	ctx := context.Background()
+
+	roleRepo.On("GetByName", ctx, "registrar").Return(&entities.Role{Name: "registrar"}, nil)
+	userRepo.On("GetByEmail", ctx, "new@example.com").Return(nil, nil)
+
+	var stored *entities.Invitation
+	invitationRepo.On("Create", ctx, mock.AnythingOfType("*entities.Invitation")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*entities.Invitation)
+		stored.ID = "inv-1"
+	}).Return(nil)
+
+	invitation, token, err := service.CreateInvitation(ctx, "a1", "new@example.com", "registrar")
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	assert.NotEqual(t, token, invitation.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), invitation.ExpiresAt, time.Minute)
+
+	authService := NewAuthService(userRepo, sessionRepo, "test-secret")
+	require.NoError(t, authService.SetRegistration(RegistrationInvite, invitationRepo))
+
+	request := RegisterRequest{Username: "newuser", Password: "password123", FullName: "New User", Email: "New@Example.com"}
+	_, err = authService.Register(ctx, request)
+	assert.ErrorIs(t, err, ErrInvitationRequired)
+
+	userRepo.On("GetByUsername", ctx, "newuser").Return(nil, nil)
+	userRepo.On("GetByEmail", ctx, "New@Example.com").Return(nil, nil)
+	invitationRepo.On("GetByTokenHash", ctx, "unknown-hash").Return(nil, nil)
+	invitationRepo.On("GetByTokenHash", ctx, stored.TokenHash).Return(stored, nil)
+	invitationRepo.On("Claim", ctx, "inv-1", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(true, nil)
+	userRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+
+	request.InvitationToken = "wrong-token"
+	invitationRepo.On("GetByTokenHash", ctx, hashInvitationToken("wrong-token")).Return(nil, nil)
+	_, err = authService.Register(ctx, request)
+	assert.ErrorIs(t, err, ErrInvalidInvitation)
+
+	// The invited email registers with the invitation's role
+	request.InvitationToken = token
+	user, err := authService.Register(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, "registrar", user.Role)
+	invitationRepo.AssertCalled(t, "Claim", ctx, "inv-1", user.ID, mock.AnythingOfType("time.Time"))
+
+	require.NoError(t, authService.SetRegistration(RegistrationClosed, nil))
+	_, err = authService.Register(ctx, request)
+	assert.ErrorIs(t, err, ErrRegistrationClosed)
+
+	assert.ErrorIs(t, authService.SetRegistration("sometimes", nil), ErrInvalidRegistration)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var ErrInvalidAnalytics = errors.New("invalid verification analytics request")
+
+// maxAnalyticsGroups bounds the groups of a verification report
+const maxAnalyticsGroups = 1000
+
+// VerificationAnalyticsService reports how the documents users signed are
+// being verified
+type VerificationAnalyticsService struct {
+	verificationLogRepo repositories.VerificationLogRepository
+	permissions         PermissionChecker
+	// Documents with tamperAlertThreshold content-changed results within
+	// tamperAlertWindow are listed as tamper alerts
+	tamperAlertThreshold int
+	tamperAlertWindow    time.Duration
+}
+
+// NewVerificationAnalyticsService creates a verification analytics service;
+// a zero alert threshold disables tamper alerts
+func NewVerificationAnalyticsService(verificationLogRepo repositories.VerificationLogRepository, tamperAlertThreshold int, tamperAlertWindow time.Duration) *VerificationAnalyticsService {
+	return &VerificationAnalyticsService{
+		verificationLogRepo:  verificationLogRepo,
+		tamperAlertThreshold: tamperAlertThreshold,
+		tamperAlertWindow:    tamperAlertWindow,
+	}
+}
+
+// SetPermissionChecker lets users with document:read:any report on every
+// user's documents in their organization
+func (s *VerificationAnalyticsService) SetPermissionChecker(permissions PermissionChecker) {
+	s.permissions = permissions
+}
+
+// VerificationAnalyticsRequest selects the verifications reported on
+type VerificationAnalyticsRequest struct {
+	UserID string
+	// All reports on every user's documents in the organization instead of
+	// the user's own; it needs document:read:any
+	All        bool
+	DocumentID string
+	Issuer     string
+	// From and To bound the verification time; To is exclusive
+	From *time.Time
+	To   *time.Time
+	// GroupBy is one of the repositories.VerificationGroup values; empty
+	// reports only the totals
+	GroupBy string
+	Limit   int
+}
+
+// VerificationAnalytics counts verifications by outcome, in total and per group
+type VerificationAnalytics struct {
+	GroupBy string                           `json:"group_by,omitempty"`
+	From    *time.Time                       `json:"from,omitempty"`
+	To      *time.Time                       `json:"to,omitempty"`
+	Summary repositories.VerificationStats   `json:"summary"`
+	Groups  []repositories.VerificationStats `json:"groups"`
+}
+
+// TamperAlerts lists the documents whose altered copies were verified at
+// least Threshold times since Since
+type TamperAlerts struct {
+	Threshold int                              `json:"threshold"`
+	Since     time.Time                        `json:"since"`
+	Documents []repositories.VerificationStats `json:"documents"`
+}
+
+// GetVerificationAnalytics counts the verifications of the user's documents,
+// or with req.All of every document in the organization
+func (s *VerificationAnalyticsService) GetVerificationAnalytics(ctx context.Context, req *VerificationAnalyticsRequest) (*VerificationAnalytics, error) {
+	filter, err := s.statsFilter(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	switch req.GroupBy {
+	case "", repositories.VerificationGroupDocument, repositories.VerificationGroupDay,
+		repositories.VerificationGroupIssuer, repositories.VerificationGroupCountry:
+	default:
+		return nil, fmt.Errorf("%w: unknown grouping %q, expected document, day, issuer or country", ErrInvalidAnalytics, req.GroupBy)
+	}
+
+	summary, err := s.verificationLogRepo.CountVerifications(ctx, filter, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count verifications: %w", err)
+	}
+	analytics := &VerificationAnalytics{
+		GroupBy: req.GroupBy,
+		From:    req.From,
+		To:      req.To,
+		Groups:  []repositories.VerificationStats{},
+	}
+	if len(summary) > 0 {
+		analytics.Summary = summary[0]
+	}
+	if req.GroupBy == "" {
+		return analytics, nil
+	}
+
+	groups, err := s.verificationLogRepo.CountVerifications(ctx, filter, req.GroupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count verifications by %s: %w", req.GroupBy, err)
+	}
+	if groups != nil {
+		analytics.Groups = groups
+	}
+	return analytics, nil
+}
+
+// GetTamperAlerts lists the documents with many qr_valid_content_changed
+// results, a sign of forged copies circulating. Without req.From the alert
+// window before now is searched.
+func (s *VerificationAnalyticsService) GetTamperAlerts(ctx context.Context, req *VerificationAnalyticsRequest) (*TamperAlerts, error) {
+	if req.From == nil {
+		since := time.Now().Add(-s.tamperAlertWindow)
+		req.From = &since
+	}
+	filter, err := s.statsFilter(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := &TamperAlerts{
+		Threshold: s.tamperAlertThreshold,
+		Since:     *req.From,
+		Documents: []repositories.VerificationStats{},
+	}
+	if s.tamperAlertThreshold <= 0 {
+		return alerts, nil
+	}
+
+	filter.MinContentChanged = int64(s.tamperAlertThreshold)
+	documents, err := s.verificationLogRepo.CountVerifications(ctx, filter, repositories.VerificationGroupDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count content-changed verifications: %w", err)
+	}
+	if documents != nil {
+		alerts.Documents = documents
+	}
+	return alerts, nil
+}
+
+// statsFilter checks the request and the user's permission to make it
+func (s *VerificationAnalyticsService) statsFilter(ctx context.Context, req *VerificationAnalyticsRequest) (repositories.VerificationStatsFilter, error) {
+	filter := repositories.VerificationStatsFilter{
+		UserID:     req.UserID,
+		DocumentID: req.DocumentID,
+		Issuer:     req.Issuer,
+		From:       req.From,
+		To:         req.To,
+		Limit:      req.Limit,
+	}
+	if req.All {
+		readAny, err := hasPermission(ctx, s.permissions, req.UserID, entities.PermissionDocumentReadAny)
+		if err != nil {
+			return filter, err
+		}
+		if !readAny {
+			return filter, fmt.Errorf("%w: reporting on all documents requires %s", ErrPermissionDenied, entities.PermissionDocumentReadAny)
+		}
+		filter.UserID = ""
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return filter, fmt.Errorf("%w: the end date must be after the start date", ErrInvalidAnalytics)
+	}
+	if filter.Limit < 1 || filter.Limit > maxAnalyticsGroups {
+		filter.Limit = maxAnalyticsGroups
+	}
+	return filter, nil
+}
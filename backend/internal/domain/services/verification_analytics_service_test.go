@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func TestVerificationAnalyticsService_GetVerificationAnalytics(t *testing.T) {
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	summary := repositories.VerificationStats{Total: 5, Valid: 3, ContentChanged: 2, UniqueVerifiers: 4}
+	days := []repositories.VerificationStats{
+		{Key: "2026-03-01", Total: 2, Valid: 2, UniqueVerifiers: 2},
+		{Key: "2026-03-02", Total: 3, Valid: 1, ContentChanged: 2, UniqueVerifiers: 2},
+	}
+
+	t.Run("the user's own documents by day", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		filter := repositories.VerificationStatsFilter{UserID: "user-1", Issuer: "Faculty A", From: &from, To: &to, Limit: maxAnalyticsGroups}
+		mockLogRepo.On("CountVerifications", mock.Anything, filter, "").Return([]repositories.VerificationStats{summary}, nil)
+		mockLogRepo.On("CountVerifications", mock.Anything, filter, repositories.VerificationGroupDay).Return(days, nil)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 24*time.Hour)
+
+		analytics, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{
+			UserID: "user-1", Issuer: "Faculty A", From: &from, To: &to, GroupBy: repositories.VerificationGroupDay,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, summary, analytics.Summary)
+		assert.Equal(t, days, analytics.Groups)
+		assert.Equal(t, repositories.VerificationGroupDay, analytics.GroupBy)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("totals only", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		mockLogRepo.On("CountVerifications", mock.Anything, mock.Anything, "").Return([]repositories.VerificationStats{summary}, nil)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 24*time.Hour)
+
+		analytics, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1"})
+		require.NoError(t, err)
+		assert.Equal(t, summary, analytics.Summary)
+		assert.Empty(t, analytics.Groups)
+		mockLogRepo.AssertNumberOfCalls(t, "CountVerifications", 1)
+	})
+
+	t.Run("all documents require read any", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 24*time.Hour)
+		service.SetPermissionChecker(stubPermissionChecker{})
+
+		_, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1", All: true})
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+		mockLogRepo.AssertNotCalled(t, "CountVerifications", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed permission check", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 24*time.Hour)
+		service.SetPermissionChecker(failingPermissionChecker{})
+
+		_, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1", All: true})
+		assert.ErrorIs(t, err, assert.AnError)
+		mockLogRepo.AssertNotCalled(t, "CountVerifications", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("all documents of the organization", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		filter := repositories.VerificationStatsFilter{Limit: 10}
+		mockLogRepo.On("CountVerifications", mock.Anything, filter, "").Return([]repositories.VerificationStats{summary}, nil)
+		mockLogRepo.On("CountVerifications", mock.Anything, filter, repositories.VerificationGroupCountry).Return(nil, nil)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 24*time.Hour)
+		service.SetPermissionChecker(stubPermissionChecker{entities.PermissionDocumentReadAny: true})
+
+		analytics, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{
+			UserID: "user-1", All: true, GroupBy: repositories.VerificationGroupCountry, Limit: 10,
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, analytics.Groups)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		service := NewVerificationAnalyticsService(new(MockVerificationLogRepository), 5, 24*time.Hour)
+
+		_, err := service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1", GroupBy: "hour"})
+		assert.ErrorIs(t, err, ErrInvalidAnalytics)
+
+		_, err = service.GetVerificationAnalytics(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1", From: &to, To: &from})
+		assert.ErrorIs(t, err, ErrInvalidAnalytics)
+	})
+}
+
+func TestVerificationAnalyticsService_GetTamperAlerts(t *testing.T) {
+	flagged := []repositories.VerificationStats{{Key: "doc-1", Label: "Transcript", Total: 9, Valid: 2, ContentChanged: 7, UniqueVerifiers: 6}}
+
+	t.Run("documents over the threshold within the window", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		mockLogRepo.On("CountVerifications", mock.Anything, mock.MatchedBy(func(filter repositories.VerificationStatsFilter) bool {
+			return filter.UserID == "user-1" && filter.MinContentChanged == 5 &&
+				filter.From != nil && time.Since(*filter.From) > 47*time.Hour && time.Since(*filter.From) < 49*time.Hour
+		}), repositories.VerificationGroupDocument).Return(flagged, nil)
+		service := NewVerificationAnalyticsService(mockLogRepo, 5, 48*time.Hour)
+
+		alerts, err := service.GetTamperAlerts(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1"})
+		require.NoError(t, err)
+		assert.Equal(t, 5, alerts.Threshold)
+		assert.Equal(t, flagged, alerts.Documents)
+		mockLogRepo.AssertExpectations(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		service := NewVerificationAnalyticsService(mockLogRepo, 0, 24*time.Hour)
+
+		alerts, err := service.GetTamperAlerts(context.Background(), &VerificationAnalyticsRequest{UserID: "user-1"})
+		require.NoError(t, err)
+		assert.Empty(t, alerts.Documents)
+		mockLogRepo.AssertNotCalled(t, "CountVerifications", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// recordingPublisher keeps the events published to it
+type recordingPublisher struct {
+	events []string
+	data   []interface{}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, eventType, userID string, organizationID *string, data interface{}) {
+	p.events = append(p.events, eventType)
+	p.data = append(p.data, data)
+}
+
+type stubCountryLocator map[string]string
+
+func (l stubCountryLocator) Country(ip string) string {
+	return l[ip]
+}
+
+func TestVerificationService_TamperAlert(t *testing.T) {
+	document := &entities.Document{ID: "doc-123", UserID: "user-1", Status: "active"}
+	verifiedAt := time.Now()
+
+	check := func(contentChanged int64) (*recordingPublisher, *MockVerificationLogRepository) {
+		mockLogRepo := new(MockVerificationLogRepository)
+		mockLogRepo.On("CountVerifications", mock.Anything, mock.MatchedBy(func(filter repositories.VerificationStatsFilter) bool {
+			return filter.DocumentID == "doc-123" && filter.From.Equal(verifiedAt.Add(-time.Hour))
+		}), "").Return([]repositories.VerificationStats{{Total: contentChanged, ContentChanged: contentChanged}}, nil)
+		events := &recordingPublisher{}
+		service := &VerificationService{verificationLogRepo: mockLogRepo}
+		service.SetEventPublisher(events)
+		service.SetTamperAlerts(3, time.Hour)
+
+		service.checkTamperAlert(context.Background(), document, verifiedAt)
+		return events, mockLogRepo
+	}
+
+	events, _ := check(2)
+	assert.Empty(t, events.events)
+
+	events, _ = check(3)
+	assert.Equal(t, []string{entities.WebhookEventDocumentForgerySuspected}, events.events)
+	assert.Equal(t, int64(3), events.data[0].(map[string]interface{})["content_changed"])
+
+	// A count that stepped past the threshold still alerts
+	events, _ = check(4)
+	assert.Equal(t, []string{entities.WebhookEventDocumentForgerySuspected}, events.events)
+
+	// Failed counts do not alert
+	mockLogRepo := new(MockVerificationLogRepository)
+	mockLogRepo.On("CountVerifications", mock.Anything, mock.Anything, "").Return(nil, assert.AnError)
+	failing := &recordingPublisher{}
+	service := &VerificationService{verificationLogRepo: mockLogRepo}
+	service.SetEventPublisher(failing)
+	service.SetTamperAlerts(3, time.Hour)
+	service.checkTamperAlert(context.Background(), document, verifiedAt)
+	assert.Empty(t, failing.events)
+
+	// One burst raises one alert per window
+	mockLogRepo = new(MockVerificationLogRepository)
+	mockLogRepo.On("CountVerifications", mock.Anything, mock.Anything, "").Return([]repositories.VerificationStats{{Total: 5, ContentChanged: 5}}, nil)
+	burst := &recordingPublisher{}
+	service = &VerificationService{verificationLogRepo: mockLogRepo}
+	service.SetEventPublisher(burst)
+	service.SetTamperAlerts(3, time.Hour)
+	service.checkTamperAlert(context.Background(), document, verifiedAt)
+	service.checkTamperAlert(context.Background(), document, verifiedAt.Add(30*time.Minute))
+	assert.Len(t, burst.events, 1)
+	service.checkTamperAlert(context.Background(), document, verifiedAt.Add(time.Hour))
+	assert.Len(t, burst.events, 2)
+
+	// Disabled alerts do not count
+	mockLogRepo = new(MockVerificationLogRepository)
+	service = &VerificationService{verificationLogRepo: mockLogRepo}
+	service.SetEventPublisher(&recordingPublisher{})
+	service.checkTamperAlert(context.Background(), document, verifiedAt)
+	mockLogRepo.AssertNotCalled(t, "CountVerifications", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestVerificationService_LogVerificationCountry(t *testing.T) {
+	mockLogRepo := new(MockVerificationLogRepository)
+	mockLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.VerificationLog")).Return(nil)
+	service := &VerificationService{verificationLogRepo: mockLogRepo}
+	service.SetGeoIP(stubCountryLocator{"36.80.12.7": "ID"})
+
+	service.logVerification(context.Background(), "doc-123", &VerificationResult{Status: StatusValid}, "36.80.12.7")
+	service.logVerification(context.Background(), "doc-123", &VerificationResult{Status: StatusValid}, "10.0.0.1")
+
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entities.VerificationLog) bool {
+		return log.VerifierIP == "36.80.12.7" && log.Country == "ID"
+	}))
+	mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entities.VerificationLog) bool {
+		return log.VerifierIP == "10.0.0.1" && log.Country == ""
+	}))
+}
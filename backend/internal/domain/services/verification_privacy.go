@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+var (
+	ErrInvalidVisibility  = errors.New("invalid verification visibility")
+	ErrAccessCodeRequired = errors.New("an access code is required to view this document")
+	ErrInvalidAccessCode  = errors.New("invalid access code")
+)
+
+const (
+	// accessCodeAlphabet leaves out characters easily misread on paper, such as 0 and O
+	accessCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// accessCodeLength gives 40 bits, too many to guess through a bcrypt hash
+	accessCodeLength = 8
+)
+
+// verificationFields are the fields an organization can redact, in the order
+// the verification page shows them
+var verificationFields = []string{
+	entities.VerificationFieldFilename,
+	entities.VerificationFieldIssuer,
+	entities.VerificationFieldOrganization,
+	entities.VerificationFieldTitle,
+	entities.VerificationFieldLetterNumber,
+	entities.VerificationFieldFileSize,
+	entities.VerificationFieldDocumentHash,
+	entities.VerificationFieldQRCodeData,
+	entities.VerificationFieldSignedBy,
+}
+
+// documentVisibility checks the visibility asked for when signing; empty is public
+func documentVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return entities.DocumentVisibilityPublic, nil
+	case entities.DocumentVisibilityPublic, entities.DocumentVisibilityMinimal, entities.DocumentVisibilityAccessCode:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("%w: %q, expected public, minimal or access_code", ErrInvalidVisibility, visibility)
+}
+
+// newAccessCode returns a random access code formatted for printing, such as
+// "K7QM-3XPA", and the bcrypt hash that is stored instead of it
+func newAccessCode() (string, string, error) {
+	code, err := generateAccessCode()
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := hashAccessCode(code)
+	if err != nil {
+		return "", "", err
+	}
+	return code, hash, nil
+}
+
+// generateAccessCode returns a random access code formatted for printing
+func generateAccessCode() (string, error) {
+	code := make([]byte, accessCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(accessCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate access code: %w", err)
+		}
+		code[i] = accessCodeAlphabet[n.Int64()]
+	}
+	half := accessCodeLength / 2
+	return string(code[:half]) + "-" + string(code[half:]), nil
+}
+
+// hashAccessCode returns the bcrypt hash accessCodeMatches checks a typed code against
+func hashAccessCode(code string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(normalizeAccessCode(code)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash access code: %w", err)
+	}
+	return string(hash), nil
+}
+
+// normalizeAccessCode drops the case, spaces and dashes of a typed access code
+func normalizeAccessCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// accessCodeMatches compares a typed access code with the stored hash,
+// ignoring case, spaces and dashes
+func accessCodeMatches(hash, code string) bool {
+	normalized := normalizeAccessCode(code)
+	if hash == "" || len(normalized) != accessCodeLength {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(normalized)) == nil
+}
+
+// disclosure is how much of a document a verifier may see
+type disclosure struct {
+	visibility string
+	// full shows the document's details less the redacted fields; otherwise
+	// only whether it is valid is shown
+	full     bool
+	redacted []string
+}
+
+// disclosureFor works out what a verifier holding accessCode may see of the
+// document. A missing or wrong access code returns the minimal disclosure with
+// ErrAccessCodeRequired or ErrInvalidAccessCode.
+func (s *VerificationService) disclosureFor(ctx context.Context, document *entities.Document, accessCode string) (*disclosure, error) {
+	visibility := document.Visibility
+	if visibility == "" {
+		visibility = entities.DocumentVisibilityPublic
+	}
+	minimal := &disclosure{visibility: visibility}
+
+	switch visibility {
+	case entities.DocumentVisibilityMinimal:
+		return minimal, nil
+	case entities.DocumentVisibilityAccessCode:
+		if accessCode == "" {
+			return minimal, ErrAccessCodeRequired
+		}
+		if !accessCodeMatches(document.AccessCodeHash, accessCode) {
+			return minimal, ErrInvalidAccessCode
+		}
+	}
+	return &disclosure{visibility: visibility, full: true, redacted: s.redactions(ctx, document)}, nil
+}
+
+// redactions returns the fields the document's organization hides. When the
+// organization cannot be read every field is hidden rather than shown.
+func (s *VerificationService) redactions(ctx context.Context, document *entities.Document) []string {
+	if document.OrganizationID == nil || s.organizations == nil {
+		return nil
+	}
+	org, err := s.organizations.GetOrganization(ctx, *document.OrganizationID)
+	if err != nil || org == nil {
+		return verificationFields
+	}
+	return org.VerificationRedactions
+}
+
+// applyToInfo removes what the verifier may not see from the verification info
+func (d *disclosure) applyToInfo(info *VerificationInfo) {
+	if !d.full {
+		*info = VerificationInfo{
+			DocumentID: info.DocumentID,
+			Status:     info.Status,
+			Visibility: d.visibility,
+		}
+		return
+	}
+
+	info.Visibility = d.visibility
+	for _, field := range d.redacted {
+		switch field {
+		case entities.VerificationFieldFilename:
+			info.Filename = ""
+		case entities.VerificationFieldIssuer:
+			info.Issuer = ""
+		case entities.VerificationFieldOrganization:
+			info.Organization = ""
+		case entities.VerificationFieldTitle:
+			info.Title = nil
+		case entities.VerificationFieldLetterNumber:
+			info.LetterNumber = nil
+		case entities.VerificationFieldFileSize:
+			info.FileSize = 0
+		case entities.VerificationFieldDocumentHash:
+			info.DocumentHash = ""
+		case entities.VerificationFieldQRCodeData:
+			info.QRCodeData = ""
+		case entities.VerificationFieldSignedBy:
+			info.SignedBy, info.OnBehalfOf = "", ""
+		}
+	}
+	info.Redacted = d.redacted
+}
+
+// applyToResult removes what the verifier may not see from a verification
+// result's details. The uploaded hash is the verifier's own and always stays.
+func (d *disclosure) applyToResult(result *VerificationResult) {
+	result.Visibility = d.visibility
+	if !d.full {
+		result.Details.OriginalHash = ""
+		result.Details.Title = nil
+		result.Details.LetterNumber = nil
+		return
+	}
+
+	for _, field := range d.redacted {
+		switch field {
+		case entities.VerificationFieldTitle:
+			result.Details.Title = nil
+		case entities.VerificationFieldLetterNumber:
+			result.Details.LetterNumber = nil
+		case entities.VerificationFieldDocumentHash:
+			result.Details.OriginalHash = ""
+		}
+	}
+	result.Redacted = d.redacted
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/infrastructure/crypto"
+	"digital-signature-system/internal/infrastructure/pdf"
+)
+
+func TestNewAccessCode(t *testing.T) {
+	code, hash, err := newAccessCode()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[A-HJ-NP-Z2-9]{4}-[A-HJ-NP-Z2-9]{4}$`), code)
+	assert.NotContains(t, hash, code)
+
+	// Verifiers may type it without the dash and in lower case
+	assert.True(t, accessCodeMatches(hash, code))
+	assert.True(t, accessCodeMatches(hash, strings.ToLower(strings.ReplaceAll(code, "-", " "))))
+	assert.False(t, accessCodeMatches(hash, "AAAA-AAAA"))
+	assert.False(t, accessCodeMatches(hash, ""))
+	assert.False(t, accessCodeMatches("", code))
+}
+
+func TestDocumentVisibility(t *testing.T) {
+	visibility, err := documentVisibility("")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DocumentVisibilityPublic, visibility)
+
+	visibility, err = documentVisibility(entities.DocumentVisibilityAccessCode)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DocumentVisibilityAccessCode, visibility)
+
+	_, err = documentVisibility("secret")
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+}
+
+func TestVerificationService_GetVerificationInfo_Visibility(t *testing.T) {
+	code, hash, err := newAccessCode()
+	require.NoError(t, err)
+	newDocument := func(visibility string) *entities.Document {
+		return &entities.Document{
+			ID:             "doc-123",
+			Filename:       "transcript.pdf",
+			Issuer:         "Faculty of Engineering",
+			Title:          stringPtr("Transcript"),
+			LetterNumber:   stringPtr("001/ENG/2026"),
+			DocumentHash:   "aGFzaA==",
+			QRCodeData:     `{"doc_id":"doc-123"}`,
+			FileSize:       2048,
+			Status:         "active",
+			CreatedAt:      time.Now(),
+			Visibility:     visibility,
+			AccessCodeHash: hash,
+		}
+	}
+	newService := func(document *entities.Document) *VerificationService {
+		mockDocRepo := new(MockDocumentRepository)
+		mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+		return &VerificationService{documentRepo: mockDocRepo}
+	}
+
+	t.Run("minimal only shows the status", func(t *testing.T) {
+		service := newService(newDocument(entities.DocumentVisibilityMinimal))
+
+		info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+		require.NoError(t, err)
+		assert.Equal(t, &VerificationInfo{
+			DocumentID: "doc-123",
+			Status:     "active",
+			Visibility: entities.DocumentVisibilityMinimal,
+		}, info)
+	})
+
+	t.Run("access code required", func(t *testing.T) {
+		service := newService(newDocument(entities.DocumentVisibilityAccessCode))
+
+		_, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+		assert.ErrorIs(t, err, ErrAccessCodeRequired)
+	})
+
+	t.Run("wrong access code", func(t *testing.T) {
+		service := newService(newDocument(entities.DocumentVisibilityAccessCode))
+
+		_, err := service.GetVerificationInfo(context.Background(), "doc-123", "AAAA-AAAA")
+		assert.ErrorIs(t, err, ErrInvalidAccessCode)
+	})
+
+	t.Run("access code shows the details", func(t *testing.T) {
+		service := newService(newDocument(entities.DocumentVisibilityAccessCode))
+
+		info, err := service.GetVerificationInfo(context.Background(), "doc-123", code)
+		require.NoError(t, err)
+		assert.Equal(t, "transcript.pdf", info.Filename)
+		assert.Equal(t, "aGFzaA==", info.DocumentHash)
+		assert.NotNil(t, info.CreatedAt)
+		assert.Equal(t, entities.DocumentVisibilityAccessCode, info.Visibility)
+	})
+
+	t.Run("documents signed before visibility are public", func(t *testing.T) {
+		service := newService(newDocument(""))
+
+		info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+		require.NoError(t, err)
+		assert.Equal(t, "Faculty of Engineering", info.Issuer)
+		assert.Equal(t, entities.DocumentVisibilityPublic, info.Visibility)
+	})
+}
+
+func TestVerificationService_GetVerificationInfo_Redactions(t *testing.T) {
+	orgID := "org-1"
+	document := &entities.Document{
+		ID:             "doc-123",
+		Filename:       "transcript.pdf",
+		Issuer:         "Faculty of Engineering",
+		Title:          stringPtr("Transcript"),
+		DocumentHash:   "aGFzaA==",
+		QRCodeData:     `{"doc_id":"doc-123"}`,
+		FileSize:       2048,
+		Status:         "active",
+		Visibility:     entities.DocumentVisibilityPublic,
+		OrganizationID: &orgID,
+	}
+	redactions := []string{entities.VerificationFieldFilename, entities.VerificationFieldDocumentHash, entities.VerificationFieldQRCodeData}
+
+	t.Run("organization redactions", func(t *testing.T) {
+		mockDocRepo := new(MockDocumentRepository)
+		mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+		service := &VerificationService{documentRepo: mockDocRepo}
+		service.SetOrganizations(&stubOrganizationResolver{org: &entities.Organization{ID: orgID, Name: "Engineering", VerificationRedactions: redactions}})
+
+		info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+		require.NoError(t, err)
+		assert.Empty(t, info.Filename)
+		assert.Empty(t, info.DocumentHash)
+		assert.Empty(t, info.QRCodeData)
+		assert.Equal(t, "Faculty of Engineering", info.Issuer)
+		assert.Equal(t, "Engineering", info.Organization)
+		assert.Equal(t, "Transcript", *info.Title)
+		assert.Equal(t, redactions, info.Redacted)
+	})
+
+	t.Run("unreadable organization hides every field", func(t *testing.T) {
+		mockDocRepo := new(MockDocumentRepository)
+		mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+		service := &VerificationService{documentRepo: mockDocRepo}
+		service.SetOrganizations(&stubOrganizationResolver{org: &entities.Organization{ID: "other-org"}})
+
+		info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+		require.NoError(t, err)
+		assert.Empty(t, info.Issuer)
+		assert.Nil(t, info.Title)
+		assert.Zero(t, info.FileSize)
+		assert.Equal(t, verificationFields, info.Redacted)
+	})
+}
+
+func TestVerificationService_VerifyDocument_AccessCode(t *testing.T) {
+	testHash := []byte("test-hash")
+	testSignature := &crypto.SignatureData{Signature: []byte("test-signature"), Hash: testHash, Algorithm: "RSA-PSS-SHA256"}
+	testSignatureJSON := `{"algorithm":"RSA-PSS-SHA256","hash":"dGVzdC1oYXNo","signature":"dGVzdC1zaWduYXR1cmU="}`
+	qrCodeJSON, _ := json.Marshal(pdf.QRCodeData{DocID: "doc-123", Hash: base64.StdEncoding.EncodeToString(testHash)})
+	code, hash, err := newAccessCode()
+	require.NoError(t, err)
+
+	verify := func(accessCode string) (*VerificationResult, *MockVerificationLogRepository) {
+		mockDocRepo := new(MockDocumentRepository)
+		mockLogRepo := new(MockVerificationLogRepository)
+		mockSigService := new(MockSignatureService)
+		mockPDFService := new(MockPDFService)
+		mockDocService := new(MockDocumentService)
+
+		mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(&entities.Document{
+			ID:             "doc-123",
+			DocumentHash:   base64.StdEncoding.EncodeToString(testHash),
+			SignatureData:  testSignatureJSON,
+			QRCodeData:     string(qrCodeJSON),
+			Status:         "active",
+			Title:          stringPtr("Transcript"),
+			LetterNumber:   stringPtr("001/ENG/2026"),
+			Visibility:     entities.DocumentVisibilityAccessCode,
+			AccessCodeHash: hash,
+		}, nil)
+		mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+		mockPDFService.On("CalculateHash", mock.Anything).Return(testHash, nil)
+		mockDocService.On("DecodeSignatureData", testSignatureJSON).Return(testSignature, nil)
+		mockSigService.On("VerifySignature", testHash, testSignature).Return(nil)
+		mockLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.VerificationLog")).Return(nil)
+
+		service := &VerificationService{
+			documentRepo:        mockDocRepo,
+			verificationLogRepo: mockLogRepo,
+			signatureService:    mockSigService,
+			pdfService:          mockPDFService,
+			documentService:     mockDocService,
+		}
+		result, err := service.VerifyDocument(context.Background(), &VerificationRequest{
+			DocumentID: "doc-123",
+			PDFData:    []byte("%PDF-1.4 test content"),
+			VerifierIP: "127.0.0.1",
+			AccessCode: accessCode,
+		})
+		require.NoError(t, err)
+		return result, mockLogRepo
+	}
+
+	t.Run("without the code only validity is shown", func(t *testing.T) {
+		result, mockLogRepo := verify("")
+		assert.True(t, result.IsValid)
+		assert.Equal(t, StatusValid, result.Status)
+		assert.Empty(t, result.Details.OriginalHash)
+		assert.Nil(t, result.Details.Title)
+		assert.Nil(t, result.Details.LetterNumber)
+		assert.NotEmpty(t, result.Details.UploadedHash)
+
+		// The owner's history keeps the full details
+		mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entities.VerificationLog) bool {
+			return strings.Contains(log.Details, "Transcript")
+		}))
+	})
+
+	t.Run("with the code", func(t *testing.T) {
+		result, _ := verify(code)
+		assert.True(t, result.IsValid)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(testHash), result.Details.OriginalHash)
+		assert.Equal(t, "Transcript", *result.Details.Title)
+	})
+}
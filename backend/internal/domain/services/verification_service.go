@@ -1,10 +1,14 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	"digital-signature-system/internal/domain/entities"
@@ -13,9 +17,22 @@ import (
 	"digital-signature-system/internal/infrastructure/pdf"
 )
 
+var (
+	// ErrNoMatchingDocument is returned alike when no document or only private
+	// ones match a file, so lookups do not reveal private documents
+	ErrNoMatchingDocument      = errors.New("no signed document matches this file")
+	ErrInvalidVerificationFile = errors.New("invalid verification file")
+)
+
+// maxFileMatches bounds the documents verified for one file; the same PDF is
+// signed again only as a new version
+const maxFileMatches = 10
+
 // DocumentServiceInterface defines the interface for document service operations needed by verification
 type DocumentServiceInterface interface {
 	DecodeSignatureData(signatureDataStr string) (*crypto.SignatureData, error)
+	// GetDocumentByID returns a document the user owns, signed or may read
+	GetDocumentByID(ctx context.Context, userID, documentID string) (*entities.Document, error)
 }
 
 // VerificationService handles document verification business logic
@@ -25,20 +42,51 @@ type VerificationService struct {
 	signatureService    SignatureServiceInterface
 	pdfService          PDFServiceInterface
 	documentService     DocumentServiceInterface
+	events              EventPublisher
+	organizations       OrganizationResolver
+	delegations         DelegationLookup
+	geoIP               CountryLocator
+	// A forgery alert is raised when a document gets tamperAlertThreshold
+	// qr_valid_content_changed results within tamperAlertWindow
+	tamperAlertThreshold int
+	tamperAlertWindow    time.Duration
+	// tamperAlerted holds when each document last raised an alert, so a burst
+	// of altered copies raises one alert per window
+	tamperAlertMu sync.Mutex
+	tamperAlerted map[string]time.Time
+}
+
+// CountryLocator maps an IP address to its ISO country code, or "" when unknown
+type CountryLocator interface {
+	Country(ip string) string
+}
+
+// DelegationLookup returns a delegation with its delegator and delegate
+type DelegationLookup interface {
+	GetDelegation(ctx context.Context, id string) (*entities.Delegation, error)
 }
 
-// VerificationInfo represents information about a document for verification
+// VerificationInfo represents information about a document for verification.
+// Documents with minimal visibility only show their ID and status.
 type VerificationInfo struct {
-	DocumentID   string    `json:"document_id"`
-	Filename     string    `json:"filename"`
-	Issuer       string    `json:"issuer"`
-	Title        *string   `json:"title,omitempty"`
-	LetterNumber *string   `json:"letter_number,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	FileSize     int64     `json:"file_size"`
-	Status       string    `json:"status"`
-	DocumentHash string    `json:"document_hash"`
-	QRCodeData   string    `json:"qr_code_data,omitempty"`
+	DocumentID   string     `json:"document_id"`
+	Filename     string     `json:"filename,omitempty"`
+	Issuer       string     `json:"issuer,omitempty"`
+	Organization string     `json:"organization,omitempty"`
+	Title        *string    `json:"title,omitempty"`
+	LetterNumber *string    `json:"letter_number,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	Status       string     `json:"status"`
+	DocumentHash string     `json:"document_hash,omitempty"`
+	QRCodeData   string     `json:"qr_code_data,omitempty"`
+	// SignedBy and OnBehalfOf name the delegate and the delegator when the
+	// document was signed under a delegation
+	SignedBy   string `json:"signed_by,omitempty"`
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+	Visibility string `json:"visibility"`
+	// Redacted lists the fields the document's organization hides
+	Redacted []string `json:"redacted,omitempty"`
 }
 
 // VerificationRequest represents a request to verify a document
@@ -46,6 +94,11 @@ type VerificationRequest struct {
 	DocumentID string `json:"document_id"`
 	PDFData    []byte `json:"-"` // PDF file data to verify
 	VerifierIP string `json:"verifier_ip"`
+	// AccessCode unlocks the details of a document with access_code visibility
+	AccessCode string `json:"-"`
+
+	// Source is an already validated and hashed upload; when set, PDFData is ignored
+	Source *pdf.SpooledPDF `json:"-"`
 }
 
 // VerificationResult represents the result of document verification
@@ -59,6 +112,9 @@ type VerificationResult struct {
 	HashMatches    bool                `json:"hash_matches"`
 	SignatureValid bool                `json:"signature_valid"`
 	QRCodeValid    bool                `json:"qr_code_valid"`
+	// Visibility and Redacted tell which details were left out for the verifier
+	Visibility string   `json:"visibility,omitempty"`
+	Redacted   []string `json:"redacted,omitempty"`
 }
 
 // VerificationDetails represents the detailed verification results
@@ -66,7 +122,7 @@ type VerificationDetails struct {
 	QRValid        bool    `json:"qr_valid"`
 	HashMatches    bool    `json:"hash_matches"`
 	SignatureValid bool    `json:"signature_valid"`
-	OriginalHash   string  `json:"original_hash"`
+	OriginalHash   string  `json:"original_hash,omitempty"`
 	UploadedHash   string  `json:"uploaded_hash"`
 	Title          *string `json:"title,omitempty"`
 	LetterNumber   *string `json:"letter_number,omitempty"`
@@ -98,8 +154,40 @@ func NewVerificationService(
 	}
 }
 
-// GetVerificationInfo retrieves information about a document for verification
-func (s *VerificationService) GetVerificationInfo(ctx context.Context, documentID string) (*VerificationInfo, error) {
+// SetEventPublisher registers a publisher for verification events
+func (s *VerificationService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetOrganizations lets documents signed with organization keys be verified
+func (s *VerificationService) SetOrganizations(organizations OrganizationResolver) {
+	s.organizations = organizations
+}
+
+// SetDelegations lets verification show who signed a document on whose behalf
+func (s *VerificationService) SetDelegations(delegations DelegationLookup) {
+	s.delegations = delegations
+}
+
+// SetGeoIP records the verifier's country with each verification
+func (s *VerificationService) SetGeoIP(geoIP CountryLocator) {
+	s.geoIP = geoIP
+}
+
+// SetTamperAlerts raises a document.forgery_suspected event when a document
+// gets threshold content-changed results within window; 0 disables the alerts
+func (s *VerificationService) SetTamperAlerts(threshold int, window time.Duration) {
+	s.tamperAlertThreshold = threshold
+	s.tamperAlertWindow = window
+}
+
+// GetVerificationInfo retrieves information about a document for verification,
+// as much as its visibility and its organization's redactions allow. Documents
+// with access_code visibility need their access code.
+func (s *VerificationService) GetVerificationInfo(ctx context.Context, documentID, accessCode string) (*VerificationInfo, error) {
+	// Anyone holding a document ID may verify it, whatever its organization
+	ctx = repositories.WithAllOrganizations(ctx)
+
 	// Get document from database
 	document, err := s.documentRepo.GetByID(ctx, documentID)
 	if err != nil {
@@ -115,18 +203,28 @@ func (s *VerificationService) GetVerificationInfo(ctx context.Context, documentI
 		return nil, fmt.Errorf("document is not active")
 	}
 
-	return &VerificationInfo{
-		DocumentID:   document.ID,
-		Filename:     document.Filename,
-		Issuer:       document.Issuer,
-		Title:        document.Title,
-		LetterNumber: document.LetterNumber,
-		CreatedAt:    document.CreatedAt,
-		FileSize:     document.FileSize,
-		Status:       document.Status,
-		DocumentHash: document.DocumentHash,
-		QRCodeData:   document.QRCodeData,
-	}, nil
+	disclosure, err := s.disclosureFor(ctx, document, accessCode)
+	if err != nil {
+		return nil, err
+	}
+	info := &VerificationInfo{
+		DocumentID: document.ID,
+		Status:     document.Status,
+	}
+	if disclosure.full {
+		info.Filename = document.Filename
+		info.Issuer = document.Issuer
+		info.Organization = s.organizationName(ctx, document)
+		info.Title = document.Title
+		info.LetterNumber = document.LetterNumber
+		info.CreatedAt = &document.CreatedAt
+		info.FileSize = document.FileSize
+		info.DocumentHash = document.DocumentHash
+		info.QRCodeData = document.QRCodeData
+		info.SignedBy, info.OnBehalfOf = s.delegationNames(ctx, document)
+	}
+	disclosure.applyToInfo(info)
+	return info, nil
 }
 
 // VerifyDocument verifies a document against its stored signature and hash
@@ -136,6 +234,7 @@ func (s *VerificationService) VerifyDocument(ctx context.Context, req *Verificat
 		VerifiedAt: time.Now(),
 		Details:    VerificationDetails{},
 	}
+	ctx = repositories.WithAllOrganizations(ctx)
 
 	// Get original document from database
 	document, err := s.documentRepo.GetByID(ctx, req.DocumentID)
@@ -162,51 +261,92 @@ func (s *VerificationService) VerifyDocument(ctx context.Context, req *Verificat
 		return result, nil
 	}
 
-	// Validate uploaded PDF
-	if err := s.pdfService.ValidatePDF(req.PDFData); err != nil {
+	uploadedHash, failure := s.uploadedHash(req)
+	if failure != "" {
 		result.Status = StatusError
-		result.Message = "Invalid PDF file"
+		result.Message = failure
 		s.logVerification(ctx, req.DocumentID, result, req.VerifierIP)
 		return result, nil
 	}
 
-	// Calculate hash of uploaded document
-	uploadedHash, err := s.pdfService.CalculateHash(req.PDFData)
+	s.verifyAgainst(ctx, document, uploadedHash, req.VerifierIP, result)
+	s.disclose(ctx, document, req.AccessCode, result)
+	return result, nil
+}
+
+// VerifyByFile verifies an uploaded PDF against every document signed from it
+// or stamped into it, newest first, without needing the document ID. Private
+// documents are never matched.
+func (s *VerificationService) VerifyByFile(ctx context.Context, req *VerificationRequest) ([]*VerificationResult, error) {
+	ctx = repositories.WithAllOrganizations(ctx)
+
+	uploadedHash, failure := s.uploadedHash(req)
+	if failure != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidVerificationFile, failure)
+	}
+
+	documents, err := s.documentRepo.GetVerifiableByHash(ctx, encodeHashForComparison(uploadedHash), maxFileMatches)
 	if err != nil {
-		result.Status = StatusError
-		result.Message = "Failed to calculate document hash"
-		s.logVerification(ctx, req.DocumentID, result, req.VerifierIP)
-		return result, nil
+		return nil, fmt.Errorf("failed to find documents: %w", err)
+	}
+	if len(documents) == 0 {
+		return nil, ErrNoMatchingDocument
 	}
 
+	results := make([]*VerificationResult, 0, len(documents))
+	for _, document := range documents {
+		result := &VerificationResult{
+			DocumentID: document.ID,
+			VerifiedAt: time.Now(),
+		}
+		s.verifyAgainst(ctx, document, uploadedHash, req.VerifierIP, result)
+		s.disclose(ctx, document, req.AccessCode, result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// verifyAgainst checks the uploaded hash, the QR code data and the signature of
+// an active document, fills in result and records the attempt
+func (s *VerificationService) verifyAgainst(ctx context.Context, document *entities.Document, uploadedHash []byte, verifierIP string, result *VerificationResult) {
 	// Parse stored QR code data
 	var qrCodeData pdf.QRCodeData
 	if err := json.Unmarshal([]byte(document.QRCodeData), &qrCodeData); err != nil {
 		result.Status = StatusError
 		result.Message = "Failed to parse QR code data"
-		s.logVerification(ctx, req.DocumentID, result, req.VerifierIP)
-		return result, nil
+		s.logVerification(ctx, document.ID, result, verifierIP)
+		return
 	}
 
 	// Verify QR code data matches document
 	result.QRCodeValid = (qrCodeData.DocID == document.ID && qrCodeData.Hash == document.DocumentHash)
 
-	// Compare hashes (encode uploaded hash in same format as stored hash)
+	// Compare hashes (encode uploaded hash in same format as stored hash); the
+	// PDF with the QR code stamped in matches as well as the original
 	uploadedHashStr := encodeHashForComparison(uploadedHash)
-	result.HashMatches = (uploadedHashStr == document.DocumentHash)
+	result.HashMatches = uploadedHashStr == document.DocumentHash ||
+		(document.StampedHash != "" && uploadedHashStr == document.StampedHash)
 
 	// Decode and verify signature
 	signatureData, err := s.documentService.DecodeSignatureData(document.SignatureData)
 	if err != nil {
 		result.Status = StatusError
 		result.Message = "Failed to decode signature data"
-		s.logVerification(ctx, req.DocumentID, result, req.VerifierIP)
-		return result, nil
+		s.logVerification(ctx, document.ID, result, verifierIP)
+		return
 	}
 
-	// Verify signature against original hash (from database)
-	err = s.signatureService.VerifySignature(signatureData.Hash, signatureData)
-	result.SignatureValid = (err == nil)
+	// Verify signature against original hash (from database) with the key that signed it
+	verifier, err := s.verifierFor(ctx, document)
+	if err != nil {
+		result.Status = StatusError
+		result.Message = "Failed to load the signing key"
+		result.Details.Error = err.Error()
+		s.logVerification(ctx, document.ID, result, verifierIP)
+		return
+	}
+	err = verifier.VerifySignature(signatureData.Hash, signatureData)
+	result.SignatureValid = (err == nil && coversLetterNumber(document, signatureData))
 
 	// Set details for frontend
 	result.Details = VerificationDetails{
@@ -239,9 +379,105 @@ func (s *VerificationService) VerifyDocument(ctx context.Context, req *Verificat
 	}
 
 	// Log verification attempt
-	s.logVerification(ctx, req.DocumentID, result, req.VerifierIP)
+	s.logVerification(ctx, document.ID, result, verifierIP)
+	if result.Status == StatusQRValidContentChanged {
+		s.checkTamperAlert(ctx, document, result.VerifiedAt)
+	}
 
-	return result, nil
+	if s.events != nil {
+		s.events.Publish(ctx, entities.WebhookEventDocumentVerified, document.UserID, document.OrganizationID, map[string]interface{}{
+			"document_id":     document.ID,
+			"letter_number":   document.LetterNumber,
+			"status":          result.Status,
+			"is_valid":        result.IsValid,
+			"hash_matches":    result.HashMatches,
+			"signature_valid": result.SignatureValid,
+			"qr_code_valid":   result.QRCodeValid,
+			"verified_at":     result.VerifiedAt,
+		})
+	}
+}
+
+// disclose removes what the verifier may not see from a logged result. Without
+// the right access code the verifier still learns whether the file is valid.
+func (s *VerificationService) disclose(ctx context.Context, document *entities.Document, accessCode string, result *VerificationResult) {
+	disclosure, _ := s.disclosureFor(ctx, document, accessCode)
+	disclosure.applyToResult(result)
+}
+
+// coversLetterNumber reports whether the signature of a document numbered by a
+// scheme was made over its letter number, so the number cannot be changed
+func coversLetterNumber(document *entities.Document, signatureData *crypto.SignatureData) bool {
+	if document.LetterNumberSchemeID == nil {
+		return true
+	}
+	documentHash, err := base64.StdEncoding.DecodeString(document.DocumentHash)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(signatureData.Hash, signedDigest(documentHash, document))
+}
+
+// verifierFor returns the organization key that signed the document, or the
+// server key for documents signed outside any organization
+func (s *VerificationService) verifierFor(ctx context.Context, document *entities.Document) (SignatureServiceInterface, error) {
+	if document.OrganizationID == nil {
+		return s.signatureService, nil
+	}
+	if s.organizations == nil {
+		return nil, fmt.Errorf("organizations are not configured")
+	}
+	return s.organizations.VerifierFor(ctx, *document.OrganizationID, document.KeyID)
+}
+
+// organizationName names the organization a document was signed for, if any
+func (s *VerificationService) organizationName(ctx context.Context, document *entities.Document) string {
+	if document.OrganizationID == nil || s.organizations == nil {
+		return ""
+	}
+	org, err := s.organizations.GetOrganization(ctx, *document.OrganizationID)
+	if err != nil {
+		return ""
+	}
+	return org.Name
+}
+
+// delegationNames names the delegate who signed the document and the delegator
+// they signed for; both are empty when the owner signed it
+func (s *VerificationService) delegationNames(ctx context.Context, document *entities.Document) (string, string) {
+	if document.DelegationID == nil || s.delegations == nil {
+		return "", ""
+	}
+	delegation, err := s.delegations.GetDelegation(ctx, *document.DelegationID)
+	if err != nil || delegation.Delegate == nil || delegation.Delegator == nil {
+		return "", ""
+	}
+	return delegation.Delegate.FullName, delegation.Delegator.FullName
+}
+
+// uploadedHash returns the SHA-256 of the uploaded PDF, reusing the hash computed while spooling.
+// On failure it returns the message reported to the verifier instead.
+func (s *VerificationService) uploadedHash(req *VerificationRequest) ([]byte, string) {
+	if req.Source != nil {
+		return req.Source.Hash(), ""
+	}
+
+	// Validate uploaded PDF
+	if err := s.pdfService.ValidatePDF(req.PDFData); err != nil {
+		return nil, "Invalid PDF file"
+	}
+
+	// Calculate hash of uploaded document
+	hash, err := s.pdfService.CalculateHash(req.PDFData)
+	if err != nil {
+		return nil, "Failed to calculate document hash"
+	}
+	return hash, ""
+}
+
+// SpoolPDF streams an upload to disk, hashing and parsing it once; the caller must Close it
+func (s *VerificationService) SpoolPDF(reader io.Reader) (*pdf.SpooledPDF, error) {
+	return s.pdfService.SpoolPDF(reader)
 }
 
 // logVerification logs the verification attempt
@@ -255,6 +491,9 @@ func (s *VerificationService) logVerification(ctx context.Context, documentID st
 		VerifierIP:         verifierIP,
 		Details:            string(details),
 	}
+	if s.geoIP != nil {
+		log.Country = s.geoIP.Country(verifierIP)
+	}
 
 	// Log error if logging fails, but don't fail the verification
 	if err := s.verificationLogRepo.Create(ctx, log); err != nil {
@@ -262,16 +501,65 @@ func (s *VerificationService) logVerification(ctx context.Context, documentID st
 	}
 }
 
-// GetVerificationHistory retrieves verification history for a document
-func (s *VerificationService) GetVerificationHistory(ctx context.Context, documentID string) ([]*entities.VerificationLog, error) {
-	// Verify document exists
-	document, err := s.documentRepo.GetByID(ctx, documentID)
+// checkTamperAlert raises a forgery alert when the document's content-changed
+// results within the alert window have reached the threshold. Altered copies
+// being verified is a sign of forged copies circulating.
+func (s *VerificationService) checkTamperAlert(ctx context.Context, document *entities.Document, verifiedAt time.Time) {
+	if s.tamperAlertThreshold <= 0 || s.events == nil {
+		return
+	}
+
+	since := verifiedAt.Add(-s.tamperAlertWindow)
+	stats, err := s.verificationLogRepo.CountVerifications(ctx, repositories.VerificationStatsFilter{DocumentID: document.ID, From: &since}, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get document: %w", err)
+		fmt.Printf("Warning: Failed to count content-changed verifications of document %s: %v\n", document.ID, err)
+		return
+	}
+	// Concurrent verifications can step past the threshold without any of
+	// them seeing it exactly, so every count at or above it qualifies
+	if len(stats) == 0 || stats[0].ContentChanged < int64(s.tamperAlertThreshold) {
+		return
+	}
+	if !s.markTamperAlert(document.ID, verifiedAt) {
+		return
 	}
 
-	if document == nil {
-		return nil, fmt.Errorf("document not found")
+	s.events.Publish(ctx, entities.WebhookEventDocumentForgerySuspected, document.UserID, document.OrganizationID, map[string]interface{}{
+		"document_id":     document.ID,
+		"letter_number":   document.LetterNumber,
+		"content_changed": stats[0].ContentChanged,
+		"since":           since,
+		"window":          s.tamperAlertWindow.String(),
+	})
+}
+
+// markTamperAlert records an alert for the document and reports false when one
+// was already raised within the alert window
+func (s *VerificationService) markTamperAlert(documentID string, at time.Time) bool {
+	s.tamperAlertMu.Lock()
+	defer s.tamperAlertMu.Unlock()
+
+	if last, ok := s.tamperAlerted[documentID]; ok && at.Sub(last) < s.tamperAlertWindow {
+		return false
+	}
+	if s.tamperAlerted == nil {
+		s.tamperAlerted = make(map[string]time.Time)
+	}
+	// Alerts that left the window are dropped so the map only holds active bursts
+	for id, last := range s.tamperAlerted {
+		if at.Sub(last) >= s.tamperAlertWindow {
+			delete(s.tamperAlerted, id)
+		}
+	}
+	s.tamperAlerted[documentID] = at
+	return true
+}
+
+// GetVerificationHistory retrieves verification history for a document. It
+// shows verifiers' IP addresses, so only users who may read the document see it.
+func (s *VerificationService) GetVerificationHistory(ctx context.Context, userID, documentID string) ([]*entities.VerificationLog, error) {
+	if _, err := s.documentService.GetDocumentByID(ctx, userID, documentID); err != nil {
+		return nil, err
 	}
 
 	// Get verification logs
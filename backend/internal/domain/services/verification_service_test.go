@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"digital-signature-system/internal/config"
 	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
 	"digital-signature-system/internal/infrastructure/crypto"
 	"digital-signature-system/internal/infrastructure/pdf"
 )
@@ -39,6 +43,19 @@ func (m *MockVerificationLogRepository) GetByID(ctx context.Context, id string)
 	return args.Get(0).(*entities.VerificationLog), args.Error(1)
 }
 
+func (m *MockVerificationLogRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockVerificationLogRepository) CountVerifications(ctx context.Context, filter repositories.VerificationStatsFilter, groupBy string) ([]repositories.VerificationStats, error) {
+	args := m.Called(ctx, filter, groupBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repositories.VerificationStats), args.Error(1)
+}
+
 func TestVerificationService_GetVerificationInfo(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -104,7 +121,7 @@ func TestVerificationService_GetVerificationInfo(t *testing.T) {
 			}
 
 			// Execute
-			info, err := service.GetVerificationInfo(context.Background(), tt.documentID)
+			info, err := service.GetVerificationInfo(context.Background(), tt.documentID, "")
 
 			// Assert
 			if tt.expectedError != "" {
@@ -123,6 +140,49 @@ func TestVerificationService_GetVerificationInfo(t *testing.T) {
 	}
 }
 
+func TestVerificationService_GetVerificationInfo_Organization(t *testing.T) {
+	orgID := "org-1"
+	document := &entities.Document{ID: "doc-123", Filename: "test.pdf", Issuer: "Faculty of Engineering", Status: "active", OrganizationID: &orgID, KeyID: "org-key-1"}
+
+	// Public verification sees every organization's documents
+	mockDocRepo := new(MockDocumentRepository)
+	mockDocRepo.On("GetByID", mock.MatchedBy(func(ctx context.Context) bool {
+		_, all := repositories.OrganizationFromContext(ctx)
+		return all
+	}), "doc-123").Return(document, nil)
+
+	service := &VerificationService{documentRepo: mockDocRepo}
+	service.SetOrganizations(&stubOrganizationResolver{org: &entities.Organization{ID: orgID, Name: "Engineering"}})
+
+	info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Engineering", info.Organization)
+	mockDocRepo.AssertExpectations(t)
+}
+
+func TestVerificationService_GetVerificationInfo_Delegation(t *testing.T) {
+	delegationID := "delegation-1"
+	secretaryID := "secretary"
+	document := &entities.Document{ID: "doc-123", UserID: "head", Filename: "memo.pdf", Issuer: "Department of Physics", Status: "active", SignedByID: &secretaryID, DelegationID: &delegationID}
+	mockDocRepo := new(MockDocumentRepository)
+	mockDocRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+
+	delegationRepo := new(MockDelegationRepository)
+	delegationRepo.On("GetByID", mock.Anything, delegationID).Return(&entities.Delegation{
+		ID:        delegationID,
+		Delegator: &entities.User{FullName: "Dr. Head"},
+		Delegate:  &entities.User{FullName: "Sam Secretary"},
+	}, nil)
+
+	service := &VerificationService{documentRepo: mockDocRepo}
+	service.SetDelegations(NewDelegationService(delegationRepo, new(MockUserRepository), &config.Config{}))
+
+	info, err := service.GetVerificationInfo(context.Background(), "doc-123", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Sam Secretary", info.SignedBy)
+	assert.Equal(t, "Dr. Head", info.OnBehalfOf)
+}
+
 func TestVerificationService_VerifyDocument(t *testing.T) {
 	// Test data
 	testHash := []byte("test-hash")
@@ -310,23 +370,101 @@ func TestVerificationService_VerifyDocument(t *testing.T) {
 	}
 }
 
+func TestVerificationService_VerifyByFile(t *testing.T) {
+	testHash := []byte("test-hash")
+	stampedHash := []byte("stamped-hash")
+	testSignature := &crypto.SignatureData{Signature: []byte("test-signature"), Hash: testHash, Algorithm: "RSA-PSS-SHA256"}
+	testSignatureJSON := `{"algorithm":"RSA-PSS-SHA256","hash":"dGVzdC1oYXNo","signature":"dGVzdC1zaWduYXR1cmU="}`
+	document := func(id string) *entities.Document {
+		qrCodeJSON, _ := json.Marshal(pdf.QRCodeData{DocID: id, Hash: base64.StdEncoding.EncodeToString(testHash)})
+		return &entities.Document{
+			ID:            id,
+			DocumentHash:  base64.StdEncoding.EncodeToString(testHash),
+			StampedHash:   base64.StdEncoding.EncodeToString(stampedHash),
+			SignatureData: testSignatureJSON,
+			QRCodeData:    string(qrCodeJSON),
+			Status:        "active",
+		}
+	}
+	newService := func(uploaded []byte, documents []*entities.Document) (*VerificationService, *MockDocumentRepository, *MockVerificationLogRepository) {
+		mockDocRepo := new(MockDocumentRepository)
+		mockLogRepo := new(MockVerificationLogRepository)
+		mockSigService := new(MockSignatureService)
+		mockPDFService := new(MockPDFService)
+		mockDocService := new(MockDocumentService)
+
+		mockPDFService.On("ValidatePDF", mock.Anything).Return(nil)
+		mockPDFService.On("CalculateHash", mock.Anything).Return(uploaded, nil)
+		mockDocRepo.On("GetVerifiableByHash", mock.Anything, base64.StdEncoding.EncodeToString(uploaded), maxFileMatches).Return(documents, nil)
+		mockDocService.On("DecodeSignatureData", testSignatureJSON).Return(testSignature, nil)
+		mockSigService.On("VerifySignature", testHash, testSignature).Return(nil)
+		mockLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.VerificationLog")).Return(nil)
+
+		return &VerificationService{
+			documentRepo:        mockDocRepo,
+			verificationLogRepo: mockLogRepo,
+			signatureService:    mockSigService,
+			pdfService:          mockPDFService,
+			documentService:     mockDocService,
+		}, mockDocRepo, mockLogRepo
+	}
+	request := &VerificationRequest{PDFData: []byte("%PDF-1.4 stamped content"), VerifierIP: "127.0.0.1"}
+
+	t.Run("stamped PDF matches every version", func(t *testing.T) {
+		service, _, mockLogRepo := newService(stampedHash, []*entities.Document{document("doc-2"), document("doc-1")})
+
+		results, err := service.VerifyByFile(context.Background(), request)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "doc-2", results[0].DocumentID)
+		for _, result := range results {
+			assert.Equal(t, StatusValid, result.Status)
+			assert.True(t, result.HashMatches)
+		}
+
+		// Each document records its own verification
+		mockLogRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entities.VerificationLog) bool {
+			return log.DocumentID == "doc-1" && log.VerificationResult == StatusValid
+		}))
+		mockLogRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		service, _, mockLogRepo := newService([]byte("unknown-hash"), []*entities.Document{})
+
+		_, err := service.VerifyByFile(context.Background(), request)
+		assert.ErrorIs(t, err, ErrNoMatchingDocument)
+		mockLogRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid PDF", func(t *testing.T) {
+		mockPDFService := new(MockPDFService)
+		mockPDFService.On("ValidatePDF", mock.Anything).Return(assert.AnError)
+		service := &VerificationService{pdfService: mockPDFService}
+
+		_, err := service.VerifyByFile(context.Background(), request)
+		assert.ErrorIs(t, err, ErrInvalidVerificationFile)
+	})
+}
+
 func TestVerificationService_GetVerificationHistory(t *testing.T) {
 	tests := []struct {
 		name          string
-		documentID    string
-		setupMocks    func(*MockDocumentRepository, *MockVerificationLogRepository)
+		userID        string
+		setupMocks    func(*MockDocumentService, *MockVerificationLogRepository)
 		expectedError string
 		expectedCount int
 	}{
 		{
-			name:       "successful history retrieval",
-			documentID: "doc-123",
-			setupMocks: func(docRepo *MockDocumentRepository, logRepo *MockVerificationLogRepository) {
+			name:   "successful history retrieval",
+			userID: "user-123",
+			setupMocks: func(docService *MockDocumentService, logRepo *MockVerificationLogRepository) {
 				document := &entities.Document{
 					ID:     "doc-123",
+					UserID: "user-123",
 					Status: "active",
 				}
-				docRepo.On("GetByID", mock.Anything, "doc-123").Return(document, nil)
+				docService.On("GetDocumentByID", mock.Anything, "user-123", "doc-123").Return(document, nil)
 
 				logs := []*entities.VerificationLog{
 					{
@@ -348,33 +486,43 @@ func TestVerificationService_GetVerificationHistory(t *testing.T) {
 			expectedCount: 2,
 		},
 		{
-			name:       "document not found",
-			documentID: "doc-123",
-			setupMocks: func(docRepo *MockDocumentRepository, logRepo *MockVerificationLogRepository) {
-				docRepo.On("GetByID", mock.Anything, "doc-123").Return((*entities.Document)(nil), nil)
+			name:   "document not found",
+			userID: "user-123",
+			setupMocks: func(docService *MockDocumentService, logRepo *MockVerificationLogRepository) {
+				docService.On("GetDocumentByID", mock.Anything, "user-123", "doc-123").Return((*entities.Document)(nil), fmt.Errorf("document not found"))
 			},
 			expectedError: "document not found",
 			expectedCount: 0,
 		},
+		{
+			// Verifiers' IP addresses are not shown to other users
+			name:   "document of another user",
+			userID: "other-user",
+			setupMocks: func(docService *MockDocumentService, logRepo *MockVerificationLogRepository) {
+				docService.On("GetDocumentByID", mock.Anything, "other-user", "doc-123").Return((*entities.Document)(nil), fmt.Errorf("access denied: document belongs to different user"))
+			},
+			expectedError: "access denied",
+			expectedCount: 0,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create mocks
-			mockDocRepo := new(MockDocumentRepository)
+			mockDocService := new(MockDocumentService)
 			mockLogRepo := new(MockVerificationLogRepository)
 
 			// Setup mocks
-			tt.setupMocks(mockDocRepo, mockLogRepo)
+			tt.setupMocks(mockDocService, mockLogRepo)
 
 			// Create service
 			service := &VerificationService{
-				documentRepo:        mockDocRepo,
 				verificationLogRepo: mockLogRepo,
+				documentService:     mockDocService,
 			}
 
 			// Execute
-			history, err := service.GetVerificationHistory(context.Background(), tt.documentID)
+			history, err := service.GetVerificationHistory(context.Background(), tt.userID, "doc-123")
 
 			// Assert
 			if tt.expectedError != "" {
@@ -388,7 +536,7 @@ func TestVerificationService_GetVerificationHistory(t *testing.T) {
 			}
 
 			// Verify mocks
-			mockDocRepo.AssertExpectations(t)
+			mockDocService.AssertExpectations(t)
 			mockLogRepo.AssertExpectations(t)
 		})
 	}
@@ -403,3 +551,8 @@ func (m *MockDocumentService) DecodeSignatureData(signatureDataStr string) (*cry
 	args := m.Called(signatureDataStr)
 	return args.Get(0).(*crypto.SignatureData), args.Error(1)
 }
+
+func (m *MockDocumentService) GetDocumentByID(ctx context.Context, userID, documentID string) (*entities.Document, error) {
+	args := m.Called(ctx, userID, documentID)
+	return args.Get(0).(*entities.Document), args.Error(1)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+// Webhook request headers
+const (
+	WebhookHeaderEvent        = "X-Webhook-Event"
+	WebhookHeaderEventID      = "X-Webhook-ID"
+	WebhookHeaderDeliveryID   = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp    = "X-Webhook-Timestamp"
+	WebhookHeaderSignature    = "X-Webhook-Signature"
+	WebhookHeaderKeySignature = "X-Webhook-Key-Signature"
+)
+
+const (
+	webhookClaimBatch       = 20
+	webhookMaxResponseBytes = 256
+)
+
+var errWebhookAddressBlocked = errors.New("webhook receiver address is not public")
+
+// WebhookDispatcher delivers pending outbox entries and schedules retries
+type WebhookDispatcher struct {
+	subscriptionRepo repositories.WebhookSubscriptionRepository
+	deliveryRepo     repositories.WebhookDeliveryRepository
+	signer           SignatureServiceInterface
+	client           *http.Client
+	pollInterval     time.Duration
+	timeout          time.Duration
+	retryBase        time.Duration
+	retryMax         time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a dispatcher; signer is optional and adds a key signature header
+func NewWebhookDispatcher(
+	subscriptionRepo repositories.WebhookSubscriptionRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	signer SignatureServiceInterface,
+	cfg *config.Config,
+) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		signer:           signer,
+		pollInterval:     cfg.WebhookPollInterval,
+		timeout:          cfg.WebhookTimeout,
+		retryBase:        cfg.WebhookRetryBase,
+		retryMax:         cfg.WebhookRetryMax,
+	}
+	if d.pollInterval <= 0 {
+		d.pollInterval = 5 * time.Second
+	}
+	if d.timeout <= 0 {
+		d.timeout = 10 * time.Second
+	}
+	if d.retryBase <= 0 {
+		d.retryBase = 30 * time.Second
+	}
+	if d.retryMax < d.retryBase {
+		d.retryMax = d.retryBase
+	}
+	// The receiver address is checked again when connecting, since DNS may have changed
+	// since the URL was validated
+	dialer := &net.Dialer{Timeout: d.timeout, KeepAlive: 30 * time.Second}
+	if !cfg.WebhookAllowPrivate {
+		dialer.Control = webhookDialControl
+	}
+	d.client = &http.Client{
+		Timeout: d.timeout,
+		// No proxy, so the dialer sees the receiver's own address
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		// Receivers must answer directly; following redirects would bypass URL validation
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return d
+}
+
+// Start launches the background delivery loop
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.DeliverDue(ctx); err != nil {
+				fmt.Printf("Warning: Webhook dispatcher error: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the delivery loop and waits for in-flight requests
+func (d *WebhookDispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// DeliverDue sends every delivery that is due and returns how many were attempted
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		// The lease outlasts the request timeout so a slow receiver is not sent the same event twice
+		deliveries, err := d.deliveryRepo.ClaimDue(ctx, webhookClaimBatch, time.Now().Add(2*d.timeout+time.Minute))
+		if err != nil {
+			return total, err
+		}
+
+		for _, delivery := range deliveries {
+			d.attempt(ctx, delivery)
+		}
+		total += len(deliveries)
+
+		if len(deliveries) < webhookClaimBatch || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *entities.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	sub, err := d.subscriptionRepo.GetByID(ctx, delivery.SubscriptionID)
+	switch {
+	case err != nil:
+		delivery.Error = err.Error()
+		d.scheduleRetry(delivery, now)
+	case sub == nil:
+		delivery.Error = "subscription no longer exists"
+		delivery.Status = entities.WebhookDeliveryDead
+	case !sub.IsActive:
+		delivery.Error = "subscription is inactive"
+		delivery.Status = entities.WebhookDeliveryDead
+	default:
+		d.send(ctx, sub, delivery)
+	}
+
+	delivery.UpdatedAt = time.Now()
+	saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.deliveryRepo.Update(saveCtx, delivery); err != nil {
+		fmt.Printf("Warning: Failed to update webhook delivery %s: %v\n", delivery.ID, err)
+	}
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, sub *entities.WebhookSubscription, delivery *entities.WebhookDelivery) {
+	start := time.Now()
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		delivery.Status = entities.WebhookDeliveryDead
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DigitalSignatureSystem-Webhook/1.0")
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderEventID, delivery.EventID)
+	req.Header.Set(WebhookHeaderDeliveryID, delivery.ID)
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhookPayload(sub.Secret, timestamp, body))
+
+	if d.signer != nil {
+		digest := sha256.Sum256(webhookSignedContent(timestamp, body))
+		if signature, err := d.signer.SignDocument(digest[:]); err == nil {
+			req.Header.Set(WebhookHeaderKeySignature, base64.StdEncoding.EncodeToString(signature.Signature))
+		} else {
+			fmt.Printf("Warning: Failed to sign webhook payload with signing key: %v\n", err)
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		d.scheduleRetry(delivery, start)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBytes))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = webhookResponseSnippet(respBody)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		deliveredAt := time.Now()
+		delivery.Status = entities.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &deliveredAt
+		return
+	}
+
+	delivery.Error = fmt.Sprintf("receiver responded with status %d", resp.StatusCode)
+	d.scheduleRetry(delivery, start)
+}
+
+// webhookDialControl refuses connections to addresses a subscription could not be created with
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicWebhookIP(ip) {
+		return errWebhookAddressBlocked
+	}
+	return nil
+}
+
+// webhookResponseSnippet keeps only printable text from the start of a receiver's response
+func webhookResponseSnippet(body []byte) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsPrint(r):
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		default:
+			return -1
+		}
+	}, strings.ToValidUTF8(string(body), ""))
+}
+
+// scheduleRetry backs off exponentially and moves exhausted deliveries to the dead-letter list
+func (d *WebhookDispatcher) scheduleRetry(delivery *entities.WebhookDelivery, now time.Time) {
+	if delivery.Attempts >= delivery.MaxAttempts {
+		delivery.Status = entities.WebhookDeliveryDead
+		return
+	}
+
+	delay := d.retryBase
+	for i := 1; i < delivery.Attempts && delay < d.retryMax; i++ {
+		delay *= 2
+	}
+	if delay > d.retryMax {
+		delay = d.retryMax
+	}
+
+	delivery.Status = entities.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(delay)
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 that receivers compare against X-Webhook-Signature
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(webhookSignedContent(timestamp, body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSignedContent binds the timestamp to the body so captured requests cannot be replayed later
+func webhookSignedContent(timestamp string, body []byte) []byte {
+	content := make([]byte, 0, len(timestamp)+1+len(body))
+	content = append(content, timestamp...)
+	content = append(content, '.')
+	return append(content, body...)
+}
@@ -0,0 +1,498 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookAccess           = errors.New("access denied: webhook belongs to different user")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL       = errors.New("invalid webhook URL")
+	ErrInvalidWebhookEvents    = errors.New("invalid webhook events")
+)
+
+// supportedWebhookEvents lists the event types a subscription may filter on
+var supportedWebhookEvents = map[string]bool{
+	entities.WebhookEventDocumentSigned:           true,
+	entities.WebhookEventDocumentRevoked:          true,
+	entities.WebhookEventDocumentVerified:         true,
+	entities.WebhookEventDocumentForgerySuspected: true,
+	entities.WebhookEventAll:                      true,
+}
+
+// EventPublisher receives domain events that should be sent to external subscribers
+// for a user and, when the event concerns an organization's document, for that organization
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType, userID string, organizationID *string, data interface{})
+}
+
+// WebhookEvent is the JSON body delivered to subscribers
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookSubscriptionRequest describes a new or updated subscription
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// webhookResolver looks up the addresses of a receiver host
+type webhookResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// WebhookService manages webhook subscriptions and writes events to the delivery outbox
+type WebhookService struct {
+	subscriptionRepo repositories.WebhookSubscriptionRepository
+	deliveryRepo     repositories.WebhookDeliveryRepository
+	config           *config.Config
+	resolver         webhookResolver
+	organizations    *OrganizationService
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	subscriptionRepo repositories.WebhookSubscriptionRepository,
+	deliveryRepo repositories.WebhookDeliveryRepository,
+	config *config.Config,
+) *WebhookService {
+	return &WebhookService{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		config:           config,
+		resolver:         net.DefaultResolver,
+	}
+}
+
+// SetOrganizations lets organization admins manage their organization's subscriptions
+func (s *WebhookService) SetOrganizations(organizations *OrganizationService) {
+	s.organizations = organizations
+}
+
+// CreateSubscription registers a webhook endpoint and generates its signing secret. With an
+// organizationID the subscription is the organization's and the actor must administer it.
+func (s *WebhookService) CreateSubscription(ctx context.Context, actorID, organizationID string, req *WebhookSubscriptionRequest) (*entities.WebhookSubscription, string, error) {
+	if err := s.authorize(ctx, actorID, organizationID); err != nil {
+		return nil, "", err
+	}
+	if err := s.validateURL(ctx, req.URL); err != nil {
+		return nil, "", err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	sub := &entities.WebhookSubscription{
+		UserID:      actorID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if organizationID != "" {
+		sub.OrganizationID = &organizationID
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, sub); err != nil {
+		return nil, "", fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return sub, secret, nil
+}
+
+// ListSubscriptions returns the user's own webhook subscriptions, or the organization's
+func (s *WebhookService) ListSubscriptions(ctx context.Context, actorID, organizationID string) ([]*entities.WebhookSubscription, error) {
+	if err := s.authorize(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+
+	var subs []*entities.WebhookSubscription
+	var err error
+	if organizationID != "" {
+		subs, err = s.subscriptionRepo.GetByOrganizationID(ctx, organizationID)
+	} else {
+		subs, err = s.subscriptionRepo.GetByUserID(ctx, actorID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// GetSubscription retrieves a subscription owned by the user, or by the organization
+func (s *WebhookService) GetSubscription(ctx context.Context, actorID, organizationID, subscriptionID string) (*entities.WebhookSubscription, error) {
+	if err := s.authorize(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+
+	sub, err := s.subscriptionRepo.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	if sub == nil {
+		return nil, ErrWebhookNotFound
+	}
+	if !webhookOwnedBy(sub.UserID, sub.OrganizationID, actorID, organizationID) {
+		return nil, ErrWebhookAccess
+	}
+	return sub, nil
+}
+
+// UpdateSubscription changes the URL, event filter, description or active flag
+func (s *WebhookService) UpdateSubscription(ctx context.Context, actorID, organizationID, subscriptionID string, req *WebhookSubscriptionRequest) (*entities.WebhookSubscription, error) {
+	sub, err := s.GetSubscription(ctx, actorID, organizationID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := s.validateURL(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = req.URL
+	}
+	if req.Events != nil {
+		events, err := normalizeWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		sub.Events = events
+	}
+	if req.Description != "" {
+		sub.Description = req.Description
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+	sub.UpdatedAt = time.Now()
+
+	if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// RotateSecret replaces the subscription's signing secret
+func (s *WebhookService) RotateSecret(ctx context.Context, actorID, organizationID, subscriptionID string) (*entities.WebhookSubscription, string, error) {
+	sub, err := s.GetSubscription(ctx, actorID, organizationID, subscriptionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	sub.Secret = secret
+	sub.UpdatedAt = time.Now()
+
+	if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+		return nil, "", fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+	return sub, secret, nil
+}
+
+// DeleteSubscription removes a subscription; its delivery log is kept
+func (s *WebhookService) DeleteSubscription(ctx context.Context, actorID, organizationID, subscriptionID string) error {
+	if _, err := s.GetSubscription(ctx, actorID, organizationID, subscriptionID); err != nil {
+		return err
+	}
+	if err := s.subscriptionRepo.Delete(ctx, subscriptionID); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log, optionally narrowed to one subscription or status
+func (s *WebhookService) ListDeliveries(ctx context.Context, actorID, organizationID, subscriptionID, status string, limit int) ([]*entities.WebhookDelivery, error) {
+	if subscriptionID != "" {
+		if _, err := s.GetSubscription(ctx, actorID, organizationID, subscriptionID); err != nil {
+			return nil, err
+		}
+	} else if err := s.authorize(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.deliveryRepo.List(ctx, repositories.WebhookDeliveryFilter{
+		UserID:         actorID,
+		OrganizationID: organizationID,
+		SubscriptionID: subscriptionID,
+		Status:         status,
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a delivery again, including ones moved to the dead-letter list
+func (s *WebhookService) Redeliver(ctx context.Context, actorID, organizationID, deliveryID string) (*entities.WebhookDelivery, error) {
+	if err := s.authorize(ctx, actorID, organizationID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if !webhookOwnedBy(delivery.UserID, delivery.OrganizationID, actorID, organizationID) {
+		return nil, ErrWebhookAccess
+	}
+
+	now := time.Now()
+	delivery.Status = entities.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.MaxAttempts = s.maxAttempts()
+	delivery.NextAttemptAt = now
+	delivery.DeliveredAt = nil
+	delivery.UpdatedAt = now
+
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// Publish writes one outbox entry per matching subscription of the user and, for an
+// organization's document, of the organization. Failures are logged, not returned,
+// so a broken webhook never fails the operation that raised the event
+func (s *WebhookService) Publish(ctx context.Context, eventType, userID string, organizationID *string, data interface{}) {
+	subs, err := s.subscriptionRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to load webhook subscriptions: %v\n", err)
+		return
+	}
+	if organizationID != nil {
+		orgSubs, err := s.subscriptionRepo.GetByOrganizationID(ctx, *organizationID)
+		if err != nil {
+			fmt.Printf("Warning: Failed to load webhook subscriptions of organization %s: %v\n", *organizationID, err)
+		}
+		subs = append(subs, orgSubs...)
+	}
+
+	event := WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		fmt.Printf("Warning: Failed to marshal webhook event: %v\n", err)
+		return
+	}
+
+	for _, sub := range subs {
+		if !sub.IsActive || !sub.Subscribes(eventType) {
+			continue
+		}
+
+		now := time.Now()
+		delivery := &entities.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			UserID:         userID,
+			OrganizationID: sub.OrganizationID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         entities.WebhookDeliveryPending,
+			MaxAttempts:    s.maxAttempts(),
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+			fmt.Printf("Warning: Failed to queue webhook delivery for subscription %s: %v\n", sub.ID, err)
+		}
+	}
+}
+
+// authorize requires the actor to administer the organization whose webhooks they manage;
+// a user's own webhooks need no further check
+func (s *WebhookService) authorize(ctx context.Context, actorID, organizationID string) error {
+	if organizationID == "" {
+		return nil
+	}
+	if s.organizations == nil {
+		return ErrOrganizationNotFound
+	}
+	_, err := s.organizations.requireAdmin(ctx, actorID, organizationID)
+	return err
+}
+
+// webhookOwnedBy reports whether a subscription or delivery belongs to the organization,
+// or, outside one, to the user alone
+func webhookOwnedBy(userID string, ownerOrganizationID *string, actorID, organizationID string) bool {
+	if organizationID != "" {
+		return ownerOrganizationID != nil && *ownerOrganizationID == organizationID
+	}
+	return ownerOrganizationID == nil && userID == actorID
+}
+
+func (s *WebhookService) maxAttempts() int {
+	if s.config.WebhookMaxAttempts > 0 {
+		return s.config.WebhookMaxAttempts
+	}
+	return 1
+}
+
+// validateURL only accepts absolute http(s) URLs whose host resolves to public addresses,
+// and requires https in production
+func (s *WebhookService) validateURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: must be an absolute URL", ErrInvalidWebhookURL)
+	}
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if s.config.Environment == "production" {
+			return fmt.Errorf("%w: https is required", ErrInvalidWebhookURL)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported scheme %q", ErrInvalidWebhookURL, parsed.Scheme)
+	}
+	if s.config.WebhookAllowPrivate {
+		return nil
+	}
+
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicWebhookIP(ip) {
+			return fmt.Errorf("%w: host is not a public address", ErrInvalidWebhookURL)
+		}
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: host %q could not be resolved", ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if !isPublicWebhookIP(addr.IP) {
+			return fmt.Errorf("%w: host %q resolves to a non-public address", ErrInvalidWebhookURL, host)
+		}
+	}
+	return nil
+}
+
+// webhookBlockedNets are special-purpose ranges not covered by the net.IP predicates
+var webhookBlockedNets = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // "this" network
+		"100.64.0.0/10",   // carrier-grade NAT
+		"192.0.0.0/24",    // IETF protocol assignments
+		"192.0.2.0/24",    // documentation
+		"198.18.0.0/15",   // benchmarking
+		"198.51.100.0/24", // documentation
+		"203.0.113.0/24",  // documentation
+		"240.0.0.0/4",     // reserved and broadcast
+		"64:ff9b::/96",    // NAT64, may embed an internal IPv4 address
+		"64:ff9b:1::/48",  // local-use NAT64
+		"100::/64",        // discard
+		"2001:db8::/32",   // documentation
+		"2002::/16",       // 6to4, may embed an internal IPv4 address
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPublicWebhookIP rejects loopback, private, link-local (including cloud metadata),
+// multicast and other special-purpose addresses as webhook receivers
+func isPublicWebhookIP(ip net.IP) bool {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range webhookBlockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return []string{entities.WebhookEventAll}, nil
+	}
+
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !supportedWebhookEvents[event] {
+			return nil, fmt.Errorf("%w: unsupported event %q", ErrInvalidWebhookEvents, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// documentEventData is the document summary included in lifecycle events
+func documentEventData(document *entities.Document) map[string]interface{} {
+	return map[string]interface{}{
+		"document_id":   document.ID,
+		"filename":      document.Filename,
+		"issuer":        document.Issuer,
+		"title":         document.Title,
+		"letter_number": document.LetterNumber,
+		"document_hash": document.DocumentHash,
+		"status":        document.Status,
+		"created_at":    document.CreatedAt,
+	}
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	infracrypto "digital-signature-system/internal/infrastructure/crypto"
+)
+
+type MockWebhookSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSubscriptionRepository) Create(ctx context.Context, sub *entities.WebhookSubscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) GetByID(ctx context.Context, id string) (*entities.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) GetByUserID(ctx context.Context, userID string) ([]*entities.WebhookSubscription, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) GetByOrganizationID(ctx context.Context, organizationID string) ([]*entities.WebhookSubscription, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) Update(ctx context.Context, sub *entities.WebhookSubscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) List(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, leaseUntil)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebhookDelivery), args.Error(1)
+}
+
+// recordingReceiver is an httptest webhook endpoint that captures requests
+type recordingReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *recordingReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := r.status
+	r.mu.Unlock()
+	w.WriteHeader(status)
+	w.Write([]byte("ok"))
+}
+
+// rsaTestSigner signs with a throwaway key so the receiver side can verify the key signature
+type rsaTestSigner struct {
+	key *rsa.PrivateKey
+}
+
+func (s *rsaTestSigner) SignDocument(hash []byte) (*infracrypto.SignatureData, error) {
+	signature, err := rsa.SignPSS(rand.Reader, s.key, crypto.SHA256, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &infracrypto.SignatureData{Signature: signature, Hash: hash, Algorithm: "RSA-PSS-SHA256"}, nil
+}
+
+func (s *rsaTestSigner) VerifySignature(hash []byte, data *infracrypto.SignatureData) error {
+	return rsa.VerifyPSS(&s.key.PublicKey, crypto.SHA256, hash, data.Signature, nil)
+}
+
+// staticResolver answers host lookups from a fixed table
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	subRepo := new(MockWebhookSubscriptionRepository)
+	subRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.WebhookSubscription")).Return(nil)
+
+	service := NewWebhookService(subRepo, new(MockWebhookDeliveryRepository), &config.Config{Environment: "production"})
+	service.resolver = staticResolver{"records.example.com": {"93.184.216.34"}}
+	ctx := context.Background()
+
+	sub, secret, err := service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{
+		URL:    "https://records.example.com/hooks",
+		Events: []string{"Document.Signed", "document.signed", "document.revoked"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	assert.Equal(t, secret, sub.Secret)
+	assert.Equal(t, []string{"document.signed", "document.revoked"}, sub.Events)
+	assert.True(t, sub.IsActive)
+
+	_, _, err = service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{URL: "http://records.example.com/hooks"})
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	_, _, err = service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{URL: "ftp://records.example.com"})
+	assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+
+	_, _, err = service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{
+		URL:    "https://records.example.com/hooks",
+		Events: []string{"document.archived"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidWebhookEvents)
+}
+
+func TestWebhookService_CreateSubscription_RejectsNonPublicHosts(t *testing.T) {
+	subRepo := new(MockWebhookSubscriptionRepository)
+	subRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.WebhookSubscription")).Return(nil)
+
+	service := NewWebhookService(subRepo, new(MockWebhookDeliveryRepository), &config.Config{})
+	service.resolver = staticResolver{
+		"localhost":            {"127.0.0.1", "::1"},
+		"internal.example.com": {"10.1.2.3"},
+		"mixed.example.com":    {"93.184.216.34", "192.168.1.10"},
+		"records.example.com":  {"93.184.216.34", "2606:2800:220:1::1"},
+	}
+	ctx := context.Background()
+
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hooks",
+		"http://[fd00:ec2::254]/hooks",
+		"http://[::ffff:192.168.1.1]/hooks",
+		"http://100.64.0.1/hooks",
+		"http://0.0.0.0/hooks",
+		"https://internal.example.com/hooks",
+		"https://mixed.example.com/hooks",
+		"https://unknown.example.com/hooks",
+	} {
+		_, _, err := service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{URL: rawURL})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+	}
+
+	_, _, err := service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{URL: "https://records.example.com/hooks"})
+	assert.NoError(t, err)
+
+	// Local development may opt in to private receivers
+	service.config.WebhookAllowPrivate = true
+	_, _, err = service.CreateSubscription(ctx, "user-123", "", &WebhookSubscriptionRequest{URL: "http://localhost:9000/hooks"})
+	assert.NoError(t, err)
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+
+	subRepo.On("GetByUserID", mock.Anything, "user-123").Return([]*entities.WebhookSubscription{
+		{ID: "sub-signed", UserID: "user-123", Events: []string{entities.WebhookEventDocumentSigned}, IsActive: true},
+		{ID: "sub-all", UserID: "user-123", Events: []string{entities.WebhookEventAll}, IsActive: true},
+		{ID: "sub-verified", UserID: "user-123", Events: []string{entities.WebhookEventDocumentVerified}, IsActive: true},
+		{ID: "sub-inactive", UserID: "user-123", Events: []string{entities.WebhookEventAll}, IsActive: false},
+	}, nil)
+
+	var created []*entities.WebhookDelivery
+	deliveryRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.WebhookDelivery")).
+		Run(func(args mock.Arguments) {
+			created = append(created, args.Get(1).(*entities.WebhookDelivery))
+		}).Return(nil)
+
+	service := NewWebhookService(subRepo, deliveryRepo, &config.Config{WebhookMaxAttempts: 5})
+	service.Publish(context.Background(), entities.WebhookEventDocumentSigned, "user-123", nil, map[string]string{"document_id": "doc-1"})
+
+	require.Len(t, created, 2)
+	assert.Equal(t, "sub-signed", created[0].SubscriptionID)
+	assert.Equal(t, "sub-all", created[1].SubscriptionID)
+	assert.Equal(t, created[0].EventID, created[1].EventID)
+	assert.Equal(t, entities.WebhookDeliveryPending, created[0].Status)
+	assert.Equal(t, 5, created[0].MaxAttempts)
+
+	var event WebhookEvent
+	require.NoError(t, json.Unmarshal([]byte(created[0].Payload), &event))
+	assert.Equal(t, entities.WebhookEventDocumentSigned, event.Type)
+	assert.Equal(t, map[string]interface{}{"document_id": "doc-1"}, event.Data)
+}
+
+func TestWebhookService_Publish_OrganizationDocument(t *testing.T) {
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	orgID := "org-1"
+
+	subRepo.On("GetByUserID", mock.Anything, "user-123").Return([]*entities.WebhookSubscription{
+		{ID: "sub-user", UserID: "user-123", Events: []string{entities.WebhookEventAll}, IsActive: true},
+	}, nil)
+	subRepo.On("GetByOrganizationID", mock.Anything, orgID).Return([]*entities.WebhookSubscription{
+		{ID: "sub-org", UserID: "admin", OrganizationID: &orgID, Events: []string{entities.WebhookEventDocumentSigned}, IsActive: true},
+	}, nil)
+
+	var created []*entities.WebhookDelivery
+	deliveryRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.WebhookDelivery")).
+		Run(func(args mock.Arguments) {
+			created = append(created, args.Get(1).(*entities.WebhookDelivery))
+		}).Return(nil)
+
+	service := NewWebhookService(subRepo, deliveryRepo, &config.Config{WebhookMaxAttempts: 5})
+	service.Publish(context.Background(), entities.WebhookEventDocumentSigned, "user-123", &orgID, map[string]string{"document_id": "doc-1"})
+
+	require.Len(t, created, 2)
+	assert.Equal(t, "sub-user", created[0].SubscriptionID)
+	assert.Nil(t, created[0].OrganizationID)
+	assert.Equal(t, "sub-org", created[1].SubscriptionID)
+	require.NotNil(t, created[1].OrganizationID)
+	assert.Equal(t, orgID, *created[1].OrganizationID)
+	assert.Equal(t, "user-123", created[1].UserID)
+}
+
+func TestWebhookService_OrganizationSubscriptions(t *testing.T) {
+	orgs, orgRepo, _ := newTestOrganizationService(t)
+	orgRepo.On("GetByID", mock.Anything, "org-1").Return(&entities.Organization{ID: "org-1", IsActive: true}, nil)
+	orgRepo.On("GetMember", mock.Anything, "org-1", "admin").Return(&entities.OrganizationMember{UserID: "admin", Role: entities.OrganizationRoleAdmin}, nil)
+	orgRepo.On("GetMember", mock.Anything, "org-1", "member").Return(&entities.OrganizationMember{UserID: "member", Role: entities.OrganizationRoleMember}, nil)
+
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	service := NewWebhookService(subRepo, deliveryRepo, &config.Config{WebhookMaxAttempts: 3})
+	service.resolver = staticResolver{"records.example.com": {"93.184.216.34"}}
+	service.SetOrganizations(orgs)
+	ctx := context.Background()
+
+	subRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.WebhookSubscription")).Return(nil)
+	sub, _, err := service.CreateSubscription(ctx, "admin", "org-1", &WebhookSubscriptionRequest{URL: "https://records.example.com/hooks"})
+	require.NoError(t, err)
+	require.NotNil(t, sub.OrganizationID)
+	assert.Equal(t, "org-1", *sub.OrganizationID)
+	assert.Equal(t, "admin", sub.UserID)
+
+	// Members who do not administer the organization cannot manage its webhooks
+	_, _, err = service.CreateSubscription(ctx, "member", "org-1", &WebhookSubscriptionRequest{URL: "https://records.example.com/hooks"})
+	assert.ErrorIs(t, err, ErrOrganizationAccess)
+	_, err = service.ListSubscriptions(ctx, "member", "org-1")
+	assert.ErrorIs(t, err, ErrOrganizationAccess)
+
+	sub.ID = "sub-org"
+	subRepo.On("GetByID", mock.Anything, "sub-org").Return(sub, nil)
+	_, err = service.GetSubscription(ctx, "admin", "org-1", "sub-org")
+	assert.NoError(t, err)
+	// ...and its creator does not own it personally
+	_, err = service.GetSubscription(ctx, "admin", "", "sub-org")
+	assert.ErrorIs(t, err, ErrWebhookAccess)
+
+	deliveryRepo.On("List", mock.Anything, repositories.WebhookDeliveryFilter{
+		UserID:         "admin",
+		OrganizationID: "org-1",
+		SubscriptionID: "sub-org",
+		Limit:          50,
+	}).Return([]*entities.WebhookDelivery{}, nil)
+	_, err = service.ListDeliveries(ctx, "admin", "org-1", "sub-org", "", 50)
+	assert.NoError(t, err)
+
+	orgID := "org-1"
+	delivery := &entities.WebhookDelivery{ID: "delivery-1", UserID: "member", OrganizationID: &orgID, Status: entities.WebhookDeliveryDead}
+	deliveryRepo.On("GetByID", mock.Anything, "delivery-1").Return(delivery, nil)
+	deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+
+	// The document's signer does not see the organization's deliveries as their own
+	_, err = service.Redeliver(ctx, "member", "", "delivery-1")
+	assert.ErrorIs(t, err, ErrWebhookAccess)
+	_, err = service.Redeliver(ctx, "admin", "org-1", "delivery-1")
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	dead := &entities.WebhookDelivery{ID: "delivery-1", UserID: "user-123", Status: entities.WebhookDeliveryDead, Attempts: 8}
+	deliveryRepo.On("GetByID", mock.Anything, "delivery-1").Return(dead, nil)
+	deliveryRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+	deliveryRepo.On("Update", mock.Anything, dead).Return(nil)
+
+	service := NewWebhookService(new(MockWebhookSubscriptionRepository), deliveryRepo, &config.Config{WebhookMaxAttempts: 3})
+	ctx := context.Background()
+
+	delivery, err := service.Redeliver(ctx, "user-123", "", "delivery-1")
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+	assert.Equal(t, 3, delivery.MaxAttempts)
+
+	_, err = service.Redeliver(ctx, "other-user", "", "delivery-1")
+	assert.ErrorIs(t, err, ErrWebhookAccess)
+
+	_, err = service.Redeliver(ctx, "user-123", "", "missing")
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookDispatcher_DeliverDue_SignsPayload(t *testing.T) {
+	receiver := &recordingReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := &rsaTestSigner{key: privateKey}
+
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	sub := &entities.WebhookSubscription{ID: "sub-1", URL: server.URL, Secret: "whsec_test", IsActive: true}
+	delivery := &entities.WebhookDelivery{
+		ID:             "delivery-1",
+		SubscriptionID: "sub-1",
+		EventID:        "event-1",
+		EventType:      entities.WebhookEventDocumentSigned,
+		Payload:        `{"id":"event-1","type":"document.signed"}`,
+		MaxAttempts:    3,
+	}
+
+	subRepo.On("GetByID", mock.Anything, "sub-1").Return(sub, nil)
+	deliveryRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.WebhookDelivery{delivery}, nil).Once()
+	deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+
+	dispatcher := NewWebhookDispatcher(subRepo, deliveryRepo, signer, &config.Config{WebhookAllowPrivate: true})
+	count, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, entities.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.Equal(t, "ok", delivery.ResponseBody)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	require.Len(t, receiver.requests, 1)
+	req := receiver.requests[0]
+	body := receiver.bodies[0]
+	assert.Equal(t, delivery.Payload, string(body))
+	assert.Equal(t, entities.WebhookEventDocumentSigned, req.Header.Get(WebhookHeaderEvent))
+	assert.Equal(t, "event-1", req.Header.Get(WebhookHeaderEventID))
+
+	// Receivers recompute the HMAC over "timestamp.body" with their copy of the secret
+	timestamp := req.Header.Get(WebhookHeaderTimestamp)
+	assert.Equal(t, "sha256="+SignWebhookPayload("whsec_test", timestamp, body), req.Header.Get(WebhookHeaderSignature))
+
+	// ...or verify the key signature with the system's public key
+	keySignature, err := base64.StdEncoding.DecodeString(req.Header.Get(WebhookHeaderKeySignature))
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(timestamp + "." + string(body)))
+	assert.NoError(t, rsa.VerifyPSS(&privateKey.PublicKey, crypto.SHA256, digest[:], keySignature, nil))
+}
+
+func TestWebhookDispatcher_RetriesAndDeadLetters(t *testing.T) {
+	receiver := &recordingReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	subRepo.On("GetByID", mock.Anything, "sub-1").Return(&entities.WebhookSubscription{ID: "sub-1", URL: server.URL, Secret: "s", IsActive: true}, nil)
+
+	delivery := &entities.WebhookDelivery{ID: "delivery-1", SubscriptionID: "sub-1", Payload: "{}", MaxAttempts: 3}
+	deliveryRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.WebhookDelivery{delivery}, nil)
+	deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+
+	dispatcher := NewWebhookDispatcher(subRepo, deliveryRepo, nil, &config.Config{
+		WebhookRetryBase:    time.Minute,
+		WebhookRetryMax:     90 * time.Second,
+		WebhookAllowPrivate: true,
+	})
+
+	before := time.Now()
+	_, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Contains(t, delivery.Error, "status 500")
+	assert.WithinDuration(t, before.Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+	assert.Empty(t, receiver.requests[0].Header.Get(WebhookHeaderKeySignature))
+
+	// The second retry doubles the delay but is capped at the configured maximum
+	_, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(90*time.Second), delivery.NextAttemptAt, 5*time.Second)
+
+	_, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Len(t, receiver.requests, 3)
+}
+
+func TestWebhookDispatcher_RefusesPrivateReceiver(t *testing.T) {
+	receiver := &recordingReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// The subscription passed validation, but its host now points at a loopback address
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	subRepo.On("GetByID", mock.Anything, "sub-1").Return(&entities.WebhookSubscription{ID: "sub-1", URL: server.URL, Secret: "s", IsActive: true}, nil)
+
+	delivery := &entities.WebhookDelivery{ID: "delivery-1", SubscriptionID: "sub-1", Payload: "{}", MaxAttempts: 3}
+	deliveryRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.WebhookDelivery{delivery}, nil).Once()
+	deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+
+	dispatcher := NewWebhookDispatcher(subRepo, deliveryRepo, nil, &config.Config{})
+	_, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, receiver.requests)
+	assert.Contains(t, delivery.Error, errWebhookAddressBlocked.Error())
+	assert.Equal(t, entities.WebhookDeliveryPending, delivery.Status)
+}
+
+func TestWebhookResponseSnippet(t *testing.T) {
+	assert.Equal(t, "ok", webhookResponseSnippet([]byte("ok")))
+	assert.Equal(t, "line one line two", webhookResponseSnippet([]byte("line one\nline\ttwo")))
+	assert.Equal(t, "ab", webhookResponseSnippet([]byte("a\x00\x1bb")))
+	assert.Equal(t, "caf", webhookResponseSnippet([]byte("caf\xc3")))
+}
+
+func TestWebhookDispatcher_InactiveSubscription(t *testing.T) {
+	subRepo := new(MockWebhookSubscriptionRepository)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	subRepo.On("GetByID", mock.Anything, "sub-1").Return(&entities.WebhookSubscription{ID: "sub-1", IsActive: false}, nil)
+
+	delivery := &entities.WebhookDelivery{ID: "delivery-1", SubscriptionID: "sub-1", MaxAttempts: 3}
+	deliveryRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.WebhookDelivery{delivery}, nil).Once()
+	deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+
+	dispatcher := NewWebhookDispatcher(subRepo, deliveryRepo, nil, &config.Config{})
+	_, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, "subscription is inactive", delivery.Error)
+}
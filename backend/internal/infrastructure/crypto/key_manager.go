@@ -45,7 +45,20 @@ func NewKeyManager() (*KeyManager, error) {
 		if publicKeyEnv == "" {
 			publicKeyEnv = os.Getenv("PUBLIC_KEY")
 		}
-		return NewKeyManagerFromEnv(privateKeyEnv, publicKeyEnv)
+		km, err := NewKeyManagerFromEnv(privateKeyEnv, publicKeyEnv)
+		if err != nil {
+			return nil, err
+		}
+		// Keys passed in the environment carry no timestamp; KEY_CREATED_AT
+		// (RFC 3339) dates them so key age checks are meaningful
+		if createdAt := os.Getenv("KEY_CREATED_AT"); createdAt != "" {
+			parsed, err := time.Parse(time.RFC3339, createdAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid KEY_CREATED_AT: %w", err)
+			}
+			km.createdAt = parsed
+		}
+		return km, nil
 	}
 
 	// Fallback to file-based keys
@@ -134,11 +147,17 @@ func NewKeyManagerFromFiles(privateKeyPath, publicKeyPath string) (*KeyManager,
 
 	keyID := generateKeyID(publicKey)
 
+	// The key file's modification time is the closest record of when the key was made
+	createdAt := time.Now()
+	if info, err := os.Stat(privateKeyPath); err == nil {
+		createdAt = info.ModTime()
+	}
+
 	km := &KeyManager{
 		privateKey: privateKey,
 		publicKey:  publicKey,
 		keyID:      keyID,
-		createdAt:  time.Now(),
+		createdAt:  createdAt,
 	}
 
 	// Validate keys during creation
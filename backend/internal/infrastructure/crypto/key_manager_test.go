@@ -233,6 +233,18 @@ func TestNewKeyManagerFromFiles(t *testing.T) {
 	}
 }
 
+func TestNewKeyManagerFromFiles_KeyAge(t *testing.T) {
+	privateKeyPath, publicKeyPath := createTestKeyFiles(t)
+	written := time.Now().Add(-400 * 24 * time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(privateKeyPath, written, written))
+
+	km, err := NewKeyManagerFromFiles(privateKeyPath, publicKeyPath)
+	require.NoError(t, err)
+
+	assert.True(t, km.GetCreatedAt().Equal(written))
+	assert.True(t, km.ShouldRotateKey(365*24*time.Hour))
+}
+
 func TestKeyManager_ValidateKeys(t *testing.T) {
 	// Create a valid key manager
 	validKM := createTestKeyManager(t)
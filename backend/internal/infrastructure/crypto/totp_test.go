@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 test key from RFC 6238 appendix B, base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; a 6 digit code is their last six digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "time %d", unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	code, err := TOTPCode(rfc6238Secret, step-1)
+	require.NoError(t, err)
+
+	matched, ok, err := ValidateTOTP(rfc6238Secret, code, now, 1)
+	require.NoError(t, err)
+	assert.True(t, ok, "a code from the previous step is accepted within the skew")
+	assert.Equal(t, step-1, matched)
+
+	_, ok, err = ValidateTOTP(rfc6238Secret, code, now, 0)
+	require.NoError(t, err)
+	assert.False(t, ok, "the previous step is rejected without skew")
+
+	_, ok, err = ValidateTOTP(rfc6238Secret, "12345", now, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = TOTPCode(secret, 1)
+	assert.NoError(t, err)
+
+	uri := TOTPProvisioningURI("Digital Signature", "alice", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Digital%20Signature:alice?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
+
+func TestSecretCipher(t *testing.T) {
+	cipher, err := NewSecretCipher("passphrase")
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt(rfc6238Secret)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, rfc6238Secret)
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, rfc6238Secret, decrypted)
+
+	other, err := NewSecretCipher("another passphrase")
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupAPIKeyTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE api_keys (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			prefix TEXT NOT NULL UNIQUE,
+			key_hash TEXT NOT NULL,
+			scopes TEXT,
+			rate_limit INTEGER,
+			expires_at DATETIME,
+			last_used_at DATETIME,
+			last_used_ip TEXT,
+			revoked_at DATETIME,
+			created_by TEXT,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create api_keys table: %v", err)
+	}
+
+	return db
+}
+
+func TestAPIKeyRepository_CreateAndLookup(t *testing.T) {
+	repo := NewAPIKeyRepository(setupAPIKeyTestDB(t))
+	ctx := context.Background()
+
+	key := &entities.APIKey{
+		UserID:    "service-1",
+		Name:      "SIS batch",
+		Prefix:    "dss_a1b2c3d4e5f6",
+		KeyHash:   "hash",
+		Scopes:    []string{entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+		RateLimit: 120,
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.GetByPrefix(ctx, "dss_a1b2c3d4e5f6")
+	if err != nil {
+		t.Fatalf("GetByPrefix() error = %v", err)
+	}
+	if found == nil || found.ID != key.ID || found.RateLimit != 120 {
+		t.Fatalf("expected the created key, got %+v", found)
+	}
+	if !found.HasScope(entities.APIKeyScopeDocumentsSign) || found.HasScope(entities.APIKeyScopeDocumentsDelete) {
+		t.Fatalf("expected scopes to round-trip, got %v", found.Scopes)
+	}
+
+	missing, err := repo.GetByPrefix(ctx, "dss_000000000000")
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil for an unknown prefix, got %+v, %v", missing, err)
+	}
+}
+
+func TestAPIKeyRepository_ListByUserAndRevoke(t *testing.T) {
+	repo := NewAPIKeyRepository(setupAPIKeyTestDB(t))
+	ctx := context.Background()
+
+	for _, key := range []*entities.APIKey{
+		{UserID: "service-1", Name: "first", Prefix: "dss_111111111111", KeyHash: "h1"},
+		{UserID: "service-1", Name: "second", Prefix: "dss_222222222222", KeyHash: "h2"},
+		{UserID: "service-2", Name: "other", Prefix: "dss_333333333333", KeyHash: "h3"},
+	} {
+		if err := repo.Create(ctx, key); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	keys, err := repo.ListByUser(ctx, "service-1")
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+
+	now := time.Now()
+	keys[0].RevokedAt = &now
+	if err := repo.Update(ctx, keys[0]); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	revoked, _ := repo.GetByID(ctx, keys[0].ID)
+	if revoked == nil || revoked.IsUsable(now) {
+		t.Fatalf("expected the key to be revoked, got %+v", revoked)
+	}
+}
+
+func TestAPIKeyRepository_TouchLastUsed(t *testing.T) {
+	repo := NewAPIKeyRepository(setupAPIKeyTestDB(t))
+	ctx := context.Background()
+
+	key := &entities.APIKey{UserID: "service-1", Name: "SIS batch", Prefix: "dss_a1b2c3d4e5f6", KeyHash: "hash"}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err := repo.TouchLastUsed(ctx, key.ID, usedAt, "10.0.0.5"); err != nil {
+		t.Fatalf("TouchLastUsed() error = %v", err)
+	}
+
+	found, _ := repo.GetByID(ctx, key.ID)
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(usedAt) || found.LastUsedIP != "10.0.0.5" {
+		t.Fatalf("expected last use to be recorded, got %+v", found)
+	}
+	if found.Name != "SIS batch" || found.KeyHash != "hash" {
+		t.Fatalf("expected the rest of the row to be untouched, got %+v", found)
+	}
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type batchJobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewBatchJobRepository(db *gorm.DB) repositories.BatchJobRepository {
+	return &batchJobRepositoryImpl{db: db}
+}
+
+func (r *batchJobRepositoryImpl) Create(ctx context.Context, job *entities.BatchJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create batch job: %w", err)
+	}
+	return nil
+}
+
+func (r *batchJobRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.BatchJob, error) {
+	var job entities.BatchJob
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get batch job by ID: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *batchJobRepositoryImpl) Update(ctx context.Context, job *entities.BatchJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update batch job: %w", err)
+	}
+	return nil
+}
@@ -58,31 +58,60 @@ func gormMigrate(db *gorm.DB) error {
 	if err := tryStandardMigration(db); err != nil {
 		fmt.Printf("Standard migration failed: %v\n", err)
 		fmt.Println("Trying alternative migration approach...")
-		return tryAlternativeMigration(db)
+		if err := tryAlternativeMigration(db); err != nil {
+			return err
+		}
+	}
+
+	// The full-text search column is generated by PostgreSQL, so GORM cannot declare it
+	if err := migrateDocumentSearch(db); err != nil {
+		return fmt.Errorf("failed to create document search index: %w", err)
 	}
 	
 	fmt.Println("GORM migration completed successfully!")
 	return nil
 }
 
-func tryStandardMigration(db *gorm.DB) error {
-	// Try standard AutoMigrate with all entities at once
-	return db.AutoMigrate(
+// migrationModels lists every entity managed by GORM migrations
+func migrationModels() []interface{} {
+	return []interface{}{
 		&entities.User{},
 		&entities.Session{},
 		&entities.Document{},
 		&entities.VerificationLog{},
-	)
+		&entities.BatchJob{},
+		&entities.Job{},
+		&entities.WebhookSubscription{},
+		&entities.WebhookDelivery{},
+		&entities.UploadSession{},
+		&entities.LoginThrottle{},
+		&entities.UserMFA{},
+		&entities.MFARecoveryCode{},
+		&entities.WebAuthnCredential{},
+		&entities.WebAuthnChallenge{},
+		&entities.MaintenanceRun{},
+		&entities.UserIdentity{},
+		&entities.OIDCAuthRequest{},
+		&entities.APIKey{},
+		&entities.Role{},
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.OrganizationKey{},
+		&entities.Invitation{},
+		&entities.Delegation{},
+		&entities.LetterNumberScheme{},
+		&entities.LetterNumberCounter{},
+	}
+}
+
+func tryStandardMigration(db *gorm.DB) error {
+	// Try standard AutoMigrate with all entities at once
+	return db.AutoMigrate(migrationModels()...)
 }
 
 func tryAlternativeMigration(db *gorm.DB) error {
 	// Alternative approach: migrate each entity individually with error handling
-	entities := []interface{}{
-		&entities.User{},
-		&entities.Session{},
-		&entities.Document{},
-		&entities.VerificationLog{},
-	}
+	entities := migrationModels()
 	
 	for _, entity := range entities {
 		fmt.Printf("Migrating %T individually...\n", entity)
@@ -102,12 +131,7 @@ func tryMigratorApproach(db *gorm.DB) error {
 	migrator := db.Migrator()
 	
 	// Check if tables exist first
-	entities := []interface{}{
-		&entities.User{},
-		&entities.Session{},
-		&entities.Document{},
-		&entities.VerificationLog{},
-	}
+	entities := migrationModels()
 	
 	for _, entity := range entities {
 		if !migrator.HasTable(entity) {
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type delegationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) repositories.DelegationRepository {
+	return &delegationRepositoryImpl{db: db}
+}
+
+func (r *delegationRepositoryImpl) Create(ctx context.Context, delegation *entities.Delegation) error {
+	if err := r.db.WithContext(ctx).Create(delegation).Error; err != nil {
+		return fmt.Errorf("failed to create delegation: %w", err)
+	}
+	return nil
+}
+
+func (r *delegationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Delegation, error) {
+	var delegation entities.Delegation
+	err := r.db.WithContext(ctx).
+		Preload("Delegator").
+		Preload("Delegate").
+		Where("id = ?", id).
+		First(&delegation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get delegation: %w", err)
+	}
+	return &delegation, nil
+}
+
+func (r *delegationRepositoryImpl) ListByUser(ctx context.Context, userID string) ([]*entities.Delegation, error) {
+	var delegations []*entities.Delegation
+	err := r.db.WithContext(ctx).
+		Preload("Delegator").
+		Preload("Delegate").
+		Where("delegator_id = ? OR delegate_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&delegations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+func (r *delegationRepositoryImpl) FindActive(ctx context.Context, delegatorID, delegateID, organizationID string, at time.Time) (*entities.Delegation, error) {
+	query := r.db.WithContext(ctx).
+		Where("delegator_id = ? AND delegate_id = ?", delegatorID, delegateID).
+		Where("revoked_at IS NULL AND valid_from <= ? AND valid_until > ?", at, at)
+	if organizationID == "" {
+		query = query.Where("organization_id IS NULL")
+	} else {
+		query = query.Where("organization_id = ?", organizationID)
+	}
+
+	var delegation entities.Delegation
+	if err := query.Order("created_at DESC").First(&delegation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find delegation: %w", err)
+	}
+	return &delegation, nil
+}
+
+func (r *delegationRepositoryImpl) Revoke(ctx context.Context, id, revokedBy string, revokedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Delegation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "revoked_by": revokedBy})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke delegation: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
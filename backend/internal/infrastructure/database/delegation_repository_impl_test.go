@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupDelegationTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	for _, statement := range []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT UNIQUE NOT NULL,
+			password_hash TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			role TEXT DEFAULT 'user',
+			created_at DATETIME,
+			updated_at DATETIME,
+			is_active BOOLEAN DEFAULT true,
+			service_account BOOLEAN DEFAULT false
+		)`,
+		`CREATE TABLE delegations (
+			id TEXT PRIMARY KEY,
+			delegator_id TEXT NOT NULL,
+			delegate_id TEXT NOT NULL,
+			organization_id TEXT,
+			valid_from DATETIME NOT NULL,
+			valid_until DATETIME NOT NULL,
+			reason TEXT,
+			revoked_at DATETIME,
+			revoked_by TEXT,
+			created_at DATETIME
+		)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+
+	return db
+}
+
+func TestDelegationRepository_FindActive(t *testing.T) {
+	db := setupDelegationTestDB(t)
+	repo := NewDelegationRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	orgID := "org-1"
+
+	for _, user := range []*entities.User{
+		{ID: "head", Username: "head", PasswordHash: "x", FullName: "Head of Department", Email: "head@example.com", IsActive: true},
+		{ID: "secretary", Username: "secretary", PasswordHash: "x", FullName: "Department Secretary", Email: "secretary@example.com", IsActive: true},
+	} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	personal := &entities.Delegation{DelegatorID: "head", DelegateID: "secretary", ValidFrom: now.Add(-time.Hour), ValidUntil: now.Add(time.Hour), CreatedAt: now}
+	scoped := &entities.Delegation{DelegatorID: "head", DelegateID: "secretary", OrganizationID: &orgID, ValidFrom: now.Add(time.Hour), ValidUntil: now.Add(2 * time.Hour), CreatedAt: now}
+	for _, delegation := range []*entities.Delegation{personal, scoped} {
+		if err := repo.Create(ctx, delegation); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	found, err := repo.FindActive(ctx, "head", "secretary", "", now)
+	if err != nil || found == nil || found.ID != personal.ID {
+		t.Fatalf("expected the unscoped grant, got %+v, %v", found, err)
+	}
+	if found, err := repo.FindActive(ctx, "secretary", "head", "", now); err != nil || found != nil {
+		t.Fatalf("expected a grant not to work in reverse, got %+v, %v", found, err)
+	}
+	// The organization's grant has not started yet
+	if found, err := repo.FindActive(ctx, "head", "secretary", orgID, now); err != nil || found != nil {
+		t.Fatalf("expected no grant before its window, got %+v, %v", found, err)
+	}
+	if found, err := repo.FindActive(ctx, "head", "secretary", orgID, now.Add(90*time.Minute)); err != nil || found == nil || found.ID != scoped.ID {
+		t.Fatalf("expected the organization's grant inside its window, got %+v, %v", found, err)
+	}
+
+	revoked, err := repo.Revoke(ctx, personal.ID, "head", now)
+	if err != nil || !revoked {
+		t.Fatalf("expected the first revoke to succeed, got %v, %v", revoked, err)
+	}
+	if revoked, _ := repo.Revoke(ctx, personal.ID, "head", now); revoked {
+		t.Fatal("expected a revoked grant not to be revoked again")
+	}
+	if found, err := repo.FindActive(ctx, "head", "secretary", "", now); err != nil || found != nil {
+		t.Fatalf("expected a revoked grant not to be found, got %+v, %v", found, err)
+	}
+
+	got, err := repo.GetByID(ctx, personal.ID)
+	if err != nil || got == nil || got.RevokedAt == nil || got.Delegator == nil || got.Delegate == nil {
+		t.Fatalf("expected the revoked grant with both users, got %+v, %v", got, err)
+	}
+	if got.Delegate.FullName != "Department Secretary" {
+		t.Fatalf("expected the delegate to be preloaded, got %+v", got.Delegate)
+	}
+
+	delegations, err := repo.ListByUser(ctx, "secretary")
+	if err != nil || len(delegations) != 2 {
+		t.Fatalf("expected the delegate to see both grants, got %d, %v", len(delegations), err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"digital-signature-system/internal/domain/entities"
 	"digital-signature-system/internal/domain/repositories"
@@ -19,15 +20,53 @@ func NewDocumentRepository(db *gorm.DB) repositories.DocumentRepository {
 }
 
 func (r *documentRepositoryImpl) Create(ctx context.Context, doc *entities.Document) error {
+	if err := checkTenant(ctx, doc.OrganizationID); err != nil {
+		return fmt.Errorf("failed to create document: %w", err)
+	}
 	if err := r.db.WithContext(ctx).Create(doc).Error; err != nil {
 		return fmt.Errorf("failed to create document: %w", err)
 	}
 	return nil
 }
 
+func (r *documentRepositoryImpl) CreateNumbered(ctx context.Context, doc *entities.Document, schemeID string, year int, number func(seq int64) error) error {
+	if err := checkTenant(ctx, doc.OrganizationID); err != nil {
+		return fmt.Errorf("failed to create document: %w", err)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The counter row is locked until the document is stored, so concurrent
+		// signings take consecutive numbers
+		counter := entities.LetterNumberCounter{SchemeID: schemeID, Year: year}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scheme_id = ? AND year = ?", schemeID, year).
+			First(&counter).Error; err != nil {
+			return err
+		}
+
+		seq := counter.Value + 1
+		if err := number(seq); err != nil {
+			return err
+		}
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entities.LetterNumberCounter{}).
+			Where("scheme_id = ? AND year = ?", schemeID, year).
+			Updates(map[string]interface{}{"value": seq, "updated_at": doc.CreatedAt}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create numbered document: %w", err)
+	}
+	return nil
+}
+
 func (r *documentRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Document, error) {
 	var doc entities.Document
-	if err := r.db.WithContext(ctx).Preload("User").Where("id = ?", id).First(&doc).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).Preload("User").Where("id = ?", id).First(&doc).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -40,7 +79,8 @@ func (r *documentRepositoryImpl) GetByUserID(ctx context.Context, userID string,
 	var docs []*entities.Document
 	var total int64
 
-	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).
+		Where("user_id = ? OR signed_by_id = ?", userID, userID)
 
 	if filter.Status != "" {
 		query = query.Where("status = ?", filter.Status)
@@ -65,9 +105,14 @@ func (r *documentRepositoryImpl) GetByUserID(ctx context.Context, userID string,
 	return docs, total, nil
 }
 
-func (r *documentRepositoryImpl) GetByHash(ctx context.Context, hash string) (*entities.Document, error) {
+func (r *documentRepositoryImpl) GetByHash(ctx context.Context, hash, userID string) (*entities.Document, error) {
+	query := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).Preload("User").Where("document_hash = ?", hash)
+	if userID != "" {
+		query = query.Where("user_id = ? OR signed_by_id = ?", userID, userID)
+	}
+
 	var doc entities.Document
-	if err := r.db.WithContext(ctx).Preload("User").Where("document_hash = ?", hash).First(&doc).Error; err != nil {
+	if err := query.Order("created_at DESC").First(&doc).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -76,7 +121,38 @@ func (r *documentRepositoryImpl) GetByHash(ctx context.Context, hash string) (*e
 	return &doc, nil
 }
 
+func (r *documentRepositoryImpl) GetVerifiableByHash(ctx context.Context, hash string, limit int) ([]*entities.Document, error) {
+	var docs []*entities.Document
+	err := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).
+		Where("(document_hash = ? OR stamped_hash = ?) AND status = ? AND private = ?", hash, hash, "active", false).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&docs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents by hash: %w", err)
+	}
+	return docs, nil
+}
+
+func (r *documentRepositoryImpl) GetRecentWithText(ctx context.Context, userID string, limit int) ([]*entities.Document, error) {
+	query := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).
+		Select("id", "user_id", "signed_by_id", "filename", "issuer", "title", "letter_number", "document_hash", "created_at", "version", "content_text").
+		Where("status = ? AND content_text IS NOT NULL AND content_text <> ''", "active")
+	if userID != "" {
+		query = query.Where("user_id = ? OR signed_by_id = ?", userID, userID)
+	}
+
+	var docs []*entities.Document
+	if err := query.Order("created_at DESC").Limit(limit).Find(&docs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get documents with text: %w", err)
+	}
+	return docs, nil
+}
+
 func (r *documentRepositoryImpl) Update(ctx context.Context, doc *entities.Document) error {
+	if err := checkTenant(ctx, doc.OrganizationID); err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
 	if err := r.db.WithContext(ctx).Save(doc).Error; err != nil {
 		return fmt.Errorf("failed to update document: %w", err)
 	}
@@ -84,7 +160,7 @@ func (r *documentRepositoryImpl) Update(ctx context.Context, doc *entities.Docum
 }
 
 func (r *documentRepositoryImpl) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Delete(&entities.Document{}, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(tenantScope(ctx, "organization_id")).Delete(&entities.Document{}, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 	return nil
@@ -2,11 +2,16 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
@@ -38,7 +43,8 @@ func setupDocumentTestDB(t *testing.T) *gorm.DB {
 			role TEXT DEFAULT 'user',
 			created_at DATETIME,
 			updated_at DATETIME,
-			is_active BOOLEAN DEFAULT true
+			is_active BOOLEAN DEFAULT true,
+			service_account BOOLEAN DEFAULT false
 		)
 	`).Error
 	if err != nil {
@@ -60,6 +66,19 @@ func setupDocumentTestDB(t *testing.T) *gorm.DB {
 			updated_at DATETIME,
 			file_size INTEGER,
 			status TEXT DEFAULT 'active',
+			organization_id TEXT,
+			key_id TEXT,
+			signed_by_id TEXT,
+			delegation_id TEXT,
+			content_text TEXT,
+			metadata TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			previous_version_id TEXT,
+			letter_number_scheme_id TEXT,
+			stamped_hash TEXT,
+			private BOOLEAN NOT NULL DEFAULT false,
+			visibility TEXT NOT NULL DEFAULT 'public',
+			access_code_hash TEXT,
 			FOREIGN KEY (user_id) REFERENCES users(id)
 		)
 	`).Error
@@ -71,6 +90,20 @@ func setupDocumentTestDB(t *testing.T) *gorm.DB {
 	db.Exec("CREATE INDEX idx_documents_user_id ON documents(user_id)")
 	db.Exec("CREATE INDEX idx_documents_hash ON documents(document_hash)")
 	db.Exec("CREATE INDEX idx_documents_created_at ON documents(created_at)")
+	db.Exec("CREATE UNIQUE INDEX idx_documents_scheme_letter_number ON documents(letter_number_scheme_id, letter_number)")
+
+	err = db.Exec(`
+		CREATE TABLE letter_number_counters (
+			scheme_id TEXT NOT NULL,
+			year INTEGER NOT NULL,
+			value INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME,
+			PRIMARY KEY (scheme_id, year)
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create letter_number_counters table: %v", err)
+	}
 
 	return db
 }
@@ -158,7 +191,7 @@ func TestDocumentRepository_GetByHash(t *testing.T) {
 	db.Create(doc)
 
 	// Test GetByHash
-	result, err := repo.GetByHash(ctx, "testhash123")
+	result, err := repo.GetByHash(ctx, "testhash123", "")
 	if err != nil {
 		t.Errorf("GetByHash() error = %v", err)
 	}
@@ -169,8 +202,31 @@ func TestDocumentRepository_GetByHash(t *testing.T) {
 		t.Errorf("Expected hash 'testhash123', got %s", result.DocumentHash)
 	}
 
+	// A newer document of another user with the same hash does not hide the
+	// user's own
+	newer := *doc
+	newer.ID = uuid.New().String()
+	newer.UserID = uuid.New().String()
+	newer.CreatedAt = time.Now().Add(time.Minute)
+	db.Create(&newer)
+
+	result, err = repo.GetByHash(ctx, "testhash123", user.ID)
+	if err != nil {
+		t.Errorf("GetByHash() error = %v", err)
+	}
+	if result == nil || result.ID != doc.ID {
+		t.Errorf("Expected the user's document %s, got %+v", doc.ID, result)
+	}
+	result, err = repo.GetByHash(ctx, "testhash123", "")
+	if err != nil {
+		t.Errorf("GetByHash() error = %v", err)
+	}
+	if result == nil || result.ID != newer.ID {
+		t.Errorf("Expected the newest document %s, got %+v", newer.ID, result)
+	}
+
 	// Test non-existent hash
-	result, err = repo.GetByHash(ctx, "nonexistent")
+	result, err = repo.GetByHash(ctx, "nonexistent", "")
 	if err != nil {
 		t.Errorf("GetByHash() error = %v", err)
 	}
@@ -237,6 +293,243 @@ func TestDocumentRepository_GetByUserID(t *testing.T) {
 	}
 }
 
+func TestDocumentRepository_GetByUserID_SignedOnBehalf(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	orgA := uuid.New().String()
+	ctxA := repositories.WithOrganization(context.Background(), orgA)
+	delegateID := "delegate-id"
+	delegationID := uuid.New().String()
+
+	// The delegate signed one of A's documents for testUserID and owns a personal one
+	signed := &entities.Document{UserID: testUserID, SignedByID: &delegateID, DelegationID: &delegationID, Filename: "signed.pdf", Issuer: "Faculty A", DocumentHash: "hash-signed", SignatureData: "sig", QRCodeData: "qr", OrganizationID: &orgA}
+	if err := repo.Create(ctxA, signed); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	personal := &entities.Document{UserID: delegateID, Filename: "personal.pdf", Issuer: "Issuer", DocumentHash: "hash-personal", SignatureData: "sig", QRCodeData: "qr"}
+	if err := repo.Create(context.Background(), personal); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, userID := range []string{testUserID, delegateID} {
+		docs, total, err := repo.GetByUserID(ctxA, userID, repositories.DocumentFilter{Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("GetByUserID() error = %v", err)
+		}
+		if total != 1 || len(docs) != 1 || docs[0].ID != signed.ID {
+			t.Fatalf("expected %s to see only the delegated document in organization A, got %+v", userID, docs)
+		}
+	}
+}
+
+func TestDocumentRepository_Metadata(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+
+	doc := &entities.Document{
+		UserID:        testUserID,
+		Filename:      "report.pdf",
+		Issuer:        "Finance Office",
+		DocumentHash:  "hash-report",
+		SignatureData: "sig",
+		QRCodeData:    "qr",
+		ContentText:   "Quarterly report\fAppendix",
+		Metadata: &entities.DocumentMetadata{
+			PDFVersion:    "1.7",
+			PageCount:     2,
+			Author:        "Finance",
+			Pages:         []entities.PageMetadata{{Number: 1, Width: 595, Height: 842}, {Number: 2, Width: 842, Height: 595, Rotation: 90}},
+			EmbeddedFiles: []entities.EmbeddedFileMetadata{{Name: "figures.csv", Size: 120}},
+		},
+	}
+	if err := repo.Create(ctx, doc); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.GetByID(ctx, doc.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if found.Metadata == nil || found.Metadata.Author != "Finance" || len(found.Metadata.Pages) != 2 || found.Metadata.Pages[1].Rotation != 90 ||
+		len(found.Metadata.EmbeddedFiles) != 1 {
+		t.Fatalf("metadata did not round-trip: %+v", found.Metadata)
+	}
+	if pages := found.PageTexts(); len(pages) != 2 || pages[1] != "Appendix" {
+		t.Fatalf("expected two pages of text, got %q", pages)
+	}
+}
+
+func TestDocumentRepository_GetRecentWithText(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+
+	created := time.Now().Add(-time.Hour)
+	for i, doc := range []*entities.Document{
+		{ID: "00000000-0000-0000-0000-000000000001", UserID: testUserID, DocumentHash: "hash-letter", ContentText: "First letter", Version: 1},
+		{ID: "00000000-0000-0000-0000-000000000002", UserID: testUserID, DocumentHash: "hash-letter", ContentText: "First letter", Version: 2},
+		{ID: "00000000-0000-0000-0000-000000000003", UserID: testUserID, DocumentHash: "hash-blank", Version: 1},
+		{ID: "00000000-0000-0000-0000-000000000004", UserID: "other-user", DocumentHash: "hash-other", ContentText: "Other letter", Version: 1},
+	} {
+		doc.Filename, doc.Issuer, doc.SignatureData, doc.QRCodeData = "letter.pdf", "Registrar", "sig", "qr"
+		doc.CreatedAt = created.Add(time.Duration(i) * time.Minute)
+		if err := repo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// The latest signing of a hash is returned
+	found, err := repo.GetByHash(ctx, "hash-letter", "")
+	if err != nil {
+		t.Fatalf("GetByHash() error = %v", err)
+	}
+	if found == nil || found.Version != 2 {
+		t.Fatalf("expected version 2, got %+v", found)
+	}
+
+	// Documents without text are skipped
+	docs, err := repo.GetRecentWithText(ctx, testUserID, 10)
+	if err != nil {
+		t.Fatalf("GetRecentWithText() error = %v", err)
+	}
+	if len(docs) != 2 || docs[0].Version != 2 || docs[0].ContentText != "First letter" {
+		t.Fatalf("expected the user's two documents with text, newest first, got %+v", docs)
+	}
+
+	docs, err = repo.GetRecentWithText(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("GetRecentWithText() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].UserID != "other-user" {
+		t.Fatalf("expected the newest document of any user, got %+v", docs)
+	}
+}
+
+func TestDocumentRepository_GetVerifiableByHash(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+
+	created := time.Now().Add(-time.Hour)
+	for i, doc := range []*entities.Document{
+		{ID: "00000000-0000-0000-0000-000000000001", DocumentHash: "hash-letter", StampedHash: "hash-stamped-1", Status: "active"},
+		{ID: "00000000-0000-0000-0000-000000000002", DocumentHash: "hash-letter", StampedHash: "hash-stamped-2", Status: "active"},
+		{ID: "00000000-0000-0000-0000-000000000003", DocumentHash: "hash-letter", Status: "active", Private: true},
+		{ID: "00000000-0000-0000-0000-000000000004", DocumentHash: "hash-letter", Status: "deleted"},
+		{ID: "00000000-0000-0000-0000-000000000005", DocumentHash: "hash-other", Status: "active"},
+	} {
+		doc.UserID, doc.Filename, doc.Issuer, doc.SignatureData, doc.QRCodeData = testUserID, "letter.pdf", "Registrar", "sig", "qr"
+		doc.Version = 1
+		doc.CreatedAt = created.Add(time.Duration(i) * time.Minute)
+		if err := repo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Private and inactive documents are never matched
+	docs, err := repo.GetVerifiableByHash(ctx, "hash-letter", 10)
+	if err != nil {
+		t.Fatalf("GetVerifiableByHash() error = %v", err)
+	}
+	if len(docs) != 2 || docs[0].ID != "00000000-0000-0000-0000-000000000002" || docs[1].ID != "00000000-0000-0000-0000-000000000001" {
+		t.Fatalf("expected the two public active documents, newest first, got %+v", docs)
+	}
+
+	// The stamped PDF finds its document
+	docs, err = repo.GetVerifiableByHash(ctx, "hash-stamped-1", 10)
+	if err != nil {
+		t.Fatalf("GetVerifiableByHash() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].ID != "00000000-0000-0000-0000-000000000001" {
+		t.Fatalf("expected the stamped document, got %+v", docs)
+	}
+
+	docs, err = repo.GetVerifiableByHash(ctx, "hash-letter", 1)
+	if err != nil {
+		t.Fatalf("GetVerifiableByHash() error = %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("expected the limit to apply, got %d documents", len(docs))
+	}
+}
+
+func TestDocumentRepository_CreateNumbered(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	schemeID := "00000000-0000-0000-0000-00000000000a"
+
+	create := func(id string, year int, letterNumber func(seq int64) string, fail error) (*entities.Document, error) {
+		doc := &entities.Document{
+			ID: id, UserID: testUserID, Filename: "letter.pdf", Issuer: "Registrar",
+			DocumentHash: "hash-" + id, SignatureData: "sig", QRCodeData: "qr", Version: 1,
+			LetterNumberSchemeID: &schemeID, CreatedAt: time.Now(),
+		}
+		err := repo.CreateNumbered(ctx, doc, schemeID, year, func(seq int64) error {
+			number := letterNumber(seq)
+			doc.LetterNumber = &number
+			return fail
+		})
+		return doc, err
+	}
+	format := func(year int) func(int64) string {
+		return func(seq int64) string { return fmt.Sprintf("%03d/%d", seq, year) }
+	}
+	counter := func(year int) int64 {
+		var value int64
+		db.Raw("SELECT value FROM letter_number_counters WHERE scheme_id = ? AND year = ?", schemeID, year).Scan(&value)
+		return value
+	}
+
+	for i, want := range []string{"001/2026", "002/2026"} {
+		doc, err := create(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i+1), 2026, format(2026), nil)
+		if err != nil {
+			t.Fatalf("CreateNumbered() error = %v", err)
+		}
+		if *doc.LetterNumber != want {
+			t.Errorf("expected %s, got %s", want, *doc.LetterNumber)
+		}
+	}
+
+	// A failed signing leaves no gap in the sequence
+	if _, err := create("00000000-0000-0000-0000-000000000003", 2026, format(2026), errors.New("signing failed")); err == nil {
+		t.Fatal("expected the callback error")
+	}
+	if got := counter(2026); got != 2 {
+		t.Errorf("expected the counter to stay at 2, got %d", got)
+	}
+	var count int64
+	db.Model(&entities.Document{}).Where("id = ?", "00000000-0000-0000-0000-000000000003").Count(&count)
+	if count != 0 {
+		t.Error("expected the failed document not to be stored")
+	}
+
+	// A number already taken within the scheme is rejected and not counted
+	if _, err := create("00000000-0000-0000-0000-000000000004", 2026, func(int64) string { return "001/2026" }, nil); err == nil {
+		t.Fatal("expected a duplicate letter number to be rejected")
+	}
+	if got := counter(2026); got != 2 {
+		t.Errorf("expected the counter to stay at 2, got %d", got)
+	}
+
+	// Each year has its own sequence
+	doc, err := create("00000000-0000-0000-0000-000000000005", 2027, format(2027), nil)
+	if err != nil {
+		t.Fatalf("CreateNumbered() error = %v", err)
+	}
+	if *doc.LetterNumber != "001/2027" {
+		t.Errorf("expected the sequence to restart, got %s", *doc.LetterNumber)
+	}
+	doc, err = create("00000000-0000-0000-0000-000000000006", 2026, format(2026), nil)
+	if err != nil {
+		t.Fatalf("CreateNumbered() error = %v", err)
+	}
+	if *doc.LetterNumber != "003/2026" {
+		t.Errorf("expected 003/2026, got %s", *doc.LetterNumber)
+	}
+}
+
 func TestDocumentRepository_GetByID(t *testing.T) {
 	db := setupDocumentTestDB(t)
 	repo := NewDocumentRepository(db)
@@ -415,3 +708,245 @@ func TestDocumentRepository_Delete(t *testing.T) {
 		t.Errorf("Delete() error for non-existent document = %v", err)
 	}
 }
+
+func TestDocumentRepository_TenantScope(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	orgA := uuid.New().String()
+	orgB := uuid.New().String()
+
+	unscoped := &entities.Document{UserID: testUserID, Filename: "personal.pdf", Issuer: "Issuer", DocumentHash: "hash-personal", SignatureData: "sig", QRCodeData: "qr"}
+	if err := repo.Create(context.Background(), unscoped); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ctxA := repositories.WithOrganization(context.Background(), orgA)
+	docA := &entities.Document{UserID: testUserID, Filename: "a.pdf", Issuer: "Faculty A", DocumentHash: "hash-a", SignatureData: "sig", QRCodeData: "qr", OrganizationID: &orgA}
+	if err := repo.Create(ctxA, docA); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// A record cannot be written into another tenant
+	docB := &entities.Document{UserID: testUserID, Filename: "b.pdf", Issuer: "Faculty B", DocumentHash: "hash-b", SignatureData: "sig", QRCodeData: "qr", OrganizationID: &orgB}
+	if err := repo.Create(ctxA, docB); err == nil {
+		t.Fatal("expected creating another organization's document to fail")
+	}
+
+	ctxB := repositories.WithOrganization(context.Background(), orgB)
+	if found, err := repo.GetByID(ctxB, docA.ID); err != nil || found != nil {
+		t.Fatalf("expected organization B not to see A's document, got %+v, %v", found, err)
+	}
+	if found, err := repo.GetByHash(context.Background(), "hash-a", ""); err != nil || found != nil {
+		t.Fatalf("expected an unscoped context not to see A's document, got %+v, %v", found, err)
+	}
+	if found, err := repo.GetByID(ctxA, docA.ID); err != nil || found == nil {
+		t.Fatalf("expected organization A to see its document, got %+v, %v", found, err)
+	}
+
+	docs, total, err := repo.GetByUserID(ctxA, testUserID, repositories.DocumentFilter{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("GetByUserID() error = %v", err)
+	}
+	if total != 1 || len(docs) != 1 || docs[0].ID != docA.ID {
+		t.Fatalf("expected only A's document, got %+v", docs)
+	}
+
+	all := repositories.WithAllOrganizations(context.Background())
+	if found, err := repo.GetByID(all, docA.ID); err != nil || found == nil {
+		t.Fatalf("expected lifted scoping to see A's document, got %+v, %v", found, err)
+	}
+
+	if err := repo.Delete(ctxB, docA.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if found, _ := repo.GetByID(ctxA, docA.ID); found == nil {
+		t.Fatal("expected a delete from another organization to leave the document in place")
+	}
+}
+
+func TestDocumentRepository_Search(t *testing.T) {
+	db := setupDocumentTestDB(t)
+	repo := NewDocumentRepository(db)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	// Five documents a day apart across January and February, and one of another user's
+	for i, issuer := range []string{"Faculty of Law", "Faculty of Law", "Rectorate", "Faculty of Law", "Rectorate"} {
+		doc := &entities.Document{
+			UserID:        testUserID,
+			Filename:      fmt.Sprintf("memo-%d.pdf", i),
+			Issuer:        issuer,
+			Title:         stringPtr(fmt.Sprintf("Memo %d", i)),
+			LetterNumber:  stringPtr(fmt.Sprintf("%03d/UN/2026", i)),
+			DocumentHash:  fmt.Sprintf("hash-%d", i),
+			SignatureData: "sig",
+			QRCodeData:    "qr",
+			CreatedAt:     base.AddDate(0, 0, i*7),
+			Status:        "active",
+		}
+		if i == 2 {
+			doc.ContentText = "Decree on the academic calendar"
+		}
+		if err := repo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	other := &entities.Document{UserID: "other-user", Filename: "calendar.pdf", Issuer: "Rectorate", DocumentHash: "hash-other", SignatureData: "sig", QRCodeData: "qr", CreatedAt: base, Status: "active"}
+	if err := repo.Create(ctx, other); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	result, err := repo.Search(ctx, repositories.DocumentSearch{UserID: testUserID, Query: "calendar", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 1 || len(result.Documents) != 1 || result.Documents[0].Filename != "memo-2.pdf" {
+		t.Fatalf("expected the extracted text to match only memo-2.pdf, got %+v", result.Documents)
+	}
+
+	// Each facet ignores its own filter
+	result, err = repo.Search(ctx, repositories.DocumentSearch{UserID: testUserID, Issuer: "Rectorate", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("expected 2 Rectorate documents, got %d", result.Total)
+	}
+	if len(result.Facets.Issuers) != 2 || result.Facets.Issuers[0] != (repositories.FacetCount{Value: "Faculty of Law", Count: 3}) {
+		t.Fatalf("unexpected issuer facets %+v", result.Facets.Issuers)
+	}
+	if len(result.Facets.Months) != 2 || result.Facets.Months[0] != (repositories.FacetCount{Value: "2026-02", Count: 1}) {
+		t.Fatalf("unexpected month facets %+v", result.Facets.Months)
+	}
+
+	from := base.AddDate(0, 0, 7)
+	to := base.AddDate(0, 0, 21)
+	result, err = repo.Search(ctx, repositories.DocumentSearch{UserID: testUserID, CreatedFrom: &from, CreatedTo: &to, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("expected 2 documents in the date range, got %d", result.Total)
+	}
+
+	// Keyset pages cover every document once, in order
+	for _, sort := range []string{repositories.DocumentSortCreatedAt, repositories.DocumentSortTitle} {
+		var seen []string
+		search := repositories.DocumentSearch{UserID: testUserID, Sort: sort, Ascending: sort == repositories.DocumentSortTitle, Limit: 2}
+		for page := 0; page < 5; page++ {
+			result, err := repo.Search(ctx, search)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			for _, doc := range result.Documents {
+				seen = append(seen, doc.Filename)
+			}
+			if result.Next == nil {
+				break
+			}
+			search.After = result.Next
+		}
+		want := []string{"memo-4.pdf", "memo-3.pdf", "memo-2.pdf", "memo-1.pdf", "memo-0.pdf"}
+		if sort == repositories.DocumentSortTitle {
+			want = []string{"memo-0.pdf", "memo-1.pdf", "memo-2.pdf", "memo-3.pdf", "memo-4.pdf"}
+		}
+		if fmt.Sprint(seen) != fmt.Sprint(want) {
+			t.Fatalf("sort %s: expected %v, got %v", sort, want, seen)
+		}
+	}
+}
+
+// registerRankingDriver registers a SQLite driver with stand-ins for PostgreSQL's
+// ranking functions: a document ranks by how often the query occurs in its
+// search_vector, as a real like ts_rank's
+var registerRankingDriver sync.Once
+
+func setupRankingTestDB(t *testing.T) *gorm.DB {
+	registerRankingDriver.Do(func() {
+		sql.Register("sqlite3_ranking", &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				if err := conn.RegisterFunc("websearch_to_tsquery", func(config, query string) string {
+					return query
+				}, true); err != nil {
+					return err
+				}
+				return conn.RegisterFunc("ts_rank", func(vector, query string) float32 {
+					return float32(strings.Count(vector, query)) * 0.0607927
+				}, true)
+			},
+		})
+	})
+
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite3_ranking", DSN: ":memory:"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.Exec(`
+		CREATE TABLE documents (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			filename TEXT NOT NULL,
+			issuer TEXT NOT NULL,
+			title TEXT,
+			document_hash TEXT NOT NULL,
+			created_at DATETIME,
+			search_vector TEXT
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create documents table: %v", err)
+	}
+	return db
+}
+
+func TestDocumentRepository_PageByTiedRank(t *testing.T) {
+	db := setupRankingTestDB(t)
+	repo := &documentRepositoryImpl{db: db}
+	ctx := context.Background()
+
+	// Three documents rank twice, four once, and one not at all
+	for i, vector := range []string{"memo memo", "memo", "memo memo", "memo", "decree", "memo", "memo memo", "memo"} {
+		err := db.Exec("INSERT INTO documents (id, user_id, filename, issuer, document_hash, search_vector) VALUES (?, ?, ?, ?, ?, ?)",
+			fmt.Sprintf("doc-%d", i), testUserID, fmt.Sprintf("memo-%d.pdf", i), "Rectorate", fmt.Sprintf("hash-%d", i), vector).Error
+		if err != nil {
+			t.Fatalf("failed to insert document: %v", err)
+		}
+	}
+
+	var seen []string
+	search := repositories.DocumentSearch{Query: "memo", Sort: repositories.DocumentSortRelevance, Limit: 2}
+	for page := 0; page < 5; page++ {
+		query, err := pageAfter(db.Model(&entities.Document{}).Where("search_vector LIKE ?", "%memo%"), search)
+		if err != nil {
+			t.Fatalf("pageAfter() error = %v", err)
+		}
+		var docs []*entities.Document
+		if err := query.Find(&docs).Error; err != nil {
+			t.Fatalf("failed to page documents: %v", err)
+		}
+		if len(docs) <= search.Limit {
+			for _, doc := range docs {
+				seen = append(seen, doc.ID)
+			}
+			break
+		}
+		docs = docs[:search.Limit]
+		for _, doc := range docs {
+			seen = append(seen, doc.ID)
+		}
+		if search.After, err = repo.cursorFor(ctx, search, docs[len(docs)-1]); err != nil {
+			t.Fatalf("cursorFor() error = %v", err)
+		}
+	}
+
+	// Ties are broken by ID and no page repeats or skips a document
+	want := []string{"doc-6", "doc-2", "doc-0", "doc-7", "doc-5", "doc-3", "doc-1"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+}
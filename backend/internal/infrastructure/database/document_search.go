@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+// maxIssuerFacets caps the issuers counted in a search's facets
+const maxIssuerFacets = 20
+
+// searchVectorSQL weights matches in the title and letter number above the issuer
+// and filename, and those above the extracted text. The 'simple' configuration
+// does no stemming, so it works for documents in any language.
+const searchVectorSQL = `setweight(to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(letter_number, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(issuer, '') || ' ' || coalesce(filename, '')), 'B') ||
+	setweight(to_tsvector('simple', coalesce(content_text, '')), 'C')`
+
+// migrateDocumentSearch adds the generated full-text search column and its GIN
+// index on PostgreSQL; other databases search with LIKE
+func migrateDocumentSearch(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	statements := []string{
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (` + searchVectorSQL + `) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *documentRepositoryImpl) Search(ctx context.Context, search repositories.DocumentSearch) (*repositories.DocumentSearchResult, error) {
+	postgres := r.db.Dialector.Name() == "postgres"
+	if search.Sort == repositories.DocumentSortRelevance && (search.Query == "" || !postgres) {
+		search.Sort = repositories.DocumentSortCreatedAt
+	}
+
+	result := &repositories.DocumentSearchResult{}
+	if err := r.searchQuery(ctx, search, postgres).Count(&result.Total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	facets, err := r.searchFacets(ctx, search, postgres)
+	if err != nil {
+		return nil, err
+	}
+	result.Facets = *facets
+
+	query, err := pageAfter(r.searchQuery(ctx, search, postgres), search)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*entities.Document
+	err = query.Preload("User").Find(&docs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	if len(docs) > search.Limit {
+		docs = docs[:search.Limit]
+		last := docs[len(docs)-1]
+		next, err := r.cursorFor(ctx, search, last)
+		if err != nil {
+			return nil, err
+		}
+		result.Next = next
+	}
+	result.Documents = docs
+	return result, nil
+}
+
+// searchQuery applies the search's filters to the documents in ctx's organization
+func (r *documentRepositoryImpl) searchQuery(ctx context.Context, search repositories.DocumentSearch, postgres bool) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&entities.Document{}).Scopes(tenantScope(ctx, "organization_id"))
+	if search.UserID != "" {
+		query = query.Where("user_id = ? OR signed_by_id = ?", search.UserID, search.UserID)
+	}
+	if search.Query != "" {
+		if postgres {
+			// Letter numbers like 012/UN/III/2026 are also matched by prefix
+			query = query.Where("search_vector @@ websearch_to_tsquery('simple', ?) OR letter_number ILIKE ?",
+				search.Query, escapeLike(search.Query)+"%")
+		} else {
+			pattern := "%" + strings.ToLower(search.Query) + "%"
+			query = query.Where("LOWER(COALESCE(title, '')) LIKE ? OR LOWER(issuer) LIKE ? OR LOWER(filename) LIKE ? OR LOWER(COALESCE(letter_number, '')) LIKE ? OR LOWER(COALESCE(content_text, '')) LIKE ?",
+				pattern, pattern, pattern, pattern, pattern)
+		}
+	}
+	if search.Issuer != "" {
+		query = query.Where("issuer = ?", search.Issuer)
+	}
+	if search.Status != "" {
+		query = query.Where("status = ?", search.Status)
+	}
+	if search.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *search.CreatedFrom)
+	}
+	if search.CreatedTo != nil {
+		query = query.Where("created_at < ?", *search.CreatedTo)
+	}
+	return query
+}
+
+// searchFacets counts the matches by issuer, status and month, each without its own filter
+func (r *documentRepositoryImpl) searchFacets(ctx context.Context, search repositories.DocumentSearch, postgres bool) (*repositories.DocumentFacets, error) {
+	facets := &repositories.DocumentFacets{}
+
+	byIssuer := search
+	byIssuer.Issuer = ""
+	err := r.searchQuery(ctx, byIssuer, postgres).
+		Select("issuer AS value, COUNT(*) AS count").
+		Group("issuer").
+		Order("count DESC, value ASC").
+		Limit(maxIssuerFacets).
+		Scan(&facets.Issuers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents by issuer: %w", err)
+	}
+
+	byStatus := search
+	byStatus.Status = ""
+	err = r.searchQuery(ctx, byStatus, postgres).
+		Select("status AS value, COUNT(*) AS count").
+		Group("status").
+		Order("count DESC, value ASC").
+		Scan(&facets.Statuses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents by status: %w", err)
+	}
+
+	month := "strftime('%Y-%m', created_at)"
+	if postgres {
+		month = "to_char(created_at, 'YYYY-MM')"
+	}
+	byMonth := search
+	byMonth.CreatedFrom, byMonth.CreatedTo = nil, nil
+	err = r.searchQuery(ctx, byMonth, postgres).
+		Select(month + " AS value, COUNT(*) AS count").
+		Group(month).
+		Order("value DESC").
+		Scan(&facets.Months).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents by month: %w", err)
+	}
+
+	return facets, nil
+}
+
+// searchSortKey is the SQL expression the search is ordered and paged by
+func searchSortKey(search repositories.DocumentSearch) (string, []interface{}) {
+	switch search.Sort {
+	case repositories.DocumentSortTitle:
+		return "COALESCE(title, '')", nil
+	case repositories.DocumentSortRelevance:
+		// ts_rank is a real; read as a double precision the cursor's key compares
+		// equal to the rank it came from, so documents with tied ranks page correctly
+		return "CAST(ts_rank(search_vector, websearch_to_tsquery('simple', ?)) AS float8)", []interface{}{search.Query}
+	default:
+		return "created_at", nil
+	}
+}
+
+// pageAfter orders query by the search's sort key and limits it to the page
+// after the search's cursor, with one document more to tell if another follows
+func pageAfter(query *gorm.DB, search repositories.DocumentSearch) (*gorm.DB, error) {
+	key, keyArgs := searchSortKey(search)
+	direction, before := "DESC", "<"
+	if search.Ascending {
+		direction, before = "ASC", ">"
+	}
+
+	if search.After != nil {
+		cursorKey, err := parseCursorKey(search)
+		if err != nil {
+			return nil, err
+		}
+		args := append(append(append(append([]interface{}{}, keyArgs...), cursorKey), keyArgs...), cursorKey, search.After.ID)
+		query = query.Where(fmt.Sprintf("%s %s ? OR (%s = ? AND id %s ?)", key, before, key, before), args...)
+	}
+	return query.Order(orderByKey(key, keyArgs, direction)).Limit(search.Limit + 1), nil
+}
+
+// orderByKey orders by the sort key, then by ID so documents with equal keys
+// keep a stable order across pages
+func orderByKey(key string, keyArgs []interface{}, direction string) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                fmt.Sprintf("%s %s, id %s", key, direction, direction),
+		Vars:               keyArgs,
+		WithoutParentheses: true,
+	}}
+}
+
+// parseCursorKey converts the cursor's key back to the sort key's type
+func parseCursorKey(search repositories.DocumentSearch) (interface{}, error) {
+	switch search.Sort {
+	case repositories.DocumentSortTitle:
+		return search.After.Key, nil
+	case repositories.DocumentSortRelevance:
+		rank, err := strconv.ParseFloat(search.After.Key, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search cursor: %w", err)
+		}
+		return rank, nil
+	default:
+		createdAt, err := time.Parse(time.RFC3339Nano, search.After.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search cursor: %w", err)
+		}
+		return createdAt, nil
+	}
+}
+
+// cursorFor returns the cursor that continues the search after the document
+func (r *documentRepositoryImpl) cursorFor(ctx context.Context, search repositories.DocumentSearch, last *entities.Document) (*repositories.DocumentCursor, error) {
+	switch search.Sort {
+	case repositories.DocumentSortTitle:
+		title := ""
+		if last.Title != nil {
+			title = *last.Title
+		}
+		return &repositories.DocumentCursor{Key: title, ID: last.ID}, nil
+	case repositories.DocumentSortRelevance:
+		key, keyArgs := searchSortKey(search)
+		var rank float64
+		err := r.db.WithContext(ctx).Model(&entities.Document{}).
+			Select(key, keyArgs...).
+			Where("id = ?", last.ID).
+			Scan(&rank).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to rank document: %w", err)
+		}
+		return &repositories.DocumentCursor{Key: strconv.FormatFloat(rank, 'g', -1, 64), ID: last.ID}, nil
+	default:
+		return &repositories.DocumentCursor{Key: last.CreatedAt.Format(time.RFC3339Nano), ID: last.ID}, nil
+	}
+}
+
+// escapeLike makes LIKE wildcards in s match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type invitationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) repositories.InvitationRepository {
+	return &invitationRepositoryImpl{db: db}
+}
+
+func (r *invitationRepositoryImpl) Create(ctx context.Context, invitation *entities.Invitation) error {
+	if err := r.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Invitation, error) {
+	var invitation entities.Invitation
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepositoryImpl) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Invitation, error) {
+	var invitation entities.Invitation
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepositoryImpl) ListPending(ctx context.Context) ([]*entities.Invitation, error) {
+	var invitations []*entities.Invitation
+	err := r.db.WithContext(ctx).
+		Where("accepted_at IS NULL").
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepositoryImpl) Claim(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Updates(map[string]interface{}{"accepted_at": acceptedAt, "accepted_user_id": userID})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim invitation: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *invitationRepositoryImpl) Release(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Model(&entities.Invitation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"accepted_at": nil, "accepted_user_id": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepositoryImpl) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.Invitation{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupInvitationTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE invitations (
+			id TEXT PRIMARY KEY,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'user',
+			token_hash TEXT NOT NULL UNIQUE,
+			invited_by TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			accepted_at DATETIME,
+			accepted_user_id TEXT,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create invitations table: %v", err)
+	}
+
+	return db
+}
+
+func TestInvitationRepository_Claim(t *testing.T) {
+	repo := NewInvitationRepository(setupInvitationTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	invitation := &entities.Invitation{Email: "new@example.com", Role: "registrar", TokenHash: "token-hash", InvitedBy: "admin-1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	if err := repo.Create(ctx, invitation); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.GetByTokenHash(ctx, "token-hash")
+	if err != nil || found == nil || found.ID != invitation.ID {
+		t.Fatalf("GetByTokenHash() = %+v, %v", found, err)
+	}
+
+	claimed, err := repo.Claim(ctx, invitation.ID, "user-1", now)
+	if err != nil || !claimed {
+		t.Fatalf("expected the first claim to succeed, got %v, %v", claimed, err)
+	}
+	claimed, err = repo.Claim(ctx, invitation.ID, "user-2", now)
+	if err != nil || claimed {
+		t.Fatalf("expected a second claim to fail, got %v, %v", claimed, err)
+	}
+
+	pending, err := repo.ListPending(ctx)
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending invitations, got %+v, %v", pending, err)
+	}
+
+	// A released invitation can be used again
+	if err := repo.Release(ctx, invitation.ID); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	pending, err = repo.ListPending(ctx)
+	if err != nil || len(pending) != 1 || pending[0].AcceptedUserID != nil {
+		t.Fatalf("expected the invitation to be pending again, got %+v, %v", pending, err)
+	}
+
+	if err := repo.Delete(ctx, invitation.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	missing, err := repo.GetByID(ctx, invitation.ID)
+	if err != nil || missing != nil {
+		t.Fatalf("expected the invitation to be gone, got %+v, %v", missing, err)
+	}
+}
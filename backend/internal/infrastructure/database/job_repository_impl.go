@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type jobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) repositories.JobRepository {
+	return &jobRepositoryImpl{db: db}
+}
+
+func (r *jobRepositoryImpl) Create(ctx context.Context, job *entities.Job) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Job, error) {
+	var job entities.Job
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get job by ID: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepositoryImpl) GetByUserID(ctx context.Context, userID string, limit int) ([]*entities.Job, error) {
+	var jobs []*entities.Job
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get jobs by user ID: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *jobRepositoryImpl) Update(ctx context.Context, job *entities.Job) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepositoryImpl) ClaimNext(ctx context.Context, workerID string, types []string) (*entities.Job, error) {
+	var claimed *entities.Job
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var job entities.Job
+		now := time.Now()
+
+		// SKIP LOCKED lets concurrent workers pick different rows without blocking each other
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", entities.JobStatusQueued, now)
+		if len(types) > 0 {
+			query = query.Where("type IN ?", types)
+		}
+
+		if err := query.Order("run_at ASC").First(&job).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		job.Status = entities.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		job.LockedBy = workerID
+		job.UpdatedAt = now
+		if err := tx.Save(&job).Error; err != nil {
+			return err
+		}
+
+		claimed = &job
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return claimed, nil
+}
+
+func (r *jobRepositoryImpl) Heartbeat(ctx context.Context, id, workerID string) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&entities.Job{}).
+		Where("id = ? AND locked_by = ? AND status = ?", id, workerID, entities.JobStatusRunning).
+		Updates(map[string]interface{}{"locked_at": now, "updated_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to refresh job lock: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, repositories.ErrJobLockLost
+	}
+
+	var job entities.Job
+	if err := r.db.WithContext(ctx).Select("cancel_requested").Where("id = ?", id).First(&job).Error; err != nil {
+		return false, fmt.Errorf("failed to read job cancellation flag: %w", err)
+	}
+	return job.CancelRequested, nil
+}
+
+func (r *jobRepositoryImpl) RequestCancel(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	// A single conditional update so a worker finishing the job at the same
+	// time cannot be overwritten or revived
+	result := r.db.WithContext(ctx).Model(&entities.Job{}).
+		Where("id = ? AND status IN ?", id, []string{entities.JobStatusQueued, entities.JobStatusRunning}).
+		Updates(map[string]interface{}{
+			"cancel_requested": true,
+			"status":           gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", entities.JobStatusQueued, entities.JobStatusCancelled),
+			"completed_at":     gorm.Expr("CASE WHEN status = ? THEN ? ELSE completed_at END", entities.JobStatusQueued, now),
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *jobRepositoryImpl) Finish(ctx context.Context, job *entities.Job, workerID string) error {
+	var status, completedAt interface{} = job.Status, job.CompletedAt
+	if job.Status == entities.JobStatusQueued {
+		now := time.Now()
+		status = gorm.Expr("CASE WHEN cancel_requested THEN ? ELSE ? END", entities.JobStatusCancelled, job.Status)
+		completedAt = gorm.Expr("CASE WHEN cancel_requested THEN ? ELSE NULL END", now)
+	}
+
+	updates := map[string]interface{}{
+		"status":       status,
+		"error":        job.Error,
+		"attempts":     job.Attempts,
+		"run_at":       job.RunAt,
+		"locked_at":    nil,
+		"locked_by":    "",
+		"updated_at":   job.UpdatedAt,
+		"completed_at": completedAt,
+	}
+	// The result column is jsonb, so it is only written when there is one
+	if job.Result != "" {
+		updates["result"] = job.Result
+		updates["result_path"] = job.ResultPath
+	}
+
+	result := r.db.WithContext(ctx).Model(&entities.Job{}).
+		Where("id = ? AND locked_by = ? AND status = ?", job.ID, workerID, entities.JobStatusRunning).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to finish job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrJobLockLost
+	}
+	return nil
+}
+
+func (r *jobRepositoryImpl) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Job{}).
+		Where("status = ? AND locked_at < ?", entities.JobStatusRunning, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":     entities.JobStatusQueued,
+			"locked_at":  nil,
+			"locked_by":  "",
+			"run_at":     time.Now(),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
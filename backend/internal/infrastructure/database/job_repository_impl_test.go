@@ -0,0 +1,282 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func setupJobTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE jobs (
+			id TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			user_id TEXT,
+			status TEXT NOT NULL DEFAULT 'queued',
+			payload TEXT,
+			result TEXT,
+			result_path TEXT,
+			error TEXT,
+			attempts INTEGER DEFAULT 0,
+			max_attempts INTEGER DEFAULT 0,
+			run_at DATETIME,
+			locked_at DATETIME,
+			locked_by TEXT,
+			cancel_requested BOOLEAN DEFAULT false,
+			created_at DATETIME,
+			updated_at DATETIME,
+			completed_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create jobs table: %v", err)
+	}
+
+	return db
+}
+
+func createTestJob(t *testing.T, repo repositories.JobRepository, jobType string, runAt time.Time) *entities.Job {
+	job := &entities.Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		UserID:      "user-123",
+		Status:      entities.JobStatusQueued,
+		Payload:     "{}",
+		MaxAttempts: 3,
+		RunAt:       runAt,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := repo.Create(context.Background(), job); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	return job
+}
+
+func TestJobRepository_ClaimNext(t *testing.T) {
+	db := setupJobTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	older := createTestJob(t, repo, "sign_document", time.Now().Add(-time.Minute))
+	createTestJob(t, repo, "sign_document", time.Now())
+	createTestJob(t, repo, "sign_document", time.Now().Add(time.Hour))
+	createTestJob(t, repo, "other", time.Now().Add(-time.Hour))
+
+	claimed, err := repo.ClaimNext(ctx, "worker-1", []string{"sign_document"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claimed == nil {
+		t.Fatal("expected a job to be claimed")
+	}
+	if claimed.ID != older.ID {
+		t.Errorf("expected oldest due job %s, got %s", older.ID, claimed.ID)
+	}
+	if claimed.Status != entities.JobStatusRunning {
+		t.Errorf("expected status %s, got %s", entities.JobStatusRunning, claimed.Status)
+	}
+	if claimed.Attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", claimed.Attempts)
+	}
+	if claimed.LockedBy != "worker-1" || claimed.LockedAt == nil {
+		t.Errorf("expected job to be locked by worker-1")
+	}
+
+	// The second due job is claimed next; the future one is left alone
+	second, err := repo.ClaimNext(ctx, "worker-1", []string{"sign_document"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if second == nil || second.ID == older.ID {
+		t.Fatal("expected the second due job to be claimed")
+	}
+
+	none, err := repo.ClaimNext(ctx, "worker-1", []string{"sign_document"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if none != nil {
+		t.Errorf("expected no job to be due, got %s", none.ID)
+	}
+}
+
+func TestJobRepository_HeartbeatAndCancel(t *testing.T) {
+	db := setupJobTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	createTestJob(t, repo, "sign_document", time.Now())
+	claimed, err := repo.ClaimNext(ctx, "worker-1", nil)
+	if err != nil || claimed == nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+
+	cancelled, err := repo.Heartbeat(ctx, claimed.ID, "worker-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cancelled {
+		t.Error("expected cancellation not to be requested")
+	}
+
+	ok, err := repo.RequestCancel(ctx, claimed.ID)
+	if err != nil || !ok {
+		t.Fatalf("expected the running job to be flagged, got %v, %v", ok, err)
+	}
+
+	cancelled, err = repo.Heartbeat(ctx, claimed.ID, "worker-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cancelled {
+		t.Error("expected cancellation to be requested")
+	}
+
+	// Another worker no longer holds the job
+	if _, err := repo.Heartbeat(ctx, claimed.ID, "worker-2"); !errors.Is(err, repositories.ErrJobLockLost) {
+		t.Errorf("expected ErrJobLockLost, got %v", err)
+	}
+}
+
+func TestJobRepository_RequestCancel(t *testing.T) {
+	db := setupJobTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	queued := createTestJob(t, repo, "sign_document", time.Now().Add(time.Hour))
+	ok, err := repo.RequestCancel(ctx, queued.ID)
+	if err != nil || !ok {
+		t.Fatalf("expected the queued job to be cancelled, got %v, %v", ok, err)
+	}
+	job, _ := repo.GetByID(ctx, queued.ID)
+	if job.Status != entities.JobStatusCancelled || !job.CancelRequested || job.CompletedAt == nil {
+		t.Errorf("expected a cancelled job, got status %s", job.Status)
+	}
+
+	// Finished jobs are left alone
+	ok, err = repo.RequestCancel(ctx, queued.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ok {
+		t.Error("expected a finished job not to be cancelled again")
+	}
+}
+
+func TestJobRepository_Finish(t *testing.T) {
+	db := setupJobTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	claim := func() *entities.Job {
+		createTestJob(t, repo, "sign_document", time.Now())
+		claimed, err := repo.ClaimNext(ctx, "worker-1", nil)
+		if err != nil || claimed == nil {
+			t.Fatalf("failed to claim job: %v", err)
+		}
+		return claimed
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		claimed := claim()
+		now := time.Now()
+		claimed.Status = entities.JobStatusSucceeded
+		claimed.Result = `{"document_id":"doc-1"}`
+		claimed.CompletedAt = &now
+		if err := repo.Finish(ctx, claimed, "worker-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		job, _ := repo.GetByID(ctx, claimed.ID)
+		if job.Status != entities.JobStatusSucceeded || job.Result != claimed.Result || job.LockedBy != "" {
+			t.Errorf("expected a succeeded, unlocked job, got status %s locked by %q", job.Status, job.LockedBy)
+		}
+	})
+
+	t.Run("retry after cancellation was requested", func(t *testing.T) {
+		claimed := claim()
+		if _, err := repo.RequestCancel(ctx, claimed.ID); err != nil {
+			t.Fatalf("failed to cancel job: %v", err)
+		}
+
+		// The worker's copy predates the request
+		claimed.Status = entities.JobStatusQueued
+		claimed.Error = "database unavailable"
+		claimed.RunAt = time.Now().Add(time.Minute)
+		if err := repo.Finish(ctx, claimed, "worker-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		job, _ := repo.GetByID(ctx, claimed.ID)
+		if job.Status != entities.JobStatusCancelled || !job.CancelRequested || job.CompletedAt == nil {
+			t.Errorf("expected the cancellation to win, got status %s", job.Status)
+		}
+	})
+
+	t.Run("lock lost", func(t *testing.T) {
+		claimed := claim()
+		if _, err := repo.RequeueStale(ctx, time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("failed to requeue job: %v", err)
+		}
+
+		claimed.Status = entities.JobStatusSucceeded
+		if err := repo.Finish(ctx, claimed, "worker-1"); !errors.Is(err, repositories.ErrJobLockLost) {
+			t.Errorf("expected ErrJobLockLost, got %v", err)
+		}
+		job, _ := repo.GetByID(ctx, claimed.ID)
+		if job.Status != entities.JobStatusQueued {
+			t.Errorf("expected the requeued job to be kept, got status %s", job.Status)
+		}
+	})
+}
+
+func TestJobRepository_RequeueStale(t *testing.T) {
+	db := setupJobTestDB(t)
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+
+	createTestJob(t, repo, "sign_document", time.Now())
+	claimed, err := repo.ClaimNext(ctx, "worker-1", nil)
+	if err != nil || claimed == nil {
+		t.Fatalf("failed to claim job: %v", err)
+	}
+
+	count, err := repo.RequeueStale(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected fresh lock to be kept, requeued %d", count)
+	}
+
+	count, err = repo.RequeueStale(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 stale job to be requeued, got %d", count)
+	}
+
+	job, err := repo.GetByID(ctx, claimed.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.Status != entities.JobStatusQueued || job.LockedBy != "" {
+		t.Errorf("expected job to be queued and unlocked, got status %s locked by %q", job.Status, job.LockedBy)
+	}
+}
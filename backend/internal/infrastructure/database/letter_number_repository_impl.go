@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type letterNumberRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLetterNumberRepository(db *gorm.DB) repositories.LetterNumberRepository {
+	return &letterNumberRepositoryImpl{db: db}
+}
+
+func (r *letterNumberRepositoryImpl) CreateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultScheme(tx, scheme); err != nil {
+			return err
+		}
+		return tx.Create(scheme).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create letter number scheme: %w", err)
+	}
+	return nil
+}
+
+func (r *letterNumberRepositoryImpl) GetScheme(ctx context.Context, organizationID, id string) (*entities.LetterNumberScheme, error) {
+	return r.findScheme(ctx, "organization_id = ? AND id = ?", organizationID, id)
+}
+
+func (r *letterNumberRepositoryImpl) GetSchemeByCode(ctx context.Context, organizationID, code string) (*entities.LetterNumberScheme, error) {
+	return r.findScheme(ctx, "organization_id = ? AND code = ?", organizationID, code)
+}
+
+func (r *letterNumberRepositoryImpl) GetDefaultScheme(ctx context.Context, organizationID string) (*entities.LetterNumberScheme, error) {
+	return r.findScheme(ctx, "organization_id = ? AND is_default = ? AND is_active = ?", organizationID, true, true)
+}
+
+func (r *letterNumberRepositoryImpl) findScheme(ctx context.Context, query string, args ...interface{}) (*entities.LetterNumberScheme, error) {
+	var scheme entities.LetterNumberScheme
+	if err := r.db.WithContext(ctx).Where(query, args...).First(&scheme).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get letter number scheme: %w", err)
+	}
+	return &scheme, nil
+}
+
+func (r *letterNumberRepositoryImpl) ListSchemes(ctx context.Context, organizationID string) ([]*entities.LetterNumberScheme, error) {
+	var schemes []*entities.LetterNumberScheme
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("code ASC").
+		Find(&schemes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list letter number schemes: %w", err)
+	}
+	return schemes, nil
+}
+
+func (r *letterNumberRepositoryImpl) UpdateScheme(ctx context.Context, scheme *entities.LetterNumberScheme) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultScheme(tx, scheme); err != nil {
+			return err
+		}
+		return tx.Save(scheme).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update letter number scheme: %w", err)
+	}
+	return nil
+}
+
+func (r *letterNumberRepositoryImpl) GetCounter(ctx context.Context, schemeID string, year int) (int64, error) {
+	var counter entities.LetterNumberCounter
+	if err := r.db.WithContext(ctx).Where("scheme_id = ? AND year = ?", schemeID, year).First(&counter).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get letter number counter: %w", err)
+	}
+	return counter.Value, nil
+}
+
+// clearDefaultScheme unsets the organization's other default schemes when
+// scheme becomes the default
+func clearDefaultScheme(tx *gorm.DB, scheme *entities.LetterNumberScheme) error {
+	if !scheme.IsDefault {
+		return nil
+	}
+	query := tx.Model(&entities.LetterNumberScheme{}).
+		Where("organization_id = ? AND is_default = ?", scheme.OrganizationID, true)
+	if scheme.ID != "" {
+		query = query.Where("id <> ?", scheme.ID)
+	}
+	return query.Update("is_default", false).Error
+}
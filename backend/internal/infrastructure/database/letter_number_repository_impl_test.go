@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupLetterNumberTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	for _, statement := range []string{
+		`CREATE TABLE letter_number_schemes (
+			id TEXT PRIMARY KEY,
+			organization_id TEXT NOT NULL,
+			code TEXT NOT NULL,
+			name TEXT NOT NULL,
+			pattern TEXT NOT NULL,
+			department TEXT,
+			padding INTEGER NOT NULL DEFAULT 3,
+			is_default BOOLEAN NOT NULL DEFAULT false,
+			is_active BOOLEAN NOT NULL DEFAULT true,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (organization_id, code)
+		)`,
+		`CREATE TABLE letter_number_counters (
+			scheme_id TEXT NOT NULL,
+			year INTEGER NOT NULL,
+			value INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME,
+			PRIMARY KEY (scheme_id, year)
+		)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+
+	return db
+}
+
+func TestLetterNumberRepository_DefaultScheme(t *testing.T) {
+	db := setupLetterNumberTestDB(t)
+	repo := NewLetterNumberRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	newScheme := func(orgID, code string, isDefault bool) *entities.LetterNumberScheme {
+		scheme := &entities.LetterNumberScheme{
+			OrganizationID: orgID, Code: code, Name: code, Pattern: "{seq}/{year}",
+			Padding: 3, IsDefault: isDefault, IsActive: true, CreatedAt: now, UpdatedAt: now,
+		}
+		if err := repo.CreateScheme(ctx, scheme); err != nil {
+			t.Fatalf("CreateScheme() error = %v", err)
+		}
+		return scheme
+	}
+
+	out := newScheme("org-1", "OUT", true)
+	other := newScheme("org-2", "OUT", true)
+
+	// A new default replaces the organization's previous one only
+	in := newScheme("org-1", "IN", true)
+	found, err := repo.GetDefaultScheme(ctx, "org-1")
+	if err != nil {
+		t.Fatalf("GetDefaultScheme() error = %v", err)
+	}
+	if found == nil || found.ID != in.ID {
+		t.Fatalf("expected IN to be the default, got %+v", found)
+	}
+	if found, _ := repo.GetDefaultScheme(ctx, "org-2"); found == nil || found.ID != other.ID {
+		t.Fatalf("expected the other organization's default to stay, got %+v", found)
+	}
+
+	// Inactive schemes are never the default
+	in.IsActive = false
+	if err := repo.UpdateScheme(ctx, in); err != nil {
+		t.Fatalf("UpdateScheme() error = %v", err)
+	}
+	if found, _ := repo.GetDefaultScheme(ctx, "org-1"); found != nil {
+		t.Fatalf("expected no default scheme, got %+v", found)
+	}
+
+	out.IsDefault = true
+	if err := repo.UpdateScheme(ctx, out); err != nil {
+		t.Fatalf("UpdateScheme() error = %v", err)
+	}
+	if found, _ := repo.GetSchemeByCode(ctx, "org-1", "IN"); found == nil || found.IsDefault {
+		t.Fatalf("expected IN to lose the default, got %+v", found)
+	}
+
+	schemes, err := repo.ListSchemes(ctx, "org-1")
+	if err != nil {
+		t.Fatalf("ListSchemes() error = %v", err)
+	}
+	if len(schemes) != 2 || schemes[0].Code != "IN" || schemes[1].Code != "OUT" {
+		t.Fatalf("expected IN and OUT, got %+v", schemes)
+	}
+
+	// Schemes are scoped to their organization
+	if found, _ := repo.GetScheme(ctx, "org-2", out.ID); found != nil {
+		t.Fatalf("expected no scheme, got %+v", found)
+	}
+	if err := repo.CreateScheme(ctx, &entities.LetterNumberScheme{OrganizationID: "org-1", Code: "OUT", Name: "Again", Pattern: "{seq}/{year}"}); err == nil {
+		t.Fatal("expected a duplicate code to be rejected")
+	}
+}
+
+func TestLetterNumberRepository_GetCounter(t *testing.T) {
+	db := setupLetterNumberTestDB(t)
+	repo := NewLetterNumberRepository(db)
+	ctx := context.Background()
+
+	value, err := repo.GetCounter(ctx, "scheme-1", 2026)
+	if err != nil || value != 0 {
+		t.Fatalf("expected 0 for an unused year, got %d (%v)", value, err)
+	}
+
+	db.Create(&entities.LetterNumberCounter{SchemeID: "scheme-1", Year: 2026, Value: 41})
+	value, err = repo.GetCounter(ctx, "scheme-1", 2026)
+	if err != nil || value != 41 {
+		t.Fatalf("expected 41, got %d (%v)", value, err)
+	}
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type lockRepositoryImpl struct {
+	db *gorm.DB
+
+	// held backs the locks on databases without advisory locks, where only
+	// this process can contend for them
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func NewLockRepository(db *gorm.DB) repositories.LockRepository {
+	return &lockRepositoryImpl{db: db, held: make(map[string]bool)}
+}
+
+func (r *lockRepositoryImpl) TryLock(ctx context.Context, name string) (func(), error) {
+	if r.db.Dialector.Name() != "postgres" {
+		return r.tryLocalLock(name), nil
+	}
+
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+
+	// Advisory locks belong to the session, so the lock is taken and released on
+	// a connection kept out of the pool until release
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	key := advisoryLockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+
+	return func() {
+		// The lock outlives the caller's context, so release with a fresh one
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			fmt.Printf("Warning: failed to release advisory lock %s: %v\n", name, err)
+		}
+		conn.Close()
+	}, nil
+}
+
+func (r *lockRepositoryImpl) tryLocalLock(name string) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.held[name] {
+		return nil
+	}
+	r.held[name] = true
+
+	return func() {
+		r.mu.Lock()
+		delete(r.held, name)
+		r.mu.Unlock()
+	}
+}
+
+// advisoryLockKey maps a lock name onto the bigint key space of advisory locks
+func advisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
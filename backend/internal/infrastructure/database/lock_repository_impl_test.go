@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLockRepository_TryLock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	repo := NewLockRepository(db)
+	ctx := context.Background()
+
+	release, err := repo.TryLock(ctx, "maintenance:session-cleanup")
+	if err != nil || release == nil {
+		t.Fatalf("expected the lock to be taken, got %v", err)
+	}
+
+	again, err := repo.TryLock(ctx, "maintenance:session-cleanup")
+	if err != nil || again != nil {
+		t.Fatalf("expected the held lock to be refused, got %v", err)
+	}
+
+	other, err := repo.TryLock(ctx, "maintenance:key-age-check")
+	if err != nil || other == nil {
+		t.Fatalf("expected an unrelated lock to be taken, got %v", err)
+	}
+	other()
+
+	release()
+	release, err = repo.TryLock(ctx, "maintenance:session-cleanup")
+	if err != nil || release == nil {
+		t.Fatalf("expected the released lock to be taken again, got %v", err)
+	}
+	release()
+}
+
+func TestAdvisoryLockKey(t *testing.T) {
+	if advisoryLockKey("maintenance:a") != advisoryLockKey("maintenance:a") {
+		t.Fatal("expected the key to be stable")
+	}
+	if advisoryLockKey("maintenance:a") == advisoryLockKey("maintenance:b") {
+		t.Fatal("expected different names to get different keys")
+	}
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type loginThrottleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) repositories.LoginThrottleRepository {
+	return &loginThrottleRepositoryImpl{db: db}
+}
+
+func (r *loginThrottleRepositoryImpl) Get(ctx context.Context, scope, key string) (*entities.LoginThrottle, error) {
+	var throttle entities.LoginThrottle
+	if err := r.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&throttle).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get login throttle: %w", err)
+	}
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.LoginThrottle, error) {
+	var throttle entities.LoginThrottle
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&throttle).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get login throttle by ID: %w", err)
+	}
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepositoryImpl) RecordFailure(ctx context.Context, scope, key string, now, staleBefore time.Time) (*entities.LoginThrottle, error) {
+	// Parallel guesses must each be counted, so the increment happens in a single upsert
+	reset := "(login_throttles.last_failure_at < ? OR (login_throttles.locked_until IS NOT NULL AND login_throttles.locked_until <= ?))"
+	throttle := &entities.LoginThrottle{
+		Scope:         scope,
+		Key:           key,
+		Failures:      1,
+		LastFailureAt: now,
+	}
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN "+reset+" THEN 1 ELSE login_throttles.failures + 1 END", staleBefore, now),
+			"locked_until":    gorm.Expr("CASE WHEN "+reset+" THEN NULL ELSE login_throttles.locked_until END", staleBefore, now),
+			"last_failure_at": now,
+			"updated_at":      now,
+		}),
+	}).Create(throttle).Error; err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return r.Get(ctx, scope, key)
+}
+
+func (r *loginThrottleRepositoryImpl) Lock(ctx context.Context, id string, now, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.LoginThrottle{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		Updates(map[string]interface{}{"locked_until": until, "updated_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to lock login throttle: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *loginThrottleRepositoryImpl) ListLocked(ctx context.Context, now time.Time) ([]*entities.LoginThrottle, error) {
+	var throttles []*entities.LoginThrottle
+	if err := r.db.WithContext(ctx).
+		Where("locked_until > ?", now).
+		Order("locked_until DESC").
+		Find(&throttles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list locked login throttles: %w", err)
+	}
+	return throttles, nil
+}
+
+func (r *loginThrottleRepositoryImpl) Clear(ctx context.Context, scope, key string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.LoginThrottle{}, "scope = ? AND key = ?", scope, key).Error; err != nil {
+		return fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+	return nil
+}
+
+func (r *loginThrottleRepositoryImpl) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.LoginThrottle{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete login throttle: %w", err)
+	}
+	return nil
+}
+
+func (r *loginThrottleRepositoryImpl) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&entities.LoginThrottle{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete stale login throttles: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupLoginThrottleTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+	// Every in-memory connection is a separate database, so goroutines must share one
+	sqlDB.SetMaxOpenConns(1)
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE login_throttles (
+			id TEXT PRIMARY KEY,
+			scope TEXT NOT NULL,
+			key TEXT NOT NULL,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_failure_at DATETIME,
+			locked_until DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (scope, key)
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create login_throttles table: %v", err)
+	}
+
+	return db
+}
+
+func TestLoginThrottleRepository_RecordFailure(t *testing.T) {
+	repo := NewLoginThrottleRepository(setupLoginThrottleTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for i := 1; i <= 3; i++ {
+		throttle, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, "alice", now, now.Add(-15*time.Minute))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if throttle.Failures != i {
+			t.Fatalf("expected %d failures, got %d", i, throttle.Failures)
+		}
+	}
+
+	// The same name in another scope is counted separately
+	throttle, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeIP, "alice", now, now.Add(-15*time.Minute))
+	if err != nil || throttle.Failures != 1 {
+		t.Fatalf("expected a fresh ip counter, got %+v (%v)", throttle, err)
+	}
+
+	// Failures older than the window start the count over
+	later := now.Add(time.Hour)
+	throttle, err = repo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, "alice", later, later.Add(-15*time.Minute))
+	if err != nil || throttle.Failures != 1 {
+		t.Fatalf("expected the count to restart, got %+v (%v)", throttle, err)
+	}
+}
+
+func TestLoginThrottleRepository_RecordFailure_Concurrent(t *testing.T) {
+	repo := NewLoginThrottleRepository(setupLoginThrottleTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, "alice", now, now.Add(-time.Minute)); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	throttle, err := repo.Get(ctx, entities.LoginThrottleScopeUsername, "alice")
+	if err != nil || throttle.Failures != 20 {
+		t.Fatalf("expected every failure to be counted, got %+v (%v)", throttle, err)
+	}
+}
+
+func TestLoginThrottleRepository_Lock(t *testing.T) {
+	repo := NewLoginThrottleRepository(setupLoginThrottleTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	throttle, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, "alice", now, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	locked, err := repo.Lock(ctx, throttle.ID, now, now.Add(15*time.Minute))
+	if err != nil || !locked {
+		t.Fatalf("expected the lock to be set, got %v (%v)", locked, err)
+	}
+	// Only the first caller locks, so the lockout is audited once
+	locked, err = repo.Lock(ctx, throttle.ID, now, now.Add(30*time.Minute))
+	if err != nil || locked {
+		t.Fatalf("expected an existing lock to be kept, got %v (%v)", locked, err)
+	}
+
+	lockedThrottles, err := repo.ListLocked(ctx, now)
+	if err != nil || len(lockedThrottles) != 1 {
+		t.Fatalf("expected one locked throttle, got %d (%v)", len(lockedThrottles), err)
+	}
+	if !lockedThrottles[0].IsLocked(now) {
+		t.Error("expected the throttle to report it is locked")
+	}
+
+	// A failure after the lock expires starts a fresh count
+	afterLock := now.Add(20 * time.Minute)
+	throttle, err = repo.RecordFailure(ctx, entities.LoginThrottleScopeUsername, "alice", afterLock, afterLock.Add(-time.Hour))
+	if err != nil || throttle.Failures != 1 || throttle.LockedUntil != nil {
+		t.Fatalf("expected the expired lock to be cleared, got %+v (%v)", throttle, err)
+	}
+
+	if err := repo.Clear(ctx, entities.LoginThrottleScopeUsername, "alice"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if throttle, _ := repo.Get(ctx, entities.LoginThrottleScopeUsername, "alice"); throttle != nil {
+		t.Error("expected the throttle to be cleared")
+	}
+}
+
+func TestLoginThrottleRepository_DeleteStale(t *testing.T) {
+	repo := NewLoginThrottleRepository(setupLoginThrottleTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	old := now.Add(-48 * time.Hour)
+	if _, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeIP, "10.0.0.1", old, old); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := repo.RecordFailure(ctx, entities.LoginThrottleScopeIP, "10.0.0.2", now, now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	removed, err := repo.DeleteStale(ctx, now.Add(-24*time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one stale throttle removed, got %d (%v)", removed, err)
+	}
+}
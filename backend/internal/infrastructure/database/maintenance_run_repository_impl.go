@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type maintenanceRunRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRunRepository(db *gorm.DB) repositories.MaintenanceRunRepository {
+	return &maintenanceRunRepositoryImpl{db: db}
+}
+
+func (r *maintenanceRunRepositoryImpl) Create(ctx context.Context, run *entities.MaintenanceRun) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create maintenance run: %w", err)
+	}
+	return nil
+}
+
+func (r *maintenanceRunRepositoryImpl) Update(ctx context.Context, run *entities.MaintenanceRun) error {
+	if err := r.db.WithContext(ctx).Save(run).Error; err != nil {
+		return fmt.Errorf("failed to update maintenance run: %w", err)
+	}
+	return nil
+}
+
+func (r *maintenanceRunRepositoryImpl) GetLatest(ctx context.Context, task, trigger string) (*entities.MaintenanceRun, error) {
+	var run entities.MaintenanceRun
+	err := r.db.WithContext(ctx).
+		Where("task = ? AND trigger = ?", task, trigger).
+		Order("scheduled_for DESC").
+		First(&run).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest maintenance run: %w", err)
+	}
+	return &run, nil
+}
+
+func (r *maintenanceRunRepositoryImpl) List(ctx context.Context, task string, limit, offset int) ([]*entities.MaintenanceRun, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entities.MaintenanceRun{})
+	if task != "" {
+		query = query.Where("task = ?", task)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count maintenance runs: %w", err)
+	}
+
+	var runs []*entities.MaintenanceRun
+	if err := query.Order("started_at DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list maintenance runs: %w", err)
+	}
+	return runs, total, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupMaintenanceRunTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE maintenance_runs (
+			id TEXT PRIMARY KEY,
+			task TEXT NOT NULL,
+			"trigger" TEXT NOT NULL,
+			scheduled_for DATETIME NOT NULL,
+			status TEXT NOT NULL,
+			result TEXT,
+			error TEXT,
+			node TEXT,
+			started_at DATETIME,
+			finished_at DATETIME,
+			duration_ms INTEGER,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create maintenance_runs table: %v", err)
+	}
+
+	return db
+}
+
+func TestMaintenanceRunRepository_GetLatest(t *testing.T) {
+	repo := NewMaintenanceRunRepository(setupMaintenanceRunTestDB(t))
+	ctx := context.Background()
+	slot := time.Date(2026, time.March, 14, 10, 0, 0, 0, time.UTC)
+
+	latest, err := repo.GetLatest(ctx, "session-cleanup", entities.MaintenanceTriggerSchedule)
+	if err != nil || latest != nil {
+		t.Fatalf("expected no run yet, got %v, %v", latest, err)
+	}
+
+	for i, run := range []*entities.MaintenanceRun{
+		{Task: "session-cleanup", Trigger: entities.MaintenanceTriggerSchedule, ScheduledFor: slot.Add(-time.Hour)},
+		{Task: "session-cleanup", Trigger: entities.MaintenanceTriggerSchedule, ScheduledFor: slot},
+		{Task: "session-cleanup", Trigger: entities.MaintenanceTriggerManual, ScheduledFor: slot.Add(time.Minute)},
+		{Task: "key-age-check", Trigger: entities.MaintenanceTriggerSchedule, ScheduledFor: slot.Add(time.Hour)},
+	} {
+		run.Status = entities.MaintenanceRunRunning
+		run.StartedAt = slot.Add(time.Duration(i) * time.Second)
+		if err := repo.Create(ctx, run); err != nil {
+			t.Fatalf("failed to create run: %v", err)
+		}
+	}
+
+	latest, err = repo.GetLatest(ctx, "session-cleanup", entities.MaintenanceTriggerSchedule)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if latest == nil || !latest.ScheduledFor.Equal(slot) {
+		t.Fatalf("expected the run for %v, got %+v", slot, latest)
+	}
+
+	now := slot.Add(2 * time.Minute)
+	latest.Status = entities.MaintenanceRunSucceeded
+	latest.Result = "removed 3 sessions"
+	latest.FinishedAt = &now
+	if err := repo.Update(ctx, latest); err != nil {
+		t.Fatalf("failed to update run: %v", err)
+	}
+
+	runs, total, err := repo.List(ctx, "session-cleanup", 10, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if total != 3 || len(runs) != 3 {
+		t.Fatalf("expected 3 session cleanup runs, got %d of %d", len(runs), total)
+	}
+	if runs[0].Trigger != entities.MaintenanceTriggerManual {
+		t.Fatalf("expected newest run first, got %+v", runs[0])
+	}
+	if runs[1].Status != entities.MaintenanceRunSucceeded || runs[1].Result != "removed 3 sessions" {
+		t.Fatalf("expected the updated run, got %+v", runs[1])
+	}
+
+	runs, total, err = repo.List(ctx, "", 2, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if total != 4 || len(runs) != 2 {
+		t.Fatalf("expected the second page of 4 runs, got %d of %d", len(runs), total)
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type mfaRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) repositories.MFARepository {
+	return &mfaRepositoryImpl{db: db}
+}
+
+func (r *mfaRepositoryImpl) Get(ctx context.Context, userID string) (*entities.UserMFA, error) {
+	var mfa entities.UserMFA
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get MFA enrollment: %w", err)
+	}
+	return &mfa, nil
+}
+
+func (r *mfaRepositoryImpl) Save(ctx context.Context, mfa *entities.UserMFA) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled", "last_used_step", "enabled_at", "updated_at"}),
+	}).Create(mfa).Error; err != nil {
+		return fmt.Errorf("failed to save MFA enrollment: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepositoryImpl) Delete(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entities.MFARecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		if err := tx.Delete(&entities.UserMFA{}, "user_id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to delete MFA enrollment: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepositoryImpl) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	// Two requests racing with the same code must not both succeed
+	result := r.db.WithContext(ctx).Model(&entities.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Updates(map[string]interface{}{"last_used_step": step, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record MFA code use: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfaRepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entities.MFARecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		codes := make([]*entities.MFARecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, &entities.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return fmt.Errorf("failed to create recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepositoryImpl) UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfaRepositoryImpl) CountRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupMFATestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE user_mfa (
+			user_id TEXT PRIMARY KEY,
+			secret TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT false,
+			last_used_step INTEGER NOT NULL DEFAULT 0,
+			enabled_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create user_mfa table: %v", err)
+	}
+	err = db.Exec(`
+		CREATE TABLE mfa_recovery_codes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create mfa_recovery_codes table: %v", err)
+	}
+
+	return db
+}
+
+func TestMFARepository_SaveAndUseStep(t *testing.T) {
+	repo := NewMFARepository(setupMFATestDB(t))
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &entities.UserMFA{UserID: "user-1", Secret: "pending"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	now := time.Now()
+	if err := repo.Save(ctx, &entities.UserMFA{UserID: "user-1", Secret: "confirmed", Enabled: true, LastUsedStep: 10, EnabledAt: &now}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mfa, err := repo.Get(ctx, "user-1")
+	if err != nil || mfa == nil || !mfa.Enabled || mfa.Secret != "confirmed" {
+		t.Fatalf("expected the enrollment to be replaced, got %+v (%v)", mfa, err)
+	}
+
+	if used, err := repo.UseStep(ctx, "user-1", 10); err != nil || used {
+		t.Fatalf("expected an already used step to be refused, got %v (%v)", used, err)
+	}
+	if used, err := repo.UseStep(ctx, "user-1", 11); err != nil || !used {
+		t.Fatalf("expected a newer step to be accepted, got %v (%v)", used, err)
+	}
+
+	if err := repo.Delete(ctx, "user-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mfa, _ := repo.Get(ctx, "user-1"); mfa != nil {
+		t.Error("expected the enrollment to be deleted")
+	}
+}
+
+func TestMFARepository_RecoveryCodes(t *testing.T) {
+	repo := NewMFARepository(setupMFATestDB(t))
+	ctx := context.Background()
+
+	if err := repo.ReplaceRecoveryCodes(ctx, "user-1", []string{"a", "b"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if used, err := repo.UseRecoveryCode(ctx, "user-1", "a", time.Now()); err != nil || !used {
+		t.Fatalf("expected the code to be used, got %v (%v)", used, err)
+	}
+	if used, _ := repo.UseRecoveryCode(ctx, "user-1", "a", time.Now()); used {
+		t.Error("expected a recovery code to work only once")
+	}
+	if used, _ := repo.UseRecoveryCode(ctx, "user-2", "b", time.Now()); used {
+		t.Error("expected another user's code to be refused")
+	}
+	if count, err := repo.CountRecoveryCodes(ctx, "user-1"); err != nil || count != 1 {
+		t.Fatalf("expected one remaining code, got %d (%v)", count, err)
+	}
+
+	if err := repo.ReplaceRecoveryCodes(ctx, "user-1", []string{"c", "d", "e"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if used, _ := repo.UseRecoveryCode(ctx, "user-1", "b", time.Now()); used {
+		t.Error("expected replaced codes to stop working")
+	}
+	if count, _ := repo.CountRecoveryCodes(ctx, "user-1"); count != 3 {
+		t.Errorf("expected three codes, got %d", count)
+	}
+}
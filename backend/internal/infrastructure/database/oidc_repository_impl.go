@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type oidcRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) repositories.OIDCRepository {
+	return &oidcRepositoryImpl{db: db}
+}
+
+func (r *oidcRepositoryImpl) CreateAuthRequest(ctx context.Context, request *entities.OIDCAuthRequest) error {
+	if err := r.db.WithContext(ctx).Create(request).Error; err != nil {
+		return fmt.Errorf("failed to create OIDC authorization request: %w", err)
+	}
+	return nil
+}
+
+func (r *oidcRepositoryImpl) ConsumeAuthRequest(ctx context.Context, stateHash string, now time.Time) (*entities.OIDCAuthRequest, error) {
+	var request entities.OIDCAuthRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&request).Error; err != nil {
+			return err
+		}
+		// The delete decides which of two concurrent callbacks gets the request
+		result := tx.Delete(&entities.OIDCAuthRequest{}, "id = ?", request.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume OIDC authorization request: %w", err)
+	}
+	if !request.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &request, nil
+}
+
+func (r *oidcRepositoryImpl) DeleteExpiredAuthRequests(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&entities.OIDCAuthRequest{}, "expires_at <= ?", now)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired OIDC authorization requests: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *oidcRepositoryImpl) GetIdentity(ctx context.Context, provider, subject string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	if err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *oidcRepositoryImpl) CreateIdentity(ctx context.Context, identity *entities.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *oidcRepositoryImpl) UpdateIdentity(ctx context.Context, identity *entities.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Save(identity).Error; err != nil {
+		return fmt.Errorf("failed to update user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *oidcRepositoryImpl) DeleteIdentity(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.UserIdentity{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete user identity: %w", err)
+	}
+	return nil
+}
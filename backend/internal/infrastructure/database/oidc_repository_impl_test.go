@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupOIDCTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE oidc_auth_requests (
+			id TEXT PRIMARY KEY,
+			provider TEXT NOT NULL,
+			state_hash TEXT NOT NULL UNIQUE,
+			nonce TEXT NOT NULL,
+			code_verifier TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create oidc_auth_requests table: %v", err)
+	}
+
+	err = db.Exec(`
+		CREATE TABLE user_identities (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			email TEXT,
+			last_login_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (provider, subject)
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create user_identities table: %v", err)
+	}
+
+	return db
+}
+
+func TestOIDCRepository_ConsumeAuthRequest(t *testing.T) {
+	repo := NewOIDCRepository(setupOIDCTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, request := range []*entities.OIDCAuthRequest{
+		{Provider: "university", StateHash: "live", Nonce: "n1", CodeVerifier: "v1", ExpiresAt: now.Add(10 * time.Minute)},
+		{Provider: "university", StateHash: "stale", Nonce: "n2", CodeVerifier: "v2", ExpiresAt: now.Add(-time.Minute)},
+	} {
+		if err := repo.CreateAuthRequest(ctx, request); err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+	}
+
+	request, err := repo.ConsumeAuthRequest(ctx, "live", now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if request == nil || request.Nonce != "n1" || request.CodeVerifier != "v1" {
+		t.Fatalf("expected the live request, got %+v", request)
+	}
+
+	// A state can only be used once
+	request, err = repo.ConsumeAuthRequest(ctx, "live", now)
+	if err != nil || request != nil {
+		t.Fatalf("expected the request to be consumed, got %+v, %v", request, err)
+	}
+
+	request, err = repo.ConsumeAuthRequest(ctx, "stale", now)
+	if err != nil || request != nil {
+		t.Fatalf("expected the expired request to be rejected, got %+v, %v", request, err)
+	}
+
+	if err := repo.CreateAuthRequest(ctx, &entities.OIDCAuthRequest{
+		Provider: "university", StateHash: "old", Nonce: "n3", CodeVerifier: "v3", ExpiresAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	removed, err := repo.DeleteExpiredAuthRequests(ctx, now)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected 1 expired request removed, got %d, %v", removed, err)
+	}
+}
+
+func TestOIDCRepository_Identities(t *testing.T) {
+	repo := NewOIDCRepository(setupOIDCTestDB(t))
+	ctx := context.Background()
+
+	identity, err := repo.GetIdentity(ctx, "university", "u-1001")
+	if err != nil || identity != nil {
+		t.Fatalf("expected no identity yet, got %+v, %v", identity, err)
+	}
+
+	identity = &entities.UserIdentity{UserID: "user-1", Provider: "university", Subject: "u-1001", Email: "ada@example.edu"}
+	if err := repo.CreateIdentity(ctx, identity); err != nil {
+		t.Fatalf("failed to create identity: %v", err)
+	}
+	if err := repo.CreateIdentity(ctx, &entities.UserIdentity{UserID: "user-2", Provider: "university", Subject: "u-1001"}); err == nil {
+		t.Fatal("expected a duplicate subject to be rejected")
+	}
+
+	now := time.Now()
+	identity.LastLoginAt = &now
+	identity.Email = "ada.lovelace@example.edu"
+	if err := repo.UpdateIdentity(ctx, identity); err != nil {
+		t.Fatalf("failed to update identity: %v", err)
+	}
+
+	found, err := repo.GetIdentity(ctx, "university", "u-1001")
+	if err != nil || found == nil {
+		t.Fatalf("expected the identity, got %+v, %v", found, err)
+	}
+	if found.UserID != "user-1" || found.Email != "ada.lovelace@example.edu" || found.LastLoginAt == nil {
+		t.Fatalf("unexpected identity %+v", found)
+	}
+
+	if found, _ := repo.GetIdentity(ctx, "partner", "u-1001"); found != nil {
+		t.Fatal("expected subjects to be scoped to their provider")
+	}
+
+	if err := repo.DeleteIdentity(ctx, identity.ID); err != nil {
+		t.Fatalf("failed to delete identity: %v", err)
+	}
+	if found, _ := repo.GetIdentity(ctx, "university", "u-1001"); found != nil {
+		t.Fatal("expected the identity to be deleted")
+	}
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type organizationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) repositories.OrganizationRepository {
+	return &organizationRepositoryImpl{db: db}
+}
+
+func (r *organizationRepositoryImpl) Create(ctx context.Context, org *entities.Organization) error {
+	if err := r.db.WithContext(ctx).Create(org).Error; err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Organization, error) {
+	var org entities.Organization
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &org, nil
+}
+
+func (r *organizationRepositoryImpl) GetBySlug(ctx context.Context, slug string) (*entities.Organization, error) {
+	var org entities.Organization
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization by slug: %w", err)
+	}
+	return &org, nil
+}
+
+func (r *organizationRepositoryImpl) List(ctx context.Context) ([]*entities.Organization, error) {
+	var orgs []*entities.Organization
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&orgs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+func (r *organizationRepositoryImpl) Update(ctx context.Context, org *entities.Organization) error {
+	if err := r.db.WithContext(ctx).Save(org).Error; err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) GetMember(ctx context.Context, organizationID, userID string) (*entities.OrganizationMember, error) {
+	var member entities.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return &member, nil
+}
+
+func (r *organizationRepositoryImpl) ListMembers(ctx context.Context, organizationID string) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("organization_id = ?", organizationID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	return members, nil
+}
+
+func (r *organizationRepositoryImpl) ListMemberships(ctx context.Context, userID string) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	err := r.db.WithContext(ctx).Preload("Organization").
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id").
+		Where("organization_members.user_id = ? AND organizations.is_active = ?", userID, true).
+		Order("organizations.name ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization memberships: %w", err)
+	}
+	return members, nil
+}
+
+func (r *organizationRepositoryImpl) SaveMember(ctx context.Context, member *entities.OrganizationMember) error {
+	if err := r.db.WithContext(ctx).Save(member).Error; err != nil {
+		return fmt.Errorf("failed to save organization member: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) DeleteMember(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.OrganizationMember{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete organization member: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) CreateKey(ctx context.Context, key *entities.OrganizationKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create organization key: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepositoryImpl) GetActiveKey(ctx context.Context, organizationID string) (*entities.OrganizationKey, error) {
+	var key entities.OrganizationKey
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND status = ?", organizationID, entities.OrganizationKeyActive).
+		Order("created_at DESC").
+		First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active organization key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *organizationRepositoryImpl) GetKey(ctx context.Context, organizationID, keyID string) (*entities.OrganizationKey, error) {
+	var key entities.OrganizationKey
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND key_id = ?", organizationID, keyID).
+		First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *organizationRepositoryImpl) ListKeys(ctx context.Context, organizationID string) ([]*entities.OrganizationKey, error) {
+	var keys []*entities.OrganizationKey
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *organizationRepositoryImpl) RotateKey(ctx context.Context, key *entities.OrganizationKey, retiredAt time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.OrganizationKey{}).
+			Where("organization_id = ? AND status = ?", key.OrganizationID, entities.OrganizationKeyActive).
+			Updates(map[string]interface{}{"status": entities.OrganizationKeyRetired, "retired_at": retiredAt}).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate organization key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupOrganizationTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	statements := []string{
+		`CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT UNIQUE NOT NULL,
+			password_hash TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			role TEXT DEFAULT 'user',
+			created_at DATETIME,
+			updated_at DATETIME,
+			is_active BOOLEAN DEFAULT true,
+			service_account BOOLEAN DEFAULT false
+		)`,
+		`CREATE TABLE organizations (
+			id TEXT PRIMARY KEY,
+			slug TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			issuer_name TEXT,
+			verification_base_url TEXT,
+			stamp_label TEXT,
+			stamp_x REAL,
+			stamp_y REAL,
+			stamp_width REAL,
+			verification_redactions TEXT,
+			is_active BOOLEAN DEFAULT true,
+			created_at DATETIME,
+			updated_at DATETIME
+		)`,
+		`CREATE TABLE organization_members (
+			id TEXT PRIMARY KEY,
+			organization_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'member',
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (organization_id, user_id)
+		)`,
+		`CREATE TABLE organization_keys (
+			id TEXT PRIMARY KEY,
+			organization_id TEXT NOT NULL,
+			key_id TEXT NOT NULL UNIQUE,
+			algorithm TEXT NOT NULL,
+			public_key TEXT NOT NULL,
+			private_key TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'active',
+			created_at DATETIME,
+			retired_at DATETIME
+		)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+
+	return db
+}
+
+func TestOrganizationRepository_Memberships(t *testing.T) {
+	db := setupOrganizationTestDB(t)
+	repo := NewOrganizationRepository(db)
+	ctx := context.Background()
+
+	user := &entities.User{Username: "dean", PasswordHash: "hash", FullName: "Dean", Email: "dean@example.com", IsActive: true}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	active := &entities.Organization{Slug: "engineering", Name: "Engineering", IsActive: true, VerificationRedactions: []string{"filename", "document_hash"}}
+	inactive := &entities.Organization{Slug: "closed", Name: "Closed"}
+	for _, org := range []*entities.Organization{active, inactive} {
+		if err := repo.Create(ctx, org); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	db.Model(inactive).Update("is_active", false)
+
+	found, err := repo.GetBySlug(ctx, "engineering")
+	if err != nil || found == nil || found.ID != active.ID {
+		t.Fatalf("GetBySlug() = %+v, %v", found, err)
+	}
+	if len(found.VerificationRedactions) != 2 || found.VerificationRedactions[1] != "document_hash" {
+		t.Errorf("VerificationRedactions = %v, want [filename document_hash]", found.VerificationRedactions)
+	}
+
+	for _, org := range []*entities.Organization{active, inactive} {
+		member := &entities.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: entities.OrganizationRoleAdmin}
+		if err := repo.SaveMember(ctx, member); err != nil {
+			t.Fatalf("SaveMember() error = %v", err)
+		}
+	}
+
+	memberships, err := repo.ListMemberships(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListMemberships() error = %v", err)
+	}
+	if len(memberships) != 1 || memberships[0].Organization == nil || memberships[0].Organization.Slug != "engineering" {
+		t.Fatalf("expected only the active organization, got %+v", memberships)
+	}
+
+	members, err := repo.ListMembers(ctx, active.ID)
+	if err != nil {
+		t.Fatalf("ListMembers() error = %v", err)
+	}
+	if len(members) != 1 || members[0].User == nil || members[0].User.Username != "dean" {
+		t.Fatalf("expected the member with their user, got %+v", members)
+	}
+
+	if err := repo.DeleteMember(ctx, members[0].ID); err != nil {
+		t.Fatalf("DeleteMember() error = %v", err)
+	}
+	member, err := repo.GetMember(ctx, active.ID, user.ID)
+	if err != nil || member != nil {
+		t.Fatalf("expected the membership to be gone, got %+v, %v", member, err)
+	}
+}
+
+func TestOrganizationRepository_RotateKey(t *testing.T) {
+	repo := NewOrganizationRepository(setupOrganizationTestDB(t))
+	ctx := context.Background()
+	orgID := "org-1"
+
+	first := &entities.OrganizationKey{OrganizationID: orgID, KeyID: "key-1", Algorithm: "RSA-2048", PublicKey: "pub-1", PrivateKey: "priv-1", Status: entities.OrganizationKeyActive, CreatedAt: time.Now().Add(-time.Hour)}
+	if err := repo.CreateKey(ctx, first); err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+
+	second := &entities.OrganizationKey{OrganizationID: orgID, KeyID: "key-2", Algorithm: "RSA-2048", PublicKey: "pub-2", PrivateKey: "priv-2", Status: entities.OrganizationKeyActive, CreatedAt: time.Now()}
+	if err := repo.RotateKey(ctx, second, second.CreatedAt); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+
+	activeKey, err := repo.GetActiveKey(ctx, orgID)
+	if err != nil || activeKey == nil || activeKey.KeyID != "key-2" {
+		t.Fatalf("GetActiveKey() = %+v, %v", activeKey, err)
+	}
+
+	retired, err := repo.GetKey(ctx, orgID, "key-1")
+	if err != nil || retired == nil {
+		t.Fatalf("GetKey() = %+v, %v", retired, err)
+	}
+	if retired.Status != entities.OrganizationKeyRetired || retired.RetiredAt == nil {
+		t.Fatalf("expected the first key to be retired, got %+v", retired)
+	}
+
+	if other, err := repo.GetKey(ctx, "org-2", "key-1"); err != nil || other != nil {
+		t.Fatalf("expected keys to be looked up within their organization, got %+v, %v", other, err)
+	}
+
+	keys, err := repo.ListKeys(ctx, orgID)
+	if err != nil || len(keys) != 2 || keys[0].KeyID != "key-2" {
+		t.Fatalf("ListKeys() = %+v, %v", keys, err)
+	}
+}
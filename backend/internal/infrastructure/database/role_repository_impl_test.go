@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupRoleTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE roles (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			permissions TEXT,
+			built_in BOOLEAN DEFAULT false,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create roles table: %v", err)
+	}
+
+	return db
+}
+
+func TestRoleRepository_CRUD(t *testing.T) {
+	repo := NewRoleRepository(setupRoleTestDB(t))
+	ctx := context.Background()
+
+	role := &entities.Role{
+		Name:        "registrar",
+		Description: "Signs transcripts",
+		Permissions: []string{entities.PermissionDocumentSign, entities.PermissionDocumentReadAny},
+	}
+	if err := repo.Create(ctx, role); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Create(ctx, &entities.Role{Name: "admin", BuiltIn: true}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := repo.GetByName(ctx, "registrar")
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+	if found == nil || !found.HasPermission(entities.PermissionDocumentReadAny) || found.HasPermission(entities.PermissionUserManage) {
+		t.Fatalf("expected permissions to round-trip, got %+v", found)
+	}
+
+	found.Permissions = append(found.Permissions, entities.PermissionAuditRead)
+	if err := repo.Update(ctx, found); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	roles, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(roles) != 2 || roles[0].Name != "admin" || !roles[1].HasPermission(entities.PermissionAuditRead) {
+		t.Fatalf("expected both roles sorted by name, got %+v", roles)
+	}
+
+	if err := repo.Delete(ctx, found.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	missing, err := repo.GetByName(ctx, "registrar")
+	if err != nil || missing != nil {
+		t.Fatalf("expected the role to be gone, got %+v, %v", missing, err)
+	}
+}
@@ -26,6 +26,17 @@ func (r *sessionRepositoryImpl) Create(ctx context.Context, session *entities.Se
 	return nil
 }
 
+func (r *sessionRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	var session entities.Session
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session by ID: %w", err)
+	}
+	return &session, nil
+}
+
 func (r *sessionRepositoryImpl) GetByToken(ctx context.Context, token string) (*entities.Session, error) {
 	var session entities.Session
 	if err := r.db.WithContext(ctx).Preload("User").Where("session_token = ?", token).First(&session).Error; err != nil {
@@ -39,7 +50,7 @@ func (r *sessionRepositoryImpl) GetByToken(ctx context.Context, token string) (*
 
 func (r *sessionRepositoryImpl) GetByUserID(ctx context.Context, userID string) ([]*entities.Session, error) {
 	var sessions []*entities.Session
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&sessions).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_accessed DESC").Find(&sessions).Error; err != nil {
 		return nil, fmt.Errorf("failed to get sessions by user ID: %w", err)
 	}
 	return sessions, nil
@@ -52,6 +63,31 @@ func (r *sessionRepositoryImpl) Update(ctx context.Context, session *entities.Se
 	return nil
 }
 
+func (r *sessionRepositoryImpl) Touch(ctx context.Context, id string, accessedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entities.Session{}).Where("id = ?", id).
+		Update("last_accessed", accessedAt).Error; err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepositoryImpl) Rotate(ctx context.Context, session *entities.Session, fromGeneration int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ? AND refresh_generation = ?", session.ID, fromGeneration).
+		Updates(map[string]interface{}{
+			"session_token":      session.SessionToken,
+			"refresh_generation": session.RefreshGeneration,
+			"ip_address":         session.IPAddress,
+			"user_agent":         session.UserAgent,
+			"expires_at":         session.ExpiresAt,
+			"last_accessed":      session.LastAccessed,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (r *sessionRepositoryImpl) Delete(ctx context.Context, token string) error {
 	if err := r.db.WithContext(ctx).Delete(&entities.Session{}, "session_token = ?", token).Error; err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
@@ -59,11 +95,24 @@ func (r *sessionRepositoryImpl) Delete(ctx context.Context, token string) error
 	return nil
 }
 
-func (r *sessionRepositoryImpl) DeleteByUserID(ctx context.Context, userID string) error {
-	if err := r.db.WithContext(ctx).Delete(&entities.Session{}, "user_id = ?", userID).Error; err != nil {
-		return fmt.Errorf("failed to delete sessions by user ID: %w", err)
+func (r *sessionRepositoryImpl) DeleteByID(ctx context.Context, userID, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&entities.Session{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete session: %w", result.Error)
 	}
-	return nil
+	return result.RowsAffected > 0, nil
+}
+
+func (r *sessionRepositoryImpl) DeleteByUserID(ctx context.Context, userID string, keepIDs ...string) (int64, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(keepIDs) > 0 {
+		query = query.Where("id NOT IN ?", keepIDs)
+	}
+	result := query.Delete(&entities.Session{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete sessions by user ID: %w", result.Error)
+	}
+	return result.RowsAffected, nil
 }
 
 func (r *sessionRepositoryImpl) DeleteExpired(ctx context.Context) error {
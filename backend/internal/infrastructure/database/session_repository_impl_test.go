@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func setupSessionTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE sessions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			session_token TEXT UNIQUE NOT NULL,
+			refresh_token TEXT UNIQUE NOT NULL,
+			refresh_generation INTEGER NOT NULL DEFAULT 0,
+			mfa BOOLEAN NOT NULL DEFAULT false,
+			ip_address TEXT,
+			user_agent TEXT,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME,
+			last_accessed DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create sessions table: %v", err)
+	}
+
+	return db
+}
+
+func createTestSession(t *testing.T, repo repositories.SessionRepository, id, userID string) *entities.Session {
+	session := &entities.Session{
+		ID:           id,
+		UserID:       userID,
+		SessionToken: "token-" + id,
+		RefreshToken: "secret-" + id,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		LastAccessed: time.Now(),
+	}
+	if err := repo.Create(context.Background(), session); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	return session
+}
+
+func TestSessionRepository_Rotate(t *testing.T) {
+	repo := NewSessionRepository(setupSessionTestDB(t))
+	ctx := context.Background()
+
+	session := createTestSession(t, repo, "session-1", "user-1")
+	session.SessionToken = "token-2"
+	session.RefreshGeneration = 1
+	session.IPAddress = "192.0.2.1"
+
+	rotated, err := repo.Rotate(ctx, session, 0)
+	if err != nil || !rotated {
+		t.Fatalf("expected rotation, got %v, %v", rotated, err)
+	}
+
+	// A second rotation from the same generation loses
+	rotated, err = repo.Rotate(ctx, session, 0)
+	if err != nil || rotated {
+		t.Fatalf("expected a stale rotation to be refused, got %v, %v", rotated, err)
+	}
+
+	stored, err := repo.GetByID(ctx, "session-1")
+	if err != nil || stored == nil {
+		t.Fatalf("expected session, got %v, %v", stored, err)
+	}
+	if stored.RefreshGeneration != 1 || stored.SessionToken != "token-2" || stored.IPAddress != "192.0.2.1" {
+		t.Errorf("unexpected session after rotation: %+v", stored)
+	}
+
+	// Touch only records activity
+	if err := repo.Touch(ctx, "session-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	stored, _ = repo.GetByID(ctx, "session-1")
+	if stored.RefreshGeneration != 1 {
+		t.Errorf("expected generation to be kept, got %d", stored.RefreshGeneration)
+	}
+
+	missing, err := repo.GetByID(ctx, "missing")
+	if err != nil || missing != nil {
+		t.Errorf("expected nil for a missing session, got %v, %v", missing, err)
+	}
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	repo := NewSessionRepository(setupSessionTestDB(t))
+	ctx := context.Background()
+
+	createTestSession(t, repo, "session-1", "user-1")
+	createTestSession(t, repo, "session-2", "user-1")
+	createTestSession(t, repo, "session-3", "user-1")
+	createTestSession(t, repo, "session-4", "user-2")
+
+	// Sessions can only be deleted by their owner
+	deleted, err := repo.DeleteByID(ctx, "user-2", "session-1")
+	if err != nil || deleted {
+		t.Fatalf("expected another user's session to be kept, got %v, %v", deleted, err)
+	}
+	deleted, err = repo.DeleteByID(ctx, "user-1", "session-1")
+	if err != nil || !deleted {
+		t.Fatalf("expected session to be deleted, got %v, %v", deleted, err)
+	}
+
+	count, err := repo.DeleteByUserID(ctx, "user-1", "session-2")
+	if err != nil || count != 1 {
+		t.Fatalf("expected one other session deleted, got %d, %v", count, err)
+	}
+	sessions, _ := repo.GetByUserID(ctx, "user-1")
+	if len(sessions) != 1 || sessions[0].ID != "session-2" {
+		t.Errorf("expected only the kept session to remain, got %d", len(sessions))
+	}
+
+	count, err = repo.DeleteByUserID(ctx, "user-1")
+	if err != nil || count != 1 {
+		t.Fatalf("expected remaining session deleted, got %d, %v", count, err)
+	}
+	sessions, _ = repo.GetByUserID(ctx, "user-2")
+	if len(sessions) != 1 {
+		t.Errorf("expected other users' sessions to be kept, got %d", len(sessions))
+	}
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/repositories"
+)
+
+// tenantScope restricts a query on a table with an organization_id column to
+// the organization ctx is scoped to
+func tenantScope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	organizationID, all := repositories.OrganizationFromContext(ctx)
+	return func(db *gorm.DB) *gorm.DB {
+		switch {
+		case all:
+			return db
+		case organizationID != "":
+			return db.Where(column+" = ?", organizationID)
+		default:
+			return db.Where(column + " IS NULL")
+		}
+	}
+}
+
+// checkTenant reports an error when a record of organizationID is written
+// through a context scoped to another organization
+func checkTenant(ctx context.Context, organizationID *string) error {
+	scoped, all := repositories.OrganizationFromContext(ctx)
+	if all {
+		return nil
+	}
+	actual := ""
+	if organizationID != nil {
+		actual = *organizationID
+	}
+	if actual != scoped {
+		return fmt.Errorf("record belongs to a different organization")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type uploadSessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUploadSessionRepository(db *gorm.DB) repositories.UploadSessionRepository {
+	return &uploadSessionRepositoryImpl{db: db}
+}
+
+func (r *uploadSessionRepositoryImpl) Create(ctx context.Context, session *entities.UploadSession) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return nil
+}
+
+func (r *uploadSessionRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.UploadSession, error) {
+	var session entities.UploadSession
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get upload session by ID: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *uploadSessionRepositoryImpl) Update(ctx context.Context, session *entities.UploadSession) error {
+	if err := r.db.WithContext(ctx).Save(session).Error; err != nil {
+		return fmt.Errorf("failed to update upload session: %w", err)
+	}
+	return nil
+}
+
+func (r *uploadSessionRepositoryImpl) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.UploadSession{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete upload session: %w", err)
+	}
+	return nil
+}
+
+func (r *uploadSessionRepositoryImpl) CountUnexpired(ctx context.Context, clientIP string, now time.Time) (int64, int64, error) {
+	var totals struct {
+		Uploads int64
+		Bytes   int64
+	}
+	if err := r.db.WithContext(ctx).Model(&entities.UploadSession{}).
+		Select("COUNT(*) AS uploads, COALESCE(SUM(length), 0) AS bytes").
+		Where("client_ip = ? AND (user_id IS NULL OR user_id = '') AND expires_at > ?", clientIP, now).
+		Scan(&totals).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to count upload sessions: %w", err)
+	}
+	return totals.Uploads, totals.Bytes, nil
+}
+
+func (r *uploadSessionRepositoryImpl) GetExpired(ctx context.Context, before time.Time, limit int) ([]*entities.UploadSession, error) {
+	var sessions []*entities.UploadSession
+	if err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get expired upload sessions: %w", err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupUploadSessionTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create table manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE upload_sessions (
+			id TEXT PRIMARY KEY,
+			purpose TEXT NOT NULL,
+			user_id TEXT,
+			document_id TEXT,
+			client_ip TEXT,
+			length INTEGER NOT NULL,
+			offset INTEGER NOT NULL DEFAULT 0,
+			metadata TEXT,
+			status TEXT NOT NULL DEFAULT 'uploading',
+			expires_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create upload_sessions table: %v", err)
+	}
+
+	return db
+}
+
+func TestUploadSessionRepository_RoundTrip(t *testing.T) {
+	db := setupUploadSessionTestDB(t)
+	repo := NewUploadSessionRepository(db)
+	ctx := context.Background()
+
+	session := &entities.UploadSession{
+		Purpose:   entities.UploadPurposeSign,
+		UserID:    testUserID,
+		Length:    1024,
+		Metadata:  map[string]string{"filename": "scan.pdf"},
+		Status:    entities.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, session); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	session.Offset = 512
+	if err := repo.Update(ctx, session); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	found, err := repo.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found == nil {
+		t.Fatal("expected upload session to be found")
+	}
+	if found.Offset != 512 {
+		t.Errorf("expected offset 512, got %d", found.Offset)
+	}
+	if found.Metadata["filename"] != "scan.pdf" {
+		t.Errorf("expected metadata to round-trip, got %v", found.Metadata)
+	}
+
+	if err := repo.Delete(ctx, session.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	found, err = repo.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found != nil {
+		t.Error("expected deleted upload session to be gone")
+	}
+}
+
+func TestUploadSessionRepository_GetExpired(t *testing.T) {
+	db := setupUploadSessionTestDB(t)
+	repo := NewUploadSessionRepository(db)
+	ctx := context.Background()
+
+	expired := &entities.UploadSession{
+		Purpose:   entities.UploadPurposeVerify,
+		Length:    10,
+		Status:    entities.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	active := &entities.UploadSession{
+		Purpose:   entities.UploadPurposeVerify,
+		Length:    10,
+		Status:    entities.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	for _, session := range []*entities.UploadSession{expired, active} {
+		if err := repo.Create(ctx, session); err != nil {
+			t.Fatalf("failed to create upload session: %v", err)
+		}
+	}
+
+	sessions, err := repo.GetExpired(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != expired.ID {
+		t.Errorf("expected only the expired session, got %d", len(sessions))
+	}
+}
+
+func TestUploadSessionRepository_CountUnexpired(t *testing.T) {
+	db := setupUploadSessionTestDB(t)
+	repo := NewUploadSessionRepository(db)
+	ctx := context.Background()
+
+	sessions := []*entities.UploadSession{
+		{Purpose: entities.UploadPurposeVerify, ClientIP: "203.0.113.7", Length: 100, ExpiresAt: time.Now().Add(time.Hour)},
+		{Purpose: entities.UploadPurposeVerify, ClientIP: "203.0.113.7", Length: 50, ExpiresAt: time.Now().Add(time.Hour)},
+		// Expired, signed-in and other clients' uploads do not count
+		{Purpose: entities.UploadPurposeVerify, ClientIP: "203.0.113.7", Length: 1000, ExpiresAt: time.Now().Add(-time.Minute)},
+		{Purpose: entities.UploadPurposeSign, UserID: testUserID, ClientIP: "203.0.113.7", Length: 1000, ExpiresAt: time.Now().Add(time.Hour)},
+		{Purpose: entities.UploadPurposeVerify, ClientIP: "198.51.100.1", Length: 1000, ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, session := range sessions {
+		session.Status = entities.UploadStatusUploading
+		if err := repo.Create(ctx, session); err != nil {
+			t.Fatalf("failed to create upload session: %v", err)
+		}
+	}
+
+	uploads, bytes, err := repo.CountUnexpired(ctx, "203.0.113.7", time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if uploads != 2 || bytes != 150 {
+		t.Errorf("expected 2 uploads of 150 bytes, got %d of %d bytes", uploads, bytes)
+	}
+}
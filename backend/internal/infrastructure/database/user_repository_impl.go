@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 
@@ -70,4 +71,51 @@ func (r *userRepositoryImpl) Delete(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *userRepositoryImpl) ListServiceAccounts(ctx context.Context) ([]*entities.User, error) {
+	var users []*entities.User
+	if err := r.db.WithContext(ctx).Where("service_account = ?", true).Order("username ASC").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return users, nil
+}
+
+func (r *userRepositoryImpl) ListByRole(ctx context.Context, role string) ([]*entities.User, error) {
+	var users []*entities.User
+	if err := r.db.WithContext(ctx).Where("role = ?", role).Order("username ASC").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users by role: %w", err)
+	}
+	return users, nil
+}
+
+func (r *userRepositoryImpl) Search(ctx context.Context, filter repositories.UserFilter) ([]*entities.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entities.User{})
+	if filter.Query != "" {
+		pattern := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(full_name) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query = query.Order("username ASC")
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	var users []*entities.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	return users, total, nil
+}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
@@ -29,7 +30,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			role TEXT DEFAULT 'user',
 			created_at DATETIME,
 			updated_at DATETIME,
-			is_active BOOLEAN DEFAULT true
+			is_active BOOLEAN DEFAULT true,
+			service_account BOOLEAN DEFAULT false
 		)
 	`).Error
 	if err != nil {
@@ -144,4 +146,86 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	if result.Email != "test@example.com" {
 		t.Errorf("Expected email 'test@example.com', got %s", result.Email)
 	}
-}
\ No newline at end of file
+}
+func TestUserRepository_ListServiceAccounts(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	for _, user := range []*entities.User{
+		{Username: "alice", FullName: "Alice", Email: "alice@example.com", PasswordHash: "hash", Role: "user", IsActive: true},
+		{Username: "sis-batch", FullName: "SIS batch job", Email: "sis-batch@service.local", Role: "user", IsActive: true, ServiceAccount: true},
+	} {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	accounts, err := repo.ListServiceAccounts(ctx)
+	if err != nil {
+		t.Fatalf("ListServiceAccounts() error = %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Username != "sis-batch" || !accounts[0].ServiceAccount {
+		t.Fatalf("expected only the service account, got %+v", accounts)
+	}
+}
+
+func TestUserRepository_ListByRole(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	for _, user := range []*entities.User{
+		{Username: "carol", FullName: "Carol", Email: "carol@example.com", PasswordHash: "hash", Role: "registrar", IsActive: true},
+		{Username: "alice", FullName: "Alice", Email: "alice@example.com", PasswordHash: "hash", Role: "registrar", IsActive: true},
+		{Username: "bob", FullName: "Bob", Email: "bob@example.com", PasswordHash: "hash", Role: "user", IsActive: true},
+	} {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	users, err := repo.ListByRole(ctx, "registrar")
+	if err != nil {
+		t.Fatalf("ListByRole() error = %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "alice" || users[1].Username != "carol" {
+		t.Fatalf("expected alice and carol, got %+v", users)
+	}
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	for _, user := range []*entities.User{
+		{Username: "carol", FullName: "Carol Smith", Email: "carol@faculty.example.edu", PasswordHash: "hash", Role: "registrar", IsActive: true},
+		{Username: "alice", FullName: "Alice Jones", Email: "alice@example.com", PasswordHash: "hash", Role: "user", IsActive: true},
+		{Username: "bob", FullName: "Bob Smith", Email: "bob@example.com", PasswordHash: "hash", Role: "user", IsActive: true},
+	} {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	db.Model(&entities.User{}).Where("username = ?", "bob").Update("is_active", false)
+
+	users, total, err := repo.Search(ctx, repositories.UserFilter{Query: "SMITH", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if total != 2 || len(users) != 2 || users[0].Username != "bob" || users[1].Username != "carol" {
+		t.Fatalf("expected bob and carol, got %d %+v", total, users)
+	}
+
+	active := true
+	users, total, err = repo.Search(ctx, repositories.UserFilter{Query: "smith", IsActive: &active, Page: 1, PageSize: 10})
+	if err != nil || total != 1 || users[0].Username != "carol" {
+		t.Fatalf("expected only carol, got %d %+v, %v", total, users, err)
+	}
+
+	users, total, err = repo.Search(ctx, repositories.UserFilter{Role: "user", Page: 2, PageSize: 1})
+	if err != nil || total != 2 || len(users) != 1 || users[0].Username != "bob" {
+		t.Fatalf("expected the second user page to hold bob, got %d %+v, %v", total, users, err)
+	}
+}
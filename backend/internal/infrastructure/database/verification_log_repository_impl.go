@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -27,7 +28,9 @@ func (r *verificationLogRepositoryImpl) Create(ctx context.Context, log *entitie
 
 func (r *verificationLogRepositoryImpl) GetByDocumentID(ctx context.Context, docID string) ([]*entities.VerificationLog, error) {
 	var logs []*entities.VerificationLog
-	if err := r.db.WithContext(ctx).Preload("Document").Where("document_id = ?", docID).Order("verified_at DESC").Find(&logs).Error; err != nil {
+	// Logs are scoped through the organization of their document
+	documents := r.db.Model(&entities.Document{}).Scopes(tenantScope(ctx, "organization_id")).Select("id")
+	if err := r.db.WithContext(ctx).Preload("Document").Where("document_id = ? AND document_id IN (?)", docID, documents).Order("verified_at DESC").Find(&logs).Error; err != nil {
 		return nil, fmt.Errorf("failed to get verification logs by document ID: %w", err)
 	}
 	return logs, nil
@@ -35,11 +38,101 @@ func (r *verificationLogRepositoryImpl) GetByDocumentID(ctx context.Context, doc
 
 func (r *verificationLogRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.VerificationLog, error) {
 	var log entities.VerificationLog
-	if err := r.db.WithContext(ctx).Preload("Document").Where("id = ?", id).First(&log).Error; err != nil {
+	documents := r.db.Model(&entities.Document{}).Scopes(tenantScope(ctx, "organization_id")).Select("id")
+	if err := r.db.WithContext(ctx).Preload("Document").Where("id = ? AND document_id IN (?)", id, documents).First(&log).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get verification log by ID: %w", err)
 	}
 	return &log, nil
-}
\ No newline at end of file
+}
+
+func (r *verificationLogRepositoryImpl) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("verified_at < ?", before).Delete(&entities.VerificationLog{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete old verification logs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// verificationGroupColumns are the key and label expressions of each grouping;
+// the day is computed by dialect
+var verificationGroupColumns = map[string][2]string{
+	repositories.VerificationGroupDocument: {"verification_logs.document_id", "COALESCE(MAX(documents.title), MAX(documents.filename))"},
+	repositories.VerificationGroupIssuer:   {"documents.issuer", "''"},
+	repositories.VerificationGroupCountry:  {"COALESCE(verification_logs.country, '')", "''"},
+}
+
+func (r *verificationLogRepositoryImpl) CountVerifications(ctx context.Context, filter repositories.VerificationStatsFilter, groupBy string) ([]repositories.VerificationStats, error) {
+	key, label := "''", "''"
+	switch groupBy {
+	case "":
+	case repositories.VerificationGroupDay:
+		key = "strftime('%Y-%m-%d', verification_logs.verified_at)"
+		if r.db.Dialector.Name() == "postgres" {
+			key = "to_char(verification_logs.verified_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+		}
+	default:
+		columns, ok := verificationGroupColumns[groupBy]
+		if !ok {
+			return nil, fmt.Errorf("unknown verification grouping %q", groupBy)
+		}
+		key, label = columns[0], columns[1]
+	}
+
+	// Logs are scoped through the organization of their document
+	query := r.db.WithContext(ctx).Table("verification_logs").
+		Joins("JOIN documents ON documents.id = verification_logs.document_id").
+		Scopes(tenantScope(ctx, "documents.organization_id")).
+		Select(fmt.Sprintf(`%s AS key, %s AS label, COUNT(*) AS total,
+			COALESCE(%s, 0) AS valid, COALESCE(%s, 0) AS content_changed,
+			COALESCE(%s, 0) AS invalid, COALESCE(%s, 0) AS error,
+			COUNT(DISTINCT verification_logs.verifier_ip) AS unique_verifiers`,
+			key, label, countResult("valid"), countResult("qr_valid_content_changed"),
+			countResult("invalid"), countResult("error")))
+	if filter.UserID != "" {
+		query = query.Where("documents.user_id = ? OR documents.signed_by_id = ?", filter.UserID, filter.UserID)
+	}
+	if filter.DocumentID != "" {
+		query = query.Where("verification_logs.document_id = ?", filter.DocumentID)
+	}
+	if filter.Issuer != "" {
+		query = query.Where("documents.issuer = ?", filter.Issuer)
+	}
+	if filter.From != nil {
+		query = query.Where("verification_logs.verified_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("verification_logs.verified_at < ?", *filter.To)
+	}
+
+	if groupBy != "" {
+		query = query.Group(key)
+		if groupBy == repositories.VerificationGroupDay {
+			query = query.Order("key ASC")
+		} else {
+			query = query.Order("total DESC, key ASC")
+		}
+	}
+	if filter.MinContentChanged > 0 {
+		query = query.Having(countResult("qr_valid_content_changed")+" >= ?", filter.MinContentChanged)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var stats []repositories.VerificationStats
+	if err := query.Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to count verifications: %w", err)
+	}
+	if groupBy == "" && len(stats) == 0 {
+		stats = append(stats, repositories.VerificationStats{})
+	}
+	return stats, nil
+}
+
+// countResult is the SQL counting the verifications with a result
+func countResult(result string) string {
+	return "SUM(CASE WHEN verification_logs.verification_result = '" + result + "' THEN 1 ELSE 0 END)"
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func setupVerificationLogTestDB(t *testing.T) *gorm.DB {
+	db := setupDocumentTestDB(t)
+
+	err := db.Exec(`
+		CREATE TABLE verification_logs (
+			id TEXT PRIMARY KEY,
+			document_id TEXT,
+			verification_result TEXT,
+			verified_at DATETIME,
+			verifier_ip TEXT,
+			country TEXT,
+			details TEXT
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create verification_logs table: %v", err)
+	}
+	return db
+}
+
+func TestVerificationLogRepository_CountVerifications(t *testing.T) {
+	db := setupVerificationLogTestDB(t)
+	docRepo := NewDocumentRepository(db)
+	repo := NewVerificationLogRepository(db)
+	orgID := uuid.New().String()
+	ctx := repositories.WithOrganization(context.Background(), orgID)
+
+	transcript := &entities.Document{UserID: testUserID, Filename: "transcript.pdf", Issuer: "Faculty A", Title: stringPtr("Transcript"), DocumentHash: "hash-1", SignatureData: "sig", QRCodeData: "qr", OrganizationID: &orgID}
+	letter := &entities.Document{UserID: "other-user", Filename: "letter.pdf", Issuer: "Faculty B", DocumentHash: "hash-2", SignatureData: "sig", QRCodeData: "qr", OrganizationID: &orgID}
+	personal := &entities.Document{UserID: testUserID, Filename: "personal.pdf", Issuer: "Faculty A", DocumentHash: "hash-3", SignatureData: "sig", QRCodeData: "qr"}
+	for _, doc := range []*entities.Document{transcript, letter} {
+		if err := docRepo.Create(ctx, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := docRepo.Create(context.Background(), personal); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	day1 := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	logs := []struct {
+		doc     *entities.Document
+		result  string
+		at      time.Time
+		ip      string
+		country string
+	}{
+		{transcript, "valid", day1, "1.1.1.1", "ID"},
+		{transcript, "valid", day1, "1.1.1.1", "ID"},
+		{transcript, "qr_valid_content_changed", day2, "2.2.2.2", "SG"},
+		{transcript, "qr_valid_content_changed", day2, "3.3.3.3", ""},
+		{letter, "invalid", day2, "4.4.4.4", "ID"},
+		{letter, "error", day2, "4.4.4.4", "ID"},
+		{personal, "valid", day2, "5.5.5.5", "ID"},
+	}
+	for _, l := range logs {
+		log := &entities.VerificationLog{ID: uuid.New().String(), DocumentID: l.doc.ID, VerificationResult: l.result, VerifiedAt: l.at, VerifierIP: l.ip, Country: l.country, Details: "{}"}
+		if err := repo.Create(ctx, log); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	count := func(filter repositories.VerificationStatsFilter, groupBy string) []repositories.VerificationStats {
+		t.Helper()
+		stats, err := repo.CountVerifications(ctx, filter, groupBy)
+		if err != nil {
+			t.Fatalf("CountVerifications(%q) error = %v", groupBy, err)
+		}
+		return stats
+	}
+	assertStats := func(got, want []repositories.VerificationStats) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	}
+
+	// Only the organization's documents are counted
+	assertStats(count(repositories.VerificationStatsFilter{}, ""), []repositories.VerificationStats{
+		{Total: 6, Valid: 2, ContentChanged: 2, Invalid: 1, Error: 1, UniqueVerifiers: 4},
+	})
+
+	assertStats(count(repositories.VerificationStatsFilter{}, repositories.VerificationGroupDocument), []repositories.VerificationStats{
+		{Key: transcript.ID, Label: "Transcript", Total: 4, Valid: 2, ContentChanged: 2, UniqueVerifiers: 3},
+		{Key: letter.ID, Label: "letter.pdf", Total: 2, Invalid: 1, Error: 1, UniqueVerifiers: 1},
+	})
+
+	assertStats(count(repositories.VerificationStatsFilter{}, repositories.VerificationGroupDay), []repositories.VerificationStats{
+		{Key: "2026-03-01", Total: 2, Valid: 2, UniqueVerifiers: 1},
+		{Key: "2026-03-02", Total: 4, ContentChanged: 2, Invalid: 1, Error: 1, UniqueVerifiers: 3},
+	})
+
+	assertStats(count(repositories.VerificationStatsFilter{UserID: testUserID}, repositories.VerificationGroupIssuer), []repositories.VerificationStats{
+		{Key: "Faculty A", Total: 4, Valid: 2, ContentChanged: 2, UniqueVerifiers: 3},
+	})
+
+	assertStats(count(repositories.VerificationStatsFilter{From: &day2}, repositories.VerificationGroupCountry), []repositories.VerificationStats{
+		{Key: "ID", Total: 2, Invalid: 1, Error: 1, UniqueVerifiers: 1},
+		{Key: "", Total: 1, ContentChanged: 1, UniqueVerifiers: 1},
+		{Key: "SG", Total: 1, ContentChanged: 1, UniqueVerifiers: 1},
+	})
+
+	// Documents with forged copies circulating
+	assertStats(count(repositories.VerificationStatsFilter{MinContentChanged: 2}, repositories.VerificationGroupDocument), []repositories.VerificationStats{
+		{Key: transcript.ID, Label: "Transcript", Total: 4, Valid: 2, ContentChanged: 2, UniqueVerifiers: 3},
+	})
+
+	assertStats(count(repositories.VerificationStatsFilter{DocumentID: letter.ID, To: &day2}, ""), []repositories.VerificationStats{{}})
+
+	if _, err := repo.CountVerifications(ctx, repositories.VerificationStatsFilter{}, "hour"); err == nil {
+		t.Fatal("expected an unknown grouping to fail")
+	}
+}
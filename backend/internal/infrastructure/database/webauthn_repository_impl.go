@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type webAuthnRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) repositories.WebAuthnRepository {
+	return &webAuthnRepositoryImpl{db: db}
+}
+
+func (r *webAuthnRepositoryImpl) CreateCredential(ctx context.Context, credential *entities.WebAuthnCredential) error {
+	if err := r.db.WithContext(ctx).Create(credential).Error; err != nil {
+		return fmt.Errorf("failed to create WebAuthn credential: %w", err)
+	}
+	return nil
+}
+
+func (r *webAuthnRepositoryImpl) ListCredentials(ctx context.Context, userID string) ([]*entities.WebAuthnCredential, error) {
+	var credentials []*entities.WebAuthnCredential
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("failed to list WebAuthn credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+func (r *webAuthnRepositoryImpl) CountCredentials(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&entities.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count WebAuthn credentials: %w", err)
+	}
+	return count, nil
+}
+
+func (r *webAuthnRepositoryImpl) DeleteCredential(ctx context.Context, userID, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&entities.WebAuthnCredential{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete WebAuthn credential: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *webAuthnRepositoryImpl) UpdateCredentialUse(ctx context.Context, credential *entities.WebAuthnCredential, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&entities.WebAuthnCredential{}).
+		Where("id = ?", credential.ID).
+		Updates(map[string]interface{}{
+			"sign_count":   credential.SignCount,
+			"backup_state": credential.BackupState,
+			"last_used_at": usedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update WebAuthn credential: %w", err)
+	}
+	credential.LastUsedAt = &usedAt
+	return nil
+}
+
+func (r *webAuthnRepositoryImpl) CreateChallenge(ctx context.Context, challenge *entities.WebAuthnChallenge) error {
+	if err := r.db.WithContext(ctx).Create(challenge).Error; err != nil {
+		return fmt.Errorf("failed to create WebAuthn challenge: %w", err)
+	}
+	return nil
+}
+
+func (r *webAuthnRepositoryImpl) ConsumeChallenge(ctx context.Context, userID, id, purpose string, now time.Time) (*entities.WebAuthnChallenge, error) {
+	var challenge entities.WebAuthnChallenge
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ? AND purpose = ?", id, userID, purpose).First(&challenge).Error; err != nil {
+			return err
+		}
+		// The delete decides which of two concurrent requests gets the challenge
+		result := tx.Delete(&entities.WebAuthnChallenge{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume WebAuthn challenge: %w", err)
+	}
+	if !challenge.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &challenge, nil
+}
+
+func (r *webAuthnRepositoryImpl) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&entities.WebAuthnChallenge{}, "expires_at <= ?", now)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired WebAuthn challenges: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+)
+
+func setupWebAuthnTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE webauthn_credentials (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			credential_id TEXT NOT NULL UNIQUE,
+			public_key BLOB NOT NULL,
+			attestation_type TEXT,
+			transports TEXT,
+			aaguid TEXT,
+			sign_count INTEGER NOT NULL DEFAULT 0,
+			backup_eligible BOOLEAN NOT NULL DEFAULT false,
+			backup_state BOOLEAN NOT NULL DEFAULT false,
+			name TEXT NOT NULL,
+			last_used_at DATETIME,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create webauthn_credentials table: %v", err)
+	}
+	err = db.Exec(`
+		CREATE TABLE webauthn_challenges (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			purpose TEXT NOT NULL,
+			session_data TEXT NOT NULL,
+			nonce TEXT,
+			document_hash TEXT,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create webauthn_challenges table: %v", err)
+	}
+
+	return db
+}
+
+func TestWebAuthnRepository_Credentials(t *testing.T) {
+	repo := NewWebAuthnRepository(setupWebAuthnTestDB(t))
+	ctx := context.Background()
+
+	credential := &entities.WebAuthnCredential{UserID: "user-1", CredentialID: "cred-1", PublicKey: []byte{1}, Name: "Key"}
+	if err := repo.CreateCredential(ctx, credential); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.CreateCredential(ctx, &entities.WebAuthnCredential{UserID: "user-2", CredentialID: "cred-1", PublicKey: []byte{1}, Name: "Copy"}); err == nil {
+		t.Fatal("expected a duplicate credential id to be refused")
+	}
+
+	credential.SignCount = 7
+	if err := repo.UpdateCredentialUse(ctx, credential, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	credentials, err := repo.ListCredentials(ctx, "user-1")
+	if err != nil || len(credentials) != 1 || credentials[0].SignCount != 7 || credentials[0].LastUsedAt == nil {
+		t.Fatalf("expected the stored use to be returned, got %+v (%v)", credentials, err)
+	}
+
+	if deleted, err := repo.DeleteCredential(ctx, "user-2", credential.ID); err != nil || deleted {
+		t.Fatalf("expected another user's delete to be refused, got %v (%v)", deleted, err)
+	}
+	if deleted, err := repo.DeleteCredential(ctx, "user-1", credential.ID); err != nil || !deleted {
+		t.Fatalf("expected the credential to be deleted, got %v (%v)", deleted, err)
+	}
+	if count, err := repo.CountCredentials(ctx, "user-1"); err != nil || count != 0 {
+		t.Fatalf("expected no credentials, got %d (%v)", count, err)
+	}
+}
+
+func TestWebAuthnRepository_ConsumeChallenge(t *testing.T) {
+	repo := NewWebAuthnRepository(setupWebAuthnTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	challenge := &entities.WebAuthnChallenge{UserID: "user-1", Purpose: entities.WebAuthnPurposeSigning, SessionData: "{}", ExpiresAt: now.Add(time.Minute)}
+	if err := repo.CreateChallenge(ctx, challenge); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got, err := repo.ConsumeChallenge(ctx, "user-1", challenge.ID, entities.WebAuthnPurposeRegistration, now); err != nil || got != nil {
+		t.Fatalf("expected a challenge for another purpose to be ignored, got %+v (%v)", got, err)
+	}
+	if got, err := repo.ConsumeChallenge(ctx, "user-2", challenge.ID, entities.WebAuthnPurposeSigning, now); err != nil || got != nil {
+		t.Fatalf("expected another user's challenge to be ignored, got %+v (%v)", got, err)
+	}
+	if got, err := repo.ConsumeChallenge(ctx, "user-1", challenge.ID, entities.WebAuthnPurposeSigning, now); err != nil || got == nil {
+		t.Fatalf("expected the challenge, got %+v (%v)", got, err)
+	}
+	if got, err := repo.ConsumeChallenge(ctx, "user-1", challenge.ID, entities.WebAuthnPurposeSigning, now); err != nil || got != nil {
+		t.Fatalf("expected a consumed challenge to be gone, got %+v (%v)", got, err)
+	}
+
+	expired := &entities.WebAuthnChallenge{UserID: "user-1", Purpose: entities.WebAuthnPurposeSigning, SessionData: "{}", ExpiresAt: now.Add(-time.Minute)}
+	if err := repo.CreateChallenge(ctx, expired); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deleted, err := repo.DeleteExpiredChallenges(ctx, now); err != nil || deleted != 1 {
+		t.Fatalf("expected one expired challenge deleted, got %d (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+type webhookSubscriptionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebhookSubscriptionRepository(db *gorm.DB) repositories.WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepositoryImpl{db: db}
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Create(ctx context.Context, sub *entities.WebhookSubscription) error {
+	if err := r.db.WithContext(ctx).Create(sub).Error; err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.WebhookSubscription, error) {
+	var sub entities.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&sub).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription by ID: %w", err)
+	}
+	return &sub, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) GetByUserID(ctx context.Context, userID string) ([]*entities.WebhookSubscription, error) {
+	var subs []*entities.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND organization_id IS NULL", userID).Order("created_at ASC").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions by user ID: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) GetByOrganizationID(ctx context.Context, organizationID string) ([]*entities.WebhookSubscription, error) {
+	var subs []*entities.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Order("created_at ASC").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions by organization ID: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Update(ctx context.Context, sub *entities.WebhookSubscription) error {
+	if err := r.db.WithContext(ctx).Save(sub).Error; err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Delete(&entities.WebhookSubscription{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+type webhookDeliveryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) repositories.WebhookDeliveryRepository {
+	return &webhookDeliveryRepositoryImpl{db: db}
+}
+
+func (r *webhookDeliveryRepositoryImpl) Create(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.WebhookDelivery, error) {
+	var delivery entities.WebhookDelivery
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery by ID: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) List(ctx context.Context, filter repositories.WebhookDeliveryFilter) ([]*entities.WebhookDelivery, error) {
+	var deliveries []*entities.WebhookDelivery
+	query := r.db.WithContext(ctx).Model(&entities.WebhookDelivery{})
+
+	switch {
+	case filter.OrganizationID != "":
+		query = query.Where("organization_id = ?", filter.OrganizationID)
+	case filter.UserID != "":
+		query = query.Where("user_id = ? AND organization_id IS NULL", filter.UserID)
+	}
+	if filter.SubscriptionID != "" {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) Update(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) ClaimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]*entities.WebhookDelivery, error) {
+	var claimed []*entities.WebhookDelivery
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entities.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return err
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		// Pushing next_attempt_at forward acts as a lease; a crashed dispatcher's rows become due again
+		ids := make([]string, len(claimed))
+		for i, delivery := range claimed {
+			ids[i] = delivery.ID
+			delivery.NextAttemptAt = leaseUntil
+		}
+		return tx.Model(&entities.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return claimed, nil
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func setupWebhookTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	// Create tables manually for SQLite compatibility
+	err = db.Exec(`
+		CREATE TABLE webhook_subscriptions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events TEXT,
+			description TEXT,
+			is_active BOOLEAN DEFAULT true,
+			created_at DATETIME,
+			updated_at DATETIME,
+			organization_id TEXT
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create webhook_subscriptions table: %v", err)
+	}
+
+	err = db.Exec(`
+		CREATE TABLE webhook_deliveries (
+			id TEXT PRIMARY KEY,
+			subscription_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER DEFAULT 0,
+			max_attempts INTEGER DEFAULT 0,
+			next_attempt_at DATETIME,
+			last_attempt_at DATETIME,
+			response_status INTEGER,
+			response_body TEXT,
+			duration_ms INTEGER,
+			error TEXT,
+			created_at DATETIME,
+			updated_at DATETIME,
+			delivered_at DATETIME,
+			organization_id TEXT
+		)
+	`).Error
+	if err != nil {
+		t.Fatalf("failed to create webhook_deliveries table: %v", err)
+	}
+
+	return db
+}
+
+func TestWebhookSubscriptionRepository_EventsRoundTrip(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	repo := NewWebhookSubscriptionRepository(db)
+	ctx := context.Background()
+
+	sub := &entities.WebhookSubscription{
+		UserID:   testUserID,
+		URL:      "https://records.example.com/hooks",
+		Secret:   "whsec_test",
+		Events:   []string{entities.WebhookEventDocumentSigned, entities.WebhookEventDocumentRevoked},
+		IsActive: true,
+	}
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	subs, err := repo.GetByUserID(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(subs) != 1 {
+		t.Fatalf("expected 1 subscription, got %d", len(subs))
+	}
+	if len(subs[0].Events) != 2 || !subs[0].Subscribes(entities.WebhookEventDocumentRevoked) {
+		t.Errorf("expected events to round-trip, got %v", subs[0].Events)
+	}
+	if subs[0].Subscribes(entities.WebhookEventDocumentVerified) {
+		t.Error("expected subscription not to match unfiltered event")
+	}
+}
+
+func TestWebhookRepositories_OrganizationScope(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	subRepo := NewWebhookSubscriptionRepository(db)
+	deliveryRepo := NewWebhookDeliveryRepository(db)
+	ctx := context.Background()
+	orgID := "org-1"
+
+	own := &entities.WebhookSubscription{UserID: testUserID, URL: "https://records.example.com/own", Secret: "s1", IsActive: true}
+	org := &entities.WebhookSubscription{UserID: testUserID, OrganizationID: &orgID, URL: "https://records.example.com/org", Secret: "s2", IsActive: true}
+	for _, sub := range []*entities.WebhookSubscription{own, org} {
+		if err := subRepo.Create(ctx, sub); err != nil {
+			t.Fatalf("failed to create subscription: %v", err)
+		}
+	}
+
+	// The organization's subscription is not its creator's own
+	subs, err := subRepo.GetByUserID(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(subs) != 1 || subs[0].ID != own.ID {
+		t.Fatalf("expected only the user's own subscription, got %d", len(subs))
+	}
+	subs, err = subRepo.GetByOrganizationID(ctx, orgID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(subs) != 1 || subs[0].ID != org.ID {
+		t.Fatalf("expected only the organization's subscription, got %d", len(subs))
+	}
+
+	for _, sub := range []*entities.WebhookSubscription{own, org} {
+		delivery := &entities.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			UserID:         testUserID,
+			OrganizationID: sub.OrganizationID,
+			EventID:        "event-1",
+			EventType:      entities.WebhookEventDocumentSigned,
+			Payload:        "{}",
+			Status:         entities.WebhookDeliveryPending,
+		}
+		if err := deliveryRepo.Create(ctx, delivery); err != nil {
+			t.Fatalf("failed to create delivery: %v", err)
+		}
+	}
+
+	deliveries, err := deliveryRepo.List(ctx, repositories.WebhookDeliveryFilter{UserID: testUserID})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].SubscriptionID != own.ID {
+		t.Errorf("expected only the user's own delivery, got %d", len(deliveries))
+	}
+	deliveries, err = deliveryRepo.List(ctx, repositories.WebhookDeliveryFilter{UserID: testUserID, OrganizationID: orgID})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].SubscriptionID != org.ID {
+		t.Errorf("expected only the organization's delivery, got %d", len(deliveries))
+	}
+}
+
+func TestWebhookDeliveryRepository_ClaimDue(t *testing.T) {
+	db := setupWebhookTestDB(t)
+	repo := NewWebhookDeliveryRepository(db)
+	ctx := context.Background()
+
+	due := &entities.WebhookDelivery{
+		SubscriptionID: "sub-1",
+		UserID:         testUserID,
+		EventID:        "event-1",
+		EventType:      entities.WebhookEventDocumentSigned,
+		Payload:        "{}",
+		Status:         entities.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now().Add(-time.Second),
+	}
+	later := &entities.WebhookDelivery{
+		SubscriptionID: "sub-1",
+		UserID:         testUserID,
+		EventID:        "event-2",
+		EventType:      entities.WebhookEventDocumentSigned,
+		Payload:        "{}",
+		Status:         entities.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now().Add(time.Hour),
+	}
+	for _, delivery := range []*entities.WebhookDelivery{due, later} {
+		if err := repo.Create(ctx, delivery); err != nil {
+			t.Fatalf("failed to create delivery: %v", err)
+		}
+	}
+
+	leaseUntil := time.Now().Add(time.Minute)
+	claimed, err := repo.ClaimDue(ctx, 10, leaseUntil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != due.ID {
+		t.Fatalf("expected only the due delivery to be claimed, got %d", len(claimed))
+	}
+
+	// The lease hides the claimed delivery from other dispatchers
+	claimed, err = repo.ClaimDue(ctx, 10, leaseUntil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("expected leased delivery to be skipped, got %d", len(claimed))
+	}
+
+	dead, err := repo.List(ctx, repositories.WebhookDeliveryFilter{UserID: testUserID, Status: entities.WebhookDeliveryDead})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(dead) != 0 {
+		t.Errorf("expected no dead deliveries, got %d", len(dead))
+	}
+}
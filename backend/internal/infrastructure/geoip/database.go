@@ -0,0 +1,156 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Database maps IP address ranges to ISO 3166 country codes. It is loaded
+// from a CSV file in the free DB-IP country format, one "start,end,country"
+// range per line; lines holding a network and a country ("10.0.0.0/8,ZZ")
+// are accepted too. IPv4 and IPv6 ranges may be mixed.
+type Database struct {
+	ranges []addressRange
+}
+
+type addressRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// Open loads the database file at path
+func Open(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	defer file.Close()
+
+	db, err := Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Load reads a database in CSV form. A header line is skipped.
+func Load(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &Database{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ipRange, err := parseRange(record)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ipRange.country != "" {
+			db.ranges = append(db.ranges, ipRange)
+		}
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+func parseRange(record []string) (addressRange, error) {
+	var ipRange addressRange
+	switch len(record) {
+	case 2:
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange, fmt.Errorf("invalid network %q", record[0])
+		}
+		prefix = prefix.Masked()
+		ipRange.start = prefix.Addr().Unmap()
+		ipRange.end = lastAddress(prefix)
+	case 3:
+		start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange, fmt.Errorf("invalid start address %q", record[0])
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return ipRange, fmt.Errorf("invalid end address %q", record[1])
+		}
+		ipRange.start, ipRange.end = start.Unmap(), end.Unmap()
+		if ipRange.end.Less(ipRange.start) || ipRange.start.Is4() != ipRange.end.Is4() {
+			return ipRange, fmt.Errorf("invalid range %s-%s", start, end)
+		}
+	default:
+		return ipRange, fmt.Errorf("expected start,end,country or network,country")
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(record[len(record)-1]))
+	// ZZ marks unassigned and private ranges
+	if country != "ZZ" {
+		ipRange.country = country
+	}
+	return ipRange, nil
+}
+
+// lastAddress returns the highest address of a network
+func lastAddress(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Addr().Unmap()
+	bytes := addr.AsSlice()
+	bits := prefix.Bits()
+	if prefix.Addr().Is4In6() {
+		bits -= 96
+	}
+	for i := range bytes {
+		hostBits := len(bytes)*8 - bits - (len(bytes)-1-i)*8
+		switch {
+		case hostBits >= 8:
+			bytes[i] = 0xff
+		case hostBits > 0:
+			bytes[i] |= byte(1<<hostBits - 1)
+		}
+	}
+	last, _ := netip.AddrFromSlice(bytes)
+	return last
+}
+
+// Country returns the country code of ip, or "" when it is not a valid
+// address or no range holds it
+func (d *Database) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || d == nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// The last range starting at or before addr is the only one that can hold it
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return addr.Less(d.ranges[i].start)
+	}) - 1
+	if i < 0 || d.ranges[i].end.Less(addr) {
+		return ""
+	}
+	return d.ranges[i].country
+}
+
+// Len returns the number of ranges loaded
+func (d *Database) Len() int {
+	return len(d.ranges)
+}
@@ -0,0 +1,69 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDatabase = `ip_start,ip_end,country
+1.0.0.0,1.0.0.255,AU
+36.64.0.0,36.95.255.255,id
+10.0.0.0,10.255.255.255,ZZ
+2001:200::,2001:200:ffff:ffff:ffff:ffff:ffff:ffff,JP
+81.2.69.0/24,GB
+2a02:ff0::/32,DE
+`
+
+func TestLoad_Country(t *testing.T) {
+	db, err := Load(strings.NewReader(testDatabase))
+	require.NoError(t, err)
+	assert.Equal(t, 5, db.Len())
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"1.0.0.0", "AU"},
+		{"1.0.0.255", "AU"},
+		{"1.0.1.0", ""},
+		{"36.80.12.7", "ID"},
+		{"::ffff:36.80.12.7", "ID"},
+		{"10.1.2.3", ""},
+		{"81.2.69.160", "GB"},
+		{"81.2.70.1", ""},
+		{"2001:200:1::1", "JP"},
+		{"2a02:ff0:ffff:ffff::1", "DE"},
+		{"2a02:ff1::1", ""},
+		{"0.0.0.1", ""},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, db.Country(tt.ip), tt.ip)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(strings.NewReader("1.0.0.0,1.0.0.255,AU\n1.0.1.0,nope,AU\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = Load(strings.NewReader("1.0.0.0,1.0.0.255,AU\n1.0.1.255,1.0.1.0,AU\n"))
+	assert.ErrorContains(t, err, "invalid range")
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dbip-country-lite.csv")
+	require.NoError(t, os.WriteFile(path, []byte(testDatabase), 0o600))
+
+	db, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, "AU", db.Country("1.0.0.1"))
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// AdminHandler exposes administrative operations; routes require the admin role
+type AdminHandler struct {
+	loginGuard *services.LoginGuard
+	scheduler  *services.Scheduler
+	validator  *validation.Validator
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(loginGuard *services.LoginGuard, scheduler *services.Scheduler) *AdminHandler {
+	return &AdminHandler{
+		loginGuard: loginGuard,
+		scheduler:  scheduler,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// ListLockouts handles GET /api/admin/lockouts
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	lockouts, err := h.loginGuard.ListLocked(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lockouts": lockouts})
+}
+
+// UnlockUser handles POST /api/admin/users/:userId/unlock
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+		return
+	}
+
+	user, err := h.loginGuard.UnlockUser(c.Request.Context(), userID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.auditUnlock(c, user.ID, user.Username, map[string]interface{}{
+		"scope": "username",
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked successfully"})
+}
+
+// DeleteLockout handles DELETE /api/admin/lockouts/:lockoutId and lifts an account or address lockout
+func (h *AdminHandler) DeleteLockout(c *gin.Context) {
+	lockoutID := c.Param("lockoutId")
+	if _, validationErr := h.validator.ValidateUUID("lockout_id", lockoutID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid lockout ID", validationErr.Error())
+		return
+	}
+
+	throttle, err := h.loginGuard.Unlock(c.Request.Context(), lockoutID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.auditUnlock(c, "", "", map[string]interface{}{
+		"lockout_id": throttle.ID,
+		"scope":      throttle.Scope,
+		"key":        throttle.Key,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout removed successfully"})
+}
+
+// ListMaintenanceTasks handles GET /api/admin/maintenance/tasks
+func (h *AdminHandler) ListMaintenanceTasks(c *gin.Context) {
+	tasks, err := h.scheduler.Tasks(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// ListMaintenanceRuns handles GET /api/admin/maintenance/runs
+func (h *AdminHandler) ListMaintenanceRuns(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			RespondWithValidationError(c, "Invalid limit parameter", "limit must be between 1 and 200")
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			RespondWithValidationError(c, "Invalid offset parameter", "offset must be zero or greater")
+			return
+		}
+		offset = parsed
+	}
+
+	runs, total, err := h.scheduler.ListRuns(c.Request.Context(), c.Query("task"), limit, offset)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":   runs,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// RunMaintenanceTask handles POST /api/admin/maintenance/tasks/:task/run
+func (h *AdminHandler) RunMaintenanceTask(c *gin.Context) {
+	run, err := h.scheduler.RunNow(c.Request.Context(), c.Param("task"))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+	logging.LogResourceOperation(
+		logging.AuditEventMaintenanceRun,
+		authUser.ID,
+		authUser.Username,
+		run.Task,
+		c.ClientIP(),
+		run.Status,
+		map[string]interface{}{
+			"run_id":      run.ID,
+			"duration_ms": run.DurationMs,
+			"error":       run.Error,
+		},
+	)
+
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
+
+func (h *AdminHandler) auditUnlock(c *gin.Context, userID, username string, details map[string]interface{}) {
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+	details["admin_id"] = authUser.ID
+	details["admin_username"] = authUser.Username
+	details["endpoint"] = c.FullPath()
+
+	logging.LogAuthentication(
+		logging.AuditEventAccountUnlock,
+		userID,
+		username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		details,
+	)
+}
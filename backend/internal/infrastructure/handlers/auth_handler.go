@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -21,11 +24,19 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginMFARequest is the second step of a login for users with two-factor authentication
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required,min=8"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	// InvitationToken is required while registration is invite-only
+	InvitationToken string `json:"invitation_token,omitempty"`
 }
 
 type ChangePasswordRequest struct {
@@ -33,6 +44,13 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
 
+// LoginErrorResponse adds the throttle state a login form needs to a StandardError
+type LoginErrorResponse struct {
+	*StandardError
+	CaptchaRequired bool       `json:"captcha_required"`
+	RetryAt         *time.Time `json:"retry_at,omitempty"`
+}
+
 // Remove the old ErrorResponse struct as we now use StandardError from errors.go
 
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
@@ -69,8 +87,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	loginReq := services.LoginRequest{
-		Username: sanitizedUsername,
-		Password: req.Password,
+		Username:  sanitizedUsername,
+		Password:  req.Password,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
 	}
 
 	response, err := h.authService.Login(c.Request.Context(), loginReq)
@@ -88,7 +108,24 @@ func (h *AuthHandler) Login(c *gin.Context) {
 				"endpoint": "/api/auth/login",
 			},
 		)
-		MapServiceErrorToHTTP(c, err)
+		h.respondWithLoginError(c, sanitizedUsername, err)
+		return
+	}
+
+	// The password was right but a second factor is still needed
+	if response.MFARequired {
+		logging.LogAuthentication(
+			logging.AuditEventMFAChallenge,
+			"",
+			sanitizedUsername,
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"PENDING",
+			map[string]interface{}{
+				"endpoint": "/api/auth/login",
+			},
+		)
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
@@ -108,6 +145,155 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// LoginMFA handles the second step of a two-factor login
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code == "" || len(code) > 32 {
+		RespondWithValidationError(c, "Invalid code")
+		return
+	}
+
+	response, err := h.authService.CompleteMFALogin(c.Request.Context(), services.MFALoginRequest{
+		MFAToken:  req.MFAToken,
+		Code:      code,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		logging.LogAuthentication(
+			logging.AuditEventAuthFailure,
+			"",
+			"",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"FAILURE",
+			map[string]interface{}{
+				"error":    err.Error(),
+				"endpoint": "/api/auth/login/mfa",
+			},
+		)
+		h.respondWithLoginError(c, "", err)
+		return
+	}
+
+	logging.LogAuthentication(
+		logging.AuditEventLogin,
+		response.User.ID,
+		response.User.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		map[string]interface{}{
+			"endpoint": "/api/auth/login/mfa",
+			"mfa":      true,
+		},
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req services.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.GetHeader("User-Agent")
+
+	response, err := h.authService.Refresh(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			// Someone replayed a rotated token; the session has been revoked
+			logging.LogSecurityEvent(
+				logging.AuditEventSuspiciousActivity,
+				c.ClientIP(),
+				c.GetHeader("User-Agent"),
+				"Refresh token reuse detected; session revoked",
+				map[string]interface{}{
+					"endpoint": "/api/auth/refresh",
+				},
+			)
+		} else {
+			logging.LogAuthentication(
+				logging.AuditEventAuthFailure,
+				"",
+				"",
+				c.ClientIP(),
+				c.GetHeader("User-Agent"),
+				"FAILURE",
+				map[string]interface{}{
+					"error":    err.Error(),
+					"endpoint": "/api/auth/refresh",
+				},
+			)
+		}
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithLoginError audits any lockout the attempt caused and tells the client
+// when it may retry and whether a CAPTCHA is now required
+func (h *AuthHandler) respondWithLoginError(c *gin.Context, username string, err error) {
+	var attemptErr *services.LoginAttemptError
+	if !errors.As(err, &attemptErr) {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	for _, throttle := range attemptErr.Locked {
+		logging.LogAuthentication(
+			logging.AuditEventAccountLocked,
+			"",
+			username,
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"LOCKED",
+			map[string]interface{}{
+				"scope":        throttle.Scope,
+				"key":          throttle.Key,
+				"failures":     throttle.Failures,
+				"locked_until": throttle.LockedUntil,
+				"endpoint":     "/api/auth/login",
+			},
+		)
+	}
+
+	response := LoginErrorResponse{CaptchaRequired: attemptErr.CaptchaRequired}
+	if !attemptErr.RetryAt.IsZero() {
+		response.RetryAt = &attemptErr.RetryAt
+		if wait := time.Until(attemptErr.RetryAt); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		}
+	}
+
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(err, services.ErrAccountLocked):
+		status = http.StatusLocked
+		response.StandardError = NewStandardError(ErrCodeAccountLocked, "Account temporarily locked due to too many failed login attempts")
+	case errors.Is(err, services.ErrLoginThrottled):
+		status = http.StatusTooManyRequests
+		response.StandardError = NewStandardError(ErrCodeRateLimitExceeded, "Too many failed login attempts, please try again later")
+	case errors.Is(err, services.ErrInvalidMFACode):
+		response.StandardError = NewStandardError(ErrCodeInvalidMFACode, "Invalid two-factor authentication code")
+	default:
+		response.StandardError = NewStandardError(ErrCodeUnauthorized, "Invalid username or password")
+	}
+
+	c.JSON(status, response)
+}
+
 // Register handles user registration
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
@@ -146,6 +332,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Password: req.Password, // Don't sanitize password
 		FullName: sanitizedFullName,
 		Email:    sanitizedEmail,
+
+		InvitationToken: strings.TrimSpace(req.InvitationToken),
 	}
 
 	user, err := h.authService.Register(c.Request.Context(), registerReq)
@@ -188,6 +376,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	})
 }
 
+// GetRegistration handles GET /api/auth/registration and tells clients whether
+// signup is open, invite-only or closed
+func (h *AuthHandler) GetRegistration(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"mode": h.authService.RegistrationMode()})
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	token := extractTokenFromHeader(c)
@@ -293,7 +487,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.ChangePassword(c.Request.Context(), user.ID, req.OldPassword, req.NewPassword); err != nil {
+	if err := h.authService.ChangePassword(c.Request.Context(), user.ID, req.OldPassword, req.NewPassword, user.SessionID); err != nil {
 		// Log failed password change attempt
 		logging.LogAuthentication(
 			logging.AuditEventPasswordChange,
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,8 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			role TEXT DEFAULT 'user',
 			created_at DATETIME,
 			updated_at DATETIME,
-			is_active BOOLEAN DEFAULT true
+			is_active BOOLEAN DEFAULT true,
+			service_account BOOLEAN DEFAULT false
 		)
 	`).Error
 	require.NoError(t, err)
@@ -45,6 +47,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			user_id TEXT NOT NULL,
 			session_token TEXT UNIQUE NOT NULL,
 			refresh_token TEXT UNIQUE NOT NULL,
+			refresh_generation INTEGER NOT NULL DEFAULT 0,
+			mfa BOOLEAN NOT NULL DEFAULT false,
+			ip_address TEXT,
+			user_agent TEXT,
 			expires_at DATETIME NOT NULL,
 			created_at DATETIME,
 			last_accessed DATETIME,
@@ -53,6 +59,123 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	`).Error
 	require.NoError(t, err)
 
+	err = db.Exec(`
+		CREATE TABLE login_throttles (
+			id TEXT PRIMARY KEY,
+			scope TEXT NOT NULL,
+			key TEXT NOT NULL,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_failure_at DATETIME,
+			locked_until DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (scope, key)
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE user_mfa (
+			user_id TEXT PRIMARY KEY,
+			secret TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT false,
+			last_used_step INTEGER NOT NULL DEFAULT 0,
+			enabled_at DATETIME,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE mfa_recovery_codes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			used_at DATETIME,
+			created_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE webauthn_credentials (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			credential_id TEXT NOT NULL UNIQUE,
+			public_key BLOB NOT NULL,
+			attestation_type TEXT,
+			transports TEXT,
+			aaguid TEXT,
+			sign_count INTEGER NOT NULL DEFAULT 0,
+			backup_eligible BOOLEAN NOT NULL DEFAULT false,
+			backup_state BOOLEAN NOT NULL DEFAULT false,
+			name TEXT NOT NULL,
+			last_used_at DATETIME,
+			created_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE webauthn_challenges (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			purpose TEXT NOT NULL,
+			session_data TEXT NOT NULL,
+			nonce TEXT,
+			document_hash TEXT,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE roles (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			permissions TEXT,
+			built_in BOOLEAN DEFAULT false,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE organizations (
+			id TEXT PRIMARY KEY,
+			slug TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			issuer_name TEXT,
+			verification_base_url TEXT,
+			stamp_label TEXT,
+			stamp_x REAL,
+			stamp_y REAL,
+			stamp_width REAL,
+			verification_redactions TEXT,
+			is_active BOOLEAN DEFAULT true,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error
+	require.NoError(t, err)
+
+	err = db.Exec(`
+		CREATE TABLE organization_members (
+			id TEXT PRIMARY KEY,
+			organization_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'member',
+			created_at DATETIME,
+			updated_at DATETIME,
+			UNIQUE (organization_id, user_id)
+		)
+	`).Error
+	require.NoError(t, err)
+
 	return db
 }
 
@@ -235,6 +358,79 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_Lockout(t *testing.T) {
+	db := setupTestDB(t)
+	server := NewServer(&config.Config{
+		JWTSecret:            "test-secret-key",
+		Environment:          "test",
+		LoginMaxFailures:     3,
+		LoginFailureWindow:   15 * time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+		LoginCaptchaAfter:    2,
+	}, db)
+
+	userRepo := database.NewUserRepository(db)
+	authService := services.NewAuthService(userRepo, database.NewSessionRepository(db), "test-secret-key")
+	for _, username := range []string{"testuser", "adminuser"} {
+		_, err := authService.Register(context.Background(), services.RegisterRequest{
+			Username: username,
+			Password: "Password123!",
+			FullName: "Test User",
+			Email:    username + "@example.com",
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Exec("UPDATE users SET role = 'admin' WHERE username = 'adminuser'").Error)
+
+	login := func(username, password string) (*httptest.ResponseRecorder, LoginErrorResponse) {
+		body, _ := json.Marshal(LoginRequest{Username: username, Password: password})
+		req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response LoginErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := login("testuser", "wrong-1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, response.CaptchaRequired)
+
+	w, response = login("testuser", "wrong-2")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.True(t, response.CaptchaRequired, "the form should ask for a CAPTCHA after repeated failures")
+
+	w, response = login("testuser", "wrong-3")
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Equal(t, ErrCodeAccountLocked, response.Code)
+	assert.NotNil(t, response.RetryAt)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// The right password does not get through while the account is locked
+	w, _ = login("testuser", "Password123!")
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	var admin struct {
+		Token string `json:"token"`
+	}
+	w, _ = login("adminuser", "Password123!")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &admin))
+
+	user, err := userRepo.GetByUsername(context.Background(), "testuser")
+	require.NoError(t, err)
+	req, _ := http.NewRequest("POST", "/api/admin/users/"+user.ID+"/unlock", nil)
+	req.Header.Set("Authorization", "Bearer "+admin.Token)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, _ = login("testuser", "Password123!")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestAuthHandler_GetProfile(t *testing.T) {
 	server, db := setupTestServer(t)
 
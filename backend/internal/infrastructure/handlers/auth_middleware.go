@@ -1,41 +1,119 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 
 	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
 	"digital-signature-system/internal/domain/services"
 	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/ratelimit"
 	"digital-signature-system/internal/infrastructure/validation"
 )
 
 type AuthMiddleware struct {
-	authService *services.AuthService
-	rateLimiter *rate.Limiter
-	logger      *logging.Logger
-	validator   *validation.Validator
-	config      *config.Config
+	authService   *services.AuthService
+	apiKeyService *services.APIKeyService
+	rbacService   *services.RBACService
+	orgService    *services.OrganizationService
+	rateLimiter   *ratelimit.Limiter
+	logger        *logging.Logger
+	validator     *validation.Validator
+	config        *config.Config
 }
 
-func NewAuthMiddleware(authService *services.AuthService, cfg *config.Config) *AuthMiddleware {
+func NewAuthMiddleware(authService *services.AuthService, cfg *config.Config, rateLimiter *ratelimit.Limiter) *AuthMiddleware {
 	return &AuthMiddleware{
 		authService: authService,
-		rateLimiter: rate.NewLimiter(rate.Every(time.Second), 100), // 100 requests per second
+		rateLimiter: rateLimiter,
 		logger:      logging.GetLogger(),
 		validator:   validation.NewValidator(),
 		config:      cfg,
 	}
 }
 
+// SetAPIKeyService lets RequireAuth accept service account API keys
+func (m *AuthMiddleware) SetAPIKeyService(apiKeyService *services.APIKeyService) {
+	m.apiKeyService = apiKeyService
+}
+
+// SetRBACService provides the roles RequirePermission checks against
+func (m *AuthMiddleware) SetRBACService(rbacService *services.RBACService) {
+	m.rbacService = rbacService
+}
+
+// SetOrganizationService lets OrganizationContext scope requests to an organization
+func (m *AuthMiddleware) SetOrganizationService(orgService *services.OrganizationService) {
+	m.orgService = orgService
+}
+
+// apiKeyRouteScopes lists the routes an API key may call and the scopes that grant
+// each, keyed by method and route pattern. Every other route is closed to keys.
+var apiKeyRouteScopes = map[string][]string{
+	"POST /api/documents/sign":                     {entities.APIKeyScopeDocumentsSign},
+	"POST /api/documents/duplicates":               {entities.APIKeyScopeDocumentsSign},
+	"POST /api/documents/sign/uploads":             {entities.APIKeyScopeDocumentsSign},
+	"HEAD /api/documents/sign/uploads/:uploadId":   {entities.APIKeyScopeDocumentsSign},
+	"PATCH /api/documents/sign/uploads/:uploadId":  {entities.APIKeyScopeDocumentsSign},
+	"DELETE /api/documents/sign/uploads/:uploadId": {entities.APIKeyScopeDocumentsSign},
+	"POST /api/documents/batch":                    {entities.APIKeyScopeDocumentsSign},
+	"GET /api/documents/batch/:batchId":            {entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents/batch/:batchId/download":   {entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents":                           {entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents/":                          {entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents/:id":                       {entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents/:id/qr-code":               {entities.APIKeyScopeDocumentsRead},
+	"GET /api/documents/:id/download":              {entities.APIKeyScopeDocumentsRead},
+	"DELETE /api/documents/:id":                    {entities.APIKeyScopeDocumentsDelete},
+	"GET /api/jobs":                                {entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+	"GET /api/jobs/:jobId":                         {entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+	"GET /api/jobs/:jobId/result":                  {entities.APIKeyScopeDocumentsSign, entities.APIKeyScopeDocumentsRead},
+	"POST /api/jobs/:jobId/cancel":                 {entities.APIKeyScopeDocumentsSign},
+}
+
+// apiKeyAllowed reports whether key may call the route c matched
+func apiKeyAllowed(c *gin.Context, key *entities.APIKey) bool {
+	for _, scope := range apiKeyRouteScopes[c.Request.Method+" "+c.FullPath()] {
+		if key.HasScope(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractAPIKey returns the API key sent in X-API-Key, or as a bearer token
+func extractAPIKey(c *gin.Context) string {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if token := extractTokenFromHeader(c); services.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
 // RequireAuth is a middleware that requires authentication
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := extractAPIKey(c); apiKey != "" && m.apiKeyService != nil {
+			m.authenticateAPIKey(c, apiKey)
+			return
+		}
+
 		token := extractTokenFromHeader(c)
 		if token == "" {
 			RespondWithUnauthorizedError(c, "Authorization token is required")
@@ -43,7 +121,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		user, err := m.authService.ValidateToken(c.Request.Context(), token)
+		user, claims, err := m.authService.ValidateTokenClaims(c.Request.Context(), token)
 		if err != nil {
 			m.logger.Warn("Authentication failed for IP %s: %v", c.ClientIP(), err)
 			MapServiceErrorToHTTP(c, err)
@@ -53,11 +131,13 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 
 		// Convert to AuthenticatedUser and store in context
 		authUser := &services.AuthenticatedUser{
-			ID:       user.ID,
-			Username: user.Username,
-			FullName: user.FullName,
-			Email:    user.Email,
-			Role:     user.Role,
+			ID:          user.ID,
+			Username:    user.Username,
+			FullName:    user.FullName,
+			Email:       user.Email,
+			Role:        user.Role,
+			MFAVerified: claims.MFA,
+			SessionID:   claims.SessionID,
 		}
 
 		c.Set("user", authUser)
@@ -67,6 +147,195 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// authenticateAPIKey authenticates a service account by key, then applies the
+// key's scopes and rate limit
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, apiKey string) {
+	user, key, err := m.apiKeyService.Authenticate(c.Request.Context(), apiKey, c.ClientIP())
+	if err != nil {
+		m.logger.Warn("API key authentication failed for IP %s: %v", c.ClientIP(), err)
+		logging.LogAuthentication(
+			logging.AuditEventAuthFailure,
+			"",
+			"",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"FAILURE",
+			map[string]interface{}{
+				"method":   "api_key",
+				"error":    err.Error(),
+				"endpoint": c.Request.URL.Path,
+			},
+		)
+		MapServiceErrorToHTTP(c, err)
+		c.Abort()
+		return
+	}
+
+	if !apiKeyAllowed(c, key) {
+		m.logger.Warn("API key %s denied on %s %s", key.Prefix, c.Request.Method, c.FullPath())
+		logging.LogAuthentication(
+			logging.AuditEventAPIKeyDenied,
+			user.ID,
+			user.Username,
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"FAILURE",
+			map[string]interface{}{
+				"api_key_id":     key.ID,
+				"api_key_prefix": key.Prefix,
+				"scopes":         key.Scopes,
+				"endpoint":       c.Request.URL.Path,
+				"method":         c.Request.Method,
+			},
+		)
+		MapServiceErrorToHTTP(c, services.ErrAPIKeyScopeDenied)
+		c.Abort()
+		return
+	}
+
+	if key.RateLimit > 0 && m.rateLimiter != nil {
+		rule := ratelimit.Rule{Route: ratelimit.RouteAPIKey, Key: ratelimit.KeyAPIKey, Limit: key.RateLimit, Window: time.Minute}
+		decision, err := m.rateLimiter.CheckRules(c.Request.Context(), []ratelimit.Rule{rule}, ratelimit.Keys{ratelimit.KeyAPIKey: key.ID})
+		if !m.applyRateLimit(c, ratelimit.RouteAPIKey, decision, err) {
+			return
+		}
+	}
+
+	authUser := &services.AuthenticatedUser{
+		ID:           user.ID,
+		Username:     user.Username,
+		FullName:     user.FullName,
+		Email:        user.Email,
+		Role:         user.Role,
+		APIKeyID:     key.ID,
+		APIKeyPrefix: key.Prefix,
+	}
+
+	c.Set("user", authUser)
+	c.Set("user_id", user.ID)
+	c.Set("user_role", user.Role)
+	c.Set("api_key_id", key.ID)
+	c.Next()
+}
+
+// RequireMFA rejects sessions opened without a second factor when policy requires
+// two-factor authentication for signing. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireMFA() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.config.MFARequiredForSigning {
+			c.Next()
+			return
+		}
+
+		user, exists := c.Get("user")
+		authUser, ok := user.(*services.AuthenticatedUser)
+		if !exists || !ok {
+			RespondWithUnauthorizedError(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		// A key stands in for a second factor; there is no one to prompt
+		if !authUser.MFAVerified && authUser.APIKeyID == "" {
+			RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeMFARequired, "Two-factor authentication is required to sign documents", "Enable two-factor authentication and log in again"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OrganizationHeader selects the organization a request acts for
+const OrganizationHeader = "X-Organization-ID"
+
+// OrganizationContext scopes the request to the organization named in the
+// X-Organization-ID header, or to the user's only organization when the header
+// is absent. Users outside any organization work with documents signed outside
+// one. It must run after RequireAuth.
+func (m *AuthMiddleware) OrganizationContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			RespondWithUnauthorizedError(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		organizationID := strings.TrimSpace(c.GetHeader(OrganizationHeader))
+		if m.orgService == nil {
+			if organizationID != "" {
+				MapServiceErrorToHTTP(c, services.ErrOrganizationNotFound)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if organizationID != "" {
+			if _, validationErr := m.validator.ValidateUUID("organization_id", organizationID, true); validationErr != nil {
+				RespondWithValidationError(c, "Invalid organization ID", validationErr.Error())
+				c.Abort()
+				return
+			}
+		}
+
+		member, err := m.orgService.ResolveMembership(c.Request.Context(), userID, organizationID)
+		if err != nil {
+			m.logger.Warn("User %s denied organization %s: %v", userID, organizationID, err)
+			MapServiceErrorToHTTP(c, err)
+			c.Abort()
+			return
+		}
+
+		scoped := ""
+		if member != nil {
+			scoped = member.OrganizationID
+			c.Set("organization_id", member.OrganizationID)
+			c.Set("organization_role", member.Role)
+		}
+		c.Request = c.Request.WithContext(repositories.WithOrganization(c.Request.Context(), scoped))
+		c.Next()
+	}
+}
+
+// RequirePermission is a middleware that requires the user's role to grant
+// permission. It must run after RequireAuth.
+func (m *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("user_role")
+		if !exists {
+			RespondWithUnauthorizedError(c, "User not authenticated")
+			c.Abort()
+			return
+		}
+
+		if m.rbacService == nil {
+			m.logger.Error("Permission %s checked without roles configured", permission)
+			RespondWithForbiddenError(c, "Insufficient permissions for this operation")
+			c.Abort()
+			return
+		}
+
+		allowed, err := m.rbacService.HasPermission(c.Request.Context(), role.(string), permission)
+		if err != nil {
+			m.logger.Error("Permission check failed for %s: %v", permission, err)
+			MapServiceErrorToHTTP(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			m.logger.Warn("User %s with role %s denied %s on %s", c.GetString("user_id"), role, permission, c.FullPath())
+			MapServiceErrorToHTTP(c, fmt.Errorf("%w: %s", services.ErrPermissionDenied, permission))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireRole is a middleware that requires a specific role
 func (m *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -128,15 +397,16 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		user, err := m.authService.ValidateToken(c.Request.Context(), token)
+		user, claims, err := m.authService.ValidateTokenClaims(c.Request.Context(), token)
 		if err == nil && user != nil {
 			// Convert to AuthenticatedUser and store in context
 			authUser := &services.AuthenticatedUser{
-				ID:       user.ID,
-				Username: user.Username,
-				FullName: user.FullName,
-				Email:    user.Email,
-				Role:     user.Role,
+				ID:          user.ID,
+				Username:    user.Username,
+				FullName:    user.FullName,
+				Email:       user.Email,
+				Role:        user.Role,
+				MFAVerified: claims.MFA,
 			}
 
 			c.Set("user", authUser)
@@ -148,6 +418,15 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	}
 }
 
+// Headers used by the tus resumable upload protocol
+const (
+	tusRequestHeaders  = "Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata, Upload-Checksum, Upload-Defer-Length"
+	tusResponseHeaders = "Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Tus-Checksum-Algorithm, Upload-Offset, Upload-Length, Upload-Metadata, Upload-Expires"
+)
+
+// Headers describing the client's rate limit quota
+const rateLimitResponseHeaders = "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After"
+
 // CORS middleware for handling cross-origin requests
 func (m *AuthMiddleware) CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -166,13 +445,14 @@ func (m *AuthMiddleware) CORS() gin.HandlerFunc {
 
 		// Only set CORS headers if origin is allowed
 		if originAllowed {
-			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-			c.Header("Access-Control-Expose-Headers", "Content-Length")
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, HEAD, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, "+OrganizationHeader+", "+AccessCodeHeader+", "+tusRequestHeaders)
+			c.Header("Access-Control-Expose-Headers", "Content-Length, Location, "+tusResponseHeaders+", "+rateLimitResponseHeaders)
 			c.Header("Access-Control-Allow-Credentials", "true")
 		}
 
-		if c.Request.Method == "OPTIONS" {
+		// Only answer CORS preflights here; a plain OPTIONS (tus discovery) reaches the router
+		if c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != "" {
 			if originAllowed {
 				c.AbortWithStatus(http.StatusNoContent)
 			} else {
@@ -185,33 +465,161 @@ func (m *AuthMiddleware) CORS() gin.HandlerFunc {
 	}
 }
 
-// RateLimiting middleware with simple rate limiting
+// RateLimiting middleware applies the global per-client limit to every request
 func (m *AuthMiddleware) RateLimiting() gin.HandlerFunc {
+	return m.RateLimit(ratelimit.RouteGlobal)
+}
+
+// RateLimit middleware applies the policies configured for route. Rules keyed by
+// user only take effect once authentication has run.
+func (m *AuthMiddleware) RateLimit(route string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		if !m.rateLimiter.Allow() {
-			m.logger.Warn("Rate limit exceeded for IP: %s", c.ClientIP())
-
-			// Log security event for rate limiting
-			logging.LogSecurityEvent(
-				logging.AuditEventRateLimitExceeded,
-				c.ClientIP(),
-				c.GetHeader("User-Agent"),
-				"Rate limit exceeded",
-				map[string]interface{}{
-					"endpoint": c.Request.URL.Path,
-					"method":   c.Request.Method,
-				},
-			)
-
-			RespondWithError(c, http.StatusTooManyRequests,
-				NewStandardError(ErrCodeRateLimitExceeded, "Too many requests"))
-			c.Abort()
+		decision, err := m.rateLimiter.Check(c.Request.Context(), route, m.rateLimitKeys(c, route))
+		if !m.applyRateLimit(c, route, decision, err) {
+			return
+		}
+		c.Next()
+	})
+}
+
+// invalidAccessCodeKey marks a request that was refused for a wrong access code
+const invalidAccessCodeKey = "invalid_access_code"
+
+// AccessCodeLimit middleware holds back clients guessing the access codes of
+// access_code documents. Only wrong codes count, per client IP and per document,
+// and a client over the limit is refused before its code is checked.
+func (m *AuthMiddleware) AccessCodeLimit() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if accessCode(c) == "" {
+			c.Next()
 			return
 		}
+
+		keys := ratelimit.Keys{
+			ratelimit.KeyIP:       c.ClientIP(),
+			ratelimit.KeyDocument: c.Param("docId"),
+		}
+		decision, err := m.rateLimiter.Peek(c.Request.Context(), ratelimit.RouteAccessCode, keys)
+		if !m.applyRateLimit(c, ratelimit.RouteAccessCode, decision, err) {
+			return
+		}
+
 		c.Next()
+
+		if c.GetBool(invalidAccessCodeKey) {
+			if _, err := m.rateLimiter.Check(c.Request.Context(), ratelimit.RouteAccessCode, keys); err != nil {
+				m.logger.Warn("Failed to count wrong access code for route %s: %v", ratelimit.RouteAccessCode, err)
+			}
+		}
 	})
 }
 
+// applyRateLimit reports a rate limit decision to the client. It responds, aborts
+// and returns false when the request is over the limit.
+func (m *AuthMiddleware) applyRateLimit(c *gin.Context, route string, decision *ratelimit.Decision, err error) bool {
+	if err != nil {
+		// Fail open: an unavailable store must not take the API down with it
+		m.logger.Warn("Rate limit check failed for route %s: %v", route, err)
+		return true
+	}
+	if decision == nil {
+		return true
+	}
+
+	setRateLimitHeaders(c, decision)
+	if !decision.Allowed {
+		m.logger.Warn("Rate limit exceeded for IP %s on route %s (%s)", c.ClientIP(), route, decision.Rule.Key)
+
+		// Log security event for rate limiting
+		logging.LogSecurityEvent(
+			logging.AuditEventRateLimitExceeded,
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"Rate limit exceeded",
+			map[string]interface{}{
+				"endpoint": c.Request.URL.Path,
+				"method":   c.Request.Method,
+				"route":    route,
+				"key":      string(decision.Rule.Key),
+			},
+		)
+
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(decision.Result.ResetAfter)))
+		RespondWithError(c, http.StatusTooManyRequests,
+			NewStandardError(ErrCodeRateLimitExceeded, "Too many requests"))
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// maxLoginBodyPeek bounds how much of a login body is read to find the username
+const maxLoginBodyPeek = 16 << 10
+
+func (m *AuthMiddleware) rateLimitKeys(c *gin.Context, route string) ratelimit.Keys {
+	keys := ratelimit.Keys{
+		ratelimit.KeyIP:   c.ClientIP(),
+		ratelimit.KeyUser: c.GetString("user_id"),
+	}
+
+	// API keys are counted by ID once authenticated, and by digest before that so
+	// raw secrets never reach the store
+	if keyID := c.GetString("api_key_id"); keyID != "" {
+		keys[ratelimit.KeyAPIKey] = keyID
+	} else if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		digest := sha256.Sum256([]byte(apiKey))
+		keys[ratelimit.KeyAPIKey] = hex.EncodeToString(digest[:])
+	}
+
+	if m.rateLimiter.Uses(route, ratelimit.KeyUsername) {
+		keys[ratelimit.KeyUsername] = peekUsername(c)
+	}
+
+	return keys
+}
+
+// peekUsername reads the username from a JSON body and puts the body back for the handler
+func peekUsername(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoginBodyPeek))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil {
+		return ""
+	}
+
+	var credentials struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(credentials.Username))
+}
+
+// setRateLimitHeaders reports the most restrictive limit seen so far on this request
+func setRateLimitHeaders(c *gin.Context, decision *ratelimit.Decision) {
+	if current := c.Writer.Header().Get("RateLimit-Remaining"); current != "" {
+		if remaining, err := strconv.Atoi(current); err == nil && decision.Allowed && remaining <= decision.Result.Remaining {
+			return
+		}
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(decision.Result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(decision.Result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Result.ResetAfter)))
+	c.Header("RateLimit-Policy", decision.Rule.Policy())
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
 // RequestLogging middleware for logging requests
 func (m *AuthMiddleware) RequestLogging() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -380,6 +788,18 @@ func (m *AuthMiddleware) SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
+// uploadMemoryLimit is how much of a multipart form may be held in memory per request
+func (m *AuthMiddleware) uploadMemoryLimit(maxSize int64) int64 {
+	limit := int64(8 << 20)
+	if m.config != nil && m.config.UploadMemoryLimit > 0 {
+		limit = m.config.UploadMemoryLimit
+	}
+	if limit > maxSize {
+		return maxSize
+	}
+	return limit
+}
+
 // FileValidation middleware for comprehensive file upload validation
 func (m *AuthMiddleware) FileValidation(maxSize int64, allowedTypes []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -398,9 +818,20 @@ func (m *AuthMiddleware) FileValidation(maxSize int64, allowedTypes []string) gi
 			return
 		}
 
-		// Parse multipart form to validate file
-		err := c.Request.ParseMultipartForm(maxSize)
+		// Chunked requests carry no Content-Length, so cap the body itself as well
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
+		// Parse multipart form to validate file; parts beyond the memory limit are spooled to disk
+		err := c.Request.ParseMultipartForm(m.uploadMemoryLimit(maxSize))
 		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				m.logger.Warn("Upload exceeded %d bytes from IP %s", maxSize, c.ClientIP())
+				RespondWithError(c, http.StatusRequestEntityTooLarge,
+					NewStandardError(ErrCodeFileTooLarge, fmt.Sprintf("request must not exceed %d bytes", maxSize)))
+				c.Abort()
+				return
+			}
 			m.logger.Error("Failed to parse multipart form from IP %s: %v", c.ClientIP(), err)
 			RespondWithValidationError(c, "Invalid form data", err.Error())
 			c.Abort()
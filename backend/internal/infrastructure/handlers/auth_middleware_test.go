@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/database"
+	"digital-signature-system/internal/infrastructure/ratelimit"
+)
+
+func setupRateLimitRouter(t *testing.T, spec string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	policies, err := ratelimit.ParsePolicies(spec)
+	require.NoError(t, err)
+	middleware := NewAuthMiddleware(nil, &config.Config{}, ratelimit.NewLimiter(ratelimit.NewMemoryStore(100), policies))
+
+	router := gin.New()
+	router.Use(middleware.RateLimiting())
+	router.POST("/login", middleware.RateLimit(ratelimit.RouteLogin), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+func TestAuthMiddleware_RateLimit_Headers(t *testing.T) {
+	router := setupRateLimitRouter(t, "global:ip=2/1m")
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, string(rune('0'+remaining)), w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), ErrCodeRateLimitExceeded)
+
+	// Each client address has its own quota
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = "192.0.2.10:4321"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_RateLimit_LoginUsername(t *testing.T) {
+	router := setupRateLimitRouter(t, "global:ip=100/1s,login:username=1/15m")
+	login := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+		return w
+	}
+
+	// The body is still readable by the handler after the username was peeked
+	w := login(`{"username":"Alice","password":"guess-1"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"username":"Alice","password":"guess-1"}`, w.Body.String())
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"), "the stricter route quota is reported")
+
+	w = login(`{"username":" alice ","password":"guess-2"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	w = login(`{"username":"bob","password":"guess-1"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func setupAPIKeyRouter(t *testing.T) (*gin.Engine, *services.APIKeyService, string) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	require.NoError(t, db.Exec(`
+		CREATE TABLE api_keys (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			prefix TEXT NOT NULL UNIQUE,
+			key_hash TEXT NOT NULL,
+			scopes TEXT,
+			rate_limit INTEGER,
+			expires_at DATETIME,
+			last_used_at DATETIME,
+			last_used_ip TEXT,
+			revoked_at DATETIME,
+			created_by TEXT,
+			created_at DATETIME,
+			updated_at DATETIME
+		)
+	`).Error)
+
+	cfg := &config.Config{
+		MFARequiredForSigning: true,
+		APIKeyDefaultTTL:      time.Hour,
+		APIKeyTouchPeriod:     time.Minute,
+	}
+	apiKeyService := services.NewAPIKeyService(database.NewAPIKeyRepository(db), database.NewUserRepository(db), cfg)
+	account, err := apiKeyService.CreateServiceAccount(context.Background(), &services.CreateServiceAccountRequest{Username: "sis-batch"})
+	require.NoError(t, err)
+
+	policies, err := ratelimit.ParsePolicies("")
+	require.NoError(t, err)
+	middleware := NewAuthMiddleware(nil, cfg, ratelimit.NewLimiter(ratelimit.NewMemoryStore(100), policies))
+	middleware.SetAPIKeyService(apiKeyService)
+
+	router := gin.New()
+	protected := router.Group("/api")
+	protected.Use(middleware.RequireAuth())
+	respond := func(c *gin.Context) {
+		user, _ := c.Get("user")
+		c.String(http.StatusOK, user.(*services.AuthenticatedUser).APIKeyPrefix)
+	}
+	protected.POST("/documents/sign", middleware.RequireMFA(), respond)
+	protected.GET("/documents/:id", respond)
+	protected.GET("/profile", respond)
+	return router, apiKeyService, account.ID
+}
+
+func TestAuthMiddleware_RequireAuth_APIKeyScopes(t *testing.T) {
+	router, apiKeyService, accountID := setupAPIKeyRouter(t)
+	key, rawKey, err := apiKeyService.CreateKey(context.Background(), accountID, "admin-1", &services.CreateAPIKeyRequest{
+		Name:   "SIS nightly",
+		Scopes: []string{"documents:sign"},
+	})
+	require.NoError(t, err)
+
+	// Signing is in scope and needs no second factor
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, key.Prefix, w.Body.String())
+
+	// The key is also accepted as a bearer token
+	req = httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+	req.Header.Set("Authorization", "Bearer "+rawKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Reading documents needs another scope, and other routes are closed to keys
+	for _, path := range []string{"/api/documents/doc-1", "/api/profile"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", rawKey)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+	}
+}
+
+func TestAuthMiddleware_RequireAuth_APIKeyRejected(t *testing.T) {
+	router, apiKeyService, accountID := setupAPIKeyRouter(t)
+	key, rawKey, err := apiKeyService.CreateKey(context.Background(), accountID, "admin-1", &services.CreateAPIKeyRequest{
+		Name:   "SIS nightly",
+		Scopes: []string{"documents:sign"},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+	req.Header.Set("X-API-Key", key.Prefix+"_not-the-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	_, err = apiKeyService.RevokeKey(context.Background(), key.ID)
+	require.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RequireAuth_APIKeyRateLimit(t *testing.T) {
+	router, apiKeyService, accountID := setupAPIKeyRouter(t)
+	limit := 2
+	_, rawKey, err := apiKeyService.CreateKey(context.Background(), accountID, "admin-1", &services.CreateAPIKeyRequest{
+		Name:      "SIS nightly",
+		Scopes:    []string{"documents:sign"},
+		RateLimit: &limit,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < limit; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+		req.Header.Set("X-API-Key", rawKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents/sign", nil)
+	req.Header.Set("X-API-Key", rawKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAuthMiddleware_RequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	cfg := &config.Config{RBACCacheTTL: time.Minute}
+	rbacService := services.NewRBACService(database.NewRoleRepository(db), database.NewUserRepository(db), cfg)
+	require.NoError(t, rbacService.EnsureBuiltInRoles(context.Background()))
+	_, err := rbacService.CreateRole(context.Background(), &services.RoleRequest{
+		Name:        "auditor",
+		Permissions: []string{"audit:read", "document:read:any"},
+	})
+	require.NoError(t, err)
+
+	middleware := NewAuthMiddleware(nil, cfg, nil)
+	middleware.SetRBACService(rbacService)
+
+	request := func(role string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/documents/sign", func(c *gin.Context) {
+			if role != "" {
+				c.Set("user_id", "user-1")
+				c.Set("user_role", role)
+			}
+		}, middleware.RequirePermission("document:sign"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/documents/sign", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("user").Code)
+	assert.Equal(t, http.StatusOK, request("admin").Code)
+	w := request("auditor")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "document:sign")
+	assert.Equal(t, http.StatusForbidden, request("no-such-role").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("").Code)
+}
+
+func TestAuthMiddleware_OrganizationContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	orgRepo := database.NewOrganizationRepository(db)
+	orgService, err := services.NewOrganizationService(orgRepo, database.NewUserRepository(db), &config.Config{JWTSecret: "test-secret-key"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	org := &entities.Organization{ID: "6f1c2b9e-3d4a-4c1b-9a7e-2f8d5c6b7a10", Slug: "engineering", Name: "Engineering", IsActive: true}
+	require.NoError(t, orgRepo.Create(ctx, org))
+	require.NoError(t, orgRepo.SaveMember(ctx, &entities.OrganizationMember{OrganizationID: org.ID, UserID: "member-1", Role: entities.OrganizationRoleMember}))
+
+	middleware := NewAuthMiddleware(nil, &config.Config{}, nil)
+	middleware.SetOrganizationService(orgService)
+
+	request := func(userID, header string) (*httptest.ResponseRecorder, string) {
+		var scoped string
+		router := gin.New()
+		router.GET("/documents", func(c *gin.Context) {
+			c.Set("user_id", userID)
+		}, middleware.OrganizationContext(), func(c *gin.Context) {
+			scoped, _ = repositories.OrganizationFromContext(c.Request.Context())
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/documents", nil)
+		if header != "" {
+			req.Header.Set(OrganizationHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w, scoped
+	}
+
+	// A member's only organization is used without the header
+	w, scoped := request("member-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, org.ID, scoped)
+
+	w, scoped = request("member-1", org.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, org.ID, scoped)
+
+	// Users outside any organization only see unscoped documents
+	w, scoped = request("outsider-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, scoped)
+
+	w, _ = request("outsider-1", org.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = request("member-1", "not-a-uuid")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
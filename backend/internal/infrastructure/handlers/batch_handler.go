@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// BatchHandler handles HTTP requests for batch document signing
+type BatchHandler struct {
+	batchService *services.BatchService
+	jobService   *services.JobService
+	authService  *services.AuthService
+	validator    *validation.Validator
+	maxSize      int64
+	maxFileSize  int64
+	tempDir      string
+}
+
+// NewBatchHandler creates a new batch handler; maxSize caps the whole batch and
+// maxFileSize each PDF, which are spooled under tempDir while the batch is signed
+func NewBatchHandler(batchService *services.BatchService, jobService *services.JobService, authService *services.AuthService, maxSize, maxFileSize int64, tempDir string) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+		jobService:   jobService,
+		authService:  authService,
+		validator:    validation.NewValidator(),
+		maxSize:      maxSize,
+		maxFileSize:  maxFileSize,
+		tempDir:      tempDir,
+	}
+}
+
+// SignBatch handles POST /api/documents/batch
+// Accepts PDFs as repeated "files" fields and/or a ZIP in "archive", plus a CSV/JSON manifest
+// either uploaded as the "manifest" file or sent inline as the "manifest" form value.
+func (h *BatchHandler) SignBatch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	if c.Request.MultipartForm == nil {
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			RespondWithValidationError(c, "Invalid form data", err.Error())
+			return
+		}
+	}
+
+	manifest, err := h.readManifest(c)
+	if err != nil {
+		RespondWithValidationError(c, "Invalid manifest", err.Error())
+		return
+	}
+
+	if validationErr := h.validateManifest(manifest); validationErr != nil {
+		RespondWithValidationError(c, "Invalid manifest entry", validationErr.Error())
+		return
+	}
+
+	// A delegate signs the whole batch for one delegator
+	delegatorID, ok := onBehalfOf(c, h.validator)
+	if !ok {
+		return
+	}
+	policy, ok := onDuplicate(c)
+	if !ok {
+		return
+	}
+
+	spool, err := h.collectFiles(c)
+	if errors.Is(err, services.ErrBatchFilesTooLarge) {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+	if err != nil {
+		RespondWithValidationError(c, "Failed to process uploaded files", err.Error())
+		return
+	}
+	defer spool.Close()
+
+	items, err := services.BuildBatchItems(manifest, spool.Files)
+	if err != nil {
+		RespondWithValidationError(c, "Manifest does not match uploaded files", err.Error())
+		return
+	}
+
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+
+	// One security key confirmation covers one batch request, bound to its
+	// files and manifest
+	digest := services.BatchDigest(items)
+	assertion, ok := requireSigningAssertion(c, h.authService, authUser, digest, true, "/api/documents/batch")
+	if !ok {
+		return
+	}
+
+	req := &services.BatchSignRequest{
+		UserID:         userID.(string),
+		OrganizationID: c.GetString("organization_id"),
+		OnBehalfOf:     delegatorID,
+		OnDuplicate:    policy,
+		Items:          items,
+	}
+
+	// Only the batch that was confirmed may be signed
+	if assertion != nil && !bytes.Equal(assertion.Digest, digest) {
+		MapServiceErrorToHTTP(c, services.ErrWebAuthnDocumentMismatch)
+		return
+	}
+
+	if h.jobService != nil && wantsAsync(c) {
+		h.enqueueBatch(c, authUser, req, assertion)
+		return
+	}
+
+	job, report, err := h.batchService.SignBatch(c.Request.Context(), req)
+	if err != nil {
+		logging.LogDocumentOperation(
+			logging.AuditEventDocumentBatchSign,
+			authUser.ID,
+			authUser.Username,
+			"",
+			c.ClientIP(),
+			"FAILURE",
+			addAPIKeyDetails(map[string]interface{}{
+				"total_items": len(items),
+				"error":       err.Error(),
+				"endpoint":    "/api/documents/batch",
+			}, authUser),
+		)
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	// Record each signed document individually so the audit trail matches single signing
+	for _, item := range report.Items {
+		if item.Status != services.BatchItemSigned {
+			continue
+		}
+		logging.LogDocumentOperation(
+			logging.AuditEventDocumentSign,
+			authUser.ID,
+			authUser.Username,
+			item.DocumentID,
+			c.ClientIP(),
+			"SUCCESS",
+			addAPIKeyDetails(addAssertionDetails(addDelegationDetails(map[string]interface{}{
+				"filename":      item.Filename,
+				"letter_number": item.LetterNumber,
+				"batch_id":      job.ID,
+				"endpoint":      "/api/documents/batch",
+			}, delegatorID), assertion), authUser),
+		)
+	}
+
+	logging.LogDocumentOperation(
+		logging.AuditEventDocumentBatchSign,
+		authUser.ID,
+		authUser.Username,
+		"",
+		c.ClientIP(),
+		"SUCCESS",
+		addAPIKeyDetails(addAssertionDetails(addDelegationDetails(map[string]interface{}{
+			"batch_id":    job.ID,
+			"total_items": report.Total,
+			"succeeded":   report.Succeeded,
+			"failed":      report.Failed,
+			"endpoint":    "/api/documents/batch",
+		}, delegatorID), assertion), authUser),
+	)
+
+	// Clients that ask for a ZIP get the archive straight away
+	if strings.Contains(c.GetHeader("Accept"), "application/zip") {
+		c.Header("X-Batch-ID", job.ID)
+		c.FileAttachment(job.ResultPath, fmt.Sprintf("batch_%s.zip", job.ID))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"batch_job":    job,
+		"report":       report,
+		"download_url": fmt.Sprintf("/api/documents/batch/%s/download", job.ID),
+		"message":      "Batch processed",
+	})
+}
+
+// enqueueBatch queues the batch for a background worker and responds with 202
+func (h *BatchHandler) enqueueBatch(c *gin.Context, authUser *services.AuthenticatedUser, req *services.BatchSignRequest, assertion *services.VerifiedAssertion) {
+	job, err := h.jobService.EnqueueBatch(c.Request.Context(), req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	logging.LogDocumentOperation(
+		logging.AuditEventJobEnqueue,
+		authUser.ID,
+		authUser.Username,
+		"",
+		c.ClientIP(),
+		"SUCCESS",
+		addAPIKeyDetails(addAssertionDetails(addDelegationDetails(map[string]interface{}{
+			"job_id":      job.ID,
+			"job_type":    job.Type,
+			"total_items": len(req.Items),
+			"endpoint":    "/api/documents/batch",
+		}, req.OnBehalfOf), assertion), authUser),
+	)
+
+	c.Header("Location", jobStatusURL(job.ID))
+	c.JSON(http.StatusAccepted, gin.H{
+		"job":        job,
+		"status_url": jobStatusURL(job.ID),
+		"message":    "Batch queued for signing",
+	})
+}
+
+// GetBatch handles GET /api/documents/batch/:batchId
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	batchID := c.Param("batchId")
+	if _, validationErr := h.validator.ValidateUUID("batch_id", batchID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid batch ID", validationErr.Error())
+		return
+	}
+
+	job, err := h.batchService.GetBatchJob(c.Request.Context(), userID.(string), batchID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batch_job": job})
+}
+
+// DownloadBatch handles GET /api/documents/batch/:batchId/download
+func (h *BatchHandler) DownloadBatch(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	batchID := c.Param("batchId")
+	if _, validationErr := h.validator.ValidateUUID("batch_id", batchID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid batch ID", validationErr.Error())
+		return
+	}
+
+	resultPath, err := h.batchService.GetBatchResultPath(c.Request.Context(), userID.(string), batchID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.FileAttachment(resultPath, fmt.Sprintf("batch_%s.zip", batchID))
+}
+
+// readManifest loads the manifest from an uploaded file or an inline form value
+func (h *BatchHandler) readManifest(c *gin.Context) ([]services.BatchManifestEntry, error) {
+	if headers := c.Request.MultipartForm.File["manifest"]; len(headers) > 0 {
+		data, err := readMultipartFile(headers[0], 10<<20)
+		if err != nil {
+			return nil, err
+		}
+		return services.ParseBatchManifest(data, headers[0].Filename)
+	}
+
+	inline := c.Request.FormValue("manifest")
+	if strings.TrimSpace(inline) == "" {
+		return nil, fmt.Errorf("manifest is required")
+	}
+	return services.ParseBatchManifest([]byte(inline), "")
+}
+
+// validateManifest applies the same field rules as single document signing
+func (h *BatchHandler) validateManifest(manifest []services.BatchManifestEntry) *validation.ValidationError {
+	for i := range manifest {
+		entry := &manifest[i]
+
+		if _, err := h.validator.ValidateFilename("filename", entry.Filename, true); err != nil {
+			return err
+		}
+
+		issuer, err := h.validator.ValidateAndSanitizeString("issuer", entry.Issuer, 1, 100, true)
+		if err != nil {
+			return err
+		}
+		title, err := h.validator.ValidateAndSanitizeString("title", entry.Title, 1, 200, true)
+		if err != nil {
+			return err
+		}
+		// A row that names a numbering scheme has its letter number allocated
+		scheme, err := h.validator.ValidateAndSanitizeString("letter_number_scheme", entry.LetterNumberScheme, 1, 32, false)
+		if err != nil {
+			return err
+		}
+		letterNumber, err := h.validator.ValidateAndSanitizeString("letter_number", entry.LetterNumber, 1, 50, scheme == "")
+		if err != nil {
+			return err
+		}
+
+		entry.Issuer = issuer
+		entry.Title = title
+		entry.LetterNumber = letterNumber
+		entry.LetterNumberScheme = scheme
+	}
+	return nil
+}
+
+// collectFiles spools the PDFs from the "files" fields and any "archive" ZIP
+// uploads; the caller must Close the spool
+func (h *BatchHandler) collectFiles(c *gin.Context) (*services.BatchSpool, error) {
+	spool, err := services.NewBatchSpool(h.tempDir, h.maxFileSize, h.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.spoolFiles(c, spool); err != nil {
+		spool.Close()
+		return nil, err
+	}
+	return spool, nil
+}
+
+func (h *BatchHandler) spoolFiles(c *gin.Context, spool *services.BatchSpool) error {
+	for _, header := range c.Request.MultipartForm.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %q: %w", header.Filename, err)
+		}
+		err = spool.Add(h.validator.SanitizeFilename(header.Filename), file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, header := range c.Request.MultipartForm.File["archive"] {
+		file, err := header.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %q: %w", header.Filename, err)
+		}
+		err = spool.AddZip(file, header.Size)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(spool.Files) == 0 {
+		return services.ErrBatchEmpty
+	}
+	return nil
+}
+
+// readMultipartFile reads an uploaded file, refusing anything larger than maxSize
+func readMultipartFile(header *multipart.FileHeader, maxSize int64) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", header.Filename, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file %q exceeds maximum size of %d bytes", header.Filename, maxSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+func TestBatchHandler_CollectFiles_TotalSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pdf := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("x"), 91)...)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, err := zw.Create("inside.pdf")
+	require.NoError(t, err)
+	_, _ = f.Write(pdf)
+	require.NoError(t, zw.Close())
+
+	collect := func(maxSize int64, files int, withArchive bool) error {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		for i := 0; i < files; i++ {
+			part, _ := writer.CreateFormFile("files", string(rune('a'+i))+".pdf")
+			_, _ = part.Write(pdf)
+		}
+		if withArchive {
+			part, _ := writer.CreateFormFile("archive", "batch.zip")
+			_, _ = part.Write(archive.Bytes())
+		}
+		require.NoError(t, writer.Close())
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/documents/batch", &body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		require.NoError(t, c.Request.ParseMultipartForm(1<<20))
+
+		h := &BatchHandler{validator: validation.NewValidator(), maxSize: maxSize, maxFileSize: 1 << 20, tempDir: t.TempDir()}
+		spool, err := h.collectFiles(c)
+		if err != nil {
+			return err
+		}
+		return spool.Close()
+	}
+
+	assert.NoError(t, collect(300, 2, true))
+	// Plain files alone over the limit
+	assert.ErrorIs(t, collect(150, 2, false), services.ErrBatchFilesTooLarge)
+	// Plain files filling the limit exactly leave no room for the archive,
+	// which must not be read without a cap
+	assert.ErrorIs(t, collect(200, 2, true), services.ErrBatchFilesTooLarge)
+	assert.ErrorIs(t, collect(250, 2, true), services.ErrBatchFilesTooLarge)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// DelegationHandler manages grants that let users sign on each other's behalf
+type DelegationHandler struct {
+	delegationService *services.DelegationService
+	validator         *validation.Validator
+}
+
+// NewDelegationHandler creates a new delegation handler
+func NewDelegationHandler(delegationService *services.DelegationService) *DelegationHandler {
+	return &DelegationHandler{
+		delegationService: delegationService,
+		validator:         validation.NewValidator(),
+	}
+}
+
+// CreateDelegation handles POST /api/delegations; the caller is the delegator
+func (h *DelegationHandler) CreateDelegation(c *gin.Context) {
+	var req services.DelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	if _, validationErr := h.validator.ValidateUUID("delegate_id", req.DelegateID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid delegate ID", validationErr.Error())
+		return
+	}
+	if req.OrganizationID != "" {
+		if _, validationErr := h.validator.ValidateUUID("organization_id", req.OrganizationID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid organization ID", validationErr.Error())
+			return
+		}
+	}
+	reason, validationErr := h.validator.ValidateAndSanitizeString("reason", req.Reason, 0, 500, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid reason", validationErr.Error())
+		return
+	}
+	req.Reason = reason
+
+	delegation, err := h.delegationService.CreateDelegation(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventDelegationCreate, delegation, map[string]interface{}{
+		"valid_from":  delegation.ValidFrom,
+		"valid_until": delegation.ValidUntil,
+		"reason":      delegation.Reason,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"delegation": delegation})
+}
+
+// ListDelegations handles GET /api/delegations and returns the grants the
+// caller gave or received
+func (h *DelegationHandler) ListDelegations(c *gin.Context) {
+	delegations, err := h.delegationService.ListDelegations(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delegations": delegations})
+}
+
+// RevokeDelegation handles POST /api/delegations/:delegationId/revoke
+func (h *DelegationHandler) RevokeDelegation(c *gin.Context) {
+	delegationID := c.Param("delegationId")
+	if _, validationErr := h.validator.ValidateUUID("delegation_id", delegationID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid delegation ID", validationErr.Error())
+		return
+	}
+
+	delegation, err := h.delegationService.RevokeDelegation(c.Request.Context(), c.GetString("user_id"), delegationID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventDelegationRevoke, delegation, map[string]interface{}{})
+
+	c.JSON(http.StatusOK, gin.H{"delegation": delegation})
+}
+
+func (h *DelegationHandler) audit(c *gin.Context, event logging.AuditEvent, delegation *entities.Delegation, details map[string]interface{}) {
+	actor, _ := c.Get("user")
+	authUser := actor.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+	details["delegation_id"] = delegation.ID
+	details["delegator_id"] = delegation.DelegatorID
+	details["delegate_id"] = delegation.DelegateID
+	if delegation.OrganizationID != nil {
+		details["organization_id"] = *delegation.OrganizationID
+	}
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "delegation", c.ClientIP(), "SUCCESS", details)
+}
+
+// onBehalfOf reads the optional "on_behalf_of" form field naming the user a
+// delegate signs for
+func onBehalfOf(c *gin.Context, validator *validation.Validator) (string, bool) {
+	delegatorID := c.Request.FormValue("on_behalf_of")
+	if delegatorID == "" {
+		return "", true
+	}
+	if _, validationErr := validator.ValidateUUID("on_behalf_of", delegatorID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid on_behalf_of user ID", validationErr.Error())
+		return "", false
+	}
+	return delegatorID, true
+}
+
+// addDelegationDetails records the delegator in signing audit details
+func addDelegationDetails(details map[string]interface{}, delegatorID string) map[string]interface{} {
+	if delegatorID != "" {
+		details["on_behalf_of"] = delegatorID
+	}
+	return details
+}
@@ -4,24 +4,34 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"digital-signature-system/internal/domain/entities"
 	"digital-signature-system/internal/domain/services"
 	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/pdf"
 	"digital-signature-system/internal/infrastructure/validation"
 )
 
 // DocumentHandler handles HTTP requests for document operations
 type DocumentHandler struct {
 	documentService *services.DocumentService
+	jobService      *services.JobService
+	uploadService   *services.UploadService
+	authService     *services.AuthService
 	validator       *validation.Validator
 }
 
-// NewDocumentHandler creates a new document handler
-func NewDocumentHandler(documentService *services.DocumentService) *DocumentHandler {
+// NewDocumentHandler creates a new document handler; authService enforces the
+// security key confirmation on signing
+func NewDocumentHandler(documentService *services.DocumentService, jobService *services.JobService, uploadService *services.UploadService, authService *services.AuthService) *DocumentHandler {
 	return &DocumentHandler{
 		documentService: documentService,
+		jobService:      jobService,
+		uploadService:   uploadService,
+		authService:     authService,
 		validator:       validation.NewValidator(),
 	}
 }
@@ -57,28 +67,6 @@ func (h *DocumentHandler) SignDocument(c *gin.Context) {
 		return
 	}
 
-	// Get file from form (form parsing is handled by middleware)
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		RespondWithValidationError(c, "File is required", err.Error())
-		return
-	}
-	defer file.Close()
-
-	// Validate and sanitize filename (use sanitized version from middleware if available)
-	filename := header.Filename
-	if sanitizedFilename, exists := c.Get("sanitized_filename_file"); exists {
-		filename = sanitizedFilename.(string)
-	} else {
-		// Fallback validation if middleware didn't process it
-		if sanitized, validationErr := h.validator.ValidateFilename("filename", header.Filename, true); validationErr != nil {
-			RespondWithValidationError(c, "Invalid filename", validationErr.Error())
-			return
-		} else {
-			filename = sanitized
-		}
-	}
-
 	// Get and validate issuer from form
 	issuer := c.Request.FormValue("issuer")
 	sanitizedIssuer, validationErr := h.validator.ValidateAndSanitizeString("issuer", issuer, 1, 100, true)
@@ -95,35 +83,114 @@ func (h *DocumentHandler) SignDocument(c *gin.Context) {
 		return
 	}
 
-	// Get and validate letter number from form
+	// Get and validate letter number from form; an organization's numbering
+	// scheme allocates it when it is left out
+	letterNumberScheme := c.Request.FormValue("letter_number_scheme")
+	sanitizedScheme, schemeValidationErr := h.validator.ValidateAndSanitizeString("letter_number_scheme", letterNumberScheme, 1, 32, false)
+	if schemeValidationErr != nil {
+		RespondWithValidationError(c, "Invalid letter number scheme", schemeValidationErr.Error())
+		return
+	}
+	numbered := sanitizedScheme != "" || c.GetString("organization_id") != ""
 	letterNumber := c.Request.FormValue("letter_number")
-	sanitizedLetterNumber, letterValidationErr := h.validator.ValidateAndSanitizeString("letter_number", letterNumber, 1, 50, true)
+	sanitizedLetterNumber, letterValidationErr := h.validator.ValidateAndSanitizeString("letter_number", letterNumber, 1, 50, !numbered)
 	if letterValidationErr != nil {
 		RespondWithValidationError(c, "Invalid letter number", letterValidationErr.Error())
 		return
 	}
 
-	// Use streaming to read PDF data with size limit for better performance
-	pdfData, err := h.documentService.ReadPDFFromStream(file)
-	if err != nil {
-		RespondWithValidationError(c, "Failed to process PDF file", err.Error())
+	// A delegate names the user they sign for
+	delegatorID, ok := onBehalfOf(c, h.validator)
+	if !ok {
+		return
+	}
+	policy, ok := onDuplicate(c)
+	if !ok {
+		return
+	}
+	private, ok := privateDocument(c)
+	if !ok {
+		return
+	}
+	visibility, ok := verificationVisibility(c)
+	if !ok {
 		return
 	}
 
-	// Create request
-	req := &services.SignDocumentRequest{
-		Filename:     filename,
-		Issuer:       sanitizedIssuer,
-		Title:        sanitizedTitle,
-		LetterNumber: sanitizedLetterNumber,
-		PDFData:      pdfData,
-		UserID:       userID.(string),
+	// The PDF comes from the multipart "file" field or from a completed resumable upload
+	spooled, filename, uploadID, ok := h.openSignSource(c, userID.(string))
+	if !ok {
+		return
 	}
+	defer spooled.Close()
 
 	// Get user info for logging
 	user, _ := c.Get("user")
 	authUser := user.(*services.AuthenticatedUser)
 
+	// A security key assertion made for exactly this file, when the user must confirm signing
+	assertion, ok := requireSigningAssertion(c, h.authService, authUser, spooled.Hash(), false, "/api/documents/sign")
+	if !ok {
+		return
+	}
+
+	// Create request
+	req := &services.SignDocumentRequest{
+		Filename:           filename,
+		Issuer:             sanitizedIssuer,
+		Title:              sanitizedTitle,
+		LetterNumber:       sanitizedLetterNumber,
+		Source:             spooled,
+		UserID:             userID.(string),
+		OrganizationID:     c.GetString("organization_id"),
+		OnBehalfOf:         delegatorID,
+		OnDuplicate:        policy,
+		LetterNumberScheme: sanitizedScheme,
+		Private:            private,
+		Visibility:         visibility,
+	}
+
+	// Queue the request when the client asks for it or the file is large
+	if h.jobService != nil && (wantsAsync(c) || h.jobService.ShouldRunAsync(spooled.Size())) {
+		job, accessCode, err := h.jobService.EnqueueSignDocument(c.Request.Context(), req)
+		if err != nil {
+			MapServiceErrorToHTTP(c, err)
+			return
+		}
+
+		logging.LogDocumentOperation(
+			logging.AuditEventJobEnqueue,
+			authUser.ID,
+			authUser.Username,
+			"",
+			c.ClientIP(),
+			"SUCCESS",
+			addAPIKeyDetails(addAssertionDetails(addDelegationDetails(map[string]interface{}{
+				"job_id":        job.ID,
+				"job_type":      job.Type,
+				"filename":      filename,
+				"letter_number": sanitizedLetterNumber,
+				"file_size":     spooled.Size(),
+				"endpoint":      "/api/documents/sign",
+			}, delegatorID), assertion), authUser),
+		)
+
+		h.releaseUpload(c, uploadID)
+
+		c.Header("Location", jobStatusURL(job.ID))
+		body := gin.H{
+			"job":        job,
+			"status_url": jobStatusURL(job.ID),
+			"message":    "Document queued for signing",
+		}
+		// The job's status does not repeat the access code, so this is the one time it is shown
+		if accessCode != "" {
+			body["access_code"] = accessCode
+		}
+		c.JSON(http.StatusAccepted, body)
+		return
+	}
+
 	// Sign document
 	response, err := h.documentService.SignDocument(c.Request.Context(), req)
 	if err != nil {
@@ -135,20 +202,44 @@ func (h *DocumentHandler) SignDocument(c *gin.Context) {
 			"", // No document ID for failed signing
 			c.ClientIP(),
 			"FAILURE",
-			map[string]interface{}{
+			addAPIKeyDetails(addAssertionDetails(addDelegationDetails(map[string]interface{}{
 				"filename":      filename,
 				"issuer":        sanitizedIssuer,
 				"letter_number": sanitizedLetterNumber,
-				"file_size":     len(pdfData),
+				"file_size":     spooled.Size(),
 				"error":         err.Error(),
 				"endpoint":      "/api/documents/sign",
-			},
+			}, delegatorID), assertion), authUser),
 		)
 		MapServiceErrorToHTTP(c, err)
 		return
 	}
 
 	// Log successful document signing
+	details := map[string]interface{}{
+		"filename":      response.Document.Filename,
+		"issuer":        response.Document.Issuer,
+		"title":         getTitleForLogging(response.Document.Title),
+		"letter_number": getLetterNumberForLogging(response.Document.LetterNumber),
+		"file_size":     spooled.Size(),
+		"version":       response.Document.Version,
+		"endpoint":      "/api/documents/sign",
+	}
+	if response.Existing {
+		details["existing"] = true
+	}
+	if response.Document.PreviousVersionID != nil {
+		details["previous_version_id"] = *response.Document.PreviousVersionID
+	}
+	if response.Document.Private {
+		details["private"] = true
+	}
+	if response.Document.Visibility != entities.DocumentVisibilityPublic {
+		details["visibility"] = response.Document.Visibility
+	}
+	if len(response.SimilarDocuments) > 0 {
+		details["similar_documents"] = len(response.SimilarDocuments)
+	}
 	logging.LogDocumentOperation(
 		logging.AuditEventDocumentSign,
 		authUser.ID,
@@ -156,23 +247,192 @@ func (h *DocumentHandler) SignDocument(c *gin.Context) {
 		response.Document.ID,
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
-			"filename":      response.Document.Filename,
-			"issuer":        response.Document.Issuer,
-			"title":         getTitleForLogging(response.Document.Title),
-			"letter_number": getLetterNumberForLogging(response.Document.LetterNumber),
-			"file_size":     len(pdfData),
-			"endpoint":      "/api/documents/sign",
-		},
+		addAPIKeyDetails(addAssertionDetails(addDelegationDetails(details, delegatorID), assertion), authUser),
 	)
 
-	// Return response without PDF data in JSON (too large)
-	c.JSON(http.StatusCreated, gin.H{
-		"document": response.Document,
-		"message":  "Document signed successfully",
+	h.releaseUpload(c, uploadID)
+
+	// Return response without PDF data in JSON (too large); the document is
+	// returned as the detail view shows it
+	response.Document.PageText = response.Document.PageTexts()
+	if response.Existing {
+		c.JSON(http.StatusOK, gin.H{
+			"document": response.Document,
+			"existing": true,
+			"message":  "This PDF was already signed; the existing document was returned",
+		})
+		return
+	}
+	body := gin.H{
+		"document":          response.Document,
+		"similar_documents": response.SimilarDocuments,
+		"message":           "Document signed successfully",
+	}
+	if response.AccessCode != "" {
+		// Only shown now; it is printed next to the QR code of the signed PDF
+		body["access_code"] = response.AccessCode
+	}
+	c.JSON(http.StatusCreated, body)
+}
+
+// CheckDuplicates handles POST /api/documents/duplicates. It reports whether the
+// PDF was already signed and which documents have nearly the same text, so a
+// clerk can check before reissuing a letter.
+func (h *DocumentHandler) CheckDuplicates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	delegatorID, ok := onBehalfOf(c, h.validator)
+	if !ok {
+		return
+	}
+
+	// The upload is only read; a resumable upload stays available for /sign
+	spooled, _, _, ok := h.openSignSource(c, userID.(string))
+	if !ok {
+		return
+	}
+	defer spooled.Close()
+
+	report, err := h.documentService.CheckDuplicates(c.Request.Context(), &services.SignDocumentRequest{
+		Source:         spooled,
+		UserID:         userID.(string),
+		OrganizationID: c.GetString("organization_id"),
+		OnBehalfOf:     delegatorID,
+	})
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicate":         report.Existing != nil,
+		"existing":          report.Existing,
+		"similar_documents": report.Similar,
 	})
 }
 
+// onDuplicate reads the "on_duplicate" form field, which chooses what signing an
+// already signed PDF does; empty leaves the configured policy
+func onDuplicate(c *gin.Context) (string, bool) {
+	policy := c.Request.FormValue("on_duplicate")
+	switch policy {
+	case "", services.DuplicateReject, services.DuplicateReturnExisting, services.DuplicateNewVersion:
+		return policy, true
+	default:
+		RespondWithValidationError(c, "Invalid on_duplicate policy", services.ErrInvalidDuplicatePolicy.Error())
+		return "", false
+	}
+}
+
+// privateDocument reads the optional "private" form field; private documents
+// cannot be found by verifying their file
+func privateDocument(c *gin.Context) (bool, bool) {
+	value := c.Request.FormValue("private")
+	if value == "" {
+		return false, true
+	}
+	private, err := strconv.ParseBool(value)
+	if err != nil {
+		RespondWithValidationError(c, "Invalid private flag", "private must be true or false")
+		return false, false
+	}
+	return private, true
+}
+
+// verificationVisibility reads the optional "visibility" form field, which sets
+// how much the public verification page shows; empty is public
+func verificationVisibility(c *gin.Context) (string, bool) {
+	visibility := c.Request.FormValue("visibility")
+	switch visibility {
+	case "", entities.DocumentVisibilityPublic, entities.DocumentVisibilityMinimal, entities.DocumentVisibilityAccessCode:
+		return visibility, true
+	default:
+		RespondWithValidationError(c, "Invalid visibility", "visibility must be public, minimal or access_code")
+		return "", false
+	}
+}
+
+// openSignSource spools the document to sign from either the "file" form field or, when
+// "upload_id" is given, a completed resumable upload owned by the user
+func (h *DocumentHandler) openSignSource(c *gin.Context, userID string) (*pdf.SpooledPDF, string, string, bool) {
+	if uploadID := c.Request.FormValue("upload_id"); uploadID != "" {
+		if _, validationErr := h.validator.ValidateUUID("upload_id", uploadID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid upload ID", validationErr.Error())
+			return nil, "", "", false
+		}
+
+		scope := services.UploadScope{Purpose: entities.UploadPurposeSign, UserID: userID}
+		file, session, err := h.uploadService.OpenCompletedUpload(c.Request.Context(), scope, uploadID)
+		if err != nil {
+			MapServiceErrorToHTTP(c, err)
+			return nil, "", "", false
+		}
+		defer file.Close()
+
+		// Prefer an explicit form value, then the name the tus client sent as metadata
+		filename := c.Request.FormValue("filename")
+		if filename == "" {
+			filename = session.Metadata["filename"]
+		}
+		if filename == "" {
+			filename = session.Metadata["name"]
+		}
+		sanitizedFilename, validationErr := h.validator.ValidateFilename("filename", filename, true)
+		if validationErr != nil {
+			RespondWithValidationError(c, "Invalid filename", validationErr.Error())
+			return nil, "", "", false
+		}
+
+		spooled, err := h.documentService.SpoolPDF(file)
+		if err != nil {
+			RespondWithValidationError(c, "Failed to process PDF file", err.Error())
+			return nil, "", "", false
+		}
+		return spooled, sanitizedFilename, uploadID, true
+	}
+
+	// Get file from form (form parsing is handled by middleware)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		RespondWithValidationError(c, "File is required", err.Error())
+		return nil, "", "", false
+	}
+	defer file.Close()
+
+	// Validate and sanitize filename (use sanitized version from middleware if available)
+	filename := header.Filename
+	if sanitizedFilename, exists := c.Get("sanitized_filename_file"); exists {
+		filename = sanitizedFilename.(string)
+	} else {
+		// Fallback validation if middleware didn't process it
+		if sanitized, validationErr := h.validator.ValidateFilename("filename", header.Filename, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid filename", validationErr.Error())
+			return nil, "", "", false
+		} else {
+			filename = sanitized
+		}
+	}
+
+	// Stream the upload to disk, hashing and parsing it once, so memory stays bounded
+	spooled, err := h.documentService.SpoolPDF(file)
+	if err != nil {
+		RespondWithValidationError(c, "Failed to process PDF file", err.Error())
+		return nil, "", "", false
+	}
+	return spooled, filename, "", true
+}
+
+// releaseUpload removes a resumable upload once the signing flow has taken its contents
+func (h *DocumentHandler) releaseUpload(c *gin.Context, uploadID string) {
+	if uploadID != "" {
+		h.uploadService.ReleaseUpload(c.Request.Context(), uploadID)
+	}
+}
+
 // GetDocuments handles GET /api/documents
 func (h *DocumentHandler) GetDocuments(c *gin.Context) {
 	// Get user ID from authentication context
@@ -272,18 +532,128 @@ func (h *DocumentHandler) GetDocuments(c *gin.Context) {
 		"", // No specific document ID for list operation
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
+		addAPIKeyDetails(map[string]interface{}{
 			"page":            req.Page,
 			"page_size":       req.PageSize,
 			"status":          req.Status,
 			"total_documents": len(response.Documents),
 			"endpoint":        "/api/documents",
-		},
+		}, authUser),
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchDocuments handles GET /api/documents/search?q=&issuer=&status=&from=&to=&sort=&order=&limit=&cursor=&all=
+func (h *DocumentHandler) SearchDocuments(c *gin.Context) {
+	req := &services.SearchDocumentsRequest{UserID: c.GetString("user_id")}
+
+	query, validationErr := h.validator.ValidateAndSanitizeString("q", c.Query("q"), 0, 200, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid search query", validationErr.Error())
+		return
+	}
+	issuer, validationErr := h.validator.ValidateAndSanitizeString("issuer", c.Query("issuer"), 0, 100, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid issuer", validationErr.Error())
+		return
+	}
+	req.Query, req.Issuer = query, issuer
+
+	switch status := c.Query("status"); status {
+	case "":
+		// Deleted documents are only found when asked for
+		req.Status = "active"
+	case "any":
+	case "active", "inactive", "deleted":
+		req.Status = status
+	default:
+		RespondWithValidationError(c, "Status must be one of: active, inactive, deleted, any")
+		return
+	}
+
+	var err error
+	if req.CreatedFrom, err = parseSearchDate(c.Query("from"), false); err != nil {
+		RespondWithValidationError(c, "Invalid from date", err.Error())
+		return
+	}
+	if req.CreatedTo, err = parseSearchDate(c.Query("to"), true); err != nil {
+		RespondWithValidationError(c, "Invalid to date", err.Error())
+		return
+	}
+
+	req.Sort = c.Query("sort")
+	switch c.Query("order") {
+	case "", "desc":
+	case "asc":
+		req.Ascending = true
+	default:
+		RespondWithValidationError(c, "Order must be asc or desc")
+		return
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 100 {
+			RespondWithValidationError(c, "Limit must be between 1 and 100")
+			return
+		}
+		req.Limit = limit
+	}
+	req.Cursor = c.Query("cursor")
+	if len(req.Cursor) > 1024 {
+		RespondWithValidationError(c, "Invalid cursor")
+		return
+	}
+	req.All = c.Query("all") == "true"
+
+	response, err := h.documentService.SearchDocuments(c.Request.Context(), req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+	logging.LogDocumentOperation(
+		logging.AuditEventDocumentList,
+		authUser.ID,
+		authUser.Username,
+		"",
+		c.ClientIP(),
+		"SUCCESS",
+		addAPIKeyDetails(map[string]interface{}{
+			"query":           req.Query,
+			"issuer":          req.Issuer,
+			"status":          req.Status,
+			"all":             req.All,
+			"total_documents": response.Total,
+			"endpoint":        "/api/documents/search",
+		}, authUser),
 	)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// parseSearchDate reads a date (YYYY-MM-DD) or timestamp (RFC 3339). A date used
+// as the end of a range covers that whole day.
+func parseSearchDate(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("use YYYY-MM-DD or an RFC 3339 timestamp")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
 // GetDocument handles GET /api/documents/:id
 func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	// Get user ID from authentication context
@@ -321,10 +691,10 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 			documentID,
 			c.ClientIP(),
 			"FAILURE",
-			map[string]interface{}{
+			addAPIKeyDetails(map[string]interface{}{
 				"error":    err.Error(),
 				"endpoint": "/api/documents/" + documentID,
-			},
+			}, authUser),
 		)
 		MapServiceErrorToHTTP(c, err)
 		return
@@ -338,13 +708,15 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 		document.ID,
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
+		addAPIKeyDetails(map[string]interface{}{
 			"filename": document.Filename,
 			"issuer":   document.Issuer,
 			"endpoint": "/api/documents/" + documentID,
-		},
+		}, authUser),
 	)
 
+	// The detail view shows the text extracted from each page
+	document.PageText = document.PageTexts()
 	c.JSON(http.StatusOK, gin.H{"document": document})
 }
 
@@ -385,10 +757,10 @@ func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 			documentID,
 			c.ClientIP(),
 			"FAILURE",
-			map[string]interface{}{
+			addAPIKeyDetails(map[string]interface{}{
 				"error":    err.Error(),
 				"endpoint": "/api/documents/" + documentID,
-			},
+			}, authUser),
 		)
 		MapServiceErrorToHTTP(c, err)
 		return
@@ -402,9 +774,9 @@ func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 		documentID,
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
+		addAPIKeyDetails(map[string]interface{}{
 			"endpoint": "/api/documents/" + documentID,
-		},
+		}, authUser),
 	)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
@@ -447,11 +819,11 @@ func (h *DocumentHandler) DownloadQRCode(c *gin.Context) {
 			documentID,
 			c.ClientIP(),
 			"FAILURE",
-			map[string]interface{}{
+			addAPIKeyDetails(map[string]interface{}{
 				"operation": "qr_code_download",
 				"error":     err.Error(),
 				"endpoint":  "/api/documents/" + documentID + "/qr-code",
-			},
+			}, authUser),
 		)
 		MapServiceErrorToHTTP(c, err)
 		return
@@ -465,11 +837,11 @@ func (h *DocumentHandler) DownloadQRCode(c *gin.Context) {
 		documentID,
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
+		addAPIKeyDetails(map[string]interface{}{
 			"operation": "qr_code_download",
 			"filename":  filename,
 			"endpoint":  "/api/documents/" + documentID + "/qr-code",
-		},
+		}, authUser),
 	)
 
 	// Set headers for file download
@@ -518,11 +890,11 @@ func (h *DocumentHandler) DownloadSignedPDF(c *gin.Context) {
 			documentID,
 			c.ClientIP(),
 			"FAILURE",
-			map[string]interface{}{
+			addAPIKeyDetails(map[string]interface{}{
 				"operation": "pdf_download",
 				"error":     err.Error(),
 				"endpoint":  "/api/documents/" + documentID + "/download",
-			},
+			}, authUser),
 		)
 		MapServiceErrorToHTTP(c, err)
 		return
@@ -536,12 +908,12 @@ func (h *DocumentHandler) DownloadSignedPDF(c *gin.Context) {
 		documentID,
 		c.ClientIP(),
 		"SUCCESS",
-		map[string]interface{}{
+		addAPIKeyDetails(map[string]interface{}{
 			"operation": "pdf_download",
 			"filename":  filename,
 			"file_size": len(pdfData),
 			"endpoint":  "/api/documents/" + documentID + "/download",
-		},
+		}, authUser),
 	)
 
 	// Set headers for file download
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/database"
+)
+
+// setupDocumentTestDB adds the tables signing and verifying a document write to
+func setupDocumentTestDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+
+	for _, statement := range []string{
+		`CREATE TABLE documents (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			filename TEXT NOT NULL,
+			issuer TEXT NOT NULL,
+			title TEXT,
+			letter_number TEXT,
+			document_hash TEXT NOT NULL,
+			signature_data TEXT NOT NULL,
+			qr_code_data TEXT NOT NULL,
+			created_at DATETIME,
+			updated_at DATETIME,
+			file_size INTEGER,
+			status TEXT DEFAULT 'active',
+			organization_id TEXT,
+			key_id TEXT,
+			signed_by_id TEXT,
+			delegation_id TEXT,
+			content_text TEXT,
+			metadata TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			previous_version_id TEXT,
+			letter_number_scheme_id TEXT,
+			stamped_hash TEXT,
+			private BOOLEAN NOT NULL DEFAULT false,
+			visibility TEXT NOT NULL DEFAULT 'public',
+			access_code_hash TEXT
+		)`,
+		`CREATE TABLE verification_logs (
+			id TEXT PRIMARY KEY,
+			document_id TEXT,
+			verification_result TEXT,
+			verified_at DATETIME,
+			verifier_ip TEXT,
+			country TEXT,
+			details TEXT
+		)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+
+	return db
+}
+
+// newDocumentTestServer starts a server over setupDocumentTestDB with a signed in user
+func newDocumentTestServer(t *testing.T, rateLimitPolicies string) (*Server, string) {
+	db := setupDocumentTestDB(t)
+	server := NewServer(&config.Config{
+		JWTSecret:         "test-secret-key",
+		Environment:       "test",
+		BaseURL:           "http://localhost:3000",
+		MaxPDFSize:        10 << 20,
+		StorageDir:        t.TempDir(),
+		RateLimitPolicies: rateLimitPolicies,
+	}, db)
+
+	authService := services.NewAuthService(database.NewUserRepository(db), database.NewSessionRepository(db), "test-secret-key")
+	_, err := authService.Register(context.Background(), services.RegisterRequest{
+		Username: "signer",
+		Password: "Password123!",
+		FullName: "Signer",
+		Email:    "signer@example.com",
+	})
+	require.NoError(t, err)
+	login, err := authService.Login(context.Background(), services.LoginRequest{Username: "signer", Password: "Password123!"})
+	require.NoError(t, err)
+
+	return server, login.Token
+}
+
+// uploadPDF posts pdfData as the multipart "file" field along with fields
+func uploadPDF(server *Server, path, token string, fields map[string]string, pdfData []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="letter.pdf"`)
+	header.Set("Content-Type", "application/pdf")
+	part, _ := writer.CreatePart(header)
+	_, _ = part.Write(pdfData)
+	for name, value := range fields {
+		_ = writer.WriteField(name, value)
+	}
+	_ = writer.Close()
+
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDocumentHandler_SignDownloadVerifyByFile(t *testing.T) {
+	server, token := newDocumentTestServer(t, "")
+	upload := func(path, token string, fields map[string]string, pdfData []byte) *httptest.ResponseRecorder {
+		return uploadPDF(server, path, token, fields, pdfData)
+	}
+
+	// Sign
+	w := upload("/api/documents/sign", token, map[string]string{
+		"issuer":        "Issuer",
+		"title":         "Letter",
+		"letter_number": "001/2026",
+	}, webAuthnTestPDF())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var signed struct {
+		Document struct {
+			ID string `json:"id"`
+		} `json:"document"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &signed))
+	require.NotEmpty(t, signed.Document.ID)
+
+	// Download the signed PDF
+	req, _ := http.NewRequest("GET", "/api/documents/"+signed.Document.ID+"/download", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	downloaded := w.Body.Bytes()
+	require.NotEmpty(t, downloaded)
+
+	// The downloaded file is found and verifies as the signed document
+	w = upload("/api/verify/by-file", "", nil, downloaded)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var verified struct {
+		Result services.VerificationResult `json:"verification_result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &verified))
+	assert.Equal(t, signed.Document.ID, verified.Result.DocumentID)
+	assert.True(t, verified.Result.HashMatches)
+	assert.True(t, verified.Result.IsValid, verified.Result.Message)
+}
+
+func TestVerificationHandler_AccessCode(t *testing.T) {
+	server, token := newDocumentTestServer(t, "access_code:ip=3/15m,access_code:document=5/15m")
+
+	w := uploadPDF(server, "/api/documents/sign", token, map[string]string{
+		"issuer":        "Issuer",
+		"title":         "Letter",
+		"letter_number": "002/2026",
+		"visibility":    "access_code",
+	}, webAuthnTestPDF())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var signed struct {
+		Document struct {
+			ID string `json:"id"`
+		} `json:"document"`
+		AccessCode string `json:"access_code"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &signed))
+	require.NotEmpty(t, signed.AccessCode)
+
+	verify := func(query, code, ip string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/verify/"+signed.Document.ID+query, nil)
+		if code != "" {
+			req.Header.Set(AccessCodeHeader, code)
+		}
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A code in the query string is ignored
+	w = verify("?access_code="+signed.AccessCode, "", "10.0.0.1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeAccessCodeRequired)
+
+	w = verify("", signed.AccessCode, "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Only wrong codes count, and a client over the limit is refused even with the right code
+	for i := 0; i < 3; i++ {
+		w = verify("", "WRONG-CODE", "10.0.0.1")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), ErrCodeInvalidAccessCode)
+	}
+	w = verify("", signed.AccessCode, "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Guesses from other addresses count against the document as well
+	w = verify("", signed.AccessCode, "10.0.0.2")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for i := 0; i < 2; i++ {
+		w = verify("", "WRONG-CODE", "10.0.0.2")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+	w = verify("", signed.AccessCode, "10.0.0.3")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestVerificationHandler_HistoryRequiresAuditRead(t *testing.T) {
+	server, token := newDocumentTestServer(t, "")
+
+	w := uploadPDF(server, "/api/documents/sign", token, map[string]string{
+		"issuer":        "Issuer",
+		"title":         "Letter",
+		"letter_number": "003/2026",
+	}, webAuthnTestPDF())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var signed struct {
+		Document struct {
+			ID string `json:"id"`
+		} `json:"document"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &signed))
+
+	get := func(path string) int {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	history := "/api/verify/" + signed.Document.ID + "/history"
+
+	// The built-in user role reads the verification logs of its own documents
+	assert.Equal(t, http.StatusOK, get(history))
+	assert.Equal(t, http.StatusOK, get("/api/verification-analytics"))
+
+	// A role without audit:read cannot, even for documents it signed
+	require.NoError(t, server.db.Exec(`INSERT INTO roles (id, name, permissions) VALUES ('role-clerk', 'clerk', '["document:sign"]')`).Error)
+	require.NoError(t, server.db.Exec(`UPDATE users SET role = 'clerk' WHERE username = 'signer'`).Error)
+	assert.Equal(t, http.StatusForbidden, get(history))
+	assert.Equal(t, http.StatusForbidden, get("/api/verification-analytics"))
+}
@@ -28,11 +28,18 @@ const (
 	ErrCodeInternalError      = "INTERNAL_ERROR"
 	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
 	ErrCodeRateLimitExceeded  = "RATE_LIMIT_EXCEEDED"
+	ErrCodeAccountLocked      = "ACCOUNT_LOCKED"
+	ErrCodeMFARequired        = "MFA_REQUIRED"
+	ErrCodeInvalidMFACode     = "INVALID_MFA_CODE"
+	ErrCodeWebAuthnRequired   = "WEBAUTHN_REQUIRED"
+	ErrCodeWebAuthnFailed     = "WEBAUTHN_FAILED"
 	ErrCodeInvalidFile        = "INVALID_FILE"
 	ErrCodeFileTooLarge       = "FILE_TOO_LARGE"
 	ErrCodeInvalidPDF         = "INVALID_PDF"
 	ErrCodeSignatureFailed    = "SIGNATURE_FAILED"
 	ErrCodeVerificationFailed = "VERIFICATION_FAILED"
+	ErrCodeAccessCodeRequired = "ACCESS_CODE_REQUIRED"
+	ErrCodeInvalidAccessCode  = "INVALID_ACCESS_CODE"
 )
 
 // NewStandardError creates a new standardized error
@@ -124,6 +131,293 @@ func MapServiceErrorToHTTP(c *gin.Context, err error) {
 		RespondWithForbiddenError(c, "User account is inactive")
 		return
 	}
+	if errors.Is(err, services.ErrAccountLocked) {
+		RespondWithError(c, http.StatusLocked, NewStandardError(ErrCodeAccountLocked, "Account temporarily locked due to too many failed login attempts"))
+		return
+	}
+	if errors.Is(err, services.ErrLoginThrottled) {
+		RespondWithError(c, http.StatusTooManyRequests, NewStandardError(ErrCodeRateLimitExceeded, "Too many failed login attempts, please try again later"))
+		return
+	}
+	if errors.Is(err, services.ErrLoginThrottleNotFound) {
+		RespondWithNotFoundError(c, "Lockout not found")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidMFACode) {
+		RespondWithError(c, http.StatusUnauthorized, NewStandardError(ErrCodeInvalidMFACode, "Invalid two-factor authentication code"))
+		return
+	}
+	if errors.Is(err, services.ErrMFARequired) {
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeMFARequired, "Two-factor authentication is required by policy"))
+		return
+	}
+	if errors.Is(err, services.ErrMFANotEnabled) || errors.Is(err, services.ErrMFAEnrollmentNotStarted) {
+		RespondWithConflictError(c, "Two-factor authentication is not set up", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrMFAAlreadyEnabled) {
+		RespondWithConflictError(c, "Two-factor authentication is already enabled")
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnRequired) {
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeWebAuthnRequired, "Confirm this request with your security key"))
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnVerificationFailed) || errors.Is(err, services.ErrWebAuthnChallengeNotFound) ||
+		errors.Is(err, services.ErrWebAuthnDocumentMismatch) {
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeWebAuthnFailed, "Security key confirmation failed", err.Error()))
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnNoCredentials) {
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeWebAuthnRequired, "Register a security key before signing"))
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnInvalidHash) {
+		RespondWithValidationError(c, "Invalid document hash", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnCredentialNotFound) {
+		RespondWithNotFoundError(c, "Security key not found")
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnCredentialRegistered) {
+		RespondWithConflictError(c, "Security key is already registered")
+		return
+	}
+	if errors.Is(err, services.ErrWebAuthnNotConfigured) {
+		RespondWithError(c, http.StatusServiceUnavailable, NewStandardError(ErrCodeServiceUnavailable, "Security keys are not available"))
+		return
+	}
+	if errors.Is(err, services.ErrRefreshTokenReused) {
+		RespondWithUnauthorizedError(c, "Session has been revoked")
+		return
+	}
+	if errors.Is(err, services.ErrSessionNotFound) {
+		RespondWithNotFoundError(c, "Session not found")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCProviderNotFound) {
+		RespondWithNotFoundError(c, "Identity provider not found")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCProviderFailed) {
+		RespondWithError(c, http.StatusServiceUnavailable, NewStandardError(ErrCodeServiceUnavailable, "Identity provider is unavailable"))
+		return
+	}
+	if errors.Is(err, services.ErrOIDCStateInvalid) {
+		RespondWithValidationError(c, "Sign-in request not found or expired, please start again")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCLoginFailed) {
+		RespondWithUnauthorizedError(c, "Sign-in with the identity provider failed")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCAccessDenied) {
+		RespondWithForbiddenError(c, "Your account is not permitted to sign in")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCAccountNotLinked) {
+		RespondWithForbiddenError(c, "No account is linked to this identity")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCAccountConflict) {
+		RespondWithConflictError(c, "An account with this email address already exists; ask an administrator to link it")
+		return
+	}
+	if errors.Is(err, services.ErrOIDCEmailRequired) {
+		RespondWithForbiddenError(c, "The identity provider did not supply an email address")
+		return
+	}
+	if errors.Is(err, services.ErrLDAPUnavailable) {
+		RespondWithError(c, http.StatusServiceUnavailable, NewStandardError(ErrCodeServiceUnavailable, "Directory server is unavailable"))
+		return
+	}
+	if errors.Is(err, services.ErrLDAPAccessDenied) {
+		RespondWithForbiddenError(c, "Your directory account is not permitted to sign in")
+		return
+	}
+	if errors.Is(err, services.ErrLDAPAccountConflict) {
+		RespondWithConflictError(c, "A local account with this username or email already exists; ask an administrator to link it")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidAPIKey) {
+		RespondWithUnauthorizedError(c, "Invalid API key")
+		return
+	}
+	if errors.Is(err, services.ErrAPIKeyExpired) {
+		RespondWithUnauthorizedError(c, "API key has expired")
+		return
+	}
+	if errors.Is(err, services.ErrAPIKeyScopeDenied) {
+		RespondWithForbiddenError(c, "API key does not permit this operation")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidAPIKeyRequest) {
+		RespondWithValidationError(c, "Invalid API key request", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		RespondWithNotFoundError(c, "API key not found")
+		return
+	}
+	if errors.Is(err, services.ErrServiceAccountNotFound) {
+		RespondWithNotFoundError(c, "Service account not found")
+		return
+	}
+	if errors.Is(err, services.ErrPermissionDenied) {
+		RespondWithForbiddenError(c, "Insufficient permissions for this operation", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrRoleNotFound) {
+		RespondWithNotFoundError(c, "Role not found")
+		return
+	}
+	if errors.Is(err, services.ErrRoleExists) {
+		RespondWithConflictError(c, "A role with this name already exists")
+		return
+	}
+	if errors.Is(err, services.ErrRoleInUse) {
+		RespondWithConflictError(c, "The role is assigned to users; assign them another role first")
+		return
+	}
+	if errors.Is(err, services.ErrBuiltInRole) {
+		RespondWithConflictError(c, "Built-in roles cannot be changed this way", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidRole) {
+		RespondWithValidationError(c, "Invalid role", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidUserRequest) {
+		RespondWithValidationError(c, "Invalid user request", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrRegistrationClosed) {
+		RespondWithForbiddenError(c, "Registration is closed; ask an administrator for an account")
+		return
+	}
+	if errors.Is(err, services.ErrInvitationRequired) {
+		RespondWithForbiddenError(c, "An invitation is required to register")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidInvitation) {
+		RespondWithForbiddenError(c, "Invalid or expired invitation")
+		return
+	}
+	if errors.Is(err, services.ErrInvitationNotFound) {
+		RespondWithNotFoundError(c, "Invitation not found")
+		return
+	}
+	if errors.Is(err, services.ErrOrganizationNotFound) {
+		RespondWithNotFoundError(c, "Organization not found")
+		return
+	}
+	if errors.Is(err, services.ErrOrganizationExists) {
+		RespondWithConflictError(c, "An organization with this slug already exists")
+		return
+	}
+	if errors.Is(err, services.ErrOrganizationInactive) {
+		RespondWithForbiddenError(c, "Organization is deactivated")
+		return
+	}
+	if errors.Is(err, services.ErrNotOrganizationMember) {
+		RespondWithForbiddenError(c, "You are not a member of this organization")
+		return
+	}
+	if errors.Is(err, services.ErrOrganizationAccess) {
+		RespondWithForbiddenError(c, "Organization administrator access required", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidOrganization) {
+		RespondWithValidationError(c, "Invalid organization", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrOrganizationKeyNotFound) {
+		RespondWithNotFoundError(c, "Organization signing key not found")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidSearch) {
+		RespondWithValidationError(c, "Invalid search", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidAnalytics) {
+		RespondWithValidationError(c, "Invalid verification report", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrDelegationNotFound) {
+		RespondWithNotFoundError(c, "Delegation not found")
+		return
+	}
+	if errors.Is(err, services.ErrDelegationAccess) {
+		RespondWithForbiddenError(c, "Access denied", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrNoActiveDelegation) {
+		RespondWithForbiddenError(c, "You have no active delegation to sign on behalf of this user", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrDelegationsDisabled) {
+		RespondWithError(c, http.StatusServiceUnavailable, NewStandardError(ErrCodeServiceUnavailable, "Signing on behalf of another user is not available"))
+		return
+	}
+	if errors.Is(err, services.ErrDuplicateDocument) {
+		RespondWithConflictError(c, "This PDF has already been signed", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidDuplicatePolicy) {
+		RespondWithValidationError(c, "Invalid on_duplicate policy", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrNoMatchingDocument) {
+		RespondWithNotFoundError(c, "No signed document matches this file")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidVerificationFile) {
+		RespondWithValidationError(c, "Invalid PDF file", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrAccessCodeRequired) {
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeAccessCodeRequired, "An access code is required to view this document"))
+		return
+	}
+	if errors.Is(err, services.ErrInvalidAccessCode) {
+		c.Set(invalidAccessCodeKey, true)
+		RespondWithError(c, http.StatusForbidden, NewStandardError(ErrCodeInvalidAccessCode, "Invalid access code"))
+		return
+	}
+	if errors.Is(err, services.ErrInvalidVisibility) {
+		RespondWithValidationError(c, "Invalid visibility", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrLetterNumberSchemeNotFound) {
+		RespondWithNotFoundError(c, "Letter number scheme not found")
+		return
+	}
+	if errors.Is(err, services.ErrLetterNumberSchemeExists) {
+		RespondWithConflictError(c, "A letter number scheme with this code already exists")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidLetterNumberScheme) {
+		RespondWithValidationError(c, "Invalid letter number scheme", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrLetterNumberConflict) || errors.Is(err, services.ErrLetterNumberRequired) ||
+		errors.Is(err, services.ErrLetterNumberReserved) {
+		RespondWithValidationError(c, "Invalid letter number", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidDelegation) {
+		RespondWithValidationError(c, "Invalid delegation", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrMaintenanceTaskNotFound) {
+		RespondWithNotFoundError(c, "Maintenance task not found")
+		return
+	}
+	if errors.Is(err, services.ErrMaintenanceTaskRunning) {
+		RespondWithConflictError(c, "Maintenance task is already running")
+		return
+	}
 	if errors.Is(err, services.ErrInvalidToken) {
 		RespondWithUnauthorizedError(c, "Invalid or malformed token")
 		return
@@ -132,6 +426,94 @@ func MapServiceErrorToHTTP(c *gin.Context, err error) {
 		RespondWithUnauthorizedError(c, "Token has expired")
 		return
 	}
+	if errors.Is(err, services.ErrBatchNotFound) {
+		RespondWithNotFoundError(c, "Batch job not found")
+		return
+	}
+	if errors.Is(err, services.ErrBatchAccess) {
+		RespondWithForbiddenError(c, "Access denied")
+		return
+	}
+	if errors.Is(err, services.ErrBatchNotFinished) {
+		RespondWithConflictError(c, "Batch job has not finished")
+		return
+	}
+	if errors.Is(err, services.ErrBatchEmpty) || errors.Is(err, services.ErrBatchTooLarge) || errors.Is(err, services.ErrInvalidManifest) {
+		RespondWithValidationError(c, "Invalid batch request", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrJobNotFound) {
+		RespondWithNotFoundError(c, "Job not found")
+		return
+	}
+	if errors.Is(err, services.ErrJobAccess) {
+		RespondWithForbiddenError(c, "Access denied")
+		return
+	}
+	if errors.Is(err, services.ErrJobFinished) {
+		RespondWithConflictError(c, "Job has already finished")
+		return
+	}
+	if errors.Is(err, services.ErrJobNoResult) {
+		RespondWithConflictError(c, "Job has no result available")
+		return
+	}
+	if errors.Is(err, services.ErrWebhookNotFound) {
+		RespondWithNotFoundError(c, "Webhook not found")
+		return
+	}
+	if errors.Is(err, services.ErrWebhookDeliveryNotFound) {
+		RespondWithNotFoundError(c, "Webhook delivery not found")
+		return
+	}
+	if errors.Is(err, services.ErrWebhookAccess) {
+		RespondWithForbiddenError(c, "Access denied")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidWebhookURL) || errors.Is(err, services.ErrInvalidWebhookEvents) {
+		RespondWithValidationError(c, "Invalid webhook", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrUploadNotFound) {
+		RespondWithNotFoundError(c, "Upload not found")
+		return
+	}
+	if errors.Is(err, services.ErrUploadAccess) {
+		RespondWithForbiddenError(c, "Access denied")
+		return
+	}
+	if errors.Is(err, services.ErrUploadExpired) {
+		RespondWithError(c, http.StatusGone, NewStandardError(ErrCodeNotFound, "Upload has expired"))
+		return
+	}
+	if errors.Is(err, services.ErrUploadOffsetMismatch) {
+		RespondWithConflictError(c, "Upload offset does not match", err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrUploadIncomplete) {
+		RespondWithConflictError(c, "Upload is not complete")
+		return
+	}
+	if errors.Is(err, services.ErrBatchFilesTooLarge) {
+		RespondWithError(c, http.StatusRequestEntityTooLarge, NewStandardError(ErrCodeFileTooLarge, err.Error()))
+		return
+	}
+	if errors.Is(err, services.ErrUploadTooLarge) {
+		RespondWithError(c, http.StatusRequestEntityTooLarge, NewStandardError(ErrCodeFileTooLarge, err.Error()))
+		return
+	}
+	if errors.Is(err, services.ErrUploadQuotaExceeded) {
+		RespondWithError(c, http.StatusTooManyRequests, NewStandardError(ErrCodeRateLimitExceeded, "Too many pending uploads", err.Error()))
+		return
+	}
+	if errors.Is(err, services.ErrUploadChecksumMismatch) {
+		RespondWithError(c, StatusChecksumMismatch, NewStandardError(ErrCodeValidationFailed, "Upload checksum mismatch"))
+		return
+	}
+	if errors.Is(err, services.ErrInvalidUpload) {
+		RespondWithValidationError(c, "Invalid upload request", err.Error())
+		return
+	}
 
 	// Fallback to string matching for other service error messages
 	switch err.Error() {
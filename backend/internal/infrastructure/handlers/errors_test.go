@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"digital-signature-system/internal/domain/services"
 )
 
 func TestStandardError(t *testing.T) {
@@ -116,6 +119,96 @@ func TestMapServiceErrorToHTTP(t *testing.T) {
 			expectedStatus: http.StatusForbidden,
 			expectedCode:   ErrCodeForbidden,
 		},
+		{
+			name:           "duplicate document",
+			serviceError:   fmt.Errorf("%w as document doc-1 on 2026-05-04", services.ErrDuplicateDocument),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   ErrCodeConflict,
+		},
+		{
+			name:           "invalid duplicate policy",
+			serviceError:   services.ErrInvalidDuplicatePolicy,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "no document matches the file",
+			serviceError:   services.ErrNoMatchingDocument,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrCodeNotFound,
+		},
+		{
+			name:           "invalid verification file",
+			serviceError:   fmt.Errorf("%w: Invalid PDF file", services.ErrInvalidVerificationFile),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "access code required",
+			serviceError:   services.ErrAccessCodeRequired,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   ErrCodeAccessCodeRequired,
+		},
+		{
+			name:           "invalid access code",
+			serviceError:   services.ErrInvalidAccessCode,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   ErrCodeInvalidAccessCode,
+		},
+		{
+			name:           "invalid visibility",
+			serviceError:   fmt.Errorf("%w: \"secret\"", services.ErrInvalidVisibility),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "letter number scheme not found",
+			serviceError:   services.ErrLetterNumberSchemeNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   ErrCodeNotFound,
+		},
+		{
+			name:           "letter number scheme exists",
+			serviceError:   services.ErrLetterNumberSchemeExists,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   ErrCodeConflict,
+		},
+		{
+			name:           "invalid letter number scheme",
+			serviceError:   fmt.Errorf("%w: pattern must contain {seq}", services.ErrInvalidLetterNumberScheme),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "letter number required",
+			serviceError:   services.ErrLetterNumberRequired,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "letter number reserved by a scheme",
+			serviceError:   fmt.Errorf("%w: 007/PHY/2026 is numbered by scheme OUT", services.ErrLetterNumberReserved),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
+		{
+			name:           "batch files too large",
+			serviceError:   fmt.Errorf("%w of 100 bytes", services.ErrBatchFilesTooLarge),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   ErrCodeFileTooLarge,
+		},
+		{
+			name:           "too many pending uploads",
+			serviceError:   fmt.Errorf("%w: at most 5 uploads at a time", services.ErrUploadQuotaExceeded),
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   ErrCodeRateLimitExceeded,
+		},
+		{
+			name:           "invalid verification report",
+			serviceError:   fmt.Errorf("%w: unknown grouping \"hour\"", services.ErrInvalidAnalytics),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   ErrCodeValidationFailed,
+		},
 		{
 			name:           "unknown error",
 			serviceError:   errors.New("some unknown error"),
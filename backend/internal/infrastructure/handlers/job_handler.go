@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// JobHandler exposes background job status, cancellation and results
+type JobHandler struct {
+	jobService *services.JobService
+	validator  *validation.Validator
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// wantsAsync reports whether the client asked for the request to be queued
+func wantsAsync(c *gin.Context) bool {
+	if async, err := strconv.ParseBool(c.Query("async")); err == nil && async {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.GetHeader("Prefer")), "respond-async")
+}
+
+// jobStatusURL returns the polling URL for a job
+func jobStatusURL(jobID string) string {
+	return fmt.Sprintf("/api/jobs/%s", jobID)
+}
+
+// ListJobs handles GET /api/jobs
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 100 {
+			RespondWithValidationError(c, "Invalid limit parameter", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context(), userID.(string), limit)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetJob handles GET /api/jobs/:jobId
+func (h *JobHandler) GetJob(c *gin.Context) {
+	userID, jobID, ok := h.jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	response := gin.H{"job": job}
+	if job.ResultPath != "" {
+		response["result_url"] = fmt.Sprintf("/api/jobs/%s/result", job.ID)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelJob handles POST /api/jobs/:jobId/cancel
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	userID, jobID, ok := h.jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.CancelJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+	logging.LogDocumentOperation(
+		logging.AuditEventJobCancel,
+		authUser.ID,
+		authUser.Username,
+		"",
+		c.ClientIP(),
+		"SUCCESS",
+		addAPIKeyDetails(map[string]interface{}{
+			"job_id":   job.ID,
+			"job_type": job.Type,
+			"status":   job.Status,
+			"endpoint": "/api/jobs/cancel",
+		}, authUser),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"job":     job,
+		"message": "Cancellation requested",
+	})
+}
+
+// DownloadResult handles GET /api/jobs/:jobId/result
+func (h *JobHandler) DownloadResult(c *gin.Context) {
+	userID, jobID, ok := h.jobParams(c)
+	if !ok {
+		return
+	}
+
+	resultPath, err := h.jobService.GetJobResultPath(c.Request.Context(), userID, jobID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.FileAttachment(resultPath, fmt.Sprintf("job_%s%s", jobID, filepath.Ext(resultPath)))
+}
+
+// jobParams extracts the authenticated user and validated job ID
+func (h *JobHandler) jobParams(c *gin.Context) (string, string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return "", "", false
+	}
+
+	jobID := c.Param("jobId")
+	if _, validationErr := h.validator.ValidateUUID("job_id", jobID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid job ID", validationErr.Error())
+		return "", "", false
+	}
+
+	return userID.(string), jobID, true
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// LetterNumberHandler manages organizations' letter numbering schemes
+type LetterNumberHandler struct {
+	letterNumberService *services.LetterNumberService
+	validator           *validation.Validator
+}
+
+// NewLetterNumberHandler creates a new letter number handler
+func NewLetterNumberHandler(letterNumberService *services.LetterNumberService) *LetterNumberHandler {
+	return &LetterNumberHandler{
+		letterNumberService: letterNumberService,
+		validator:           validation.NewValidator(),
+	}
+}
+
+// ListSchemes handles GET /api/organizations/:orgId/letter-number-schemes
+func (h *LetterNumberHandler) ListSchemes(c *gin.Context) {
+	orgID, ok := h.param(c, "orgId", "organization_id")
+	if !ok {
+		return
+	}
+
+	schemes, err := h.letterNumberService.ListSchemes(c.Request.Context(), c.GetString("user_id"), orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schemes": schemes})
+}
+
+// CreateScheme handles POST /api/organizations/:orgId/letter-number-schemes
+func (h *LetterNumberHandler) CreateScheme(c *gin.Context) {
+	orgID, ok := h.param(c, "orgId", "organization_id")
+	if !ok {
+		return
+	}
+
+	var req services.LetterNumberSchemeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	scheme, err := h.letterNumberService.CreateScheme(c.Request.Context(), c.GetString("user_id"), orgID, &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventLetterNumberSchemeCreate, map[string]interface{}{
+		"organization_id": orgID,
+		"scheme_id":       scheme.ID,
+		"code":            scheme.Code,
+		"pattern":         scheme.Pattern,
+		"is_default":      scheme.IsDefault,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"scheme": scheme})
+}
+
+// UpdateScheme handles PUT /api/organizations/:orgId/letter-number-schemes/:schemeId
+func (h *LetterNumberHandler) UpdateScheme(c *gin.Context) {
+	orgID, ok := h.param(c, "orgId", "organization_id")
+	if !ok {
+		return
+	}
+	schemeID, ok := h.param(c, "schemeId", "scheme_id")
+	if !ok {
+		return
+	}
+
+	var req services.LetterNumberSchemeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	scheme, err := h.letterNumberService.UpdateScheme(c.Request.Context(), c.GetString("user_id"), orgID, schemeID, &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventLetterNumberSchemeUpdate, map[string]interface{}{
+		"organization_id": orgID,
+		"scheme_id":       scheme.ID,
+		"pattern":         scheme.Pattern,
+		"is_default":      scheme.IsDefault,
+		"is_active":       scheme.IsActive,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"scheme": scheme})
+}
+
+// NextLetterNumber handles GET /api/organizations/:orgId/letter-number-schemes/:schemeId/next.
+// The number is a preview; it is allocated when a document is signed.
+func (h *LetterNumberHandler) NextLetterNumber(c *gin.Context) {
+	orgID, ok := h.param(c, "orgId", "organization_id")
+	if !ok {
+		return
+	}
+	schemeID, ok := h.param(c, "schemeId", "scheme_id")
+	if !ok {
+		return
+	}
+
+	letterNumber, err := h.letterNumberService.NextLetterNumber(c.Request.Context(), c.GetString("user_id"), orgID, schemeID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"letter_number": letterNumber})
+}
+
+// param returns the named UUID path parameter
+func (h *LetterNumberHandler) param(c *gin.Context, name, field string) (string, bool) {
+	value := c.Param(name)
+	if _, validationErr := h.validator.ValidateUUID(field, value, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid "+field, validationErr.Error())
+		return "", false
+	}
+	return value, true
+}
+
+func (h *LetterNumberHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	actor, _ := c.Get("user")
+	authUser := actor.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "letter_number_scheme", c.ClientIP(), "SUCCESS", details)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain gives NewServer a signing key and runs the package in a temporary
+// directory, so the server's logs directory is not written into the tree.
+// Without a key NewServer exits the test binary before any test reports.
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "handlers-test-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create test directory: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeTestKeys(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write test keys: %v\n", err)
+		return 1
+	}
+	// Keys in the environment would take precedence over the files
+	for _, name := range []string{"RSA_PRIVATE_KEY", "PRIVATE_KEY", "RSA_PUBLIC_KEY", "PUBLIC_KEY"} {
+		os.Unsetenv(name)
+	}
+	os.Setenv("PRIVATE_KEY_PATH", filepath.Join(dir, "private_key.pem"))
+	os.Setenv("PUBLIC_KEY_PATH", filepath.Join(dir, "public_key.pem"))
+
+	if err := os.Chdir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to enter test directory: %v\n", err)
+		return 1
+	}
+	return m.Run()
+}
+
+func writeTestKeys(dir string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	if err := os.WriteFile(filepath.Join(dir, "private_key.pem"), privatePEM, 0o600); err != nil {
+		return err
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return os.WriteFile(filepath.Join(dir, "public_key.pem"), publicPEM, 0o644)
+}
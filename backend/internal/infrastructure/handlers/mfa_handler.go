@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+)
+
+// MFAHandler handles two-factor enrollment and recovery codes for the current user
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+// MFACodeRequest carries a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// GetStatus handles GET /api/mfa
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.mfaService.Status(c.Request.Context(), userID.(string))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// BeginEnrollment handles POST /api/mfa/enroll
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(c.Request.Context(), userID.(string))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmEnrollment handles POST /api/mfa/enroll/confirm
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.ConfirmEnrollment(c.Request.Context(), userID, code)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventMFAEnroll)
+
+	// Recovery codes are only ever returned here and on regeneration
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": recoveryCodes,
+		"message":        "Two-factor authentication enabled; store the recovery codes now, they will not be shown again",
+	})
+}
+
+// Disable handles POST /api/mfa/disable
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID, code); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventMFADisable)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles POST /api/mfa/recovery-codes
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, code, ok := h.codeRequest(c)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID, code)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventMFARecovery)
+
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": recoveryCodes,
+		"message":        "Recovery codes replaced; the previous codes no longer work",
+	})
+}
+
+func (h *MFAHandler) codeRequest(c *gin.Context) (string, string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return "", "", false
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return "", "", false
+	}
+	code := strings.TrimSpace(req.Code)
+	if code == "" || len(code) > 32 {
+		RespondWithValidationError(c, "Invalid code")
+		return "", "", false
+	}
+
+	return userID.(string), code, true
+}
+
+func (h *MFAHandler) audit(c *gin.Context, event logging.AuditEvent) {
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+
+	logging.LogAuthentication(
+		event,
+		authUser.ID,
+		authUser.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		map[string]interface{}{
+			"endpoint": c.FullPath(),
+		},
+	)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/crypto"
+	"digital-signature-system/internal/infrastructure/database"
+)
+
+func TestMFAHandler_EnrollAndLogin(t *testing.T) {
+	db := setupTestDB(t)
+	server := NewServer(&config.Config{
+		JWTSecret:             "test-secret-key",
+		Environment:           "test",
+		MFAIssuer:             "Digital Signature System",
+		MFAChallengeTTL:       5 * time.Minute,
+		MFARequiredForSigning: true,
+		MaxPDFSize:            10 << 20,
+	}, db)
+
+	authService := services.NewAuthService(database.NewUserRepository(db), database.NewSessionRepository(db), "test-secret-key")
+	_, err := authService.Register(context.Background(), services.RegisterRequest{
+		Username: "signer",
+		Password: "Password123!",
+		FullName: "Signer",
+		Email:    "signer@example.com",
+	})
+	require.NoError(t, err)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	var login services.LoginResponse
+	w := send("POST", "/api/auth/login", "", LoginRequest{Username: "signer", Password: "Password123!"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	assert.True(t, login.MFAEnrollmentRequired)
+
+	// Policy keeps a password-only session from signing
+	w = send("POST", "/api/documents/sign", login.Token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeMFARequired)
+
+	var enrollment services.MFAEnrollment
+	w = send("POST", "/api/mfa/enroll", login.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	assert.NotEmpty(t, enrollment.QRCode)
+
+	step := crypto.TOTPStep(time.Now())
+	code, err := crypto.TOTPCode(enrollment.Secret, step)
+	require.NoError(t, err)
+
+	var confirmed struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	w = send("POST", "/api/mfa/enroll/confirm", login.Token, MFACodeRequest{Code: code})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &confirmed))
+	assert.Len(t, confirmed.RecoveryCodes, 10)
+
+	// Disabling is refused while the policy is on
+	w = send("POST", "/api/mfa/disable", login.Token, MFACodeRequest{Code: confirmed.RecoveryCodes[0]})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var challenge services.LoginResponse
+	w = send("POST", "/api/auth/login", "", LoginRequest{Username: "signer", Password: "Password123!"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	require.True(t, challenge.MFARequired)
+	assert.Empty(t, challenge.Token)
+
+	// The code used to confirm enrollment cannot be replayed
+	w = send("POST", "/api/auth/login/mfa", "", LoginMFARequest{MFAToken: challenge.MFAToken, Code: code})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeInvalidMFACode)
+
+	var session services.LoginResponse
+	w = send("POST", "/api/auth/login/mfa", "", LoginMFARequest{MFAToken: challenge.MFAToken, Code: confirmed.RecoveryCodes[1]})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	require.NotEmpty(t, session.Token)
+
+	// The second factor session passes the policy and reaches upload validation
+	w = send("POST", "/api/documents/sign", session.Token, nil)
+	assert.NotEqual(t, http.StatusForbidden, w.Code)
+
+	var status services.MFAStatus
+	w = send("GET", "/api/mfa", session.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(t, status.Enabled)
+	assert.Equal(t, int64(9), status.RecoveryCodesRemaining)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+)
+
+// OIDCHandler handles single sign-on through OpenID Connect providers
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCHandler creates a new OIDC handler
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// ListProviders handles GET /api/auth/oidc/providers
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.oidcService.Providers()})
+}
+
+// Authorize handles POST /api/auth/oidc/:provider/authorize
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authorization, err := h.oidcService.BeginLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, authorization)
+}
+
+// Callback handles POST /api/auth/oidc/callback with the provider's authorization response
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req services.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	if len(req.State) > 256 || len(req.Code) > 2048 {
+		RespondWithValidationError(c, "Invalid authorization response")
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.GetHeader("User-Agent")
+
+	response, err := h.oidcService.CompleteLogin(c.Request.Context(), req)
+	if err != nil {
+		logging.LogAuthentication(
+			logging.AuditEventAuthFailure,
+			"",
+			"",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"FAILURE",
+			map[string]interface{}{
+				"error":    err.Error(),
+				"endpoint": "/api/auth/oidc/callback",
+			},
+		)
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	// The provider vouched for the user but a local second factor is still needed
+	if response.MFARequired {
+		logging.LogAuthentication(
+			logging.AuditEventMFAChallenge,
+			"",
+			"",
+			c.ClientIP(),
+			c.GetHeader("User-Agent"),
+			"PENDING",
+			map[string]interface{}{
+				"endpoint": "/api/auth/oidc/callback",
+			},
+		)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	logging.LogAuthentication(
+		logging.AuditEventLogin,
+		response.User.ID,
+		response.User.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		map[string]interface{}{
+			"endpoint": "/api/auth/oidc/callback",
+			"method":   "oidc",
+		},
+	)
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// OrganizationHandler manages organizations, their members and signing keys
+type OrganizationHandler struct {
+	orgService *services.OrganizationService
+	validator  *validation.Validator
+}
+
+// SetMemberRequest names the role to give an organization member
+type SetMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgService: orgService,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// CreateOrganization handles POST /api/admin/organizations
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req services.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	if req.AdminUserID != "" {
+		if _, validationErr := h.validator.ValidateUUID("admin_user_id", req.AdminUserID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+			return
+		}
+	}
+
+	org, err := h.orgService.CreateOrganization(c.Request.Context(), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventOrganizationCreate, map[string]interface{}{
+		"organization_id": org.ID,
+		"slug":            org.Slug,
+		"admin_user_id":   req.AdminUserID,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"organization": org})
+}
+
+// ListAllOrganizations handles GET /api/admin/organizations
+func (h *OrganizationHandler) ListAllOrganizations(c *gin.Context) {
+	orgs, err := h.orgService.ListOrganizations(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+// ListOrganizations handles GET /api/organizations and returns the caller's memberships
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	memberships, err := h.orgService.ListUserOrganizations(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"memberships": memberships})
+}
+
+// GetOrganization handles GET /api/organizations/:orgId
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgService.GetOrganizationForUser(c.Request.Context(), c.GetString("user_id"), orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization": org})
+}
+
+// UpdateOrganization handles PUT /api/organizations/:orgId
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	var req services.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	org, err := h.orgService.UpdateOrganization(c.Request.Context(), c.GetString("user_id"), orgID, &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventOrganizationUpdate, map[string]interface{}{
+		"organization_id": org.ID,
+		"is_active":       org.IsActive,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"organization": org})
+}
+
+// ListMembers handles GET /api/organizations/:orgId/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgService.ListMembers(c.Request.Context(), c.GetString("user_id"), orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// SetMember handles PUT /api/organizations/:orgId/members/:userId
+func (h *OrganizationHandler) SetMember(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+		return
+	}
+
+	var req SetMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	member, err := h.orgService.SetMember(c.Request.Context(), c.GetString("user_id"), orgID, userID, req.Role)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventOrganizationMemberSet, map[string]interface{}{
+		"organization_id": orgID,
+		"target_user_id":  userID,
+		"role":            member.Role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+// RemoveMember handles DELETE /api/organizations/:orgId/members/:userId
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+		return
+	}
+
+	if err := h.orgService.RemoveMember(c.Request.Context(), c.GetString("user_id"), orgID, userID); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventOrganizationMemberRemove, map[string]interface{}{
+		"organization_id": orgID,
+		"target_user_id":  userID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// ListKeys handles GET /api/organizations/:orgId/keys
+func (h *OrganizationHandler) ListKeys(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.orgService.ListKeys(c.Request.Context(), c.GetString("user_id"), orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// RotateKey handles POST /api/organizations/:orgId/keys/rotate
+func (h *OrganizationHandler) RotateKey(c *gin.Context) {
+	orgID, ok := h.orgID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.orgService.RotateKey(c.Request.Context(), c.GetString("user_id"), orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventOrganizationKeyRotate, map[string]interface{}{
+		"organization_id": orgID,
+		"key_id":          key.KeyID,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"key": key})
+}
+
+func (h *OrganizationHandler) orgID(c *gin.Context) (string, bool) {
+	orgID := c.Param("orgId")
+	if _, validationErr := h.validator.ValidateUUID("organization_id", orgID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid organization ID", validationErr.Error())
+		return "", false
+	}
+	return orgID, true
+}
+
+func (h *OrganizationHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	actor, _ := c.Get("user")
+	authUser := actor.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "organization", c.ClientIP(), "SUCCESS", details)
+}
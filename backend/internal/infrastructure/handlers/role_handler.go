@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// RoleHandler lets admins manage roles and assign them to users
+type RoleHandler struct {
+	rbacService *services.RBACService
+	validator   *validation.Validator
+}
+
+// AssignRoleRequest names the role to give a user
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(rbacService *services.RBACService) *RoleHandler {
+	return &RoleHandler{
+		rbacService: rbacService,
+		validator:   validation.NewValidator(),
+	}
+}
+
+// ListRoles handles GET /api/admin/roles
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roles":       roles,
+		"permissions": entities.Permissions,
+	})
+}
+
+// CreateRole handles POST /api/admin/roles
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req services.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(c.Request.Context(), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventRoleCreate, map[string]interface{}{
+		"role":        role.Name,
+		"permissions": role.Permissions,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"role": role})
+}
+
+// UpdateRole handles PUT /api/admin/roles/:name
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req services.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	role, err := h.rbacService.UpdateRole(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventRoleUpdate, map[string]interface{}{
+		"role":        role.Name,
+		"permissions": role.Permissions,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
+
+// DeleteRole handles DELETE /api/admin/roles/:name
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.rbacService.DeleteRole(c.Request.Context(), name); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventRoleDelete, map[string]interface{}{
+		"role": name,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// ListRoleUsers handles GET /api/admin/roles/:name/users
+func (h *RoleHandler) ListRoleUsers(c *gin.Context) {
+	users, err := h.rbacService.ListRoleUsers(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// AssignRole handles PUT /api/admin/users/:userId/role
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	user, previous, err := h.rbacService.AssignRole(c.Request.Context(), userID, req.Role)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventRoleAssign, map[string]interface{}{
+		"target_user_id":  user.ID,
+		"target_username": user.Username,
+		"previous_role":   previous,
+		"role":            user.Role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+func (h *RoleHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "role", c.ClientIP(), "SUCCESS", details)
+}
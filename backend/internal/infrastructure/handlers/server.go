@@ -1,28 +1,71 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/repositories"
 	"digital-signature-system/internal/domain/services"
 	"digital-signature-system/internal/infrastructure/crypto"
 	"digital-signature-system/internal/infrastructure/database"
+	"digital-signature-system/internal/infrastructure/geoip"
 	"digital-signature-system/internal/infrastructure/logging"
 	"digital-signature-system/internal/infrastructure/pdf"
+	"digital-signature-system/internal/infrastructure/ratelimit"
 )
 
+// batchUploadTypes lists the content types accepted by the batch signing endpoint
+var batchUploadTypes = []string{
+	"application/pdf",
+	"application/zip",
+	"application/x-zip-compressed",
+	"text/csv",
+	"application/json",
+}
+
 type Server struct {
-	config              *config.Config
-	db                  *gorm.DB
-	router              *gin.Engine
-	authService         *services.AuthService
-	documentService     *services.DocumentService
-	verificationService *services.VerificationService
-	authHandler         *AuthHandler
-	documentHandler     *DocumentHandler
-	verificationHandler *VerificationHandler
-	authMiddleware      *AuthMiddleware
+	config                *config.Config
+	db                    *gorm.DB
+	router                *gin.Engine
+	authService           *services.AuthService
+	documentService       *services.DocumentService
+	verificationService   *services.VerificationService
+	batchService          *services.BatchService
+	jobService            *services.JobService
+	jobRunner             *services.JobRunner
+	webhookDispatcher     *services.WebhookDispatcher
+	uploadService         *services.UploadService
+	loginGuard            *services.LoginGuard
+	scheduler             *services.Scheduler
+	mfaService            *services.MFAService
+	oidcService           *services.OIDCService
+	apiKeyService         *services.APIKeyService
+	rbacService           *services.RBACService
+	authHandler           *AuthHandler
+	documentHandler       *DocumentHandler
+	verificationHandler   *VerificationHandler
+	analyticsHandler      *VerificationAnalyticsHandler
+	batchHandler          *BatchHandler
+	jobHandler            *JobHandler
+	webhookHandler        *WebhookHandler
+	uploadHandler         *UploadHandler
+	adminHandler          *AdminHandler
+	mfaHandler            *MFAHandler
+	webAuthnHandler       *WebAuthnHandler
+	sessionHandler        *SessionHandler
+	oidcHandler           *OIDCHandler
+	serviceAccountHandler *ServiceAccountHandler
+	roleHandler           *RoleHandler
+	organizationHandler   *OrganizationHandler
+	userAdminHandler      *UserAdminHandler
+	delegationHandler     *DelegationHandler
+	letterNumberHandler   *LetterNumberHandler
+	authMiddleware        *AuthMiddleware
+	rateLimiter           *ratelimit.Limiter
 }
 
 func NewServer(cfg *config.Config, db *gorm.DB) *Server {
@@ -49,6 +92,23 @@ func NewServer(cfg *config.Config, db *gorm.DB) *Server {
 	sessionRepo := database.NewSessionRepository(db)
 	documentRepo := database.NewDocumentRepository(db)
 	verificationLogRepo := database.NewVerificationLogRepository(db)
+	batchJobRepo := database.NewBatchJobRepository(db)
+	jobRepo := database.NewJobRepository(db)
+	webhookSubscriptionRepo := database.NewWebhookSubscriptionRepository(db)
+	webhookDeliveryRepo := database.NewWebhookDeliveryRepository(db)
+	uploadSessionRepo := database.NewUploadSessionRepository(db)
+	loginThrottleRepo := database.NewLoginThrottleRepository(db)
+	mfaRepo := database.NewMFARepository(db)
+	webAuthnRepo := database.NewWebAuthnRepository(db)
+	maintenanceRunRepo := database.NewMaintenanceRunRepository(db)
+	lockRepo := database.NewLockRepository(db)
+	oidcRepo := database.NewOIDCRepository(db)
+	apiKeyRepo := database.NewAPIKeyRepository(db)
+	roleRepo := database.NewRoleRepository(db)
+	orgRepo := database.NewOrganizationRepository(db)
+	invitationRepo := database.NewInvitationRepository(db)
+	delegationRepo := database.NewDelegationRepository(db)
+	letterNumberRepo := database.NewLetterNumberRepository(db)
 
 	// Initialize crypto services
 	keyManager, err := crypto.NewKeyManager()
@@ -61,30 +121,202 @@ func NewServer(cfg *config.Config, db *gorm.DB) *Server {
 		logger.Fatal("Failed to initialize signature service: %v", err)
 	}
 
-	pdfService := pdf.NewPDFService()
+	pdfService := pdf.NewPDFServiceWithLimits(cfg.MaxPDFSize, cfg.UploadTempDir)
 
 	// Initialize services
 	authService := services.NewAuthService(userRepo, sessionRepo, cfg.JWTSecret)
 	documentService := services.NewDocumentService(documentRepo, signatureService, pdfService, cfg)
 	verificationService := services.NewVerificationService(documentRepo, verificationLogRepo, signatureService, pdfService, documentService)
+	batchService := services.NewBatchService(batchJobRepo, documentService, documentService, cfg)
+	jobService := services.NewJobService(jobRepo, cfg)
+	webhookService := services.NewWebhookService(webhookSubscriptionRepo, webhookDeliveryRepo, cfg)
+	uploadService := services.NewUploadService(uploadSessionRepo, cfg)
+	loginGuard := services.NewLoginGuard(loginThrottleRepo, userRepo, cfg)
+	mfaService, err := services.NewMFAService(mfaRepo, userRepo, pdfService, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize MFA service: %v", err)
+	}
+
+	oidcService, err := services.NewOIDCService(oidcRepo, userRepo, authService, cfg)
+	if err != nil {
+		logger.Fatal("Invalid OIDC configuration: %v", err)
+	}
+
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, cfg)
+	rbacService := services.NewRBACService(roleRepo, userRepo, cfg)
+	if err := rbacService.EnsureBuiltInRoles(context.Background()); err != nil {
+		logger.Fatal("Failed to initialize roles: %v", err)
+	}
+	userAdminService := services.NewUserAdminService(userRepo, sessionRepo, invitationRepo, rbacService, cfg)
+	orgService, err := services.NewOrganizationService(orgRepo, userRepo, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize organization service: %v", err)
+	}
+	delegationService := services.NewDelegationService(delegationRepo, userRepo, cfg)
+
+	// Access tokens are short lived and renewed through the session's refresh token
+	authService.SetTokenLifetimes(cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	// Failed logins are counted per username and address
+	authService.SetLoginGuard(loginGuard)
+	// Signup is open, invite-only or closed to the public
+	if err := authService.SetRegistration(cfg.RegistrationMode, invitationRepo); err != nil {
+		logger.Fatal("Invalid registration configuration: %v", err)
+	}
+	// Users with two-factor enabled log in in two steps
+	authService.SetMFAService(mfaService)
+	// Directory accounts sign in with their directory password once local
+	// accounts have been checked
+	if cfg.LDAP.URL != "" {
+		ldapVerifier, err := services.NewLDAPVerifier(cfg.LDAP, userRepo, oidcRepo)
+		if err != nil {
+			logger.Fatal("Invalid LDAP configuration: %v", err)
+		}
+		authService.AddCredentialVerifier(ldapVerifier)
+	}
+	// Users with a security key confirm every signing request with it; keys are
+	// unavailable when no relying party is configured
+	if cfg.WebAuthnRPID != "" {
+		if err := authService.SetWebAuthn(webAuthnRepo, cfg); err != nil {
+			logger.Fatal("Invalid WebAuthn configuration: %v", err)
+		}
+	}
+
+	// Document and verification events are written to the webhook outbox
+	documentService.SetEventPublisher(webhookService)
+	verificationService.SetEventPublisher(webhookService)
+	// Organization admins manage the organization's webhooks, which also receive its documents' events
+	webhookService.SetOrganizations(orgService)
+	// Roles granting document:read:any can read every user's documents
+	documentService.SetPermissionChecker(rbacService)
+	orgService.SetPermissionChecker(rbacService)
+	// Documents signed for an organization use its key, issuer and stamp branding
+	documentService.SetOrganizations(orgService)
+	verificationService.SetOrganizations(orgService)
+	// Delegates sign on behalf of the users who granted them a delegation, and
+	// verification names both
+	delegationService.SetPermissionChecker(rbacService)
+	delegationService.SetOrganizations(orgService)
+	documentService.SetDelegations(delegationService)
+	verificationService.SetDelegations(delegationService)
+	// Verifications record the verifier's country and raise forgery alerts
+	verificationService.SetTamperAlerts(cfg.TamperAlertThreshold, cfg.TamperAlertWindow)
+	if cfg.GeoIPDatabase != "" {
+		geoIP, err := geoip.Open(cfg.GeoIPDatabase)
+		if err != nil {
+			logger.Fatal("Failed to load GeoIP database: %v", err)
+		}
+		verificationService.SetGeoIP(geoIP)
+	}
+	verificationAnalyticsService := services.NewVerificationAnalyticsService(verificationLogRepo, cfg.TamperAlertThreshold, cfg.TamperAlertWindow)
+	verificationAnalyticsService.SetPermissionChecker(rbacService)
+	// Organizations' documents signed without a letter number are numbered by a scheme
+	letterNumberService := services.NewLetterNumberService(letterNumberRepo, orgService)
+	documentService.SetLetterNumbers(letterNumberService)
+
+	// Background workers are started by Run
+	jobRunner := services.NewJobRunner(jobRepo, cfg)
+	jobRunner.Register(services.JobTypeSignDocument, services.NewSignDocumentJobHandler(documentService, documentService))
+	jobRunner.Register(services.JobTypeBatchSign, services.NewBatchJobHandler(batchService))
+	webhookDispatcher := services.NewWebhookDispatcher(webhookSubscriptionRepo, webhookDeliveryRepo, signatureService, cfg)
+	scheduler := services.NewScheduler(maintenanceRunRepo, lockRepo, cfg)
+	registerTask := func(name, spec string, run services.MaintenanceTaskFunc) {
+		if err := scheduler.Register(name, spec, run); err != nil {
+			logger.Fatal("Invalid maintenance schedule: %v", err)
+		}
+	}
+	registerTask(services.MaintenanceTaskSessionCleanup, cfg.SessionCleanupSchedule, services.NewSessionCleanupTask(authService))
+	// Retention and key age tasks are switched off with a zero period
+	if cfg.VerificationLogRetention > 0 {
+		registerTask(services.MaintenanceTaskVerificationLogs, cfg.VerificationLogSchedule,
+			services.NewVerificationLogRetentionTask(verificationLogRepo, cfg.VerificationLogRetention))
+	}
+	if cfg.AuditLogRetention > 0 {
+		registerTask(services.MaintenanceTaskAuditLogs, cfg.AuditLogSchedule,
+			services.NewAuditLogRetentionTask("logs", cfg.AuditLogRetention))
+	}
+	if cfg.KeyMaxAge > 0 {
+		registerTask(services.MaintenanceTaskKeyAgeCheck, cfg.KeyAgeCheckSchedule,
+			services.NewKeyAgeCheckTask(keyManager, cfg.KeyMaxAge))
+	}
 
 	// Initialize handlers and middleware
 	authHandler := NewAuthHandler(authService)
-	documentHandler := NewDocumentHandler(documentService)
-	verificationHandler := NewVerificationHandler(verificationService)
-	authMiddleware := NewAuthMiddleware(authService, cfg)
+	documentHandler := NewDocumentHandler(documentService, jobService, uploadService, authService)
+	verificationHandler := NewVerificationHandler(verificationService, uploadService)
+	verificationAnalyticsHandler := NewVerificationAnalyticsHandler(verificationAnalyticsService)
+	batchHandler := NewBatchHandler(batchService, jobService, authService, cfg.BatchMaxSize, cfg.MaxPDFSize, cfg.UploadTempDir)
+	jobHandler := NewJobHandler(jobService)
+	webhookHandler := NewWebhookHandler(webhookService)
+	uploadHandler := NewUploadHandler(uploadService)
+	adminHandler := NewAdminHandler(loginGuard, scheduler)
+	mfaHandler := NewMFAHandler(mfaService)
+	webAuthnHandler := NewWebAuthnHandler(authService)
+	sessionHandler := NewSessionHandler(authService)
+	oidcHandler := NewOIDCHandler(oidcService)
+	serviceAccountHandler := NewServiceAccountHandler(apiKeyService)
+	roleHandler := NewRoleHandler(rbacService)
+	organizationHandler := NewOrganizationHandler(orgService)
+	userAdminHandler := NewUserAdminHandler(userAdminService)
+	delegationHandler := NewDelegationHandler(delegationService)
+	letterNumberHandler := NewLetterNumberHandler(letterNumberService)
+	rateLimiter, err := ratelimit.NewFromConfig(context.Background(), cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize rate limiter: %v", err)
+	}
+	authMiddleware := NewAuthMiddleware(authService, cfg, rateLimiter)
+	// Service accounts call the API with keys instead of logging in
+	authMiddleware.SetAPIKeyService(apiKeyService)
+	// Routes are guarded by the permissions of the caller's role
+	authMiddleware.SetRBACService(rbacService)
+	// Requests are scoped to the caller's organization
+	authMiddleware.SetOrganizationService(orgService)
 
 	server := &Server{
-		config:              cfg,
-		db:                  db,
-		router:              gin.Default(),
-		authService:         authService,
-		documentService:     documentService,
-		verificationService: verificationService,
-		authHandler:         authHandler,
-		documentHandler:     documentHandler,
-		verificationHandler: verificationHandler,
-		authMiddleware:      authMiddleware,
+		config:                cfg,
+		db:                    db,
+		router:                gin.Default(),
+		authService:           authService,
+		documentService:       documentService,
+		verificationService:   verificationService,
+		batchService:          batchService,
+		jobService:            jobService,
+		jobRunner:             jobRunner,
+		webhookDispatcher:     webhookDispatcher,
+		uploadService:         uploadService,
+		loginGuard:            loginGuard,
+		scheduler:             scheduler,
+		mfaService:            mfaService,
+		oidcService:           oidcService,
+		apiKeyService:         apiKeyService,
+		rbacService:           rbacService,
+		authHandler:           authHandler,
+		documentHandler:       documentHandler,
+		verificationHandler:   verificationHandler,
+		analyticsHandler:      verificationAnalyticsHandler,
+		batchHandler:          batchHandler,
+		jobHandler:            jobHandler,
+		webhookHandler:        webhookHandler,
+		uploadHandler:         uploadHandler,
+		adminHandler:          adminHandler,
+		mfaHandler:            mfaHandler,
+		webAuthnHandler:       webAuthnHandler,
+		sessionHandler:        sessionHandler,
+		oidcHandler:           oidcHandler,
+		serviceAccountHandler: serviceAccountHandler,
+		roleHandler:           roleHandler,
+		organizationHandler:   organizationHandler,
+		userAdminHandler:      userAdminHandler,
+		delegationHandler:     delegationHandler,
+		letterNumberHandler:   letterNumberHandler,
+		authMiddleware:        authMiddleware,
+		rateLimiter:           rateLimiter,
+	}
+
+	// Without trusted proxies every client behind one would share its IP's rate limit
+	if proxies := cfg.GetTrustedProxies(); proxies != nil {
+		if err := server.router.SetTrustedProxies(proxies); err != nil {
+			logger.Fatal("Invalid trusted proxies: %v", err)
+		}
 	}
 
 	server.setupMiddleware()
@@ -107,7 +339,7 @@ func (s *Server) setupMiddleware() {
 	// Add request logging middleware
 	s.router.Use(s.authMiddleware.RequestLogging())
 
-	// Add rate limiting middleware
+	// Add per-client rate limiting; stricter route policies are attached in setupRoutes
 	s.router.Use(s.authMiddleware.RateLimiting())
 }
 
@@ -125,54 +357,261 @@ func (s *Server) setupRoutes() {
 			c.JSON(200, gin.H{"message": "pong"})
 		})
 
+		// tus discovery is answered without authentication
+		api.OPTIONS("/documents/sign/uploads", s.uploadHandler.Options)
+		api.OPTIONS("/verify/:docId/uploads", s.uploadHandler.Options)
+
 		// Authentication routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", s.authHandler.Login)
-			auth.POST("/register", s.authHandler.Register)
+			auth.POST("/login", s.authMiddleware.RateLimit(ratelimit.RouteLogin), s.authHandler.Login)
+			auth.POST("/login/mfa", s.authMiddleware.RateLimit(ratelimit.RouteLogin), s.authHandler.LoginMFA)
+			auth.POST("/register", s.authMiddleware.RateLimit(ratelimit.RouteRegister), s.authHandler.Register)
+			auth.GET("/registration", s.authHandler.GetRegistration)
+			auth.POST("/refresh", s.authMiddleware.RateLimit(ratelimit.RouteAPI), s.authHandler.Refresh)
 			auth.POST("/logout", s.authHandler.Logout)
+			// Single sign-on with OpenID Connect providers
+			auth.GET("/oidc/providers", s.oidcHandler.ListProviders)
+			auth.POST("/oidc/:provider/authorize", s.authMiddleware.RateLimit(ratelimit.RouteLogin), s.oidcHandler.Authorize)
+			auth.POST("/oidc/callback", s.authMiddleware.RateLimit(ratelimit.RouteLogin), s.oidcHandler.Callback)
 			auth.GET("/me", s.authMiddleware.RequireAuth(), s.authHandler.GetProfile)
 		}
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(s.authMiddleware.RequireAuth())
+		protected.Use(s.authMiddleware.RequireAuth(), s.authMiddleware.RateLimit(ratelimit.RouteAPI), s.authMiddleware.OrganizationContext())
 		{
 			// User profile routes
 			protected.GET("/profile", s.authHandler.GetProfile)
 			protected.POST("/change-password", s.authHandler.ChangePassword)
 
+			// The current user's sessions on other devices
+			sessions := protected.Group("/sessions")
+			{
+				sessions.GET("", s.sessionHandler.ListSessions)
+				sessions.DELETE("", s.sessionHandler.RevokeOtherSessions)
+				sessions.DELETE("/:id", s.sessionHandler.RevokeSession)
+			}
+
+			// Two-factor enrollment for the current user
+			mfa := protected.Group("/mfa")
+			{
+				mfa.GET("", s.mfaHandler.GetStatus)
+				mfa.POST("/enroll", s.mfaHandler.BeginEnrollment)
+				mfa.POST("/enroll/confirm", s.mfaHandler.ConfirmEnrollment)
+				mfa.POST("/disable", s.mfaHandler.Disable)
+				mfa.POST("/recovery-codes", s.mfaHandler.RegenerateRecoveryCodes)
+			}
+
+			// Security keys and the assertions that confirm signing requests
+			webAuthn := protected.Group("/webauthn")
+			{
+				webAuthn.GET("/credentials", s.webAuthnHandler.ListCredentials)
+				webAuthn.DELETE("/credentials/:id", s.webAuthnHandler.DeleteCredential)
+				webAuthn.POST("/confirm/begin", s.webAuthnHandler.BeginConfirmation)
+				webAuthn.POST("/register/begin", s.authMiddleware.RequireMFA(), s.webAuthnHandler.BeginRegistration)
+				webAuthn.POST("/register/finish", s.webAuthnHandler.FinishRegistration)
+				webAuthn.POST("/sign/begin", s.webAuthnHandler.BeginSigning)
+				webAuthn.POST("/sign/batch/begin", s.webAuthnHandler.BeginBatchSigning)
+			}
+
 			// Document routes
 			documents := protected.Group("/documents")
 			{
 				// Add file validation for document signing (50MB max, PDF only)
 				documents.POST("/sign",
-					s.authMiddleware.FileValidation(50<<20, []string{"application/pdf"}),
+					s.authMiddleware.RequirePermission(entities.PermissionDocumentSign),
+					s.authMiddleware.RequireMFA(),
+					s.authMiddleware.RateLimit(ratelimit.RouteSign),
+					s.authMiddleware.FileValidation(s.config.MaxPDFSize, []string{"application/pdf"}),
 					s.documentHandler.SignDocument)
+				// Reports whether a PDF was signed before, without signing it
+				documents.POST("/duplicates",
+					s.authMiddleware.RequirePermission(entities.PermissionDocumentSign),
+					s.authMiddleware.RateLimit(ratelimit.RouteSign),
+					s.authMiddleware.FileValidation(s.config.MaxPDFSize, []string{"application/pdf"}),
+					s.documentHandler.CheckDuplicates)
+				// Resumable (tus) uploads that feed /sign via upload_id
+				documents.POST("/sign/uploads", s.authMiddleware.RequirePermission(entities.PermissionDocumentSign), s.authMiddleware.RequireMFA(), s.authMiddleware.RateLimit(ratelimit.RouteSign), s.uploadHandler.CreateUpload)
+				documents.HEAD("/sign/uploads/:uploadId", s.uploadHandler.GetUploadOffset)
+				documents.PATCH("/sign/uploads/:uploadId", s.uploadHandler.PatchUpload)
+				documents.DELETE("/sign/uploads/:uploadId", s.uploadHandler.TerminateUpload)
+				// Batch signing accepts PDFs, ZIP archives and a CSV/JSON manifest
+				documents.POST("/batch",
+					s.authMiddleware.RequirePermission(entities.PermissionDocumentSign),
+					s.authMiddleware.RequireMFA(),
+					s.authMiddleware.RateLimit(ratelimit.RouteSign),
+					s.authMiddleware.FileValidation(s.config.BatchMaxSize, batchUploadTypes),
+					s.batchHandler.SignBatch)
+				documents.GET("/batch/:batchId", s.batchHandler.GetBatch)
+				documents.GET("/batch/:batchId/download", s.batchHandler.DownloadBatch)
 				documents.GET("/", s.documentHandler.GetDocuments)
+				documents.GET("/search", s.documentHandler.SearchDocuments)
 				documents.GET("/:id", s.documentHandler.GetDocument)
 				documents.GET("/:id/qr-code", s.documentHandler.DownloadQRCode)
 				documents.GET("/:id/download", s.documentHandler.DownloadSignedPDF)
-				documents.DELETE("/:id", s.documentHandler.DeleteDocument)
+				documents.DELETE("/:id", s.authMiddleware.RequirePermission(entities.PermissionDocumentRevoke), s.documentHandler.DeleteDocument)
 			}
 
 			// Add direct route without trailing slash to avoid redirects
 			protected.GET("/documents", s.documentHandler.GetDocuments)
+
+			// Background job routes
+			jobs := protected.Group("/jobs")
+			{
+				jobs.GET("", s.jobHandler.ListJobs)
+				jobs.GET("/:jobId", s.jobHandler.GetJob)
+				jobs.POST("/:jobId/cancel", s.jobHandler.CancelJob)
+				jobs.GET("/:jobId/result", s.jobHandler.DownloadResult)
+			}
+
+			// Webhook subscription and delivery routes
+			webhooks := protected.Group("/webhooks")
+			{
+				webhooks.GET("", s.webhookHandler.ListWebhooks)
+				webhooks.POST("", s.webhookHandler.CreateWebhook)
+				webhooks.GET("/dead-letters", s.webhookHandler.ListDeadLetters)
+				webhooks.POST("/deliveries/:deliveryId/redeliver", s.webhookHandler.Redeliver)
+				webhooks.GET("/:webhookId", s.webhookHandler.GetWebhook)
+				webhooks.PUT("/:webhookId", s.webhookHandler.UpdateWebhook)
+				webhooks.DELETE("/:webhookId", s.webhookHandler.DeleteWebhook)
+				webhooks.POST("/:webhookId/rotate-secret", s.webhookHandler.RotateWebhookSecret)
+				webhooks.GET("/:webhookId/deliveries", s.webhookHandler.ListDeliveries)
+			}
+
+			// Organization routes; members and settings are managed by organization admins
+			organizations := protected.Group("/organizations")
+			{
+				organizations.GET("", s.organizationHandler.ListOrganizations)
+				organizations.GET("/:orgId", s.organizationHandler.GetOrganization)
+				organizations.PUT("/:orgId", s.organizationHandler.UpdateOrganization)
+				organizations.GET("/:orgId/members", s.organizationHandler.ListMembers)
+				organizations.PUT("/:orgId/members/:userId", s.organizationHandler.SetMember)
+				organizations.DELETE("/:orgId/members/:userId", s.organizationHandler.RemoveMember)
+				organizations.GET("/:orgId/keys", s.organizationHandler.ListKeys)
+				organizations.POST("/:orgId/keys/rotate", s.authMiddleware.RequirePermission(entities.PermissionKeyRotate), s.organizationHandler.RotateKey)
+				organizations.GET("/:orgId/letter-number-schemes", s.letterNumberHandler.ListSchemes)
+				organizations.POST("/:orgId/letter-number-schemes", s.letterNumberHandler.CreateScheme)
+				organizations.PUT("/:orgId/letter-number-schemes/:schemeId", s.letterNumberHandler.UpdateScheme)
+				organizations.GET("/:orgId/letter-number-schemes/:schemeId/next", s.letterNumberHandler.NextLetterNumber)
+				// Organization webhooks share the handlers above and are managed by organization admins
+				organizations.GET("/:orgId/webhooks", s.webhookHandler.ListWebhooks)
+				organizations.POST("/:orgId/webhooks", s.webhookHandler.CreateWebhook)
+				organizations.GET("/:orgId/webhooks/dead-letters", s.webhookHandler.ListDeadLetters)
+				organizations.POST("/:orgId/webhooks/deliveries/:deliveryId/redeliver", s.webhookHandler.Redeliver)
+				organizations.GET("/:orgId/webhooks/:webhookId", s.webhookHandler.GetWebhook)
+				organizations.PUT("/:orgId/webhooks/:webhookId", s.webhookHandler.UpdateWebhook)
+				organizations.DELETE("/:orgId/webhooks/:webhookId", s.webhookHandler.DeleteWebhook)
+				organizations.POST("/:orgId/webhooks/:webhookId/rotate-secret", s.webhookHandler.RotateWebhookSecret)
+				organizations.GET("/:orgId/webhooks/:webhookId/deliveries", s.webhookHandler.ListDeliveries)
+			}
+
+			// Signing delegation routes; only users who may sign can delegate it
+			delegations := protected.Group("/delegations")
+			{
+				delegations.GET("", s.delegationHandler.ListDelegations)
+				delegations.POST("", s.authMiddleware.RequirePermission(entities.PermissionDocumentSign), s.delegationHandler.CreateDelegation)
+				delegations.POST("/:delegationId/revoke", s.delegationHandler.RevokeDelegation)
+			}
+
+			// Verification reports on the caller's documents, or with all=true on
+			// every document of their organization; format=csv exports them
+			analytics := protected.Group("/verification-analytics", s.authMiddleware.RequirePermission(entities.PermissionAuditRead))
+			{
+				analytics.GET("", s.analyticsHandler.GetReport(""))
+				analytics.GET("/documents", s.analyticsHandler.GetReport(repositories.VerificationGroupDocument))
+				analytics.GET("/days", s.analyticsHandler.GetReport(repositories.VerificationGroupDay))
+				analytics.GET("/issuers", s.analyticsHandler.GetReport(repositories.VerificationGroupIssuer))
+				analytics.GET("/countries", s.analyticsHandler.GetReport(repositories.VerificationGroupCountry))
+				analytics.GET("/alerts", s.analyticsHandler.GetAlerts)
+			}
+
+			// Administration routes, each guarded by the permission it needs
+			admin := protected.Group("/admin")
+			{
+				manageUsers := s.authMiddleware.RequirePermission(entities.PermissionUserManage)
+				runMaintenance := s.authMiddleware.RequirePermission(entities.PermissionMaintenanceRun)
+
+				admin.GET("/lockouts", manageUsers, s.adminHandler.ListLockouts)
+				admin.DELETE("/lockouts/:lockoutId", manageUsers, s.adminHandler.DeleteLockout)
+				admin.GET("/users", manageUsers, s.userAdminHandler.ListUsers)
+				admin.POST("/users", manageUsers, s.userAdminHandler.CreateUser)
+				admin.GET("/users/:userId", manageUsers, s.userAdminHandler.GetUser)
+				admin.POST("/users/:userId/deactivate", manageUsers, s.userAdminHandler.DeactivateUser)
+				admin.POST("/users/:userId/reactivate", manageUsers, s.userAdminHandler.ReactivateUser)
+				admin.POST("/users/:userId/reset-password", manageUsers, s.userAdminHandler.ResetPassword)
+				admin.POST("/users/:userId/logout", manageUsers, s.userAdminHandler.ForceLogout)
+				admin.POST("/users/:userId/unlock", manageUsers, s.adminHandler.UnlockUser)
+				admin.PUT("/users/:userId/role", manageUsers, s.roleHandler.AssignRole)
+				admin.GET("/roles", manageUsers, s.roleHandler.ListRoles)
+				admin.POST("/roles", manageUsers, s.roleHandler.CreateRole)
+				admin.PUT("/roles/:name", manageUsers, s.roleHandler.UpdateRole)
+				admin.DELETE("/roles/:name", manageUsers, s.roleHandler.DeleteRole)
+				admin.GET("/roles/:name/users", manageUsers, s.roleHandler.ListRoleUsers)
+				admin.GET("/maintenance/tasks", runMaintenance, s.adminHandler.ListMaintenanceTasks)
+				admin.GET("/maintenance/runs", runMaintenance, s.adminHandler.ListMaintenanceRuns)
+				admin.POST("/maintenance/tasks/:task/run", runMaintenance, s.adminHandler.RunMaintenanceTask)
+				admin.GET("/service-accounts", manageUsers, s.serviceAccountHandler.ListServiceAccounts)
+				admin.POST("/service-accounts", manageUsers, s.serviceAccountHandler.CreateServiceAccount)
+				admin.GET("/service-accounts/:userId/api-keys", manageUsers, s.serviceAccountHandler.ListAPIKeys)
+				admin.POST("/service-accounts/:userId/api-keys", manageUsers, s.serviceAccountHandler.CreateAPIKey)
+				admin.DELETE("/api-keys/:keyId", manageUsers, s.serviceAccountHandler.RevokeAPIKey)
+				admin.GET("/invitations", manageUsers, s.userAdminHandler.ListInvitations)
+				admin.POST("/invitations", manageUsers, s.userAdminHandler.CreateInvitation)
+				admin.DELETE("/invitations/:invitationId", manageUsers, s.userAdminHandler.RevokeInvitation)
+				admin.GET("/organizations", manageUsers, s.organizationHandler.ListAllOrganizations)
+				admin.POST("/organizations", manageUsers, s.organizationHandler.CreateOrganization)
+			}
 		}
 
 		// Public verification routes (no authentication required)
 		verify := api.Group("/verify")
 		{
-			verify.GET("/:docId", s.verificationHandler.GetVerificationInfo)
+			verify.GET("/:docId",
+				s.authMiddleware.RateLimit(ratelimit.RouteVerify),
+				s.authMiddleware.AccessCodeLimit(),
+				s.verificationHandler.GetVerificationInfo)
+			// Finds and verifies the documents signed from an uploaded PDF
+			verify.POST("/by-file",
+				s.authMiddleware.RateLimit(ratelimit.RouteVerifyFile),
+				s.authMiddleware.FileValidation(s.config.MaxPDFSize, []string{"application/pdf"}),
+				s.authMiddleware.AccessCodeLimit(),
+				s.verificationHandler.VerifyByFile)
 			// Add file validation for document verification (50MB max, PDF only)
 			verify.POST("/:docId/upload",
-				s.authMiddleware.FileValidation(50<<20, []string{"application/pdf"}),
+				s.authMiddleware.RateLimit(ratelimit.RouteVerify),
+				s.authMiddleware.FileValidation(s.config.MaxPDFSize, []string{"application/pdf"}),
+				s.authMiddleware.AccessCodeLimit(),
 				s.verificationHandler.VerifyDocument)
-			verify.GET("/:docId/history", s.verificationHandler.GetVerificationHistory)
+			// Resumable (tus) uploads that feed /upload via upload_id
+			verify.POST("/:docId/uploads", s.authMiddleware.RateLimit(ratelimit.RouteVerify), s.uploadHandler.CreateUpload)
+			verify.HEAD("/:docId/uploads/:uploadId", s.authMiddleware.RateLimit(ratelimit.RouteVerify), s.uploadHandler.GetUploadOffset)
+			verify.PATCH("/:docId/uploads/:uploadId", s.authMiddleware.RateLimit(ratelimit.RouteVerify), s.uploadHandler.PatchUpload)
+			verify.DELETE("/:docId/uploads/:uploadId", s.authMiddleware.RateLimit(ratelimit.RouteVerify), s.uploadHandler.TerminateUpload)
+			// The history shows verifiers' IP addresses, so it needs a user who may read
+			// the document and whose role may read verification logs
+			verify.GET("/:docId/history",
+				s.authMiddleware.RequireAuth(),
+				s.authMiddleware.RequirePermission(entities.PermissionAuditRead),
+				s.authMiddleware.RateLimit(ratelimit.RouteAPI),
+				s.authMiddleware.OrganizationContext(),
+				s.verificationHandler.GetVerificationHistory)
 		}
 	}
 }
 
 func (s *Server) Run(addr string) error {
+	s.jobRunner.Start(context.Background())
+	defer s.jobRunner.Stop()
+	s.webhookDispatcher.Start(context.Background())
+	defer s.webhookDispatcher.Stop()
+	s.uploadService.Start(context.Background())
+	defer s.uploadService.Stop()
+	s.loginGuard.Start(context.Background())
+	defer s.loginGuard.Stop()
+	s.scheduler.Start(context.Background())
+	defer s.scheduler.Stop()
+	defer s.rateLimiter.Close()
+
 	return s.router.Run(addr)
 }
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// ServiceAccountHandler lets admins manage service accounts and their API keys
+type ServiceAccountHandler struct {
+	apiKeyService *services.APIKeyService
+	validator     *validation.Validator
+}
+
+// NewServiceAccountHandler creates a new service account handler
+func NewServiceAccountHandler(apiKeyService *services.APIKeyService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		apiKeyService: apiKeyService,
+		validator:     validation.NewValidator(),
+	}
+}
+
+// ListServiceAccounts handles GET /api/admin/service-accounts
+func (h *ServiceAccountHandler) ListServiceAccounts(c *gin.Context) {
+	accounts, err := h.apiKeyService.ListServiceAccounts(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_accounts": accounts})
+}
+
+// CreateServiceAccount handles POST /api/admin/service-accounts
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	var req services.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	account, err := h.apiKeyService.CreateServiceAccount(c.Request.Context(), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventServiceAccountCreate, "service_account", map[string]interface{}{
+		"service_account_id": account.ID,
+		"username":           account.Username,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"service_account": account})
+}
+
+// ListAPIKeys handles GET /api/admin/service-accounts/:userId/api-keys
+func (h *ServiceAccountHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(c.Request.Context(), userID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey handles POST /api/admin/service-accounts/:userId/api-keys. The key
+// is only ever shown in this response.
+func (h *ServiceAccountHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := h.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	var req services.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+
+	key, rawKey, err := h.apiKeyService.CreateKey(c.Request.Context(), userID, authUser.ID, &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventAPIKeyCreate, "api_key", map[string]interface{}{
+		"service_account_id": userID,
+		"api_key_id":         key.ID,
+		"api_key_prefix":     key.Prefix,
+		"scopes":             key.Scopes,
+		"rate_limit":         key.RateLimit,
+		"expires_at":         key.ExpiresAt,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"api_key": key,
+		"key":     rawKey,
+		"message": "Store this key now; it cannot be shown again",
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/admin/api-keys/:keyId
+func (h *ServiceAccountHandler) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param("keyId")
+	if _, validationErr := h.validator.ValidateUUID("key_id", keyID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid API key ID", validationErr.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeKey(c.Request.Context(), keyID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventAPIKeyRevoke, "api_key", map[string]interface{}{
+		"service_account_id": key.UserID,
+		"api_key_id":         key.ID,
+		"api_key_prefix":     key.Prefix,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"api_key": key, "message": "API key revoked successfully"})
+}
+
+func (h *ServiceAccountHandler) serviceAccountID(c *gin.Context) (string, bool) {
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid service account ID", validationErr.Error())
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *ServiceAccountHandler) audit(c *gin.Context, event logging.AuditEvent, resource string, details map[string]interface{}) {
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, resource, c.ClientIP(), "SUCCESS", details)
+}
+
+// addAPIKeyDetails records which API key a service account acted through
+func addAPIKeyDetails(details map[string]interface{}, authUser *services.AuthenticatedUser) map[string]interface{} {
+	if authUser == nil || authUser.APIKeyID == "" {
+		return details
+	}
+	details["api_key_id"] = authUser.APIKeyID
+	details["api_key_prefix"] = authUser.APIKeyPrefix
+	return details
+}
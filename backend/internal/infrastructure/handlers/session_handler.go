@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+)
+
+// SessionHandler lists and revokes the current user's sessions
+type SessionHandler struct {
+	authService *services.AuthService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(authService *services.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+// ListSessions handles GET /api/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	authUser, ok := currentUser(c)
+	if !ok {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), authUser.ID, authUser.SessionID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession handles DELETE /api/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	authUser, ok := currentUser(c)
+	if !ok {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if err := h.authService.RevokeSession(c.Request.Context(), authUser.ID, sessionID); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, authUser, map[string]interface{}{
+		"session_id": sessionID,
+		"current":    sessionID == authUser.SessionID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeOtherSessions handles DELETE /api/sessions, ending every session but the current one
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	authUser, ok := currentUser(c)
+	if !ok {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(c.Request.Context(), authUser.ID, authUser.SessionID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, authUser, map[string]interface{}{
+		"revoked": revoked,
+		"others":  true,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked",
+		"revoked": revoked,
+	})
+}
+
+func (h *SessionHandler) audit(c *gin.Context, authUser *services.AuthenticatedUser, details map[string]interface{}) {
+	details["endpoint"] = c.FullPath()
+	logging.LogAuthentication(
+		logging.AuditEventSessionRevoke,
+		authUser.ID,
+		authUser.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		details,
+	)
+}
+
+// currentUser returns the user set by RequireAuth
+func currentUser(c *gin.Context) (*services.AuthenticatedUser, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		return nil, false
+	}
+	authUser, ok := user.(*services.AuthenticatedUser)
+	return authUser, ok
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/database"
+)
+
+func TestSessionHandler_RefreshAndRevoke(t *testing.T) {
+	server, db := setupTestServer(t)
+
+	authService := services.NewAuthService(database.NewUserRepository(db), database.NewSessionRepository(db), "test-secret-key")
+	_, err := authService.Register(context.Background(), services.RegisterRequest{
+		Username: "testuser",
+		Password: "Password123!",
+		FullName: "Test User",
+		Email:    "test@example.com",
+	})
+	require.NoError(t, err)
+
+	send := func(method, path, token string, body interface{}, userAgent string) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			payload, _ := json.Marshal(body)
+			reader = bytes.NewReader(payload)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+	login := func(userAgent string) services.LoginResponse {
+		w := send("POST", "/api/auth/login", "", LoginRequest{Username: "testuser", Password: "Password123!"}, userAgent)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response services.LoginResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotEmpty(t, response.RefreshToken)
+		return response
+	}
+
+	laptop := login("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36")
+	phone := login("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Mobile Safari/537.36")
+
+	// Refreshing rotates the refresh token and issues a working access token
+	w := send("POST", "/api/auth/refresh", "", gin.H{"refresh_token": laptop.RefreshToken}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var refreshed services.LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+	assert.NotEqual(t, laptop.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, http.StatusOK, send("GET", "/api/auth/me", refreshed.Token, nil, "").Code)
+
+	// The laptop lists both sessions and sees which one it is
+	w = send("GET", "/api/sessions", refreshed.Token, nil, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Sessions []services.SessionInfo `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Sessions, 2)
+	devices := map[string]bool{}
+	current := 0
+	for _, session := range list.Sessions {
+		devices[session.Device] = session.Current
+		if session.Current {
+			current++
+		}
+	}
+	assert.Equal(t, 1, current)
+	assert.True(t, devices["Chrome on Windows"])
+	assert.False(t, devices["Chrome on Android"])
+	assert.NotContains(t, w.Body.String(), laptop.RefreshToken)
+
+	// Replaying the rotated refresh token revokes the laptop's session
+	w = send("POST", "/api/auth/refresh", "", gin.H{"refresh_token": laptop.RefreshToken}, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/me", refreshed.Token, nil, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/api/auth/refresh", "", gin.H{"refresh_token": refreshed.RefreshToken}, "").Code)
+
+	// A new laptop login revokes every other session
+	laptop = login("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36")
+	w = send("DELETE", "/api/sessions", laptop.Token, nil, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/me", phone.Token, nil, "").Code)
+	assert.Equal(t, http.StatusOK, send("GET", "/api/auth/me", laptop.Token, nil, "").Code)
+
+	// Single sessions are revoked by id, and only the owner's
+	w = send("DELETE", "/api/sessions/00000000-0000-0000-0000-000000000000", laptop.Token, nil, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Logging out ends the session for good
+	require.Equal(t, http.StatusOK, send("POST", "/api/auth/logout", laptop.Token, nil, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("GET", "/api/auth/me", laptop.Token, nil, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/api/auth/refresh", "", gin.H{"refresh_token": laptop.RefreshToken}, "").Code)
+}
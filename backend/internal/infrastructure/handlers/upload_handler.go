@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// tus protocol constants (https://tus.io/protocols/resumable-upload)
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,expiration,checksum,termination"
+
+	// StatusChecksumMismatch is the tus status for a chunk whose Upload-Checksum does not match
+	StatusChecksumMismatch = 460
+
+	tusOffsetContentType = "application/offset+octet-stream"
+	maxUploadMetadata    = 20
+	maxUploadMetadataLen = 1024
+)
+
+// UploadHandler implements the tus resumable upload protocol for signing and verification
+type UploadHandler struct {
+	uploadService *services.UploadService
+	validator     *validation.Validator
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(uploadService *services.UploadService) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		validator:     validation.NewValidator(),
+	}
+}
+
+// Options handles OPTIONS on an upload collection and advertises server capabilities
+func (h *UploadHandler) Options(c *gin.Context) {
+	c.Header("Tus-Resumable", TusVersion)
+	c.Header("Tus-Version", TusVersion)
+	c.Header("Tus-Extension", TusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.uploadService.MaxLength(), 10))
+	c.Header("Tus-Checksum-Algorithm", strings.Join(services.UploadChecksumAlgorithms, ","))
+	c.Status(http.StatusNoContent)
+}
+
+// CreateUpload handles POST /api/documents/sign/uploads and POST /api/verify/:docId/uploads
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	scope, ok := h.scope(c)
+	if !ok {
+		return
+	}
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		RespondWithValidationError(c, "Deferred upload length is not supported")
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		RespondWithValidationError(c, "Invalid Upload-Length header")
+		return
+	}
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		RespondWithValidationError(c, "Invalid Upload-Metadata header", err.Error())
+		return
+	}
+
+	session, err := h.uploadService.CreateUpload(c.Request.Context(), scope, length, metadata)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	if scope.UserID != "" {
+		h.audit(c, logging.AuditEventUploadCreate, map[string]interface{}{
+			"upload_id": session.ID,
+			"purpose":   session.Purpose,
+			"length":    session.Length,
+		})
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+session.ID)
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// GetUploadOffset handles HEAD on an upload and reports how much has been received
+func (h *UploadHandler) GetUploadOffset(c *gin.Context) {
+	scope, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.GetUpload(c.Request.Context(), scope, uploadID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(session.Length, 10))
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	if len(session.Metadata) > 0 {
+		c.Header("Upload-Metadata", encodeUploadMetadata(session.Metadata))
+	}
+	c.Status(http.StatusOK)
+}
+
+// PatchUpload handles PATCH on an upload and appends one chunk
+func (h *UploadHandler) PatchUpload(c *gin.Context) {
+	scope, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	if c.ContentType() != tusOffsetContentType {
+		RespondWithError(c, http.StatusUnsupportedMediaType,
+			NewStandardError(ErrCodeInvalidRequest, "Content-Type must be "+tusOffsetContentType))
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		RespondWithValidationError(c, "Invalid Upload-Offset header")
+		return
+	}
+
+	session, err := h.uploadService.AppendChunk(c.Request.Context(), scope, uploadID, offset, c.Request.Body, c.GetHeader("Upload-Checksum"))
+	if err != nil {
+		if session != nil {
+			// The connection dropped mid-chunk; what arrived is kept for the client to resume from
+			c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+		}
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusNoContent)
+}
+
+// TerminateUpload handles DELETE on an upload
+func (h *UploadHandler) TerminateUpload(c *gin.Context) {
+	scope, uploadID, ok := h.uploadParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uploadService.TerminateUpload(c.Request.Context(), scope, uploadID); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	if scope.UserID != "" {
+		h.audit(c, logging.AuditEventUploadTerminate, map[string]interface{}{
+			"upload_id": uploadID,
+			"purpose":   scope.Purpose,
+		})
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// scope checks the tus version and works out which flow the route belongs to
+func (h *UploadHandler) scope(c *gin.Context) (services.UploadScope, bool) {
+	c.Header("Tus-Resumable", TusVersion)
+	if c.GetHeader("Tus-Resumable") != TusVersion {
+		c.Header("Tus-Version", TusVersion)
+		RespondWithError(c, http.StatusPreconditionFailed,
+			NewStandardError(ErrCodeInvalidRequest, "Unsupported tus version", "supported: "+TusVersion))
+		return services.UploadScope{}, false
+	}
+
+	// Verification uploads are public and bound to the document being verified
+	if documentID := c.Param("docId"); documentID != "" {
+		if _, validationErr := h.validator.ValidateUUID("document_id", documentID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid document ID", validationErr.Error())
+			return services.UploadScope{}, false
+		}
+		return services.UploadScope{Purpose: entities.UploadPurposeVerify, DocumentID: documentID, ClientIP: c.ClientIP()}, true
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return services.UploadScope{}, false
+	}
+	return services.UploadScope{Purpose: entities.UploadPurposeSign, UserID: userID.(string)}, true
+}
+
+func (h *UploadHandler) uploadParams(c *gin.Context) (services.UploadScope, string, bool) {
+	scope, ok := h.scope(c)
+	if !ok {
+		return scope, "", false
+	}
+
+	uploadID := c.Param("uploadId")
+	if _, validationErr := h.validator.ValidateUUID("upload_id", uploadID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid upload ID", validationErr.Error())
+		return scope, "", false
+	}
+	return scope, uploadID, true
+}
+
+func (h *UploadHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "upload", c.ClientIP(), "SUCCESS", addAPIKeyDetails(details, authUser))
+}
+
+// parseUploadMetadata decodes "key base64value,key2 base64value" pairs; values are optional
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	pairs := strings.Split(header, ",")
+	if len(pairs) > maxUploadMetadata {
+		return nil, fmt.Errorf("at most %d metadata entries are allowed", maxUploadMetadata)
+	}
+	for _, pair := range pairs {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.ContainsAny(key, " ,") {
+			return nil, fmt.Errorf("invalid metadata key %q", key)
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, fmt.Errorf("duplicate metadata key %q", key)
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("metadata value for %q is not valid base64", key)
+		}
+		if len(value) > maxUploadMetadataLen {
+			return nil, fmt.Errorf("metadata value for %q is too long", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+func encodeUploadMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if metadata[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUploadMetadata(t *testing.T) {
+	// "scan.pdf" and "application/pdf", as sent by tus clients
+	metadata, err := parseUploadMetadata("filename c2Nhbi5wZGY=, filetype YXBwbGljYXRpb24vcGRm,is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"filename":        "scan.pdf",
+		"filetype":        "application/pdf",
+		"is_confidential": "",
+	}, metadata)
+
+	assert.Equal(t, "filename c2Nhbi5wZGY=,filetype YXBwbGljYXRpb24vcGRm,is_confidential", encodeUploadMetadata(metadata))
+
+	empty, err := parseUploadMetadata("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestParseUploadMetadata_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "value not base64", header: "filename scan.pdf"},
+		{name: "empty key", header: "filename c2Nhbi5wZGY=,,"},
+		{name: "duplicate key", header: "filename c2Nhbi5wZGY=,filename c2Nhbi5wZGY="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUploadMetadata(tt.header)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// UserAdminHandler lets admins manage user accounts and invitations
+type UserAdminHandler struct {
+	userAdminService *services.UserAdminService
+	validator        *validation.Validator
+}
+
+// ResetPasswordRequest optionally names the new password; a temporary one is
+// generated when it is empty
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// CreateInvitationRequest invites an email address to register
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+// NewUserAdminHandler creates a new user admin handler
+func NewUserAdminHandler(userAdminService *services.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminService: userAdminService,
+		validator:        validation.NewValidator(),
+	}
+}
+
+// ListUsers handles GET /api/admin/users?q=&role=&status=active|inactive&page=&page_size=
+func (h *UserAdminHandler) ListUsers(c *gin.Context) {
+	filter := repositories.UserFilter{Role: strings.ToLower(c.Query("role"))}
+
+	query, validationErr := h.validator.ValidateAndSanitizeString("q", c.Query("q"), 0, 100, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid search query", validationErr.Error())
+		return
+	}
+	filter.Query = query
+
+	switch c.Query("status") {
+	case "":
+	case "active":
+		active := true
+		filter.IsActive = &active
+	case "inactive":
+		inactive := false
+		filter.IsActive = &inactive
+	default:
+		RespondWithValidationError(c, "Invalid status parameter", "status must be active or inactive")
+		return
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			RespondWithValidationError(c, "Invalid page parameter", "page must be 1 or greater")
+			return
+		}
+		filter.Page = page
+	}
+	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err != nil || pageSize < 1 || pageSize > 100 {
+			RespondWithValidationError(c, "Invalid page_size parameter", "page_size must be between 1 and 100")
+			return
+		}
+		filter.PageSize = pageSize
+	}
+
+	page, err := h.userAdminService.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetUser handles GET /api/admin/users/:userId
+func (h *UserAdminHandler) GetUser(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userAdminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// CreateUser handles POST /api/admin/users
+func (h *UserAdminHandler) CreateUser(c *gin.Context) {
+	var req services.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	username, validationErr := h.validator.ValidateAndSanitizeString("username", req.Username, 3, 50, true)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid username", validationErr.Error())
+		return
+	}
+	fullName, validationErr := h.validator.ValidateAndSanitizeString("full_name", req.FullName, 1, 100, true)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid full name", validationErr.Error())
+		return
+	}
+	email, validationErr := h.validator.ValidateEmail("email", req.Email, true)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid email", validationErr.Error())
+		return
+	}
+	if req.Password != "" {
+		if validationErr := h.validator.ValidatePassword("password", req.Password); validationErr != nil {
+			RespondWithValidationError(c, "Invalid password", validationErr.Error())
+			return
+		}
+	}
+	req.Username, req.FullName, req.Email = username, fullName, email
+
+	user, temporaryPassword, err := h.userAdminService.CreateUser(c.Request.Context(), &req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventUserCreate, user.ID, user.Username, map[string]interface{}{
+		"email":              user.Email,
+		"role":               user.Role,
+		"temporary_password": temporaryPassword != "",
+	})
+
+	response := gin.H{"user": user}
+	if temporaryPassword != "" {
+		response["temporary_password"] = temporaryPassword
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// DeactivateUser handles POST /api/admin/users/:userId/deactivate
+func (h *UserAdminHandler) DeactivateUser(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// ReactivateUser handles POST /api/admin/users/:userId/reactivate
+func (h *UserAdminHandler) ReactivateUser(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *UserAdminHandler) setActive(c *gin.Context, active bool) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userAdminService.SetActive(c.Request.Context(), c.GetString("user_id"), userID, active)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	event := logging.AuditEventUserDeactivate
+	if active {
+		event = logging.AuditEventUserReactivate
+	}
+	h.audit(c, event, user.ID, user.Username, map[string]interface{}{})
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ResetPassword handles POST /api/admin/users/:userId/reset-password
+func (h *UserAdminHandler) ResetPassword(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req ResetPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondWithValidationError(c, "Invalid request format", err.Error())
+			return
+		}
+	}
+	if req.Password != "" {
+		if validationErr := h.validator.ValidatePassword("password", req.Password); validationErr != nil {
+			RespondWithValidationError(c, "Invalid password", validationErr.Error())
+			return
+		}
+	}
+
+	user, temporaryPassword, err := h.userAdminService.ResetPassword(c.Request.Context(), userID, req.Password)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventUserPasswordReset, user.ID, user.Username, map[string]interface{}{
+		"temporary_password": temporaryPassword != "",
+	})
+
+	response := gin.H{"message": "Password reset successfully; the user's sessions were ended"}
+	if temporaryPassword != "" {
+		response["temporary_password"] = temporaryPassword
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ForceLogout handles POST /api/admin/users/:userId/logout
+func (h *UserAdminHandler) ForceLogout(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	user, ended, err := h.userAdminService.ForceLogout(c.Request.Context(), userID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventUserForceLogout, user.ID, user.Username, map[string]interface{}{
+		"sessions_ended": ended,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"sessions_ended": ended})
+}
+
+// ListInvitations handles GET /api/admin/invitations
+func (h *UserAdminHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.userAdminService.ListInvitations(c.Request.Context())
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// CreateInvitation handles POST /api/admin/invitations. The token is only
+// returned in this response.
+func (h *UserAdminHandler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	email, validationErr := h.validator.ValidateEmail("email", req.Email, true)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid email", validationErr.Error())
+		return
+	}
+
+	invitation, token, err := h.userAdminService.CreateInvitation(c.Request.Context(), c.GetString("user_id"), email, req.Role)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventInvitationCreate, "", "", map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+		"expires_at":    invitation.ExpiresAt,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+// RevokeInvitation handles DELETE /api/admin/invitations/:invitationId
+func (h *UserAdminHandler) RevokeInvitation(c *gin.Context) {
+	invitationID := c.Param("invitationId")
+	if _, validationErr := h.validator.ValidateUUID("invitation_id", invitationID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid invitation ID", validationErr.Error())
+		return
+	}
+
+	invitation, err := h.userAdminService.RevokeInvitation(c.Request.Context(), invitationID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventInvitationRevoke, "", "", map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+func (h *UserAdminHandler) userID(c *gin.Context) (string, bool) {
+	userID := c.Param("userId")
+	if _, validationErr := h.validator.ValidateUUID("user_id", userID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid user ID", validationErr.Error())
+		return "", false
+	}
+	return userID, true
+}
+
+// audit records an admin action; targetID and targetUsername name the account it
+// was taken on, if any
+func (h *UserAdminHandler) audit(c *gin.Context, event logging.AuditEvent, targetID, targetUsername string, details map[string]interface{}) {
+	admin, _ := c.Get("user")
+	authUser := admin.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+	if targetID != "" {
+		details["target_user_id"] = targetID
+		details["target_username"] = targetUsername
+	}
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "user", c.ClientIP(), "SUCCESS", details)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/repositories"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// VerificationAnalyticsHandler reports how signed documents are being verified
+type VerificationAnalyticsHandler struct {
+	analyticsService *services.VerificationAnalyticsService
+	validator        *validation.Validator
+}
+
+// NewVerificationAnalyticsHandler creates a new verification analytics handler
+func NewVerificationAnalyticsHandler(analyticsService *services.VerificationAnalyticsService) *VerificationAnalyticsHandler {
+	return &VerificationAnalyticsHandler{
+		analyticsService: analyticsService,
+		validator:        validation.NewValidator(),
+	}
+}
+
+// csvKeyColumns names the key column of each grouping in CSV exports
+var csvKeyColumns = map[string]string{
+	repositories.VerificationGroupDocument: "document_id",
+	repositories.VerificationGroupDay:      "day",
+	repositories.VerificationGroupIssuer:   "issuer",
+	repositories.VerificationGroupCountry:  "country",
+}
+
+// GetReport returns the handler for GET /api/verification-analytics and its
+// /documents, /days, /issuers and /countries breakdowns. format=csv downloads
+// the rows as a spreadsheet.
+func (h *VerificationAnalyticsHandler) GetReport(groupBy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, csvFormat, ok := h.request(c)
+		if !ok {
+			return
+		}
+		req.GroupBy = groupBy
+
+		analytics, err := h.analyticsService.GetVerificationAnalytics(c.Request.Context(), req)
+		if err != nil {
+			MapServiceErrorToHTTP(c, err)
+			return
+		}
+
+		h.audit(c, req, csvFormat, map[string]interface{}{
+			"group_by": groupBy,
+			"groups":   len(analytics.Groups),
+			"total":    analytics.Summary.Total,
+		})
+
+		if !csvFormat {
+			c.JSON(http.StatusOK, analytics)
+			return
+		}
+		if groupBy == "" {
+			writeVerificationCSV(c, "verifications.csv", "", []repositories.VerificationStats{analytics.Summary})
+			return
+		}
+		writeVerificationCSV(c, "verifications-by-"+groupBy+".csv", csvKeyColumns[groupBy], analytics.Groups)
+	}
+}
+
+// GetAlerts handles GET /api/verification-analytics/alerts
+func (h *VerificationAnalyticsHandler) GetAlerts(c *gin.Context) {
+	req, csvFormat, ok := h.request(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.analyticsService.GetTamperAlerts(c.Request.Context(), req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, req, csvFormat, map[string]interface{}{
+		"threshold": alerts.Threshold,
+		"documents": len(alerts.Documents),
+	})
+
+	if csvFormat {
+		writeVerificationCSV(c, "tamper-alerts.csv", "document_id", alerts.Documents)
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+// request reads the report filters shared by the analytics endpoints
+func (h *VerificationAnalyticsHandler) request(c *gin.Context) (*services.VerificationAnalyticsRequest, bool, bool) {
+	req := &services.VerificationAnalyticsRequest{
+		UserID: c.GetString("user_id"),
+		All:    c.Query("all") == "true",
+	}
+
+	issuer, validationErr := h.validator.ValidateAndSanitizeString("issuer", c.Query("issuer"), 0, 100, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid issuer", validationErr.Error())
+		return nil, false, false
+	}
+	documentID, validationErr := h.validator.ValidateUUID("document_id", c.Query("document_id"), false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid document ID", validationErr.Error())
+		return nil, false, false
+	}
+	req.Issuer, req.DocumentID = issuer, documentID
+
+	var err error
+	if req.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+		RespondWithValidationError(c, "Invalid from date", err.Error())
+		return nil, false, false
+	}
+	if req.To, err = parseSearchDate(c.Query("to"), true); err != nil {
+		RespondWithValidationError(c, "Invalid to date", err.Error())
+		return nil, false, false
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 1000 {
+			RespondWithValidationError(c, "Limit must be between 1 and 1000")
+			return nil, false, false
+		}
+		req.Limit = limit
+	}
+
+	switch c.Query("format") {
+	case "", "json":
+		return req, false, true
+	case "csv":
+		return req, true, true
+	default:
+		RespondWithValidationError(c, "Format must be json or csv")
+		return nil, false, false
+	}
+}
+
+func (h *VerificationAnalyticsHandler) audit(c *gin.Context, req *services.VerificationAnalyticsRequest, csvFormat bool, details map[string]interface{}) {
+	actor, _ := c.Get("user")
+	authUser := actor.(*services.AuthenticatedUser)
+	details["all"] = req.All
+	details["document_id"] = req.DocumentID
+	details["issuer"] = req.Issuer
+	details["csv"] = csvFormat
+	details["endpoint"] = c.FullPath()
+
+	logging.LogResourceOperation(logging.AuditEventVerificationReport, authUser.ID, authUser.Username, "verification_analytics", c.ClientIP(), "SUCCESS", addAPIKeyDetails(details, authUser))
+}
+
+// writeVerificationCSV sends verification counts as a CSV download. The key
+// column is left out when keyColumn is empty.
+func writeVerificationCSV(c *gin.Context, filename, keyColumn string, rows []repositories.VerificationStats) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Status(http.StatusOK)
+
+	header := []string{"total", "valid", "content_changed", "invalid", "error", "unique_verifiers"}
+	if keyColumn == "document_id" {
+		header = append([]string{keyColumn, "title"}, header...)
+	} else if keyColumn != "" {
+		header = append([]string{keyColumn}, header...)
+	}
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write(header)
+	for _, row := range rows {
+		record := []string{
+			strconv.FormatInt(row.Total, 10),
+			strconv.FormatInt(row.Valid, 10),
+			strconv.FormatInt(row.ContentChanged, 10),
+			strconv.FormatInt(row.Invalid, 10),
+			strconv.FormatInt(row.Error, 10),
+			strconv.FormatInt(row.UniqueVerifiers, 10),
+		}
+		if keyColumn == "document_id" {
+			record = append([]string{row.Key, csvCell(row.Label)}, record...)
+		} else if keyColumn != "" {
+			record = append([]string{csvCell(row.Key)}, record...)
+		}
+		_ = writer.Write(record)
+	}
+	writer.Flush()
+}
+
+// csvCell keeps user-entered text such as titles and issuers from being run
+// as a formula when the export is opened in a spreadsheet
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
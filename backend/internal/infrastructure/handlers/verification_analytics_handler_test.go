@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"digital-signature-system/internal/domain/repositories"
+)
+
+func TestWriteVerificationCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("documents", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		writeVerificationCSV(c, "verifications-by-document.csv", "document_id", []repositories.VerificationStats{
+			{Key: "doc-1", Label: "Transcript, 2026", Total: 4, Valid: 2, ContentChanged: 2, UniqueVerifiers: 3},
+			{Key: "doc-2", Label: "=HYPERLINK(\"http://evil\")", Total: 1, Invalid: 1, UniqueVerifiers: 1},
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="verifications-by-document.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "document_id,title,total,valid,content_changed,invalid,error,unique_verifiers\n"+
+			"doc-1,\"Transcript, 2026\",4,2,2,0,0,3\n"+
+			"doc-2,\"'=HYPERLINK(\"\"http://evil\"\")\",1,0,0,1,0,1\n", w.Body.String())
+	})
+
+	t.Run("totals", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		writeVerificationCSV(c, "verifications.csv", "", []repositories.VerificationStats{{Total: 6, Valid: 6, UniqueVerifiers: 2}})
+
+		assert.Equal(t, "total,valid,content_changed,invalid,error,unique_verifiers\n6,6,0,0,0,2\n", w.Body.String())
+	})
+}
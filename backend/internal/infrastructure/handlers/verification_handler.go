@@ -1,31 +1,37 @@
 package handlers
 
 import (
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"digital-signature-system/internal/domain/entities"
 	"digital-signature-system/internal/domain/services"
 	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/pdf"
 	"digital-signature-system/internal/infrastructure/validation"
 )
 
 // VerificationHandler handles HTTP requests for document verification
 type VerificationHandler struct {
 	verificationService *services.VerificationService
+	uploadService       *services.UploadService
 	validator           *validation.Validator
 }
 
 // NewVerificationHandler creates a new verification handler
-func NewVerificationHandler(verificationService *services.VerificationService) *VerificationHandler {
+func NewVerificationHandler(verificationService *services.VerificationService, uploadService *services.UploadService) *VerificationHandler {
 	return &VerificationHandler{
 		verificationService: verificationService,
+		uploadService:       uploadService,
 		validator:           validation.NewValidator(),
 	}
 }
 
-// GetVerificationInfo handles GET /api/verify/:docId
+// GetVerificationInfo handles GET /api/verify/:docId. The access code of a
+// document with access_code visibility comes from the X-Access-Code header.
 func (h *VerificationHandler) GetVerificationInfo(c *gin.Context) {
 	// Get and validate document ID from URL parameter
 	documentID := c.Param("docId")
@@ -35,7 +41,7 @@ func (h *VerificationHandler) GetVerificationInfo(c *gin.Context) {
 	}
 
 	// Get verification info
-	info, err := h.verificationService.GetVerificationInfo(c.Request.Context(), documentID)
+	info, err := h.verificationService.GetVerificationInfo(c.Request.Context(), documentID, accessCode(c))
 	if err != nil {
 		if err.Error() == "document is not active" {
 			RespondWithError(c, http.StatusGone, 
@@ -58,34 +64,12 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 		return
 	}
 
-	// Get file from form (form parsing is handled by middleware)
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		RespondWithValidationError(c, "File is required", err.Error())
-		return
-	}
-	defer file.Close()
-
-	// Validate filename if provided
-	if header.Filename != "" {
-		if _, validationErr := h.validator.ValidateFilename("filename", header.Filename, false); validationErr != nil {
-			RespondWithValidationError(c, "Invalid filename", validationErr.Error())
-			return
-		}
-	}
-
-	// Read file data
-	pdfData, err := io.ReadAll(file)
-	if err != nil {
-		RespondWithInternalError(c, "Failed to read file data", err.Error())
-		return
-	}
-
-	// Validate file size
-	if validationErr := h.validator.ValidateFileSize("file", int64(len(pdfData)), 50<<20); validationErr != nil {
-		RespondWithValidationError(c, "Invalid file size", validationErr.Error())
+	// The PDF comes from the multipart "file" field or from a completed resumable upload
+	spooled, fileSize, uploadID, ok := h.openVerifySource(c, documentID)
+	if !ok {
 		return
 	}
+	defer spooled.Close()
 
 	// Get and validate client IP for logging
 	clientIP := c.ClientIP()
@@ -99,8 +83,9 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 	// Create verification request
 	req := &services.VerificationRequest{
 		DocumentID: documentID,
-		PDFData:    pdfData,
+		Source:     spooled,
 		VerifierIP: clientIP,
+		AccessCode: accessCode(c),
 	}
 
 	// Verify document
@@ -115,7 +100,7 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 			"FAILURE",
 			map[string]interface{}{
 				"error": err.Error(),
-				"file_size": len(pdfData),
+				"file_size": fileSize,
 				"endpoint": "/api/verify/" + documentID + "/upload",
 			},
 		)
@@ -123,6 +108,10 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 		return
 	}
 
+	if uploadID != "" {
+		h.uploadService.ReleaseUpload(c.Request.Context(), uploadID)
+	}
+
 	// Determine the audit event based on verification result
 	var auditEvent logging.AuditEvent
 	var auditResult string
@@ -146,7 +135,7 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 			"hash_matches": result.HashMatches,
 			"signature_valid": result.SignatureValid,
 			"qr_code_valid": result.QRCodeValid,
-			"file_size": len(pdfData),
+			"file_size": fileSize,
 			"endpoint": "/api/verify/" + documentID + "/upload",
 		},
 	)
@@ -155,8 +144,172 @@ func (h *VerificationHandler) VerifyDocument(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"verification_result": result})
 }
 
-// GetVerificationHistory handles GET /api/verify/:docId/history (optional endpoint for audit)
+// VerifyByFile handles POST /api/verify/by-file. It verifies the uploaded PDF
+// against the documents signed from it, for recipients who only have the file.
+func (h *VerificationHandler) VerifyByFile(c *gin.Context) {
+	// Resumable uploads belong to a document, so only the "file" field is read
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		RespondWithValidationError(c, "File is required", err.Error())
+		return
+	}
+	defer file.Close()
+
+	spooled, err := h.verificationService.SpoolPDF(file)
+	switch {
+	case errors.Is(err, pdf.ErrPDFTooLarge):
+		RespondWithValidationError(c, "Invalid file size", err.Error())
+		return
+	case errors.Is(err, pdf.ErrInvalidPDF):
+		// The service reports the file as invalid
+	case err != nil:
+		RespondWithInternalError(c, "Failed to read file data", err.Error())
+		return
+	default:
+		defer spooled.Close()
+	}
+
+	clientIP := c.ClientIP()
+	if sanitizedIP, validationErr := h.validator.ValidateAndSanitizeString("client_ip", clientIP, 0, 45, false); validationErr != nil {
+		clientIP = "unknown"
+	} else {
+		clientIP = sanitizedIP
+	}
+
+	results, err := h.verificationService.VerifyByFile(c.Request.Context(), &services.VerificationRequest{
+		Source:     spooled,
+		VerifierIP: clientIP,
+		AccessCode: accessCode(c),
+	})
+	if err != nil {
+		logging.LogVerificationAttempt(
+			logging.AuditEventVerificationFailure,
+			"",
+			clientIP,
+			c.GetHeader("User-Agent"),
+			"FAILURE",
+			map[string]interface{}{
+				"error":     err.Error(),
+				"file_size": header.Size,
+				"endpoint":  "/api/verify/by-file",
+			},
+		)
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	for _, result := range results {
+		auditEvent, auditResult := logging.AuditEventVerificationFailure, "INVALID"
+		if result.IsValid {
+			auditEvent, auditResult = logging.AuditEventVerificationSuccess, "SUCCESS"
+		}
+		logging.LogVerificationAttempt(
+			auditEvent,
+			result.DocumentID,
+			clientIP,
+			c.GetHeader("User-Agent"),
+			auditResult,
+			map[string]interface{}{
+				"verification_status": result.Status,
+				"hash_matches":        result.HashMatches,
+				"signature_valid":     result.SignatureValid,
+				"qr_code_valid":       result.QRCodeValid,
+				"file_size":           header.Size,
+				"endpoint":            "/api/verify/by-file",
+			},
+		)
+	}
+
+	// The newest document comes first; older versions signed from the same file follow
+	c.JSON(http.StatusOK, gin.H{
+		"verification_result": results[0],
+		"matches":             results,
+		"total":               len(results),
+	})
+}
+
+// AccessCodeHeader carries the access code of an access_code document
+const AccessCodeHeader = "X-Access-Code"
+
+// accessCode returns the access code a verifier gave in the X-Access-Code header
+// or the "access_code" field of a POST body. Query parameters are ignored so codes
+// do not end up in access logs, browser history or Referer headers.
+func accessCode(c *gin.Context) string {
+	if code := c.GetHeader(AccessCodeHeader); code != "" {
+		return code
+	}
+	if c.Request.Method != http.MethodPost {
+		return ""
+	}
+	return c.Request.PostFormValue("access_code")
+}
+
+// openVerifySource spools the document to verify from either the "file" form field or, when
+// "upload_id" is given, a completed resumable upload created for this document. A nil source
+// means the upload is not a parseable PDF; the service records that as an invalid verification.
+func (h *VerificationHandler) openVerifySource(c *gin.Context, documentID string) (*pdf.SpooledPDF, int64, string, bool) {
+	var reader io.Reader
+	var size int64
+	uploadID := c.Request.FormValue("upload_id")
+
+	if uploadID != "" {
+		if _, validationErr := h.validator.ValidateUUID("upload_id", uploadID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid upload ID", validationErr.Error())
+			return nil, 0, "", false
+		}
+
+		scope := services.UploadScope{Purpose: entities.UploadPurposeVerify, DocumentID: documentID}
+		file, session, err := h.uploadService.OpenCompletedUpload(c.Request.Context(), scope, uploadID)
+		if err != nil {
+			MapServiceErrorToHTTP(c, err)
+			return nil, 0, "", false
+		}
+		defer file.Close()
+		reader, size = file, session.Length
+	} else {
+		// Get file from form (form parsing is handled by middleware)
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			RespondWithValidationError(c, "File is required", err.Error())
+			return nil, 0, "", false
+		}
+		defer file.Close()
+
+		// Validate filename if provided
+		if header.Filename != "" {
+			if _, validationErr := h.validator.ValidateFilename("filename", header.Filename, false); validationErr != nil {
+				RespondWithValidationError(c, "Invalid filename", validationErr.Error())
+				return nil, 0, "", false
+			}
+		}
+		reader, size = file, header.Size
+	}
+
+	// Stream the upload to disk, hashing and parsing it once, so memory stays bounded
+	spooled, err := h.verificationService.SpoolPDF(reader)
+	switch {
+	case err == nil:
+		return spooled, size, uploadID, true
+	case errors.Is(err, pdf.ErrPDFTooLarge):
+		RespondWithValidationError(c, "Invalid file size", err.Error())
+	case errors.Is(err, pdf.ErrInvalidPDF):
+		// Let the service record the attempt and report the file as invalid
+		return nil, size, uploadID, true
+	default:
+		RespondWithInternalError(c, "Failed to read file data", err.Error())
+	}
+	return nil, 0, "", false
+}
+
+// GetVerificationHistory handles GET /api/verify/:docId/history for users who
+// may read the document, as it shows verifiers' IP addresses
 func (h *VerificationHandler) GetVerificationHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
 	// Get and validate document ID from URL parameter
 	documentID := c.Param("docId")
 	if _, validationErr := h.validator.ValidateUUID("document_id", documentID, true); validationErr != nil {
@@ -165,7 +318,7 @@ func (h *VerificationHandler) GetVerificationHistory(c *gin.Context) {
 	}
 
 	// Get verification history
-	history, err := h.verificationService.GetVerificationHistory(c.Request.Context(), documentID)
+	history, err := h.verificationService.GetVerificationHistory(c.Request.Context(), userID.(string), documentID)
 	if err != nil {
 		MapServiceErrorToHTTP(c, err)
 		return
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+)
+
+// Multipart fields that carry the signing step-up assertion
+const (
+	webAuthnChallengeField = "webauthn_challenge_id"
+	webAuthnAssertionField = "webauthn_assertion"
+)
+
+// WebAuthnHandler handles security key registration and the ceremonies that
+// confirm signing requests
+type WebAuthnHandler struct {
+	authService *services.AuthService
+}
+
+// WebAuthnConfirmationRequest carries an optional confirmation from an existing key
+type WebAuthnConfirmationRequest struct {
+	Confirmation *services.WebAuthnAssertion `json:"confirmation"`
+}
+
+// WebAuthnRegistrationRequest completes a registration ceremony
+type WebAuthnRegistrationRequest struct {
+	ChallengeID string          `json:"challenge_id" binding:"required"`
+	Name        string          `json:"name"`
+	Response    json.RawMessage `json:"response" binding:"required"`
+}
+
+// WebAuthnSignBeginRequest names the document a signing assertion is for
+type WebAuthnSignBeginRequest struct {
+	// DocumentHash is the hex encoded SHA-256 of the PDF that will be uploaded
+	DocumentHash string `json:"document_hash" binding:"required"`
+}
+
+// WebAuthnBatchSignBeginRequest names the batch a signing assertion is for
+type WebAuthnBatchSignBeginRequest struct {
+	// BatchDigest is the hex encoded services.BatchDigest of the files and
+	// manifest that will be uploaded
+	BatchDigest string `json:"batch_digest" binding:"required"`
+}
+
+// NewWebAuthnHandler creates a new WebAuthn handler
+func NewWebAuthnHandler(authService *services.AuthService) *WebAuthnHandler {
+	return &WebAuthnHandler{authService: authService}
+}
+
+// ListCredentials handles GET /api/webauthn/credentials
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	credentials, err := h.authService.ListWebAuthnCredentials(c.Request.Context(), userID.(string))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credentials": credentials})
+}
+
+// BeginConfirmation handles POST /api/webauthn/confirm/begin
+func (h *WebAuthnHandler) BeginConfirmation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	ceremony, err := h.authService.BeginWebAuthnConfirmation(c.Request.Context(), userID.(string))
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// BeginRegistration handles POST /api/webauthn/register/begin. Users who already
+// have a key send a confirmation made with it.
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	var req WebAuthnConfirmationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondWithValidationError(c, "Invalid request format", err.Error())
+			return
+		}
+	}
+
+	ceremony, err := h.authService.BeginWebAuthnRegistration(c.Request.Context(), userID.(string), req.Confirmation)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// FinishRegistration handles POST /api/webauthn/register/finish
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	var req WebAuthnRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	credential, err := h.authService.FinishWebAuthnRegistration(c.Request.Context(), userID.(string), req.ChallengeID, req.Name, req.Response)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebAuthnAdd, map[string]interface{}{
+		"credential_id": credential.ID,
+		"name":          credential.Name,
+		"aaguid":        credential.AAGUID,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"credential": credential,
+		"message":    "Security key registered; signing now requires confirming with a security key",
+	})
+}
+
+// DeleteCredential handles DELETE /api/webauthn/credentials/:id
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	var req WebAuthnConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+
+	credentialID := c.Param("id")
+	if err := h.authService.DeleteWebAuthnCredential(c.Request.Context(), userID.(string), credentialID, req.Confirmation); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebAuthnRemove, map[string]interface{}{
+		"credential_id": credentialID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Security key removed"})
+}
+
+// BeginSigning handles POST /api/webauthn/sign/begin. The returned challenge only
+// confirms signing the document with the given hash.
+func (h *WebAuthnHandler) BeginSigning(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	var req WebAuthnSignBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	documentHash, err := hex.DecodeString(strings.TrimSpace(req.DocumentHash))
+	if err != nil {
+		MapServiceErrorToHTTP(c, services.ErrWebAuthnInvalidHash)
+		return
+	}
+
+	ceremony, err := h.authService.BeginSigningAssertion(c.Request.Context(), userID.(string), documentHash)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// BeginBatchSigning handles POST /api/webauthn/sign/batch/begin. The returned
+// challenge only confirms signing the batch with the given digest.
+func (h *WebAuthnHandler) BeginBatchSigning(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return
+	}
+
+	var req WebAuthnBatchSignBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return
+	}
+	batchDigest, err := hex.DecodeString(strings.TrimSpace(req.BatchDigest))
+	if err != nil {
+		MapServiceErrorToHTTP(c, services.ErrWebAuthnInvalidHash)
+		return
+	}
+
+	ceremony, err := h.authService.BeginBatchSigningAssertion(c.Request.Context(), userID.(string), batchDigest)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ceremony)
+}
+
+func (h *WebAuthnHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+
+	details["endpoint"] = c.FullPath()
+	logging.LogAuthentication(
+		event,
+		authUser.ID,
+		authUser.Username,
+		c.ClientIP(),
+		c.GetHeader("User-Agent"),
+		"SUCCESS",
+		details,
+	)
+}
+
+// requireSigningAssertion enforces the security key step-up on a signing request.
+// digest is the SHA-256 of the uploaded PDF, or the services.BatchDigest of a
+// batch. It responds and returns false when the request must stop; the assertion
+// is nil when the user is not required to confirm.
+func requireSigningAssertion(c *gin.Context, authService *services.AuthService, authUser *services.AuthenticatedUser, digest []byte, batch bool, endpoint string) (*services.VerifiedAssertion, bool) {
+	// Service accounts sign unattended; their key's scopes are the control
+	if authService == nil || authUser.APIKeyID != "" {
+		return nil, true
+	}
+
+	required, err := authService.SigningAssertionRequired(c.Request.Context(), authUser.ID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return nil, false
+	}
+	if !required {
+		return nil, true
+	}
+
+	assertion := services.WebAuthnAssertion{ChallengeID: c.Request.FormValue(webAuthnChallengeField)}
+	response := c.Request.FormValue(webAuthnAssertionField)
+	if assertion.ChallengeID == "" || response == "" {
+		err = services.ErrWebAuthnRequired
+	} else {
+		assertion.Response = json.RawMessage(response)
+		var verified *services.VerifiedAssertion
+		if batch {
+			verified, err = authService.VerifyBatchSigningAssertion(c.Request.Context(), authUser.ID, assertion, digest)
+		} else {
+			verified, err = authService.VerifySigningAssertion(c.Request.Context(), authUser.ID, assertion, digest)
+		}
+		if err == nil {
+			return verified, true
+		}
+	}
+
+	event := logging.AuditEventDocumentSign
+	details := map[string]interface{}{
+		"error":    err.Error(),
+		"endpoint": endpoint,
+	}
+	if batch {
+		event = logging.AuditEventDocumentBatchSign
+		details["batch_digest"] = hex.EncodeToString(digest)
+	} else {
+		details["document_hash"] = hex.EncodeToString(digest)
+	}
+	logging.LogDocumentOperation(event, authUser.ID, authUser.Username, "", c.ClientIP(), "FAILURE", details)
+
+	MapServiceErrorToHTTP(c, err)
+	return nil, false
+}
+
+// addAssertionDetails records which authenticator confirmed a signing request
+func addAssertionDetails(details map[string]interface{}, assertion *services.VerifiedAssertion) map[string]interface{} {
+	if assertion == nil {
+		return details
+	}
+	details["webauthn_credential_id"] = assertion.Credential.ID
+	details["webauthn_credential_name"] = assertion.Credential.Name
+	details["webauthn_aaguid"] = assertion.Credential.AAGUID
+	details["webauthn_user_verified"] = assertion.UserVerified
+	return details
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"digital-signature-system/internal/config"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/database"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:3000"
+)
+
+// softAuthenticator is a software security key with a P-256 credential and
+// "none" attestation, enough to drive real registration and assertion ceremonies
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: credentialID}
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": testOrigin})
+	require.NoError(t, err)
+	return data
+}
+
+// register answers navigator.credentials.create for the given challenge
+func (a *softAuthenticator) register(t *testing.T, challenge string) json.RawMessage {
+	publicKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(0x45, attested), // UP, UV and AT
+	})
+	require.NoError(t, err)
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(a.clientData(t, "webauthn.create", challenge)),
+		"attestationObject": b64(attestation),
+	})
+}
+
+// assert answers navigator.credentials.get for the given challenge
+func (a *softAuthenticator) assert(t *testing.T, challenge string) json.RawMessage {
+	a.signCount++
+	authData := a.authData(0x05, nil) // UP and UV
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return a.credential(t, map[string]string{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+	})
+}
+
+func (a *softAuthenticator) credential(t *testing.T, response map[string]string) json.RawMessage {
+	data, err := json.Marshal(map[string]interface{}{
+		"id":       b64(a.credentialID),
+		"rawId":    b64(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ceremonyChallenge extracts the challenge from a begin response's options
+func ceremonyChallenge(t *testing.T, body []byte) (string, string) {
+	var ceremony struct {
+		ChallengeID string `json:"challenge_id"`
+		Options     struct {
+			PublicKey struct {
+				Challenge string `json:"challenge"`
+			} `json:"publicKey"`
+		} `json:"options"`
+	}
+	require.NoError(t, json.Unmarshal(body, &ceremony))
+	require.NotEmpty(t, ceremony.ChallengeID)
+	require.NotEmpty(t, ceremony.Options.PublicKey.Challenge)
+	return ceremony.ChallengeID, ceremony.Options.PublicKey.Challenge
+}
+
+func webAuthnTestPDF() []byte {
+	return []byte("%PDF-1.4\n1 0 obj\n<<\n/Type /Catalog\n/Pages 2 0 R\n>>\nendobj\n\n2 0 obj\n<<\n/Type /Pages\n/Kids [3 0 R]\n/Count 1\n>>\nendobj\n\n3 0 obj\n<<\n/Type /Page\n/Parent 2 0 R\n/MediaBox [0 0 612 792]\n>>\nendobj\n\nxref\n0 4\n0000000000 65535 f \n0000000010 00000 n \n0000000053 00000 n \n0000000100 00000 n \ntrailer\n<<\n/Size 4\n/Root 1 0 R\n>>\nstartxref\n157\n%%EOF")
+}
+
+func TestWebAuthnHandler_SigningStepUp(t *testing.T) {
+	db := setupTestDB(t)
+	server := NewServer(&config.Config{
+		JWTSecret:            "test-secret-key",
+		Environment:          "test",
+		MaxPDFSize:           10 << 20,
+		WebAuthnRPID:         testRPID,
+		WebAuthnRPName:       "Digital Signature System",
+		WebAuthnRPOrigins:    testOrigin,
+		WebAuthnChallengeTTL: 5 * time.Minute,
+	}, db)
+
+	authService := services.NewAuthService(database.NewUserRepository(db), database.NewSessionRepository(db), "test-secret-key")
+	_, err := authService.Register(context.Background(), services.RegisterRequest{
+		Username: "signer",
+		Password: "Password123!",
+		FullName: "Signer",
+		Email:    "signer@example.com",
+	})
+	require.NoError(t, err)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	pdfData := webAuthnTestPDF()
+	sign := func(token, challengeID string, assertion json.RawMessage) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="letter.pdf"`)
+		header.Set("Content-Type", "application/pdf")
+		part, _ := writer.CreatePart(header)
+		_, _ = part.Write(pdfData)
+		_ = writer.WriteField("issuer", "Issuer")
+		_ = writer.WriteField("title", "Letter")
+		_ = writer.WriteField("letter_number", "001/2026")
+		if challengeID != "" {
+			_ = writer.WriteField(webAuthnChallengeField, challengeID)
+			_ = writer.WriteField(webAuthnAssertionField, string(assertion))
+		}
+		_ = writer.Close()
+
+		req, _ := http.NewRequest("POST", "/api/documents/sign", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	var login services.LoginResponse
+	w := send("POST", "/api/auth/login", "", LoginRequest{Username: "signer", Password: "Password123!"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	token := login.Token
+
+	// Register a key
+	authenticator := newSoftAuthenticator(t)
+	w = send("POST", "/api/webauthn/register/begin", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	challengeID, challenge := ceremonyChallenge(t, w.Body.Bytes())
+
+	w = send("POST", "/api/webauthn/register/finish", token, WebAuthnRegistrationRequest{
+		ChallengeID: challengeID,
+		Name:        "Test key",
+		Response:    authenticator.register(t, challenge),
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// A second key needs a confirmation from the first
+	w = send("POST", "/api/webauthn/register/begin", token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeWebAuthnRequired)
+
+	// With a key registered, signing without an assertion is refused
+	w = sign(token, "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeWebAuthnRequired)
+
+	// An assertion made for a different document does not carry over
+	otherHash := sha256.Sum256([]byte("another document"))
+	w = send("POST", "/api/webauthn/sign/begin", token, WebAuthnSignBeginRequest{DocumentHash: hex.EncodeToString(otherHash[:])})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	challengeID, challenge = ceremonyChallenge(t, w.Body.Bytes())
+	w = sign(token, challengeID, authenticator.assert(t, challenge))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeWebAuthnFailed)
+
+	// An assertion bound to this document's hash passes the step-up
+	documentHash := sha256.Sum256(pdfData)
+	w = send("POST", "/api/webauthn/sign/begin", token, WebAuthnSignBeginRequest{DocumentHash: hex.EncodeToString(documentHash[:])})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	challengeID, challenge = ceremonyChallenge(t, w.Body.Bytes())
+	assertion := authenticator.assert(t, challenge)
+	w = sign(token, challengeID, assertion)
+	assert.NotEqual(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// Each assertion confirms one request
+	w = sign(token, challengeID, assertion)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), ErrCodeWebAuthnFailed)
+
+	var listed struct {
+		Credentials []map[string]interface{} `json:"credentials"`
+	}
+	w = send("GET", "/api/webauthn/credentials", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Credentials, 1)
+	assert.NotNil(t, listed.Credentials[0]["last_used_at"])
+	credentialID := listed.Credentials[0]["id"].(string)
+
+	// Removing the key needs a confirmation from it
+	w = send("DELETE", "/api/webauthn/credentials/"+credentialID, token, WebAuthnConfirmationRequest{})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = send("POST", "/api/webauthn/confirm/begin", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	challengeID, challenge = ceremonyChallenge(t, w.Body.Bytes())
+	w = send("DELETE", "/api/webauthn/credentials/"+credentialID, token, WebAuthnConfirmationRequest{
+		Confirmation: &services.WebAuthnAssertion{ChallengeID: challengeID, Response: authenticator.assert(t, challenge)},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Without keys and without the policy, signing no longer asks for one
+	w = sign(token, "", nil)
+	assert.NotEqual(t, http.StatusForbidden, w.Code)
+}
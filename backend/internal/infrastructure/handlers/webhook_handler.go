@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"digital-signature-system/internal/domain/entities"
+	"digital-signature-system/internal/domain/services"
+	"digital-signature-system/internal/infrastructure/logging"
+	"digital-signature-system/internal/infrastructure/validation"
+)
+
+// WebhookHandler handles webhook subscription management and the delivery log
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	validator      *validation.Validator
+}
+
+// WebhookRequest is the body for creating or updating a subscription
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validation.NewValidator(),
+	}
+}
+
+// ListWebhooks handles GET /api/webhooks and GET /api/organizations/:orgId/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, orgID, ok := h.owner(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context(), userID, orgID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": subs})
+}
+
+// CreateWebhook handles POST /api/webhooks and POST /api/organizations/:orgId/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, orgID, ok := h.owner(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+	if req.URL == "" {
+		RespondWithValidationError(c, "URL is required")
+		return
+	}
+
+	sub, secret, err := h.webhookService.CreateSubscription(c.Request.Context(), userID, orgID, req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebhookCreate, map[string]interface{}{
+		"webhook_id": sub.ID,
+		"url":        sub.URL,
+		"events":     sub.Events,
+	})
+
+	// The secret is only ever returned here and on rotation
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": sub,
+		"secret":  secret,
+		"message": "Webhook created; store the secret now, it will not be shown again",
+	})
+}
+
+// GetWebhook handles GET /api/webhooks/:webhookId and its organization counterpart
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, orgID, webhookID, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), userID, orgID, webhookID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": sub})
+}
+
+// UpdateWebhook handles PUT /api/webhooks/:webhookId and its organization counterpart
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, orgID, webhookID, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(c.Request.Context(), userID, orgID, webhookID, req)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebhookUpdate, map[string]interface{}{
+		"webhook_id": sub.ID,
+		"url":        sub.URL,
+		"events":     sub.Events,
+		"is_active":  sub.IsActive,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"webhook": sub})
+}
+
+// RotateWebhookSecret handles POST /api/webhooks/:webhookId/rotate-secret and its organization counterpart
+func (h *WebhookHandler) RotateWebhookSecret(c *gin.Context) {
+	userID, orgID, webhookID, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	sub, secret, err := h.webhookService.RotateSecret(c.Request.Context(), userID, orgID, webhookID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebhookUpdate, map[string]interface{}{
+		"webhook_id": sub.ID,
+		"action":     "rotate_secret",
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": sub,
+		"secret":  secret,
+	})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/:webhookId and its organization counterpart
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, orgID, webhookID, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), userID, orgID, webhookID); err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebhookDelete, map[string]interface{}{
+		"webhook_id": webhookID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries handles GET /api/webhooks/:webhookId/deliveries and its organization counterpart
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, orgID, webhookID, ok := h.webhookParams(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", entities.WebhookDeliveryPending, entities.WebhookDeliveryDelivered, entities.WebhookDeliveryDead:
+	default:
+		RespondWithValidationError(c, "Invalid status parameter", "status must be pending, delivered or dead")
+		return
+	}
+
+	h.respondWithDeliveries(c, userID, orgID, webhookID, status)
+}
+
+// ListDeadLetters handles GET /api/webhooks/dead-letters and its organization counterpart
+func (h *WebhookHandler) ListDeadLetters(c *gin.Context) {
+	userID, orgID, ok := h.owner(c)
+	if !ok {
+		return
+	}
+
+	h.respondWithDeliveries(c, userID, orgID, "", entities.WebhookDeliveryDead)
+}
+
+// Redeliver handles POST /api/webhooks/deliveries/:deliveryId/redeliver and its organization counterpart
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID, orgID, ok := h.owner(c)
+	if !ok {
+		return
+	}
+
+	deliveryID := c.Param("deliveryId")
+	if _, validationErr := h.validator.ValidateUUID("delivery_id", deliveryID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid delivery ID", validationErr.Error())
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), userID, orgID, deliveryID)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEventWebhookRedeliver, map[string]interface{}{
+		"webhook_id":  delivery.SubscriptionID,
+		"delivery_id": delivery.ID,
+		"event_type":  delivery.EventType,
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"delivery": delivery,
+		"message":  "Delivery queued",
+	})
+}
+
+func (h *WebhookHandler) respondWithDeliveries(c *gin.Context, userID, orgID, webhookID, status string) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			RespondWithValidationError(c, "Invalid limit parameter", "limit must be between 1 and 200")
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), userID, orgID, webhookID, status, limit)
+	if err != nil {
+		MapServiceErrorToHTTP(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// bindRequest parses and sanitizes a subscription request body
+func (h *WebhookHandler) bindRequest(c *gin.Context) (*services.WebhookSubscriptionRequest, bool) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithValidationError(c, "Invalid request format", err.Error())
+		return nil, false
+	}
+
+	if len(req.URL) > 2048 {
+		RespondWithValidationError(c, "URL too long")
+		return nil, false
+	}
+
+	description, validationErr := h.validator.ValidateAndSanitizeString("description", req.Description, 0, 200, false)
+	if validationErr != nil {
+		RespondWithValidationError(c, "Invalid description", validationErr.Error())
+		return nil, false
+	}
+
+	return &services.WebhookSubscriptionRequest{
+		URL:         req.URL,
+		Events:      req.Events,
+		Description: description,
+		IsActive:    req.IsActive,
+	}, true
+}
+
+// owner extracts the authenticated user and, on organization routes, the validated organization ID
+func (h *WebhookHandler) owner(c *gin.Context) (string, string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		RespondWithUnauthorizedError(c, "User not authenticated")
+		return "", "", false
+	}
+
+	orgID := c.Param("orgId")
+	if orgID != "" {
+		if _, validationErr := h.validator.ValidateUUID("organization_id", orgID, true); validationErr != nil {
+			RespondWithValidationError(c, "Invalid organization ID", validationErr.Error())
+			return "", "", false
+		}
+	}
+
+	return userID.(string), orgID, true
+}
+
+// webhookParams extracts the owner and validated webhook ID
+func (h *WebhookHandler) webhookParams(c *gin.Context) (string, string, string, bool) {
+	userID, orgID, ok := h.owner(c)
+	if !ok {
+		return "", "", "", false
+	}
+
+	webhookID := c.Param("webhookId")
+	if _, validationErr := h.validator.ValidateUUID("webhook_id", webhookID, true); validationErr != nil {
+		RespondWithValidationError(c, "Invalid webhook ID", validationErr.Error())
+		return "", "", "", false
+	}
+
+	return userID, orgID, webhookID, true
+}
+
+func (h *WebhookHandler) audit(c *gin.Context, event logging.AuditEvent, details map[string]interface{}) {
+	user, _ := c.Get("user")
+	authUser := user.(*services.AuthenticatedUser)
+	details["endpoint"] = c.FullPath()
+	if orgID := c.Param("orgId"); orgID != "" {
+		details["organization_id"] = orgID
+	}
+
+	logging.LogResourceOperation(event, authUser.ID, authUser.Username, "webhook", c.ClientIP(), "SUCCESS", details)
+}
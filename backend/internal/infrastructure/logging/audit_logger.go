@@ -20,17 +20,77 @@ const (
 	AuditEventRegister       AuditEvent = "REGISTER"
 	AuditEventPasswordChange AuditEvent = "PASSWORD_CHANGE"
 	AuditEventAuthFailure    AuditEvent = "AUTH_FAILURE"
+	AuditEventAccountLocked  AuditEvent = "ACCOUNT_LOCKED"
+	AuditEventAccountUnlock  AuditEvent = "ACCOUNT_UNLOCK"
+	AuditEventMFAChallenge   AuditEvent = "MFA_CHALLENGE"
+	AuditEventMFAEnroll      AuditEvent = "MFA_ENROLL"
+	AuditEventMFADisable     AuditEvent = "MFA_DISABLE"
+	AuditEventMFARecovery    AuditEvent = "MFA_RECOVERY_CODES"
+	AuditEventWebAuthnAdd    AuditEvent = "WEBAUTHN_CREDENTIAL_ADD"
+	AuditEventWebAuthnRemove AuditEvent = "WEBAUTHN_CREDENTIAL_REMOVE"
+	AuditEventSessionRevoke  AuditEvent = "SESSION_REVOKE"
+	AuditEventAPIKeyDenied   AuditEvent = "API_KEY_DENIED"
+
+	// Administrative events
+	AuditEventMaintenanceRun       AuditEvent = "MAINTENANCE_RUN"
+	AuditEventServiceAccountCreate AuditEvent = "SERVICE_ACCOUNT_CREATE"
+	AuditEventAPIKeyCreate         AuditEvent = "API_KEY_CREATE"
+	AuditEventAPIKeyRevoke         AuditEvent = "API_KEY_REVOKE"
+	AuditEventRoleCreate           AuditEvent = "ROLE_CREATE"
+	AuditEventRoleUpdate           AuditEvent = "ROLE_UPDATE"
+	AuditEventRoleDelete           AuditEvent = "ROLE_DELETE"
+	AuditEventRoleAssign           AuditEvent = "ROLE_ASSIGN"
+
+	// User management events
+	AuditEventUserCreate        AuditEvent = "USER_CREATE"
+	AuditEventUserDeactivate    AuditEvent = "USER_DEACTIVATE"
+	AuditEventUserReactivate    AuditEvent = "USER_REACTIVATE"
+	AuditEventUserPasswordReset AuditEvent = "USER_PASSWORD_RESET"
+	AuditEventUserForceLogout   AuditEvent = "USER_FORCE_LOGOUT"
+	AuditEventInvitationCreate  AuditEvent = "INVITATION_CREATE"
+	AuditEventInvitationRevoke  AuditEvent = "INVITATION_REVOKE"
+
+	// Organization events
+	AuditEventOrganizationCreate       AuditEvent = "ORGANIZATION_CREATE"
+	AuditEventOrganizationUpdate       AuditEvent = "ORGANIZATION_UPDATE"
+	AuditEventOrganizationMemberSet    AuditEvent = "ORGANIZATION_MEMBER_SET"
+	AuditEventOrganizationMemberRemove AuditEvent = "ORGANIZATION_MEMBER_REMOVE"
+	AuditEventOrganizationKeyRotate    AuditEvent = "ORGANIZATION_KEY_ROTATE"
+
+	// Letter numbering events
+	AuditEventLetterNumberSchemeCreate AuditEvent = "LETTER_NUMBER_SCHEME_CREATE"
+	AuditEventLetterNumberSchemeUpdate AuditEvent = "LETTER_NUMBER_SCHEME_UPDATE"
+
+	// Signing delegation events
+	AuditEventDelegationCreate AuditEvent = "DELEGATION_CREATE"
+	AuditEventDelegationRevoke AuditEvent = "DELEGATION_REVOKE"
 
 	// Document events
-	AuditEventDocumentSign   AuditEvent = "DOCUMENT_SIGN"
-	AuditEventDocumentView   AuditEvent = "DOCUMENT_VIEW"
-	AuditEventDocumentDelete AuditEvent = "DOCUMENT_DELETE"
-	AuditEventDocumentList   AuditEvent = "DOCUMENT_LIST"
+	AuditEventDocumentSign      AuditEvent = "DOCUMENT_SIGN"
+	AuditEventDocumentView      AuditEvent = "DOCUMENT_VIEW"
+	AuditEventDocumentDelete    AuditEvent = "DOCUMENT_DELETE"
+	AuditEventDocumentList      AuditEvent = "DOCUMENT_LIST"
+	AuditEventDocumentBatchSign AuditEvent = "DOCUMENT_BATCH_SIGN"
+
+	// Background job events
+	AuditEventJobEnqueue AuditEvent = "JOB_ENQUEUE"
+	AuditEventJobCancel  AuditEvent = "JOB_CANCEL"
+
+	// Webhook events
+	AuditEventWebhookCreate    AuditEvent = "WEBHOOK_CREATE"
+	AuditEventWebhookUpdate    AuditEvent = "WEBHOOK_UPDATE"
+	AuditEventWebhookDelete    AuditEvent = "WEBHOOK_DELETE"
+	AuditEventWebhookRedeliver AuditEvent = "WEBHOOK_REDELIVER"
+
+	// Resumable upload events
+	AuditEventUploadCreate    AuditEvent = "UPLOAD_CREATE"
+	AuditEventUploadTerminate AuditEvent = "UPLOAD_TERMINATE"
 
 	// Verification events
 	AuditEventVerificationAttempt AuditEvent = "VERIFICATION_ATTEMPT"
 	AuditEventVerificationSuccess AuditEvent = "VERIFICATION_SUCCESS"
 	AuditEventVerificationFailure AuditEvent = "VERIFICATION_FAILURE"
+	AuditEventVerificationReport  AuditEvent = "VERIFICATION_REPORT"
 
 	// Security events
 	AuditEventSuspiciousActivity AuditEvent = "SUSPICIOUS_ACTIVITY"
@@ -40,23 +100,23 @@ const (
 
 // AuditLogEntry represents a structured audit log entry
 type AuditLogEntry struct {
-	Timestamp   time.Time              `json:"timestamp"`
-	Event       AuditEvent             `json:"event"`
-	UserID      string                 `json:"user_id,omitempty"`
-	Username    string                 `json:"username,omitempty"`
-	IPAddress   string                 `json:"ip_address,omitempty"`
-	UserAgent   string                 `json:"user_agent,omitempty"`
-	Resource    string                 `json:"resource,omitempty"`
-	Action      string                 `json:"action,omitempty"`
-	Result      string                 `json:"result"`
-	Message     string                 `json:"message,omitempty"`
-	Details     map[string]interface{} `json:"details,omitempty"`
-	SessionID   string                 `json:"session_id,omitempty"`
-	DocumentID  string                 `json:"document_id,omitempty"`
-	RequestID   string                 `json:"request_id,omitempty"`
-	Duration    int64                  `json:"duration_ms,omitempty"`
-	ErrorCode   string                 `json:"error_code,omitempty"`
-	Severity    string                 `json:"severity"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Event      AuditEvent             `json:"event"`
+	UserID     string                 `json:"user_id,omitempty"`
+	Username   string                 `json:"username,omitempty"`
+	IPAddress  string                 `json:"ip_address,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	Resource   string                 `json:"resource,omitempty"`
+	Action     string                 `json:"action,omitempty"`
+	Result     string                 `json:"result"`
+	Message    string                 `json:"message,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	DocumentID string                 `json:"document_id,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Duration   int64                  `json:"duration_ms,omitempty"`
+	ErrorCode  string                 `json:"error_code,omitempty"`
+	Severity   string                 `json:"severity"`
 }
 
 // AuditLogger handles audit logging with structured JSON format
@@ -108,7 +168,7 @@ func InitializeAuditLogger(logDir string) error {
 // rotateAuditLogsIfNeeded performs basic log rotation based on file size
 func rotateAuditLogsIfNeeded(logDir string) error {
 	auditLogFile := filepath.Join(logDir, "audit.log")
-	
+
 	// Check if audit log file exists
 	info, err := os.Stat(auditLogFile)
 	if os.IsNotExist(err) {
@@ -124,7 +184,7 @@ func rotateAuditLogsIfNeeded(logDir string) error {
 		// Create backup filename with timestamp
 		timestamp := time.Now().Format("2006-01-02-15-04-05")
 		backupFile := filepath.Join(logDir, fmt.Sprintf("audit.log.%s", timestamp))
-		
+
 		// Rename current log file to backup
 		if err := os.Rename(auditLogFile, backupFile); err != nil {
 			return fmt.Errorf("failed to rotate audit log: %w", err)
@@ -192,6 +252,29 @@ func cleanupOldAuditLogs(logDir string, keepCount int) error {
 	return nil
 }
 
+// PruneAuditLogs removes rotated audit log files last written before the cutoff
+// and returns how many were removed; the active audit.log is never touched
+func PruneAuditLogs(logDir string, before time.Time) (int, error) {
+	matches, err := filepath.Glob(filepath.Join(logDir, "audit.log.*"))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(match); err != nil {
+			return removed, fmt.Errorf("failed to remove audit log %s: %w", match, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
 // GetAuditLogger returns the default audit logger instance
 func GetAuditLogger() *AuditLogger {
 	if defaultAuditLogger == nil {
@@ -230,11 +313,14 @@ func (a *AuditLogger) LogEvent(entry AuditLogEntry) {
 // getSeverityForEvent returns the default severity level for an event type
 func (a *AuditLogger) getSeverityForEvent(event AuditEvent) string {
 	switch event {
-	case AuditEventAuthFailure, AuditEventSuspiciousActivity, AuditEventRateLimitExceeded:
+	case AuditEventAuthFailure, AuditEventAccountLocked, AuditEventSuspiciousActivity, AuditEventRateLimitExceeded:
 		return "HIGH"
 	case AuditEventVerificationFailure, AuditEventValidationFailure:
 		return "MEDIUM"
-	case AuditEventLogin, AuditEventLogout, AuditEventDocumentSign, AuditEventDocumentDelete:
+	case AuditEventLogin, AuditEventLogout, AuditEventDocumentSign, AuditEventDocumentDelete, AuditEventDocumentBatchSign:
+		return "MEDIUM"
+	case AuditEventMFAEnroll, AuditEventMFADisable, AuditEventMFARecovery, AuditEventWebAuthnAdd, AuditEventWebAuthnRemove,
+		AuditEventSessionRevoke, AuditEventMaintenanceRun:
 		return "MEDIUM"
 	default:
 		return "LOW"
@@ -274,6 +360,21 @@ func (a *AuditLogger) LogDocumentOperation(event AuditEvent, userID, username, d
 	a.LogEvent(entry)
 }
 
+// LogResourceOperation logs changes a user makes to a non-document resource
+func (a *AuditLogger) LogResourceOperation(event AuditEvent, userID, username, resource, ipAddress string, result string, details map[string]interface{}) {
+	entry := AuditLogEntry{
+		Event:     event,
+		UserID:    userID,
+		Username:  username,
+		IPAddress: ipAddress,
+		Resource:  resource,
+		Action:    string(event),
+		Result:    result,
+		Details:   details,
+	}
+	a.LogEvent(entry)
+}
+
 // LogVerificationAttempt logs document verification attempts
 func (a *AuditLogger) LogVerificationAttempt(event AuditEvent, documentID, ipAddress, userAgent string, result string, details map[string]interface{}) {
 	entry := AuditLogEntry{
@@ -314,10 +415,14 @@ func LogDocumentOperation(event AuditEvent, userID, username, documentID, ipAddr
 	GetAuditLogger().LogDocumentOperation(event, userID, username, documentID, ipAddress, result, details)
 }
 
+func LogResourceOperation(event AuditEvent, userID, username, resource, ipAddress string, result string, details map[string]interface{}) {
+	GetAuditLogger().LogResourceOperation(event, userID, username, resource, ipAddress, result, details)
+}
+
 func LogVerificationAttempt(event AuditEvent, documentID, ipAddress, userAgent string, result string, details map[string]interface{}) {
 	GetAuditLogger().LogVerificationAttempt(event, documentID, ipAddress, userAgent, result, details)
 }
 
 func LogSecurityEvent(event AuditEvent, ipAddress, userAgent, message string, details map[string]interface{}) {
 	GetAuditLogger().LogSecurityEvent(event, ipAddress, userAgent, message, details)
-}
\ No newline at end of file
+}
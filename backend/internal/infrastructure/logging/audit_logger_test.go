@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -237,6 +239,39 @@ func TestInitializeAuditLogger(t *testing.T) {
 	assert.NoError(t, err, "Audit log file should be created")
 }
 
+func TestPruneAuditLogs(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Now()
+
+	files := map[string]time.Time{
+		"audit.log":                 now.Add(-400 * 24 * time.Hour),
+		"audit.log.20240101-000000": now.Add(-200 * 24 * time.Hour),
+		"audit.log.20250101-000000": now.Add(-100 * 24 * time.Hour),
+		"audit.log.20260101-000000": now.Add(-time.Hour),
+		"app.log.20240101-000000":   now.Add(-200 * 24 * time.Hour),
+	}
+	for name, modTime := range files {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	removed, err := PruneAuditLogs(tempDir, now.Add(-90*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	for name, kept := range map[string]bool{
+		"audit.log":                 true,
+		"audit.log.20240101-000000": false,
+		"audit.log.20250101-000000": false,
+		"audit.log.20260101-000000": true,
+		"app.log.20240101-000000":   true,
+	} {
+		_, err := os.Stat(filepath.Join(tempDir, name))
+		assert.Equal(t, kept, err == nil, name)
+	}
+}
+
 func TestAuditPackageLevelFunctions(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir := t.TempDir()
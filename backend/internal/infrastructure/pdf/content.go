@@ -0,0 +1,334 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unidoc/unipdf/v3/common/license"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+const (
+	// MaxContentText caps the text kept for a document, counting a separator
+	// between pages, so its search vector stays well inside PostgreSQL's 1MB
+	// tsvector limit
+	MaxContentText = 512 * 1024
+	// MaxXMPSize caps the XMP metadata packet kept for a document
+	MaxXMPSize = 64 * 1024
+)
+
+// PDFContent is the metadata and text extracted from a PDF
+type PDFContent struct {
+	Version       string         `json:"version"`
+	Encrypted     bool           `json:"encrypted"`
+	Info          PDFInfo        `json:"info"`
+	XMP           string         `json:"xmp,omitempty"`
+	Pages         []PageContent  `json:"pages"`
+	EmbeddedFiles []EmbeddedFile `json:"embedded_files,omitempty"`
+	// TextTruncated is set when the pages held more text than MaxContentText
+	TextTruncated bool `json:"text_truncated,omitempty"`
+}
+
+// PageContent describes one page; sizes are in points
+type PageContent struct {
+	Number   int     `json:"number"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation int64   `json:"rotation,omitempty"`
+	Text     string  `json:"text,omitempty"`
+}
+
+// EmbeddedFile describes a file attached to a PDF
+type EmbeddedFile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+	Size        int    `json:"size"`
+}
+
+// ExtractContent reads the metadata and text of a PDF held in memory
+func (s *PDFService) ExtractContent(pdfData []byte) (*PDFContent, error) {
+	if err := s.ValidatePDF(pdfData); err != nil {
+		return nil, fmt.Errorf("PDF validation failed: %w", err)
+	}
+
+	pdfReader, err := model.NewPdfReader(bytes.NewReader(pdfData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF reader: %w", err)
+	}
+	return extractContent(pdfReader, int64(len(pdfData)))
+}
+
+// ExtractContentFromSpool reads the metadata and text of a spooled PDF
+func (s *PDFService) ExtractContentFromSpool(src *SpooledPDF) (*PDFContent, error) {
+	if src == nil || src.reader == nil {
+		return nil, fmt.Errorf("%w: spooled PDF is not open", ErrInvalidPDF)
+	}
+	return extractContent(src.reader, src.size)
+}
+
+func extractContent(pdfReader *model.PdfReader, fileSize int64) (*PDFContent, error) {
+	content := &PDFContent{
+		Version: pdfReader.PdfVersion().String(),
+		Info:    PDFInfo{FileSize: fileSize},
+	}
+
+	encrypted, err := pdfReader.IsEncrypted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check encryption: %w", err)
+	}
+	if encrypted {
+		content.Encrypted = true
+		// Documents that only restrict permissions open with the empty user password;
+		// nothing more can be read from the others
+		if ok, err := pdfReader.Decrypt([]byte("")); err != nil || !ok {
+			return content, nil
+		}
+	}
+
+	content.Info.NumPages, err = pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number of pages: %w", err)
+	}
+	readInfo(pdfReader, &content.Info)
+	content.XMP = readXMP(pdfReader)
+	content.EmbeddedFiles = readEmbeddedFiles(pdfReader)
+
+	remaining := MaxContentText
+	for number := 1; number <= content.Info.NumPages; number++ {
+		page, err := pdfReader.GetPage(number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page %d: %w", number, err)
+		}
+
+		pageContent := PageContent{Number: number}
+		if box, err := page.GetMediaBox(); err == nil {
+			pageContent.Width, pageContent.Height = box.Width(), box.Height()
+		}
+		if rotation, err := page.GetRotate(); err == nil {
+			pageContent.Rotation = rotation
+		}
+
+		if remaining > 0 {
+			text := pageText(page)
+			if len(text) > remaining {
+				text = truncateUTF8(text, remaining)
+				content.TextTruncated = true
+			}
+			remaining -= len(text) + 1
+			pageContent.Text = text
+		} else if !content.TextTruncated {
+			content.TextTruncated = pageText(page) != ""
+		}
+		content.Pages = append(content.Pages, pageContent)
+	}
+
+	return content, nil
+}
+
+// readInfo copies the document information dictionary into info
+func readInfo(pdfReader *model.PdfReader, info *PDFInfo) {
+	pdfInfo, err := pdfReader.GetPdfInfo()
+	if err != nil || pdfInfo == nil {
+		return
+	}
+	info.Title = decodedString(pdfInfo.Title)
+	info.Author = decodedString(pdfInfo.Author)
+	info.Subject = decodedString(pdfInfo.Subject)
+	info.Keywords = decodedString(pdfInfo.Keywords)
+	info.Creator = decodedString(pdfInfo.Creator)
+	info.Producer = decodedString(pdfInfo.Producer)
+	if pdfInfo.CreationDate != nil {
+		created := pdfInfo.CreationDate.ToGoTime()
+		info.CreationDate = &created
+	}
+	if pdfInfo.ModifiedDate != nil {
+		modified := pdfInfo.ModifiedDate.ToGoTime()
+		info.ModifiedDate = &modified
+	}
+}
+
+func decodedString(s *core.PdfObjectString) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(s.Decoded())
+}
+
+// readXMP returns the catalog's XMP metadata packet, or "" when it has none
+func readXMP(pdfReader *model.PdfReader) string {
+	obj, ok := pdfReader.GetCatalogMetadata()
+	if !ok {
+		return ""
+	}
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return ""
+	}
+	data, err := core.DecodeStream(stream)
+	if err != nil || len(data) > MaxXMPSize || !utf8.Valid(data) {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readEmbeddedFiles lists the files attached to the document
+func readEmbeddedFiles(pdfReader *model.PdfReader) []EmbeddedFile {
+	attached, err := pdfReader.GetAttachedFiles()
+	if err != nil {
+		return nil
+	}
+	var files []EmbeddedFile
+	for _, file := range attached {
+		files = append(files, EmbeddedFile{
+			Name:        file.Name,
+			Description: file.Description,
+			MimeType:    file.FileType,
+			Size:        len(file.Content),
+		})
+	}
+	return files
+}
+
+// pageText extracts a page's text. Without a UniPDF license the extractor is
+// unavailable, so the text operators of the page's content stream are decoded
+// directly, which is good enough for search.
+func pageText(page *model.PdfPage) string {
+	if key := license.GetLicenseKey(); key != nil && key.IsLicensed() {
+		if textExtractor, err := extractor.New(page); err == nil {
+			if text, err := textExtractor.ExtractText(); err == nil {
+				return normalizeText(text)
+			}
+		}
+	}
+	return normalizeText(contentStreamText(page))
+}
+
+// contentStreamText decodes the strings shown by the page's text operators,
+// starting a new line wherever the text moves to one
+func contentStreamText(page *model.PdfPage) string {
+	streams, err := page.GetAllContentStreams()
+	if err != nil {
+		return ""
+	}
+	operations, err := contentstream.NewContentStreamParser(streams).Parse()
+	if err != nil {
+		return ""
+	}
+
+	var text strings.Builder
+	fonts := map[string]*model.PdfFont{}
+	var font *model.PdfFont
+	for _, op := range *operations {
+		switch op.Operand {
+		case "Tf":
+			if len(op.Params) > 0 {
+				if name, ok := core.GetName(op.Params[0]); ok {
+					font = pageFont(page, fonts, string(*name))
+				}
+			}
+		case "Tj":
+			if len(op.Params) > 0 {
+				text.WriteString(showString(font, op.Params[0]))
+			}
+		case "'", "\"":
+			text.WriteString("\n")
+			if len(op.Params) > 0 {
+				text.WriteString(showString(font, op.Params[len(op.Params)-1]))
+			}
+		case "TJ":
+			if len(op.Params) == 0 {
+				continue
+			}
+			array, ok := core.GetArray(op.Params[0])
+			if !ok {
+				continue
+			}
+			for _, element := range array.Elements() {
+				// Large negative adjustments separate words
+				if adjustment, err := core.GetNumberAsFloat(element); err == nil {
+					if adjustment < -200 {
+						text.WriteString(" ")
+					}
+					continue
+				}
+				text.WriteString(showString(font, element))
+			}
+		case "Td", "TD":
+			if len(op.Params) == 2 {
+				if ty, err := core.GetNumberAsFloat(op.Params[1]); err == nil && ty != 0 {
+					text.WriteString("\n")
+					continue
+				}
+			}
+			text.WriteString(" ")
+		case "T*", "ET":
+			text.WriteString("\n")
+		case "Tm":
+			text.WriteString(" ")
+		}
+	}
+	return text.String()
+}
+
+// pageFont resolves a font resource of the page, caching it by name
+func pageFont(page *model.PdfPage, fonts map[string]*model.PdfFont, name string) *model.PdfFont {
+	if font, ok := fonts[name]; ok {
+		return font
+	}
+	var font *model.PdfFont
+	if page.Resources != nil {
+		if obj, ok := page.Resources.GetFontByName(core.PdfObjectName(name)); ok {
+			font, _ = model.NewPdfFontFromPdfObject(obj)
+		}
+	}
+	fonts[name] = font
+	return font
+}
+
+// showString decodes a string operand with the current font, falling back to
+// Latin-1 when the font is unknown
+func showString(font *model.PdfFont, obj core.PdfObject) string {
+	str, ok := core.GetString(obj)
+	if !ok {
+		return ""
+	}
+	data := str.Bytes()
+	if font != nil {
+		text, _, _ := font.CharcodeBytesToUnicode(data)
+		return text
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// normalizeText trims each line, collapses runs of spaces and drops empty lines
+func normalizeText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF assembles a PDF from numbered objects with a correct cross-reference table
+func buildPDF(version string, objects []string, trailer string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%%PDF-%s\n", version)
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF", len(objects)+1, trailer, xref)
+	return buf.Bytes()
+}
+
+func stream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
+
+// createTextPDF creates a two-page PDF with text, an information dictionary and XMP metadata
+func createTextPDF() []byte {
+	xmp := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><dc:title>Quarterly Report</dc:title></x:xmpmeta>`
+	return buildPDF("1.6", []string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 8 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 5 0 R /Resources << /Font << /F1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 842 595] /Rotate 90 /Contents 6 0 R /Resources << /Font << /F1 7 0 R >> >> >>",
+		stream("BT /F1 12 Tf 72 720 Td (Quarterly   report) Tj 0 -14 Td [(Revenue) -250 (grew)] TJ ET"),
+		stream("BT /F1 12 Tf 72 500 Td (Appendix) Tj ET"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp), xmp),
+		"<< /Title (Quarterly Report) /Author (Finance Office) /Subject (Results) /Keywords (revenue, q1) /Producer (Test Writer) /CreationDate (D:20260301120000Z) >>",
+	}, "/Root 1 0 R /Info 9 0 R")
+}
+
+func TestPDFService_ExtractContent(t *testing.T) {
+	service := NewPDFService()
+	data := createTextPDF()
+
+	content, err := service.ExtractContent(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.6", content.Version)
+	assert.False(t, content.Encrypted)
+	assert.Equal(t, 2, content.Info.NumPages)
+	assert.Equal(t, int64(len(data)), content.Info.FileSize)
+	assert.Equal(t, "Quarterly Report", content.Info.Title)
+	assert.Equal(t, "Finance Office", content.Info.Author)
+	assert.Equal(t, "Results", content.Info.Subject)
+	assert.Equal(t, "revenue, q1", content.Info.Keywords)
+	assert.Equal(t, "Test Writer", content.Info.Producer)
+	require.NotNil(t, content.Info.CreationDate)
+	assert.True(t, content.Info.CreationDate.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Contains(t, content.XMP, "<dc:title>Quarterly Report</dc:title>")
+	assert.Empty(t, content.EmbeddedFiles)
+
+	require.Len(t, content.Pages, 2)
+	assert.Equal(t, PageContent{Number: 1, Width: 595, Height: 842, Text: "Quarterly report\nRevenue grew"}, content.Pages[0])
+	assert.Equal(t, PageContent{Number: 2, Width: 842, Height: 595, Rotation: 90, Text: "Appendix"}, content.Pages[1])
+	assert.False(t, content.TextTruncated)
+}
+
+func TestPDFService_ExtractContent_Minimal(t *testing.T) {
+	content, err := NewPDFService().ExtractContent(createMinimalPDF())
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.4", content.Version)
+	assert.Empty(t, content.Info.Title)
+	assert.Empty(t, content.XMP)
+	require.Len(t, content.Pages, 1)
+	assert.Equal(t, PageContent{Number: 1, Width: 612, Height: 792}, content.Pages[0])
+}
+
+func TestPDFService_ExtractContent_Invalid(t *testing.T) {
+	_, err := NewPDFService().ExtractContent([]byte("not a pdf"))
+	assert.ErrorContains(t, err, "PDF validation failed")
+}
+
+func TestPDFService_ExtractContentFromSpool(t *testing.T) {
+	service := NewPDFServiceWithLimits(MaxPDFSize, t.TempDir())
+	spooled, err := service.SpoolPDF(bytes.NewReader(createTextPDF()))
+	require.NoError(t, err)
+	defer spooled.Close()
+
+	content, err := service.ExtractContentFromSpool(spooled)
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly Report", content.Info.Title)
+	require.Len(t, content.Pages, 2)
+	assert.Equal(t, "Appendix", content.Pages[1].Text)
+
+	_, err = service.ExtractContentFromSpool(nil)
+	assert.ErrorIs(t, err, ErrInvalidPDF)
+}
+
+func TestNormalizeText(t *testing.T) {
+	assert.Equal(t, "one two\nthree", normalizeText("  one   two \n\n\tthree  \n"))
+	assert.Equal(t, "", normalizeText(" \n "))
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "caf", truncateUTF8("café", 4))
+	assert.Equal(t, "café", truncateUTF8("café", 5))
+	assert.Equal(t, strings.Repeat("a", 3), truncateUTF8("aaaa", 3))
+}
@@ -11,6 +11,7 @@ import (
 	"image/png"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/skip2/go-qrcode"
 	"github.com/unidoc/unipdf/v3/creator"
@@ -21,18 +22,38 @@ import (
 )
 
 const (
-	MaxPDFSize = 50 * 1024 * 1024 // 50MB
+	MaxPDFSize = 50 * 1024 * 1024 // 50MB, used when no limit is configured
 )
 
 // PDFService handles PDF processing operations
 // Note: PDF modification operations (InjectQRCode) require a UniPDF license for production use.
 // For development and testing, these operations will return license errors.
 // Get a free trial license at https://unidoc.io
-type PDFService struct{}
+type PDFService struct {
+	maxSize  int64
+	spoolDir string
+}
 
 // NewPDFService creates a new PDF service instance
 func NewPDFService() *PDFService {
-	return &PDFService{}
+	return NewPDFServiceWithLimits(MaxPDFSize, "")
+}
+
+// NewPDFServiceWithLimits creates a PDF service with a configured size limit and spool directory
+// (an empty spoolDir uses the system temp directory)
+func NewPDFServiceWithLimits(maxSize int64, spoolDir string) *PDFService {
+	if maxSize <= 0 {
+		maxSize = MaxPDFSize
+	}
+	return &PDFService{
+		maxSize:  maxSize,
+		spoolDir: spoolDir,
+	}
+}
+
+// MaxSize returns the largest PDF the service accepts
+func (s *PDFService) MaxSize() int64 {
+	return s.maxSize
 }
 
 // ValidatePDF validates if the provided data is a valid PDF
@@ -41,12 +62,12 @@ func (s *PDFService) ValidatePDF(pdfData []byte) error {
 		return fmt.Errorf("PDF data is empty")
 	}
 
-	if len(pdfData) > MaxPDFSize {
-		return fmt.Errorf("PDF size exceeds maximum allowed size of %d bytes", MaxPDFSize)
+	if int64(len(pdfData)) > s.maxSize {
+		return fmt.Errorf("PDF size exceeds maximum allowed size of %d bytes", s.maxSize)
 	}
 
 	// Check PDF header
-	if len(pdfData) < 4 || string(pdfData[:4]) != "%PDF" {
+	if !hasPDFHeader(pdfData) {
 		return fmt.Errorf("invalid PDF format: missing PDF header")
 	}
 
@@ -60,6 +81,11 @@ func (s *PDFService) ValidatePDF(pdfData []byte) error {
 	return nil
 }
 
+// hasPDFHeader reports whether data starts with the %PDF magic bytes
+func hasPDFHeader(data []byte) bool {
+	return len(data) >= 4 && string(data[:4]) == "%PDF"
+}
+
 // CalculateHash calculates SHA-256 hash of the PDF document
 func (s *PDFService) CalculateHash(pdfData []byte) ([]byte, error) {
 	if err := s.ValidatePDF(pdfData); err != nil {
@@ -70,7 +96,7 @@ func (s *PDFService) CalculateHash(pdfData []byte) ([]byte, error) {
 	return hash[:], nil
 }
 
-// GetPDFInfo extracts basic information from the PDF
+// GetPDFInfo extracts the page count, size and information dictionary of the PDF
 func (s *PDFService) GetPDFInfo(pdfData []byte) (*PDFInfo, error) {
 	if err := s.ValidatePDF(pdfData); err != nil {
 		return nil, fmt.Errorf("PDF validation failed: %w", err)
@@ -91,10 +117,7 @@ func (s *PDFService) GetPDFInfo(pdfData []byte) (*PDFInfo, error) {
 		NumPages: numPages,
 		FileSize: int64(len(pdfData)),
 	}
-
-	// Note: Document metadata extraction would require additional unipdf API calls
-	// For now, we focus on basic info (pages and file size) which are sufficient
-	// for the core functionality
+	readInfo(pdfReader, info)
 
 	return info, nil
 }
@@ -103,8 +126,8 @@ func (s *PDFService) GetPDFInfo(pdfData []byte) (*PDFInfo, error) {
 func (s *PDFService) ReadPDFFromReader(reader io.Reader) ([]byte, error) {
 	var buf bytes.Buffer
 
-	// Limit the read to MaxPDFSize to prevent memory issues
-	limitedReader := io.LimitReader(reader, MaxPDFSize+1)
+	// Limit the read to the configured size to prevent memory issues
+	limitedReader := io.LimitReader(reader, s.maxSize+1)
 
 	_, err := buf.ReadFrom(limitedReader)
 	if err != nil {
@@ -114,8 +137,8 @@ func (s *PDFService) ReadPDFFromReader(reader io.Reader) ([]byte, error) {
 	pdfData := buf.Bytes()
 
 	// Check if the file exceeds the maximum size
-	if len(pdfData) > MaxPDFSize {
-		return nil, fmt.Errorf("PDF size exceeds maximum allowed size of %d bytes", MaxPDFSize)
+	if int64(len(pdfData)) > s.maxSize {
+		return nil, fmt.Errorf("PDF size exceeds maximum allowed size of %d bytes", s.maxSize)
 	}
 
 	return pdfData, nil
@@ -300,10 +323,32 @@ func (s *PDFService) drawCenterText(img *image.RGBA, text string, bounds image.R
 // InjectQRCode injects a QR code into the PDF at the specified position
 // Note: This requires a UniPDF license for PDF modification operations
 func (s *PDFService) InjectQRCode(pdfData []byte, qrCodeData QRCodeData, position *QRPosition) ([]byte, error) {
-	if err := s.ValidatePDF(pdfData); err != nil {
-		return nil, fmt.Errorf("PDF validation failed: %w", err)
+	if len(pdfData) == 0 {
+		return nil, fmt.Errorf("PDF validation failed: PDF data is empty")
+	}
+	if int64(len(pdfData)) > s.maxSize {
+		return nil, fmt.Errorf("PDF validation failed: PDF size exceeds maximum allowed size of %d bytes", s.maxSize)
+	}
+	if !hasPDFHeader(pdfData) {
+		return nil, fmt.Errorf("PDF validation failed: invalid PDF format: missing PDF header")
+	}
+
+	// Parse once; a parse failure is the same validation error ValidatePDF would report
+	pdfReader, err := model.NewPdfReader(bytes.NewReader(pdfData))
+	if err != nil {
+		return nil, fmt.Errorf("PDF validation failed: invalid PDF format: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.writeWithQRCode(pdfReader, qrCodeData, position, &buf); err != nil {
+		return nil, err
 	}
 
+	return buf.Bytes(), nil
+}
+
+// writeWithQRCode copies every page of an already parsed PDF and stamps the QR code on the last one
+func (s *PDFService) writeWithQRCode(pdfReader *model.PdfReader, qrCodeData QRCodeData, position *QRPosition, w io.Writer) error {
 	// Use default position if none provided
 	if position == nil {
 		defaultPos := DefaultQRPosition()
@@ -313,14 +358,7 @@ func (s *PDFService) InjectQRCode(pdfData []byte, qrCodeData QRCodeData, positio
 	// Generate QR code image
 	qrCodeImage, err := s.GenerateQRCode(qrCodeData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate QR code: %w", err)
-	}
-
-	// Read the original PDF
-	reader := bytes.NewReader(pdfData)
-	pdfReader, err := model.NewPdfReader(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create PDF reader: %w", err)
+		return fmt.Errorf("failed to generate QR code: %w", err)
 	}
 
 	// Create a new PDF creator
@@ -329,43 +367,42 @@ func (s *PDFService) InjectQRCode(pdfData []byte, qrCodeData QRCodeData, positio
 	// Get the number of pages
 	numPages, err := pdfReader.GetNumPages()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get number of pages: %w", err)
+		return fmt.Errorf("failed to get number of pages: %w", err)
 	}
 
 	// Copy all pages to the new PDF
 	for i := 1; i <= numPages; i++ {
 		page, err := pdfReader.GetPage(i)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get page %d: %w", i, err)
+			return fmt.Errorf("failed to get page %d: %w", i, err)
 		}
 
 		// Import the page
 		err = c.AddPage(page)
 		if err != nil {
-			return nil, fmt.Errorf("failed to add page %d: %w", i, err)
+			return fmt.Errorf("failed to add page %d: %w", i, err)
 		}
 
 		// Add QR code to the last page
 		if i == numPages {
 			err = s.addQRCodeToPage(c, qrCodeImage, *position)
 			if err != nil {
-				return nil, fmt.Errorf("failed to add QR code to page: %w", err)
+				return fmt.Errorf("failed to add QR code to page: %w", err)
 			}
 		}
 	}
 
-	// Write the modified PDF to buffer
-	var buf bytes.Buffer
-	err = c.Write(&buf)
+	// Write the modified PDF
+	err = c.Write(w)
 	if err != nil {
 		// Handle license error gracefully for development
 		if strings.Contains(err.Error(), "license") {
-			return nil, fmt.Errorf("PDF modification requires UniPDF license: %w", err)
+			return fmt.Errorf("PDF modification requires UniPDF license: %w", err)
 		}
-		return nil, fmt.Errorf("failed to write modified PDF: %w", err)
+		return fmt.Errorf("failed to write modified PDF: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	return nil
 }
 
 // addQRCodeToPage adds a QR code image to the current page in the creator
@@ -386,16 +423,33 @@ func (s *PDFService) addQRCodeToPage(c *creator.Creator, qrCodeImage []byte, pos
 		return fmt.Errorf("failed to draw QR code image: %w", err)
 	}
 
+	if position.Caption != "" {
+		caption := c.NewParagraph(position.Caption)
+		caption.SetFontSize(8)
+		caption.SetWidth(position.Width)
+		caption.SetTextAlignment(creator.TextAlignmentCenter)
+		caption.SetPos(position.X, position.Y+img.Height()+2)
+		if err := c.Draw(caption); err != nil {
+			return fmt.Errorf("failed to draw QR code caption: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// PDFInfo contains basic information about a PDF document
+// PDFInfo contains basic information about a PDF document and the fields of
+// its document information dictionary
 type PDFInfo struct {
-	NumPages int    `json:"num_pages"`
-	FileSize int64  `json:"file_size"`
-	Title    string `json:"title,omitempty"`
-	Author   string `json:"author,omitempty"`
-	Subject  string `json:"subject,omitempty"`
+	NumPages     int        `json:"num_pages"`
+	FileSize     int64      `json:"file_size"`
+	Title        string     `json:"title,omitempty"`
+	Author       string     `json:"author,omitempty"`
+	Subject      string     `json:"subject,omitempty"`
+	Keywords     string     `json:"keywords,omitempty"`
+	Creator      string     `json:"creator,omitempty"`
+	Producer     string     `json:"producer,omitempty"`
+	CreationDate *time.Time `json:"creation_date,omitempty"`
+	ModifiedDate *time.Time `json:"modified_date,omitempty"`
 }
 
 // QRCodeData contains the data to be encoded in the QR code
@@ -404,6 +458,8 @@ type QRCodeData struct {
 	Hash      string `json:"hash"`
 	Signature string `json:"signature"`
 	Timestamp int64  `json:"timestamp"`
+	// LetterNumber is set when a numbering scheme allocated it; the signature covers it
+	LetterNumber string `json:"letter_number,omitempty"`
 }
 
 // QRPosition defines where to place the QR code on the page
@@ -412,6 +468,8 @@ type QRPosition struct {
 	Y      float64 `json:"y"`      // Y coordinate (points from bottom)
 	Width  float64 `json:"width"`  // QR code width in points
 	Height float64 `json:"height"` // QR code height in points
+	// Caption is printed centred under the QR code, such as its access code
+	Caption string `json:"caption,omitempty"`
 }
 
 // DefaultQRPosition returns the default position for QR code (bottom right of last page)
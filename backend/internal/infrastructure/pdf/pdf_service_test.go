@@ -385,6 +385,16 @@ func TestPDFService_GetPDFInfo(t *testing.T) {
 	}
 }
 
+func TestPDFService_GetPDFInfo_Metadata(t *testing.T) {
+	info, err := NewPDFService().GetPDFInfo(createTextPDF())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, info.NumPages)
+	assert.Equal(t, "Quarterly Report", info.Title)
+	assert.Equal(t, "Finance Office", info.Author)
+	assert.Equal(t, "Results", info.Subject)
+}
+
 func TestPDFService_ReadPDFFromReader(t *testing.T) {
 	service := NewPDFService()
 
@@ -635,6 +645,20 @@ func TestPDFService_InjectQRCode(t *testing.T) {
 			wantErr: true, // Expect license error in test environment
 			errMsg:  "license",
 		},
+		{
+			name:    "valid PDF with captioned position (license required)",
+			pdfData: pdfData,
+			qrData:  qrData,
+			position: &QRPosition{
+				X:       100,
+				Y:       100,
+				Width:   80,
+				Height:  80,
+				Caption: "Code: ABCD-2345",
+			},
+			wantErr: true, // Expect license error in test environment
+			errMsg:  "license",
+		},
 		{
 			name:     "invalid PDF data",
 			pdfData:  []byte("not a pdf"),
@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+var (
+	// ErrPDFTooLarge is returned when a streamed PDF exceeds the configured size limit
+	ErrPDFTooLarge = errors.New("PDF size exceeds maximum allowed size")
+	// ErrInvalidPDF is returned when a streamed upload is empty or cannot be parsed as a PDF
+	ErrInvalidPDF = errors.New("invalid PDF format")
+)
+
+// SpooledPDF is an uploaded PDF held in a temporary file. It is hashed while it is
+// written and parsed exactly once, so callers never need the whole document in memory.
+type SpooledPDF struct {
+	file   *os.File
+	size   int64
+	hash   []byte
+	reader *model.PdfReader
+}
+
+// SpoolPDF streams a PDF to a temporary file, computing its SHA-256 hash on the way,
+// then validates the header and parses it once. The caller must Close the result.
+func (s *PDFService) SpoolPDF(reader io.Reader) (*SpooledPDF, error) {
+	file, err := os.CreateTemp(s.spoolDir, "upload-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+
+	spooled := &SpooledPDF{file: file}
+	if err := spooled.fill(reader, s.maxSize); err != nil {
+		spooled.Close()
+		return nil, err
+	}
+
+	return spooled, nil
+}
+
+// fill copies at most maxSize bytes into the spool file, then checks and parses the result
+func (p *SpooledPDF) fill(reader io.Reader, maxSize int64) error {
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(p.file, hasher), io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read PDF data: %w", err)
+	}
+	if written == 0 {
+		return fmt.Errorf("%w: PDF data is empty", ErrInvalidPDF)
+	}
+	if written > maxSize {
+		return fmt.Errorf("%w of %d bytes", ErrPDFTooLarge, maxSize)
+	}
+
+	p.size = written
+	p.hash = hasher.Sum(nil)
+
+	header := make([]byte, 4)
+	if _, err := p.file.ReadAt(header, 0); err != nil || !hasPDFHeader(header) {
+		return fmt.Errorf("%w: missing PDF header", ErrInvalidPDF)
+	}
+
+	// The reader keeps using the file for lazily loaded objects, so it stays open until Close
+	pdfReader, err := model.NewPdfReader(io.NewSectionReader(p.file, 0, p.size))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+	}
+	p.reader = pdfReader
+
+	return nil
+}
+
+// Hash returns the SHA-256 hash of the original bytes
+func (p *SpooledPDF) Hash() []byte {
+	return p.hash
+}
+
+// Size returns the size of the original document in bytes
+func (p *SpooledPDF) Size() int64 {
+	return p.size
+}
+
+// Path returns the location of the spool file
+func (p *SpooledPDF) Path() string {
+	return p.file.Name()
+}
+
+// PdfReader returns the parsed document
+func (p *SpooledPDF) PdfReader() *model.PdfReader {
+	return p.reader
+}
+
+// WriteTo copies the original bytes to w without disturbing the parsed reader
+func (p *SpooledPDF) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, io.NewSectionReader(p.file, 0, p.size))
+}
+
+// Close releases the spool file and removes it from disk
+func (p *SpooledPDF) Close() error {
+	if p == nil || p.file == nil {
+		return nil
+	}
+	name := p.file.Name()
+	closeErr := p.file.Close()
+	p.file = nil
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return closeErr
+}
+
+// InjectQRCodeFromSpool stamps the QR code onto a spooled PDF and streams the result to w.
+// The output is staged in a temporary file so nothing reaches w if injection fails part way.
+func (s *PDFService) InjectQRCodeFromSpool(src *SpooledPDF, qrCodeData QRCodeData, position *QRPosition, w io.Writer) error {
+	if src == nil || src.reader == nil {
+		return fmt.Errorf("PDF validation failed: no parsed document")
+	}
+
+	staged, err := os.CreateTemp(s.spoolDir, "signed-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() {
+		staged.Close()
+		os.Remove(staged.Name())
+	}()
+
+	if err := s.writeWithQRCode(src.reader, qrCodeData, position, staged); err != nil {
+		return err
+	}
+
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind output file: %w", err)
+	}
+	if _, err := io.Copy(w, staged); err != nil {
+		return fmt.Errorf("failed to write modified PDF: %w", err)
+	}
+
+	return nil
+}
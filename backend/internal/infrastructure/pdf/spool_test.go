@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFService_SpoolPDF(t *testing.T) {
+	service := NewPDFServiceWithLimits(MaxPDFSize, t.TempDir())
+	pdfData := createMinimalPDF()
+
+	spooled, err := service.SpoolPDF(bytes.NewReader(pdfData))
+	require.NoError(t, err)
+	defer spooled.Close()
+
+	// The streamed hash must match the in-memory one so stored signatures still verify
+	expectedHash, err := service.CalculateHash(pdfData)
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, spooled.Hash())
+	assert.Equal(t, int64(len(pdfData)), spooled.Size())
+
+	numPages, err := spooled.PdfReader().GetNumPages()
+	require.NoError(t, err)
+	assert.Equal(t, 1, numPages)
+
+	var copied bytes.Buffer
+	_, err = spooled.WriteTo(&copied)
+	require.NoError(t, err)
+	assert.Equal(t, pdfData, copied.Bytes())
+}
+
+func TestPDFService_SpoolPDF_Errors(t *testing.T) {
+	service := NewPDFServiceWithLimits(64, t.TempDir())
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "empty upload", data: []byte{}, wantErr: ErrInvalidPDF},
+		{name: "missing header", data: []byte("not a pdf"), wantErr: ErrInvalidPDF},
+		{name: "unparseable", data: []byte("%PDF-1.4 garbage"), wantErr: ErrInvalidPDF},
+		{name: "over configured limit", data: createMinimalPDF(), wantErr: ErrPDFTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spooled, err := service.SpoolPDF(bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, spooled)
+		})
+	}
+}
+
+func TestPDFService_SpoolPDF_RemovesFiles(t *testing.T) {
+	dir := t.TempDir()
+	service := NewPDFServiceWithLimits(MaxPDFSize, dir)
+
+	// Rejected uploads leave nothing behind
+	_, err := service.SpoolPDF(strings.NewReader("not a pdf"))
+	require.Error(t, err)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	spooled, err := service.SpoolPDF(bytes.NewReader(createMinimalPDF()))
+	require.NoError(t, err)
+	assert.FileExists(t, spooled.Path())
+
+	path := spooled.Path()
+	require.NoError(t, spooled.Close())
+	assert.NoFileExists(t, path)
+	assert.NoError(t, spooled.Close())
+}
+
+func TestPDFService_InjectQRCodeFromSpool(t *testing.T) {
+	service := NewPDFServiceWithLimits(MaxPDFSize, t.TempDir())
+
+	spooled, err := service.SpoolPDF(bytes.NewReader(createMinimalPDF()))
+	require.NoError(t, err)
+	defer spooled.Close()
+
+	// Expect license error in test environment; nothing may be written on failure
+	var out bytes.Buffer
+	err = service.InjectQRCodeFromSpool(spooled, createTestQRCodeData(), nil, &out)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "license")
+	assert.Zero(t, out.Len())
+}
+
+func TestPDFService_ConfiguredMaxSize(t *testing.T) {
+	service := NewPDFServiceWithLimits(16, "")
+	assert.Equal(t, int64(16), service.MaxSize())
+
+	_, err := service.ReadPDFFromReader(bytes.NewReader(make([]byte, 17)))
+	assert.Error(t, err)
+	assert.Contains(t, service.ValidatePDF(make([]byte, 17)).Error(), "exceeds maximum allowed size")
+
+	assert.Equal(t, int64(MaxPDFSize), NewPDFServiceWithLimits(0, "").MaxSize())
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"digital-signature-system/internal/config"
+)
+
+// Keys holds the values a request can be counted by; empty values are skipped
+type Keys map[KeyType]string
+
+// Decision is the outcome for one route. Rule and Result describe the most
+// restrictive rule, which is what gets reported back to the client.
+type Decision struct {
+	Allowed bool
+	Rule    Rule
+	Result  Result
+}
+
+// Limiter enforces per-route policies against a shared store
+type Limiter struct {
+	store    Store
+	policies Policies
+}
+
+// NewLimiter creates a limiter over store
+func NewLimiter(store Store, policies Policies) *Limiter {
+	return &Limiter{store: store, policies: policies}
+}
+
+// NewFromConfig builds the store and policies selected by configuration
+func NewFromConfig(ctx context.Context, cfg *config.Config) (*Limiter, error) {
+	policies, err := PoliciesWithOverrides(cfg.RateLimitPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	var store Store
+	switch cfg.RateLimitStore {
+	case "", "memory":
+		store = NewMemoryStore(cfg.RateLimitCacheSize)
+	case "redis":
+		store, err = NewRedisStoreFromURL(ctx, cfg.RateLimitRedisURL)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimitStore)
+	}
+
+	return NewLimiter(store, policies), nil
+}
+
+// Uses reports whether route has a rule keyed by key, so callers can skip
+// extracting keys that are expensive to read
+func (l *Limiter) Uses(route string, key KeyType) bool {
+	for _, rule := range l.policies[route] {
+		if rule.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Check counts the request against every rule on route. It returns nil when no
+// rule applied, either because the route has none or the request had no matching keys.
+func (l *Limiter) Check(ctx context.Context, route string, keys Keys) (*Decision, error) {
+	return l.CheckRules(ctx, l.policies[route], keys)
+}
+
+// CheckRules counts the request against rules that are not part of the configured
+// policies, such as the limit stored with an API key
+func (l *Limiter) CheckRules(ctx context.Context, rules []Rule, keys Keys) (*Decision, error) {
+	return l.decide(ctx, rules, keys, l.store.Take)
+}
+
+// Peek reports whether one more request would be allowed on route without counting
+// it, for routes that only count requests once their outcome is known
+func (l *Limiter) Peek(ctx context.Context, route string, keys Keys) (*Decision, error) {
+	return l.decide(ctx, l.policies[route], keys, l.store.Peek)
+}
+
+func (l *Limiter) decide(ctx context.Context, rules []Rule, keys Keys, count func(context.Context, string, int, time.Duration) (Result, error)) (*Decision, error) {
+	var decision *Decision
+	for _, rule := range rules {
+		value := keys[rule.Key]
+		if value == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s:%s", rule.Route, rule.Key, value)
+		result, err := count(ctx, key, rule.Limit, rule.Window)
+		if err != nil {
+			return nil, err
+		}
+
+		if decision == nil || moreRestrictive(result, decision.Result) {
+			decision = &Decision{Rule: rule, Result: result}
+		}
+	}
+
+	if decision != nil {
+		decision.Allowed = decision.Result.Allowed
+	}
+	return decision, nil
+}
+
+// Close releases the store
+func (l *Limiter) Close() error {
+	return l.store.Close()
+}
+
+// moreRestrictive prefers a denial, then the longest wait, then the fewest requests left
+func moreRestrictive(a, b Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.ResetAfter > b.ResetAfter
+	}
+	return a.Remaining < b.Remaining
+}
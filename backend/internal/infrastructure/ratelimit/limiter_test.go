@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("login:ip=20/1m, login:username=5/15m,verify:ip=30/1m")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Rule{
+		{Route: "login", Key: KeyIP, Limit: 20, Window: time.Minute},
+		{Route: "login", Key: KeyUsername, Limit: 5, Window: 15 * time.Minute},
+	}, policies["login"])
+	assert.Equal(t, "30;w=60", policies["verify"][0].Policy())
+
+	for _, spec := range []string{
+		"login=5/1m",
+		"login:cookie=5/1m",
+		"login:ip=5",
+		"login:ip=many/1m",
+		"login:ip=5/100ms",
+	} {
+		_, err := ParsePolicies(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestPoliciesWithOverrides(t *testing.T) {
+	policies, err := PoliciesWithOverrides("login:username=3/1h,verify:ip=0/1m,export:user=5/1m")
+	require.NoError(t, err)
+
+	assert.Len(t, policies[RouteLogin], 2)
+	assert.Contains(t, policies[RouteLogin], Rule{Route: RouteLogin, Key: KeyUsername, Limit: 3, Window: time.Hour})
+	assert.NotContains(t, policies, RouteVerify, "a zero limit disables the rule")
+	assert.Contains(t, policies, "export")
+	assert.Contains(t, policies, RouteGlobal)
+}
+
+func TestLimiter_Check(t *testing.T) {
+	policies, err := ParsePolicies("login:ip=5/1m,login:username=2/15m")
+	require.NoError(t, err)
+	limiter := NewLimiter(NewMemoryStore(100), policies)
+	ctx := context.Background()
+
+	assert.True(t, limiter.Uses(RouteLogin, KeyUsername))
+	assert.False(t, limiter.Uses(RouteLogin, KeyUser))
+
+	keys := Keys{KeyIP: "10.0.0.1", KeyUsername: "alice"}
+	decision, err := limiter.Check(ctx, RouteLogin, keys)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, KeyUsername, decision.Rule.Key, "the rule with fewest requests left is reported")
+	assert.Equal(t, 1, decision.Result.Remaining)
+
+	_, _ = limiter.Check(ctx, RouteLogin, keys)
+	decision, err = limiter.Check(ctx, RouteLogin, keys)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, KeyUsername, decision.Rule.Key)
+
+	// Guessing another account from the same address is only held back by the IP rule
+	decision, err = limiter.Check(ctx, RouteLogin, Keys{KeyIP: "10.0.0.1", KeyUsername: "bob"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Result.Remaining)
+
+	// Requests without any matching key, or on routes without rules, are not limited
+	decision, err = limiter.Check(ctx, RouteLogin, Keys{KeyUser: "user-1"})
+	require.NoError(t, err)
+	assert.Nil(t, decision)
+	decision, err = limiter.Check(ctx, "unknown", keys)
+	require.NoError(t, err)
+	assert.Nil(t, decision)
+}
+
+func TestLimiter_CheckRules(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(100), Policies{})
+	ctx := context.Background()
+	rules := []Rule{{Route: RouteAPIKey, Key: KeyAPIKey, Limit: 1, Window: time.Minute}}
+
+	decision, err := limiter.CheckRules(ctx, rules, Keys{KeyAPIKey: "key-1"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "1;w=60", decision.Rule.Policy())
+
+	decision, err = limiter.CheckRules(ctx, rules, Keys{KeyAPIKey: "key-1"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	// Each key has its own quota
+	decision, err = limiter.CheckRules(ctx, rules, Keys{KeyAPIKey: "key-2"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestLimiter_Peek(t *testing.T) {
+	policies, err := ParsePolicies("access_code:ip=2/15m,access_code:document=3/15m")
+	require.NoError(t, err)
+	limiter := NewLimiter(NewMemoryStore(100), policies)
+	ctx := context.Background()
+	keys := Keys{KeyIP: "10.0.0.1", KeyDocument: "doc-1"}
+
+	// Peeking does not use up the quota
+	for i := 0; i < 3; i++ {
+		decision, err := limiter.Peek(ctx, RouteAccessCode, keys)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+
+	_, _ = limiter.Check(ctx, RouteAccessCode, keys)
+	_, _ = limiter.Check(ctx, RouteAccessCode, keys)
+	decision, err := limiter.Peek(ctx, RouteAccessCode, keys)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, KeyIP, decision.Rule.Key)
+
+	// Another address is still held back once the document has used its quota
+	decision, err = limiter.Peek(ctx, RouteAccessCode, Keys{KeyIP: "10.0.0.2", KeyDocument: "doc-1"})
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	_, _ = limiter.Check(ctx, RouteAccessCode, Keys{KeyIP: "10.0.0.2", KeyDocument: "doc-1"})
+	decision, err = limiter.Peek(ctx, RouteAccessCode, Keys{KeyIP: "10.0.0.3", KeyDocument: "doc-1"})
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, KeyDocument, decision.Rule.Key)
+}
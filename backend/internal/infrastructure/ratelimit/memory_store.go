@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryStoreSize is the number of keys kept when no size is configured
+const DefaultMemoryStoreSize = 10000
+
+// MemoryStore keeps counters in process, evicting the least recently used key once
+// it holds size keys so a flood of distinct clients cannot grow memory without bound.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	count   int
+	resetAt time.Time
+}
+
+// NewMemoryStore creates an in-memory LRU store holding at most size keys
+func NewMemoryStore(size int) *MemoryStore {
+	if size <= 0 {
+		size = DefaultMemoryStoreSize
+	}
+	return &MemoryStore{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Take counts one request for key in the current window
+func (s *MemoryStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var entry *memoryEntry
+	if element, ok := s.entries[key]; ok {
+		s.order.MoveToFront(element)
+		entry = element.Value.(*memoryEntry)
+	} else {
+		entry = &memoryEntry{key: key}
+		s.entries[key] = s.order.PushFront(entry)
+		s.evict()
+	}
+
+	if !now.Before(entry.resetAt) {
+		entry.count = 0
+		entry.resetAt = now.Add(window)
+	}
+	entry.count++
+
+	return newResult(entry.count, limit, entry.resetAt.Sub(now)), nil
+}
+
+// Peek reports whether one more request for key would fit in the current window
+func (s *MemoryStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		if now.Before(entry.resetAt) {
+			return newResult(entry.count+1, limit, entry.resetAt.Sub(now)), nil
+		}
+	}
+	return newResult(1, limit, window), nil
+}
+
+// Len returns the number of keys currently tracked
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Close is a no-op for the in-memory store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) evict() {
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore(10)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		result, err := store.Take(ctx, "login:ip:10.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3-i, result.Remaining)
+	}
+
+	now = now.Add(20 * time.Second)
+	result, err := store.Take(ctx, "login:ip:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 40*time.Second, result.ResetAfter)
+
+	// Other clients have their own counters
+	result, err = store.Take(ctx, "login:ip:10.0.0.2", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// A new window starts the count over
+	now = now.Add(40 * time.Second)
+	result, err = store.Take(ctx, "login:ip:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2)
+	ctx := context.Background()
+
+	_, _ = store.Take(ctx, "a", 1, time.Minute)
+	_, _ = store.Take(ctx, "b", 1, time.Minute)
+	// Touching "a" makes "b" the eviction candidate
+	result, _ := store.Take(ctx, "a", 1, time.Minute)
+	assert.False(t, result.Allowed)
+
+	for i := 0; i < 5; i++ {
+		_, _ = store.Take(ctx, fmt.Sprintf("flood-%d", i), 1, time.Minute)
+	}
+	assert.Equal(t, 2, store.Len())
+
+	result, _ = store.Take(ctx, "b", 1, time.Minute)
+	assert.True(t, result.Allowed, "evicted keys start a fresh window")
+}
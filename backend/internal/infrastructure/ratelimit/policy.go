@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyType identifies what a rule counts requests by
+type KeyType string
+
+const (
+	KeyIP       KeyType = "ip"
+	KeyUser     KeyType = "user"
+	KeyUsername KeyType = "username"
+	KeyAPIKey   KeyType = "apikey"
+	// KeyDocument counts requests by the document they name
+	KeyDocument KeyType = "document"
+)
+
+// Route names that policies are attached to
+const (
+	RouteGlobal   = "global"
+	RouteLogin    = "login"
+	RouteRegister = "register"
+	RouteVerify   = "verify"
+	// RouteVerifyFile looks documents up by file hash, so it is held tighter
+	// than verifying a known document
+	RouteVerifyFile = "verify_file"
+	RouteSign       = "sign"
+	RouteAPI        = "api"
+	// RouteAPIKey counts requests against the limit stored with each API key
+	RouteAPIKey = "apikey"
+	// RouteAccessCode counts wrong access codes given for access_code documents
+	RouteAccessCode = "access_code"
+)
+
+// DefaultPolicies apply unless RATE_LIMIT_POLICIES overrides them
+const DefaultPolicies = "global:ip=100/1s," +
+	"login:ip=20/1m,login:username=10/15m," +
+	"register:ip=10/1h," +
+	"verify:ip=30/1m,verify_file:ip=10/1m," +
+	"access_code:ip=10/15m,access_code:document=20/15m," +
+	"sign:user=60/1m," +
+	"api:user=600/1m,api:apikey=600/1m"
+
+// Rule allows Limit requests per Window for each distinct value of Key on a route
+type Rule struct {
+	Route  string
+	Key    KeyType
+	Limit  int
+	Window time.Duration
+}
+
+// Policy returns the rule in RateLimit-Policy form, e.g. "100;w=60"
+func (r Rule) Policy() string {
+	return fmt.Sprintf("%d;w=%d", r.Limit, int(r.Window.Seconds()))
+}
+
+// Policies maps a route to the rules enforced on it
+type Policies map[string][]Rule
+
+// ParsePolicies reads comma separated "route:key=limit/window" entries, e.g.
+// "login:username=10/15m". A limit of 0 disables that route and key.
+func ParsePolicies(spec string) (Policies, error) {
+	policies := make(Policies)
+	if err := policies.merge(spec); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// PoliciesWithOverrides starts from DefaultPolicies and replaces any route and key
+// pair that appears in overrides
+func PoliciesWithOverrides(overrides string) (Policies, error) {
+	policies, err := ParsePolicies(DefaultPolicies)
+	if err != nil {
+		return nil, err
+	}
+	if err := policies.merge(overrides); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (p Policies) merge(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		rule, err := parseRule(entry)
+		if err != nil {
+			return err
+		}
+		p.set(rule)
+	}
+	return nil
+}
+
+func (p Policies) set(rule Rule) {
+	var rules []Rule
+	for _, existing := range p[rule.Route] {
+		if existing.Key != rule.Key {
+			rules = append(rules, existing)
+		}
+	}
+	if rule.Limit > 0 {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Key < rules[j].Key })
+
+	if len(rules) == 0 {
+		delete(p, rule.Route)
+		return
+	}
+	p[rule.Route] = rules
+}
+
+func parseRule(entry string) (Rule, error) {
+	target, quota, ok := strings.Cut(entry, "=")
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: expected route:key=limit/window", entry)
+	}
+	route, key, ok := strings.Cut(strings.TrimSpace(target), ":")
+	if !ok || route == "" {
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: expected route:key", entry)
+	}
+
+	keyType := KeyType(key)
+	switch keyType {
+	case KeyIP, KeyUser, KeyUsername, KeyAPIKey, KeyDocument:
+	default:
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: unknown key %q", entry, key)
+	}
+
+	limitText, windowText, ok := strings.Cut(strings.TrimSpace(quota), "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: expected limit/window", entry)
+	}
+	limit, err := strconv.Atoi(limitText)
+	if err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: bad limit", entry)
+	}
+	window, err := time.ParseDuration(windowText)
+	if err != nil || window < time.Second {
+		return Rule{}, fmt.Errorf("invalid rate limit policy %q: window must be at least 1s", entry)
+	}
+
+	return Rule{Route: route, Key: keyType, Limit: limit, Window: window}, nil
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+// takeScript increments the window counter and starts its expiry on the first hit, so
+// the count and TTL are updated atomically even with many API instances
+var takeScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if count == 1 or ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// peekScript reads the window counter and its remaining TTL together
+var peekScript = redis.NewScript(`
+local count = tonumber(redis.call("GET", KEYS[1]) or "0")
+local ttl = redis.call("PTTL", KEYS[1])
+return {count, ttl}
+`)
+
+// RedisStore keeps counters in Redis (or any server speaking its protocol)
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore creates a store on an existing client
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// NewRedisStoreFromURL connects to the server at a redis:// or rediss:// URL
+func NewRedisStoreFromURL(ctx context.Context, url string) (*RedisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return NewRedisStore(client), nil
+}
+
+// Take counts one request for key in the current window
+func (s *RedisStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{redisKeyPrefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to update rate limit counter: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", values)
+	}
+
+	return newResult(int(values[0]), limit, time.Duration(values[1])*time.Millisecond), nil
+}
+
+// Peek reports whether one more request for key would fit in the current window
+func (s *RedisStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	values, err := peekScript.Run(ctx, s.client, []string{redisKeyPrefix + key}).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read rate limit counter: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", values)
+	}
+
+	// A missing counter, or one without an expiry, starts a fresh window
+	if values[1] < 0 {
+		return newResult(1, limit, window), nil
+	}
+	return newResult(int(values[0])+1, limit, time.Duration(values[1])*time.Millisecond), nil
+}
+
+// Close closes the underlying client
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
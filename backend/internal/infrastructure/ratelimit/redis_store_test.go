@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	store := NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+func TestRedisStore_Take(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		result, err := store.Take(ctx, "verify:ip:10.0.0.1", 2, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+		assert.Equal(t, time.Minute, result.ResetAfter)
+	}
+
+	result, err := store.Take(ctx, "verify:ip:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.True(t, server.Exists(redisKeyPrefix+"verify:ip:10.0.0.1"))
+
+	// The counter expires with its window
+	server.FastForward(time.Minute)
+	result, err = store.Take(ctx, "verify:ip:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestRedisStore_SharedBetweenInstances(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	other := NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	defer other.Close()
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "login:username:alice", 1, time.Minute)
+	require.NoError(t, err)
+
+	result, err := other.Take(ctx, "login:username:alice", 1, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestRedisStore_Unavailable(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	server.Close()
+
+	_, err := store.Take(context.Background(), "global:ip:10.0.0.1", 10, time.Second)
+	assert.Error(t, err)
+}
+
+func TestRedisStore_Peek(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	result, err := store.Peek(ctx, "access_code:document:doc-1", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.ResetAfter)
+
+	_, err = store.Take(ctx, "access_code:document:doc-1", 1, time.Minute)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		result, err = store.Peek(ctx, "access_code:document:doc-1", 1, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	}
+}
@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Result is the outcome of counting one request against a rule
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the current window ends and the count starts over
+	ResetAfter time.Duration
+}
+
+// Store counts requests per key in fixed windows. Implementations must be safe for
+// concurrent use; the Redis store lets several API instances share one set of counters.
+type Store interface {
+	// Take counts one request for key and reports whether it fits in limit for the window
+	Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Peek reports whether one more request for key would fit in limit, without counting it
+	Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	Close() error
+}
+
+func newResult(count, limit int, resetAfter time.Duration) Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:    count <= limit,
+		Limit:      limit,
+		Remaining:  remaining,
+		ResetAfter: resetAfter,
+	}
+}
@@ -10,7 +10,7 @@ import React, { useState, useEffect } from 'react';
 import { useRouter } from 'next/navigation';
 import { Button } from '@/components/ui/Button';
 import { Input } from '@/components/ui/Input';
-import { useAuthOperations, useAuthValidation } from '@/hooks';
+import { useAuthOperations, useAuthValidation, useOIDCProviders } from '@/hooks';
 
 export default function LoginPage() {
   const router = useRouter();
@@ -18,18 +18,25 @@ export default function LoginPage() {
   const [password, setPassword] = useState('');
   const [usernameError, setUsernameError] = useState('');
   const [passwordError, setPasswordError] = useState('');
+  const [mfaCode, setMfaCode] = useState('');
 
   // Use custom hooks for authentication logic
   const {
     isAuthenticated,
     isLoggingIn,
+    isVerifyingMFA,
     loginError,
+    mfaError,
     loginSuccess,
+    mfaToken,
     login,
+    verifyMFA,
     resetLogin,
+    resetMFA,
   } = useAuthOperations();
 
   const { validateUsername, validatePassword } = useAuthValidation();
+  const { providers, beginLogin, isRedirecting, beginError } = useOIDCProviders();
 
   // Redirect if already authenticated
   useEffect(() => {
@@ -80,6 +87,17 @@ export default function LoginPage() {
     login({ username: username.trim(), password });
   };
 
+  // Handle the second step of a two-factor login
+  const handleMFASubmit = (event: React.FormEvent) => {
+    event.preventDefault();
+
+    if (!mfaToken || !mfaCode.trim()) {
+      return;
+    }
+
+    verifyMFA({ mfaToken, code: mfaCode.trim() });
+  };
+
   // Handle input changes with error clearing
   const handleUsernameChange = (event: React.ChangeEvent<HTMLInputElement>) => {
     const value = event.target.value;
@@ -99,9 +117,15 @@ export default function LoginPage() {
 
   // Handle error dismissal
   const handleErrorDismiss = () => {
+    if (mfaError) {
+      resetMFA();
+      return;
+    }
     resetLogin();
   };
 
+  const authError = mfaError || loginError || beginError;
+
   return (
     <div className="min-h-screen flex items-center justify-center bg-gray-50 py-12 px-4 sm:px-6 lg:px-8">
       <div className="max-w-md w-full space-y-8">
@@ -115,7 +139,7 @@ export default function LoginPage() {
         </div>
 
         {/* Login Error */}
-        {loginError && (
+        {authError && (
           <div className="bg-red-50 border border-red-200 rounded-md p-4">
             <div className="flex">
               <div className="flex-shrink-0">
@@ -128,7 +152,7 @@ export default function LoginPage() {
                   Login failed
                 </h3>
                 <div className="mt-2 text-sm text-red-700">
-                  <p>{loginError instanceof Error ? loginError.message : 'Invalid username or password'}</p>
+                  <p>{authError instanceof Error ? authError.message : 'Invalid username or password'}</p>
                 </div>
                 <div className="mt-4">
                   <button
@@ -144,6 +168,49 @@ export default function LoginPage() {
           </div>
         )}
 
+        {mfaToken ? (
+          <form className="mt-8 space-y-6" onSubmit={handleMFASubmit}>
+            <p className="text-sm text-gray-600">
+              Enter the 6-digit code from your authenticator app, or one of your recovery codes.
+            </p>
+
+            <Input
+              label="Verification code"
+              type="text"
+              value={mfaCode}
+              onChange={(event) => setMfaCode(event.target.value)}
+              placeholder="123456"
+              disabled={isVerifyingMFA}
+              required
+              autoComplete="one-time-code"
+              inputMode="numeric"
+            />
+
+            <div className="space-y-3">
+              <Button
+                type="submit"
+                variant="primary"
+                size="lg"
+                isLoading={isVerifyingMFA}
+                disabled={!mfaCode.trim() || isVerifyingMFA}
+                className="w-full"
+              >
+                {isVerifyingMFA ? 'Verifying...' : 'Verify'}
+              </Button>
+              <button
+                type="button"
+                onClick={() => {
+                  setMfaCode('');
+                  resetLogin();
+                }}
+                className="w-full text-sm text-gray-500 hover:text-gray-700"
+                disabled={isVerifyingMFA}
+              >
+                Use a different account
+              </button>
+            </div>
+          </form>
+        ) : (
         <form className="mt-8 space-y-6" onSubmit={handleSubmit}>
           <div className="space-y-4">
             <Input
@@ -184,6 +251,32 @@ export default function LoginPage() {
             </Button>
           </div>
 
+          {providers.length > 0 && (
+            <div className="space-y-3">
+              <div className="relative">
+                <div className="absolute inset-0 flex items-center">
+                  <div className="w-full border-t border-gray-300" />
+                </div>
+                <div className="relative flex justify-center text-sm">
+                  <span className="px-2 bg-gray-50 text-gray-500">or</span>
+                </div>
+              </div>
+              {providers.map((provider) => (
+                <Button
+                  key={provider.name}
+                  type="button"
+                  variant="secondary"
+                  size="lg"
+                  onClick={() => beginLogin(provider.name)}
+                  disabled={isLoggingIn || isRedirecting}
+                  className="w-full"
+                >
+                  Sign in with {provider.display_name}
+                </Button>
+              ))}
+            </div>
+          )}
+
           <div className="text-center">
             <p className="text-sm text-gray-600">
               Don&apos;t have an account?{' '}
@@ -198,6 +291,7 @@ export default function LoginPage() {
             </p>
           </div>
         </form>
+        )}
 
         <div className="mt-6">
           <div className="text-center">
@@ -0,0 +1,142 @@
+/**
+ * Single Sign-On Callback Page
+ * Completes a login at an OpenID Connect provider with the code it redirected back with
+ */
+
+'use client';
+
+import React, { Suspense, useEffect, useRef, useState } from 'react';
+import { useRouter, useSearchParams } from 'next/navigation';
+import { Button } from '@/components/ui/Button';
+import { Input } from '@/components/ui/Input';
+import { useAuthOperations } from '@/hooks';
+
+function OIDCCallback() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const [mfaCode, setMfaCode] = useState('');
+  const started = useRef(false);
+
+  const {
+    isCompletingOIDC,
+    isVerifyingMFA,
+    oidcError,
+    mfaError,
+    loginSuccess,
+    mfaToken,
+    completeOIDCLogin,
+    verifyMFA,
+  } = useAuthOperations();
+
+  const state = searchParams.get('state');
+  const code = searchParams.get('code');
+  // Set by the provider when the user cancelled or was refused
+  const providerError = searchParams.get('error_description') || searchParams.get('error');
+
+  // The code can only be used once, so exchange it exactly once
+  useEffect(() => {
+    if (started.current || providerError || !state || !code) {
+      return;
+    }
+    started.current = true;
+    completeOIDCLogin({ state, code });
+  }, [state, code, providerError, completeOIDCLogin]);
+
+  useEffect(() => {
+    if (loginSuccess) {
+      router.push('/documents');
+    }
+  }, [loginSuccess, router]);
+
+  const handleMFASubmit = (event: React.FormEvent) => {
+    event.preventDefault();
+
+    if (!mfaToken || !mfaCode.trim()) {
+      return;
+    }
+
+    verifyMFA({ mfaToken, code: mfaCode.trim() });
+  };
+
+  let error: string | null = null;
+  if (providerError) {
+    error = providerError;
+  } else if (!state || !code) {
+    error = 'The sign-in response is incomplete.';
+  } else if (oidcError) {
+    error = oidcError instanceof Error ? oidcError.message : 'Single sign-on failed';
+  }
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-50 py-12 px-4 sm:px-6 lg:px-8">
+      <div className="max-w-md w-full space-y-8">
+        <h2 className="mt-6 text-center text-3xl font-extrabold text-gray-900">
+          Single sign-on
+        </h2>
+
+        {error ? (
+          <div className="bg-red-50 border border-red-200 rounded-md p-4">
+            <h3 className="text-sm font-medium text-red-800">Sign-in failed</h3>
+            <p className="mt-2 text-sm text-red-700">{error}</p>
+            <div className="mt-4">
+              <button
+                type="button"
+                onClick={() => router.push('/login')}
+                className="text-sm font-medium text-red-800 hover:text-red-600"
+              >
+                Back to sign in
+              </button>
+            </div>
+          </div>
+        ) : mfaToken ? (
+          <form className="mt-8 space-y-6" onSubmit={handleMFASubmit}>
+            {mfaError && (
+              <p className="text-sm text-red-700">
+                {mfaError instanceof Error ? mfaError.message : 'Invalid verification code'}
+              </p>
+            )}
+            <p className="text-sm text-gray-600">
+              Enter the 6-digit code from your authenticator app, or one of your recovery codes.
+            </p>
+
+            <Input
+              label="Verification code"
+              type="text"
+              value={mfaCode}
+              onChange={(event) => setMfaCode(event.target.value)}
+              placeholder="123456"
+              disabled={isVerifyingMFA}
+              required
+              autoComplete="one-time-code"
+              inputMode="numeric"
+            />
+
+            <Button
+              type="submit"
+              variant="primary"
+              size="lg"
+              isLoading={isVerifyingMFA}
+              disabled={!mfaCode.trim() || isVerifyingMFA}
+              className="w-full"
+            >
+              {isVerifyingMFA ? 'Verifying...' : 'Verify'}
+            </Button>
+          </form>
+        ) : (
+          <p className="text-center text-sm text-gray-600">
+            {isCompletingOIDC ? 'Signing you in...' : 'Redirecting...'}
+          </p>
+        )}
+      </div>
+    </div>
+  );
+}
+
+export default function OIDCCallbackPage() {
+  // useSearchParams needs a suspense boundary for the page to be prerendered
+  return (
+    <Suspense fallback={null}>
+      <OIDCCallback />
+    </Suspense>
+  );
+}
@@ -0,0 +1,112 @@
+/**
+ * DocumentDetails Component
+ * Shows the metadata and text extracted from a signed PDF
+ */
+
+'use client';
+
+import React, { useState } from 'react';
+import { useDocument } from '@/hooks';
+import type { Document } from '@/lib/types';
+
+interface DocumentDetailsProps {
+  document: Document;
+  formatDate?: (dateString: string) => string;
+  formatFileSize?: (bytes: number) => string;
+}
+
+const POINTS_PER_MM = 72 / 25.4;
+
+function formatPageSize(width: number, height: number): string {
+  return `${Math.round(width / POINTS_PER_MM)} × ${Math.round(height / POINTS_PER_MM)} mm`;
+}
+
+export function DocumentDetails({
+  document,
+  formatDate = (date) => new Date(date).toLocaleDateString(),
+  formatFileSize = (bytes) => `${Math.round(bytes / 1024)} KB`,
+}: DocumentDetailsProps) {
+  const [isOpen, setIsOpen] = useState(false);
+  // The extracted text is only loaded once the details are opened
+  const { document: detail, isLoading } = useDocument(isOpen ? document.id : '');
+  const metadata = document.metadata;
+
+  if (!metadata) {
+    return null;
+  }
+
+  const fields: Array<[string, string | undefined]> = [
+    ['PDF version', metadata.pdf_version],
+    ['Pages', String(metadata.page_count)],
+    ['Title', metadata.title],
+    ['Author', metadata.author],
+    ['Subject', metadata.subject],
+    ['Keywords', metadata.keywords],
+    ['Creator', metadata.creator],
+    ['Producer', metadata.producer],
+    ['Created', metadata.creation_date && formatDate(metadata.creation_date)],
+    ['Modified', metadata.modified_date && formatDate(metadata.modified_date)],
+  ];
+  const pageText = detail?.page_text ?? [];
+
+  return (
+    <details
+      className="mt-2"
+      onToggle={(event) => setIsOpen((event.target as HTMLDetailsElement).open)}
+    >
+      <summary className="cursor-pointer font-medium text-gray-600">PDF details</summary>
+
+      <div className="mt-2 grid grid-cols-1 md:grid-cols-2 gap-2">
+        {fields
+          .filter(([, value]) => value)
+          .map(([label, value]) => (
+            <div key={label}>
+              <span className="font-medium">{label}:</span> {value}
+            </div>
+          ))}
+        {metadata.pages && metadata.pages.length > 0 && (
+          <div>
+            <span className="font-medium">Page size:</span>{' '}
+            {formatPageSize(metadata.pages[0].width, metadata.pages[0].height)}
+          </div>
+        )}
+      </div>
+
+      {metadata.encrypted && (
+        <p className="mt-2 text-yellow-700">This PDF is encrypted.</p>
+      )}
+
+      {metadata.embedded_files && metadata.embedded_files.length > 0 && (
+        <div className="mt-2">
+          <span className="font-medium">Embedded files:</span>
+          <ul className="list-disc ml-5">
+            {metadata.embedded_files.map((file) => (
+              <li key={file.name}>
+                {file.name} ({formatFileSize(file.size)})
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+
+      <div className="mt-2">
+        <span className="font-medium">Extracted text</span>
+        {isLoading ? (
+          <p className="mt-1">Loading…</p>
+        ) : pageText.length === 0 ? (
+          <p className="mt-1">No text could be extracted from this PDF.</p>
+        ) : (
+          pageText.map((text, index) => (
+            <div key={index} className="mt-1">
+              <p className="text-gray-400">Page {index + 1}</p>
+              <pre className="whitespace-pre-wrap font-sans text-gray-700 max-h-48 overflow-y-auto">{text}</pre>
+            </div>
+          ))
+        )}
+        {metadata.text_truncated && (
+          <p className="mt-1 text-gray-400">Only the beginning of the text was kept.</p>
+        )}
+      </div>
+    </details>
+  );
+}
@@ -5,6 +5,7 @@
 
 import React from 'react';
 import { Button } from './ui/Button';
+import { DocumentDetails } from './DocumentDetails';
 import type { Document } from '@/lib/types';
 
 interface DocumentListProps {
@@ -221,6 +222,11 @@ export function DocumentList({
                       <span className="font-medium">Hash:</span> {document.document_hash.substring(0, 16)}...
                     </div>
                   </div>
+                  <DocumentDetails
+                    document={document}
+                    formatDate={formatDate}
+                    formatFileSize={formatFileSize}
+                  />
                 </div>
               </div>
           </div>
@@ -78,28 +78,44 @@ export function VerificationForm({
                 <dd className="mt-1 text-sm text-gray-900 font-medium">{documentInfo.title}</dd>
               </div>
             )}
-            <div>
-              <dt className="text-sm font-medium text-gray-500">Filename</dt>
-              <dd className="mt-1 text-sm text-gray-900">{documentInfo.filename}</dd>
-            </div>
-            <div>
-              <dt className="text-sm font-medium text-gray-500">Issuer</dt>
-              <dd className="mt-1 text-sm text-gray-900">{documentInfo.issuer}</dd>
-            </div>
+            {documentInfo.filename && (
+              <div>
+                <dt className="text-sm font-medium text-gray-500">Filename</dt>
+                <dd className="mt-1 text-sm text-gray-900">{documentInfo.filename}</dd>
+              </div>
+            )}
+            {documentInfo.issuer && (
+              <div>
+                <dt className="text-sm font-medium text-gray-500">Issuer</dt>
+                <dd className="mt-1 text-sm text-gray-900">{documentInfo.issuer}</dd>
+              </div>
+            )}
+            {documentInfo.signed_by && documentInfo.on_behalf_of && (
+              <div className="sm:col-span-2">
+                <dt className="text-sm font-medium text-gray-500">Signatory</dt>
+                <dd className="mt-1 text-sm text-gray-900">
+                  Signed by {documentInfo.signed_by} on behalf of {documentInfo.on_behalf_of}
+                </dd>
+              </div>
+            )}
             <div>
               <dt className="text-sm font-medium text-gray-500">Letter Number</dt>
               <dd className="mt-1 text-sm text-gray-900">{documentInfo.letter_number && documentInfo.letter_number.trim() ? documentInfo.letter_number : 'Not provided'}</dd>
             </div>
-            <div>
-              <dt className="text-sm font-medium text-gray-500">Signed Date</dt>
-              <dd className="mt-1 text-sm text-gray-900">{formatDate(documentInfo.created_at)}</dd>
-            </div>
-            <div>
-              <dt className="text-sm font-medium text-gray-500">Document Hash</dt>
-              <dd className="mt-1 text-sm text-gray-900 font-mono text-xs">
-                {documentInfo.document_hash.substring(0, 32)}...
-              </dd>
-            </div>
+            {documentInfo.created_at && (
+              <div>
+                <dt className="text-sm font-medium text-gray-500">Signed Date</dt>
+                <dd className="mt-1 text-sm text-gray-900">{formatDate(documentInfo.created_at)}</dd>
+              </div>
+            )}
+            {documentInfo.document_hash && (
+              <div>
+                <dt className="text-sm font-medium text-gray-500">Document Hash</dt>
+                <dd className="mt-1 text-sm text-gray-900 font-mono text-xs">
+                  {documentInfo.document_hash.substring(0, 32)}...
+                </dd>
+              </div>
+            )}
           </dl>
         </div>
       </div>
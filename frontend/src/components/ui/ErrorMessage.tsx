@@ -124,6 +124,16 @@ function getApiErrorTitle(code: string): string {
       return 'Invalid Input';
     case 'RATE_LIMIT_EXCEEDED':
       return 'Too Many Requests';
+    case 'ACCOUNT_LOCKED':
+      return 'Account Locked';
+    case 'MFA_REQUIRED':
+      return 'Two-Factor Authentication Required';
+    case 'INVALID_MFA_CODE':
+      return 'Invalid Code';
+    case 'WEBAUTHN_REQUIRED':
+      return 'Security Key Required';
+    case 'WEBAUTHN_FAILED':
+      return 'Security Key Not Accepted';
     case 'FILE_TOO_LARGE':
       return 'File Too Large';
     case 'INVALID_FILE':
@@ -151,6 +161,16 @@ function getApiErrorMessage(error: ApiClientError): string {
       return error.message || 'Please check your input and try again.';
     case 'RATE_LIMIT_EXCEEDED':
       return 'You\'re making requests too quickly. Please wait a moment and try again.';
+    case 'ACCOUNT_LOCKED':
+      return 'This account is temporarily locked after too many failed sign-in attempts. Please try again later or contact an administrator.';
+    case 'MFA_REQUIRED':
+      return 'Signing documents requires two-factor authentication. Enable it in your account settings and sign in again.';
+    case 'INVALID_MFA_CODE':
+      return 'The verification code is incorrect or has already been used.';
+    case 'WEBAUTHN_REQUIRED':
+      return 'Confirm this request with your security key before signing.';
+    case 'WEBAUTHN_FAILED':
+      return 'The security key confirmation was not accepted or was made for a different file. Please confirm again.';
     case 'FILE_TOO_LARGE':
       return 'The file you\'re trying to upload is too large. Please choose a smaller file.';
     case 'INVALID_FILE':
@@ -173,6 +193,11 @@ function getApiErrorSeverity(code: string): 'error' | 'warning' | 'info' {
     case 'UNAUTHORIZED':
     case 'FORBIDDEN':
     case 'NOT_FOUND':
+    case 'ACCOUNT_LOCKED':
+    case 'MFA_REQUIRED':
+    case 'INVALID_MFA_CODE':
+    case 'WEBAUTHN_REQUIRED':
+    case 'WEBAUTHN_FAILED':
       return 'warning';
     case 'VALIDATION_FAILED':
     case 'RATE_LIMIT_EXCEEDED':
@@ -17,6 +17,7 @@ export {
   useAuthOperations,
   useAuthValidation,
   useRequireAuth,
+  useOIDCProviders,
   authKeys,
 } from './useAuthOperations';
 
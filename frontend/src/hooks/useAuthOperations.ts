@@ -17,6 +17,7 @@ export const authKeys = {
   all: ['auth'] as const,
   user: () => [...authKeys.all, 'user'] as const,
   session: () => [...authKeys.all, 'session'] as const,
+  oidcProviders: () => [...authKeys.all, 'oidc-providers'] as const,
 };
 
 /**
@@ -70,25 +71,49 @@ export function useAuthOperations() {
     refetchOnReconnect: false,   // Prevent aggressive refetching
   });
 
+  // Store a new session in the query cache
+  const handleLoginSuccess = (data: LoginResponse) => {
+    // A two-factor challenge is not a session yet
+    if (data.mfa_required) {
+      return;
+    }
+
+    // Update session cache
+    queryClient.setQueryData(authKeys.session(), {
+      user: data.user,
+      token: data.token,
+      isAuthenticated: true,
+      isLoading: false,
+    });
+
+    // Update user cache
+    queryClient.setQueryData(authKeys.user(), data.user);
+
+    // Invalidate all queries to refetch with new auth
+    queryClient.invalidateQueries();
+  };
+
   // Mutation for login
   const loginMutation = useMutation({
     mutationFn: ({ username, password }: { username: string; password: string }) =>
       authService.login(username, password),
-    onSuccess: (data: LoginResponse) => {
-      // Update session cache
-      queryClient.setQueryData(authKeys.session(), {
-        user: data.user,
-        token: data.token,
-        isAuthenticated: true,
-        isLoading: false,
-      });
-      
-      // Update user cache
-      queryClient.setQueryData(authKeys.user(), data.user);
-      
-      // Invalidate all queries to refetch with new auth
-      queryClient.invalidateQueries();
-    },
+    onSuccess: handleLoginSuccess,
+    onError: handleError,
+  });
+
+  // Mutation for the second step of a two-factor login
+  const mfaLoginMutation = useMutation({
+    mutationFn: ({ mfaToken, code }: { mfaToken: string; code: string }) =>
+      authService.completeMFALogin(mfaToken, code),
+    onSuccess: handleLoginSuccess,
+    onError: handleError,
+  });
+
+  // Mutation for the callback of a single sign-on login
+  const oidcLoginMutation = useMutation({
+    mutationFn: ({ state, code }: { state: string; code: string }) =>
+      authService.completeOIDCLogin(state, code),
+    onSuccess: handleLoginSuccess,
     onError: handleError,
   });
 
@@ -159,28 +184,45 @@ export function useAuthOperations() {
     // Loading states
     isLoading: sessionQuery.isLoading || validateSessionQuery.isLoading,
     isLoggingIn: loginMutation.isPending,
+    isVerifyingMFA: mfaLoginMutation.isPending,
+    isCompletingOIDC: oidcLoginMutation.isPending,
     isRegistering: registerMutation.isPending,
     isLoggingOut: logoutMutation.isPending,
     
     // Error states
     sessionError: sessionQuery.error || validateSessionQuery.error,
     loginError: loginMutation.error,
+    mfaError: mfaLoginMutation.error,
+    oidcError: oidcLoginMutation.error,
     registerError: registerMutation.error,
     logoutError: logoutMutation.error,
     lastError,
     
     // Actions
     login: loginMutation.mutate,
+    verifyMFA: mfaLoginMutation.mutate,
+    completeOIDCLogin: oidcLoginMutation.mutate,
     register: registerMutation.mutate,
     logout: logoutMutation.mutate,
     
     // Success states
-    loginSuccess: loginMutation.isSuccess,
+    loginSuccess: (loginMutation.isSuccess && !loginMutation.data?.mfa_required) ||
+      (oidcLoginMutation.isSuccess && !oidcLoginMutation.data?.mfa_required) ||
+      mfaLoginMutation.isSuccess,
+    // Token for the second login step while a two-factor challenge is pending
+    mfaToken: (loginMutation.data?.mfa_required ? loginMutation.data.mfa_token : undefined) ??
+      (oidcLoginMutation.data?.mfa_required ? oidcLoginMutation.data.mfa_token : undefined) ??
+      null,
     registerSuccess: registerMutation.isSuccess,
     logoutSuccess: logoutMutation.isSuccess,
     
     // Reset mutations
-    resetLogin: loginMutation.reset,
+    resetLogin: () => {
+      loginMutation.reset();
+      mfaLoginMutation.reset();
+      oidcLoginMutation.reset();
+    },
+    resetMFA: mfaLoginMutation.reset,
     resetRegister: registerMutation.reset,
     resetLogout: logoutMutation.reset,
     
@@ -191,6 +233,29 @@ export function useAuthOperations() {
   };
 }
 
+/**
+ * Hook for the single sign-on providers shown on the login page
+ */
+export function useOIDCProviders() {
+  const providersQuery = useQuery({
+    queryKey: authKeys.oidcProviders(),
+    queryFn: () => authService.getOIDCProviders(),
+    staleTime: 30 * 60 * 1000, // 30 minutes
+  });
+
+  const beginMutation = useMutation({
+    mutationFn: (provider: string) => authService.beginOIDCLogin(provider),
+  });
+
+  return {
+    providers: providersQuery.data ?? [],
+    isLoading: providersQuery.isLoading,
+    beginLogin: beginMutation.mutate,
+    isRedirecting: beginMutation.isPending || beginMutation.isSuccess,
+    beginError: beginMutation.error,
+  };
+}
+
 /**
  * Hook for authentication validation utilities
  */
@@ -177,6 +177,52 @@ describe('ApiClient', () => {
     });
   });
 
+  describe('token refresh', () => {
+    const unauthorized = () =>
+      ({
+        ok: false,
+        status: 401,
+        statusText: 'Unauthorized',
+        headers: new Headers({ 'content-type': 'application/json' }),
+        json: async () => ({ code: 'UNAUTHORIZED', message: 'Token has expired' }),
+      }) as Response;
+
+    it('should retry once with a refreshed token', async () => {
+      apiClient.setToken('expired-token');
+      const refresh = jest.fn(async () => {
+        apiClient.setToken('fresh-token');
+        return 'fresh-token';
+      });
+      apiClient.setRefreshHandler(refresh);
+
+      mockFetch.mockResolvedValueOnce(unauthorized()).mockResolvedValueOnce({
+        ok: true,
+        status: 200,
+        headers: new Headers({ 'content-type': 'application/json' }),
+        json: async () => ({ id: 1 }),
+      } as Response);
+
+      const result = await apiClient.get('/test');
+
+      expect(result).toEqual({ id: 1 });
+      expect(refresh).toHaveBeenCalledTimes(1);
+      expect(mockFetch).toHaveBeenLastCalledWith('http://localhost:8000/api/test', {
+        method: 'GET',
+        headers: { Authorization: 'Bearer fresh-token' },
+        body: undefined,
+      });
+    });
+
+    it('should give up when the session cannot be refreshed', async () => {
+      apiClient.setToken('expired-token');
+      apiClient.setRefreshHandler(async () => null);
+      mockFetch.mockResolvedValueOnce(unauthorized());
+
+      await expect(apiClient.get('/test')).rejects.toThrow(ApiClientError);
+      expect(apiClient.getToken()).toBeNull();
+    });
+  });
+
   describe('empty responses', () => {
     it('should handle 204 No Content responses', async () => {
       mockFetch.mockResolvedValueOnce({
@@ -13,6 +13,9 @@ export interface ApiError {
   code: string;
   message: string;
   details?: string;
+  // Set on failed logins once the account or address has failed repeatedly
+  captcha_required?: boolean;
+  retry_at?: string;
 }
 
 export class ApiClientError extends Error {
@@ -20,16 +23,29 @@ export class ApiClientError extends Error {
     public status: number,
     public code: string,
     message: string,
-    public details?: string
+    public details?: string,
+    public captchaRequired: boolean = false,
+    public retryAt?: string
   ) {
     super(message);
     this.name = 'ApiClientError';
   }
 }
 
+/**
+ * Renews the access token after it expires; resolves to the new token, or null when
+ * the session is over
+ */
+export type RefreshHandler = () => Promise<string | null>;
+
+// Requests that must not trigger a token refresh when they fail with 401
+const NO_REFRESH_ENDPOINTS = ['/auth/login', '/auth/login/mfa', '/auth/refresh', '/auth/logout'];
+
 export class ApiClient {
   private baseURL: string;
   private token: string | null = null;
+  private refreshHandler: RefreshHandler | null = null;
+  private refreshing: Promise<string | null> | null = null;
 
   constructor(baseURL: string = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8000') {
     this.baseURL = baseURL.replace(/\/$/, ''); // Remove trailing slash
@@ -42,6 +58,13 @@ export class ApiClient {
     this.token = token;
   }
 
+  /**
+   * Set the handler used to renew an expired access token
+   */
+  setRefreshHandler(handler: RefreshHandler | null): void {
+    this.refreshHandler = handler;
+  }
+
   /**
    * Get current authentication token
    */
@@ -52,8 +75,8 @@ export class ApiClient {
   /**
    * Perform GET request
    */
-  async get<T>(endpoint: string): Promise<T> {
-    return this.request<T>('GET', endpoint);
+  async get<T>(endpoint: string, extraHeaders?: Record<string, string>): Promise<T> {
+    return this.request<T>('GET', endpoint, undefined, false, extraHeaders);
   }
 
   /**
@@ -87,6 +110,7 @@ export class ApiClient {
     // Clear from localStorage
     if (typeof window !== 'undefined') {
       localStorage.removeItem('auth_token');
+      localStorage.removeItem('auth_refresh_token');
       localStorage.removeItem('auth_user');
       
       // Clear cookie
@@ -101,12 +125,36 @@ export class ApiClient {
   }
 
   /**
-   * Core request method with error handling
+   * Renew the access token once for all requests that failed at the same time
    */
-  private async request<T>(method: string, endpoint: string, data?: any): Promise<T> {
+  private async refreshToken(): Promise<string | null> {
+    if (!this.refreshHandler) {
+      return null;
+    }
+    if (!this.refreshing) {
+      this.refreshing = this.refreshHandler()
+        .catch(() => null)
+        .finally(() => {
+          this.refreshing = null;
+        });
+    }
+    return this.refreshing;
+  }
+
+  /**
+   * Core request method with error handling. A request rejected because the access
+   * token expired is retried once with a refreshed token.
+   */
+  private async request<T>(
+    method: string,
+    endpoint: string,
+    data?: any,
+    retried: boolean = false,
+    extraHeaders?: Record<string, string>
+  ): Promise<T> {
     const url = `${this.baseURL}/api${endpoint}`;
     
-    const headers: Record<string, string> = {};
+    const headers: Record<string, string> = { ...extraHeaders };
 
     // Set authorization header if token exists
     if (this.token) {
@@ -154,8 +202,14 @@ export class ApiClient {
           };
         }
 
-        // Handle 401 errors by clearing token and redirecting to login
+        // Handle 401 errors by refreshing the token, or clearing it and redirecting to login
         if (response.status === 401) {
+          if (!retried && this.token && !NO_REFRESH_ENDPOINTS.includes(endpoint)) {
+            const token = await this.refreshToken();
+            if (token) {
+              return this.request<T>(method, endpoint, data, true, extraHeaders);
+            }
+          }
           this.handleAuthenticationError();
         }
 
@@ -163,7 +217,9 @@ export class ApiClient {
           response.status,
           errorData.code,
           errorData.message,
-          errorData.details
+          errorData.details,
+          errorData.captcha_required ?? false,
+          errorData.retry_at
         );
       }
 
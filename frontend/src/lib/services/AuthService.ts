@@ -8,18 +8,28 @@ import type {
   User,
   LoginRequest,
   LoginResponse,
+  MFALoginRequest,
+  OIDCProvider,
+  OIDCAuthorization,
+  OIDCCallbackRequest,
   RegisterRequest,
   RegisterResponse,
+  RefreshRequest,
+  SessionInfo,
   AuthState,
 } from '@/lib/types';
 
 export class AuthService {
   private static readonly TOKEN_KEY = 'auth_token';
+  private static readonly REFRESH_TOKEN_KEY = 'auth_refresh_token';
   private static readonly USER_KEY = 'auth_user';
+  private static readonly OIDC_STATE_KEY = 'oidc_state';
 
   constructor(private apiClient: ApiClient) {
     // Initialize token from localStorage on service creation
     this.initializeFromStorage();
+    // Expired access tokens are renewed with the refresh token
+    this.apiClient.setRefreshHandler(() => this.refreshSession());
   }
 
   /**
@@ -41,8 +51,79 @@ export class AuthService {
 
     const response = await this.apiClient.post<LoginResponse>('/auth/login', loginData);
 
+    // Users with two-factor authentication get a challenge instead of a session
+    if (response.mfa_required) {
+      return response;
+    }
+
     // Store authentication data
-    this.storeAuthData(response.token, response.user);
+    this.storeAuthData(response.token, response.user, response.refresh_token);
+
+    return response;
+  }
+
+  /**
+   * Complete a two-factor login with a TOTP or recovery code
+   */
+  async completeMFALogin(mfaToken: string, code: string): Promise<LoginResponse> {
+    if (!code.trim()) {
+      throw new Error('Verification code is required');
+    }
+
+    const mfaData: MFALoginRequest = {
+      mfa_token: mfaToken,
+      code: code.trim(),
+    };
+
+    const response = await this.apiClient.post<LoginResponse>('/auth/login/mfa', mfaData);
+
+    // Store authentication data
+    this.storeAuthData(response.token, response.user, response.refresh_token);
+
+    return response;
+  }
+
+  /**
+   * List the single sign-on providers users can sign in with
+   */
+  async getOIDCProviders(): Promise<OIDCProvider[]> {
+    const response = await this.apiClient.get<{ providers: OIDCProvider[] }>('/auth/oidc/providers');
+    return response.providers;
+  }
+
+  /**
+   * Start a single sign-on login by redirecting to the provider
+   */
+  async beginOIDCLogin(provider: string): Promise<void> {
+    const response = await this.apiClient.post<OIDCAuthorization>(
+      `/auth/oidc/${encodeURIComponent(provider)}/authorize`
+    );
+
+    // Remembered so the callback page only accepts the login this tab started
+    sessionStorage.setItem(AuthService.OIDC_STATE_KEY, response.state);
+    window.location.assign(response.authorization_url);
+  }
+
+  /**
+   * Complete a single sign-on login with the code the provider redirected back with
+   */
+  async completeOIDCLogin(state: string, code: string): Promise<LoginResponse> {
+    const expectedState = sessionStorage.getItem(AuthService.OIDC_STATE_KEY);
+    sessionStorage.removeItem(AuthService.OIDC_STATE_KEY);
+    if (!expectedState || expectedState !== state) {
+      throw new Error('This sign-in was not started from this browser. Please try again.');
+    }
+
+    const callbackData: OIDCCallbackRequest = { state, code };
+    const response = await this.apiClient.post<LoginResponse>('/auth/oidc/callback', callbackData);
+
+    // Local two-factor authentication still applies to single sign-on
+    if (response.mfa_required) {
+      return response;
+    }
+
+    // Store authentication data
+    this.storeAuthData(response.token, response.user, response.refresh_token);
 
     return response;
   }
@@ -104,6 +185,52 @@ export class AuthService {
     }
   }
 
+  /**
+   * Exchange the refresh token for a new access token. Returns the new token, or
+   * null when the session has ended.
+   */
+  async refreshSession(): Promise<string | null> {
+    // Another tab may already have rotated the tokens
+    const storedToken = this.getStoredToken();
+    if (storedToken && storedToken !== this.apiClient.getToken()) {
+      this.apiClient.setToken(storedToken);
+      return storedToken;
+    }
+
+    const refreshToken = this.getStoredRefreshToken();
+    if (!refreshToken) {
+      return null;
+    }
+
+    const refreshData: RefreshRequest = { refresh_token: refreshToken };
+    const response = await this.apiClient.post<LoginResponse>('/auth/refresh', refreshData);
+    this.storeAuthData(response.token, response.user, response.refresh_token);
+    return response.token;
+  }
+
+  /**
+   * List the current user's sessions
+   */
+  async listSessions(): Promise<SessionInfo[]> {
+    const response = await this.apiClient.get<{ sessions: SessionInfo[] }>('/sessions');
+    return response.sessions;
+  }
+
+  /**
+   * Sign out one session, on this or another device
+   */
+  async revokeSession(sessionId: string): Promise<void> {
+    await this.apiClient.delete<void>(`/sessions/${encodeURIComponent(sessionId)}`);
+  }
+
+  /**
+   * Sign out every session except this one
+   */
+  async revokeOtherSessions(): Promise<number> {
+    const response = await this.apiClient.delete<{ revoked: number }>('/sessions');
+    return response.revoked;
+  }
+
   /**
    * Get current authentication state
    */
@@ -169,9 +296,12 @@ export class AuthService {
   /**
    * Store authentication data in localStorage and API client
    */
-  private storeAuthData(token: string, user: User): void {
+  private storeAuthData(token: string, user: User, refreshToken?: string): void {
     localStorage.setItem(AuthService.TOKEN_KEY, token);
     localStorage.setItem(AuthService.USER_KEY, JSON.stringify(user));
+    if (refreshToken) {
+      localStorage.setItem(AuthService.REFRESH_TOKEN_KEY, refreshToken);
+    }
     this.apiClient.setToken(token);
     
     // Also store in cookie for middleware (simple implementation)
@@ -192,6 +322,7 @@ export class AuthService {
    */
   private clearAuthData(): void {
     localStorage.removeItem(AuthService.TOKEN_KEY);
+    localStorage.removeItem(AuthService.REFRESH_TOKEN_KEY);
     localStorage.removeItem(AuthService.USER_KEY);
     this.apiClient.setToken(null);
     
@@ -209,6 +340,14 @@ export class AuthService {
     return localStorage.getItem(AuthService.TOKEN_KEY);
   }
 
+  /**
+   * Get stored refresh token from localStorage
+   */
+  private getStoredRefreshToken(): string | null {
+    if (typeof window === 'undefined') return null;
+    return localStorage.getItem(AuthService.REFRESH_TOKEN_KEY);
+  }
+
   /**
    * Get stored user from localStorage
    */
@@ -8,6 +8,9 @@ import type {
   Document,
   SignDocumentRequest,
   SignDocumentResponse,
+  DuplicatePolicy,
+  DuplicateReport,
+  DocumentVisibility,
   DocumentList,
 } from '@/lib/types';
 
@@ -17,7 +20,15 @@ export class DocumentService {
   /**
    * Sign a PDF document with digital signature
    */
-  async signDocument(file: File, issuer: string, title: string, letterNumber: string): Promise<SignDocumentResponse> {
+  async signDocument(
+    file: File,
+    issuer: string,
+    title: string,
+    letterNumber: string,
+    onDuplicate?: DuplicatePolicy,
+    letterNumberScheme?: string,
+    visibility?: DocumentVisibility
+  ): Promise<SignDocumentResponse> {
     // Validate input
     if (!file) {
       throw new Error('File is required');
@@ -34,7 +45,8 @@ export class DocumentService {
     if (file.size > 50 * 1024 * 1024) { // 50MB limit
       throw new Error('File size must be less than 50MB');
     }
-    if (!letterNumber.trim()) {
+    // A numbering scheme allocates the letter number when none is typed in
+    if (!letterNumber.trim() && !letterNumberScheme) {
       throw new Error('Letter number is required');
     }
 
@@ -43,11 +55,36 @@ export class DocumentService {
     formData.append('file', file);
     formData.append('issuer', issuer.trim());
     formData.append('title', title.trim());
-    formData.append('letter_number', letterNumber.trim());
+    if (letterNumber.trim()) {
+      formData.append('letter_number', letterNumber.trim());
+    } else if (letterNumberScheme) {
+      formData.append('letter_number_scheme', letterNumberScheme);
+    }
+    if (onDuplicate) {
+      formData.append('on_duplicate', onDuplicate);
+    }
+    // access_code documents get a code printed next to their QR code
+    if (visibility) {
+      formData.append('visibility', visibility);
+    }
 
     return this.apiClient.post<SignDocumentResponse>('/documents/sign', formData);
   }
 
+  /**
+   * Check whether a PDF was already signed, or nearly matches a signed document, before signing it
+   */
+  async checkDuplicates(file: File): Promise<DuplicateReport> {
+    if (!file) {
+      throw new Error('File is required');
+    }
+
+    const formData = new FormData();
+    formData.append('file', file);
+
+    return this.apiClient.post<DuplicateReport>('/documents/duplicates', formData);
+  }
+
   /**
    * Get list of signed documents with pagination
    */
@@ -75,7 +112,8 @@ export class DocumentService {
       throw new Error('Document ID is required');
     }
 
-    return this.apiClient.get<Document>(`/documents/${documentId}`);
+    const response = await this.apiClient.get<{ document: Document }>(`/documents/${documentId}`);
+    return response.document;
   }
 
   /**
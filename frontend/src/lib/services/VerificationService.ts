@@ -9,27 +9,43 @@ import type {
   VerifyDocumentRequest,
   VerificationResult,
   VerificationStatus,
+  VerifyByFileResponse,
+  VerificationGroup,
+  VerificationAnalyticsFilters,
+  VerificationAnalytics,
+  TamperAlerts,
 } from '@/lib/types';
 
+// Analytics endpoint of each breakdown
+const analyticsPaths: Record<VerificationGroup, string> = {
+  document: '/documents',
+  day: '/days',
+  issuer: '/issuers',
+  country: '/countries',
+};
+
 export class VerificationService {
   constructor(private apiClient: ApiClient) {}
 
   /**
-   * Get verification information for a document by ID
+   * Get verification information for a document by ID; access_code documents
+   * need the code printed next to their QR code
    */
-  async getVerificationInfo(documentId: string): Promise<VerificationInfo> {
+  async getVerificationInfo(documentId: string, accessCode?: string): Promise<VerificationInfo> {
     if (!documentId.trim()) {
       throw new Error('Document ID is required');
     }
 
-    const response = await this.apiClient.get<{ verification_info: VerificationInfo }>(`/verify/${documentId}`);
+    // The code goes in a header so it stays out of URLs and access logs
+    const headers = accessCode?.trim() ? { 'X-Access-Code': accessCode.trim() } : undefined;
+    const response = await this.apiClient.get<{ verification_info: VerificationInfo }>(`/verify/${documentId}`, headers);
     return response.verification_info || response as any;
   }
 
   /**
    * Verify a document by uploading it for comparison
    */
-  async verifyDocument(documentId: string, file: File): Promise<VerificationResult> {
+  async verifyDocument(documentId: string, file: File, accessCode?: string): Promise<VerificationResult> {
     // Validate input
     if (!documentId.trim()) {
       throw new Error('Document ID is required');
@@ -47,11 +63,83 @@ export class VerificationService {
     // Create form data for file upload
     const formData = new FormData();
     formData.append('file', file);
+    // Without the access code only whether the file is valid is returned
+    if (accessCode?.trim()) {
+      formData.append('access_code', accessCode.trim());
+    }
 
     const response = await this.apiClient.post<{ verification_result: VerificationResult }>(`/verify/${documentId}/upload`, formData);
     return response.verification_result || response as any;
   }
 
+  /**
+   * Verify a PDF without its document ID by finding the documents signed from it
+   */
+  async verifyByFile(file: File): Promise<VerifyByFileResponse> {
+    const validation = this.validateFileForVerification(file);
+    if (!validation.isValid) {
+      throw new Error(validation.error);
+    }
+
+    const formData = new FormData();
+    formData.append('file', file);
+
+    return this.apiClient.post<VerifyByFileResponse>('/verify/by-file', formData);
+  }
+
+  /**
+   * Get verification counts of the user's documents by outcome, in total and
+   * broken down by document, day, issuer or country
+   */
+  async getVerificationAnalytics(groupBy?: VerificationGroup, filters: VerificationAnalyticsFilters = {}): Promise<VerificationAnalytics> {
+    const path = groupBy ? analyticsPaths[groupBy] : '';
+    return this.apiClient.get<VerificationAnalytics>(`/verification-analytics${path}${this.analyticsQuery(filters)}`);
+  }
+
+  /**
+   * Get the documents with many content-changed verifications; without a from
+   * date the server's alert window is searched
+   */
+  async getTamperAlerts(filters: VerificationAnalyticsFilters = {}): Promise<TamperAlerts> {
+    return this.apiClient.get<TamperAlerts>(`/verification-analytics/alerts${this.analyticsQuery(filters)}`);
+  }
+
+  /**
+   * Download verification analytics as CSV
+   */
+  async exportVerificationAnalytics(groupBy?: VerificationGroup, filters: VerificationAnalyticsFilters = {}): Promise<Blob> {
+    const path = groupBy ? analyticsPaths[groupBy] : '';
+    const response = await fetch(
+      `${this.apiClient['baseURL']}/api/verification-analytics${path}${this.analyticsQuery(filters, 'csv')}`,
+      {
+        method: 'GET',
+        headers: {
+          Authorization: `Bearer ${this.apiClient.getToken()}`,
+        },
+      }
+    );
+
+    if (!response.ok) {
+      throw new Error('Failed to export verification analytics');
+    }
+
+    return response.blob();
+  }
+
+  private analyticsQuery(filters: VerificationAnalyticsFilters, format?: 'csv'): string {
+    const params = new URLSearchParams();
+    if (filters.from) params.set('from', filters.from);
+    if (filters.to) params.set('to', filters.to);
+    if (filters.issuer?.trim()) params.set('issuer', filters.issuer.trim());
+    if (filters.document_id?.trim()) params.set('document_id', filters.document_id.trim());
+    if (filters.limit) params.set('limit', filters.limit.toString());
+    if (filters.all) params.set('all', 'true');
+    if (format) params.set('format', format);
+
+    const query = params.toString();
+    return query ? `?${query}` : '';
+  }
+
   /**
    * Parse QR code data to extract document ID
    * This would typically be used with a QR code scanner library
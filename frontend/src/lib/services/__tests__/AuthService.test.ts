@@ -180,6 +180,84 @@ describe('AuthService', () => {
     });
   });
 
+  describe('refreshSession', () => {
+    it('should exchange the refresh token and store the rotated one', async () => {
+      mockLocalStorage.getItem.mockImplementation((key) => {
+        if (key === 'auth_token') return 'old-token';
+        if (key === 'auth_refresh_token') return 'refresh-1';
+        return null;
+      });
+      mockApiClient.getToken.mockReturnValue('old-token');
+      mockApiClient.post.mockResolvedValue({
+        user: mockUser,
+        token: 'new-token',
+        expires_at: '2024-01-02T00:00:00Z',
+        refresh_token: 'refresh-2',
+      });
+
+      const token = await authService.refreshSession();
+
+      expect(token).toBe('new-token');
+      expect(mockApiClient.post).toHaveBeenCalledWith('/auth/refresh', { refresh_token: 'refresh-1' });
+      expect(mockLocalStorage.setItem).toHaveBeenCalledWith('auth_refresh_token', 'refresh-2');
+      expect(mockApiClient.setToken).toHaveBeenCalledWith('new-token');
+    });
+
+    it('should adopt tokens another tab already refreshed', async () => {
+      mockLocalStorage.getItem.mockImplementation((key) => (key === 'auth_token' ? 'new-token' : null));
+      mockApiClient.getToken.mockReturnValue('old-token');
+
+      const token = await authService.refreshSession();
+
+      expect(token).toBe('new-token');
+      expect(mockApiClient.post).not.toHaveBeenCalled();
+    });
+
+    it('should return null without a refresh token', async () => {
+      mockLocalStorage.getItem.mockReturnValue(null);
+
+      expect(await authService.refreshSession()).toBeNull();
+    });
+  });
+
+  describe('completeOIDCLogin', () => {
+    const mockSessionStorage = {
+      getItem: jest.fn(),
+      setItem: jest.fn(),
+      removeItem: jest.fn(),
+    };
+    Object.defineProperty(window, 'sessionStorage', { value: mockSessionStorage });
+
+    beforeEach(() => {
+      mockSessionStorage.getItem.mockReset();
+      mockSessionStorage.removeItem.mockClear();
+    });
+
+    it('should exchange the code for the login this browser started', async () => {
+      mockSessionStorage.getItem.mockReturnValue('state-1');
+      mockApiClient.post.mockResolvedValue({
+        user: mockUser,
+        token: 'sso-token',
+        expires_at: '2024-01-02T00:00:00Z',
+        refresh_token: 'sso-refresh',
+      });
+
+      await authService.completeOIDCLogin('state-1', 'code-1');
+
+      expect(mockApiClient.post).toHaveBeenCalledWith('/auth/oidc/callback', { state: 'state-1', code: 'code-1' });
+      expect(mockSessionStorage.removeItem).toHaveBeenCalledWith('oidc_state');
+      expect(mockLocalStorage.setItem).toHaveBeenCalledWith('auth_token', 'sso-token');
+      expect(mockLocalStorage.setItem).toHaveBeenCalledWith('auth_refresh_token', 'sso-refresh');
+    });
+
+    it('should reject a callback for a login started elsewhere', async () => {
+      mockSessionStorage.getItem.mockReturnValue('state-1');
+
+      await expect(authService.completeOIDCLogin('forged', 'code-1')).rejects.toThrow();
+      expect(mockApiClient.post).not.toHaveBeenCalled();
+    });
+  });
+
   describe('getAuthState', () => {
     it('should return authenticated state when token and user exist', () => {
       mockLocalStorage.getItem.mockImplementation((key) => {
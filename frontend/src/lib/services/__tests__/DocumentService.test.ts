@@ -35,6 +35,9 @@ describe('DocumentService', () => {
         updated_at: '2024-01-01T00:00:00Z',
         file_size: 1024,
         status: 'active',
+        version: 1,
+        private: false,
+        visibility: 'public',
       },
       download_url: 'http://example.com/download/123',
     };
@@ -95,6 +98,30 @@ describe('DocumentService', () => {
   const formData = mockApiClient.post.mock.calls[0][1] as FormData;
   expect(formData.get('issuer')).toBe('John Doe');
   expect(formData.get('letter_number')).toBe('001/2025');
+  expect(formData.get('on_duplicate')).toBeNull();
+    });
+
+    it('should send the duplicate policy', async () => {
+      mockApiClient.post.mockResolvedValue({ ...mockResponse, existing: true });
+
+      const result = await documentService.signDocument(mockFile, 'John Doe', 'Test Document', '001/2025', 'return_existing');
+
+      const formData = mockApiClient.post.mock.calls[0][1] as FormData;
+      expect(formData.get('on_duplicate')).toBe('return_existing');
+      expect(result.existing).toBe(true);
+    });
+  });
+
+  describe('checkDuplicates', () => {
+    it('should post the file to the duplicates endpoint', async () => {
+      const report = { duplicate: false, similar_documents: null };
+      mockApiClient.post.mockResolvedValue(report);
+
+      const file = new File(['test content'], 'test.pdf', { type: 'application/pdf' });
+      const result = await documentService.checkDuplicates(file);
+
+      expect(mockApiClient.post).toHaveBeenCalledWith('/documents/duplicates', expect.any(FormData));
+      expect(result).toEqual(report);
     });
   });
 
@@ -153,10 +180,13 @@ describe('DocumentService', () => {
       updated_at: '2024-01-01T00:00:00Z',
       file_size: 1024,
       status: 'active',
+      version: 1,
+      private: false,
+      visibility: 'public',
     };
 
     it('should get document by ID', async () => {
-      mockApiClient.get.mockResolvedValue(mockDocument);
+      mockApiClient.get.mockResolvedValue({ document: mockDocument });
 
       const result = await documentService.getDocumentById('123');
 
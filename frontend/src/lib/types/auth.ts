@@ -22,6 +22,36 @@ export interface LoginResponse {
   user: User;
   token: string;
   expires_at: string;
+  // Exchanged at /auth/refresh for a new access token once the token expires
+  refresh_token?: string;
+  refresh_expires_at?: string;
+  // Set instead of user and token when a second factor is still needed
+  mfa_required?: boolean;
+  mfa_token?: string;
+  // Set when policy requires two-factor authentication to sign documents
+  mfa_enrollment_required?: boolean;
+}
+
+export interface MFALoginRequest {
+  mfa_token: string;
+  code: string;
+}
+
+// A single sign-on provider, from GET /auth/oidc/providers
+export interface OIDCProvider {
+  name: string;
+  display_name: string;
+}
+
+export interface OIDCAuthorization {
+  authorization_url: string;
+  state: string;
+  expires_at: string;
+}
+
+export interface OIDCCallbackRequest {
+  state: string;
+  code: string;
 }
 
 export interface RegisterRequest {
@@ -37,6 +67,24 @@ export interface RegisterResponse {
   expires_at: string;
 }
 
+export interface RefreshRequest {
+  refresh_token: string;
+}
+
+// One of the current user's sessions, from GET /sessions
+export interface SessionInfo {
+  id: string;
+  device: string;
+  ip_address: string;
+  user_agent: string;
+  mfa: boolean;
+  created_at: string;
+  last_accessed: string;
+  expires_at: string;
+  // Set on the session making the request
+  current: boolean;
+}
+
 export interface AuthState {
   user: User | null;
   token: string | null;
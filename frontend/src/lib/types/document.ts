@@ -1,3 +1,5 @@
+import type { DocumentVisibility } from './verification';
+
 export interface Document {
   id: string;
   user_id: string;
@@ -12,18 +14,98 @@ export interface Document {
   updated_at: string;
   file_size: number;
   status: string;
+  version: number;
+  previous_version_id?: string; // The document this re-signing superseded
+  letter_number_scheme_id?: string; // Set when a numbering scheme allocated the letter number
+  stamped_hash?: string; // Hash of the PDF with the QR code stamped in
+  private: boolean; // Private documents cannot be found by verifying their file
+  visibility: DocumentVisibility; // How much the public verification page shows
+  metadata?: DocumentMetadata;
+  page_text?: string[]; // Only returned by the document detail endpoint
+}
+
+export interface DocumentMetadata {
+  pdf_version: string;
+  page_count: number;
+  encrypted: boolean;
+  title?: string;
+  author?: string;
+  subject?: string;
+  keywords?: string;
+  creator?: string;
+  producer?: string;
+  creation_date?: string;
+  modified_date?: string;
+  xmp?: string;
+  pages?: PageMetadata[];
+  embedded_files?: EmbeddedFileMetadata[];
+  text_truncated?: boolean;
+}
+
+export interface PageMetadata {
+  number: number;
+  width: number;
+  height: number;
+  rotation?: number;
+}
+
+export interface EmbeddedFileMetadata {
+  name: string;
+  description?: string;
+  mime_type?: string;
+  size: number;
+}
+
+// What signing an already signed PDF does
+export type DuplicatePolicy = 'reject' | 'return_existing' | 'new_version';
+
+export interface SimilarDocument {
+  document_id: string;
+  title?: string;
+  letter_number?: string;
+  issuer: string;
+  version: number;
+  created_at: string;
+  similarity: number; // Share of text in common, from 0 to 1
+}
+
+// An organization's letter numbering scheme, e.g. "{seq}/{dept}/{roman_month}/{year}"
+export interface LetterNumberScheme {
+  id: string;
+  organization_id: string;
+  code: string;
+  name: string;
+  pattern: string;
+  department?: string;
+  padding: number;
+  is_default: boolean;
+  is_active: boolean;
+  created_at: string;
+  updated_at: string;
 }
 
 export interface SignDocumentRequest {
   file: File;
   issuer: string;
   title: string; // Required for new documents
-  letterNumber: string; // Required for new documents
+  letterNumber: string; // Required for new documents unless a scheme allocates it
+  letterNumberScheme?: string; // Code of the organization's scheme; its default scheme when omitted
+  onDuplicate?: DuplicatePolicy;
+  visibility?: DocumentVisibility; // public when omitted
 }
 
 export interface SignDocumentResponse {
   document: Document;
   download_url: string;
+  existing?: boolean; // The PDF was already signed and that document was returned
+  similar_documents?: SimilarDocument[];
+  access_code?: string; // Returned once for access_code documents
+}
+
+export interface DuplicateReport {
+  duplicate: boolean;
+  existing?: Document;
+  similar_documents: SimilarDocument[] | null;
 }
 
 export interface DocumentList {
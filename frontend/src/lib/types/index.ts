@@ -6,8 +6,15 @@
 // Document types
 export type {
   Document,
+  DocumentMetadata,
+  PageMetadata,
+  EmbeddedFileMetadata,
   SignDocumentRequest,
   SignDocumentResponse,
+  DuplicatePolicy,
+  SimilarDocument,
+  DuplicateReport,
+  LetterNumberScheme,
   DocumentList,
 } from './document';
 
@@ -16,8 +23,14 @@ export type {
   User,
   LoginRequest,
   LoginResponse,
+  MFALoginRequest,
+  OIDCProvider,
+  OIDCAuthorization,
+  OIDCCallbackRequest,
   RegisterRequest,
   RegisterResponse,
+  RefreshRequest,
+  SessionInfo,
   AuthState,
 } from './auth';
 
@@ -27,4 +40,12 @@ export type {
   VerifyDocumentRequest,
   VerificationResult,
   VerificationStatus,
+  VerifyByFileResponse,
+  DocumentVisibility,
+  VerificationField,
+  VerificationGroup,
+  VerificationAnalyticsFilters,
+  VerificationStats,
+  VerificationAnalytics,
+  TamperAlerts,
 } from './verification';
\ No newline at end of file
@@ -2,18 +2,44 @@
  * Verification-related TypeScript interfaces
  */
 
+// Documents with minimal visibility only return their ID, status and visibility;
+// fields their organization redacts are left out as well
 export interface VerificationInfo {
   document_id: string;
-  filename: string;
-  issuer: string;
+  status: string;
+  visibility: DocumentVisibility;
+  filename?: string;
+  issuer?: string;
+  organization?: string;
   // optional title of the document
   title?: string;
   // optional letter/issue number associated with the signed document
   letter_number?: string;
-  created_at: string;
-  document_hash: string;
+  created_at?: string;
+  file_size?: number;
+  document_hash?: string;
+  // names of the delegate and the delegator when signed under a delegation
+  signed_by?: string;
+  on_behalf_of?: string;
+  redacted?: VerificationField[];
 }
 
+// How much the public verification page shows; access_code documents need the
+// code printed next to their QR code
+export type DocumentVisibility = 'public' | 'minimal' | 'access_code';
+
+// Fields an organization can hide on the public verification page
+export type VerificationField =
+  | 'filename'
+  | 'issuer'
+  | 'organization'
+  | 'title'
+  | 'letter_number'
+  | 'file_size'
+  | 'document_hash'
+  | 'qr_code_data'
+  | 'signed_by';
+
 export interface VerifyDocumentRequest {
   document_id: string;
   file: File;
@@ -26,7 +52,7 @@ export interface VerificationResult {
     qr_valid: boolean;
     hash_matches: boolean;
     signature_valid: boolean;
-    original_hash: string;
+    original_hash?: string; // Left out when the verifier may not see it
     uploaded_hash: string;
   // optional title of the document
   title?: string | null;
@@ -34,6 +60,58 @@ export interface VerificationResult {
   letter_number?: string | null;
   };
   verified_at: string;
+  visibility?: DocumentVisibility;
+  redacted?: VerificationField[];
+}
+
+export type VerificationStatus = 'valid' | 'invalid' | 'modified';
+
+// Verifying a file without its document ID checks every document signed from it
+export interface VerifyByFileResponse {
+  verification_result: VerificationResult; // The newest matching document
+  matches: VerificationResult[];
+  total: number;
+}
+// Breakdowns of the verification analytics; days are UTC dates (YYYY-MM-DD)
+// and countries ISO codes
+export type VerificationGroup = 'document' | 'day' | 'issuer' | 'country';
+
+// Filters of the verification analytics; `all` reports on every document of
+// the organization and needs the document:read:any permission
+export interface VerificationAnalyticsFilters {
+  from?: string;
+  to?: string;
+  issuer?: string;
+  document_id?: string;
+  limit?: number;
+  all?: boolean;
+}
+
+// Verification counts of one group, or of every verification in the summary
+export interface VerificationStats {
+  key: string;
+  // title, or else filename, of a document group
+  label?: string;
+  total: number;
+  valid: number;
+  content_changed: number;
+  invalid: number;
+  error: number;
+  unique_verifiers: number;
 }
 
-export type VerificationStatus = 'valid' | 'invalid' | 'modified';
\ No newline at end of file
+export interface VerificationAnalytics {
+  group_by?: VerificationGroup;
+  from?: string;
+  to?: string;
+  summary: VerificationStats;
+  groups: VerificationStats[];
+}
+
+// Documents whose altered copies were verified at least `threshold` times
+// since `since`, a sign of forged copies circulating
+export interface TamperAlerts {
+  threshold: number;
+  since: string;
+  documents: VerificationStats[];
+}